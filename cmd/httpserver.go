@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"net/http"
+	"time"
+)
+
+const (
+	// defaultHTTPReadTimeout bounds how long a client may take to send a
+	// request, including the body.
+	defaultHTTPReadTimeout = 30 * time.Second
+
+	// defaultHTTPIdleTimeout bounds how long keep-alive connections stay open
+	// between requests.
+	defaultHTTPIdleTimeout = 120 * time.Second
+
+	// httpReadHeaderTimeout bounds how long a client may take to send request
+	// headers. It is not configurable: slow headers are never legitimate.
+	httpReadHeaderTimeout = 10 * time.Second
+)
+
+// httpServerConfig holds the net/http server settings exposed as serve flags
+// for the sse and streamable-http transports.
+type httpServerConfig struct {
+	ReadTimeout    time.Duration
+	WriteTimeout   time.Duration
+	IdleTimeout    time.Duration
+	MaxHeaderBytes int
+	EnableHTTP2    bool
+}
+
+// newHTTPServer builds the *http.Server used by the HTTP transports.
+//
+// HTTP/1.1 is always served. When EnableHTTP2 is set, HTTP/2 is served too,
+// including unencrypted HTTP/2 with prior knowledge since TLS is usually
+// terminated in front of the server.
+func newHTTPServer(handler http.Handler, cfg httpServerConfig) *http.Server {
+	protocols := new(http.Protocols)
+	protocols.SetHTTP1(true)
+	if cfg.EnableHTTP2 {
+		protocols.SetHTTP2(true)
+		protocols.SetUnencryptedHTTP2(true)
+	}
+
+	return &http.Server{
+		Handler:           handler,
+		ReadHeaderTimeout: httpReadHeaderTimeout,
+		ReadTimeout:       cfg.ReadTimeout,
+		WriteTimeout:      cfg.WriteTimeout,
+		IdleTimeout:       cfg.IdleTimeout,
+		MaxHeaderBytes:    cfg.MaxHeaderBytes,
+		Protocols:         protocols,
+	}
+}
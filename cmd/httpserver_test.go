@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestNewHTTPServerWriteTimeoutAbortsSlowResponse(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+
+	release := make(chan struct{})
+	defer close(release)
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(500 * time.Millisecond):
+		case <-release:
+		}
+		_, _ = io.WriteString(w, "too late")
+	})
+
+	srv := newHTTPServer(handler, httpServerConfig{WriteTimeout: 100 * time.Millisecond})
+	go func() { _ = srv.Serve(ln) }()
+	defer func() { _ = srv.Close() }()
+
+	resp, err := http.Get("http://" + ln.Addr().String())
+	if err == nil {
+		body, _ := io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		t.Fatalf("expected request to be aborted, got status %d body %q", resp.StatusCode, body)
+	}
+}
+
+func TestNewHTTPServerAppliesConfig(t *testing.T) {
+	cfg := httpServerConfig{
+		ReadTimeout:    time.Second,
+		WriteTimeout:   2 * time.Second,
+		IdleTimeout:    3 * time.Second,
+		MaxHeaderBytes: 4096,
+	}
+
+	srv := newHTTPServer(http.NotFoundHandler(), cfg)
+	if srv.ReadTimeout != cfg.ReadTimeout || srv.WriteTimeout != cfg.WriteTimeout ||
+		srv.IdleTimeout != cfg.IdleTimeout || srv.MaxHeaderBytes != cfg.MaxHeaderBytes {
+		t.Errorf("server settings do not match config: %+v", srv)
+	}
+	if srv.Protocols.HTTP2() || !srv.Protocols.HTTP1() {
+		t.Errorf("expected HTTP/1.1 only, got %v", srv.Protocols)
+	}
+
+	cfg.EnableHTTP2 = true
+	srv = newHTTPServer(http.NotFoundHandler(), cfg)
+	if !srv.Protocols.HTTP2() || !srv.Protocols.UnencryptedHTTP2() {
+		t.Errorf("expected HTTP/2 enabled, got %v", srv.Protocols)
+	}
+}
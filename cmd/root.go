@@ -4,6 +4,8 @@ import (
 	"os"
 
 	"github.com/spf13/cobra"
+
+	"github.com/giantswarm/mcp-prometheus/pkg/plugin"
 )
 
 var rootCmd = &cobra.Command{
@@ -17,6 +19,7 @@ and analyze metrics data from your Prometheus instance.
 
 The server supports various authentication methods including basic auth
 and bearer tokens, and can be configured through environment variables.`,
+	Args: cobra.ExactArgs(0),
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
@@ -27,9 +30,22 @@ func Execute() {
 	}
 }
 
-// SetVersion sets the version for the root command
-func SetVersion(version string) {
-	rootCmd.Version = version
+// SetVersion sets the version reported by --version, the version command and
+// the MCP server, and the build information printed by serve --build-info.
+func SetVersion(info BuildInfo) {
+	buildInfo = info
+	rootCmd.Version = info.Version
+	rootCmd.SetVersionTemplate("mcp-prometheus {{.Version}}\n")
+}
+
+// toolPlugins are the plugins added with RegisterToolPlugin.
+var toolPlugins []plugin.ToolPlugin
+
+// RegisterToolPlugin adds a plugin whose tools the serve command registers
+// after the built-in tools. Applications embedding mcp-prometheus call it
+// before Execute; plugins are registered in the order given.
+func RegisterToolPlugin(p plugin.ToolPlugin) {
+	toolPlugins = append(toolPlugins, p)
 }
 
 func init() {
@@ -4,11 +4,13 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"log"
 	"log/slog"
 	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
 	"strings"
 	"syscall"
 	"time"
@@ -17,6 +19,7 @@ import (
 	mcpserver "github.com/mark3labs/mcp-go/server"
 	"github.com/spf13/cobra"
 
+	"github.com/giantswarm/mcp-prometheus/internal/filter"
 	"github.com/giantswarm/mcp-prometheus/internal/oauth"
 	"github.com/giantswarm/mcp-prometheus/internal/observability"
 	"github.com/giantswarm/mcp-prometheus/internal/server"
@@ -29,6 +32,7 @@ func newServeCmd() *cobra.Command {
 	var (
 		debugMode   bool
 		enableOAuth bool
+		logOutput   string
 
 		// Transport options
 		transport       string
@@ -43,6 +47,35 @@ func newServeCmd() *cobra.Command {
 		// Tenancy
 		tenancyMode   string
 		staticTenants string
+
+		// Admin
+		adminToken       string
+		allowRawConfig   bool
+		enableAdminTools bool
+
+		// Audit
+		auditLogEntries int
+		auditLogPath    string
+
+		// Prometheus clients and the dynamic client cache
+		clientCfg clientConfig
+
+		// Request rate, result size, query cost and query policy limits
+		limits         queryLimitsConfig
+		queryCostGuard string
+
+		// Endpoint profiles
+		configFile string
+
+		// Error reporting
+		errorVerbosity string
+
+		// HTTP server tuning
+		httpCfg httpServerConfig
+
+		// Version output
+		showVersion   bool
+		showBuildInfo bool
 	)
 
 	cmd := &cobra.Command{
@@ -62,6 +95,24 @@ Environment Variables:
   PROMETHEUS_USERNAME - Optional: Basic auth username
   PROMETHEUS_PASSWORD - Optional: Basic auth password
   PROMETHEUS_TOKEN    - Optional: Bearer token for authentication
+  PROMETHEUS_TOKEN_FILE - Optional: File holding the bearer token, re-read every minute (e.g. a projected service account token)
+  PROMETHEUS_GRAFANA_CLOUD_INSTANCE_ID / PROMETHEUS_GRAFANA_CLOUD_TOKEN - Optional: Grafana Cloud instance ID and access policy token
+  PROMETHEUS_AUTH_MODE - Optional: gcp (Google Cloud Managed Service for Prometheus) or azure (Azure Monitor managed Prometheus)
+  PROMETHEUS_GCP_CREDENTIALS_FILE - Optional: Service account key for gcp (default: Application Default Credentials)
+  PROMETHEUS_AZURE_TENANT_ID / PROMETHEUS_AZURE_CLIENT_ID / PROMETHEUS_AZURE_CLIENT_SECRET - Optional: Azure AD identity for azure (default: managed identity)
+  PROMETHEUS_OAUTH2_TOKEN_URL - Optional: OAuth2 token endpoint; enables the client credentials grant
+  PROMETHEUS_OAUTH2_CLIENT_ID / PROMETHEUS_OAUTH2_CLIENT_SECRET / PROMETHEUS_OAUTH2_SCOPES - OAuth2 client and scopes
+  PROMETHEUS_PATH_PREFIX - Optional: Sub-path Prometheus is served under (e.g. /custom/prometheus)
+  ALERTMANAGER_URL    - Optional: Alertmanager base URL (default: discovered from Prometheus)
+  ALERTMANAGER_USERNAME / ALERTMANAGER_PASSWORD / ALERTMANAGER_TOKEN / ALERTMANAGER_TOKEN_FILE - Optional: Alertmanager credentials (default: the Prometheus credentials)
+  PROMETHEUS_REMOTE_WRITE_URL - Optional: Remote write endpoint for push_metric
+  PROMETHEUS_TRACE_BASE_URL   - Optional: Trace UI base URL for query_exemplars links
+  PROMETHEUS_ERROR_VERBOSITY  - Optional: detailed (default) or safe; see --error-verbosity
+  PROMETHEUS_EXCLUDED_METRICS - Optional: Metric name patterns hidden from metric listings, e.g. up,go_*,*_bucket
+  PROMETHEUS_API_VERSION_NEGOTIATION - Optional: false disables checking the Prometheus version before using newer API features
+  PROMETHEUS_TENANT_ROUTES    - Optional: Per-tenant backends, e.g. tenant1=http://shard1:9090,tenant2=http://shard2:9090
+  MCP_PROMETHEUS_MAX_RESULT_LENGTH - Optional: Characters after which tool results are truncated; see --max-result-length
+  MCP_PROMETHEUS_RANGE_QUERY_POINTS - Optional: Points per series range queries without a step are sized for; see --range-query-points
 
 OAuth 2.1 (when --enable-oauth is set):
   MCP_OAUTH_ISSUER              - OAuth issuer URL (required)
@@ -80,17 +131,36 @@ Tenancy (when --enable-oauth is set):
   --static-tenants              - Comma-separated tenant IDs for all users (static mode)
   TENANCY_STATIC_GROUP_MAP      - JSON map of group→[tenant IDs] for group-mapping (static mode)
 
+Runtime log level:
+  SIGUSR1                       - Toggle between debug and info logging
+  SIGHUP                        - Reopen the --log-output and --audit-log files (for logrotate)
+  POST /admin/log-level         - Set the level, e.g. {"level":"debug"} (requires --admin-token,
+                                  sent in the X-Admin-Token header; sse/streamable-http only)
+
+Endpoint profiles:
+  --config                      - YAML file of named Prometheus endpoints (default:
+                                  ~/.config/mcp-prometheus/config.yaml when it exists).
+                                  Tools select one with the profile parameter; the
+                                  default profile is used when PROMETHEUS_URL is unset.
+
 If PROMETHEUS_URL or PROMETHEUS_ORGID environment variables are not set,
 they can be provided as parameters to individual tool calls.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if showVersion || showBuildInfo {
+				writeVersion(cmd.OutOrStdout(), showBuildInfo)
+				return nil
+			}
+			limits.CostGuard.Mode = server.QueryCostMode(queryCostGuard)
 			return runServe(transport, debugMode, enableOAuth,
 				httpAddr, sseEndpoint, messageEndpoint, httpEndpoint,
-				metricsAddr, tenancyMode, staticTenants)
+				metricsAddr, tenancyMode, staticTenants, adminToken, allowRawConfig, enableAdminTools, auditLogEntries, auditLogPath, logOutput, configFile, errorVerbosity,
+				clientCfg, limits, httpCfg)
 		},
 	}
 
 	// Add flags for configuring the server
 	cmd.Flags().BoolVar(&debugMode, "debug", false, "Enable debug logging (default: false)")
+	cmd.Flags().StringVar(&logOutput, "log-output", server.LogOutputStderr, "Log destination: stderr, stdout (not with stdio transport), or a file path (opened in append mode and reopened on SIGHUP)")
 	cmd.Flags().BoolVar(&enableOAuth, "enable-oauth", false, "Enable OAuth 2.1 authentication (requires MCP_OAUTH_* and DEX_* env vars; sse/streamable-http only)")
 
 	// Transport flags
@@ -99,6 +169,11 @@ they can be provided as parameters to individual tool calls.`,
 	cmd.Flags().StringVar(&sseEndpoint, "sse-endpoint", "/sse", "SSE endpoint path (for sse transport)")
 	cmd.Flags().StringVar(&messageEndpoint, "message-endpoint", "/message", "Message endpoint path (for sse transport)")
 	cmd.Flags().StringVar(&httpEndpoint, "http-endpoint", "/mcp", "HTTP endpoint path (for streamable-http transport)")
+	cmd.Flags().DurationVar(&httpCfg.ReadTimeout, "http-read-timeout", defaultHTTPReadTimeout, "Maximum duration for reading an entire request, including the body (0 disables)")
+	cmd.Flags().DurationVar(&httpCfg.WriteTimeout, "http-write-timeout", 0, "Maximum duration before timing out writes of a response (0 disables; long-lived SSE streams are cut off when set)")
+	cmd.Flags().DurationVar(&httpCfg.IdleTimeout, "http-idle-timeout", defaultHTTPIdleTimeout, "Maximum time to wait for the next request on a keep-alive connection (0 disables)")
+	cmd.Flags().IntVar(&httpCfg.MaxHeaderBytes, "http-max-header-bytes", http.DefaultMaxHeaderBytes, "Maximum size of request headers in bytes")
+	cmd.Flags().BoolVar(&httpCfg.EnableHTTP2, "enable-http2", true, "Serve HTTP/2 in addition to HTTP/1.1 (including unencrypted HTTP/2 with prior knowledge)")
 	cmd.Flags().StringVar(&metricsAddr, "metrics-addr", ":9091", "Address for the observability HTTP server (/metrics, /healthz, /readyz). Set to empty string to disable.")
 
 	// Tenancy flags (only relevant when --enable-oauth is set)
@@ -107,29 +182,235 @@ they can be provided as parameters to individual tool calls.`,
 	cmd.Flags().StringVar(&staticTenants, "static-tenants", "",
 		"Comma-separated Mimir tenant IDs for all authenticated users (--tenancy-mode=static only)")
 
+	// Admin flags
+	cmd.Flags().StringVar(&adminToken, "admin-token", "",
+		"Token required in the X-Admin-Token header for POST /admin/log-level (sse/streamable-http only). The endpoint is disabled when empty.")
+	cmd.Flags().BoolVar(&allowRawConfig, "allow-raw-config", false,
+		"Let get_config callers pass raw=true to read the Prometheus configuration with credentials unredacted")
+	cmd.Flags().BoolVar(&enableAdminTools, "enable-admin-tools", false,
+		"Register the tools that write to Prometheus: push_metric and the TSDB admin API tools (delete_series, snapshot_tsdb)")
+	cmd.Flags().IntVar(&auditLogEntries, "audit-log-entries", 0,
+		fmt.Sprintf("Keep the last N tool invocations in memory for get_invocation_history (0 disables; negative uses %d)", server.DefaultAuditLogEntries))
+	cmd.Flags().StringVar(&auditLogPath, "audit-log", "",
+		"Append every tool invocation (tool, redacted arguments, caller, session, duration, result size, error) as a JSON line to this file (reopened on SIGHUP; empty disables)")
+
+	// Prometheus client flags
+	cmd.Flags().IntVar(&clientCfg.ConnectionWarmup, "connection-warmup", 0,
+		"Number of connections to open to PROMETHEUS_URL at startup before serving tool calls (0 disables; waits at most 5s)")
+	cmd.Flags().Float64Var(&limits.MaxQPS, "max-qps", 0,
+		"Maximum requests per second sent to Prometheus across all tool calls, with bursts of the same size (0 disables)")
+	cmd.Flags().IntVar(&limits.MaxConcurrentQueries, "max-concurrent-queries", 0,
+		"Maximum requests to Prometheus in flight at once across all tool calls; further requests wait (0 disables)")
+	cmd.Flags().IntVar(&clientCfg.CacheSize, "client-cache-size", server.DefaultClientCacheSize,
+		"Clients for prometheus_url, org_id and profile parameters kept for reuse by later calls, least recently used evicted first (0 disables)")
+	cmd.Flags().DurationVar(&clientCfg.CacheIdleTimeout, "client-cache-idle-timeout", server.DefaultClientCacheIdleTimeout,
+		"Evict cached clients unused for this long (0 keeps them until evicted by --client-cache-size)")
+
+	// Endpoint profile flags
+	cmd.Flags().StringVar(&configFile, "config", "",
+		"YAML file of named Prometheus endpoint profiles (default: mcp-prometheus/config.yaml in the user config directory, when it exists)")
+
+	// Result truncation flags
+	cmd.Flags().IntVar(&limits.MaxResultLength, "max-result-length", 0,
+		fmt.Sprintf("Characters after which tool result text is truncated (0 uses MCP_PROMETHEUS_MAX_RESULT_LENGTH, or %d when unset)", prometheus.MaxResultLength))
+
+	// Range query resolution flags
+	cmd.Flags().IntVar(&limits.RangeQueryPoints, "range-query-points", 0,
+		fmt.Sprintf("Points per series that execute_range_query sizes the step for when none is given (0 uses MCP_PROMETHEUS_RANGE_QUERY_POINTS, or %d when unset)", prometheus.DefaultRangeQueryPoints))
+
+	// Error reporting flags
+	cmd.Flags().StringVar(&errorVerbosity, "error-verbosity", os.Getenv("PROMETHEUS_ERROR_VERBOSITY"),
+		"Error detail returned to clients: detailed (default) or safe (opaque error codes; details are only logged). Defaults to PROMETHEUS_ERROR_VERBOSITY")
+
+	// Query cost guard flags
+	cmd.Flags().StringVar(&queryCostGuard, "query-cost-guard", string(server.QueryCostOff),
+		"Estimate the cost of execute_query and execute_range_query calls before running them: off, warn (run with a warning) or refuse (return guidance on narrowing the query) when over --max-query-series or --max-query-samples")
+	cmd.Flags().IntVar(&limits.CostGuard.MaxSeries, "max-query-series", server.DefaultMaxQuerySeries,
+		"Series the selectors of a query may match over its time range before the cost guard applies (0 disables this check)")
+	cmd.Flags().Int64Var(&limits.CostGuard.MaxSamples, "max-query-samples", server.DefaultMaxQuerySamples,
+		"Samples (series times evaluation steps) a query may return before the cost guard applies (0 disables this check)")
+	cmd.Flags().BoolVar(&limits.CostGuard.FailOpen, "query-cost-guard-fail-open", false,
+		"Run queries whose cost cannot be estimated (e.g. the series lookup failed, or Prometheus lacks the limit parameter) instead of refusing them in refuse mode or warning in warn mode")
+
+	// Query policy flags
+	cmd.Flags().DurationVar(&limits.Policy.MaxRange, "max-query-range", 0,
+		"Refuse queries whose time range, range vectors or subqueries span more than this duration (e.g. 168h; 0 disables)")
+	cmd.Flags().DurationVar(&limits.Policy.MinStep, "min-query-step", 0,
+		"Refuse range queries and subqueries with a smaller step; computed steps are raised to it (e.g. 30s; 0 disables)")
+	cmd.Flags().BoolVar(&limits.Policy.RequireMatcher, "require-query-matcher", false,
+		"Refuse queries with a selector lacking a non-regex label matcher, such as a metric name or job=\"api\"")
+
+	// Version flags
+	cmd.Flags().BoolVar(&showVersion, "version", false, "Print the version and exit")
+	cmd.Flags().BoolVar(&showBuildInfo, "build-info", false, "Print the version, Go version, GOOS/GOARCH and git commit, and exit")
+
 	return cmd
 }
 
+// clientConfig holds the serve flags for the Prometheus clients: the
+// connections opened at startup and the cache of dynamic clients.
+type clientConfig struct {
+	ConnectionWarmup int
+	CacheSize        int
+	CacheIdleTimeout time.Duration
+}
+
+// queryLimitsConfig holds the serve flags that bound tool calls: the rate
+// and concurrency of requests to Prometheus, the size of results, the
+// resolution of range queries, and the query cost guard and policy.
+type queryLimitsConfig struct {
+	MaxQPS               float64
+	MaxConcurrentQueries int
+	MaxResultLength      int
+	RangeQueryPoints     int
+	CostGuard            server.QueryCostGuard
+	Policy               server.QueryPolicy
+}
+
 // runServe contains the main server logic with support for multiple transports
 func runServe(transport string, debugMode bool, enableOAuth bool,
 	httpAddr, sseEndpoint, messageEndpoint, httpEndpoint string,
-	metricsAddr string, tenancyMode string, staticTenants string) error {
+	metricsAddr string, tenancyMode string, staticTenants string, adminToken string, allowRawConfig bool, enableAdminTools bool,
+	auditLogEntries int, auditLogPath string, logOutput string, configFile string, errorVerbosity string,
+	clientCfg clientConfig, limits queryLimitsConfig, httpCfg httpServerConfig) error {
 
-	// Create the unified structured logger.
-	logLevel := slog.LevelInfo
+	// The stdio transport speaks JSON-RPC on stdout; log lines would corrupt it.
+	if transport == "stdio" && logOutput == server.LogOutputStdout {
+		return fmt.Errorf("--log-output stdout is not supported with stdio transport; use stderr or a file")
+	}
+	verbosity, err := server.ParseErrorVerbosity(errorVerbosity)
+	if err != nil {
+		return err
+	}
+	if limits.MaxResultLength == 0 {
+		if raw := os.Getenv("MCP_PROMETHEUS_MAX_RESULT_LENGTH"); raw != "" {
+			if limits.MaxResultLength, err = strconv.Atoi(raw); err != nil {
+				return fmt.Errorf("MCP_PROMETHEUS_MAX_RESULT_LENGTH: %w", err)
+			}
+		}
+	}
+	if limits.MaxResultLength < 0 {
+		return fmt.Errorf("max result length must not be negative (got %d)", limits.MaxResultLength)
+	}
+	if limits.RangeQueryPoints == 0 {
+		if raw := os.Getenv("MCP_PROMETHEUS_RANGE_QUERY_POINTS"); raw != "" {
+			if limits.RangeQueryPoints, err = strconv.Atoi(raw); err != nil {
+				return fmt.Errorf("MCP_PROMETHEUS_RANGE_QUERY_POINTS: %w", err)
+			}
+		}
+	}
+	if limits.RangeQueryPoints < 0 {
+		return fmt.Errorf("range query points must not be negative (got %d)", limits.RangeQueryPoints)
+	}
+	requestLimiter, err := server.NewRequestLimiter(limits.MaxQPS, limits.MaxConcurrentQueries)
+	if err != nil {
+		return err
+	}
+	if limits.CostGuard.Mode, err = server.ParseQueryCostMode(string(limits.CostGuard.Mode)); err != nil {
+		return err
+	}
+	if limits.CostGuard.MaxSeries < 0 || limits.CostGuard.MaxSamples < 0 {
+		return fmt.Errorf("query cost limits must not be negative (got %d series, %d samples)", limits.CostGuard.MaxSeries, limits.CostGuard.MaxSamples)
+	}
+	if limits.Policy.MaxRange < 0 || limits.Policy.MinStep < 0 {
+		return fmt.Errorf("query policy durations must not be negative (got max range %s, min step %s)", limits.Policy.MaxRange, limits.Policy.MinStep)
+	}
+	if clientCfg.CacheSize < 0 || clientCfg.CacheIdleTimeout < 0 {
+		return fmt.Errorf("client cache size and idle timeout must not be negative (got %d, %s)", clientCfg.CacheSize, clientCfg.CacheIdleTimeout)
+	}
+
+	// Create the unified structured logger. The level is held in a LevelVar so
+	// it can be changed at runtime via SIGUSR1 or the admin endpoint.
+	logLevel := new(slog.LevelVar)
 	if debugMode {
-		logLevel = slog.LevelDebug
+		logLevel.Set(slog.LevelDebug)
+	}
+	logWriter, logFile, err := server.OpenLogOutput(logOutput)
+	if err != nil {
+		return err
 	}
-	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: logLevel}))
+	if logFile != nil {
+		defer func() { _ = logFile.Close() }()
+	}
+	logger := slog.New(slog.NewTextHandler(logWriter, &slog.HandlerOptions{Level: logLevel}))
+
+	// Route output of libraries using the standard log package through the
+	// structured logger so it shares format and level filtering.
+	log.SetFlags(0)
+	log.SetOutput(server.NewLogWriterAdapter(logger))
 
 	// Setup graceful shutdown - listen for both SIGINT and SIGTERM
 	shutdownCtx, cancel := signal.NotifyContext(context.Background(),
 		os.Interrupt, syscall.SIGTERM)
 	defer cancel()
 
+	// Initialise tracing first so Prometheus client spans use the provider
+	tp, shutdownTracer, err := observability.NewTracerProvider(shutdownCtx, logger)
+	if err != nil {
+		return fmt.Errorf("failed to initialise OTel tracer: %w", err)
+	}
+	defer func() {
+		if err := shutdownTracer(context.Background()); err != nil {
+			logger.Error("Error flushing OTel spans", "error", err)
+		}
+	}()
+
 	// Collect server context options; OAuth may append more below.
 	serverOpts := []server.ServerOption{
 		server.WithSlogLogger(logger),
+		server.WithLogLevelVar(logLevel),
+		server.WithVersion(rootCmd.Version),
+		server.WithErrorVerbosity(verbosity),
+		server.WithQueryCostGuard(limits.CostGuard),
+		server.WithQueryPolicy(limits.Policy),
+		server.WithRawConfig(allowRawConfig),
+		server.WithAdminTools(enableAdminTools),
+		server.WithClientCache(server.NewClientCache(clientCfg.CacheSize, clientCfg.CacheIdleTimeout)),
+		server.WithTracerProvider(tp),
+	}
+	if auditLogEntries != 0 {
+		serverOpts = append(serverOpts, server.WithAuditLog(auditLogEntries))
+	}
+	var auditFile *server.LogFile
+	if auditLogPath != "" {
+		if auditFile, err = server.OpenLogFile(auditLogPath); err != nil {
+			return fmt.Errorf("audit log: %w", err)
+		}
+		defer func() { _ = auditFile.Close() }()
+		serverOpts = append(serverOpts, server.WithAuditLogFile(auditFile))
+	}
+	if clientCfg.ConnectionWarmup > 0 {
+		serverOpts = append(serverOpts, server.WithConnectionWarmup(clientCfg.ConnectionWarmup))
+	}
+	if requestLimiter != nil {
+		serverOpts = append(serverOpts, server.WithRequestLimiter(requestLimiter))
+	}
+	if limits.MaxResultLength > 0 {
+		serverOpts = append(serverOpts, server.WithMaxResultLength(limits.MaxResultLength))
+	}
+	if limits.RangeQueryPoints > 0 {
+		serverOpts = append(serverOpts, server.WithRangeQueryPoints(limits.RangeQueryPoints))
+	}
+	if configFile == "" {
+		// The default file is optional; an explicit --config must exist.
+		if path := server.DefaultConfigFile(); path != "" {
+			if _, err := os.Stat(path); err == nil {
+				configFile = path
+			}
+		}
+	}
+	if configFile != "" {
+		serverOpts = append(serverOpts, server.WithConfigFile(configFile))
+	}
+	if patterns := filter.ParsePatterns(os.Getenv("PROMETHEUS_EXCLUDED_METRICS")); len(patterns) > 0 {
+		serverOpts = append(serverOpts, server.WithExcludedMetrics(patterns))
+	}
+	if raw := os.Getenv("PROMETHEUS_TENANT_ROUTES"); raw != "" {
+		routes, err := server.ParseTenantRoutes(raw)
+		if err != nil {
+			return fmt.Errorf("PROMETHEUS_TENANT_ROUTES: %w", err)
+		}
+		serverOpts = append(serverOpts, server.WithTenantRouter(routes))
 	}
 
 	// OAuth 2.1 setup (SSE and streamable-http transports only).
@@ -172,45 +453,69 @@ func runServe(transport string, debugMode bool, enableOAuth bool,
 		logger.Info("OAuth 2.1 authentication enabled", "tenancy_mode", tenancyMode)
 	}
 
+	for _, p := range toolPlugins {
+		serverOpts = append(serverOpts, server.WithToolPlugin(p))
+	}
+
 	// Create server context
 	serverContext, err := server.NewServerContext(shutdownCtx, serverOpts...)
 	if err != nil {
 		return fmt.Errorf("failed to create server context: %w", err)
 	}
+	if names := serverContext.Profiles().Names(); len(names) > 0 {
+		logger.Info("Loaded Prometheus endpoint profiles", "file", configFile, "profiles", names, "default", serverContext.Profiles().Default)
+	}
 	defer func() {
 		if err := serverContext.Shutdown(); err != nil {
 			logger.Error("Error during server context shutdown", "error", err)
 		}
 	}()
 
+	// SIGUSR1 toggles debug logging without a restart.
+	server.WatchDebugToggleSignal(shutdownCtx, serverContext)
+	if logFile != nil {
+		server.WatchLogReopenSignal(shutdownCtx, logFile, logger)
+	}
+	if auditFile != nil {
+		server.WatchLogReopenSignal(shutdownCtx, auditFile, logger)
+	}
+
+	// Admin routes are only mounted when a token is configured.
+	adminRoutes := map[string]http.Handler{}
+	if adminToken != "" {
+		adminRoutes[server.AdminLogLevelPath] = server.NewLogLevelHandler(serverContext, adminToken)
+	}
+
 	// Log configuration
 	config := serverContext.PrometheusConfig()
-	authMethod := "none"
-	if config.Username != "" && config.Password != "" {
+	authMethod := config.AuthType()
+	switch authMethod {
+	case "basic":
 		authMethod = fmt.Sprintf("basic (username: %s)", config.Username)
-	} else if config.Token != "" {
-		authMethod = "bearer token"
+	case "oauth2":
+		authMethod = fmt.Sprintf("oauth2 (client ID: %s)", config.OAuth2.ClientID)
+	case "gcp":
+		if config.GCPCredentialsFile == "" {
+			authMethod = "gcp (application default credentials)"
+		}
+	case "azure":
+		if config.Azure.ClientSecret == "" {
+			authMethod = "azure (managed identity)"
+		}
 	}
 	logger.Info("Prometheus configuration",
 		"url", config.URL,
 		"auth", authMethod,
 		"org_id", config.OrgID,
 	)
+	if requestLimiter != nil {
+		logger.Info("Limiting requests to Prometheus", "max_qps", limits.MaxQPS, "max_concurrent_queries", limits.MaxConcurrentQueries)
+	}
 
-	// Initialise observability (metrics + tracing)
+	// Initialise observability metrics
 	metrics := observability.NewMetrics()
 	health := &observability.Health{}
 
-	tp, shutdownTracer, err := observability.NewTracerProvider(shutdownCtx, logger)
-	if err != nil {
-		return fmt.Errorf("failed to initialise OTel tracer: %w", err)
-	}
-	defer func() {
-		if err := shutdownTracer(context.Background()); err != nil {
-			logger.Error("Error flushing OTel spans", "error", err)
-		}
-	}()
-
 	inst := observability.NewInstrumentor(metrics, tp)
 
 	// Start observability HTTP server (/metrics, /healthz, /readyz) unless disabled.
@@ -250,9 +555,9 @@ func runServe(transport string, debugMode bool, enableOAuth bool,
 	case "stdio":
 		return runStdioServer(mcpSrv, logger)
 	case "sse":
-		return runSSEServer(mcpSrv, httpAddr, sseEndpoint, messageEndpoint, shutdownCtx, logger, oauthHandler)
+		return runSSEServer(mcpSrv, httpAddr, sseEndpoint, messageEndpoint, shutdownCtx, logger, oauthHandler, adminRoutes, httpCfg)
 	case "streamable-http":
-		return runStreamableHTTPServer(mcpSrv, httpAddr, httpEndpoint, shutdownCtx, logger, oauthHandler)
+		return runStreamableHTTPServer(mcpSrv, httpAddr, httpEndpoint, shutdownCtx, logger, oauthHandler, adminRoutes, httpCfg)
 	default:
 		return fmt.Errorf("unsupported transport type: %s (supported: stdio, sse, streamable-http)", transport)
 	}
@@ -275,16 +580,13 @@ func parseTenants(s string) []string {
 
 // serveHTTPWithShutdown binds a TCP listener, serves with the given handler, and
 // shuts down gracefully when ctx is cancelled.
-func serveHTTPWithShutdown(ctx context.Context, addr string, handler http.Handler) error {
+func serveHTTPWithShutdown(ctx context.Context, addr string, handler http.Handler, cfg httpServerConfig) error {
 	ln, err := net.Listen("tcp", addr)
 	if err != nil {
 		return fmt.Errorf("listen %s: %w", addr, err)
 	}
 
-	srv := &http.Server{
-		Handler:           handler,
-		ReadHeaderTimeout: 10 * time.Second,
-	}
+	srv := newHTTPServer(handler, cfg)
 
 	errCh := make(chan error, 1)
 	go func() {
@@ -322,6 +624,22 @@ func registerOAuthRoutes(mux *http.ServeMux, h *handler.Handler, mcpPath string,
 	}
 }
 
+// registerRoutes mounts handlers onto mux without authentication.
+func registerRoutes(mux *http.ServeMux, handlers map[string]http.Handler) {
+	for path, handler := range handlers {
+		mux.Handle(path, handler)
+	}
+}
+
+// registerAdminRoutes mounts the admin endpoints onto mux. They authenticate
+// requests themselves via the --admin-token shared secret.
+func registerAdminRoutes(mux *http.ServeMux, adminRoutes map[string]http.Handler, logger *slog.Logger) {
+	for path, handler := range adminRoutes {
+		mux.Handle(path, handler)
+		logger.Info("Admin endpoint mounted", "path", path)
+	}
+}
+
 // runStdioServer runs the server with STDIO transport
 func runStdioServer(mcpSrv *mcpserver.MCPServer, logger *slog.Logger) error {
 	// Start the server in a goroutine so we can handle shutdown signals
@@ -344,50 +662,38 @@ func runStdioServer(mcpSrv *mcpserver.MCPServer, logger *slog.Logger) error {
 }
 
 // runSSEServer runs the server with SSE transport.
-// When oauthHandler is non-nil, a custom HTTP mux is built with OAuth 2.1
-// endpoints and the SSE/message paths are protected with ValidateToken.
-func runSSEServer(mcpSrv *mcpserver.MCPServer, addr, sseEndpoint, messageEndpoint string, ctx context.Context, logger *slog.Logger, oauthHandler *handler.Handler) error {
+// When oauthHandler is non-nil, OAuth 2.1 endpoints are mounted and the
+// SSE/message paths are protected with ValidateToken.
+// adminRoutes are mounted on the same mux without OAuth protection; they are
+// expected to authenticate requests themselves.
+func runSSEServer(mcpSrv *mcpserver.MCPServer, addr, sseEndpoint, messageEndpoint string, ctx context.Context, logger *slog.Logger, oauthHandler *handler.Handler, adminRoutes map[string]http.Handler, httpCfg httpServerConfig) error {
 	logger.Debug("SSE server configuration", "addr", addr, "sse_endpoint", sseEndpoint, "message_endpoint", messageEndpoint, "oauth", oauthHandler != nil)
 
 	sseServer := mcpserver.NewSSEServer(mcpSrv,
 		mcpserver.WithSSEEndpoint(sseEndpoint),
 		mcpserver.WithMessageEndpoint(messageEndpoint),
+		mcpserver.WithSSEContextFunc(prometheus.WithStreamingTransport),
 	)
 
 	logger.Info("SSE server starting", "addr", addr, "sse_endpoint", sseEndpoint, "message_endpoint", messageEndpoint)
 
+	// Serve through an explicit *http.Server so the timeouts and protocol
+	// settings from httpCfg apply instead of mcp-go's defaults.
+	mux := http.NewServeMux()
+	mcpHandlers := map[string]http.Handler{
+		sseEndpoint:     sseServer.SSEHandler(),
+		messageEndpoint: sseServer.MessageHandler(),
+	}
 	if oauthHandler != nil {
-		// OAuth path: custom mux with protected MCP routes.
-		mux := http.NewServeMux()
-		registerOAuthRoutes(mux, oauthHandler, sseEndpoint, map[string]http.Handler{
-			sseEndpoint:     sseServer.SSEHandler(),
-			messageEndpoint: sseServer.MessageHandler(),
-		})
+		registerOAuthRoutes(mux, oauthHandler, sseEndpoint, mcpHandlers)
 		logger.Info("OAuth 2.1 endpoints mounted", "path", "/oauth/*")
-		return serveHTTPWithShutdown(ctx, addr, mux)
+	} else {
+		registerRoutes(mux, mcpHandlers)
 	}
+	registerAdminRoutes(mux, adminRoutes, logger)
 
-	// No OAuth: use the SSE server's built-in Start/Shutdown lifecycle.
-	serverDone := make(chan error, 1)
-	go func() {
-		defer close(serverDone)
-		if err := sseServer.Start(addr); err != nil {
-			serverDone <- err
-		}
-	}()
-
-	select {
-	case <-ctx.Done():
-		logger.Info("Shutdown signal received, stopping SSE server")
-		shutdownCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-		defer cancel()
-		if err := sseServer.Shutdown(shutdownCtx); err != nil {
-			return fmt.Errorf("error shutting down SSE server: %w", err)
-		}
-	case err := <-serverDone:
-		if err != nil {
-			return fmt.Errorf("SSE server stopped with error: %w", err)
-		}
+	if err := serveHTTPWithShutdown(ctx, addr, mux, httpCfg); err != nil {
+		return fmt.Errorf("SSE server stopped with error: %w", err)
 	}
 
 	logger.Info("SSE server gracefully stopped")
@@ -396,44 +702,30 @@ func runSSEServer(mcpSrv *mcpserver.MCPServer, addr, sseEndpoint, messageEndpoin
 
 // runStreamableHTTPServer runs the server with Streamable HTTP transport.
 // When oauthHandler is non-nil, MCP requests are protected with ValidateToken.
-func runStreamableHTTPServer(mcpSrv *mcpserver.MCPServer, addr, endpoint string, ctx context.Context, logger *slog.Logger, oauthHandler *handler.Handler) error {
+// adminRoutes are mounted as in runSSEServer.
+func runStreamableHTTPServer(mcpSrv *mcpserver.MCPServer, addr, endpoint string, ctx context.Context, logger *slog.Logger, oauthHandler *handler.Handler, adminRoutes map[string]http.Handler, httpCfg httpServerConfig) error {
 	httpServer := mcpserver.NewStreamableHTTPServer(mcpSrv,
 		mcpserver.WithEndpointPath(endpoint),
 	)
 
 	logger.Info("Streamable HTTP server starting", "addr", addr, "endpoint", endpoint)
 
+	// Serve through an explicit *http.Server so the timeouts and protocol
+	// settings from httpCfg apply instead of mcp-go's defaults.
+	mux := http.NewServeMux()
+	mcpHandlers := map[string]http.Handler{
+		endpoint: httpServer,
+	}
 	if oauthHandler != nil {
-		// OAuth path: custom mux with protected MCP route.
-		mux := http.NewServeMux()
-		registerOAuthRoutes(mux, oauthHandler, endpoint, map[string]http.Handler{
-			endpoint: httpServer,
-		})
+		registerOAuthRoutes(mux, oauthHandler, endpoint, mcpHandlers)
 		logger.Info("OAuth 2.1 endpoints mounted", "path", "/oauth/*")
-		return serveHTTPWithShutdown(ctx, addr, mux)
+	} else {
+		registerRoutes(mux, mcpHandlers)
 	}
+	registerAdminRoutes(mux, adminRoutes, logger)
 
-	// No OAuth: use the streamable HTTP server's built-in lifecycle.
-	serverDone := make(chan error, 1)
-	go func() {
-		defer close(serverDone)
-		if err := httpServer.Start(addr); err != nil {
-			serverDone <- err
-		}
-	}()
-
-	select {
-	case <-ctx.Done():
-		logger.Info("Shutdown signal received, stopping HTTP server")
-		shutdownCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-		defer cancel()
-		if err := httpServer.Shutdown(shutdownCtx); err != nil {
-			return fmt.Errorf("error shutting down HTTP server: %w", err)
-		}
-	case err := <-serverDone:
-		if err != nil {
-			return fmt.Errorf("HTTP server stopped with error: %w", err)
-		}
+	if err := serveHTTPWithShutdown(ctx, addr, mux, httpCfg); err != nil {
+		return fmt.Errorf("HTTP server stopped with error: %w", err)
 	}
 
 	logger.Info("HTTP server gracefully stopped")
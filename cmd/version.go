@@ -2,10 +2,44 @@ package cmd
 
 import (
 	"fmt"
+	"io"
 
 	"github.com/spf13/cobra"
 )
 
+// BuildInfo describes the running binary. Version and Commit are injected at
+// build time via ldflags; the Go fields come from the runtime package.
+type BuildInfo struct {
+	Version   string
+	Commit    string
+	GoVersion string
+	GOOS      string
+	GOARCH    string
+}
+
+// buildInfo is the build information set by SetVersion.
+var buildInfo BuildInfo
+
+// writeVersion prints the version line and, when withBuildInfo is set, the
+// Go version, platform and commit of the binary.
+func writeVersion(w io.Writer, withBuildInfo bool) {
+	_, _ = fmt.Fprintf(w, "mcp-prometheus %s\n", rootCmd.Version)
+	if !withBuildInfo {
+		return
+	}
+	_, _ = fmt.Fprintf(w, "go: %s\n", valueOrUnknown(buildInfo.GoVersion))
+	_, _ = fmt.Fprintf(w, "platform: %s/%s\n", valueOrUnknown(buildInfo.GOOS), valueOrUnknown(buildInfo.GOARCH))
+	_, _ = fmt.Fprintf(w, "commit: %s\n", valueOrUnknown(buildInfo.Commit))
+}
+
+// valueOrUnknown returns s, or "unknown" when s was not injected.
+func valueOrUnknown(s string) string {
+	if s == "" {
+		return "unknown"
+	}
+	return s
+}
+
 // newVersionCmd creates a new version command
 func newVersionCmd() *cobra.Command {
 	return &cobra.Command{
@@ -13,7 +47,7 @@ func newVersionCmd() *cobra.Command {
 		Short: "Print the version number",
 		Long:  `Print the version number of mcp-prometheus`,
 		Run: func(cmd *cobra.Command, args []string) {
-			fmt.Printf("mcp-prometheus %s\n", rootCmd.Version)
+			writeVersion(cmd.OutOrStdout(), false)
 		},
 	}
 }
@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestVersionOutput(t *testing.T) {
+	previous := buildInfo
+	SetVersion(BuildInfo{
+		Version:   "v1.2.3",
+		Commit:    "abc1234",
+		GoVersion: "go1.26.0",
+		GOOS:      "linux",
+		GOARCH:    "arm64",
+	})
+	defer SetVersion(previous)
+
+	tests := []struct {
+		name string
+		run  func(args []string, out *bytes.Buffer) error
+		args []string
+		want map[string]string
+	}{
+		{
+			name: "root --version",
+			run:  executeRoot,
+			args: []string{"--version"},
+			want: map[string]string{"mcp-prometheus": "v1.2.3"},
+		},
+		{
+			name: "serve --version",
+			run:  executeServe,
+			args: []string{"--version"},
+			want: map[string]string{"mcp-prometheus": "v1.2.3"},
+		},
+		{
+			name: "serve --build-info",
+			run:  executeServe,
+			args: []string{"--build-info"},
+			want: map[string]string{
+				"mcp-prometheus": "v1.2.3",
+				"go:":            "go1.26.0",
+				"platform:":      "linux/arm64",
+				"commit:":        "abc1234",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			if err := tt.run(tt.args, &out); err != nil {
+				t.Fatalf("execute %v: %v", tt.args, err)
+			}
+
+			got := map[string]string{}
+			for line := range strings.Lines(out.String()) {
+				key, value, ok := strings.Cut(strings.TrimSpace(line), " ")
+				if !ok {
+					t.Fatalf("unexpected output line %q", line)
+				}
+				got[key] = value
+			}
+			if len(got) != len(tt.want) {
+				t.Errorf("got %d lines, want %d:\n%s", len(got), len(tt.want), out.String())
+			}
+			for key, want := range tt.want {
+				if got[key] != want {
+					t.Errorf("%s = %q, want %q", key, got[key], want)
+				}
+			}
+		})
+	}
+}
+
+func TestVersionOutputUnknownBuildInfo(t *testing.T) {
+	previous := buildInfo
+	SetVersion(BuildInfo{Version: "dev"})
+	defer SetVersion(previous)
+
+	var out bytes.Buffer
+	writeVersion(&out, true)
+	if !strings.Contains(out.String(), "commit: unknown\n") {
+		t.Errorf("expected unknown commit, got:\n%s", out.String())
+	}
+}
+
+func executeRoot(args []string, out *bytes.Buffer) error {
+	rootCmd.SetOut(out)
+	rootCmd.SetArgs(args)
+	defer func() {
+		rootCmd.SetOut(nil)
+		rootCmd.SetArgs(nil)
+	}()
+	return rootCmd.Execute()
+}
+
+func executeServe(args []string, out *bytes.Buffer) error {
+	cmd := newServeCmd()
+	cmd.SetOut(out)
+	cmd.SetArgs(args)
+	return cmd.Execute()
+}
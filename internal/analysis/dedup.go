@@ -0,0 +1,153 @@
+package analysis
+
+import (
+	"fmt"
+	"math"
+	"os"
+
+	"github.com/prometheus/common/model"
+)
+
+// DefaultReplicaLabel is the label that distinguishes Prometheus replicas when
+// PROMETHEUS_REPLICA_LABEL is not set.
+const DefaultReplicaLabel = "prometheus_replica"
+
+// Aggregate selects how the samples of duplicate series are combined.
+type Aggregate string
+
+const (
+	// AggregateLatest keeps the sample with the highest timestamp. It is the
+	// default.
+	AggregateLatest Aggregate = "latest"
+	// AggregateFirst keeps the sample from the first result it appears in.
+	AggregateFirst Aggregate = "first"
+	// AggregateAvg averages the sample values and keeps the highest
+	// timestamp.
+	AggregateAvg Aggregate = "avg"
+	// AggregateMax keeps the sample with the highest value. NaN samples are
+	// skipped unless every sample is NaN, in which case the latest is kept.
+	AggregateMax Aggregate = "max"
+	// AggregateMin keeps the sample with the lowest value. NaN samples are
+	// skipped as for AggregateMax.
+	AggregateMin Aggregate = "min"
+)
+
+// ParseAggregate validates an aggregate name. The empty string selects
+// AggregateLatest.
+func ParseAggregate(s string) (Aggregate, error) {
+	switch a := Aggregate(s); a {
+	case "":
+		return AggregateLatest, nil
+	case AggregateLatest, AggregateFirst, AggregateAvg, AggregateMax, AggregateMin:
+		return a, nil
+	}
+	return "", fmt.Errorf("invalid aggregate %q: must be one of latest, first, avg, max, min", s)
+}
+
+// ReplicaLabelFromEnv returns the value of PROMETHEUS_REPLICA_LABEL, or
+// DefaultReplicaLabel when it is unset.
+func ReplicaLabelFromEnv() string {
+	if l := os.Getenv("PROMETHEUS_REPLICA_LABEL"); l != "" {
+		return l
+	}
+	return DefaultReplicaLabel
+}
+
+// DeduplicateVector merges instant-query results from several replicas into
+// a single vector.
+//
+// Series are grouped by the fingerprint of all labels except replicaLabel;
+// each group is reduced to one sample according to agg, and the replica label
+// is dropped from the result. Groups are returned in order of first
+// appearance.
+func DeduplicateVector(results []model.Vector, replicaLabel string, agg Aggregate) model.Vector {
+	type group struct {
+		metric  model.Metric
+		samples []*model.Sample
+	}
+
+	var order []model.Fingerprint
+	groups := make(map[model.Fingerprint]*group)
+	for _, vector := range results {
+		for _, sample := range vector {
+			metric := sample.Metric.Clone()
+			delete(metric, model.LabelName(replicaLabel))
+			fp := metric.Fingerprint()
+
+			g, ok := groups[fp]
+			if !ok {
+				g = &group{metric: metric}
+				groups[fp] = g
+				order = append(order, fp)
+			}
+			g.samples = append(g.samples, sample)
+		}
+	}
+
+	out := make(model.Vector, 0, len(order))
+	for _, fp := range order {
+		g := groups[fp]
+		pair := aggregateSamples(g.samples, agg)
+		out = append(out, &model.Sample{
+			Metric:    g.metric,
+			Value:     pair.Value,
+			Timestamp: pair.Timestamp,
+		})
+	}
+	return out
+}
+
+// aggregateSamples reduces duplicate samples to one value and timestamp.
+// Every aggregate except AggregateAvg keeps both from a single chosen sample.
+func aggregateSamples(samples []*model.Sample, agg Aggregate) model.SamplePair {
+	var chosen *model.Sample
+	switch agg {
+	case AggregateFirst:
+		chosen = samples[0]
+	case AggregateAvg:
+		var sum model.SampleValue
+		for _, s := range samples {
+			sum += s.Value
+		}
+		return model.SamplePair{
+			Timestamp: latestSample(samples).Timestamp,
+			Value:     sum / model.SampleValue(len(samples)),
+		}
+	case AggregateMax, AggregateMin:
+		chosen = extremeSample(samples, agg == AggregateMax)
+	default:
+		chosen = latestSample(samples)
+	}
+	return model.SamplePair{Timestamp: chosen.Timestamp, Value: chosen.Value}
+}
+
+// extremeSample returns the sample with the highest value when highest is
+// set and the lowest otherwise, preferring the earliest one on ties. NaN
+// samples are skipped; when all of them are NaN the latest sample is returned.
+func extremeSample(samples []*model.Sample, highest bool) *model.Sample {
+	var best *model.Sample
+	for _, s := range samples {
+		if math.IsNaN(float64(s.Value)) {
+			continue
+		}
+		if best == nil || (highest && s.Value > best.Value) || (!highest && s.Value < best.Value) {
+			best = s
+		}
+	}
+	if best == nil {
+		return latestSample(samples)
+	}
+	return best
+}
+
+// latestSample returns the sample with the highest timestamp, preferring the
+// earliest one on ties.
+func latestSample(samples []*model.Sample) *model.Sample {
+	latest := samples[0]
+	for _, s := range samples[1:] {
+		if s.Timestamp > latest.Timestamp {
+			latest = s
+		}
+	}
+	return latest
+}
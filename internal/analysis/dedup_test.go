@@ -0,0 +1,151 @@
+package analysis
+
+import (
+	"math"
+	"testing"
+
+	"github.com/prometheus/common/model"
+)
+
+func replicaSample(replica string, value float64, ts int64) *model.Sample {
+	return &model.Sample{
+		Metric: model.Metric{
+			model.MetricNameLabel: "up",
+			"job":                 "api",
+			DefaultReplicaLabel:   model.LabelValue(replica),
+		},
+		Value:     model.SampleValue(value),
+		Timestamp: model.Time(ts),
+	}
+}
+
+func TestDeduplicateVectorCollapsesReplicas(t *testing.T) {
+	results := []model.Vector{
+		{replicaSample("a", 1, 1000)},
+		{replicaSample("b", 3, 2000)},
+	}
+
+	tests := []struct {
+		agg       Aggregate
+		wantValue model.SampleValue
+		wantTS    model.Time
+	}{
+		{AggregateLatest, 3, 2000},
+		{AggregateFirst, 1, 1000},
+		{AggregateAvg, 2, 2000},
+		{AggregateMax, 3, 2000},
+		{AggregateMin, 1, 1000},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.agg), func(t *testing.T) {
+			got := DeduplicateVector(results, DefaultReplicaLabel, tt.agg)
+			if len(got) != 1 {
+				t.Fatalf("expected 1 series, got %d: %v", len(got), got)
+			}
+			if _, ok := got[0].Metric[DefaultReplicaLabel]; ok {
+				t.Errorf("expected replica label to be dropped, got %v", got[0].Metric)
+			}
+			if got[0].Metric["job"] != "api" {
+				t.Errorf("expected other labels to be kept, got %v", got[0].Metric)
+			}
+			if got[0].Value != tt.wantValue || got[0].Timestamp != tt.wantTS {
+				t.Errorf("got value=%v ts=%v, want value=%v ts=%v", got[0].Value, got[0].Timestamp, tt.wantValue, tt.wantTS)
+			}
+		})
+	}
+}
+
+func TestDeduplicateVectorSkipsNaN(t *testing.T) {
+	tests := []struct {
+		name      string
+		results   []model.Vector
+		agg       Aggregate
+		wantValue float64
+		wantTS    model.Time
+	}{
+		{
+			name:      "max skips NaN",
+			results:   []model.Vector{{replicaSample("a", 2, 1000)}, {replicaSample("b", math.NaN(), 2000)}},
+			agg:       AggregateMax,
+			wantValue: 2,
+			wantTS:    1000,
+		},
+		{
+			name:      "min skips NaN",
+			results:   []model.Vector{{replicaSample("a", math.NaN(), 2000)}, {replicaSample("b", 2, 1000)}},
+			agg:       AggregateMin,
+			wantValue: 2,
+			wantTS:    1000,
+		},
+		{
+			name:      "all NaN keeps latest",
+			results:   []model.Vector{{replicaSample("a", math.NaN(), 1000)}, {replicaSample("b", math.NaN(), 2000)}},
+			agg:       AggregateMax,
+			wantValue: math.NaN(),
+			wantTS:    2000,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := DeduplicateVector(tt.results, DefaultReplicaLabel, tt.agg)
+			if len(got) != 1 {
+				t.Fatalf("expected 1 series, got %d: %v", len(got), got)
+			}
+			value := float64(got[0].Value)
+			sameValue := value == tt.wantValue || (math.IsNaN(value) && math.IsNaN(tt.wantValue))
+			if !sameValue || got[0].Timestamp != tt.wantTS {
+				t.Errorf("got value=%v ts=%v, want value=%v ts=%v", got[0].Value, got[0].Timestamp, tt.wantValue, tt.wantTS)
+			}
+		})
+	}
+}
+
+func TestDeduplicateVectorKeepsDistinctSeries(t *testing.T) {
+	other := replicaSample("b", 5, 1000)
+	other.Metric["job"] = "batch"
+
+	got := DeduplicateVector([]model.Vector{
+		{replicaSample("a", 1, 1000)},
+		{replicaSample("b", 1, 1000), other},
+	}, DefaultReplicaLabel, AggregateLatest)
+
+	if len(got) != 2 {
+		t.Fatalf("expected 2 series, got %d: %v", len(got), got)
+	}
+	if got[0].Metric["job"] != "api" || got[1].Metric["job"] != "batch" {
+		t.Errorf("expected order of first appearance, got %v", got)
+	}
+}
+
+func TestDeduplicateVectorDoesNotModifyInput(t *testing.T) {
+	in := replicaSample("a", 1, 1000)
+	DeduplicateVector([]model.Vector{{in}}, DefaultReplicaLabel, AggregateLatest)
+	if in.Metric[DefaultReplicaLabel] != "a" {
+		t.Error("input metric was modified")
+	}
+}
+
+func TestParseAggregate(t *testing.T) {
+	if a, err := ParseAggregate(""); err != nil || a != AggregateLatest {
+		t.Errorf("ParseAggregate(\"\") = %q, %v", a, err)
+	}
+	if a, err := ParseAggregate("max"); err != nil || a != AggregateMax {
+		t.Errorf("ParseAggregate(\"max\") = %q, %v", a, err)
+	}
+	if _, err := ParseAggregate("median"); err == nil {
+		t.Error("expected error for unknown aggregate")
+	}
+}
+
+func TestReplicaLabelFromEnv(t *testing.T) {
+	t.Setenv("PROMETHEUS_REPLICA_LABEL", "")
+	if got := ReplicaLabelFromEnv(); got != DefaultReplicaLabel {
+		t.Errorf("got %q, want %q", got, DefaultReplicaLabel)
+	}
+	t.Setenv("PROMETHEUS_REPLICA_LABEL", "replica")
+	if got := ReplicaLabelFromEnv(); got != "replica" {
+		t.Errorf("got %q, want replica", got)
+	}
+}
@@ -0,0 +1,24 @@
+// Package analysis post-processes query results returned by the Prometheus
+// HTTP API.
+//
+// Deduplication ([DeduplicateVector]) collapses series returned by several
+// Prometheus replicas into one series per label set, ignoring the replica
+// label. The label name defaults to [DefaultReplicaLabel] and can be
+// overridden with the PROMETHEUS_REPLICA_LABEL environment variable (see
+// [ReplicaLabelFromEnv]).
+//
+// Anomaly detection ([ComputeZScore]) scores samples of a comparison window
+// against the mean and standard deviation of a baseline window.
+//
+// Trend fitting ([LinearRegression]) fits a least-squares line through a
+// series; [ProjectLinear] extrapolates it.
+//
+// Range summaries use [Increase] for the reset-aware growth of a counter and
+// [BucketQuantile] to estimate quantiles of a classic histogram from its
+// cumulative buckets.
+//
+// [Downsample] reduces a series to a point budget while keeping its shape,
+// with the largest-triangle-three-buckets algorithm.
+//
+// Nothing in this package performs network I/O.
+package analysis
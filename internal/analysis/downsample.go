@@ -0,0 +1,79 @@
+package analysis
+
+import (
+	"math"
+
+	"github.com/prometheus/common/model"
+)
+
+// Downsample reduces points to at most maxPoints samples with the
+// largest-triangle-three-buckets algorithm: the first and last samples are
+// kept, the rest are split into maxPoints-2 buckets, and from each bucket the
+// sample forming the largest triangle with the previously kept sample and the
+// average of the next bucket is kept. Peaks, dips and steps survive, unlike
+// with averaging or taking every nth sample. points is returned unchanged
+// when it already fits or maxPoints is below 2. A NaN sample is only kept
+// when every sample of its bucket is NaN.
+func Downsample(points []model.SamplePair, maxPoints int) []model.SamplePair {
+	if maxPoints < 2 || len(points) <= maxPoints {
+		return points
+	}
+	if maxPoints == 2 {
+		return []model.SamplePair{points[0], points[len(points)-1]}
+	}
+
+	out := make([]model.SamplePair, 0, maxPoints)
+	out = append(out, points[0])
+
+	// Buckets cover points[1 : len-1]; bucketSize is fractional so the
+	// buckets differ in size by at most one sample.
+	bucketSize := float64(len(points)-2) / float64(maxPoints-2)
+	bucketStart := func(i int) int { return 1 + int(math.Floor(float64(i)*bucketSize)) }
+
+	prev := points[0]
+	for i := range maxPoints - 2 {
+		from, to := bucketStart(i), bucketStart(i+1)
+
+		// The third corner is the average of the next bucket, or the last
+		// sample for the final bucket.
+		nextFrom, nextTo := to, bucketStart(i+2)
+		if i == maxPoints-3 {
+			nextFrom, nextTo = len(points)-1, len(points)
+		}
+		avgX, avgY := averagePoint(points[nextFrom:nextTo])
+
+		chosen, maxArea := from, -1.0
+		prevX, prevY := unixSeconds(prev.Timestamp), float64(prev.Value)
+		if math.IsNaN(prevY) {
+			prevY = avgY
+		}
+		for j := from; j < to; j++ {
+			x, y := unixSeconds(points[j].Timestamp), float64(points[j].Value)
+			area := math.Abs((prevX-avgX)*(y-prevY) - (prevX-x)*(avgY-prevY))
+			if area > maxArea {
+				chosen, maxArea = j, area
+			}
+		}
+		prev = points[chosen]
+		out = append(out, prev)
+	}
+	return append(out, points[len(points)-1])
+}
+
+// averagePoint returns the mean timestamp in Unix seconds and the mean value
+// of points, skipping NaN values.
+func averagePoint(points []model.SamplePair) (x, y float64) {
+	var n int
+	for _, p := range points {
+		x += unixSeconds(p.Timestamp)
+		if !math.IsNaN(float64(p.Value)) {
+			y += float64(p.Value)
+			n++
+		}
+	}
+	x /= float64(len(points))
+	if n > 0 {
+		y /= float64(n)
+	}
+	return x, y
+}
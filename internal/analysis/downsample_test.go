@@ -0,0 +1,54 @@
+package analysis
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/prometheus/common/model"
+)
+
+func TestDownsample(t *testing.T) {
+	start := model.Time(1704067200000)
+	var points []model.SamplePair
+	for i := range 1000 {
+		points = append(points, model.SamplePair{Timestamp: start.Add(time.Duration(i) * 15 * time.Second), Value: 1})
+	}
+	points[500].Value = 100 // a spike
+	points[700].Value = -50 // a dip
+	points[300].Value = model.SampleValue(math.NaN())
+
+	got := Downsample(points, 50)
+	if len(got) != 50 {
+		t.Fatalf("len = %d, want 50", len(got))
+	}
+	if got[0] != points[0] || got[49] != points[999] {
+		t.Errorf("first and last samples not kept: %v, %v", got[0], got[49])
+	}
+	var spike, dip bool
+	for i, p := range got {
+		if i > 0 && p.Timestamp <= got[i-1].Timestamp {
+			t.Fatalf("timestamps not increasing at %d", i)
+		}
+		if math.IsNaN(float64(p.Value)) {
+			t.Errorf("NaN sample kept at %s", p.Timestamp)
+		}
+		spike = spike || p.Value == 100
+		dip = dip || p.Value == -50
+	}
+	if !spike || !dip {
+		t.Errorf("spike kept = %t, dip kept = %t; want both", spike, dip)
+	}
+}
+
+func TestDownsampleUnchanged(t *testing.T) {
+	points := pairs(1, 2, 3, 4)
+	for _, maxPoints := range []int{0, 1, 4, 10} {
+		if got := Downsample(points, maxPoints); len(got) != len(points) {
+			t.Errorf("Downsample(%d) returned %d points, want %d", maxPoints, len(got), len(points))
+		}
+	}
+	if got := Downsample(points, 2); len(got) != 2 || got[0] != points[0] || got[1] != points[3] {
+		t.Errorf("Downsample(2) = %v, want the first and last sample", got)
+	}
+}
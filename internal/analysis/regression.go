@@ -0,0 +1,68 @@
+package analysis
+
+import (
+	"math"
+
+	"github.com/prometheus/common/model"
+)
+
+// PoorFitR2 is the coefficient of determination below which a linear trend
+// explains too little of a series to be relied on.
+const PoorFitR2 = 0.5
+
+// LinearRegression fits value = slope*t + intercept by least squares, with t
+// in Unix seconds, so slope is in units per second. r2 is the coefficient of
+// determination; it is 1 for a constant series, which the flat line fits
+// exactly. NaN samples are skipped. ok is false when fewer than two usable
+// samples remain or they all share one timestamp.
+func LinearRegression(points []model.SamplePair) (slope, intercept, r2 float64, ok bool) {
+	var sumX, sumY float64
+	var n int
+	for _, p := range points {
+		if math.IsNaN(float64(p.Value)) {
+			continue
+		}
+		sumX += unixSeconds(p.Timestamp)
+		sumY += float64(p.Value)
+		n++
+	}
+	if n < 2 {
+		return 0, 0, 0, false
+	}
+	meanX, meanY := sumX/float64(n), sumY/float64(n)
+
+	// Centring on the means keeps the sums small: raw Unix timestamps
+	// squared would lose precision.
+	var sxx, sxy, syy float64
+	for _, p := range points {
+		y := float64(p.Value)
+		if math.IsNaN(y) {
+			continue
+		}
+		dx, dy := unixSeconds(p.Timestamp)-meanX, y-meanY
+		sxx += dx * dx
+		sxy += dx * dy
+		syy += dy * dy
+	}
+	if sxx == 0 {
+		return 0, 0, 0, false
+	}
+
+	slope = sxy / sxx
+	intercept = meanY - slope*meanX
+	r2 = 1.0
+	if syy != 0 {
+		r2 = sxy * sxy / (sxx * syy)
+	}
+	return slope, intercept, r2, true
+}
+
+// ProjectLinear evaluates the line slope*t + intercept at t.
+func ProjectLinear(slope, intercept float64, t model.Time) float64 {
+	return slope*unixSeconds(t) + intercept
+}
+
+// unixSeconds converts a Prometheus timestamp to fractional Unix seconds.
+func unixSeconds(t model.Time) float64 {
+	return float64(t) / 1000
+}
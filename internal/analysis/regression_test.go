@@ -0,0 +1,61 @@
+package analysis
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/prometheus/common/model"
+)
+
+func TestLinearRegression(t *testing.T) {
+	// value = 0.25/s * t + 10, sampled every 15s from a realistic Unix time.
+	start := model.Time(1704067200000)
+	var points []model.SamplePair
+	for i := range 40 {
+		ts := start.Add(time.Duration(15*i) * time.Second)
+		points = append(points, model.SamplePair{Timestamp: ts, Value: model.SampleValue(0.25*float64(ts)/1000 + 10)})
+	}
+	points = append(points, model.SamplePair{Timestamp: start.Add(600 * time.Second), Value: model.SampleValue(math.NaN())})
+
+	slope, intercept, r2, ok := LinearRegression(points)
+	if !ok {
+		t.Fatal("LinearRegression() not ok")
+	}
+	if math.Abs(slope-0.25) > 1e-9 {
+		t.Errorf("slope = %v, want 0.25", slope)
+	}
+	if math.Abs(r2-1) > 1e-9 {
+		t.Errorf("r2 = %v, want 1", r2)
+	}
+	end := start.Add(time.Duration(15*39) * time.Second)
+	want := 0.25*float64(end.Add(time.Hour))/1000 + 10
+	if got := ProjectLinear(slope, intercept, end.Add(time.Hour)); math.Abs(got-want) > 1e-3 {
+		t.Errorf("ProjectLinear() = %v, want %v", got, want)
+	}
+}
+
+func TestLinearRegressionNoisy(t *testing.T) {
+	// Alternating values around a flat line: slope 0-ish, poor fit.
+	slope, _, r2, ok := LinearRegression(pairs(1, 9, 1, 9, 1, 9, 1, 9))
+	if !ok {
+		t.Fatal("LinearRegression() not ok")
+	}
+	if r2 >= PoorFitR2 {
+		t.Errorf("r2 = %v, want < %v (slope %v)", r2, PoorFitR2, slope)
+	}
+}
+
+func TestLinearRegressionDegenerate(t *testing.T) {
+	if _, _, _, ok := LinearRegression(pairs(5)); ok {
+		t.Error("single sample should not fit")
+	}
+	same := []model.SamplePair{{Timestamp: 1000, Value: 1}, {Timestamp: 1000, Value: 2}}
+	if _, _, _, ok := LinearRegression(same); ok {
+		t.Error("samples sharing one timestamp should not fit")
+	}
+	slope, intercept, r2, ok := LinearRegression(pairs(7, 7, 7))
+	if !ok || slope != 0 || intercept != 7 || r2 != 1 {
+		t.Errorf("constant series: slope=%v intercept=%v r2=%v ok=%v", slope, intercept, r2, ok)
+	}
+}
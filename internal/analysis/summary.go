@@ -0,0 +1,97 @@
+package analysis
+
+import (
+	"math"
+	"slices"
+
+	"github.com/prometheus/common/model"
+)
+
+// Increase returns how much a counter grew over points, treating any drop
+// as a counter reset, like PromQL's increase() without extrapolation. NaN
+// samples are skipped.
+func Increase(points []model.SamplePair) float64 {
+	var total, prev float64
+	first := true
+	for _, p := range points {
+		v := float64(p.Value)
+		if math.IsNaN(v) {
+			continue
+		}
+		switch {
+		case first:
+			first = false
+		case v < prev:
+			total += v
+		default:
+			total += v - prev
+		}
+		prev = v
+	}
+	return total
+}
+
+// Bucket is one cumulative bucket of a classic histogram: Count
+// observations were at most UpperBound.
+type Bucket struct {
+	UpperBound float64
+	Count      float64
+}
+
+// BucketQuantile estimates the q-quantile (0 <= q <= 1) of a classic
+// histogram the way PromQL's histogram_quantile() does: it finds the bucket
+// holding the rank and interpolates linearly inside it, treating the lower
+// bound of the first bucket as 0 when its upper bound is positive. A rank in
+// the +Inf bucket returns the highest finite upper bound. It returns NaN
+// when there is no +Inf bucket or no observation.
+func BucketQuantile(q float64, buckets []Bucket) float64 {
+	if q < 0 || q > 1 || len(buckets) == 0 {
+		return math.NaN()
+	}
+	buckets = slices.Clone(buckets)
+	slices.SortFunc(buckets, func(a, b Bucket) int {
+		switch {
+		case a.UpperBound < b.UpperBound:
+			return -1
+		case a.UpperBound > b.UpperBound:
+			return 1
+		}
+		return 0
+	})
+	last := buckets[len(buckets)-1]
+	if !math.IsInf(last.UpperBound, 1) || last.Count <= 0 {
+		return math.NaN()
+	}
+	// Scrapes are not atomic, so counts can decrease with the bound;
+	// enforce monotonicity like PromQL.
+	for i := 1; i < len(buckets); i++ {
+		buckets[i].Count = max(buckets[i].Count, buckets[i-1].Count)
+	}
+
+	rank := q * last.Count
+	i, _ := slices.BinarySearchFunc(buckets, rank, func(b Bucket, rank float64) int {
+		if b.Count < rank {
+			return -1
+		}
+		return 1
+	})
+	if i == len(buckets)-1 {
+		if len(buckets) == 1 {
+			return math.NaN()
+		}
+		return buckets[len(buckets)-2].UpperBound
+	}
+	if i == 0 && buckets[0].UpperBound <= 0 {
+		return buckets[0].UpperBound
+	}
+
+	lower, below := 0.0, 0.0
+	if i > 0 {
+		lower, below = buckets[i-1].UpperBound, buckets[i-1].Count
+	}
+	inBucket := buckets[i].Count - below
+	if inBucket == 0 {
+		return buckets[i].UpperBound
+	}
+	return lower + (buckets[i].UpperBound-lower)*(rank-below)/inBucket
+}
@@ -0,0 +1,68 @@
+package analysis
+
+import (
+	"math"
+	"testing"
+)
+
+func TestIncrease(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []float64
+		want   float64
+	}{
+		{name: "monotonic", values: []float64{10, 15, 25}, want: 15},
+		{name: "reset", values: []float64{10, 20, 5, 8}, want: 18},
+		{name: "NaN skipped", values: []float64{1, math.NaN(), 4}, want: 3},
+		{name: "single sample", values: []float64{7}, want: 0},
+		{name: "empty", want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Increase(pairs(tt.values...)); got != tt.want {
+				t.Errorf("Increase() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBucketQuantile(t *testing.T) {
+	// 100 observations: 50 at most 0.1, 90 at most 0.5, all at most 1.
+	buckets := []Bucket{
+		{UpperBound: math.Inf(1), Count: 100},
+		{UpperBound: 0.5, Count: 90},
+		{UpperBound: 0.1, Count: 50},
+		{UpperBound: 1, Count: 100},
+	}
+	tests := []struct {
+		q    float64
+		want float64
+	}{
+		{q: 0.5, want: 0.1},
+		{q: 0.25, want: 0.05},
+		{q: 0.7, want: 0.3},
+		{q: 0.95, want: 0.75},
+		{q: 1, want: 1},
+	}
+	for _, tt := range tests {
+		if got := BucketQuantile(tt.q, buckets); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("BucketQuantile(%v) = %v, want %v", tt.q, got, tt.want)
+		}
+	}
+
+	// A rank in the +Inf bucket returns the highest finite bound.
+	tail := []Bucket{{UpperBound: 1, Count: 10}, {UpperBound: math.Inf(1), Count: 20}}
+	if got := BucketQuantile(0.99, tail); got != 1 {
+		t.Errorf("BucketQuantile(0.99) in +Inf bucket = %v, want 1", got)
+	}
+
+	for name, b := range map[string][]Bucket{
+		"no +Inf bucket":   {{UpperBound: 1, Count: 10}},
+		"no observations":  {{UpperBound: 1, Count: 0}, {UpperBound: math.Inf(1), Count: 0}},
+		"only +Inf bucket": {{UpperBound: math.Inf(1), Count: 5}},
+	} {
+		if got := BucketQuantile(0.5, b); !math.IsNaN(got) {
+			t.Errorf("%s: BucketQuantile() = %v, want NaN", name, got)
+		}
+	}
+}
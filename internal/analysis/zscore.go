@@ -0,0 +1,107 @@
+package analysis
+
+import (
+	"math"
+
+	"github.com/prometheus/common/model"
+)
+
+// DefaultZScoreThreshold is the absolute z-score above which a sample is
+// considered anomalous when callers do not choose a threshold.
+const DefaultZScoreThreshold = 3.0
+
+// Direction of an anomalous deviation from the baseline mean.
+const (
+	DirectionSpike = "spike"
+	DirectionDrop  = "drop"
+)
+
+// AnomalyEvent is the z-score of one comparison sample against a baseline.
+type AnomalyEvent struct {
+	Timestamp model.Time
+	Value     float64
+	// ZScore is (Value-mean)/stddev of the baseline. It is ±Inf when the
+	// baseline is constant and Value differs from it.
+	ZScore    float64
+	Direction string
+}
+
+// Baseline holds the mean and population standard deviation of a series.
+type Baseline struct {
+	Mean   float64
+	StdDev float64
+}
+
+// NewBaseline computes the mean and population standard deviation of the
+// sample values, ignoring NaNs. ok is false if no usable samples remain.
+func NewBaseline(samples []model.SamplePair) (b Baseline, ok bool) {
+	var sum float64
+	var n int
+	for _, s := range samples {
+		v := float64(s.Value)
+		if math.IsNaN(v) {
+			continue
+		}
+		sum += v
+		n++
+	}
+	if n == 0 {
+		return Baseline{}, false
+	}
+	b.Mean = sum / float64(n)
+
+	var sq float64
+	for _, s := range samples {
+		v := float64(s.Value)
+		if math.IsNaN(v) {
+			continue
+		}
+		sq += (v - b.Mean) * (v - b.Mean)
+	}
+	b.StdDev = math.Sqrt(sq / float64(n))
+	return b, true
+}
+
+// ComputeZScore scores every comparison sample against the mean and standard
+// deviation of baseline. It returns nil when baseline has no usable samples.
+// NaN comparison samples are skipped.
+func ComputeZScore(baseline, comparison []model.SamplePair) []AnomalyEvent {
+	b, ok := NewBaseline(baseline)
+	if !ok {
+		return nil
+	}
+
+	events := make([]AnomalyEvent, 0, len(comparison))
+	for _, s := range comparison {
+		v := float64(s.Value)
+		if math.IsNaN(v) {
+			continue
+		}
+		var z float64
+		switch {
+		case b.StdDev > 0:
+			z = (v - b.Mean) / b.StdDev
+		case v > b.Mean:
+			z = math.Inf(1)
+		case v < b.Mean:
+			z = math.Inf(-1)
+		}
+		direction := DirectionSpike
+		if z < 0 {
+			direction = DirectionDrop
+		}
+		events = append(events, AnomalyEvent{Timestamp: s.Timestamp, Value: v, ZScore: z, Direction: direction})
+	}
+	return events
+}
+
+// PeakEvent returns the event with the largest absolute z-score. ok is false
+// for an empty slice.
+func PeakEvent(events []AnomalyEvent) (peak AnomalyEvent, ok bool) {
+	for i, e := range events {
+		if i == 0 || math.Abs(e.ZScore) > math.Abs(peak.ZScore) {
+			peak = e
+		}
+	}
+	return peak, len(events) > 0
+}
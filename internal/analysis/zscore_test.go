@@ -0,0 +1,65 @@
+package analysis
+
+import (
+	"math"
+	"testing"
+
+	"github.com/prometheus/common/model"
+)
+
+func pairs(values ...float64) []model.SamplePair {
+	out := make([]model.SamplePair, len(values))
+	for i, v := range values {
+		out[i] = model.SamplePair{Timestamp: model.Time(int64(i) * 60_000), Value: model.SampleValue(v)}
+	}
+	return out
+}
+
+func TestComputeZScore(t *testing.T) {
+	// Baseline mean 10, population stddev 2.
+	baseline := pairs(8, 12, 8, 12)
+	comparison := pairs(10, 18, 4)
+
+	events := ComputeZScore(baseline, comparison)
+	if len(events) != 3 {
+		t.Fatalf("expected 3 events, got %d", len(events))
+	}
+
+	want := []struct {
+		z         float64
+		direction string
+	}{
+		{0, DirectionSpike},
+		{4, DirectionSpike},
+		{-3, DirectionDrop},
+	}
+	for i, w := range want {
+		if math.Abs(events[i].ZScore-w.z) > 1e-9 || events[i].Direction != w.direction {
+			t.Errorf("event %d: got z=%v %s, want z=%v %s", i, events[i].ZScore, events[i].Direction, w.z, w.direction)
+		}
+	}
+
+	peak, ok := PeakEvent(events)
+	if !ok || peak.Value != 18 || peak.Timestamp != 60_000 {
+		t.Errorf("PeakEvent() = %+v, %v", peak, ok)
+	}
+}
+
+func TestComputeZScoreConstantBaseline(t *testing.T) {
+	events := ComputeZScore(pairs(5, 5, 5), pairs(5, 6, 4))
+	if events[0].ZScore != 0 || !math.IsInf(events[1].ZScore, 1) || !math.IsInf(events[2].ZScore, -1) {
+		t.Errorf("unexpected z-scores for constant baseline: %+v", events)
+	}
+}
+
+func TestComputeZScoreEmptyBaseline(t *testing.T) {
+	if events := ComputeZScore(nil, pairs(1)); events != nil {
+		t.Errorf("expected nil, got %+v", events)
+	}
+	if events := ComputeZScore(pairs(math.NaN()), pairs(1)); events != nil {
+		t.Errorf("expected nil for all-NaN baseline, got %+v", events)
+	}
+	if _, ok := PeakEvent(nil); ok {
+		t.Error("expected PeakEvent(nil) to report !ok")
+	}
+}
@@ -0,0 +1,11 @@
+// Package chart draws time series as PNG line charts for MCP image content.
+//
+// [LinePNG] plots every series in its own colour from [Palette] over a shared
+// time axis, with horizontal grid lines labelled with their values and the
+// start, middle and end of the time range below the plot. Labels use a
+// built-in bitmap font holding only digits and the few symbols numbers and
+// times need, so the package has no font dependency; series names are left
+// to the caller, which lists them next to the image with [ColorName].
+//
+// Nothing in this package performs network I/O.
+package chart
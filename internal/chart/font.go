@@ -0,0 +1,57 @@
+package chart
+
+import "image"
+
+// Glyphs are 3×5 pixel bitmaps drawn glyphScale times enlarged, with one
+// enlarged pixel between characters.
+const (
+	glyphWidth   = 3
+	glyphHeight  = 5
+	glyphScale   = 2
+	glyphAdvance = (glyphWidth + 1) * glyphScale
+)
+
+// glyphs holds one row string per pixel row; characters without a glyph are
+// drawn as blanks.
+var glyphs = map[rune][glyphHeight]string{
+	'0': {"###", "#.#", "#.#", "#.#", "###"},
+	'1': {".#.", "##.", ".#.", ".#.", "###"},
+	'2': {"###", "..#", "###", "#..", "###"},
+	'3': {"###", "..#", "###", "..#", "###"},
+	'4': {"#.#", "#.#", "###", "..#", "..#"},
+	'5': {"###", "#..", "###", "..#", "###"},
+	'6': {"###", "#..", "###", "#.#", "###"},
+	'7': {"###", "..#", "..#", "..#", "..#"},
+	'8': {"###", "#.#", "###", "#.#", "###"},
+	'9': {"###", "#.#", "###", "..#", "###"},
+	'.': {"...", "...", "...", "...", ".#."},
+	'-': {"...", "...", "###", "...", "..."},
+	'+': {"...", ".#.", "###", ".#.", "..."},
+	':': {"...", ".#.", "...", ".#.", "..."},
+	'e': {"...", ".##", "###", "#..", ".##"},
+}
+
+// textWidth returns the width in pixels drawText uses for s.
+func textWidth(s string) int {
+	return len([]rune(s))*glyphAdvance - glyphScale
+}
+
+// drawText draws s with its top left corner at (x, y) in labelColor.
+func drawText(img *image.RGBA, x, y int, s string) {
+	for _, r := range s {
+		g := glyphs[r]
+		for row, bits := range g {
+			for col, bit := range bits {
+				if bit != '#' {
+					continue
+				}
+				for dy := range glyphScale {
+					for dx := range glyphScale {
+						img.SetRGBA(x+col*glyphScale+dx, y+row*glyphScale+dy, labelColor)
+					}
+				}
+			}
+		}
+		x += glyphAdvance
+	}
+}
@@ -0,0 +1,214 @@
+package chart
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/common/model"
+)
+
+// Default chart size in pixels.
+const (
+	DefaultWidth  = 800
+	DefaultHeight = 400
+)
+
+// Plot area margins in pixels; the left margin holds the value labels and
+// the bottom margin the time labels.
+const (
+	marginLeft   = 90
+	marginRight  = 16
+	marginTop    = 12
+	marginBottom = 30
+
+	gridLines = 5
+)
+
+var (
+	background = color.RGBA{0xff, 0xff, 0xff, 0xff}
+	gridColor  = color.RGBA{0xe0, 0xe0, 0xe0, 0xff}
+	axisColor  = color.RGBA{0x60, 0x60, 0x60, 0xff}
+	labelColor = color.RGBA{0x30, 0x30, 0x30, 0xff}
+)
+
+// NamedColor is a series colour and the name a legend refers to it by.
+type NamedColor struct {
+	Name  string
+	Color color.RGBA
+}
+
+// Palette holds the series colours in order; series beyond its length reuse
+// it from the start.
+var Palette = []NamedColor{
+	{"blue", color.RGBA{0x1f, 0x77, 0xb4, 0xff}},
+	{"orange", color.RGBA{0xff, 0x7f, 0x0e, 0xff}},
+	{"green", color.RGBA{0x2c, 0xa0, 0x2c, 0xff}},
+	{"red", color.RGBA{0xd6, 0x27, 0x28, 0xff}},
+	{"purple", color.RGBA{0x94, 0x67, 0xbd, 0xff}},
+	{"brown", color.RGBA{0x8c, 0x56, 0x4b, 0xff}},
+	{"pink", color.RGBA{0xe3, 0x77, 0xc2, 0xff}},
+	{"grey", color.RGBA{0x7f, 0x7f, 0x7f, 0xff}},
+	{"olive", color.RGBA{0xbc, 0xbd, 0x22, 0xff}},
+	{"cyan", color.RGBA{0x17, 0xbe, 0xcf, 0xff}},
+}
+
+// ColorName returns the name of the colour LinePNG draws series i in.
+func ColorName(i int) string {
+	return Palette[i%len(Palette)].Name
+}
+
+// LinePNG draws series as a width×height PNG line chart. Consecutive samples
+// of a series are joined by lines; NaN and infinite values break the line.
+// It fails when no series has a finite sample or the size leaves no room for
+// the plot.
+func LinePNG(series [][]model.SamplePair, width, height int) ([]byte, error) {
+	plot := image.Rect(marginLeft, marginTop, width-marginRight, height-marginBottom)
+	if plot.Dx() < 10 || plot.Dy() < 10 {
+		return nil, errors.New("chart size too small")
+	}
+
+	var first, last model.Time
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for _, s := range series {
+		for _, p := range s {
+			v := float64(p.Value)
+			if math.IsNaN(v) || math.IsInf(v, 0) {
+				continue
+			}
+			if math.IsInf(lo, 1) || p.Timestamp < first {
+				first = p.Timestamp
+			}
+			last = max(last, p.Timestamp)
+			lo, hi = min(lo, v), max(hi, v)
+		}
+	}
+	if lo > hi {
+		return nil, errors.New("no finite samples to chart")
+	}
+	if lo == hi {
+		pad := max(math.Abs(lo)*0.1, 1)
+		lo, hi = lo-pad, hi+pad
+	}
+	if first == last {
+		first, last = first-1000, last+1000
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(img, img.Bounds(), image.NewUniform(background), image.Point{}, draw.Src)
+
+	x := func(t model.Time) int {
+		return plot.Min.X + int(math.Round(float64(t-first)/float64(last-first)*float64(plot.Dx()-1)))
+	}
+	y := func(v float64) int {
+		return plot.Max.Y - 1 - int(math.Round((v-lo)/(hi-lo)*float64(plot.Dy()-1)))
+	}
+
+	for i := range gridLines {
+		v := lo + (hi-lo)*float64(i)/float64(gridLines-1)
+		gy := y(v)
+		for gx := plot.Min.X; gx < plot.Max.X; gx++ {
+			img.Set(gx, gy, gridColor)
+		}
+		label := strconv.FormatFloat(v, 'g', 4, 64)
+		drawText(img, plot.Min.X-8-textWidth(label), gy-glyphHeight*glyphScale/2, label)
+	}
+	for gx := plot.Min.X; gx < plot.Max.X; gx++ {
+		img.Set(gx, plot.Max.Y-1, axisColor)
+	}
+	for gy := plot.Min.Y; gy < plot.Max.Y; gy++ {
+		img.Set(plot.Min.X, gy, axisColor)
+	}
+
+	layout := "15:04"
+	if last.Sub(first) > 24*time.Hour {
+		layout = "01-02 15:04"
+	}
+	labelY := plot.Max.Y + 8
+	for i, t := range []model.Time{first, first + (last-first)/2, last} {
+		label := t.Time().UTC().Format(layout)
+		lx := x(t) - textWidth(label)/2
+		switch i {
+		case 0:
+			lx = plot.Min.X
+		case 2:
+			lx = plot.Max.X - textWidth(label)
+		}
+		drawText(img, lx, labelY, label)
+	}
+
+	for i, s := range series {
+		c := Palette[i%len(Palette)].Color
+		var prev image.Point
+		joined := false
+		for _, p := range s {
+			v := float64(p.Value)
+			if math.IsNaN(v) || math.IsInf(v, 0) {
+				joined = false
+				continue
+			}
+			pt := image.Pt(x(p.Timestamp), y(v))
+			if joined {
+				drawLine(img, prev, pt, c)
+			} else {
+				drawLine(img, pt, pt, c)
+			}
+			prev, joined = pt, true
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// drawLine draws a two pixel thick line from a to b with Bresenham's
+// algorithm.
+func drawLine(img *image.RGBA, a, b image.Point, c color.RGBA) {
+	dx, dy := abs(b.X-a.X), -abs(b.Y-a.Y)
+	sx, sy := sign(b.X-a.X), sign(b.Y-a.Y)
+	e := dx + dy
+	for {
+		img.SetRGBA(a.X, a.Y, c)
+		img.SetRGBA(a.X+1, a.Y, c)
+		img.SetRGBA(a.X, a.Y+1, c)
+		if a == b {
+			return
+		}
+		e2 := 2 * e
+		if e2 >= dy {
+			e += dy
+			a.X += sx
+		}
+		if e2 <= dx {
+			e += dx
+			a.Y += sy
+		}
+	}
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+func sign(n int) int {
+	switch {
+	case n < 0:
+		return -1
+	case n > 0:
+		return 1
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,97 @@
+package chart
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"math"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/prometheus/common/model"
+)
+
+func series(start model.Time, values ...float64) []model.SamplePair {
+	out := make([]model.SamplePair, len(values))
+	for i, v := range values {
+		out[i] = model.SamplePair{Timestamp: start.Add(time.Duration(i) * time.Minute), Value: model.SampleValue(v)}
+	}
+	return out
+}
+
+// countColor returns the number of pixels of img drawn in palette colour i.
+func countColor(img image.Image, i int) int {
+	want := Palette[i].Color
+	n := 0
+	b := img.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			r, g, bl, _ := img.At(x, y).RGBA()
+			if uint8(r>>8) == want.R && uint8(g>>8) == want.G && uint8(bl>>8) == want.B {
+				n++
+			}
+		}
+	}
+	return n
+}
+
+func TestLinePNG(t *testing.T) {
+	start := model.Time(1704067200000)
+	data, err := LinePNG([][]model.SamplePair{
+		series(start, 1, 5, 3, math.NaN(), 8, 2),
+		series(start, 4, 4, 4, 4, 4, 4),
+	}, DefaultWidth, DefaultHeight)
+	if err != nil {
+		t.Fatalf("LinePNG: %v", err)
+	}
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("output is not a PNG: %v", err)
+	}
+	if got := img.Bounds().Size(); got != image.Pt(DefaultWidth, DefaultHeight) {
+		t.Errorf("size = %v, want %dx%d", got, DefaultWidth, DefaultHeight)
+	}
+	for i := range 2 {
+		if n := countColor(img, i); n < 100 {
+			t.Errorf("series %d (%s) drawn with %d pixels, want a line", i, ColorName(i), n)
+		}
+	}
+	if n := countColor(img, 2); n != 0 {
+		t.Errorf("unused colour %s drawn with %d pixels", ColorName(2), n)
+	}
+}
+
+func TestLinePNGErrors(t *testing.T) {
+	start := model.Time(1704067200000)
+	if _, err := LinePNG([][]model.SamplePair{series(start, math.NaN(), math.Inf(1))}, DefaultWidth, DefaultHeight); err == nil {
+		t.Error("expected an error without finite samples")
+	}
+	if _, err := LinePNG([][]model.SamplePair{series(start, 1)}, 50, 50); err == nil {
+		t.Error("expected an error for a chart too small to plot in")
+	}
+	// A single sample is drawn as a point.
+	if _, err := LinePNG([][]model.SamplePair{series(start, 1)}, DefaultWidth, DefaultHeight); err != nil {
+		t.Errorf("single sample: %v", err)
+	}
+}
+
+func TestGlyphsCoverLabels(t *testing.T) {
+	labels := []string{time.Now().UTC().Format("01-02 15:04")}
+	for _, v := range []float64{-1.5e-7, 0, 0.25, 1234.5678, 9.87e+12} {
+		labels = append(labels, strconv.FormatFloat(v, 'g', 4, 64))
+	}
+	for _, label := range labels {
+		for _, r := range label {
+			if _, ok := glyphs[r]; !ok && r != ' ' {
+				t.Errorf("no glyph for %q in label %q", r, label)
+			}
+		}
+	}
+}
+
+func TestColorName(t *testing.T) {
+	if ColorName(0) != "blue" || ColorName(len(Palette)) != "blue" || ColorName(3) != "red" {
+		t.Errorf("ColorName() = %s, %s, %s", ColorName(0), ColorName(len(Palette)), ColorName(3))
+	}
+}
@@ -0,0 +1,8 @@
+// Package filter matches metric names against simple glob patterns.
+//
+// A pattern is a literal name or contains '*' wildcards, each matching any
+// run of characters: "go_*" matches by prefix, "*_seconds" by suffix and
+// "*scrape*" by substring. [ParsePatterns] reads the comma-separated form
+// used by PROMETHEUS_EXCLUDED_METRICS and [Exclude] drops matching names
+// from a list.
+package filter
@@ -0,0 +1,67 @@
+package filter
+
+import "strings"
+
+// ParsePatterns splits a comma-separated pattern list, trimming spaces and
+// dropping empty entries.
+func ParsePatterns(s string) []string {
+	var patterns []string
+	for p := range strings.SplitSeq(s, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			patterns = append(patterns, p)
+		}
+	}
+	return patterns
+}
+
+// Match reports whether name matches pattern. Without a '*' the pattern must
+// equal the name. Otherwise the text before the first '*' must be a prefix
+// of name, the text after the last '*' a suffix, and the parts between them
+// must appear in order in the rest.
+func Match(pattern, name string) bool {
+	parts := strings.Split(pattern, "*")
+	if len(parts) == 1 {
+		return pattern == name
+	}
+
+	prefix, suffix := parts[0], parts[len(parts)-1]
+	if !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name[len(prefix):], suffix) {
+		return false
+	}
+	rest := name[len(prefix) : len(name)-len(suffix)]
+	for _, infix := range parts[1 : len(parts)-1] {
+		i := strings.Index(rest, infix)
+		if i < 0 {
+			return false
+		}
+		rest = rest[i+len(infix):]
+	}
+	return true
+}
+
+// MatchAny reports whether name matches any of patterns.
+func MatchAny(patterns []string, name string) bool {
+	for _, p := range patterns {
+		if Match(p, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// Exclude returns the names that match none of patterns, in their original
+// order, and the number of names dropped.
+func Exclude(names []string, patterns []string) (kept []string, excluded int) {
+	if len(patterns) == 0 {
+		return names, 0
+	}
+	kept = make([]string, 0, len(names))
+	for _, name := range names {
+		if MatchAny(patterns, name) {
+			excluded++
+			continue
+		}
+		kept = append(kept, name)
+	}
+	return kept, excluded
+}
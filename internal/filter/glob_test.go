@@ -0,0 +1,69 @@
+package filter
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		pattern string
+		name    string
+		want    bool
+	}{
+		// Literal names.
+		{pattern: "up", name: "up", want: true},
+		{pattern: "up", name: "upstream_requests_total", want: false},
+
+		// Trailing '*': prefix match.
+		{pattern: "go_*", name: "go_goroutines", want: true},
+		{pattern: "go_*", name: "go_", want: true},
+		{pattern: "go_*", name: "cargo_builds", want: false},
+
+		// Leading '*': suffix match.
+		{pattern: "*_seconds", name: "scrape_duration_seconds", want: true},
+		{pattern: "*_seconds", name: "scrape_duration_seconds_count", want: false},
+
+		// '*' on both sides: substring match.
+		{pattern: "*scrape*", name: "scrape_duration_seconds", want: true},
+		{pattern: "*scrape*", name: "prometheus_target_scrapes_total", want: true},
+		{pattern: "*scrape*", name: "node_cpu_seconds_total", want: false},
+
+		// '*' in the middle: prefix and suffix.
+		{pattern: "http_*_total", name: "http_requests_total", want: true},
+		{pattern: "http_*_total", name: "http_total", want: false},
+		{pattern: "a*b*c", name: "axxbyyc", want: true},
+		{pattern: "a*b*c", name: "acb", want: false},
+
+		{pattern: "*", name: "anything", want: true},
+	}
+	for _, tt := range tests {
+		if got := Match(tt.pattern, tt.name); got != tt.want {
+			t.Errorf("Match(%q, %q) = %v, want %v", tt.pattern, tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestParsePatterns(t *testing.T) {
+	got := ParsePatterns(" up, go_* ,,*_bucket ")
+	want := []string{"up", "go_*", "*_bucket"}
+	if !slices.Equal(got, want) {
+		t.Errorf("ParsePatterns() = %q, want %q", got, want)
+	}
+	if got := ParsePatterns(""); got != nil {
+		t.Errorf("ParsePatterns(\"\") = %q, want nil", got)
+	}
+}
+
+func TestExclude(t *testing.T) {
+	names := []string{"go_goroutines", "http_requests_total", "scrape_duration_seconds", "up"}
+	kept, excluded := Exclude(names, []string{"go_*", "*_seconds", "up"})
+	if !slices.Equal(kept, []string{"http_requests_total"}) || excluded != 3 {
+		t.Errorf("Exclude() = %q, %d; want [http_requests_total], 3", kept, excluded)
+	}
+
+	kept, excluded = Exclude(names, nil)
+	if !slices.Equal(kept, names) || excluded != 0 {
+		t.Errorf("Exclude() without patterns = %q, %d; want all names", kept, excluded)
+	}
+}
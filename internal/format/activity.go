@@ -0,0 +1,53 @@
+package format
+
+import (
+	"strings"
+	"time"
+
+	"github.com/prometheus/common/model"
+)
+
+// Activity bar cells.
+const (
+	activeCell   = '█'
+	inactiveCell = '░'
+)
+
+// BuildActivityBar renders when the series of matrix were active between start
+// and end as a bar of width cells, e.g. "[████████░░░░████]". Each cell covers
+// an equal slice of the range and is filled when any sample in it has a
+// non-zero value. A width below one or an empty range renders "[]".
+func BuildActivityBar(matrix model.Matrix, start, end time.Time, width int) string {
+	if width < 1 || !end.After(start) {
+		return "[]"
+	}
+
+	active := make([]bool, width)
+	span := end.Sub(start)
+	for _, series := range matrix {
+		for _, p := range series.Values {
+			if p.Value == 0 {
+				continue
+			}
+			offset := p.Timestamp.Time().Sub(start)
+			if offset < 0 || offset > span {
+				continue
+			}
+			// The sample at end belongs to the last cell.
+			i := min(int(int64(offset)*int64(width)/int64(span)), width-1)
+			active[i] = true
+		}
+	}
+
+	var b strings.Builder
+	b.WriteByte('[')
+	for _, a := range active {
+		if a {
+			b.WriteRune(activeCell)
+		} else {
+			b.WriteRune(inactiveCell)
+		}
+	}
+	b.WriteByte(']')
+	return b.String()
+}
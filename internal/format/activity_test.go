@@ -0,0 +1,74 @@
+package format
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/common/model"
+)
+
+func TestBuildActivityBar(t *testing.T) {
+	start := time.Unix(0, 0)
+	end := start.Add(16 * time.Minute)
+	// samples returns one sample per minute for the given minutes.
+	samples := func(value model.SampleValue, minutes ...int) []model.SamplePair {
+		var pairs []model.SamplePair
+		for _, m := range minutes {
+			pairs = append(pairs, model.SamplePair{Timestamp: model.TimeFromUnix(int64(m * 60)), Value: value})
+		}
+		return pairs
+	}
+
+	tests := []struct {
+		name   string
+		matrix model.Matrix
+		width  int
+		end    time.Time
+		want   string
+	}{
+		{
+			name:   "gap",
+			matrix: model.Matrix{{Values: samples(1, 0, 1, 2, 3, 4, 5, 6, 7, 12, 13, 14, 15)}},
+			width:  16,
+			want:   "[████████░░░░████]",
+		},
+		{
+			name:   "zero counts are inactive",
+			matrix: model.Matrix{{Values: append(samples(0, 0, 1, 2, 3), samples(1, 4, 5, 6, 7)...)}},
+			width:  4,
+			want:   "[░█░░]",
+		},
+		{
+			name:   "sample at end fills last cell",
+			matrix: model.Matrix{{Values: samples(1, 16)}},
+			width:  4,
+			want:   "[░░░█]",
+		},
+		{
+			name:   "samples outside range are ignored",
+			matrix: model.Matrix{{Values: samples(1, -1, 17)}},
+			width:  4,
+			want:   "[░░░░]",
+		},
+		{
+			name:   "multiple series are merged",
+			matrix: model.Matrix{{Values: samples(1, 0)}, {Values: samples(1, 12)}},
+			width:  4,
+			want:   "[█░░█]",
+		},
+		{name: "no data", width: 3, want: "[░░░]"},
+		{name: "zero width", width: 0, want: "[]"},
+		{name: "empty range", width: 4, end: start, want: "[]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := end
+			if !tt.end.IsZero() {
+				e = tt.end
+			}
+			if got := BuildActivityBar(tt.matrix, start, e, tt.width); got != tt.want {
+				t.Errorf("BuildActivityBar() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
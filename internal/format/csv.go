@@ -0,0 +1,57 @@
+package format
+
+import (
+	"bytes"
+	"encoding/csv"
+	"slices"
+
+	"github.com/prometheus/common/model"
+)
+
+// csvTimeLayout renders the time column with millisecond precision in UTC.
+const csvTimeLayout = "2006-01-02T15:04:05.000Z07:00"
+
+// MatrixCSV serialises a range query result as CSV with one row per
+// timestamp and one column per series. The first two columns hold the
+// timestamp as Unix seconds and as RFC3339; each series column is headed by
+// its label set, e.g. up{job="api"}. A cell is empty when its series has no
+// float sample at that timestamp, so series with gaps or different
+// timestamps line up. Native histogram samples are omitted.
+func MatrixCSV(m model.Matrix) (string, error) {
+	var timestamps []model.Time
+	values := make([]map[model.Time]model.SampleValue, len(m))
+	for i, s := range m {
+		values[i] = make(map[model.Time]model.SampleValue, len(s.Values))
+		for _, p := range s.Values {
+			values[i][p.Timestamp] = p.Value
+			timestamps = append(timestamps, p.Timestamp)
+		}
+	}
+	slices.Sort(timestamps)
+	timestamps = slices.Compact(timestamps)
+
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	header := []string{"timestamp", "time"}
+	for _, s := range m {
+		header = append(header, s.Metric.String())
+	}
+	if err := w.Write(header); err != nil {
+		return "", err
+	}
+	row := make([]string, len(header))
+	for _, ts := range timestamps {
+		row[0], row[1] = ts.String(), ts.Time().UTC().Format(csvTimeLayout)
+		for i := range m {
+			row[i+2] = ""
+			if v, ok := values[i][ts]; ok {
+				row[i+2] = v.String()
+			}
+		}
+		if err := w.Write(row); err != nil {
+			return "", err
+		}
+	}
+	w.Flush()
+	return buf.String(), w.Error()
+}
@@ -0,0 +1,41 @@
+package format
+
+import (
+	"math"
+	"testing"
+
+	"github.com/prometheus/common/model"
+)
+
+func TestMatrixCSV(t *testing.T) {
+	m := model.Matrix{
+		{Metric: model.Metric{"__name__": "up", "job": "api"}, Values: []model.SamplePair{
+			{Timestamp: 1704067200000, Value: 1},
+			{Timestamp: 1704067215000, Value: 0},
+			{Timestamp: 1704067230500, Value: model.SampleValue(math.NaN())},
+		}},
+		{Metric: model.Metric{"job": "db"}, Values: []model.SamplePair{
+			{Timestamp: 1704067215000, Value: 0.25},
+		}},
+	}
+
+	got, err := MatrixCSV(m)
+	if err != nil {
+		t.Fatalf("MatrixCSV: %v", err)
+	}
+	want := `timestamp,time,"up{job=""api""}","{job=""db""}"
+1704067200,2024-01-01T00:00:00.000Z,1,
+1704067215,2024-01-01T00:00:15.000Z,0,0.25
+1704067230.5,2024-01-01T00:00:30.500Z,NaN,
+`
+	if got != want {
+		t.Errorf("MatrixCSV() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestMatrixCSVEmpty(t *testing.T) {
+	got, err := MatrixCSV(nil)
+	if err != nil || got != "timestamp,time\n" {
+		t.Errorf("MatrixCSV(nil) = %q, %v", got, err)
+	}
+}
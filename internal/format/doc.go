@@ -0,0 +1,13 @@
+// Package format renders Prometheus API results as compact, human-readable
+// text for tool responses.
+//
+// Helpers here operate on plain Go values (metric names, samples) and return
+// strings or grouped data; they never talk to Prometheus. For example,
+// [GroupByPrefix] and [RenderPrefixGroups] turn a flat list of metric names
+// into a tree grouped by their first name segment. [GrafanaDashboardJSON]
+// exports a query as an importable Grafana dashboard, [OpenMetricsText]
+// serialises an instant vector as OpenMetrics text, [MatrixCSV] writes a
+// range result as CSV with one column per series, [Sparkline] draws a series
+// of values as a one-line bar chart and [BuildActivityBar] shows when series
+// were active over a time range.
+package format
@@ -0,0 +1,111 @@
+package format
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"text/template"
+)
+
+// Defaults applied by GrafanaDashboardJSON to empty GrafanaPanel fields.
+const (
+	DefaultGrafanaDataSource   = "Prometheus"
+	DefaultGrafanaPanelType    = "timeseries"
+	DefaultGrafanaLegendFormat = "__auto"
+	DefaultGrafanaFrom         = "now-1h"
+	DefaultGrafanaTo           = "now"
+)
+
+// GrafanaPanelTypes maps the accepted panel_type values to Grafana panel
+// plugin IDs.
+var GrafanaPanelTypes = map[string]string{
+	"timeseries": "timeseries",
+	"stat":       "stat",
+	"gauge":      "gauge",
+	"bar":        "barchart",
+}
+
+// GrafanaPanel describes the single panel of a generated dashboard.
+type GrafanaPanel struct {
+	Title        string
+	Description  string
+	PanelType    string // a key of GrafanaPanelTypes
+	Query        string
+	LegendFormat string
+	DataSource   string // name shown when importing; bound via __inputs
+	From         string // Grafana time, e.g. "now-6h" or an RFC3339 timestamp
+	To           string
+}
+
+// grafanaDashboardTemplate renders a Grafana 9+ dashboard with one panel.
+// The data source is declared in __inputs so Grafana asks for it on import.
+// Every interpolated value goes through the json function, which quotes and
+// escapes it.
+var grafanaDashboardTemplate = template.Must(template.New("dashboard").Funcs(template.FuncMap{
+	"json": func(v any) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	},
+}).Parse(`{
+  "__inputs": [{"name": "DS_PROMETHEUS", "label": {{json .DataSource}}, "type": "datasource", "pluginId": "prometheus", "pluginName": "Prometheus"}],
+  "title": {{json .Title}},
+  "description": {{json .Description}},
+  "schemaVersion": 39,
+  "editable": true,
+  "time": {"from": {{json .From}}, "to": {{json .To}}},
+  "panels": [
+    {
+      "id": 1,
+      "type": {{json .PanelType}},
+      "title": {{json .Title}},
+      "description": {{json .Description}},
+      "datasource": {"type": "prometheus", "uid": "${DS_PROMETHEUS}"},
+      "gridPos": {"h": 8, "w": 12, "x": 0, "y": 0},
+      "targets": [
+        {
+          "refId": "A",
+          "datasource": {"type": "prometheus", "uid": "${DS_PROMETHEUS}"},
+          "expr": {{json .Query}},
+          "legendFormat": {{json .LegendFormat}}
+        }
+      ]
+    }
+  ]
+}`))
+
+// GrafanaDashboardJSON renders p as an importable Grafana dashboard holding a
+// single panel that plots p.Query. Empty fields take the Default* values.
+func GrafanaDashboardJSON(p GrafanaPanel) (string, error) {
+	if p.Query == "" {
+		return "", fmt.Errorf("query is required")
+	}
+	if p.PanelType == "" {
+		p.PanelType = DefaultGrafanaPanelType
+	}
+	pluginID, ok := GrafanaPanelTypes[p.PanelType]
+	if !ok {
+		return "", fmt.Errorf("unsupported panel type %q: must be timeseries, stat, gauge or bar", p.PanelType)
+	}
+	p.PanelType = pluginID
+	if p.Title == "" {
+		p.Title = p.Query
+	}
+	if p.DataSource == "" {
+		p.DataSource = DefaultGrafanaDataSource
+	}
+	if p.LegendFormat == "" {
+		p.LegendFormat = DefaultGrafanaLegendFormat
+	}
+	if p.From == "" {
+		p.From = DefaultGrafanaFrom
+	}
+	if p.To == "" {
+		p.To = DefaultGrafanaTo
+	}
+
+	var buf bytes.Buffer
+	if err := grafanaDashboardTemplate.Execute(&buf, p); err != nil {
+		return "", fmt.Errorf("render dashboard: %w", err)
+	}
+	return buf.String(), nil
+}
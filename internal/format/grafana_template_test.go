@@ -0,0 +1,88 @@
+package format
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+// grafanaDashboard is the subset of the generated dashboard the tests check.
+type grafanaDashboard struct {
+	Inputs []struct {
+		Label string `json:"label"`
+	} `json:"__inputs"`
+	Title string `json:"title"`
+	Time  struct {
+		From string `json:"from"`
+		To   string `json:"to"`
+	} `json:"time"`
+	Panels []struct {
+		Type        string `json:"type"`
+		Title       string `json:"title"`
+		Description string `json:"description"`
+		Targets     []struct {
+			Expr         string `json:"expr"`
+			LegendFormat string `json:"legendFormat"`
+		} `json:"targets"`
+	} `json:"panels"`
+}
+
+func TestGrafanaDashboardJSON(t *testing.T) {
+	query := `sum by (job) (rate(http_requests_total{code=~"5.."}[5m]))`
+	out, err := GrafanaDashboardJSON(GrafanaPanel{
+		Title:        `5xx "errors"`,
+		Description:  "Error rate per job\nfrom the API gateway",
+		PanelType:    "bar",
+		Query:        query,
+		LegendFormat: "{{job}}",
+		DataSource:   "Mimir",
+		From:         "now-6h",
+	})
+	if err != nil {
+		t.Fatalf("GrafanaDashboardJSON: %v", err)
+	}
+
+	var d grafanaDashboard
+	if err := json.Unmarshal([]byte(out), &d); err != nil {
+		t.Fatalf("generated dashboard is not valid JSON: %v\n%s", err, out)
+	}
+	if len(d.Panels) != 1 || len(d.Panels[0].Targets) != 1 {
+		t.Fatalf("expected one panel with one target, got %+v", d.Panels)
+	}
+	p := d.Panels[0]
+	if p.Targets[0].Expr != query {
+		t.Errorf("expr = %q, want %q", p.Targets[0].Expr, query)
+	}
+	if p.Type != "barchart" || p.Title != `5xx "errors"` || p.Targets[0].LegendFormat != "{{job}}" {
+		t.Errorf("unexpected panel: %+v", p)
+	}
+	if d.Time.From != "now-6h" || d.Time.To != DefaultGrafanaTo {
+		t.Errorf("time = %+v, want now-6h to now", d.Time)
+	}
+	if len(d.Inputs) != 1 || d.Inputs[0].Label != "Mimir" {
+		t.Errorf("__inputs = %+v, want the Mimir data source", d.Inputs)
+	}
+}
+
+func TestGrafanaDashboardJSONDefaults(t *testing.T) {
+	out, err := GrafanaDashboardJSON(GrafanaPanel{Query: "up"})
+	if err != nil {
+		t.Fatalf("GrafanaDashboardJSON: %v", err)
+	}
+	var d grafanaDashboard
+	if err := json.Unmarshal([]byte(out), &d); err != nil {
+		t.Fatalf("generated dashboard is not valid JSON: %v", err)
+	}
+	if d.Title != "up" || d.Panels[0].Type != DefaultGrafanaPanelType || d.Inputs[0].Label != DefaultGrafanaDataSource ||
+		d.Panels[0].Targets[0].LegendFormat != DefaultGrafanaLegendFormat || d.Time.From != DefaultGrafanaFrom {
+		t.Errorf("defaults not applied: %s", out)
+	}
+}
+
+func TestGrafanaDashboardJSONErrors(t *testing.T) {
+	if _, err := GrafanaDashboardJSON(GrafanaPanel{}); err == nil {
+		t.Error("expected an error without a query")
+	}
+	if _, err := GrafanaDashboardJSON(GrafanaPanel{Query: "up", PanelType: "pie"}); err == nil {
+		t.Error("expected an error for an unsupported panel type")
+	}
+}
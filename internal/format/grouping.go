@@ -0,0 +1,71 @@
+package format
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// DefaultMinGroupSize is the smallest number of metrics sharing a prefix that
+// GroupByPrefix turns into a group.
+const DefaultMinGroupSize = 3
+
+// GroupByPrefix groups metric names by their first segment including the
+// trailing underscore (e.g. "http_" for "http_requests_total").
+//
+// Prefixes shared by fewer than minGroupSize names are not grouped; those
+// names, and names without an underscore, are returned under the empty key.
+// Names within each group keep their input order.
+func GroupByPrefix(metrics []string, minGroupSize int) map[string][]string {
+	byPrefix := make(map[string][]string)
+	for _, name := range metrics {
+		prefix := ""
+		if i := strings.Index(name, "_"); i > 0 {
+			prefix = name[:i+1]
+		}
+		byPrefix[prefix] = append(byPrefix[prefix], name)
+	}
+
+	groups := make(map[string][]string)
+	for prefix, names := range byPrefix {
+		if prefix == "" || len(names) < minGroupSize {
+			groups[""] = append(groups[""], names...)
+			continue
+		}
+		groups[prefix] = names
+	}
+	if ungrouped := groups[""]; ungrouped != nil {
+		sort.Strings(ungrouped)
+	}
+	return groups
+}
+
+// RenderPrefixGroups renders the result of GroupByPrefix as an indented tree,
+// groups in prefix order followed by the ungrouped names:
+//
+//	http_ (3 metrics):
+//	  http_request_duration_seconds
+//	  http_requests_total
+//	  http_response_size_bytes
+//	up
+func RenderPrefixGroups(groups map[string][]string) string {
+	prefixes := make([]string, 0, len(groups))
+	for prefix := range groups {
+		if prefix != "" {
+			prefixes = append(prefixes, prefix)
+		}
+	}
+	sort.Strings(prefixes)
+
+	var b strings.Builder
+	for _, prefix := range prefixes {
+		fmt.Fprintf(&b, "%s (%d metrics):\n", prefix, len(groups[prefix]))
+		for _, name := range groups[prefix] {
+			fmt.Fprintf(&b, "  %s\n", name)
+		}
+	}
+	for _, name := range groups[""] {
+		fmt.Fprintf(&b, "%s\n", name)
+	}
+	return b.String()
+}
@@ -0,0 +1,49 @@
+package format
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGroupByPrefix(t *testing.T) {
+	metrics := []string{
+		"http_request_duration_seconds",
+		"http_requests_total",
+		"http_response_size_bytes",
+		"go_goroutines",
+		"go_threads",
+		"up",
+	}
+
+	got := GroupByPrefix(metrics, DefaultMinGroupSize)
+	want := map[string][]string{
+		"http_": {"http_request_duration_seconds", "http_requests_total", "http_response_size_bytes"},
+		"":      {"go_goroutines", "go_threads", "up"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("GroupByPrefix() = %v, want %v", got, want)
+	}
+
+	got = GroupByPrefix(metrics, 2)
+	if len(got["go_"]) != 2 || !reflect.DeepEqual(got[""], []string{"up"}) {
+		t.Errorf("GroupByPrefix(min=2) = %v", got)
+	}
+}
+
+func TestRenderPrefixGroups(t *testing.T) {
+	groups := GroupByPrefix([]string{
+		"up",
+		"http_requests_total",
+		"http_request_duration_seconds",
+		"http_response_size_bytes",
+	}, DefaultMinGroupSize)
+
+	want := "http_ (3 metrics):\n" +
+		"  http_requests_total\n" +
+		"  http_request_duration_seconds\n" +
+		"  http_response_size_bytes\n" +
+		"up\n"
+	if got := RenderPrefixGroups(groups); got != want {
+		t.Errorf("RenderPrefixGroups() =\n%s\nwant:\n%s", got, want)
+	}
+}
@@ -0,0 +1,127 @@
+package format
+
+import (
+	"bytes"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+	"github.com/prometheus/common/model"
+)
+
+// UnnamedMetricFamily is the family name given to series without a
+// __name__ label, such as the result of rate() or sum().
+const UnnamedMetricFamily = "query_result"
+
+// MetricFamilyMetadata is the HELP and TYPE reported for a metric by the
+// Prometheus metadata API. Type is one of the API's type strings
+// ("counter", "gauge", "histogram", ...).
+type MetricFamilyMetadata struct {
+	Type string
+	Help string
+}
+
+// OpenMetricsText serialises an instant vector in the OpenMetrics 1.0.0 text
+// format, one family per metric name, ending with "# EOF". metadata is keyed
+// by metric name and supplies HELP and TYPE.
+//
+// Every sample of a vector is a single value, so only counters (named
+// *_total) and gauges keep their type; histogram and summary components and
+// metrics without metadata are written with the Prometheus text format's
+// "# TYPE untyped" line instead of OpenMetrics' "unknown", keeping the output
+// readable by expfmt.TextParser. Sample timestamps and native histogram
+// samples are omitted.
+func OpenMetricsText(vector model.Vector, metadata map[string]MetricFamilyMetadata) (string, error) {
+	samples := slices.Clone(vector)
+	slices.SortFunc(samples, func(a, b *model.Sample) int {
+		return strings.Compare(a.Metric.String(), b.Metric.String())
+	})
+
+	families := make(map[string]*dto.MetricFamily)
+	for _, s := range samples {
+		if s.Histogram != nil {
+			continue
+		}
+		name := string(s.Metric[model.MetricNameLabel])
+		if name == "" {
+			name = UnnamedMetricFamily
+		}
+		family, ok := families[name]
+		if !ok {
+			family = newMetricFamily(name, metadata)
+			families[name] = family
+		}
+		family.Metric = append(family.Metric, newMetric(family.GetType(), s))
+	}
+
+	var b bytes.Buffer
+	for _, name := range slices.Sorted(maps.Keys(families)) {
+		family := families[name]
+		var err error
+		if family.GetType() == dto.MetricType_UNTYPED {
+			_, err = expfmt.MetricFamilyToText(&b, family)
+		} else {
+			_, err = expfmt.MetricFamilyToOpenMetrics(&b, family)
+		}
+		if err != nil {
+			return "", fmt.Errorf("encode metric family %s: %w", name, err)
+		}
+	}
+	if _, err := expfmt.FinalizeOpenMetrics(&b); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// newMetricFamily creates an empty family typed from metadata. Counter
+// metadata may be keyed with or without the _total suffix, depending on
+// whether the target was scraped as OpenMetrics.
+func newMetricFamily(name string, metadata map[string]MetricFamilyMetadata) *dto.MetricFamily {
+	md, ok := metadata[name]
+	if !ok {
+		if base, trimmed := strings.CutSuffix(name, "_total"); trimmed {
+			md = metadata[base]
+		}
+	}
+
+	metricType := dto.MetricType_UNTYPED
+	switch {
+	case md.Type == string(model.MetricTypeCounter) && strings.HasSuffix(name, "_total"):
+		metricType = dto.MetricType_COUNTER
+	case md.Type == string(model.MetricTypeGauge):
+		metricType = dto.MetricType_GAUGE
+	}
+
+	family := &dto.MetricFamily{Name: &name, Type: &metricType}
+	if md.Help != "" {
+		help := md.Help
+		family.Help = &help
+	}
+	return family
+}
+
+// newMetric converts a sample to a dto.Metric of the given type.
+func newMetric(metricType dto.MetricType, s *model.Sample) *dto.Metric {
+	m := &dto.Metric{}
+	for _, name := range slices.Sorted(maps.Keys(s.Metric)) {
+		if name == model.MetricNameLabel {
+			continue
+		}
+		labelName, labelValue := string(name), string(s.Metric[name])
+		m.Label = append(m.Label, &dto.LabelPair{Name: &labelName, Value: &labelValue})
+	}
+
+	value := float64(s.Value)
+	switch metricType {
+	case dto.MetricType_COUNTER:
+		m.Counter = &dto.Counter{Value: &value}
+	case dto.MetricType_GAUGE:
+		m.Gauge = &dto.Gauge{Value: &value}
+	default:
+		m.Untyped = &dto.Untyped{Value: &value}
+	}
+	return m
+}
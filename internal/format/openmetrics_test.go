@@ -0,0 +1,72 @@
+package format
+
+import (
+	"strings"
+	"testing"
+
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+	"github.com/prometheus/common/model"
+)
+
+func TestOpenMetricsText(t *testing.T) {
+	vector := model.Vector{
+		{Metric: model.Metric{"__name__": "http_requests_total", "job": "api", "code": "500"}, Value: 3, Timestamp: 1704067200000},
+		{Metric: model.Metric{"__name__": "http_requests_total", "job": "api", "code": "200"}, Value: 42, Timestamp: 1704067200000},
+		{Metric: model.Metric{"__name__": "go_goroutines", "job": "api"}, Value: 17, Timestamp: 1704067200000},
+		{Metric: model.Metric{"__name__": "custom_thing", "job": "api"}, Value: 1, Timestamp: 1704067200000},
+		{Metric: model.Metric{"__name__": "request_duration_seconds_bucket", "le": "+Inf"}, Value: 9, Timestamp: 1704067200000},
+		{Metric: model.Metric{"job": "api"}, Value: 0.5, Timestamp: 1704067200000},
+	}
+	metadata := map[string]MetricFamilyMetadata{
+		"http_requests":            {Type: "counter", Help: "Total HTTP requests."},
+		"go_goroutines":            {Type: "gauge", Help: "Number of goroutines."},
+		"request_duration_seconds": {Type: "histogram", Help: "Request latency."},
+	}
+
+	got, err := OpenMetricsText(vector, metadata)
+	if err != nil {
+		t.Fatalf("OpenMetricsText: %v", err)
+	}
+
+	want := `# TYPE custom_thing untyped
+custom_thing{job="api"} 1
+# HELP go_goroutines Number of goroutines.
+# TYPE go_goroutines gauge
+go_goroutines{job="api"} 17.0
+# HELP http_requests Total HTTP requests.
+# TYPE http_requests counter
+http_requests_total{code="200",job="api"} 42.0
+http_requests_total{code="500",job="api"} 3.0
+# TYPE query_result untyped
+query_result{job="api"} 0.5
+# TYPE request_duration_seconds_bucket untyped
+request_duration_seconds_bucket{le="+Inf"} 9
+# EOF
+`
+	if got != want {
+		t.Errorf("OpenMetricsText() =\n%s\nwant\n%s", got, want)
+	}
+
+	parser := expfmt.NewTextParser(model.UTF8Validation)
+	families, err := parser.TextToMetricFamilies(strings.NewReader(got))
+	if err != nil {
+		t.Fatalf("output does not parse: %v", err)
+	}
+	if f := families["go_goroutines"]; f == nil || f.GetType() != dto.MetricType_GAUGE || f.GetMetric()[0].GetGauge().GetValue() != 17 {
+		t.Errorf("go_goroutines parsed as %v", f)
+	}
+	if f := families["custom_thing"]; f == nil || f.GetType() != dto.MetricType_UNTYPED {
+		t.Errorf("custom_thing parsed as %v", f)
+	}
+}
+
+func TestOpenMetricsTextEmpty(t *testing.T) {
+	got, err := OpenMetricsText(nil, nil)
+	if err != nil {
+		t.Fatalf("OpenMetricsText: %v", err)
+	}
+	if got != "# EOF\n" {
+		t.Errorf("OpenMetricsText(nil) = %q, want %q", got, "# EOF\n")
+	}
+}
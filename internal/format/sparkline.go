@@ -0,0 +1,35 @@
+package format
+
+import (
+	"math"
+	"strings"
+)
+
+// sparkBlocks are the eight bar heights of a sparkline, lowest first.
+var sparkBlocks = []rune("▁▂▃▄▅▆▇█")
+
+// Sparkline renders values as one bar character each, scaled between the
+// smallest and largest value. NaN values (missing samples) are rendered as
+// spaces; a series without spread is drawn at the lowest height.
+func Sparkline(values []float64) string {
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for _, v := range values {
+		if !math.IsNaN(v) {
+			lo, hi = min(lo, v), max(hi, v)
+		}
+	}
+
+	var b strings.Builder
+	for _, v := range values {
+		switch {
+		case math.IsNaN(v):
+			b.WriteRune(' ')
+		case hi == lo:
+			b.WriteRune(sparkBlocks[0])
+		default:
+			i := int((v - lo) / (hi - lo) * float64(len(sparkBlocks)-1))
+			b.WriteRune(sparkBlocks[i])
+		}
+	}
+	return b.String()
+}
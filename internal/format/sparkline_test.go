@@ -0,0 +1,28 @@
+package format
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSparkline(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []float64
+		want   string
+	}{
+		{"empty", nil, ""},
+		{"ascending", []float64{0, 1, 2, 3, 4, 5, 6, 7}, "▁▂▃▄▅▆▇█"},
+		{"step function", []float64{10, 10, 20, 20}, "▁▁██"},
+		{"constant", []float64{5, 5, 5}, "▁▁▁"},
+		{"gaps", []float64{1, math.NaN(), 3}, "▁ █"},
+		{"all missing", []float64{math.NaN(), math.NaN()}, "  "},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Sparkline(tt.values); got != tt.want {
+				t.Errorf("Sparkline(%v) = %q, want %q", tt.values, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package format
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// unitConversions maps a source unit to the units it converts to and the
+// factor a value is multiplied by.
+var unitConversions = map[string]map[string]float64{
+	"bytes": {
+		"KiB": 1.0 / (1 << 10),
+		"MiB": 1.0 / (1 << 20),
+		"GiB": 1.0 / (1 << 30),
+		"TiB": 1.0 / (1 << 40),
+	},
+	"seconds": {
+		"ms": 1e3,
+		"µs": 1e6,
+		"ns": 1e9,
+	},
+	"ratio": {
+		"percent": 100,
+	},
+	"hertz": {
+		"MHz": 1e-6,
+		"GHz": 1e-9,
+	},
+}
+
+// unitAliases are alternative spellings of units, keyed in lower case.
+var unitAliases = map[string]string{
+	"us": "µs",
+	"μs": "µs", // Greek mu
+	"%":  "percent",
+}
+
+// UnitConverter converts sample values between two units.
+type UnitConverter struct {
+	From   string
+	To     string
+	Factor float64
+}
+
+// ParseUnitConversion parses a conversion such as "bytes->GiB" or
+// "seconds/ms". Units are matched case-insensitively and reported in their
+// canonical spelling.
+func ParseUnitConversion(expr string) (UnitConverter, error) {
+	from, to, ok := strings.Cut(expr, "->")
+	if !ok {
+		from, to, ok = strings.Cut(expr, "/")
+	}
+	if !ok {
+		return UnitConverter{}, fmt.Errorf("invalid unit conversion %q: expected <from>-><to>, e.g. bytes->GiB", expr)
+	}
+
+	fromUnit, ok := lookupUnit(slices.Collect(maps.Keys(unitConversions)), from)
+	if !ok {
+		return UnitConverter{}, fmt.Errorf("unknown source unit %q: supported units are %s", strings.TrimSpace(from), strings.Join(slices.Sorted(maps.Keys(unitConversions)), ", "))
+	}
+	targets := unitConversions[fromUnit]
+	toUnit, ok := lookupUnit(slices.Collect(maps.Keys(targets)), to)
+	if !ok {
+		return UnitConverter{}, fmt.Errorf("cannot convert %s to %q: supported targets are %s", fromUnit, strings.TrimSpace(to), strings.Join(slices.Sorted(maps.Keys(targets)), ", "))
+	}
+	return UnitConverter{From: fromUnit, To: toUnit, Factor: targets[toUnit]}, nil
+}
+
+// lookupUnit finds name among units, ignoring case and surrounding spaces
+// and resolving aliases.
+func lookupUnit(units []string, name string) (string, bool) {
+	name = strings.TrimSpace(name)
+	if alias, ok := unitAliases[strings.ToLower(name)]; ok {
+		name = alias
+	}
+	for _, u := range units {
+		if strings.EqualFold(u, name) {
+			return u, true
+		}
+	}
+	return "", false
+}
+
+// Convert returns v in the target unit.
+func (c UnitConverter) Convert(v float64) float64 {
+	return v * c.Factor
+}
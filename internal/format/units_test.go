@@ -0,0 +1,70 @@
+package format
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseUnitConversion(t *testing.T) {
+	tests := []struct {
+		expr   string
+		from   string
+		to     string
+		factor float64
+	}{
+		{"bytes->GiB", "bytes", "GiB", 1.0 / 1073741824},
+		{"bytes/GiB", "bytes", "GiB", 1.0 / 1073741824},
+		{"bytes->KiB", "bytes", "KiB", 1.0 / 1024},
+		{"bytes->MiB", "bytes", "MiB", 1.0 / 1048576},
+		{"bytes->TiB", "bytes", "TiB", 1.0 / 1099511627776},
+		{"seconds->ms", "seconds", "ms", 1e3},
+		{"seconds->us", "seconds", "µs", 1e6},
+		{"seconds->µs", "seconds", "µs", 1e6},
+		{"seconds->ns", "seconds", "ns", 1e9},
+		{"ratio->percent", "ratio", "percent", 100},
+		{"ratio->%", "ratio", "percent", 100},
+		{"hertz->MHz", "hertz", "MHz", 1e-6},
+		{"hertz->GHz", "hertz", "GHz", 1e-9},
+		{" Bytes -> gib ", "bytes", "GiB", 1.0 / 1073741824},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			c, err := ParseUnitConversion(tt.expr)
+			if err != nil {
+				t.Fatalf("ParseUnitConversion(%q): %v", tt.expr, err)
+			}
+			if c.From != tt.from || c.To != tt.to || c.Factor != tt.factor {
+				t.Errorf("ParseUnitConversion(%q) = %+v, want %s->%s ×%g", tt.expr, c, tt.from, tt.to, tt.factor)
+			}
+		})
+	}
+}
+
+func TestParseUnitConversionInvalid(t *testing.T) {
+	tests := []struct {
+		expr    string
+		wantErr string
+	}{
+		{"GiB", "expected <from>-><to>"},
+		{"meters->km", "unknown source unit"},
+		{"bytes->ms", "cannot convert bytes"},
+		{"seconds->GiB", "cannot convert seconds"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			if _, err := ParseUnitConversion(tt.expr); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ParseUnitConversion(%q) error = %v, want %q", tt.expr, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestUnitConverterConvert(t *testing.T) {
+	c, err := ParseUnitConversion("bytes->GiB")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := c.Convert(2147483648); got != 2 {
+		t.Errorf("Convert(2 GiB in bytes) = %g, want 2", got)
+	}
+}
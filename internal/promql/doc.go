@@ -0,0 +1,23 @@
+// Package promql contains local PromQL helpers that work on the parsed AST
+// rather than on a live Prometheus server.
+//
+// The main entry points are:
+//
+//   - [Parse] — parses an expression with the upstream Prometheus parser,
+//     configured the same way everywhere in mcp-prometheus.
+//   - [Format] — pretty-prints an expression like the server's
+//     format_query endpoint.
+//   - [SyntaxErrors] — lists every parse error of an expression with its
+//     line and column.
+//   - [Explain] — breaks an expression down into selectors, functions,
+//     aggregations and binary operations and describes it in plain English.
+//   - [VectorSelectors] — extracts the unique vector selectors referenced by
+//     an expression, e.g. to inspect or rewrite their label matchers.
+//   - [Aggregations], [BinaryOperators] and [FunctionSignatures] — a static
+//     reference of the PromQL language, used to document it to clients.
+//   - [InferMetricType] — guesses a metric's type from its name suffix when
+//     the metadata API has no entry for it.
+//
+// Nothing in this package performs network I/O; callers combine the results
+// with the Prometheus HTTP API themselves.
+package promql
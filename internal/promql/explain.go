@@ -0,0 +1,374 @@
+package promql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/promql/parser"
+)
+
+// Explanation is a breakdown of a PromQL expression.
+type Explanation struct {
+	// Type is the result type of the expression, e.g. "vector".
+	Type string
+	// Selectors lists the series selectors in depth-first order.
+	Selectors []SelectorInfo
+	// Functions holds the signature of every function called, once each.
+	Functions []string
+	// Aggregations holds each aggregation with its grouping, e.g.
+	// "sum by (job)" or "topk(5) without (instance)".
+	Aggregations []string
+	// BinaryOperations holds each binary operator with its modifiers, e.g.
+	// "/ on (job) group_left (team)".
+	BinaryOperations []string
+	// Text describes what the expression computes in plain English.
+	Text string
+}
+
+// SelectorInfo describes one series selector of an expression.
+type SelectorInfo struct {
+	// Selector is the selector as printed by the parser, including its range,
+	// offset and @ modifier.
+	Selector string
+	// Metric is the metric name, or empty when only label matchers select
+	// the series.
+	Metric string
+	// Matchers are the label matchers other than the metric name.
+	Matchers []string
+	// Range is the range window of a range vector selector, e.g. "5m", or
+	// empty for an instant vector selector.
+	Range string
+}
+
+// Explain breaks expr down into its selectors, functions, aggregations and
+// binary operations and describes it in plain English.
+func Explain(expr parser.Expr) Explanation {
+	e := Explanation{
+		Type: string(expr.Type()),
+		Text: "Computes " + describe(expr) + ".",
+	}
+
+	inRange := make(map[*parser.VectorSelector]bool)
+	functions := make(map[string]bool)
+	parser.Inspect(expr, func(node parser.Node, _ []parser.Node) error {
+		switch n := node.(type) {
+		case *parser.MatrixSelector:
+			vs := n.VectorSelector.(*parser.VectorSelector)
+			inRange[vs] = true
+			e.Selectors = append(e.Selectors, selectorInfo(n.String(), vs, rangeString(n)))
+		case *parser.VectorSelector:
+			if !inRange[n] {
+				e.Selectors = append(e.Selectors, selectorInfo(n.String(), n, ""))
+			}
+		case *parser.Call:
+			if !functions[n.Func.Name] {
+				functions[n.Func.Name] = true
+				e.Functions = append(e.Functions, functionSignature(n.Func))
+			}
+		case *parser.AggregateExpr:
+			e.Aggregations = append(e.Aggregations, aggregationString(n))
+		case *parser.BinaryExpr:
+			e.BinaryOperations = append(e.BinaryOperations, binaryOperationString(n))
+		}
+		return nil
+	})
+	return e
+}
+
+func selectorInfo(selector string, vs *parser.VectorSelector, window string) SelectorInfo {
+	info := SelectorInfo{Selector: selector, Metric: vs.Name, Range: window}
+	for _, m := range vs.LabelMatchers {
+		if m.Name == labels.MetricName && m.Type == labels.MatchEqual && m.Value == vs.Name {
+			continue
+		}
+		info.Matchers = append(info.Matchers, m.String())
+	}
+	return info
+}
+
+func rangeString(ms *parser.MatrixSelector) string {
+	if ms.RangeExpr != nil {
+		return ms.RangeExpr.String()
+	}
+	return model.Duration(ms.Range).String()
+}
+
+func aggregationString(agg *parser.AggregateExpr) string {
+	s := agg.Op.String()
+	if agg.Param != nil {
+		s += "(" + agg.Param.String() + ")"
+	}
+	switch {
+	case agg.Without:
+		s += " without (" + strings.Join(agg.Grouping, ", ") + ")"
+	case len(agg.Grouping) > 0:
+		s += " by (" + strings.Join(agg.Grouping, ", ") + ")"
+	}
+	return s
+}
+
+func binaryOperationString(b *parser.BinaryExpr) string {
+	s := b.Op.String()
+	if b.ReturnBool {
+		s += " bool"
+	}
+	vm := b.VectorMatching
+	if vm == nil {
+		return s
+	}
+	switch {
+	case vm.On:
+		s += " on (" + strings.Join(vm.MatchingLabels, ", ") + ")"
+	case len(vm.MatchingLabels) > 0:
+		s += " ignoring (" + strings.Join(vm.MatchingLabels, ", ") + ")"
+	}
+	switch vm.Card {
+	case parser.CardManyToOne:
+		s += " group_left (" + strings.Join(vm.Include, ", ") + ")"
+	case parser.CardOneToMany:
+		s += " group_right (" + strings.Join(vm.Include, ", ") + ")"
+	}
+	return s
+}
+
+// functionPhrases describe common functions; %s, when present, is replaced
+// by the description of the first argument. Functions with more than one
+// described argument are handled by describeCall.
+var functionPhrases = map[string]string{
+	"rate":              "the per-second average rate of increase of %s",
+	"irate":             "the per-second rate of increase between the last two samples of %s",
+	"increase":          "the increase of %s",
+	"delta":             "the difference between the first and last value of %s",
+	"idelta":            "the difference between the last two samples of %s",
+	"deriv":             "the per-second derivative of %s",
+	"changes":           "the number of value changes of %s",
+	"resets":            "the number of counter resets of %s",
+	"avg_over_time":     "the average of %s",
+	"min_over_time":     "the minimum of %s",
+	"max_over_time":     "the maximum of %s",
+	"sum_over_time":     "the sum of %s",
+	"count_over_time":   "the number of samples of %s",
+	"last_over_time":    "the most recent sample of %s",
+	"stddev_over_time":  "the standard deviation of %s",
+	"stdvar_over_time":  "the standard variance of %s",
+	"present_over_time": "1 for every series with samples in %s",
+	"absent":            "1 when %s returns no series",
+	"absent_over_time":  "1 when %s has no samples",
+	"abs":               "the absolute value of %s",
+	"ceil":              "%s rounded up",
+	"floor":             "%s rounded down",
+	"round":             "%s rounded",
+	"sqrt":              "the square root of %s",
+	"ln":                "the natural logarithm of %s",
+	"log2":              "the binary logarithm of %s",
+	"log10":             "the decimal logarithm of %s",
+	"exp":               "the exponential of %s",
+	"scalar":            "%s as a scalar",
+	"vector":            "%s as a single-series vector",
+	"sort":              "%s sorted by value, ascending",
+	"sort_desc":         "%s sorted by value, descending",
+	"timestamp":         "the timestamp of each sample of %s",
+	"histogram_count":   "the observation count of the native histograms %s",
+	"histogram_sum":     "the sum of observations of the native histograms %s",
+	"histogram_avg":     "the average observation of the native histograms %s",
+	"histogram_stddev":  "the standard deviation of the native histograms %s",
+	"histogram_stdvar":  "the standard variance of the native histograms %s",
+	"label_replace":     "%s with a label rewritten by regular expression",
+	"label_join":        "%s with labels joined into a new label",
+	"clamp":             "%s clamped to a range",
+	"clamp_min":         "%s clamped to a minimum",
+	"clamp_max":         "%s clamped to a maximum",
+	"time":              "the evaluation time in seconds since the epoch",
+}
+
+func describeCall(c *parser.Call) string {
+	args := make([]string, len(c.Args))
+	for i, arg := range c.Args {
+		args[i] = describe(arg)
+	}
+
+	switch c.Func.Name {
+	case "quantile_over_time":
+		return fmt.Sprintf("the %s-quantile of %s", c.Args[0], args[1])
+	case "histogram_quantile":
+		return fmt.Sprintf("the %s-quantile estimated from the histogram buckets in %s", c.Args[0], args[1])
+	case "predict_linear":
+		return fmt.Sprintf("the value %s seconds ahead predicted by linear regression over %s", c.Args[1], args[0])
+	}
+	if phrase, ok := functionPhrases[c.Func.Name]; ok {
+		if !strings.Contains(phrase, "%s") {
+			return phrase
+		}
+		if len(args) > 0 {
+			return fmt.Sprintf(phrase, args[0])
+		}
+	}
+	if len(args) == 0 {
+		return fmt.Sprintf("the result of %s()", c.Func.Name)
+	}
+	return fmt.Sprintf("%s() applied to %s", c.Func.Name, strings.Join(args, " and "))
+}
+
+// aggregationPhrases describe the aggregation operators without parameter;
+// the others are handled by describeAggregation.
+var aggregationPhrases = map[string]string{
+	"sum":    "the sum",
+	"avg":    "the average",
+	"min":    "the minimum",
+	"max":    "the maximum",
+	"count":  "the number of series",
+	"group":  "a series with value 1",
+	"stddev": "the standard deviation",
+	"stdvar": "the standard variance",
+}
+
+func describeAggregation(agg *parser.AggregateExpr) string {
+	op := agg.Op.String()
+	var s string
+	switch op {
+	case "topk":
+		s = fmt.Sprintf("the %s largest series", agg.Param)
+	case "bottomk":
+		s = fmt.Sprintf("the %s smallest series", agg.Param)
+	case "quantile":
+		s = fmt.Sprintf("the %s-quantile", agg.Param)
+	case "count_values":
+		s = fmt.Sprintf("the number of series per value, stored in label %s,", agg.Param)
+	case "limitk":
+		s = fmt.Sprintf("%s sampled series", agg.Param)
+	case "limit_ratio":
+		s = fmt.Sprintf("a %s ratio of sampled series", agg.Param)
+	default:
+		s = aggregationPhrases[op]
+		if s == "" {
+			s = "the " + op
+		}
+	}
+	s += " of " + describe(agg.Expr)
+
+	switch {
+	case agg.Without:
+		s += " for each group of labels except " + strings.Join(agg.Grouping, ", ")
+	case len(agg.Grouping) > 0:
+		s += " per " + strings.Join(agg.Grouping, " and ")
+	case op != "topk" && op != "bottomk" && op != "limitk" && op != "limit_ratio":
+		s += " across all series"
+	}
+	return s
+}
+
+// binaryPhrases describe the binary operators; the first %s is the left
+// operand and the second the right one.
+var binaryPhrases = map[string]string{
+	"+":      "%s plus %s",
+	"-":      "%s minus %s",
+	"*":      "%s multiplied by %s",
+	"/":      "%s divided by %s",
+	"%":      "%s modulo %s",
+	"^":      "%s raised to the power of %s",
+	"atan2":  "the arc tangent of %s and %s",
+	"and":    "%s, only where %s has a matching series",
+	"or":     "%s, plus %s where the left side has no matching series",
+	"unless": "%s, except where %s has a matching series",
+}
+
+// comparisonPhrases name the comparison operators.
+var comparisonPhrases = map[string]string{
+	"==": "equal to",
+	"!=": "not equal to",
+	">":  "greater than",
+	"<":  "less than",
+	">=": "greater than or equal to",
+	"<=": "less than or equal to",
+}
+
+func describeBinary(b *parser.BinaryExpr) string {
+	lhs, rhs := describe(b.LHS), describe(b.RHS)
+	op := b.Op.String()
+
+	var s string
+	if cmp, ok := comparisonPhrases[op]; ok {
+		if b.ReturnBool {
+			s = fmt.Sprintf("1 where %s is %s %s, otherwise 0", lhs, cmp, rhs)
+		} else {
+			s = fmt.Sprintf("%s, keeping only values %s %s", lhs, cmp, rhs)
+		}
+	} else {
+		s = fmt.Sprintf(binaryPhrases[op], lhs, rhs)
+	}
+
+	vm := b.VectorMatching
+	if vm == nil {
+		return s
+	}
+	var matching []string
+	switch {
+	case vm.On:
+		matching = append(matching, "matching series on "+strings.Join(vm.MatchingLabels, ", "))
+	case len(vm.MatchingLabels) > 0:
+		matching = append(matching, "matching series ignoring "+strings.Join(vm.MatchingLabels, ", "))
+	}
+	switch vm.Card {
+	case parser.CardManyToOne:
+		matching = append(matching, "many-to-one"+copiedLabels(vm.Include, "right"))
+	case parser.CardOneToMany:
+		matching = append(matching, "one-to-many"+copiedLabels(vm.Include, "left"))
+	}
+	if len(matching) > 0 {
+		s += " (" + strings.Join(matching, ", ") + ")"
+	}
+	return s
+}
+
+func copiedLabels(include []string, side string) string {
+	if len(include) == 0 {
+		return ""
+	}
+	return fmt.Sprintf(" copying %s from the %s side", strings.Join(include, ", "), side)
+}
+
+// describe renders node as a noun phrase.
+func describe(node parser.Expr) string {
+	switch n := node.(type) {
+	case *parser.VectorSelector:
+		return n.String()
+	case *parser.MatrixSelector:
+		return fmt.Sprintf("%s over the last %s", n.VectorSelector.String(), rangeString(n))
+	case *parser.Call:
+		return describeCall(n)
+	case *parser.AggregateExpr:
+		return describeAggregation(n)
+	case *parser.BinaryExpr:
+		return describeBinary(n)
+	case *parser.ParenExpr:
+		return describe(n.Expr)
+	case *parser.UnaryExpr:
+		if n.Op == parser.SUB {
+			return "the negation of " + describe(n.Expr)
+		}
+		return describe(n.Expr)
+	case *parser.SubqueryExpr:
+		step := "at the default resolution"
+		if n.Step != 0 {
+			step = "every " + model.Duration(n.Step).String()
+		} else if n.StepExpr != nil {
+			step = "every " + n.StepExpr.String()
+		}
+		window := model.Duration(n.Range).String()
+		if n.RangeExpr != nil {
+			window = n.RangeExpr.String()
+		}
+		return fmt.Sprintf("%s evaluated %s over the last %s", describe(n.Expr), step, window)
+	case *parser.NumberLiteral:
+		return strconv.FormatFloat(n.Val, 'g', -1, 64)
+	case *parser.StringLiteral:
+		return strconv.Quote(n.Val)
+	case *parser.StepInvariantExpr:
+		return describe(n.Expr)
+	default:
+		return node.String()
+	}
+}
@@ -0,0 +1,61 @@
+package promql
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestExplain(t *testing.T) {
+	expr, err := Parse(`sum by (job) (rate(http_requests_total{code=~"5.."}[5m])) / on (job) group_left (team) sum by (job) (rate(http_requests_total[5m])) > 0.1`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := Explain(expr)
+
+	if e.Type != "vector" {
+		t.Errorf("Type = %q, want vector", e.Type)
+	}
+	if len(e.Selectors) != 2 {
+		t.Fatalf("Selectors = %+v, want 2", e.Selectors)
+	}
+	if s := e.Selectors[0]; s.Selector != `http_requests_total{code=~"5.."}[5m]` || s.Metric != "http_requests_total" || !slices.Equal(s.Matchers, []string{`code=~"5.."`}) || s.Range != "5m" {
+		t.Errorf("Selectors[0] = %+v", s)
+	}
+	if !slices.Equal(e.Functions, []string{"rate(range vector) → instant vector"}) {
+		t.Errorf("Functions = %q", e.Functions)
+	}
+	if !slices.Equal(e.Aggregations, []string{"sum by (job)", "sum by (job)"}) {
+		t.Errorf("Aggregations = %q", e.Aggregations)
+	}
+	if !slices.Equal(e.BinaryOperations, []string{">", "/ on (job) group_left (team)"}) {
+		t.Errorf("BinaryOperations = %q", e.BinaryOperations)
+	}
+	want := `Computes the sum of the per-second average rate of increase of http_requests_total{code=~"5.."} over the last 5m per job divided by the sum of the per-second average rate of increase of http_requests_total over the last 5m per job (matching series on job, many-to-one copying team from the right side), keeping only values greater than 0.1.`
+	if e.Text != want {
+		t.Errorf("Text = %q, want %q", e.Text, want)
+	}
+}
+
+func TestExplainText(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{`histogram_quantile(0.99, sum by (le) (rate(http_request_duration_seconds_bucket[5m])))`, "Computes the 0.99-quantile estimated from the histogram buckets in the sum of the per-second average rate of increase of http_request_duration_seconds_bucket over the last 5m per le."},
+		{`topk(5, max_over_time(up[1h:1m]))`, "Computes the 5 largest series of the maximum of up evaluated every 1m over the last 1h."},
+		{`count without (instance) (up == bool 0)`, "Computes the number of series of 1 where up is equal to 0, otherwise 0 for each group of labels except instance."},
+		{`absent(up{job="api"})`, `Computes 1 when up{job="api"} returns no series.`},
+		{`sum(up)`, "Computes the sum of up across all series."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			expr, err := Parse(tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := Explain(expr).Text; got != tt.want {
+				t.Errorf("Explain(%q).Text = %q, want %q", tt.query, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package promql
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/prometheus/common/model"
+)
+
+// gaugeUnitPattern matches base units that are usually exposed as gauges
+// when no counter or histogram suffix follows them.
+var gaugeUnitPattern = regexp.MustCompile(`_(seconds|bytes|ratio|percent)$`)
+
+// InferMetricType guesses the type of a metric from its name, for use when
+// the metadata API has nothing for it. It returns one of "counter", "gauge",
+// "histogram", "summary" or "unknown":
+//
+//   - _bucket is a histogram component ("histogram");
+//   - _total and _count are counters ("counter");
+//   - _sum is a histogram or summary component; as only histograms expose
+//     _bucket series, a lone _sum is reported as "summary";
+//   - _info metrics and names ending in a base unit (_seconds, _bytes,
+//     _ratio, _percent) are gauges ("gauge").
+//
+// The guess follows the Prometheus naming conventions and is wrong for
+// metrics that ignore them.
+func InferMetricType(name string) string {
+	switch {
+	case strings.HasSuffix(name, "_bucket"):
+		return string(model.MetricTypeHistogram)
+	case strings.HasSuffix(name, "_total"), strings.HasSuffix(name, "_count"):
+		return string(model.MetricTypeCounter)
+	case strings.HasSuffix(name, "_sum"):
+		return string(model.MetricTypeSummary)
+	case strings.HasSuffix(name, "_info"), gaugeUnitPattern.MatchString(name):
+		return string(model.MetricTypeGauge)
+	default:
+		return string(model.MetricTypeUnknown)
+	}
+}
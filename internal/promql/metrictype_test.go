@@ -0,0 +1,39 @@
+package promql
+
+import "testing"
+
+func TestInferMetricType(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"http_requests_total", "counter"},
+		{"node_cpu_seconds_total", "counter"},
+		{"process_cpu_seconds_total", "counter"},
+		{"prometheus_tsdb_compactions_total", "counter"},
+		{"http_request_duration_seconds_count", "counter"},
+		{"http_request_duration_seconds_bucket", "histogram"},
+		{"apiserver_request_duration_seconds_bucket", "histogram"},
+		{"http_request_duration_seconds_sum", "summary"},
+		{"go_gc_duration_seconds_sum", "summary"},
+		{"go_gc_duration_seconds", "gauge"},
+		{"process_resident_memory_bytes", "gauge"},
+		{"node_filesystem_avail_bytes", "gauge"},
+		{"process_start_time_seconds", "gauge"},
+		{"container_memory_usage_ratio", "gauge"},
+		{"node_cpu_utilisation_percent", "gauge"},
+		{"kube_pod_info", "gauge"},
+		{"go_build_info", "gauge"},
+		{"up", "unknown"},
+		{"go_goroutines", "unknown"},
+		{"kube_deployment_status_replicas", "unknown"},
+		{"", "unknown"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := InferMetricType(tt.name); got != tt.want {
+				t.Errorf("InferMetricType(%q) = %q, want %q", tt.name, got, tt.want)
+			}
+		})
+	}
+}
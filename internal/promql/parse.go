@@ -0,0 +1,91 @@
+package promql
+
+import (
+	"fmt"
+
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/promql/parser"
+)
+
+// exprParser is shared by all helpers in this package. Experimental functions
+// are enabled so that expressions accepted by recent Prometheus/Mimir
+// versions are not rejected locally.
+var exprParser = parser.NewParser(parser.Options{
+	EnableExperimentalFunctions: true,
+})
+
+// Parse parses a PromQL expression.
+func Parse(query string) (parser.Expr, error) {
+	expr, err := exprParser.ParseExpr(query)
+	if err != nil {
+		return nil, fmt.Errorf("parse PromQL expression: %w", err)
+	}
+	return expr, nil
+}
+
+// ParseMatchers parses a series selector such as {job="api",code=~"5.."}
+// into its label matchers.
+func ParseMatchers(selector string) ([]*labels.Matcher, error) {
+	matchers, err := exprParser.ParseMetricSelector(selector)
+	if err != nil {
+		return nil, fmt.Errorf("parse series selector: %w", err)
+	}
+	return matchers, nil
+}
+
+// Format parses query and renders it with the upstream PromQL prettifier,
+// the same formatting the /api/v1/format_query endpoint applies.
+func Format(query string) (string, error) {
+	expr, err := Parse(query)
+	if err != nil {
+		return "", err
+	}
+	return parser.Prettify(expr), nil
+}
+
+// VectorSelectors returns the vector selectors referenced by expr in
+// depth-first order. Selectors with an identical string form are returned
+// once.
+func VectorSelectors(expr parser.Expr) []*parser.VectorSelector {
+	var selectors []*parser.VectorSelector
+	seen := make(map[string]bool)
+	parser.Inspect(expr, func(node parser.Node, _ []parser.Node) error {
+		vs, ok := node.(*parser.VectorSelector)
+		if !ok {
+			return nil
+		}
+		key := vs.String()
+		if !seen[key] {
+			seen[key] = true
+			selectors = append(selectors, vs)
+		}
+		return nil
+	})
+	return selectors
+}
+
+// WithMatcher returns the string form of vs with an additional equality
+// matcher name="value". Offsets and @ modifiers are dropped because the result
+// is intended for the series and label APIs, which accept bare selectors only.
+func WithMatcher(vs *parser.VectorSelector, name, value string) string {
+	matchers := make([]*labels.Matcher, 0, len(vs.LabelMatchers)+1)
+	matchers = append(matchers, vs.LabelMatchers...)
+	matchers = append(matchers, labels.MustNewMatcher(labels.MatchEqual, name, value))
+	return (&parser.VectorSelector{Name: vs.Name, LabelMatchers: matchers}).String()
+}
+
+// SelectorString returns the string form of vs without offsets or @
+// modifiers, suitable for the match[] parameter of the series and label APIs.
+func SelectorString(vs *parser.VectorSelector) string {
+	return (&parser.VectorSelector{Name: vs.Name, LabelMatchers: vs.LabelMatchers}).String()
+}
+
+// MatchedLabelNames returns the set of label names that vs already constrains
+// via its matchers, including __name__.
+func MatchedLabelNames(vs *parser.VectorSelector) map[string]bool {
+	names := make(map[string]bool, len(vs.LabelMatchers))
+	for _, m := range vs.LabelMatchers {
+		names[m.Name] = true
+	}
+	return names
+}
@@ -0,0 +1,111 @@
+package promql
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/prometheus/prometheus/promql/parser"
+)
+
+// Operator is a PromQL keyword or symbol with a one-line description.
+type Operator struct {
+	Name        string
+	Description string
+}
+
+// Aggregations lists the PromQL aggregation operators.
+var Aggregations = []Operator{
+	{"sum", "sum over dimensions"},
+	{"avg", "average over dimensions"},
+	{"min", "minimum over dimensions"},
+	{"max", "maximum over dimensions"},
+	{"count", "count number of elements in the vector"},
+	{"group", "all values in the resulting vector are 1"},
+	{"stddev", "population standard deviation over dimensions"},
+	{"stdvar", "population standard variance over dimensions"},
+	{"count_values(label, v)", "count number of elements with the same value, exposed in the given label"},
+	{"topk(k, v)", "largest k elements by sample value"},
+	{"bottomk(k, v)", "smallest k elements by sample value"},
+	{"quantile(φ, v)", "φ-quantile (0 ≤ φ ≤ 1) over dimensions"},
+	{"limitk(k, v)", "sample k elements (experimental)"},
+	{"limit_ratio(r, v)", "sample a pseudo-random ratio r of elements (experimental)"},
+}
+
+// AggregationModifiers lists the clauses that select the grouping labels of
+// an aggregation.
+var AggregationModifiers = []Operator{
+	{"by (labels)", "keep only the listed labels and aggregate over the rest"},
+	{"without (labels)", "drop the listed labels and aggregate over the rest"},
+}
+
+// BinaryOperators lists the PromQL binary operators in precedence order,
+// highest first.
+var BinaryOperators = []Operator{
+	{"^", "power (right-associative)"},
+	{"*", "multiplication"},
+	{"/", "division"},
+	{"%", "modulo"},
+	{"atan2", "arc tangent of the two operands"},
+	{"+", "addition"},
+	{"-", "subtraction"},
+	{"==", "equal; filters samples unless the bool modifier is used"},
+	{"!=", "not equal; filters samples unless the bool modifier is used"},
+	{">", "greater than; filters samples unless the bool modifier is used"},
+	{"<", "less than; filters samples unless the bool modifier is used"},
+	{">=", "greater or equal; filters samples unless the bool modifier is used"},
+	{"<=", "less or equal; filters samples unless the bool modifier is used"},
+	{"and", "intersection: left-hand elements with a matching right-hand label set"},
+	{"unless", "complement: left-hand elements without a matching right-hand label set"},
+	{"or", "union: all left-hand elements plus right-hand elements without a match"},
+}
+
+// VectorMatchingModifiers lists the modifiers controlling how binary
+// operators match series between vectors.
+var VectorMatchingModifiers = []Operator{
+	{"bool", "comparison returns 0 or 1 instead of filtering"},
+	{"on (labels)", "match series only on the listed labels"},
+	{"ignoring (labels)", "ignore the listed labels when matching series"},
+	{"group_left (labels)", "many-to-one matching; copy the listed labels from the right-hand side"},
+	{"group_right (labels)", "one-to-many matching; copy the listed labels from the left-hand side"},
+}
+
+// FunctionSignatures returns the signature of every function known to the
+// PromQL parser, sorted by name, e.g. "round(instant vector[, scalar]) →
+// instant vector". Optional arguments are in brackets; "..." marks a
+// repeatable argument. Experimental functions are suffixed "(experimental)".
+func FunctionSignatures() []string {
+	names := make([]string, 0, len(parser.Functions))
+	for name := range parser.Functions {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	signatures := make([]string, 0, len(names))
+	for _, name := range names {
+		signatures = append(signatures, functionSignature(parser.Functions[name]))
+	}
+	return signatures
+}
+
+func functionSignature(fn *parser.Function) string {
+	args := make([]string, 0, len(fn.ArgTypes))
+	for i, t := range fn.ArgTypes {
+		arg := parser.DocumentedType(t)
+		last := i == len(fn.ArgTypes)-1
+		switch {
+		case !last || fn.Variadic == 0:
+			args = append(args, arg)
+		case fn.Variadic == 1:
+			args = append(args, "["+arg+"]")
+		default:
+			args = append(args, "["+arg+" ...]")
+		}
+	}
+
+	sig := fmt.Sprintf("%s(%s) → %s", fn.Name, strings.ReplaceAll(strings.Join(args, ", "), ", [", "[, "), parser.DocumentedType(fn.ReturnType))
+	if fn.Experimental {
+		sig += " (experimental)"
+	}
+	return sig
+}
@@ -0,0 +1,49 @@
+package promql
+
+import (
+	"errors"
+
+	"github.com/prometheus/prometheus/promql/parser"
+)
+
+// SyntaxError is one error reported by the parser, located by its 1-based
+// line and column (in bytes) in the query. Line and Column are 0 when the
+// parser gave no position.
+type SyntaxError struct {
+	Line    int
+	Column  int
+	Message string
+}
+
+// SyntaxErrors returns every error the parser reported for query; err is the
+// error returned by [Parse]. Unlike err itself, which only describes the
+// first problem, the result lists all of them.
+func SyntaxErrors(query string, err error) []SyntaxError {
+	var parseErrs parser.ParseErrors
+	if !errors.As(err, &parseErrs) {
+		var parseErr *parser.ParseErr
+		if !errors.As(err, &parseErr) {
+			return []SyntaxError{{Message: err.Error()}}
+		}
+		parseErrs = parser.ParseErrors{*parseErr}
+	}
+
+	result := make([]SyntaxError, len(parseErrs))
+	for i, e := range parseErrs {
+		result[i] = SyntaxError{Message: e.Err.Error()}
+		pos := int(e.PositionRange.Start)
+		if pos < 0 || pos > len(query) {
+			continue
+		}
+		lastLineBreak := -1
+		result[i].Line = 1
+		for j, c := range query[:pos] {
+			if c == '\n' {
+				lastLineBreak = j
+				result[i].Line++
+			}
+		}
+		result[i].Column = pos - lastLineBreak
+	}
+	return result
+}
@@ -0,0 +1,28 @@
+package promql
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSyntaxErrors(t *testing.T) {
+	tests := []struct {
+		query string
+		want  []SyntaxError
+	}{
+		{"rate(http_requests_total[5m]", []SyntaxError{{Line: 1, Column: 29, Message: "unclosed left parenthesis"}}},
+		{"sum(up)\n  by (job) +", []SyntaxError{{Line: 2, Column: 13, Message: "unexpected end of input"}}},
+		{`up{job="a"`, []SyntaxError{{Line: 1, Column: 11, Message: "unexpected end of input inside braces"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			_, err := Parse(tt.query)
+			if err == nil {
+				t.Fatalf("Parse(%q) succeeded, want an error", tt.query)
+			}
+			if got := SyntaxErrors(tt.query, err); !slices.Equal(got, tt.want) {
+				t.Errorf("SyntaxErrors(%q) = %+v, want %+v", tt.query, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package server
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+)
+
+// AdminLogLevelPath is the path of the log level admin endpoint.
+const AdminLogLevelPath = "/admin/log-level"
+
+// adminTokenHeader carries the shared secret configured with --admin-token.
+const adminTokenHeader = "X-Admin-Token"
+
+// logLevelRequest is the JSON body accepted by the log level endpoint.
+type logLevelRequest struct {
+	Level string `json:"level"`
+}
+
+// NewLogLevelHandler returns an HTTP handler that changes the log level of sc.
+//
+// The handler accepts POST requests with a body such as {"level":"debug"};
+// any level understood by slog ("debug", "info", "warn", "error") is valid.
+// Requests must carry the configured token in the X-Admin-Token header. An
+// empty token rejects every request so the endpoint cannot be left open by
+// accident.
+func NewLogLevelHandler(sc *ServerContext, token string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		got := r.Header.Get(adminTokenHeader)
+		if token == "" || subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		var req logLevelRequest
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1024)).Decode(&req); err != nil {
+			http.Error(w, "invalid JSON body", http.StatusBadRequest)
+			return
+		}
+
+		var level slog.Level
+		if err := level.UnmarshalText([]byte(req.Level)); err != nil {
+			http.Error(w, "invalid level: "+req.Level, http.StatusBadRequest)
+			return
+		}
+
+		sc.SetLogLevel(level)
+		sc.Logger().Info("Log level changed via admin endpoint", "level", level.String())
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(logLevelRequest{Level: level.String()})
+	})
+}
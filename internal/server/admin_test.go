@@ -0,0 +1,86 @@
+package server
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestLogLevelHandler(t *testing.T) {
+	const token = "s3cret"
+
+	tests := []struct {
+		name       string
+		method     string
+		token      string
+		body       string
+		wantStatus int
+		wantLevel  slog.Level
+	}{
+		{name: "set debug", method: http.MethodPost, token: token, body: `{"level":"debug"}`, wantStatus: http.StatusOK, wantLevel: slog.LevelDebug},
+		{name: "set warn", method: http.MethodPost, token: token, body: `{"level":"WARN"}`, wantStatus: http.StatusOK, wantLevel: slog.LevelWarn},
+		{name: "missing token", method: http.MethodPost, body: `{"level":"debug"}`, wantStatus: http.StatusUnauthorized, wantLevel: slog.LevelInfo},
+		{name: "wrong token", method: http.MethodPost, token: "nope", body: `{"level":"debug"}`, wantStatus: http.StatusUnauthorized, wantLevel: slog.LevelInfo},
+		{name: "invalid level", method: http.MethodPost, token: token, body: `{"level":"loud"}`, wantStatus: http.StatusBadRequest, wantLevel: slog.LevelInfo},
+		{name: "invalid body", method: http.MethodPost, token: token, body: `level=debug`, wantStatus: http.StatusBadRequest, wantLevel: slog.LevelInfo},
+		{name: "wrong method", method: http.MethodGet, token: token, wantStatus: http.StatusMethodNotAllowed, wantLevel: slog.LevelInfo},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sc, err := NewServerContext(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			req := httptest.NewRequest(tt.method, AdminLogLevelPath, strings.NewReader(tt.body))
+			if tt.token != "" {
+				req.Header.Set(adminTokenHeader, tt.token)
+			}
+			rec := httptest.NewRecorder()
+			NewLogLevelHandler(sc, token).ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d (body: %s)", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			if got := sc.LogLevel(); got != tt.wantLevel {
+				t.Errorf("level = %v, want %v", got, tt.wantLevel)
+			}
+		})
+	}
+}
+
+func TestLogLevelHandlerEmptyTokenRejects(t *testing.T) {
+	sc, err := NewServerContext(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := httptest.NewRequest(http.MethodPost, AdminLogLevelPath, strings.NewReader(`{"level":"debug"}`))
+	rec := httptest.NewRecorder()
+	NewLogLevelHandler(sc, "").ServeHTTP(rec, req)
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusUnauthorized)
+	}
+}
+
+func TestLogLevelVarSharedWithHandler(t *testing.T) {
+	level := new(slog.LevelVar)
+	var buf strings.Builder
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: level}))
+
+	sc, err := NewServerContext(context.Background(), WithSlogLogger(logger), WithLogLevelVar(level))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sc.Logger().Debug("hidden")
+	sc.SetDebugMode(true)
+	sc.Logger().Debug("visible")
+
+	if strings.Contains(buf.String(), "hidden") || !strings.Contains(buf.String(), "visible") {
+		t.Errorf("unexpected log output:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,195 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// DefaultAuditLogEntries is the audit log capacity used when WithAuditLog is
+// given a non-positive size.
+const DefaultAuditLogEntries = 1000
+
+// ToolInvocationRecord describes one completed tool call.
+type ToolInvocationRecord struct {
+	ToolName string
+	// Arguments are the arguments the client passed, with secrets redacted.
+	Arguments     map[string]any
+	PrometheusURL string
+	OrgID         string
+	// Caller identifies the authenticated user, when OAuth is enabled.
+	Caller string
+	// SessionID is the MCP session the call arrived on, if any.
+	SessionID string
+	// Scope is the caller scope (OAuth user or MCP session) the call was
+	// made in; InvocationHistoryOf only returns the records of one scope.
+	Scope     string
+	StartTime time.Time
+	Duration  time.Duration
+	// ResultSize is the length in bytes of the result text.
+	ResultSize   int
+	Success      bool
+	ErrorMessage string
+}
+
+// auditLog is a fixed-size ring buffer of tool invocations. It has its own
+// mutex so recording a call never contends with ServerContext configuration
+// reads.
+type auditLog struct {
+	mu      sync.Mutex
+	records []ToolInvocationRecord
+	next    int  // index of the slot the next record is written to
+	full    bool // whether the buffer has wrapped at least once
+}
+
+func newAuditLog(maxEntries int) *auditLog {
+	if maxEntries <= 0 {
+		maxEntries = DefaultAuditLogEntries
+	}
+	return &auditLog{records: make([]ToolInvocationRecord, maxEntries)}
+}
+
+func (a *auditLog) add(rec ToolInvocationRecord) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.records[a.next] = rec
+	a.next = (a.next + 1) % len(a.records)
+	if a.next == 0 {
+		a.full = true
+	}
+}
+
+// last returns up to n records for which keep reports true, newest first.
+// n <= 0 returns all of them; a nil keep keeps every record.
+func (a *auditLog) last(n int, keep func(*ToolInvocationRecord) bool) []ToolInvocationRecord {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	size := a.next
+	if a.full {
+		size = len(a.records)
+	}
+	if n <= 0 || n > size {
+		n = size
+	}
+
+	out := make([]ToolInvocationRecord, 0, n)
+	for i := 1; i <= size && len(out) < n; i++ {
+		rec := &a.records[(a.next-i+len(a.records))%len(a.records)]
+		if keep == nil || keep(rec) {
+			out = append(out, *rec)
+		}
+	}
+	return out
+}
+
+// WithAuditLog keeps the last maxEntries tool invocations in memory
+// (DefaultAuditLogEntries when maxEntries <= 0). Without it nothing is
+// recorded.
+func WithAuditLog(maxEntries int) ServerOption {
+	return func(sc *ServerContext) {
+		sc.auditLog = newAuditLog(maxEntries)
+	}
+}
+
+// auditFileEntry is the JSON line written to the audit log file per
+// invocation.
+type auditFileEntry struct {
+	Time          time.Time      `json:"time"`
+	Tool          string         `json:"tool"`
+	Arguments     map[string]any `json:"arguments,omitempty"`
+	PrometheusURL string         `json:"prometheus_url,omitempty"`
+	OrgID         string         `json:"org_id,omitempty"`
+	Caller        string         `json:"caller,omitempty"`
+	SessionID     string         `json:"session_id,omitempty"`
+	DurationMS    float64        `json:"duration_ms"`
+	ResultBytes   int            `json:"result_bytes"`
+	Success       bool           `json:"success"`
+	Error         string         `json:"error,omitempty"`
+}
+
+// auditFile writes invocations to w, one JSON object per line. The mutex
+// keeps lines of concurrent calls from interleaving.
+type auditFile struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (a *auditFile) write(rec ToolInvocationRecord) error {
+	line, err := json.Marshal(auditFileEntry{
+		Time:          rec.StartTime.UTC(),
+		Tool:          rec.ToolName,
+		Arguments:     rec.Arguments,
+		PrometheusURL: rec.PrometheusURL,
+		OrgID:         rec.OrgID,
+		Caller:        rec.Caller,
+		SessionID:     rec.SessionID,
+		DurationMS:    float64(rec.Duration.Microseconds()) / 1000,
+		ResultBytes:   rec.ResultSize,
+		Success:       rec.Success,
+		Error:         rec.ErrorMessage,
+	})
+	if err != nil {
+		return fmt.Errorf("encode audit record: %w", err)
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	_, err = a.w.Write(append(line, '\n'))
+	return err
+}
+
+// WithAuditLogFile writes every tool invocation to w as a line of JSON (JSONL),
+// independently of the in-memory history kept by WithAuditLog. Pass a
+// *LogFile to support reopening the file for log rotation.
+func WithAuditLogFile(w io.Writer) ServerOption {
+	return func(sc *ServerContext) {
+		sc.auditFile = &auditFile{w: w}
+	}
+}
+
+// AuditLogEnabled reports whether tool invocations are kept in memory for
+// InvocationHistory.
+func (sc *ServerContext) AuditLogEnabled() bool {
+	return sc.auditLog != nil
+}
+
+// RecordsInvocations reports whether tool invocations are recorded at all,
+// in memory or to an audit log file.
+func (sc *ServerContext) RecordsInvocations() bool {
+	return sc.auditLog != nil || sc.auditFile != nil
+}
+
+// RecordInvocation appends rec to the in-memory audit log and the audit log
+// file, when enabled. Failing to write the file is logged, not returned, so
+// auditing never fails a tool call. It is safe for concurrent use.
+func (sc *ServerContext) RecordInvocation(rec ToolInvocationRecord) {
+	if sc.auditLog != nil {
+		sc.auditLog.add(rec)
+	}
+	if sc.auditFile != nil {
+		if err := sc.auditFile.write(rec); err != nil {
+			sc.Logger().Error("Failed to write audit log", "tool", rec.ToolName, "error", err)
+		}
+	}
+}
+
+// InvocationHistory returns up to n recorded invocations, newest first.
+// n <= 0 returns the whole log; a disabled audit log returns nil.
+func (sc *ServerContext) InvocationHistory(n int) []ToolInvocationRecord {
+	if sc.auditLog == nil {
+		return nil
+	}
+	return sc.auditLog.last(n, nil)
+}
+
+// InvocationHistoryOf returns up to n invocations recorded in scope, newest
+// first, like InvocationHistory. Callers only see their own calls.
+func (sc *ServerContext) InvocationHistoryOf(scope string, n int) []ToolInvocationRecord {
+	if sc.auditLog == nil {
+		return nil
+	}
+	return sc.auditLog.last(n, func(rec *ToolInvocationRecord) bool { return rec.Scope == scope })
+}
@@ -0,0 +1,167 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestAuditLogDisabledByDefault(t *testing.T) {
+	sc, err := NewServerContext(context.Background())
+	if err != nil {
+		t.Fatalf("NewServerContext: %v", err)
+	}
+	defer func() { _ = sc.Shutdown() }()
+
+	sc.RecordInvocation(ToolInvocationRecord{ToolName: "execute_query"})
+	if sc.AuditLogEnabled() {
+		t.Error("expected audit log to be disabled")
+	}
+	if got := sc.InvocationHistory(0); got != nil {
+		t.Errorf("expected no history, got %v", got)
+	}
+}
+
+func TestAuditLogAccumulates(t *testing.T) {
+	sc, err := NewServerContext(context.Background(), WithAuditLog(10))
+	if err != nil {
+		t.Fatalf("NewServerContext: %v", err)
+	}
+	defer func() { _ = sc.Shutdown() }()
+
+	for i := range 3 {
+		sc.RecordInvocation(ToolInvocationRecord{ToolName: fmt.Sprintf("tool-%d", i)})
+	}
+
+	got := sc.InvocationHistory(0)
+	if len(got) != 3 {
+		t.Fatalf("expected 3 records, got %d", len(got))
+	}
+	for i, want := range []string{"tool-2", "tool-1", "tool-0"} {
+		if got[i].ToolName != want {
+			t.Errorf("record %d = %q, want %q", i, got[i].ToolName, want)
+		}
+	}
+
+	if got := sc.InvocationHistory(2); len(got) != 2 || got[0].ToolName != "tool-2" {
+		t.Errorf("InvocationHistory(2) = %v, want the 2 newest records", got)
+	}
+}
+
+func TestInvocationHistoryOf(t *testing.T) {
+	sc, err := NewServerContext(context.Background(), WithAuditLog(10))
+	if err != nil {
+		t.Fatalf("NewServerContext: %v", err)
+	}
+	defer func() { _ = sc.Shutdown() }()
+
+	for i := range 6 {
+		scope := "user:alice"
+		if i%2 == 1 {
+			scope = "user:bob"
+		}
+		sc.RecordInvocation(ToolInvocationRecord{ToolName: fmt.Sprintf("tool-%d", i), Scope: scope})
+	}
+
+	got := sc.InvocationHistoryOf("user:bob", 2)
+	if len(got) != 2 || got[0].ToolName != "tool-5" || got[1].ToolName != "tool-3" {
+		t.Errorf("InvocationHistoryOf(bob, 2) = %v, want tool-5 and tool-3", got)
+	}
+	if got := sc.InvocationHistoryOf("user:carol", 0); len(got) != 0 {
+		t.Errorf("InvocationHistoryOf(carol) = %v, want none", got)
+	}
+}
+
+func TestAuditLogWrapsAtCapacity(t *testing.T) {
+	sc, err := NewServerContext(context.Background(), WithAuditLog(3))
+	if err != nil {
+		t.Fatalf("NewServerContext: %v", err)
+	}
+	defer func() { _ = sc.Shutdown() }()
+
+	for i := range 5 {
+		sc.RecordInvocation(ToolInvocationRecord{ToolName: fmt.Sprintf("tool-%d", i)})
+	}
+
+	got := sc.InvocationHistory(10)
+	if len(got) != 3 {
+		t.Fatalf("expected history capped at 3, got %d", len(got))
+	}
+	for i, want := range []string{"tool-4", "tool-3", "tool-2"} {
+		if got[i].ToolName != want {
+			t.Errorf("record %d = %q, want %q", i, got[i].ToolName, want)
+		}
+	}
+}
+
+func TestAuditLogDefaultCapacity(t *testing.T) {
+	if got := len(newAuditLog(0).records); got != DefaultAuditLogEntries {
+		t.Errorf("capacity = %d, want %d", got, DefaultAuditLogEntries)
+	}
+}
+
+func TestAuditLogConcurrentWrites(t *testing.T) {
+	sc, err := NewServerContext(context.Background(), WithAuditLog(50))
+	if err != nil {
+		t.Fatalf("NewServerContext: %v", err)
+	}
+	defer func() { _ = sc.Shutdown() }()
+
+	var wg sync.WaitGroup
+	for i := range 100 {
+		wg.Go(func() {
+			sc.RecordInvocation(ToolInvocationRecord{ToolName: fmt.Sprintf("tool-%d", i)})
+			_ = sc.InvocationHistory(5)
+		})
+	}
+	wg.Wait()
+
+	if got := len(sc.InvocationHistory(0)); got != 50 {
+		t.Errorf("expected 50 records after wrapping, got %d", got)
+	}
+}
+
+func TestAuditLogFile(t *testing.T) {
+	var buf bytes.Buffer
+	sc, err := NewServerContext(context.Background(), WithAuditLogFile(&buf))
+	if err != nil {
+		t.Fatalf("NewServerContext: %v", err)
+	}
+	defer func() { _ = sc.Shutdown() }()
+
+	if sc.AuditLogEnabled() || !sc.RecordsInvocations() {
+		t.Fatal("expected invocations to be recorded to the file only")
+	}
+	start := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	sc.RecordInvocation(ToolInvocationRecord{
+		ToolName:   "execute_query",
+		Arguments:  map[string]any{"query": "up"},
+		Caller:     "jane@example.com",
+		SessionID:  "s1",
+		StartTime:  start,
+		Duration:   1500 * time.Microsecond,
+		ResultSize: 42,
+		Success:    true,
+	})
+	sc.RecordInvocation(ToolInvocationRecord{ToolName: "get_targets", StartTime: start, ErrorMessage: "boom"})
+
+	lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 lines, got %q", buf.String())
+	}
+	want := `{"time":"2026-01-02T03:04:05Z","tool":"execute_query","arguments":{"query":"up"},"caller":"jane@example.com","session_id":"s1","duration_ms":1.5,"result_bytes":42,"success":true}`
+	if string(lines[0]) != want {
+		t.Errorf("first line = %s, want %s", lines[0], want)
+	}
+	var failed map[string]any
+	if err := json.Unmarshal(lines[1], &failed); err != nil || failed["success"] != false || failed["error"] != "boom" {
+		t.Errorf("second line = %s (%v)", lines[1], err)
+	}
+	if got := sc.InvocationHistory(0); got != nil {
+		t.Errorf("expected no in-memory history, got %v", got)
+	}
+}
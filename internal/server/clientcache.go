@@ -0,0 +1,178 @@
+package server
+
+import (
+	"container/list"
+	"errors"
+	"sync"
+	"time"
+)
+
+// Default ClientCache limits.
+const (
+	DefaultClientCacheSize        = 64
+	DefaultClientCacheIdleTimeout = 10 * time.Minute
+)
+
+// errClientCreationAborted is returned to lookups waiting for a creation
+// that panicked.
+var errClientCreationAborted = errors.New("client creation aborted")
+
+// CachedClient is a client kept in a ClientCache. CloseIdleConnections is
+// called when it is evicted.
+type CachedClient interface {
+	CloseIdleConnections()
+}
+
+// ClientCache keeps the Prometheus clients created for per-request URLs, org
+// IDs and profiles, so agents calling tools many times per minute reuse
+// connections and negotiated API versions instead of building a client for
+// every call. Beyond its size the least recently used client is evicted, and
+// clients unused for the idle timeout are evicted on the next lookup. A nil
+// *ClientCache caches nothing.
+type ClientCache struct {
+	mu          sync.Mutex
+	size        int
+	idleTimeout time.Duration
+	now         func() time.Time
+
+	// lru holds *clientCacheEntry values, most recently used first.
+	lru     *list.List
+	entries map[string]*list.Element
+
+	// inflight holds the creations in progress by key, so concurrent
+	// lookups of a missing key wait for one creation instead of each
+	// building a client.
+	inflight map[string]*clientCacheCall
+}
+
+// clientCacheCall is a client creation in progress. done is closed once
+// client and err are set.
+type clientCacheCall struct {
+	done   chan struct{}
+	client CachedClient
+	err    error
+}
+
+type clientCacheEntry struct {
+	key      string
+	client   CachedClient
+	lastUsed time.Time
+}
+
+// NewClientCache returns a cache of up to size clients, evicting clients
+// unused for idleTimeout (0 keeps them until they are the least recently
+// used). It returns nil, caching nothing, when size is not positive.
+func NewClientCache(size int, idleTimeout time.Duration) *ClientCache {
+	if size <= 0 {
+		return nil
+	}
+	return &ClientCache{
+		size:        size,
+		idleTimeout: idleTimeout,
+		now:         time.Now,
+		lru:         list.New(),
+		entries:     make(map[string]*list.Element),
+		inflight:    make(map[string]*clientCacheCall),
+	}
+}
+
+// Get returns the client cached under key, creating it with create when
+// missing. create runs without the cache locked, so a slow creation only
+// delays lookups of the same key, which wait for its result. Failed
+// creations are not cached.
+func (c *ClientCache) Get(key string, create func() (CachedClient, error)) (CachedClient, error) {
+	if c == nil {
+		return create()
+	}
+
+	c.mu.Lock()
+	now := c.now()
+	c.evictIdle(now)
+	if e, ok := c.entries[key]; ok {
+		entry := e.Value.(*clientCacheEntry)
+		entry.lastUsed = now
+		c.lru.MoveToFront(e)
+		c.mu.Unlock()
+		return entry.client, nil
+	}
+	if call, ok := c.inflight[key]; ok {
+		c.mu.Unlock()
+		<-call.done
+		return call.client, call.err
+	}
+	// The error stays set if create panics, so waiting lookups fail too.
+	call := &clientCacheCall{done: make(chan struct{}), err: errClientCreationAborted}
+	c.inflight[key] = call
+	c.mu.Unlock()
+
+	defer func() {
+		c.mu.Lock()
+		delete(c.inflight, key)
+		if call.err == nil {
+			c.entries[key] = c.lru.PushFront(&clientCacheEntry{key: key, client: call.client, lastUsed: c.now()})
+			for c.lru.Len() > c.size {
+				c.evict(c.lru.Back())
+			}
+		}
+		c.mu.Unlock()
+		close(call.done)
+	}()
+	call.client, call.err = create()
+	return call.client, call.err
+}
+
+// Len returns the number of cached clients.
+func (c *ClientCache) Len() int {
+	if c == nil {
+		return 0
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lru.Len()
+}
+
+// Close evicts every cached client.
+func (c *ClientCache) Close() {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for c.lru.Len() > 0 {
+		c.evict(c.lru.Back())
+	}
+}
+
+// evictIdle evicts the clients unused since idleTimeout before now. They
+// are at the back of lru.
+func (c *ClientCache) evictIdle(now time.Time) {
+	if c.idleTimeout <= 0 {
+		return
+	}
+	for e := c.lru.Back(); e != nil && now.Sub(e.Value.(*clientCacheEntry).lastUsed) > c.idleTimeout; e = c.lru.Back() {
+		c.evict(e)
+	}
+}
+
+func (c *ClientCache) evict(e *list.Element) {
+	entry := c.lru.Remove(e).(*clientCacheEntry)
+	delete(c.entries, entry.key)
+	entry.client.CloseIdleConnections()
+}
+
+// WithClientCache caches the clients created for per-request URLs, org IDs
+// and profiles in c; nil disables the cache. Without it a cache of
+// DefaultClientCacheSize clients with DefaultClientCacheIdleTimeout is used.
+func WithClientCache(c *ClientCache) ServerOption {
+	return func(sc *ServerContext) {
+		sc.clientCache = c
+		sc.clientCacheSet = true
+	}
+}
+
+// ClientCache returns the cache of per-request clients, nil when disabled.
+func (sc *ServerContext) ClientCache() *ClientCache {
+	sc.mutex.RLock()
+	defer sc.mutex.RUnlock()
+	return sc.clientCache
+}
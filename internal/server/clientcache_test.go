@@ -0,0 +1,124 @@
+package server
+
+import (
+	"errors"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeClient counts the calls to CloseIdleConnections.
+type fakeClient struct {
+	name   string
+	closed int
+}
+
+func (c *fakeClient) CloseIdleConnections() { c.closed++ }
+
+func TestClientCache(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	cache := NewClientCache(2, time.Minute)
+	cache.now = func() time.Time { return now }
+
+	created := map[string]*fakeClient{}
+	get := func(key string) *fakeClient {
+		t.Helper()
+		client, err := cache.Get(key, func() (CachedClient, error) {
+			created[key] = &fakeClient{name: key}
+			return created[key], nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return client.(*fakeClient)
+	}
+
+	a := get("a")
+	if get("a") != a || len(created) != 1 {
+		t.Fatal("expected the second lookup to reuse the client")
+	}
+
+	// "a" was used last, so "b" is evicted when "c" is added.
+	b := get("b")
+	get("a")
+	get("c")
+	if cache.Len() != 2 || b.closed != 1 || a.closed != 0 {
+		t.Errorf("after adding c: %d cached, b closed %d times, a %d times; want b evicted", cache.Len(), b.closed, a.closed)
+	}
+
+	// Clients unused for the idle timeout are evicted on the next lookup.
+	now = now.Add(30 * time.Second)
+	get("c")
+	now = now.Add(45 * time.Second)
+	get("c")
+	if a.closed != 1 || cache.Len() != 1 {
+		t.Errorf("after the idle timeout: a closed %d times, %d cached; want a evicted", a.closed, cache.Len())
+	}
+
+	if _, err := cache.Get("d", func() (CachedClient, error) { return nil, errors.New("boom") }); err == nil || cache.Len() != 1 {
+		t.Errorf("failed creation: err %v, %d cached; want the error and nothing cached", err, cache.Len())
+	}
+
+	cache.Close()
+	if cache.Len() != 0 || created["c"].closed != 1 {
+		t.Errorf("after Close: %d cached, c closed %d times", cache.Len(), created["c"].closed)
+	}
+}
+
+func TestNilClientCache(t *testing.T) {
+	cache := NewClientCache(0, time.Minute)
+	if cache != nil {
+		t.Fatal("expected no cache for size 0")
+	}
+	calls := 0
+	for range 2 {
+		if _, err := cache.Get("a", func() (CachedClient, error) { calls++; return &fakeClient{}, nil }); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if calls != 2 || cache.Len() != 0 {
+		t.Errorf("nil cache created %d clients and holds %d; want 2 and 0", calls, cache.Len())
+	}
+	cache.Close()
+}
+
+func TestClientCacheCreatesOncePerKey(t *testing.T) {
+	cache := NewClientCache(4, 0)
+
+	release := make(chan struct{})
+	var creations atomic.Int32
+	slowCreate := func() (CachedClient, error) {
+		creations.Add(1)
+		<-release
+		return &fakeClient{name: "slow"}, nil
+	}
+
+	var wg sync.WaitGroup
+	clients := make([]CachedClient, 5)
+	for i := range clients {
+		wg.Go(func() {
+			clients[i], _ = cache.Get("slow", slowCreate)
+		})
+	}
+
+	// A slow creation does not block lookups of other keys.
+	for creations.Load() == 0 {
+		runtime.Gosched()
+	}
+	if _, err := cache.Get("fast", func() (CachedClient, error) { return &fakeClient{name: "fast"}, nil }); err != nil {
+		t.Fatal(err)
+	}
+
+	close(release)
+	wg.Wait()
+	if n := creations.Load(); n != 1 {
+		t.Errorf("created %d clients for one key, want 1", n)
+	}
+	for i, c := range clients {
+		if c == nil || c != clients[0] {
+			t.Errorf("lookup %d got %v, want the shared client", i, c)
+		}
+	}
+}
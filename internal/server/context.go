@@ -1,11 +1,20 @@
 package server
 
 import (
+	"cmp"
 	"context"
+	"errors"
+	"fmt"
 	"io"
 	"log/slog"
 	"os"
+	"strings"
 	"sync"
+	"unicode"
+
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/giantswarm/mcp-prometheus/pkg/plugin"
 )
 
 // PrometheusConfig holds the Prometheus server configuration
@@ -16,9 +25,177 @@ type PrometheusConfig struct {
 	Token    string
 	OrgID    string
 
+	// TokenFile holds a bearer token that is re-read periodically, e.g. a
+	// rotating Kubernetes projected service account token
+	// (PROMETHEUS_TOKEN_FILE). It is mutually exclusive with Token.
+	TokenFile string
+
+	// PasswordFile holds the basic auth password and is re-read like
+	// TokenFile, e.g. the Grafana Cloud token_file of a profile. It is
+	// mutually exclusive with Password.
+	PasswordFile string
+
 	// TLS configuration
-	TLSSkipVerify bool   // PROMETHEUS_TLS_SKIP_VERIFY — disable TLS certificate verification
-	TLSCACert     string // PROMETHEUS_TLS_CA_CERT — path to a PEM-encoded CA certificate file
+	TLSSkipVerify bool   // PROMETHEUS_TLS_SKIP_VERIFY or PROMETHEUS_TLS_INSECURE — disable TLS certificate verification
+	TLSCACert     string // PROMETHEUS_TLS_CA_CERT or PROMETHEUS_CA_CERT — path to a PEM-encoded CA bundle
+
+	// AuthMode selects an authentication scheme that is not implied by the
+	// credentials above (PROMETHEUS_AUTH_MODE): AuthModeGCP, AuthModeAzure,
+	// or empty to pick one from the configured credentials.
+	AuthMode string
+
+	// GCPCredentialsFile is the service account key used in AuthModeGCP
+	// (PROMETHEUS_GCP_CREDENTIALS_FILE). Application Default Credentials
+	// are used when empty.
+	GCPCredentialsFile string
+
+	// Azure is the Azure AD identity used in AuthModeAzure.
+	Azure AzureConfig
+
+	// OAuth2 authenticates requests with access tokens obtained through the
+	// OAuth2 client credentials grant (PROMETHEUS_OAUTH2_*). It takes
+	// precedence over Token and basic auth when TokenURL is set.
+	OAuth2 OAuth2Config
+
+	// Alertmanager selects the Alertmanager used by the Alertmanager tools
+	// and, optionally, separate credentials for it (ALERTMANAGER_*).
+	Alertmanager AlertmanagerConfig
+
+	// RemoteWriteURL is the remote write endpoint used by push_metric
+	// (PROMETHEUS_REMOTE_WRITE_URL).
+	RemoteWriteURL string
+
+	// PathPrefix is appended to URL for deployments that serve Prometheus
+	// under a sub-path, e.g. "/custom/prometheus" behind a reverse proxy
+	// (PROMETHEUS_PATH_PREFIX).
+	PathPrefix string
+
+	// TraceBaseURL is the trace UI (Jaeger, Grafana/Tempo, Zipkin) that
+	// query_exemplars links trace IDs to (PROMETHEUS_TRACE_BASE_URL).
+	TraceBaseURL string
+
+	// TracerProvider creates the spans recorded for requests to Prometheus.
+	// The global tracer provider is used when nil.
+	TracerProvider trace.TracerProvider
+
+	// RequestLimiter caps the rate and concurrency of requests to
+	// Prometheus. Requests are not limited when nil.
+	RequestLimiter *RequestLimiter
+
+	// DisableAPIVersionNegotiation stops the client from reading the server
+	// version to reject API features the server is too old for
+	// (PROMETHEUS_API_VERSION_NEGOTIATION=false).
+	DisableAPIVersionNegotiation bool
+}
+
+// AuthModeGCP authenticates requests to Google Cloud Managed Service for
+// Prometheus with Google OAuth2 access tokens.
+const AuthModeGCP = "gcp"
+
+// AuthModeAzure authenticates requests to Azure Monitor managed service for
+// Prometheus with Azure AD (Microsoft Entra ID) access tokens.
+const AuthModeAzure = "azure"
+
+// AzureConfig selects the Azure AD identity of AuthModeAzure: a service
+// principal when ClientSecret is set, otherwise the managed identity of the
+// host, user-assigned when ClientID is set.
+type AzureConfig struct {
+	TenantID     string // PROMETHEUS_AZURE_TENANT_ID
+	ClientID     string // PROMETHEUS_AZURE_CLIENT_ID
+	ClientSecret string // PROMETHEUS_AZURE_CLIENT_SECRET
+}
+
+// OAuth2Config configures the OAuth2 client credentials grant used to
+// authenticate requests to Prometheus, e.g. behind an OAuth2 proxy. Tokens
+// are fetched from TokenURL and refreshed before they expire.
+type OAuth2Config struct {
+	TokenURL     string   // PROMETHEUS_OAUTH2_TOKEN_URL
+	ClientID     string   // PROMETHEUS_OAUTH2_CLIENT_ID
+	ClientSecret string   // PROMETHEUS_OAUTH2_CLIENT_SECRET
+	Scopes       []string // PROMETHEUS_OAUTH2_SCOPES, comma- or space-separated
+}
+
+// AlertmanagerConfig configures requests to Alertmanager. URL overrides the
+// Alertmanager discovered via Prometheus. Requests carry the Prometheus
+// credentials and org ID, as Mimir serves both APIs, unless credentials of
+// their own are set here; TLS settings are always shared.
+type AlertmanagerConfig struct {
+	URL       string // ALERTMANAGER_URL
+	Username  string // ALERTMANAGER_USERNAME
+	Password  string // ALERTMANAGER_PASSWORD
+	Token     string // ALERTMANAGER_TOKEN
+	TokenFile string // ALERTMANAGER_TOKEN_FILE
+}
+
+// HasAuth reports whether Alertmanager has credentials of its own.
+func (c AlertmanagerConfig) HasAuth() bool {
+	return c.Token != "" || c.TokenFile != "" || (c.Username != "" && c.Password != "")
+}
+
+// Enabled reports whether the client credentials grant is configured.
+func (c OAuth2Config) Enabled() bool {
+	return c.TokenURL != ""
+}
+
+// parseScopes splits a comma- or space-separated list of OAuth2 scopes.
+func parseScopes(s string) []string {
+	return strings.FieldsFunc(s, func(r rune) bool { return r == ',' || unicode.IsSpace(r) })
+}
+
+// AuthType returns the kind of credentials the configuration carries:
+// "gcp", "azure", "oauth2", "basic", "bearer" or "none". It never exposes
+// the credentials themselves.
+func (c PrometheusConfig) AuthType() string {
+	switch {
+	case c.AuthMode == AuthModeGCP:
+		return "gcp"
+	case c.AuthMode == AuthModeAzure:
+		return "azure"
+	case c.OAuth2.Enabled():
+		return "oauth2"
+	case c.Username != "" && (c.Password != "" || c.PasswordFile != ""):
+		return "basic"
+	case c.Token != "" || c.TokenFile != "":
+		return "bearer"
+	default:
+		return "none"
+	}
+}
+
+// Sources of the Prometheus configuration, as reported by
+// ServerContext.PrometheusConfigSource.
+const (
+	ConfigSourceOption  = "option"  // set via WithPrometheusConfig
+	ConfigSourceEnv     = "env"     // loaded from PROMETHEUS_* environment variables
+	ConfigSourceProfile = "profile" // default profile of the configuration file
+	ConfigSourceUnset   = "unset"   // no URL configured; tools need prometheus_url
+)
+
+// ErrorVerbosity controls how much detail failed tool calls report to the
+// client.
+type ErrorVerbosity string
+
+const (
+	// ErrorVerbosityDetailed returns the full error, including query text
+	// and Prometheus URLs. It is the default.
+	ErrorVerbosityDetailed ErrorVerbosity = "detailed"
+	// ErrorVerbositySafe replaces errors with an opaque code and request ID;
+	// the full error is only logged.
+	ErrorVerbositySafe ErrorVerbosity = "safe"
+)
+
+// ParseErrorVerbosity parses a --error-verbosity or
+// PROMETHEUS_ERROR_VERBOSITY value. An empty string means
+// ErrorVerbosityDetailed.
+func ParseErrorVerbosity(s string) (ErrorVerbosity, error) {
+	switch v := ErrorVerbosity(strings.ToLower(strings.TrimSpace(s))); v {
+	case "":
+		return ErrorVerbosityDetailed, nil
+	case ErrorVerbosityDetailed, ErrorVerbositySafe:
+		return v, nil
+	default:
+		return "", fmt.Errorf("invalid error verbosity %q: must be %q or %q", s, ErrorVerbosityDetailed, ErrorVerbositySafe)
+	}
 }
 
 // TenancyResolver resolves Mimir tenant IDs from a set of authenticated user
@@ -36,14 +213,95 @@ type ServerContext struct {
 	mutex  sync.RWMutex
 
 	// Configuration
-	logger *slog.Logger
+	logger   *slog.Logger
+	logLevel *slog.LevelVar
+
+	// Build information
+	version string
 
 	// Prometheus configuration
-	prometheusConfig PrometheusConfig
+	prometheusConfig       PrometheusConfig
+	prometheusConfigSource string
 
 	// OAuth / tenancy (optional; nil when disabled)
 	oauthEnabled    bool
 	tenancyResolver TenancyResolver
+
+	// Per-tenant backend URLs (optional; nil when disabled)
+	tenantRouter TenantRouter
+
+	// Tracer provider for Prometheus client spans (optional; nil uses the
+	// global provider)
+	tracerProvider trace.TracerProvider
+
+	// Limiter of requests to Prometheus shared by all clients (optional;
+	// nil when unlimited)
+	requestLimiter *RequestLimiter
+
+	// API version negotiation override (optional; nil keeps the
+	// configuration's setting)
+	apiVersionNegotiation *bool
+
+	// Tool invocation history (optional; nil when disabled)
+	auditLog *auditLog
+
+	// Tool invocation audit file (optional; nil when disabled)
+	auditFile *auditFile
+
+	// Number of connections to pre-establish to Prometheus at startup
+	connectionWarmup int
+
+	// Character limit of tool result text (0 uses the tools' default)
+	maxResultLength int
+
+	// Target samples per series of range queries without a step (0 uses
+	// the tools' default)
+	rangeQueryPoints int
+
+	// How much error detail tool results expose
+	errorVerbosity ErrorVerbosity
+
+	// Cost limits checked before queries run
+	queryCostGuard QueryCostGuard
+
+	// Restrictions on the queries run for callers
+	queryPolicy QueryPolicy
+
+	// Clients for per-request URLs, org IDs and profiles; clientCacheSet
+	// records that WithClientCache chose it, possibly disabling it
+	clientCache    *ClientCache
+	clientCacheSet bool
+
+	// Glob patterns of metric names hidden from metric listings
+	excludedMetrics []string
+
+	// Whether get_config may return the configuration unredacted
+	rawConfig bool
+
+	// Whether tools that destroy or copy TSDB data are registered
+	adminTools bool
+
+	// Named Prometheus endpoints loaded from configFile
+	configFile string
+	profiles   Profiles
+
+	// Custom logic run around every Prometheus tool call
+	toolMiddleware []ToolMiddleware
+
+	// Custom tools registered after the built-in ones
+	toolPlugins []plugin.ToolPlugin
+
+	// Named PromQL templates registered at runtime, keyed by caller scope
+	// and name. They have their own mutex so template lookups never
+	// contend with configuration reads.
+	templatesMu        sync.RWMutex
+	queryTemplates     map[string]map[string]string
+	queryTemplateCount int
+
+	// Scrape targets started by register_test_target, keyed by ID
+	testTargetsMu sync.Mutex
+	testTargets   map[string]*testTarget
+	testTargetSeq int
 }
 
 // ServerOption is a functional option for configuring ServerContext
@@ -56,6 +314,21 @@ func WithSlogLogger(logger *slog.Logger) ServerOption {
 	}
 }
 
+// WithLogLevelVar attaches the level variable backing the logger's handler so
+// that the log level can be changed at runtime via SetLogLevel.
+func WithLogLevelVar(level *slog.LevelVar) ServerOption {
+	return func(sc *ServerContext) {
+		sc.logLevel = level
+	}
+}
+
+// WithVersion records the server version reported to clients.
+func WithVersion(version string) ServerOption {
+	return func(sc *ServerContext) {
+		sc.version = version
+	}
+}
+
 // WithPrometheusConfig sets the Prometheus configuration
 func WithPrometheusConfig(config PrometheusConfig) ServerOption {
 	return func(sc *ServerContext) {
@@ -63,6 +336,25 @@ func WithPrometheusConfig(config PrometheusConfig) ServerOption {
 	}
 }
 
+// WithTracerProvider sets the tracer provider used for spans around requests
+// to Prometheus. It fills PrometheusConfig.TracerProvider unless the
+// configuration already sets one.
+func WithTracerProvider(tp trace.TracerProvider) ServerOption {
+	return func(sc *ServerContext) {
+		sc.tracerProvider = tp
+	}
+}
+
+// WithAPIVersionNegotiation enables or disables API version negotiation,
+// overriding PrometheusConfig.DisableAPIVersionNegotiation. When enabled, the
+// client reads the Prometheus version before using API features added in 2.x
+// releases and rejects them on older servers.
+func WithAPIVersionNegotiation(enabled bool) ServerOption {
+	return func(sc *ServerContext) {
+		sc.apiVersionNegotiation = &enabled
+	}
+}
+
 // WithOAuthEnabled marks the server as running behind OAuth 2.1 middleware.
 // When true, tool handlers will attempt to extract user info from the request
 // context to perform tenancy resolution.
@@ -80,6 +372,64 @@ func WithTenancyResolver(r TenancyResolver) ServerOption {
 	}
 }
 
+// WithConnectionWarmup makes tool registration pre-establish count
+// connections to the configured Prometheus before the server starts serving.
+func WithConnectionWarmup(count int) ServerOption {
+	return func(sc *ServerContext) {
+		sc.connectionWarmup = count
+	}
+}
+
+// WithMaxResultLength sets the number of characters after which tool result
+// text is truncated. Values of 0 or less keep the default.
+func WithMaxResultLength(n int) ServerOption {
+	return func(sc *ServerContext) {
+		sc.maxResultLength = max(n, 0)
+	}
+}
+
+// WithRangeQueryPoints sets the number of samples per series that range
+// queries called without a step are sized for. Values of 0 or less keep the
+// default.
+func WithRangeQueryPoints(n int) ServerOption {
+	return func(sc *ServerContext) {
+		sc.rangeQueryPoints = max(n, 0)
+	}
+}
+
+// WithErrorVerbosity sets how much error detail tool results expose to
+// clients. See ErrorVerbositySafe.
+func WithErrorVerbosity(v ErrorVerbosity) ServerOption {
+	return func(sc *ServerContext) {
+		sc.errorVerbosity = v
+	}
+}
+
+// WithExcludedMetrics hides metric names matching any of patterns from
+// metric name listings. Patterns may use '*' wildcards (see package filter).
+func WithExcludedMetrics(patterns []string) ServerOption {
+	return func(sc *ServerContext) {
+		sc.excludedMetrics = patterns
+	}
+}
+
+// WithAdminTools registers the tools that write to Prometheus: push_metric
+// and those using the TSDB admin API, such as delete_series. They are off by
+// default.
+func WithAdminTools(enabled bool) ServerOption {
+	return func(sc *ServerContext) {
+		sc.adminTools = enabled
+	}
+}
+
+// WithRawConfig lets get_config callers pass raw=true to read the Prometheus
+// configuration without its credentials redacted. It is off by default.
+func WithRawConfig(enabled bool) ServerOption {
+	return func(sc *ServerContext) {
+		sc.rawConfig = enabled
+	}
+}
+
 // NewServerContext creates a new server context with the given options
 func NewServerContext(ctx context.Context, opts ...ServerOption) (*ServerContext, error) {
 	serverCtx, cancel := context.WithCancel(ctx)
@@ -98,18 +448,92 @@ func NewServerContext(ctx context.Context, opts ...ServerOption) (*ServerContext
 	if sc.logger == nil {
 		sc.logger = slog.New(slog.NewTextHandler(io.Discard, nil))
 	}
+	if sc.logLevel == nil {
+		sc.logLevel = new(slog.LevelVar)
+	}
+	if !sc.clientCacheSet {
+		sc.clientCache = NewClientCache(DefaultClientCacheSize, DefaultClientCacheIdleTimeout)
+	}
+
+	if sc.configFile != "" {
+		profiles, err := LoadProfiles(sc.configFile)
+		if err != nil {
+			cancel()
+			return nil, fmt.Errorf("load config file: %w", err)
+		}
+		sc.profiles = profiles
+	}
 
 	// Load Prometheus configuration from environment if not provided
+	sc.prometheusConfigSource = ConfigSourceOption
 	if sc.prometheusConfig.URL == "" {
+		sc.prometheusConfigSource = ConfigSourceEnv
 		sc.prometheusConfig = PrometheusConfig{
 			URL:           os.Getenv("PROMETHEUS_URL"),
 			Username:      os.Getenv("PROMETHEUS_USERNAME"),
 			Password:      os.Getenv("PROMETHEUS_PASSWORD"),
 			Token:         os.Getenv("PROMETHEUS_TOKEN"),
+			TokenFile:     os.Getenv("PROMETHEUS_TOKEN_FILE"),
 			OrgID:         os.Getenv("PROMETHEUS_ORGID"),
-			TLSSkipVerify: os.Getenv("PROMETHEUS_TLS_SKIP_VERIFY") == "true",
-			TLSCACert:     os.Getenv("PROMETHEUS_TLS_CA_CERT"),
+			TLSSkipVerify: os.Getenv("PROMETHEUS_TLS_SKIP_VERIFY") == "true" || os.Getenv("PROMETHEUS_TLS_INSECURE") == "true",
+			TLSCACert:     cmp.Or(os.Getenv("PROMETHEUS_TLS_CA_CERT"), os.Getenv("PROMETHEUS_CA_CERT")),
+
+			AuthMode:           os.Getenv("PROMETHEUS_AUTH_MODE"),
+			GCPCredentialsFile: os.Getenv("PROMETHEUS_GCP_CREDENTIALS_FILE"),
+			Azure: AzureConfig{
+				TenantID:     os.Getenv("PROMETHEUS_AZURE_TENANT_ID"),
+				ClientID:     os.Getenv("PROMETHEUS_AZURE_CLIENT_ID"),
+				ClientSecret: os.Getenv("PROMETHEUS_AZURE_CLIENT_SECRET"),
+			},
+			OAuth2: OAuth2Config{
+				TokenURL:     os.Getenv("PROMETHEUS_OAUTH2_TOKEN_URL"),
+				ClientID:     os.Getenv("PROMETHEUS_OAUTH2_CLIENT_ID"),
+				ClientSecret: os.Getenv("PROMETHEUS_OAUTH2_CLIENT_SECRET"),
+				Scopes:       parseScopes(os.Getenv("PROMETHEUS_OAUTH2_SCOPES")),
+			},
+
+			Alertmanager: AlertmanagerConfig{
+				URL:       os.Getenv("ALERTMANAGER_URL"),
+				Username:  os.Getenv("ALERTMANAGER_USERNAME"),
+				Password:  os.Getenv("ALERTMANAGER_PASSWORD"),
+				Token:     os.Getenv("ALERTMANAGER_TOKEN"),
+				TokenFile: os.Getenv("ALERTMANAGER_TOKEN_FILE"),
+			},
+			RemoteWriteURL: os.Getenv("PROMETHEUS_REMOTE_WRITE_URL"),
+			PathPrefix:     os.Getenv("PROMETHEUS_PATH_PREFIX"),
+			TraceBaseURL:   os.Getenv("PROMETHEUS_TRACE_BASE_URL"),
+
+			DisableAPIVersionNegotiation: os.Getenv("PROMETHEUS_API_VERSION_NEGOTIATION") == "false",
 		}
+		if instanceID, token := os.Getenv("PROMETHEUS_GRAFANA_CLOUD_INSTANCE_ID"), os.Getenv("PROMETHEUS_GRAFANA_CLOUD_TOKEN"); instanceID != "" || token != "" {
+			// Without PROMETHEUS_URL the credentials would be dropped
+			// silently in favour of the default profile.
+			if sc.prometheusConfig.URL == "" {
+				cancel()
+				return nil, errors.New("PROMETHEUS_GRAFANA_CLOUD_INSTANCE_ID and PROMETHEUS_GRAFANA_CLOUD_TOKEN need PROMETHEUS_URL; use a grafana_cloud block for profiles")
+			}
+			if err := applyGrafanaCloud(&sc.prometheusConfig, instanceID, token, ""); err != nil {
+				cancel()
+				return nil, err
+			}
+		}
+		if sc.prometheusConfig.URL == "" {
+			sc.prometheusConfigSource = ConfigSourceUnset
+			if config, ok := sc.profiles.Get(sc.profiles.Default); ok {
+				config.DisableAPIVersionNegotiation = sc.prometheusConfig.DisableAPIVersionNegotiation
+				sc.prometheusConfig = config
+				sc.prometheusConfigSource = ConfigSourceProfile
+			}
+		}
+	}
+	if sc.prometheusConfig.TracerProvider == nil {
+		sc.prometheusConfig.TracerProvider = sc.tracerProvider
+	}
+	if sc.prometheusConfig.RequestLimiter == nil {
+		sc.prometheusConfig.RequestLimiter = sc.requestLimiter
+	}
+	if sc.apiVersionNegotiation != nil {
+		sc.prometheusConfig.DisableAPIVersionNegotiation = !*sc.apiVersionNegotiation
 	}
 
 	return sc, nil
@@ -129,6 +553,43 @@ func (sc *ServerContext) Logger() *slog.Logger {
 	return sc.logger
 }
 
+// LogLevel returns the current minimum log level.
+func (sc *ServerContext) LogLevel() slog.Level {
+	sc.mutex.RLock()
+	defer sc.mutex.RUnlock()
+	return sc.logLevel.Level()
+}
+
+// SetLogLevel changes the minimum log level at runtime. It only affects the
+// logger if its handler was built with the LevelVar passed to WithLogLevelVar.
+func (sc *ServerContext) SetLogLevel(level slog.Level) {
+	sc.mutex.Lock()
+	defer sc.mutex.Unlock()
+	sc.logLevel.Set(level)
+}
+
+// SetDebugMode switches between debug and info logging.
+func (sc *ServerContext) SetDebugMode(enabled bool) {
+	if enabled {
+		sc.SetLogLevel(slog.LevelDebug)
+	} else {
+		sc.SetLogLevel(slog.LevelInfo)
+	}
+}
+
+// ToggleDebugMode switches debug logging on if the current level is above
+// debug and back to info otherwise. It returns the new level.
+func (sc *ServerContext) ToggleDebugMode() slog.Level {
+	sc.mutex.Lock()
+	defer sc.mutex.Unlock()
+	if sc.logLevel.Level() > slog.LevelDebug {
+		sc.logLevel.Set(slog.LevelDebug)
+	} else {
+		sc.logLevel.Set(slog.LevelInfo)
+	}
+	return sc.logLevel.Level()
+}
+
 // PrometheusConfig returns the Prometheus configuration
 func (sc *ServerContext) PrometheusConfig() PrometheusConfig {
 	sc.mutex.RLock()
@@ -136,6 +597,80 @@ func (sc *ServerContext) PrometheusConfig() PrometheusConfig {
 	return sc.prometheusConfig
 }
 
+// PrometheusConfigSource reports where the Prometheus configuration came
+// from: ConfigSourceOption, ConfigSourceEnv or ConfigSourceUnset.
+func (sc *ServerContext) PrometheusConfigSource() string {
+	sc.mutex.RLock()
+	defer sc.mutex.RUnlock()
+	return sc.prometheusConfigSource
+}
+
+// Version returns the server version set with WithVersion.
+func (sc *ServerContext) Version() string {
+	sc.mutex.RLock()
+	defer sc.mutex.RUnlock()
+	return sc.version
+}
+
+// ConnectionWarmup returns the number of startup warmup connections set with
+// WithConnectionWarmup (0 when disabled).
+func (sc *ServerContext) ConnectionWarmup() int {
+	sc.mutex.RLock()
+	defer sc.mutex.RUnlock()
+	return sc.connectionWarmup
+}
+
+// MaxResultLength returns the result length limit set with
+// WithMaxResultLength (0 when unset).
+func (sc *ServerContext) MaxResultLength() int {
+	sc.mutex.RLock()
+	defer sc.mutex.RUnlock()
+	return sc.maxResultLength
+}
+
+// RangeQueryPoints returns the range query point target set with
+// WithRangeQueryPoints (0 when unset).
+func (sc *ServerContext) RangeQueryPoints() int {
+	sc.mutex.RLock()
+	defer sc.mutex.RUnlock()
+	return sc.rangeQueryPoints
+}
+
+// ErrorVerbosity returns the verbosity set with WithErrorVerbosity, or
+// ErrorVerbosityDetailed when none was set.
+func (sc *ServerContext) ErrorVerbosity() ErrorVerbosity {
+	sc.mutex.RLock()
+	defer sc.mutex.RUnlock()
+	if sc.errorVerbosity == "" {
+		return ErrorVerbosityDetailed
+	}
+	return sc.errorVerbosity
+}
+
+// ExcludedMetrics returns the metric name patterns set with
+// WithExcludedMetrics (nil when none are excluded).
+func (sc *ServerContext) ExcludedMetrics() []string {
+	sc.mutex.RLock()
+	defer sc.mutex.RUnlock()
+	return sc.excludedMetrics
+}
+
+// AdminToolsEnabled reports whether WithAdminTools enabled the tools that
+// write to Prometheus.
+func (sc *ServerContext) AdminToolsEnabled() bool {
+	sc.mutex.RLock()
+	defer sc.mutex.RUnlock()
+	return sc.adminTools
+}
+
+// RawConfigAllowed reports whether WithRawConfig enabled unredacted
+// get_config output.
+func (sc *ServerContext) RawConfigAllowed() bool {
+	sc.mutex.RLock()
+	defer sc.mutex.RUnlock()
+	return sc.rawConfig
+}
+
 // IsOAuthEnabled returns whether OAuth 2.1 middleware is active.
 func (sc *ServerContext) IsOAuthEnabled() bool {
 	sc.mutex.RLock()
@@ -160,6 +695,7 @@ func (sc *ServerContext) Shutdown() error {
 		sc.cancel()
 		sc.cancel = nil
 	}
+	sc.clientCache.Close()
 
-	return nil
+	return sc.stopTestTargets()
 }
@@ -4,7 +4,10 @@ import (
 	"context"
 	"io"
 	"log/slog"
+	"strings"
 	"testing"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 )
 
 const tenantA = "tenant-a"
@@ -127,6 +130,32 @@ func TestPrometheusConfigOption(t *testing.T) {
 	}
 }
 
+func TestWithTracerProvider(t *testing.T) {
+	tp := sdktrace.NewTracerProvider()
+	sc, err := NewServerContext(context.Background(),
+		WithPrometheusConfig(PrometheusConfig{URL: "http://prom:9090"}),
+		WithTracerProvider(tp),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sc.PrometheusConfig().TracerProvider != tp {
+		t.Error("expected the tracer provider to be set on the Prometheus config")
+	}
+
+	explicit := sdktrace.NewTracerProvider()
+	sc, err = NewServerContext(context.Background(),
+		WithPrometheusConfig(PrometheusConfig{URL: "http://prom:9090", TracerProvider: explicit}),
+		WithTracerProvider(tp),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sc.PrometheusConfig().TracerProvider != explicit {
+		t.Error("expected the config's own tracer provider to take precedence")
+	}
+}
+
 func TestShutdown(t *testing.T) {
 	sc, err := NewServerContext(context.Background())
 	if err != nil {
@@ -147,3 +176,218 @@ func TestShutdown(t *testing.T) {
 		t.Errorf("second Shutdown() returned error: %v", err)
 	}
 }
+
+func TestPrometheusConfigSource(t *testing.T) {
+	t.Setenv("PROMETHEUS_URL", "")
+	sc, err := NewServerContext(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := sc.PrometheusConfigSource(); got != ConfigSourceUnset {
+		t.Errorf("PrometheusConfigSource() = %q, want %q", got, ConfigSourceUnset)
+	}
+
+	t.Setenv("PROMETHEUS_URL", "http://prometheus:9090")
+	sc, err = NewServerContext(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := sc.PrometheusConfigSource(); got != ConfigSourceEnv {
+		t.Errorf("PrometheusConfigSource() = %q, want %q", got, ConfigSourceEnv)
+	}
+
+	sc, err = NewServerContext(context.Background(), WithPrometheusConfig(PrometheusConfig{URL: "http://other:9090"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := sc.PrometheusConfigSource(); got != ConfigSourceOption {
+		t.Errorf("PrometheusConfigSource() = %q, want %q", got, ConfigSourceOption)
+	}
+}
+
+func TestPrometheusConfigAuthType(t *testing.T) {
+	tests := []struct {
+		config PrometheusConfig
+		want   string
+	}{
+		{PrometheusConfig{}, "none"},
+		{PrometheusConfig{Username: "u", Password: "p"}, "basic"},
+		{PrometheusConfig{Username: "u"}, "none"},
+		{PrometheusConfig{Token: "t"}, "bearer"},
+		{PrometheusConfig{TokenFile: "/var/run/secrets/token"}, "bearer"},
+		{PrometheusConfig{Token: "t", AuthMode: AuthModeGCP}, "gcp"},
+		{PrometheusConfig{Token: "t", AuthMode: AuthModeAzure}, "azure"},
+		{PrometheusConfig{Token: "t", OAuth2: OAuth2Config{TokenURL: "https://auth/token", ClientID: "c"}}, "oauth2"},
+	}
+	for _, tt := range tests {
+		if got := tt.config.AuthType(); got != tt.want {
+			t.Errorf("AuthType(%+v) = %q, want %q", tt.config, got, tt.want)
+		}
+	}
+}
+
+func TestErrorVerbosity(t *testing.T) {
+	sc, err := NewServerContext(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := sc.ErrorVerbosity(); got != ErrorVerbosityDetailed {
+		t.Errorf("ErrorVerbosity() = %q by default, want %q", got, ErrorVerbosityDetailed)
+	}
+
+	sc, err = NewServerContext(context.Background(), WithErrorVerbosity(ErrorVerbositySafe))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := sc.ErrorVerbosity(); got != ErrorVerbositySafe {
+		t.Errorf("ErrorVerbosity() = %q, want %q", got, ErrorVerbositySafe)
+	}
+}
+
+func TestParseErrorVerbosity(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    ErrorVerbosity
+		wantErr bool
+	}{
+		{in: "", want: ErrorVerbosityDetailed},
+		{in: "detailed", want: ErrorVerbosityDetailed},
+		{in: " SAFE ", want: ErrorVerbositySafe},
+		{in: "verbose", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseErrorVerbosity(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseErrorVerbosity(%q) = %q, %v; want %q, error %t", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestWithAPIVersionNegotiation(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    []ServerOption
+		disable bool
+	}{
+		{name: "default", opts: nil, disable: false},
+		{name: "disabled", opts: []ServerOption{WithAPIVersionNegotiation(false)}, disable: true},
+		{name: "enabled", opts: []ServerOption{WithAPIVersionNegotiation(true)}, disable: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := append([]ServerOption{WithPrometheusConfig(PrometheusConfig{URL: "http://prom:9090"})}, tt.opts...)
+			sc, err := NewServerContext(context.Background(), opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := sc.PrometheusConfig().DisableAPIVersionNegotiation; got != tt.disable {
+				t.Errorf("DisableAPIVersionNegotiation = %v, want %v", got, tt.disable)
+			}
+		})
+	}
+}
+
+func TestWithExcludedMetrics(t *testing.T) {
+	sc, err := NewServerContext(context.Background(), WithPrometheusConfig(PrometheusConfig{URL: "http://prom:9090"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := sc.ExcludedMetrics(); got != nil {
+		t.Errorf("expected no excluded metrics by default, got %q", got)
+	}
+
+	sc, err = NewServerContext(context.Background(),
+		WithPrometheusConfig(PrometheusConfig{URL: "http://prom:9090"}),
+		WithExcludedMetrics([]string{"up", "go_*"}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := sc.ExcludedMetrics(); len(got) != 2 || got[0] != "up" || got[1] != "go_*" {
+		t.Errorf("unexpected excluded metrics: %q", got)
+	}
+}
+
+func TestWithMaxResultLength(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []ServerOption
+		want int
+	}{
+		{name: "default", opts: nil, want: 0},
+		{name: "set", opts: []ServerOption{WithMaxResultLength(200000)}, want: 200000},
+		{name: "negative keeps default", opts: []ServerOption{WithMaxResultLength(-1)}, want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := append([]ServerOption{WithPrometheusConfig(PrometheusConfig{URL: "http://prom:9090"})}, tt.opts...)
+			sc, err := NewServerContext(context.Background(), opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := sc.MaxResultLength(); got != tt.want {
+				t.Errorf("MaxResultLength() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithRangeQueryPoints(t *testing.T) {
+	for n, want := range map[int]int{500: 500, -1: 0} {
+		sc, err := NewServerContext(context.Background(),
+			WithPrometheusConfig(PrometheusConfig{URL: "http://prom:9090"}),
+			WithRangeQueryPoints(n),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := sc.RangeQueryPoints(); got != want {
+			t.Errorf("WithRangeQueryPoints(%d): RangeQueryPoints() = %d, want %d", n, got, want)
+		}
+	}
+}
+
+func TestPrometheusTLSEnvAliases(t *testing.T) {
+	t.Setenv("PROMETHEUS_URL", "https://prometheus:9090")
+	t.Setenv("PROMETHEUS_TLS_SKIP_VERIFY", "")
+	t.Setenv("PROMETHEUS_TLS_INSECURE", "true")
+	t.Setenv("PROMETHEUS_TLS_CA_CERT", "")
+	t.Setenv("PROMETHEUS_CA_CERT", "/etc/ssl/internal-ca.pem")
+	sc, err := NewServerContext(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := sc.PrometheusConfig()
+	if !got.TLSSkipVerify || got.TLSCACert != "/etc/ssl/internal-ca.pem" {
+		t.Errorf("TLSSkipVerify = %t, TLSCACert = %q; want the aliases applied", got.TLSSkipVerify, got.TLSCACert)
+	}
+
+	// The original names win when both are set.
+	t.Setenv("PROMETHEUS_TLS_CA_CERT", "/etc/ssl/prometheus-ca.pem")
+	sc, err = NewServerContext(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := sc.PrometheusConfig().TLSCACert; got != "/etc/ssl/prometheus-ca.pem" {
+		t.Errorf("TLSCACert = %q, want PROMETHEUS_TLS_CA_CERT", got)
+	}
+}
+
+func TestPrometheusOAuth2Env(t *testing.T) {
+	t.Setenv("PROMETHEUS_URL", "https://prometheus:9090")
+	t.Setenv("PROMETHEUS_OAUTH2_TOKEN_URL", "https://auth.example.com/token")
+	t.Setenv("PROMETHEUS_OAUTH2_CLIENT_ID", "mcp")
+	t.Setenv("PROMETHEUS_OAUTH2_CLIENT_SECRET", "s3cret")
+	t.Setenv("PROMETHEUS_OAUTH2_SCOPES", "metrics.read, metrics.query openid")
+	sc, err := NewServerContext(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := sc.PrometheusConfig().OAuth2
+	if got.TokenURL != "https://auth.example.com/token" || got.ClientID != "mcp" || got.ClientSecret != "s3cret" {
+		t.Errorf("OAuth2 = %+v", got)
+	}
+	if strings.Join(got.Scopes, "|") != "metrics.read|metrics.query|openid" {
+		t.Errorf("Scopes = %q", got.Scopes)
+	}
+}
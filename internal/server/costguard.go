@@ -0,0 +1,73 @@
+package server
+
+import (
+	"fmt"
+	"strings"
+)
+
+// QueryCostMode controls what execute_query and execute_range_query do with
+// a query whose estimated cost exceeds the QueryCostGuard limits.
+type QueryCostMode string
+
+const (
+	// QueryCostOff runs queries without estimating their cost.
+	QueryCostOff QueryCostMode = "off"
+	// QueryCostWarn runs expensive queries with a warning in the result.
+	QueryCostWarn QueryCostMode = "warn"
+	// QueryCostRefuse refuses expensive queries with guidance on narrowing
+	// them.
+	QueryCostRefuse QueryCostMode = "refuse"
+)
+
+// Default QueryCostGuard limits.
+const (
+	DefaultMaxQuerySeries  = 10000
+	DefaultMaxQuerySamples = 1000000
+)
+
+// ParseQueryCostMode parses a --query-cost-guard value. The empty string
+// selects QueryCostOff.
+func ParseQueryCostMode(s string) (QueryCostMode, error) {
+	switch m := QueryCostMode(strings.ToLower(strings.TrimSpace(s))); m {
+	case "":
+		return QueryCostOff, nil
+	case QueryCostOff, QueryCostWarn, QueryCostRefuse:
+		return m, nil
+	default:
+		return "", fmt.Errorf("invalid query cost guard %q: must be %q, %q or %q", s, QueryCostOff, QueryCostWarn, QueryCostRefuse)
+	}
+}
+
+// QueryCostGuard configures the cost estimate run before queries: the
+// number of series their selectors match over the queried window and the
+// samples they return (series times evaluation steps). A limit of 0 is not
+// checked.
+type QueryCostGuard struct {
+	Mode       QueryCostMode
+	MaxSeries  int
+	MaxSamples int64
+	// FailOpen runs queries whose cost cannot be estimated, e.g. because
+	// the series lookup failed. By default refuse mode refuses them and
+	// warn mode runs them with a warning.
+	FailOpen bool
+}
+
+// Enabled reports whether queries are estimated before they run.
+func (g QueryCostGuard) Enabled() bool {
+	return (g.Mode == QueryCostWarn || g.Mode == QueryCostRefuse) && (g.MaxSeries > 0 || g.MaxSamples > 0)
+}
+
+// WithQueryCostGuard sets the query cost guard. Without it queries run
+// unchecked.
+func WithQueryCostGuard(g QueryCostGuard) ServerOption {
+	return func(sc *ServerContext) {
+		sc.queryCostGuard = g
+	}
+}
+
+// QueryCostGuard returns the guard set with WithQueryCostGuard.
+func (sc *ServerContext) QueryCostGuard() QueryCostGuard {
+	sc.mutex.RLock()
+	defer sc.mutex.RUnlock()
+	return sc.queryCostGuard
+}
@@ -0,0 +1,32 @@
+package server
+
+import "testing"
+
+func TestParseQueryCostMode(t *testing.T) {
+	for in, want := range map[string]QueryCostMode{"": QueryCostOff, "off": QueryCostOff, " Warn ": QueryCostWarn, "refuse": QueryCostRefuse} {
+		if got, err := ParseQueryCostMode(in); err != nil || got != want {
+			t.Errorf("ParseQueryCostMode(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	if _, err := ParseQueryCostMode("block"); err == nil {
+		t.Error("expected an error for an unknown mode")
+	}
+}
+
+func TestQueryCostGuardEnabled(t *testing.T) {
+	tests := []struct {
+		guard QueryCostGuard
+		want  bool
+	}{
+		{QueryCostGuard{}, false},
+		{QueryCostGuard{Mode: QueryCostOff, MaxSeries: 10}, false},
+		{QueryCostGuard{Mode: QueryCostWarn}, false},
+		{QueryCostGuard{Mode: QueryCostWarn, MaxSamples: 10}, true},
+		{QueryCostGuard{Mode: QueryCostRefuse, MaxSeries: 10}, true},
+	}
+	for _, tt := range tests {
+		if got := tt.guard.Enabled(); got != tt.want {
+			t.Errorf("%+v.Enabled() = %t, want %t", tt.guard, got, tt.want)
+		}
+	}
+}
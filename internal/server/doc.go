@@ -4,6 +4,7 @@
 // - ServerContext: Configuration and shared resources management
 // - Logger interface: Structured logging abstraction
 // - Configuration options: Functional options pattern for server setup
+// - ToolMiddleware: custom pre/post logic run around every Prometheus tool call
 //
 // The ServerContext manages the lifecycle of the server and provides
 // thread-safe access to configuration options such as:
@@ -19,4 +20,8 @@
 //	    server.WithDebugMode(true),
 //	    server.WithLogger(logger),
 //	)
+//
+// Embedding applications add their own tools by implementing
+// plugin.ToolPlugin from pkg/plugin; WithToolPlugin registers one with the
+// ServerContext, which is the plugin.Host its Register receives.
 package server
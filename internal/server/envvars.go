@@ -0,0 +1,63 @@
+package server
+
+import (
+	"slices"
+	"strings"
+)
+
+// EnvVarDoc documents one environment variable the server reads.
+type EnvVarDoc struct {
+	Name        string
+	Default     string
+	Description string
+	// Sensitive values (credentials) are never reported, only whether they
+	// are set.
+	Sensitive bool
+}
+
+// envVarRegistry lists every PROMETHEUS_* and ALERTMANAGER_* environment
+// variable read by the server. TestEnvVarRegistryComplete fails when a new
+// os.Getenv call is not listed here.
+var envVarRegistry = []EnvVarDoc{
+	{Name: "PROMETHEUS_URL", Description: "Prometheus/Mimir base URL"},
+	{Name: "PROMETHEUS_USERNAME", Description: "Basic auth username"},
+	{Name: "PROMETHEUS_PASSWORD", Description: "Basic auth password", Sensitive: true},
+	{Name: "PROMETHEUS_TOKEN", Description: "Bearer token", Sensitive: true},
+	{Name: "PROMETHEUS_TOKEN_FILE", Description: "File holding the bearer token, re-read every minute so rotated tokens are picked up"},
+	{Name: "PROMETHEUS_GRAFANA_CLOUD_INSTANCE_ID", Description: "Grafana Cloud Prometheus instance ID, used as the basic auth username"},
+	{Name: "PROMETHEUS_GRAFANA_CLOUD_TOKEN", Description: "Grafana Cloud access policy token, used as the basic auth password", Sensitive: true},
+	{Name: "PROMETHEUS_AUTH_MODE", Description: "Authentication scheme not implied by the credentials: gcp (Google Cloud Managed Service for Prometheus) or azure (Azure Monitor managed Prometheus)"},
+	{Name: "PROMETHEUS_GCP_CREDENTIALS_FILE", Description: "Service account key for PROMETHEUS_AUTH_MODE=gcp (default: Application Default Credentials)"},
+	{Name: "PROMETHEUS_AZURE_TENANT_ID", Description: "Azure AD tenant of the service principal for PROMETHEUS_AUTH_MODE=azure"},
+	{Name: "PROMETHEUS_AZURE_CLIENT_ID", Description: "Azure AD service principal or user-assigned managed identity client ID"},
+	{Name: "PROMETHEUS_AZURE_CLIENT_SECRET", Description: "Azure AD service principal secret; managed identity is used when unset", Sensitive: true},
+	{Name: "PROMETHEUS_OAUTH2_TOKEN_URL", Description: "OAuth2 token endpoint for the client credentials grant"},
+	{Name: "PROMETHEUS_OAUTH2_CLIENT_ID", Description: "OAuth2 client ID"},
+	{Name: "PROMETHEUS_OAUTH2_CLIENT_SECRET", Description: "OAuth2 client secret", Sensitive: true},
+	{Name: "PROMETHEUS_OAUTH2_SCOPES", Description: "Comma-separated OAuth2 scopes to request"},
+	{Name: "PROMETHEUS_ORGID", Description: "Default Mimir org/tenant ID"},
+	{Name: "PROMETHEUS_TLS_SKIP_VERIFY", Default: "false", Description: "Skip TLS certificate verification"},
+	{Name: "PROMETHEUS_TLS_INSECURE", Default: "false", Description: "Alias of PROMETHEUS_TLS_SKIP_VERIFY"},
+	{Name: "PROMETHEUS_TLS_CA_CERT", Description: "Path to a PEM CA certificate bundle"},
+	{Name: "PROMETHEUS_CA_CERT", Description: "Alias of PROMETHEUS_TLS_CA_CERT, which takes precedence"},
+	{Name: "PROMETHEUS_PATH_PREFIX", Description: "Sub-path Prometheus is served under behind a reverse proxy"},
+	{Name: "PROMETHEUS_REMOTE_WRITE_URL", Description: "Remote write endpoint used by push_metric"},
+	{Name: "PROMETHEUS_TRACE_BASE_URL", Description: "Trace UI that query_exemplars links trace IDs to"},
+	{Name: "PROMETHEUS_EXCLUDED_METRICS", Description: "Comma-separated metric name patterns (with * wildcards) hidden from metric name listings"},
+	{Name: "PROMETHEUS_REPLICA_LABEL", Default: "prometheus_replica", Description: "Label distinguishing Prometheus replicas when execute_query deduplicates results (deduplicate=true)"},
+	{Name: "PROMETHEUS_TENANT_ROUTES", Description: "Per-tenant backend URLs as tenant1=http://shard1:9090,tenant2=http://shard2:9090", Sensitive: true},
+	{Name: "PROMETHEUS_API_VERSION_NEGOTIATION", Default: "true", Description: "Check the Prometheus version before using newer API features; false disables"},
+	{Name: "PROMETHEUS_ERROR_VERBOSITY", Default: string(ErrorVerbosityDetailed), Description: "Error detail returned to clients: detailed or safe"},
+	{Name: "ALERTMANAGER_URL", Description: "Alertmanager base URL (default: discovered from Prometheus)"},
+	{Name: "ALERTMANAGER_USERNAME", Description: "Alertmanager basic auth username (default: the Prometheus credentials are used)"},
+	{Name: "ALERTMANAGER_PASSWORD", Description: "Alertmanager basic auth password", Sensitive: true},
+	{Name: "ALERTMANAGER_TOKEN", Description: "Alertmanager bearer token", Sensitive: true},
+	{Name: "ALERTMANAGER_TOKEN_FILE", Description: "File holding the Alertmanager bearer token, re-read every minute"},
+}
+
+// EnvVars returns the documented environment variables sorted by name.
+func EnvVars() []EnvVarDoc {
+	docs := slices.Clone(envVarRegistry)
+	slices.SortFunc(docs, func(a, b EnvVarDoc) int { return strings.Compare(a.Name, b.Name) })
+	return docs
+}
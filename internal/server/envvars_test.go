@@ -0,0 +1,91 @@
+package server
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// readEnvVars returns the PROMETHEUS_* and ALERTMANAGER_* names passed as
+// string literals to os.Getenv or os.LookupEnv in the non-test Go files
+// under root.
+func readEnvVars(t *testing.T, root string) map[string]string {
+	t.Helper()
+	found := make(map[string]string)
+	fset := token.NewFileSet()
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() && (d.Name() == "vendor" || strings.HasPrefix(d.Name(), ".")) && path != root {
+			return filepath.SkipDir
+		}
+		if d.IsDir() || !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
+			return nil
+		}
+		f, err := parser.ParseFile(fset, path, nil, 0)
+		if err != nil {
+			return err
+		}
+		ast.Inspect(f, func(n ast.Node) bool {
+			call, ok := n.(*ast.CallExpr)
+			if !ok || len(call.Args) == 0 {
+				return true
+			}
+			sel, ok := call.Fun.(*ast.SelectorExpr)
+			if !ok || (sel.Sel.Name != "Getenv" && sel.Sel.Name != "LookupEnv") {
+				return true
+			}
+			if pkg, ok := sel.X.(*ast.Ident); !ok || pkg.Name != "os" {
+				return true
+			}
+			lit, ok := call.Args[0].(*ast.BasicLit)
+			if !ok || lit.Kind != token.STRING {
+				return true
+			}
+			name, err := strconv.Unquote(lit.Value)
+			if err == nil && (strings.HasPrefix(name, "PROMETHEUS_") || strings.HasPrefix(name, "ALERTMANAGER_")) {
+				found[name] = fset.Position(lit.Pos()).String()
+			}
+			return true
+		})
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("scan %s: %v", root, err)
+	}
+	return found
+}
+
+func TestEnvVarRegistryComplete(t *testing.T) {
+	documented := make(map[string]bool)
+	for _, doc := range EnvVars() {
+		if documented[doc.Name] {
+			t.Errorf("%s is documented twice", doc.Name)
+		}
+		documented[doc.Name] = true
+		if doc.Description == "" {
+			t.Errorf("%s has no description", doc.Name)
+		}
+	}
+
+	read := readEnvVars(t, filepath.Join("..", ".."))
+	if _, ok := read["PROMETHEUS_URL"]; !ok {
+		t.Fatal("scan did not find the PROMETHEUS_URL read in NewServerContext")
+	}
+	for name, pos := range read {
+		if !documented[name] {
+			t.Errorf("%s is read at %s but missing from envVarRegistry", name, pos)
+		}
+	}
+	for name := range documented {
+		if _, ok := read[name]; !ok {
+			t.Errorf("%s is documented but never read", name)
+		}
+	}
+}
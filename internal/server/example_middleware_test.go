@@ -0,0 +1,68 @@
+package server_test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/giantswarm/mcp-oauth/handler"
+	"github.com/giantswarm/mcp-oauth/providers"
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/giantswarm/mcp-prometheus/internal/server"
+)
+
+// perUserRateLimit returns a ToolMiddleware allowing user at most limit tool
+// calls per minute. Other users are not limited.
+func perUserRateLimit(user string, limit int) server.ToolMiddleware {
+	var mu sync.Mutex
+	var window time.Time
+	var calls int
+
+	return func(ctx context.Context, toolName string, next func() (*mcp.CallToolResult, error)) (*mcp.CallToolResult, error) {
+		if info, ok := handler.UserInfoFromContext(ctx); !ok || info.Email != user {
+			return next()
+		}
+
+		mu.Lock()
+		if now := time.Now(); now.Sub(window) >= time.Minute {
+			window, calls = now, 0
+		}
+		calls++
+		allowed := calls <= limit
+		mu.Unlock()
+
+		if !allowed {
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{
+					mcp.TextContent{Type: "text", Text: fmt.Sprintf("rate limit exceeded: %s may make %d calls per minute", user, limit)},
+				},
+			}, nil
+		}
+		return next()
+	}
+}
+
+func ExampleWithToolMiddleware() {
+	sc, err := server.NewServerContext(context.Background(),
+		server.WithToolMiddleware(perUserRateLimit("alice@example.com", 2)),
+	)
+	if err != nil {
+		panic(err)
+	}
+	defer func() { _ = sc.Shutdown() }()
+
+	ctx := handler.ContextWithUserInfo(context.Background(), &providers.UserInfo{Email: "alice@example.com"})
+	for range 3 {
+		result, _ := sc.RunToolMiddleware(ctx, "execute_query", func() (*mcp.CallToolResult, error) {
+			return &mcp.CallToolResult{Content: []mcp.Content{mcp.TextContent{Type: "text", Text: "ok"}}}, nil
+		})
+		fmt.Println(result.Content[0].(mcp.TextContent).Text)
+	}
+	// Output:
+	// ok
+	// ok
+	// rate limit exceeded: alice@example.com may make 2 calls per minute
+}
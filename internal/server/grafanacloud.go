@@ -0,0 +1,57 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// grafanaCloudPathPrefix is the path Grafana Cloud serves the Prometheus API
+// under.
+const grafanaCloudPathPrefix = "/api/prom"
+
+// applyGrafanaCloud sets config up for a Grafana Cloud Prometheus endpoint:
+// the stack's instance ID and an access policy token, given directly or as a
+// file the client re-reads, become basic auth credentials, and /api/prom is
+// added to the URL when it is missing. config.URL may be the query URL from
+// the Grafana Cloud portal
+// (https://prometheus-prod-01-eu-west-0.grafana.net/api/prom), the remote
+// write URL ending in /push, which also becomes RemoteWriteURL, or the bare
+// host.
+func applyGrafanaCloud(config *PrometheusConfig, instanceID, token, tokenFile string) error {
+	if token != "" && tokenFile != "" {
+		return errors.New("set grafana cloud token or token_file, not both")
+	}
+	if instanceID == "" || (token == "" && tokenFile == "") {
+		return errors.New("grafana cloud needs both an instance ID and a token")
+	}
+	if _, err := strconv.ParseUint(instanceID, 10, 64); err != nil {
+		return fmt.Errorf("grafana cloud instance ID must be numeric (got %q)", instanceID)
+	}
+	if config.Username != "" || config.Password != "" || config.PasswordFile != "" || config.Token != "" || config.TokenFile != "" {
+		return errors.New("grafana cloud credentials replace the username, password and token settings; unset them")
+	}
+	u, err := url.Parse(config.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("grafana cloud needs the full URL of the stack's Prometheus endpoint (got %q)", config.URL)
+	}
+
+	path := strings.TrimRight(u.Path, "/")
+	if trimmed, ok := strings.CutSuffix(path, "/push"); ok {
+		if config.RemoteWriteURL == "" {
+			config.RemoteWriteURL = u.String()
+		}
+		path = trimmed
+	}
+	if !strings.HasSuffix(path, grafanaCloudPathPrefix) && config.PathPrefix == "" {
+		config.PathPrefix = grafanaCloudPathPrefix
+	}
+	u.Path = path
+	config.URL = u.String()
+	config.Username = instanceID
+	config.Password = token
+	config.PasswordFile = tokenFile
+	return nil
+}
@@ -0,0 +1,79 @@
+package server
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestApplyGrafanaCloud(t *testing.T) {
+	const host = "https://prometheus-prod-01-eu-west-0.grafana.net"
+	tests := []struct {
+		name            string
+		url             string
+		wantURL         string
+		wantPrefix      string
+		wantRemoteWrite string
+	}{
+		{name: "query URL", url: host + "/api/prom", wantURL: host + "/api/prom"},
+		{name: "trailing slash", url: host + "/api/prom/", wantURL: host + "/api/prom"},
+		{name: "bare host", url: host, wantURL: host, wantPrefix: "/api/prom"},
+		{name: "remote write URL", url: host + "/api/prom/push", wantURL: host + "/api/prom", wantRemoteWrite: host + "/api/prom/push"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := PrometheusConfig{URL: tt.url}
+			if err := applyGrafanaCloud(&config, "123456", "glc_token", ""); err != nil {
+				t.Fatalf("applyGrafanaCloud: %v", err)
+			}
+			if config.URL != tt.wantURL || config.PathPrefix != tt.wantPrefix || config.RemoteWriteURL != tt.wantRemoteWrite {
+				t.Errorf("got URL %q, prefix %q, remote write %q", config.URL, config.PathPrefix, config.RemoteWriteURL)
+			}
+			if config.Username != "123456" || config.Password != "glc_token" || config.AuthType() != "basic" {
+				t.Errorf("got credentials %q/%q", config.Username, config.Password)
+			}
+		})
+	}
+
+	for name, tt := range map[string]struct {
+		config                       PrometheusConfig
+		instanceID, token, tokenFile string
+	}{
+		"missing token":        {PrometheusConfig{URL: host}, "123456", "", ""},
+		"token and token file": {PrometheusConfig{URL: host}, "123456", "glc_token", "/run/secrets/glc"},
+		"non-numeric ID":       {PrometheusConfig{URL: host}, "my-stack", "glc_token", ""},
+		"conflicting token":    {PrometheusConfig{URL: host, Token: "t"}, "123456", "glc_token", ""},
+		"conflicting password": {PrometheusConfig{URL: host, PasswordFile: "/run/secrets/p"}, "123456", "glc_token", ""},
+		"relative URL":         {PrometheusConfig{URL: "prometheus-prod-01-eu-west-0.grafana.net"}, "123456", "glc_token", ""},
+	} {
+		if err := applyGrafanaCloud(&tt.config, tt.instanceID, tt.token, tt.tokenFile); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestGrafanaCloudEnv(t *testing.T) {
+	t.Setenv("PROMETHEUS_URL", "https://prometheus-prod-01-eu-west-0.grafana.net")
+	t.Setenv("PROMETHEUS_GRAFANA_CLOUD_INSTANCE_ID", "123456")
+	t.Setenv("PROMETHEUS_GRAFANA_CLOUD_TOKEN", "glc_token")
+	sc, err := NewServerContext(context.Background())
+	if err != nil {
+		t.Fatalf("NewServerContext: %v", err)
+	}
+	defer func() { _ = sc.Shutdown() }()
+	if got := sc.PrometheusConfig(); got.Username != "123456" || got.PathPrefix != "/api/prom" {
+		t.Errorf("PrometheusConfig() = %+v", got)
+	}
+
+	t.Setenv("PROMETHEUS_GRAFANA_CLOUD_TOKEN", "")
+	if _, err := NewServerContext(context.Background()); err == nil || !strings.Contains(err.Error(), "instance ID and a token") {
+		t.Errorf("expected an error for an instance ID without a token, got %v", err)
+	}
+
+	// Without PROMETHEUS_URL the credentials are not silently dropped.
+	t.Setenv("PROMETHEUS_URL", "")
+	t.Setenv("PROMETHEUS_GRAFANA_CLOUD_TOKEN", "glc_token")
+	if _, err := NewServerContext(context.Background()); err == nil || !strings.Contains(err.Error(), "need PROMETHEUS_URL") {
+		t.Errorf("expected an error for Grafana Cloud credentials without a URL, got %v", err)
+	}
+}
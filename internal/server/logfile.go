@@ -0,0 +1,104 @@
+package server
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+// Special --log-output values; anything else is treated as a file path.
+const (
+	LogOutputStderr = "stderr"
+	LogOutputStdout = "stdout"
+)
+
+// LogFile is an append-only log file that can be reopened at the same path,
+// so external tools such as logrotate can move it away and have the server
+// start a fresh file. It is safe for concurrent use.
+type LogFile struct {
+	mu   sync.Mutex
+	path string
+	file *os.File
+}
+
+// OpenLogFile opens (or creates) path for appending.
+func OpenLogFile(path string) (*LogFile, error) {
+	f, err := openAppend(path)
+	if err != nil {
+		return nil, err
+	}
+	return &LogFile{path: path, file: f}, nil
+}
+
+func openAppend(path string) (*os.File, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("open log file %q: %w", path, err)
+	}
+	return f, nil
+}
+
+// Path returns the file path the log is written to.
+func (l *LogFile) Path() string {
+	return l.path
+}
+
+// Write implements io.Writer.
+func (l *LogFile) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.file == nil {
+		return 0, os.ErrClosed
+	}
+	return l.file.Write(p)
+}
+
+// Reopen closes the current file handle and opens path again, creating the
+// file if it was moved or deleted. On failure the old handle is kept.
+func (l *LogFile) Reopen() error {
+	f, err := openAppend(l.path)
+	if err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	old := l.file
+	l.file = f
+	l.mu.Unlock()
+
+	if old != nil {
+		return old.Close()
+	}
+	return nil
+}
+
+// Close closes the file. Later writes fail with os.ErrClosed.
+func (l *LogFile) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.file == nil {
+		return nil
+	}
+	err := l.file.Close()
+	l.file = nil
+	return err
+}
+
+// OpenLogOutput resolves a --log-output value to a writer: LogOutputStderr,
+// LogOutputStdout or a file path opened with OpenLogFile. The returned
+// *LogFile is nil unless a file was opened.
+func OpenLogOutput(dest string) (io.Writer, *LogFile, error) {
+	switch dest {
+	case "", LogOutputStderr:
+		return os.Stderr, nil, nil
+	case LogOutputStdout:
+		return os.Stdout, nil, nil
+	default:
+		f, err := OpenLogFile(dest)
+		if err != nil {
+			return nil, nil, err
+		}
+		return f, f, nil
+	}
+}
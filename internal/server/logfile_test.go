@@ -0,0 +1,111 @@
+package server
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestOpenLogOutput(t *testing.T) {
+	for dest, want := range map[string]*os.File{"": os.Stderr, "stderr": os.Stderr, "stdout": os.Stdout} {
+		w, f, err := OpenLogOutput(dest)
+		if err != nil {
+			t.Fatalf("OpenLogOutput(%q): %v", dest, err)
+		}
+		if w != want || f != nil {
+			t.Errorf("OpenLogOutput(%q) = %v, %v; want %v and no log file", dest, w, f, want)
+		}
+	}
+
+	path := filepath.Join(t.TempDir(), "server.log")
+	w, f, err := OpenLogOutput(path)
+	if err != nil {
+		t.Fatalf("OpenLogOutput(file): %v", err)
+	}
+	defer func() { _ = f.Close() }()
+	if w != f || f.Path() != path {
+		t.Errorf("expected the writer to be the log file at %s", path)
+	}
+
+	if _, _, err := OpenLogOutput(filepath.Join(t.TempDir(), "missing", "server.log")); err == nil {
+		t.Error("expected an error for a file in a missing directory")
+	}
+}
+
+func TestLogFileAppendsAndReopens(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "server.log")
+	if err := os.WriteFile(path, []byte("existing\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := OpenLogFile(path)
+	if err != nil {
+		t.Fatalf("OpenLogFile: %v", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	if _, err := f.Write([]byte("first\n")); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	assertFileContent(t, path, "existing\nfirst\n")
+
+	// Simulate logrotate moving the file away.
+	rotated := path + ".1"
+	if err := os.Rename(path, rotated); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Reopen(); err != nil {
+		t.Fatalf("Reopen: %v", err)
+	}
+	if _, err := f.Write([]byte("second\n")); err != nil {
+		t.Fatalf("Write after reopen: %v", err)
+	}
+	assertFileContent(t, rotated, "existing\nfirst\n")
+	assertFileContent(t, path, "second\n")
+
+	if err := f.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if _, err := f.Write([]byte("late\n")); err == nil {
+		t.Error("expected write after Close to fail")
+	}
+}
+
+func TestLogFileConcurrentWrites(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "server.log")
+	f, err := OpenLogFile(path)
+	if err != nil {
+		t.Fatalf("OpenLogFile: %v", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	var wg sync.WaitGroup
+	for range 50 {
+		wg.Go(func() {
+			_, _ = f.Write([]byte("line\n"))
+			_ = f.Reopen()
+		})
+	}
+	wg.Wait()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Count(string(data), "line\n"); got != 50 {
+		t.Errorf("expected 50 lines, got %d", got)
+	}
+}
+
+func assertFileContent(t *testing.T, path, want string) {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read %s: %v", path, err)
+	}
+	if string(data) != want {
+		t.Errorf("%s = %q, want %q", path, data, want)
+	}
+}
@@ -0,0 +1,67 @@
+package server
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// ToolMiddleware runs custom logic around a tool call, e.g. RBAC
+// checks, cost attribution or compliance logging. Code before next() is the
+// pre-hook; the tool's arguments are available via ToolCallFromContext(ctx).
+// Code after next() is the post-hook and sees the result. Returning without
+// calling next() rejects the call.
+type ToolMiddleware func(ctx context.Context, toolName string, next func() (*mcp.CallToolResult, error)) (*mcp.CallToolResult, error)
+
+// ToolCall describes the tool call a ToolMiddleware is running for.
+type ToolCall struct {
+	// Name is the registered tool name.
+	Name string
+
+	// Arguments are the arguments the client passed. Middleware may read
+	// them but should not modify them.
+	Arguments map[string]any
+
+	// Header holds extra HTTP headers sent with every Prometheus request
+	// made by this call. Middleware may add to it in its pre-hook.
+	Header http.Header
+}
+
+type toolCallKey struct{}
+
+// ContextWithToolCall returns a copy of ctx carrying call.
+func ContextWithToolCall(ctx context.Context, call *ToolCall) context.Context {
+	return context.WithValue(ctx, toolCallKey{}, call)
+}
+
+// ToolCallFromContext returns the tool call ctx belongs to, if any.
+func ToolCallFromContext(ctx context.Context) (*ToolCall, bool) {
+	call, ok := ctx.Value(toolCallKey{}).(*ToolCall)
+	return call, ok
+}
+
+// WithToolMiddleware appends middleware run around every built-in tool
+// call. The first middleware given is the outermost.
+func WithToolMiddleware(middleware ...ToolMiddleware) ServerOption {
+	return func(sc *ServerContext) {
+		sc.toolMiddleware = append(sc.toolMiddleware, middleware...)
+	}
+}
+
+// RunToolMiddleware calls final wrapped in the middleware registered with
+// WithToolMiddleware, outermost first. ctx should carry a ToolCall.
+func (sc *ServerContext) RunToolMiddleware(ctx context.Context, toolName string, final func() (*mcp.CallToolResult, error)) (*mcp.CallToolResult, error) {
+	sc.mutex.RLock()
+	middleware := sc.toolMiddleware
+	sc.mutex.RUnlock()
+
+	call := final
+	for i := len(middleware) - 1; i >= 0; i-- {
+		mw, next := middleware[i], call
+		call = func() (*mcp.CallToolResult, error) {
+			return mw(ctx, toolName, next)
+		}
+	}
+	return call()
+}
@@ -0,0 +1,87 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestRunToolMiddlewareOrder(t *testing.T) {
+	var order []string
+	recorder := func(name string) ToolMiddleware {
+		return func(ctx context.Context, toolName string, next func() (*mcp.CallToolResult, error)) (*mcp.CallToolResult, error) {
+			order = append(order, name+":pre:"+toolName)
+			result, err := next()
+			order = append(order, name+":post")
+			return result, err
+		}
+	}
+
+	sc, err := NewServerContext(context.Background(),
+		WithToolMiddleware(recorder("outer")),
+		WithToolMiddleware(recorder("inner")),
+	)
+	if err != nil {
+		t.Fatalf("NewServerContext: %v", err)
+	}
+	defer func() { _ = sc.Shutdown() }()
+
+	want := &mcp.CallToolResult{}
+	got, err := sc.RunToolMiddleware(context.Background(), "execute_query", func() (*mcp.CallToolResult, error) {
+		order = append(order, "handler")
+		return want, nil
+	})
+	if err != nil || got != want {
+		t.Fatalf("RunToolMiddleware = %v, %v; want the handler result", got, err)
+	}
+
+	wantOrder := []string{"outer:pre:execute_query", "inner:pre:execute_query", "handler", "inner:post", "outer:post"}
+	if !reflect.DeepEqual(order, wantOrder) {
+		t.Errorf("order = %v, want %v", order, wantOrder)
+	}
+}
+
+func TestRunToolMiddlewareShortCircuit(t *testing.T) {
+	errDenied := errors.New("denied")
+	sc, err := NewServerContext(context.Background(), WithToolMiddleware(
+		func(ctx context.Context, toolName string, next func() (*mcp.CallToolResult, error)) (*mcp.CallToolResult, error) {
+			call, ok := ToolCallFromContext(ctx)
+			if !ok || call.Arguments["query"] != "up" {
+				return nil, errDenied
+			}
+			return next()
+		},
+	))
+	if err != nil {
+		t.Fatalf("NewServerContext: %v", err)
+	}
+	defer func() { _ = sc.Shutdown() }()
+
+	called := false
+	ctx := ContextWithToolCall(context.Background(), &ToolCall{Name: "execute_query", Arguments: map[string]any{"query": "secret_metric"}})
+	if _, err := sc.RunToolMiddleware(ctx, "execute_query", func() (*mcp.CallToolResult, error) {
+		called = true
+		return nil, nil
+	}); !errors.Is(err, errDenied) {
+		t.Errorf("expected errDenied, got %v", err)
+	}
+	if called {
+		t.Error("handler ran although the middleware rejected the call")
+	}
+}
+
+func TestRunToolMiddlewareNone(t *testing.T) {
+	sc, err := NewServerContext(context.Background())
+	if err != nil {
+		t.Fatalf("NewServerContext: %v", err)
+	}
+	defer func() { _ = sc.Shutdown() }()
+
+	want := &mcp.CallToolResult{}
+	if got, _ := sc.RunToolMiddleware(context.Background(), "t", func() (*mcp.CallToolResult, error) { return want, nil }); got != want {
+		t.Error("expected the handler result without middleware")
+	}
+}
@@ -0,0 +1,23 @@
+package server
+
+import (
+	"github.com/giantswarm/mcp-prometheus/pkg/plugin"
+)
+
+// ServerContext is the plugin.Host plugins register their tools with.
+var _ plugin.Host = (*ServerContext)(nil)
+
+// WithToolPlugin appends a plugin whose tools are registered after the
+// built-in tools. Plugins are registered in the order given.
+func WithToolPlugin(p plugin.ToolPlugin) ServerOption {
+	return func(sc *ServerContext) {
+		sc.toolPlugins = append(sc.toolPlugins, p)
+	}
+}
+
+// ToolPlugins returns the plugins registered with WithToolPlugin.
+func (sc *ServerContext) ToolPlugins() []plugin.ToolPlugin {
+	sc.mutex.RLock()
+	defer sc.mutex.RUnlock()
+	return sc.toolPlugins
+}
@@ -0,0 +1,365 @@
+package server
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// profileFile is the layout of the configuration file read by
+// LoadProfiles:
+//
+//	default: prod
+//	profiles:
+//	  prod:
+//	    url: https://prometheus.prod.example.com
+//	    token_file: /var/run/secrets/prod-token
+//	  staging:
+//	    url: https://mimir.staging.example.com/prometheus
+//	    org_id: staging
+//	    username: reader
+//	    password_file: ~/.config/mcp-prometheus/staging-password
+//	  managed:
+//	    url: https://prometheus.managed.example.com
+//	    oauth2:
+//	      token_url: https://auth.example.com/oauth2/token
+//	      client_id: mcp-prometheus
+//	      client_secret_file: managed-secret
+//	      scopes: [metrics.read]
+//	  gmp:
+//	    url: https://monitoring.googleapis.com/v1/projects/my-project/location/global/prometheus
+//	    auth_mode: gcp
+//	    gcp_credentials_file: gmp-reader.json
+//	  amw:
+//	    url: https://my-workspace.westeurope.prometheus.monitor.azure.com
+//	    auth_mode: azure
+//	    azure:
+//	      client_id: 00000000-0000-0000-0000-000000000000 # user-assigned managed identity
+//	  grafana:
+//	    url: https://prometheus-prod-01-eu-west-0.grafana.net
+//	    grafana_cloud:
+//	      instance_id: "123456"
+//	      token_file: grafana-token
+//	    alertmanager:
+//	      url: https://alertmanager-prod-eu-west-0.grafana.net
+//	      token_file: alertmanager-token
+type profileFile struct {
+	Default  string                   `yaml:"default"`
+	Profiles map[string]profileConfig `yaml:"profiles"`
+}
+
+// profileConfig is one named endpoint in the configuration file.
+// Credentials can be given inline or read from a file so the configuration
+// itself can be shared.
+type profileConfig struct {
+	URL             string `yaml:"url"`
+	OrgID           string `yaml:"org_id"`
+	Username        string `yaml:"username"`
+	Password        string `yaml:"password"`
+	PasswordFile    string `yaml:"password_file"`
+	Token           string `yaml:"token"`
+	TokenFile       string `yaml:"token_file"`
+	TLSSkipVerify   bool   `yaml:"tls_skip_verify"`
+	TLSCACert       string `yaml:"tls_ca_cert"`
+	PathPrefix      string `yaml:"path_prefix"`
+	AlertmanagerURL string `yaml:"alertmanager_url"`
+	RemoteWriteURL  string `yaml:"remote_write_url"`
+	TraceBaseURL    string `yaml:"trace_base_url"`
+
+	AuthMode           string               `yaml:"auth_mode"`
+	GCPCredentialsFile string               `yaml:"gcp_credentials_file"`
+	Azure              *profileAzure        `yaml:"azure"`
+	OAuth2             *profileOAuth2       `yaml:"oauth2"`
+	GrafanaCloud       *profileGrafanaCloud `yaml:"grafana_cloud"`
+	Alertmanager       *profileAlertmanager `yaml:"alertmanager"`
+}
+
+// profileAlertmanager configures the Alertmanager of a profile and its own
+// credentials; without credentials the profile's are used.
+type profileAlertmanager struct {
+	URL          string `yaml:"url"`
+	Username     string `yaml:"username"`
+	Password     string `yaml:"password"`
+	PasswordFile string `yaml:"password_file"`
+	Token        string `yaml:"token"`
+	TokenFile    string `yaml:"token_file"`
+}
+
+// profileGrafanaCloud configures a Grafana Cloud Prometheus endpoint.
+type profileGrafanaCloud struct {
+	InstanceID string `yaml:"instance_id"`
+	Token      string `yaml:"token"`
+	TokenFile  string `yaml:"token_file"`
+}
+
+// profileAzure configures the Azure AD identity of a profile.
+type profileAzure struct {
+	TenantID         string `yaml:"tenant_id"`
+	ClientID         string `yaml:"client_id"`
+	ClientSecret     string `yaml:"client_secret"`
+	ClientSecretFile string `yaml:"client_secret_file"`
+}
+
+// profileOAuth2 configures the OAuth2 client credentials grant of a profile.
+type profileOAuth2 struct {
+	TokenURL         string   `yaml:"token_url"`
+	ClientID         string   `yaml:"client_id"`
+	ClientSecret     string   `yaml:"client_secret"`
+	ClientSecretFile string   `yaml:"client_secret_file"`
+	Scopes           []string `yaml:"scopes"`
+}
+
+// Profiles holds the named Prometheus endpoints of a configuration file.
+type Profiles struct {
+	// Default names the profile used when a tool call selects none and no
+	// PROMETHEUS_URL is set; it may be empty.
+	Default string
+
+	configs map[string]PrometheusConfig
+}
+
+// Get returns the configuration of the named profile.
+func (p Profiles) Get(name string) (PrometheusConfig, bool) {
+	config, ok := p.configs[name]
+	return config, ok
+}
+
+// Names returns the profile names in order.
+func (p Profiles) Names() []string {
+	names := make([]string, 0, len(p.configs))
+	for name := range p.configs {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// DefaultConfigFile returns the configuration file read when --config is
+// not given: mcp-prometheus/config.yaml in the user configuration directory
+// (~/.config on Linux). It returns an empty string when that directory is
+// unknown.
+func DefaultConfigFile() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "mcp-prometheus", "config.yaml")
+}
+
+// LoadProfiles reads the named Prometheus endpoints from the YAML file at
+// path. Relative credential and CA file paths are resolved against the
+// directory of the file, and a leading ~/ against the home directory.
+func LoadProfiles(path string) (Profiles, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Profiles{}, err
+	}
+	var file profileFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return Profiles{}, fmt.Errorf("parse %s: %w", path, err)
+	}
+
+	profiles := Profiles{Default: file.Default, configs: make(map[string]PrometheusConfig, len(file.Profiles))}
+	for name, p := range file.Profiles {
+		config, err := p.prometheusConfig(filepath.Dir(path))
+		if err != nil {
+			return Profiles{}, fmt.Errorf("%s: profile %q: %w", path, name, err)
+		}
+		profiles.configs[name] = config
+	}
+	if file.Default != "" {
+		if _, ok := profiles.configs[file.Default]; !ok {
+			return Profiles{}, fmt.Errorf("%s: default profile %q is not defined", path, file.Default)
+		}
+	}
+	return profiles, nil
+}
+
+// prometheusConfig validates p and converts it, reading credential files
+// relative to dir.
+func (p profileConfig) prometheusConfig(dir string) (PrometheusConfig, error) {
+	u, err := url.Parse(p.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return PrometheusConfig{}, errors.New("url must be a full http or https URL")
+	}
+	if p.Password != "" && p.PasswordFile != "" {
+		return PrometheusConfig{}, errors.New("set password or password_file, not both")
+	}
+	if p.Token != "" && p.TokenFile != "" {
+		return PrometheusConfig{}, errors.New("set token or token_file, not both")
+	}
+	if p.AuthMode != "" && p.AuthMode != AuthModeGCP && p.AuthMode != AuthModeAzure {
+		return PrometheusConfig{}, fmt.Errorf("unsupported auth_mode %q (supported: %s, %s)", p.AuthMode, AuthModeGCP, AuthModeAzure)
+	}
+	var azure profileAzure
+	if p.Azure != nil {
+		azure = *p.Azure
+		if azure.ClientSecret != "" && azure.ClientSecretFile != "" {
+			return PrometheusConfig{}, errors.New("set azure client_secret or client_secret_file, not both")
+		}
+	}
+	alertmanager := profileAlertmanager{URL: p.AlertmanagerURL}
+	if a := p.Alertmanager; a != nil {
+		if a.URL != "" && p.AlertmanagerURL != "" {
+			return PrometheusConfig{}, errors.New("set alertmanager_url or alertmanager url, not both")
+		}
+		if a.Password != "" && a.PasswordFile != "" {
+			return PrometheusConfig{}, errors.New("set alertmanager password or password_file, not both")
+		}
+		if a.Token != "" && a.TokenFile != "" {
+			return PrometheusConfig{}, errors.New("set alertmanager token or token_file, not both")
+		}
+		alertmanager = *a
+		alertmanager.URL = cmp.Or(a.URL, p.AlertmanagerURL)
+	}
+	var oauth2 profileOAuth2
+	if p.OAuth2 != nil {
+		oauth2 = *p.OAuth2
+		if oauth2.TokenURL == "" || oauth2.ClientID == "" {
+			return PrometheusConfig{}, errors.New("oauth2 needs token_url and client_id")
+		}
+		if oauth2.ClientSecret != "" && oauth2.ClientSecretFile != "" {
+			return PrometheusConfig{}, errors.New("set oauth2 client_secret or client_secret_file, not both")
+		}
+	}
+
+	config := PrometheusConfig{
+		URL:           p.URL,
+		OrgID:         p.OrgID,
+		Username:      p.Username,
+		Password:      p.Password,
+		Token:         p.Token,
+		TLSSkipVerify: p.TLSSkipVerify,
+		PathPrefix:    p.PathPrefix,
+		Alertmanager: AlertmanagerConfig{
+			URL:      alertmanager.URL,
+			Username: alertmanager.Username,
+			Password: alertmanager.Password,
+			Token:    alertmanager.Token,
+		},
+		RemoteWriteURL: p.RemoteWriteURL,
+		TraceBaseURL:   p.TraceBaseURL,
+		AuthMode:       p.AuthMode,
+		Azure: AzureConfig{
+			TenantID:     azure.TenantID,
+			ClientID:     azure.ClientID,
+			ClientSecret: azure.ClientSecret,
+		},
+		OAuth2: OAuth2Config{
+			TokenURL:     oauth2.TokenURL,
+			ClientID:     oauth2.ClientID,
+			ClientSecret: oauth2.ClientSecret,
+			Scopes:       oauth2.Scopes,
+		},
+	}
+	if p.TLSCACert != "" {
+		config.TLSCACert = resolveConfigPath(dir, p.TLSCACert)
+	}
+	if p.TokenFile != "" {
+		// The client re-reads the token file so rotated tokens are picked
+		// up; only check here that it exists.
+		config.TokenFile = resolveConfigPath(dir, p.TokenFile)
+		if _, err := os.Stat(config.TokenFile); err != nil {
+			return PrometheusConfig{}, err
+		}
+	}
+	if alertmanager.TokenFile != "" {
+		config.Alertmanager.TokenFile = resolveConfigPath(dir, alertmanager.TokenFile)
+		if _, err := os.Stat(config.Alertmanager.TokenFile); err != nil {
+			return PrometheusConfig{}, err
+		}
+	}
+	if p.GCPCredentialsFile != "" {
+		config.GCPCredentialsFile = resolveConfigPath(dir, p.GCPCredentialsFile)
+	}
+	for _, secret := range []struct {
+		file string
+		dst  *string
+	}{
+		{p.PasswordFile, &config.Password},
+		{oauth2.ClientSecretFile, &config.OAuth2.ClientSecret},
+		{azure.ClientSecretFile, &config.Azure.ClientSecret},
+		{alertmanager.PasswordFile, &config.Alertmanager.Password},
+	} {
+		if secret.file == "" {
+			continue
+		}
+		data, err := os.ReadFile(resolveConfigPath(dir, secret.file))
+		if err != nil {
+			return PrometheusConfig{}, err
+		}
+		*secret.dst = strings.TrimSpace(string(data))
+	}
+	if g := p.GrafanaCloud; g != nil {
+		// Like token_file above, the client re-reads the token file.
+		var tokenFile string
+		if g.TokenFile != "" {
+			tokenFile = resolveConfigPath(dir, g.TokenFile)
+			if _, err := os.Stat(tokenFile); err != nil {
+				return PrometheusConfig{}, err
+			}
+		}
+		if err := applyGrafanaCloud(&config, g.InstanceID, g.Token, tokenFile); err != nil {
+			return PrometheusConfig{}, err
+		}
+	}
+	return config, nil
+}
+
+// resolveConfigPath expands a leading ~/ and makes relative paths relative
+// to dir.
+func resolveConfigPath(dir, path string) string {
+	if rest, ok := strings.CutPrefix(path, "~/"); ok {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, rest)
+		}
+	}
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(dir, path)
+}
+
+// WithConfigFile makes NewServerContext load Prometheus endpoint profiles
+// from the YAML file at path (see LoadProfiles). NewServerContext fails when
+// the file cannot be read or is invalid.
+func WithConfigFile(path string) ServerOption {
+	return func(sc *ServerContext) {
+		sc.configFile = path
+	}
+}
+
+// Profiles returns the endpoint profiles loaded with WithConfigFile; it is
+// empty when no configuration file was loaded.
+func (sc *ServerContext) Profiles() Profiles {
+	sc.mutex.RLock()
+	defer sc.mutex.RUnlock()
+	return sc.profiles
+}
+
+// ProfileConfig returns the Prometheus configuration of the named profile,
+// with the server's tracer provider, request limiter and API version
+// negotiation setting applied.
+func (sc *ServerContext) ProfileConfig(name string) (PrometheusConfig, error) {
+	profiles := sc.Profiles()
+	config, ok := profiles.Get(name)
+	if !ok {
+		if len(profiles.configs) == 0 {
+			return PrometheusConfig{}, fmt.Errorf("unknown profile %q: no profiles are configured", name)
+		}
+		return PrometheusConfig{}, fmt.Errorf("unknown profile %q (available: %s)", name, strings.Join(profiles.Names(), ", "))
+	}
+	sc.mutex.RLock()
+	defer sc.mutex.RUnlock()
+	config.TracerProvider = sc.prometheusConfig.TracerProvider
+	config.RequestLimiter = sc.prometheusConfig.RequestLimiter
+	config.DisableAPIVersionNegotiation = sc.prometheusConfig.DisableAPIVersionNegotiation
+	return config, nil
+}
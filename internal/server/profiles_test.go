@@ -0,0 +1,190 @@
+package server
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeConfigFile writes content to config.yaml in a temporary directory and
+// returns its path.
+func writeConfigFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadProfiles(t *testing.T) {
+	path := writeConfigFile(t, `
+default: prod
+profiles:
+  prod:
+    url: https://prometheus.prod.example.com
+    token_file: prod-token
+  staging:
+    url: https://mimir.staging.example.com
+    org_id: staging
+    username: reader
+    password: secret
+    path_prefix: /prometheus
+    tls_ca_cert: ca.pem
+    alertmanager:
+      url: https://alertmanager.staging.example.com
+      username: am-reader
+      password_file: managed-secret
+  managed:
+    url: https://prometheus.managed.example.com
+    oauth2:
+      token_url: https://auth.example.com/token
+      client_id: mcp
+      client_secret_file: managed-secret
+      scopes: [metrics.read]
+  grafana:
+    url: https://prometheus-prod-01-eu-west-0.grafana.net/api/prom
+    grafana_cloud:
+      instance_id: "123456"
+      token_file: managed-secret
+  amw:
+    url: https://workspace.westeurope.prometheus.monitor.azure.com
+    auth_mode: azure
+    azure:
+      tenant_id: tenant
+      client_id: client
+      client_secret_file: managed-secret
+`)
+	for file, content := range map[string]string{"prod-token": "abc123\n", "managed-secret": "s3cret\n"} {
+		if err := os.WriteFile(filepath.Join(filepath.Dir(path), file), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	profiles, err := LoadProfiles(path)
+	if err != nil {
+		t.Fatalf("LoadProfiles: %v", err)
+	}
+	if profiles.Default != "prod" {
+		t.Errorf("Default = %q, want prod", profiles.Default)
+	}
+	if got := strings.Join(profiles.Names(), ","); got != "amw,grafana,managed,prod,staging" {
+		t.Errorf("Names() = %q", got)
+	}
+
+	prod, ok := profiles.Get("prod")
+	if !ok || prod.URL != "https://prometheus.prod.example.com" || prod.TokenFile != filepath.Join(filepath.Dir(path), "prod-token") {
+		t.Errorf("Get(prod) = %+v, %t", prod, ok)
+	}
+	staging, _ := profiles.Get("staging")
+	if staging.OrgID != "staging" || staging.Username != "reader" || staging.Password != "secret" || staging.PathPrefix != "/prometheus" {
+		t.Errorf("Get(staging) = %+v", staging)
+	}
+	if staging.Alertmanager != (AlertmanagerConfig{URL: "https://alertmanager.staging.example.com", Username: "am-reader", Password: "s3cret"}) {
+		t.Errorf("Get(staging).Alertmanager = %+v", staging.Alertmanager)
+	}
+	if want := filepath.Join(filepath.Dir(path), "ca.pem"); staging.TLSCACert != want {
+		t.Errorf("TLSCACert = %q, want %q", staging.TLSCACert, want)
+	}
+	amw, _ := profiles.Get("amw")
+	if amw.AuthMode != AuthModeAzure || amw.Azure != (AzureConfig{TenantID: "tenant", ClientID: "client", ClientSecret: "s3cret"}) {
+		t.Errorf("Get(amw) = %+v", amw)
+	}
+	grafana, _ := profiles.Get("grafana")
+	if grafana.Username != "123456" || grafana.Password != "" || grafana.PasswordFile != filepath.Join(filepath.Dir(path), "managed-secret") {
+		t.Errorf("Get(grafana) = %+v", grafana)
+	}
+	managed, _ := profiles.Get("managed")
+	if o := managed.OAuth2; o.TokenURL != "https://auth.example.com/token" || o.ClientID != "mcp" || o.ClientSecret != "s3cret" || len(o.Scopes) != 1 {
+		t.Errorf("Get(managed).OAuth2 = %+v", o)
+	}
+}
+
+func TestLoadProfilesErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{"missing url", "profiles:\n  a:\n    org_id: x\n", "url must be"},
+		{"bad scheme", "profiles:\n  a:\n    url: ftp://host\n", "url must be"},
+		{"password twice", "profiles:\n  a:\n    url: http://host\n    password: x\n    password_file: y\n", "password or password_file"},
+		{"token twice", "profiles:\n  a:\n    url: http://host\n    token: x\n    token_file: y\n", "token or token_file"},
+		{"missing token file", "profiles:\n  a:\n    url: http://host\n    token_file: missing\n", "missing"},
+		{"oauth2 without client id", "profiles:\n  a:\n    url: http://host\n    oauth2:\n      token_url: http://auth\n", "token_url and client_id"},
+		{"oauth2 secret twice", "profiles:\n  a:\n    url: http://host\n    oauth2:\n      token_url: http://auth\n      client_id: c\n      client_secret: x\n      client_secret_file: y\n", "client_secret or client_secret_file"},
+		{"unknown auth mode", "profiles:\n  a:\n    url: http://host\n    auth_mode: kerberos\n", "unsupported auth_mode"},
+		{"grafana cloud without token", "profiles:\n  a:\n    url: https://prometheus-prod-01-eu-west-0.grafana.net\n    grafana_cloud:\n      instance_id: \"1\"\n", "instance ID and a token"},
+		{"alertmanager url twice", "profiles:\n  a:\n    url: http://host\n    alertmanager_url: http://am\n    alertmanager:\n      url: http://am\n", "alertmanager_url or alertmanager url"},
+		{"alertmanager token twice", "profiles:\n  a:\n    url: http://host\n    alertmanager:\n      token: x\n      token_file: y\n", "alertmanager token or token_file"},
+		{"undefined default", "default: b\nprofiles:\n  a:\n    url: http://host\n", `default profile "b"`},
+		{"invalid yaml", "profiles: [", "parse"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadProfiles(writeConfigFile(t, tt.content))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("LoadProfiles() error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestWithConfigFile(t *testing.T) {
+	path := writeConfigFile(t, `
+default: prod
+profiles:
+  prod:
+    url: https://prometheus.prod.example.com
+  staging:
+    url: https://prometheus.staging.example.com
+`)
+
+	t.Setenv("PROMETHEUS_URL", "")
+	sc, err := NewServerContext(context.Background(), WithConfigFile(path))
+	if err != nil {
+		t.Fatalf("NewServerContext: %v", err)
+	}
+	defer func() { _ = sc.Shutdown() }()
+
+	if got := sc.PrometheusConfigSource(); got != ConfigSourceProfile {
+		t.Errorf("PrometheusConfigSource() = %q, want %q", got, ConfigSourceProfile)
+	}
+	if got := sc.PrometheusConfig().URL; got != "https://prometheus.prod.example.com" {
+		t.Errorf("PrometheusConfig().URL = %q, want the default profile URL", got)
+	}
+	if config, err := sc.ProfileConfig("staging"); err != nil || config.URL != "https://prometheus.staging.example.com" {
+		t.Errorf("ProfileConfig(staging) = %+v, %v", config, err)
+	}
+	if _, err := sc.ProfileConfig("dev"); err == nil || !strings.Contains(err.Error(), "prod, staging") {
+		t.Errorf("ProfileConfig(dev) error = %v, want the available profiles listed", err)
+	}
+
+	// An explicitly configured URL wins over the default profile.
+	t.Setenv("PROMETHEUS_URL", "http://prometheus:9090")
+	sc2, err := NewServerContext(context.Background(), WithConfigFile(path))
+	if err != nil {
+		t.Fatalf("NewServerContext: %v", err)
+	}
+	defer func() { _ = sc2.Shutdown() }()
+	if got := sc2.PrometheusConfigSource(); got != ConfigSourceEnv {
+		t.Errorf("PrometheusConfigSource() = %q, want %q", got, ConfigSourceEnv)
+	}
+
+	if _, err := NewServerContext(context.Background(), WithConfigFile(filepath.Join(t.TempDir(), "missing.yaml"))); err == nil {
+		t.Error("expected an error for a missing config file")
+	}
+}
+
+func TestProfileConfigWithoutProfiles(t *testing.T) {
+	sc, err := NewServerContext(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = sc.Shutdown() }()
+	if _, err := sc.ProfileConfig("prod"); err == nil || !strings.Contains(err.Error(), "no profiles are configured") {
+		t.Errorf("ProfileConfig(prod) error = %v", err)
+	}
+}
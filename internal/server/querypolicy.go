@@ -0,0 +1,37 @@
+package server
+
+import "time"
+
+// QueryPolicy restricts the PromQL queries tools run on behalf of callers,
+// protecting shared Prometheus or Mimir tenants from pathological queries. A
+// zero field is not enforced.
+type QueryPolicy struct {
+	// MaxRange is the longest time range of a range query, and the longest
+	// range vector or subquery window of any query.
+	MaxRange time.Duration
+	// MinStep is the smallest resolution step of a range query or subquery.
+	MinStep time.Duration
+	// RequireMatcher requires every vector selector to have at least one
+	// non-regex equality matcher with a value, such as a metric name.
+	RequireMatcher bool
+}
+
+// Enabled reports whether any restriction is set.
+func (p QueryPolicy) Enabled() bool {
+	return p.MaxRange > 0 || p.MinStep > 0 || p.RequireMatcher
+}
+
+// WithQueryPolicy sets the query policy. Without it queries are not
+// restricted.
+func WithQueryPolicy(p QueryPolicy) ServerOption {
+	return func(sc *ServerContext) {
+		sc.queryPolicy = p
+	}
+}
+
+// QueryPolicy returns the policy set with WithQueryPolicy.
+func (sc *ServerContext) QueryPolicy() QueryPolicy {
+	sc.mutex.RLock()
+	defer sc.mutex.RUnlock()
+	return sc.queryPolicy
+}
@@ -0,0 +1,23 @@
+package server
+
+import (
+	"testing"
+	"time"
+)
+
+func TestQueryPolicyEnabled(t *testing.T) {
+	tests := []struct {
+		policy QueryPolicy
+		want   bool
+	}{
+		{QueryPolicy{}, false},
+		{QueryPolicy{MaxRange: 24 * time.Hour}, true},
+		{QueryPolicy{MinStep: time.Minute}, true},
+		{QueryPolicy{RequireMatcher: true}, true},
+	}
+	for _, tt := range tests {
+		if got := tt.policy.Enabled(); got != tt.want {
+			t.Errorf("%+v.Enabled() = %t, want %t", tt.policy, got, tt.want)
+		}
+	}
+}
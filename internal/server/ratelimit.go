@@ -0,0 +1,78 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"golang.org/x/time/rate"
+)
+
+// RequestLimiter caps the rate and the concurrency of requests to Prometheus,
+// so an agent looping over hundreds of metrics cannot overload a production
+// server. One limiter is shared by every client the server creates, including
+// those for per-request URLs and profiles. A nil *RequestLimiter allows
+// everything.
+type RequestLimiter struct {
+	rate  *rate.Limiter
+	slots chan struct{}
+}
+
+// NewRequestLimiter returns a limiter allowing qps requests per second, with
+// bursts of up to qps (at least 1), and maxConcurrent requests in flight. A
+// value of 0 lifts the respective limit; nil is returned when both are 0.
+func NewRequestLimiter(qps float64, maxConcurrent int) (*RequestLimiter, error) {
+	if qps < 0 || math.IsNaN(qps) || math.IsInf(qps, 0) {
+		return nil, fmt.Errorf("max QPS must be a non-negative number (got %v)", qps)
+	}
+	if maxConcurrent < 0 {
+		return nil, fmt.Errorf("max concurrent queries must not be negative (got %d)", maxConcurrent)
+	}
+	if qps == 0 && maxConcurrent == 0 {
+		return nil, nil
+	}
+
+	l := &RequestLimiter{}
+	if qps > 0 {
+		l.rate = rate.NewLimiter(rate.Limit(qps), max(1, int(math.Ceil(qps))))
+	}
+	if maxConcurrent > 0 {
+		l.slots = make(chan struct{}, maxConcurrent)
+	}
+	return l, nil
+}
+
+// Acquire waits until a request may be sent. The returned release function
+// must be called once the request is done, to free its concurrency slot. It
+// fails when ctx ends first, or when its deadline would pass before the rate
+// limit allows the request.
+func (l *RequestLimiter) Acquire(ctx context.Context) (release func(), err error) {
+	release = func() {}
+	if l == nil {
+		return release, nil
+	}
+	if l.slots != nil {
+		select {
+		case l.slots <- struct{}{}:
+			release = func() { <-l.slots }
+		case <-ctx.Done():
+			return nil, fmt.Errorf("wait for a concurrent request slot: %w", ctx.Err())
+		}
+	}
+	if l.rate != nil {
+		if err := l.rate.Wait(ctx); err != nil {
+			release()
+			return nil, fmt.Errorf("wait for the request rate limit: %w", err)
+		}
+	}
+	return release, nil
+}
+
+// WithRequestLimiter limits the requests of all Prometheus clients with l.
+// It fills PrometheusConfig.RequestLimiter unless the configuration already
+// sets one.
+func WithRequestLimiter(l *RequestLimiter) ServerOption {
+	return func(sc *ServerContext) {
+		sc.requestLimiter = l
+	}
+}
@@ -0,0 +1,92 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestNewRequestLimiter(t *testing.T) {
+	if l, err := NewRequestLimiter(0, 0); l != nil || err != nil {
+		t.Errorf("NewRequestLimiter(0, 0) = %v, %v; want no limiter", l, err)
+	}
+	for _, tt := range []struct {
+		qps           float64
+		maxConcurrent int
+	}{{-1, 0}, {0, -1}} {
+		if _, err := NewRequestLimiter(tt.qps, tt.maxConcurrent); err == nil {
+			t.Errorf("NewRequestLimiter(%v, %d): expected an error", tt.qps, tt.maxConcurrent)
+		}
+	}
+
+	// A nil limiter allows everything.
+	var nilLimiter *RequestLimiter
+	release, err := nilLimiter.Acquire(context.Background())
+	if err != nil {
+		t.Fatalf("nil limiter: %v", err)
+	}
+	release()
+}
+
+func TestRequestLimiterConcurrency(t *testing.T) {
+	l, err := NewRequestLimiter(0, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	first, err := l.Acquire(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := l.Acquire(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := l.Acquire(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("third request: got %v, want it to wait until the deadline", err)
+	}
+
+	first()
+	if _, err := l.Acquire(context.Background()); err != nil {
+		t.Errorf("expected a free slot after release, got %v", err)
+	}
+}
+
+func TestRequestLimiterRate(t *testing.T) {
+	l, err := NewRequestLimiter(1, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The burst of one passes; the next request would wait a second.
+	if _, err := l.Acquire(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if _, err := l.Acquire(ctx); err == nil {
+		t.Error("expected the second request to exceed the deadline")
+	}
+}
+
+func TestRequestLimiterFillsConfig(t *testing.T) {
+	l, err := NewRequestLimiter(10, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sc, err := NewServerContext(context.Background(),
+		WithPrometheusConfig(PrometheusConfig{URL: "http://prometheus:9090"}),
+		WithRequestLimiter(l),
+	)
+	if err != nil {
+		t.Fatalf("NewServerContext: %v", err)
+	}
+	defer func() { _ = sc.Shutdown() }()
+
+	if sc.PrometheusConfig().RequestLimiter != l {
+		t.Error("expected the limiter in the Prometheus configuration")
+	}
+}
@@ -0,0 +1,56 @@
+//go:build !windows
+
+package server
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// WatchDebugToggleSignal toggles debug logging on sc every time the process
+// receives SIGUSR1. It returns once the watcher is installed; the watcher
+// stops when ctx is cancelled.
+func WatchDebugToggleSignal(ctx context.Context, sc *ServerContext) {
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGUSR1)
+
+	go func() {
+		defer signal.Stop(sigCh)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-sigCh:
+				level := sc.ToggleDebugMode()
+				sc.Logger().Info("Log level toggled via SIGUSR1", "level", level.String())
+			}
+		}
+	}()
+}
+
+// WatchLogReopenSignal reopens f every time the process receives SIGHUP, for
+// compatibility with logrotate's move-and-signal rotation. It returns once
+// the watcher is installed; the watcher stops when ctx is cancelled.
+func WatchLogReopenSignal(ctx context.Context, f *LogFile, logger *slog.Logger) {
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGHUP)
+
+	go func() {
+		defer signal.Stop(sigCh)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-sigCh:
+				if err := f.Reopen(); err != nil {
+					logger.Error("Failed to reopen log file on SIGHUP", "path", f.Path(), "error", err)
+					continue
+				}
+				logger.Info("Log file reopened via SIGHUP", "path", f.Path())
+			}
+		}
+	}()
+}
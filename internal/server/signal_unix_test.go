@@ -0,0 +1,80 @@
+//go:build !windows
+
+package server
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestWatchDebugToggleSignal(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sc, err := NewServerContext(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	WatchDebugToggleSignal(ctx, sc)
+
+	for _, want := range []slog.Level{slog.LevelDebug, slog.LevelInfo} {
+		if err := syscall.Kill(os.Getpid(), syscall.SIGUSR1); err != nil {
+			t.Fatalf("kill: %v", err)
+		}
+		deadline := time.Now().Add(2 * time.Second)
+		for sc.LogLevel() != want && time.Now().Before(deadline) {
+			time.Sleep(10 * time.Millisecond)
+		}
+		if got := sc.LogLevel(); got != want {
+			t.Fatalf("after SIGUSR1: level = %v, want %v", got, want)
+		}
+	}
+}
+
+func TestWatchLogReopenSignal(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	path := filepath.Join(t.TempDir(), "server.log")
+	f, err := OpenLogFile(path)
+	if err != nil {
+		t.Fatalf("OpenLogFile: %v", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	logger := slog.New(slog.NewTextHandler(f, nil))
+	WatchLogReopenSignal(ctx, f, logger)
+
+	logger.Info("before rotation")
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	if err := syscall.Kill(os.Getpid(), syscall.SIGHUP); err != nil {
+		t.Fatalf("kill: %v", err)
+	}
+
+	// The watcher logs through the reopened handle, so its message marks the
+	// point where the swap is complete.
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if data, err := os.ReadFile(path); err == nil && strings.Contains(string(data), "Log file reopened") {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	logger.Info("after rotation")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("log file was not re-created after SIGHUP: %v", err)
+	}
+	if !strings.Contains(string(data), "after rotation") || strings.Contains(string(data), "before rotation") {
+		t.Errorf("unexpected log file content after reopen:\n%s", data)
+	}
+}
@@ -0,0 +1,15 @@
+//go:build windows
+
+package server
+
+import (
+	"context"
+	"log/slog"
+)
+
+// WatchDebugToggleSignal is a no-op on Windows, which has no SIGUSR1. Use the
+// admin endpoint to change the log level instead.
+func WatchDebugToggleSignal(_ context.Context, _ *ServerContext) {}
+
+// WatchLogReopenSignal is a no-op on Windows, which has no SIGHUP.
+func WatchLogReopenSignal(_ context.Context, _ *LogFile, _ *slog.Logger) {}
@@ -0,0 +1,65 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"log/slog"
+	"strings"
+)
+
+// LogWriterAdapter is an io.Writer that forwards each written line to a
+// structured logger. It lets libraries that only know the standard log
+// package share the server's log output and level filtering.
+//
+// The level of each line is inferred from a leading ERROR, WARN or DEBUG
+// keyword (optionally bracketed or followed by a colon); all other lines are
+// logged at info level.
+type LogWriterAdapter struct {
+	logger *slog.Logger
+}
+
+// NewLogWriterAdapter returns a LogWriterAdapter that writes to logger.
+func NewLogWriterAdapter(logger *slog.Logger) *LogWriterAdapter {
+	return &LogWriterAdapter{logger: logger}
+}
+
+// Write implements io.Writer. It always reports len(p) bytes written.
+func (a *LogWriterAdapter) Write(p []byte) (int, error) {
+	for _, line := range bytes.Split(bytes.TrimRight(p, "\n"), []byte("\n")) {
+		msg := strings.TrimSpace(string(line))
+		if msg == "" {
+			continue
+		}
+		level, msg := parseStdLogLevel(msg)
+		a.logger.Log(context.Background(), level, msg, "source", "stdlog")
+	}
+	return len(p), nil
+}
+
+// parseStdLogLevel strips a leading level keyword from msg and returns the
+// matching slog level.
+func parseStdLogLevel(msg string) (slog.Level, string) {
+	keyword, rest, _ := strings.Cut(msg, " ")
+	keyword = strings.Trim(keyword, "[]:")
+	var level slog.Level
+	switch strings.ToUpper(keyword) {
+	case "ERROR", "ERR":
+		level = slog.LevelError
+	case "WARN", "WARNING":
+		level = slog.LevelWarn
+	case "DEBUG":
+		level = slog.LevelDebug
+	case "INFO":
+		level = slog.LevelInfo
+	default:
+		return slog.LevelInfo, msg
+	}
+	return level, strings.TrimSpace(rest)
+}
+
+// NewStdLogger returns a standard library logger backed by a
+// LogWriterAdapter. Timestamps are left to the structured handler.
+func NewStdLogger(logger *slog.Logger) *log.Logger {
+	return log.New(NewLogWriterAdapter(logger), "", 0)
+}
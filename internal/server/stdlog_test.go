@@ -0,0 +1,65 @@
+package server
+
+import (
+	"bytes"
+	"log"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestNewStdLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	std := NewStdLogger(logger)
+	std.Printf("connected to %s", "prometheus")
+	std.Printf("[ERROR] scrape failed")
+
+	out := buf.String()
+	for _, want := range []string{
+		`level=INFO msg="connected to prometheus" source=stdlog`,
+		`level=ERROR msg="scrape failed" source=stdlog`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected output to contain %q, got:\n%s", want, out)
+		}
+	}
+}
+
+func TestLogWriterAdapterWithLogSetOutput(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, nil))
+
+	origWriter, origFlags := log.Writer(), log.Flags()
+	defer func() {
+		log.SetOutput(origWriter)
+		log.SetFlags(origFlags)
+	}()
+
+	log.SetFlags(0)
+	log.SetOutput(NewLogWriterAdapter(logger))
+	log.Printf("retrying in %ds", 3)
+	log.Print("DEBUG: hidden at info level")
+
+	out := buf.String()
+	if !strings.Contains(out, `level=INFO msg="retrying in 3s"`) {
+		t.Errorf("expected log.Printf output in slog output, got:\n%s", out)
+	}
+	if strings.Contains(out, "hidden") {
+		t.Errorf("expected debug line to be filtered, got:\n%s", out)
+	}
+}
+
+func TestLogWriterAdapterMultiline(t *testing.T) {
+	var buf bytes.Buffer
+	a := NewLogWriterAdapter(slog.New(slog.NewTextHandler(&buf, nil)))
+
+	n, err := a.Write([]byte("first\n\nsecond\n"))
+	if err != nil || n != len("first\n\nsecond\n") {
+		t.Fatalf("Write returned %d, %v", n, err)
+	}
+	if got := strings.Count(buf.String(), "\n"); got != 2 {
+		t.Errorf("expected 2 records, got %d:\n%s", got, buf.String())
+	}
+}
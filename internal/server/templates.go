@@ -0,0 +1,104 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+)
+
+const (
+	// MaxQueryTemplates is the number of templates one scope may register.
+	MaxQueryTemplates = 100
+
+	// MaxQueryTemplatesTotal is the number of templates kept across all
+	// scopes.
+	MaxQueryTemplatesTotal = 10000
+
+	// MaxQueryTemplateNameLength and MaxQueryTemplateLength bound the size
+	// of one template, in bytes.
+	MaxQueryTemplateNameLength = 128
+	MaxQueryTemplateLength     = 4096
+)
+
+// ErrQueryTemplateLimit is returned by SetQueryTemplate when a template is
+// too large or no more templates can be registered.
+var ErrQueryTemplateLimit = errors.New("query template limit exceeded")
+
+// SetQueryTemplate stores a named PromQL template in scope, replacing any
+// template already registered there under name. It reports whether one was
+// replaced. A scope (an OAuth user or an MCP session) only sees its own
+// templates. Templates live in memory only and are lost on restart.
+func (sc *ServerContext) SetQueryTemplate(scope, name, template string) (bool, error) {
+	if len(name) > MaxQueryTemplateNameLength {
+		return false, fmt.Errorf("%w: name is longer than %d bytes", ErrQueryTemplateLimit, MaxQueryTemplateNameLength)
+	}
+	if len(template) > MaxQueryTemplateLength {
+		return false, fmt.Errorf("%w: template is longer than %d bytes", ErrQueryTemplateLimit, MaxQueryTemplateLength)
+	}
+
+	sc.templatesMu.Lock()
+	defer sc.templatesMu.Unlock()
+	templates := sc.queryTemplates[scope]
+	if _, replaced := templates[name]; replaced {
+		templates[name] = template
+		return true, nil
+	}
+	if len(templates) >= MaxQueryTemplates {
+		return false, fmt.Errorf("%w: at most %d templates can be registered", ErrQueryTemplateLimit, MaxQueryTemplates)
+	}
+	if sc.queryTemplateCount >= MaxQueryTemplatesTotal {
+		return false, fmt.Errorf("%w: the server holds %d templates", ErrQueryTemplateLimit, MaxQueryTemplatesTotal)
+	}
+	if templates == nil {
+		if sc.queryTemplates == nil {
+			sc.queryTemplates = make(map[string]map[string]string)
+		}
+		templates = make(map[string]string)
+		sc.queryTemplates[scope] = templates
+	}
+	templates[name] = template
+	sc.queryTemplateCount++
+	return false, nil
+}
+
+// QueryTemplate returns the template registered in scope under name.
+func (sc *ServerContext) QueryTemplate(scope, name string) (string, bool) {
+	sc.templatesMu.RLock()
+	defer sc.templatesMu.RUnlock()
+	template, ok := sc.queryTemplates[scope][name]
+	return template, ok
+}
+
+// QueryTemplates returns a copy of the templates registered in scope keyed
+// by name.
+func (sc *ServerContext) QueryTemplates(scope string) map[string]string {
+	sc.templatesMu.RLock()
+	defer sc.templatesMu.RUnlock()
+	return maps.Clone(sc.queryTemplates[scope])
+}
+
+// DeleteQueryTemplate removes the template registered in scope under name
+// and reports whether it existed.
+func (sc *ServerContext) DeleteQueryTemplate(scope, name string) bool {
+	sc.templatesMu.Lock()
+	defer sc.templatesMu.Unlock()
+	templates := sc.queryTemplates[scope]
+	if _, ok := templates[name]; !ok {
+		return false
+	}
+	delete(templates, name)
+	sc.queryTemplateCount--
+	if len(templates) == 0 {
+		delete(sc.queryTemplates, scope)
+	}
+	return true
+}
+
+// DeleteQueryTemplates removes all templates registered in scope, e.g. when
+// the MCP session owning them ends.
+func (sc *ServerContext) DeleteQueryTemplates(scope string) {
+	sc.templatesMu.Lock()
+	defer sc.templatesMu.Unlock()
+	sc.queryTemplateCount -= len(sc.queryTemplates[scope])
+	delete(sc.queryTemplates, scope)
+}
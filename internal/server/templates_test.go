@@ -0,0 +1,105 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestQueryTemplates(t *testing.T) {
+	sc, err := NewServerContext(context.Background())
+	if err != nil {
+		t.Fatalf("NewServerContext: %v", err)
+	}
+
+	if _, ok := sc.QueryTemplate("alice", "rate"); ok {
+		t.Error("expected no template before registration")
+	}
+	if replaced, err := sc.SetQueryTemplate("alice", "rate", "rate({{.metric}}[5m])"); err != nil || replaced {
+		t.Errorf("first registration = %v, %v", replaced, err)
+	}
+	if replaced, err := sc.SetQueryTemplate("alice", "rate", "rate({{.metric}}[{{.window}}])"); err != nil || !replaced {
+		t.Errorf("second registration = %v, %v", replaced, err)
+	}
+	if got, _ := sc.QueryTemplate("alice", "rate"); got != "rate({{.metric}}[{{.window}}])" {
+		t.Errorf("QueryTemplate() = %q", got)
+	}
+
+	templates := sc.QueryTemplates("alice")
+	templates["other"] = "up"
+	if _, ok := sc.QueryTemplate("alice", "other"); ok {
+		t.Error("QueryTemplates() returned the internal map instead of a copy")
+	}
+
+	if _, ok := sc.QueryTemplate("bob", "rate"); ok {
+		t.Error("template of one scope is visible in another")
+	}
+	if len(sc.QueryTemplates("bob")) != 0 || sc.DeleteQueryTemplate("bob", "rate") {
+		t.Error("another scope can list or delete the template")
+	}
+
+	if !sc.DeleteQueryTemplate("alice", "rate") || sc.DeleteQueryTemplate("alice", "rate") {
+		t.Error("DeleteQueryTemplate() should succeed once")
+	}
+}
+
+func TestQueryTemplateLimits(t *testing.T) {
+	sc, err := NewServerContext(context.Background())
+	if err != nil {
+		t.Fatalf("NewServerContext: %v", err)
+	}
+
+	if _, err := sc.SetQueryTemplate("alice", "big", strings.Repeat("x", MaxQueryTemplateLength+1)); !errors.Is(err, ErrQueryTemplateLimit) {
+		t.Errorf("oversized template: err = %v", err)
+	}
+	if _, err := sc.SetQueryTemplate("alice", strings.Repeat("x", MaxQueryTemplateNameLength+1), "up"); !errors.Is(err, ErrQueryTemplateLimit) {
+		t.Errorf("oversized name: err = %v", err)
+	}
+
+	for i := range MaxQueryTemplates {
+		if _, err := sc.SetQueryTemplate("alice", fmt.Sprintf("t%d", i), "up"); err != nil {
+			t.Fatalf("SetQueryTemplate(%d): %v", i, err)
+		}
+	}
+	if _, err := sc.SetQueryTemplate("alice", "one-too-many", "up"); !errors.Is(err, ErrQueryTemplateLimit) {
+		t.Errorf("template over the per-scope limit: err = %v", err)
+	}
+	if _, err := sc.SetQueryTemplate("alice", "t0", "down"); err != nil {
+		t.Errorf("replacing at the limit: %v", err)
+	}
+	if _, err := sc.SetQueryTemplate("bob", "t0", "up"); err != nil {
+		t.Errorf("other scope at the limit: %v", err)
+	}
+
+	sc.DeleteQueryTemplates("alice")
+	if n := len(sc.QueryTemplates("alice")); n != 0 {
+		t.Errorf("DeleteQueryTemplates() left %d templates", n)
+	}
+	if sc.queryTemplateCount != 1 {
+		t.Errorf("template count = %d, want 1", sc.queryTemplateCount)
+	}
+}
+
+func TestQueryTemplatesConcurrent(t *testing.T) {
+	sc, err := NewServerContext(context.Background())
+	if err != nil {
+		t.Fatalf("NewServerContext: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for i := range 10 {
+		wg.Go(func() {
+			name := fmt.Sprintf("t%d", i)
+			_, _ = sc.SetQueryTemplate("", name, "up")
+			sc.QueryTemplates("")
+			sc.DeleteQueryTemplate("", name)
+		})
+	}
+	wg.Wait()
+	if n := len(sc.QueryTemplates("")); n != 0 {
+		t.Errorf("expected no templates left, got %d", n)
+	}
+}
@@ -0,0 +1,62 @@
+package server
+
+import (
+	"fmt"
+	"maps"
+	"net/url"
+	"strings"
+)
+
+// TenantRouter maps Mimir/Cortex org IDs to the base URL of the backend
+// serving that tenant, for deployments where tenants live on different
+// shards.
+type TenantRouter map[string]string
+
+// ParseTenantRoutes parses a PROMETHEUS_TENANT_ROUTES value of the form
+// "tenant1=http://shard1:9090,tenant2=http://shard2:9090". Whitespace around
+// entries is ignored; an empty string yields an empty router.
+func ParseTenantRoutes(s string) (TenantRouter, error) {
+	router := TenantRouter{}
+	for entry := range strings.SplitSeq(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		tenant, rawURL, ok := strings.Cut(entry, "=")
+		tenant, rawURL = strings.TrimSpace(tenant), strings.TrimSpace(rawURL)
+		if !ok || tenant == "" || rawURL == "" {
+			return nil, fmt.Errorf("invalid tenant route %q: must be tenant=url", entry)
+		}
+		u, err := url.Parse(rawURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, fmt.Errorf("invalid tenant route %q: URL must be a full http or https URL", entry)
+		}
+		if _, dup := router[tenant]; dup {
+			return nil, fmt.Errorf("duplicate tenant route for %q", tenant)
+		}
+		router[tenant] = rawURL
+	}
+	return router, nil
+}
+
+// Route returns the backend URL for orgID, if one is configured.
+func (r TenantRouter) Route(orgID string) (string, bool) {
+	u, ok := r[orgID]
+	return u, ok
+}
+
+// WithTenantRouter routes requests carrying one of the given org IDs to the
+// mapped Prometheus URL instead of the default one.
+func WithTenantRouter(routes map[string]string) ServerOption {
+	return func(sc *ServerContext) {
+		sc.tenantRouter = TenantRouter(maps.Clone(routes))
+	}
+}
+
+// TenantRouter returns the configured tenant routes; it is nil when none
+// are configured.
+func (sc *ServerContext) TenantRouter() TenantRouter {
+	sc.mutex.RLock()
+	defer sc.mutex.RUnlock()
+	return sc.tenantRouter
+}
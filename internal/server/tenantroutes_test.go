@@ -0,0 +1,55 @@
+package server
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestParseTenantRoutes(t *testing.T) {
+	router, err := ParseTenantRoutes(" tenant1=http://shard1:9090 , tenant2=https://shard2:9090/prometheus,")
+	if err != nil {
+		t.Fatalf("ParseTenantRoutes: %v", err)
+	}
+	if u, ok := router.Route("tenant1"); !ok || u != "http://shard1:9090" {
+		t.Errorf("Route(tenant1) = %q, %t", u, ok)
+	}
+	if u, ok := router.Route("tenant2"); !ok || u != "https://shard2:9090/prometheus" {
+		t.Errorf("Route(tenant2) = %q, %t", u, ok)
+	}
+	if _, ok := router.Route("tenant3"); ok {
+		t.Error("Route(tenant3) should not match")
+	}
+
+	for input, want := range map[string]string{
+		"tenant1":                         "must be tenant=url",
+		"=http://shard1:9090":             "must be tenant=url",
+		"tenant1=shard1:9090":             "full http or https URL",
+		"tenant1=ftp://shard1":            "full http or https URL",
+		"t=http://a:9090,t=http://b:9090": "duplicate tenant route",
+	} {
+		if _, err := ParseTenantRoutes(input); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("ParseTenantRoutes(%q) error = %v, want %q", input, err, want)
+		}
+	}
+}
+
+func TestWithTenantRouter(t *testing.T) {
+	routes := map[string]string{"tenant1": "http://shard1:9090"}
+	sc, err := NewServerContext(context.Background(), WithTenantRouter(routes))
+	if err != nil {
+		t.Fatalf("NewServerContext: %v", err)
+	}
+	routes["tenant2"] = "http://shard2:9090"
+	if _, ok := sc.TenantRouter().Route("tenant2"); ok {
+		t.Error("WithTenantRouter should copy the routes")
+	}
+
+	sc, err = NewServerContext(context.Background())
+	if err != nil {
+		t.Fatalf("NewServerContext: %v", err)
+	}
+	if _, ok := sc.TenantRouter().Route("tenant1"); ok {
+		t.Error("a server without tenant routes should not route")
+	}
+}
@@ -0,0 +1,286 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"net"
+	"net/http"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/prometheus/common/model"
+)
+
+// Metric types accepted by TestMetricDef.Type.
+const (
+	TestMetricGauge   = "gauge"
+	TestMetricCounter = "counter"
+)
+
+const (
+	// testTargetShutdownTimeout bounds how long stopping a test target
+	// waits for in-flight scrapes.
+	testTargetShutdownTimeout = 5 * time.Second
+
+	// MaxTestTargets is the number of test targets one scope may run.
+	MaxTestTargets = 5
+
+	// MaxTestTargetsTotal is the number of test targets run across all
+	// scopes.
+	MaxTestTargetsTotal = 50
+)
+
+// testTargetIdleTimeout is how long a test target runs without being
+// scraped before it is stopped. It is a variable so tests can shorten it.
+var testTargetIdleTimeout = time.Hour
+
+// ErrTestTargetLimit is returned by StartTestTarget when no more test
+// targets can be started.
+var ErrTestTargetLimit = errors.New("test target limit exceeded")
+
+// TestMetricDef describes a metric exposed by a test scrape target.
+type TestMetricDef struct {
+	Name   string
+	Type   string // TestMetricGauge or TestMetricCounter
+	Help   string
+	Value  float64
+	Labels map[string]string
+}
+
+// TestTarget describes a running test scrape target.
+type TestTarget struct {
+	ID      string
+	URL     string
+	Metrics []string
+}
+
+// testTarget is a local /metrics endpoint serving a private registry.
+type testTarget struct {
+	TestTarget
+	owner  string
+	server *http.Server
+
+	// lastScrape is the time of the last scrape, or of the start, in Unix
+	// nanoseconds. idle stops the target once it is idleTimeout in the
+	// past.
+	lastScrape  atomic.Int64
+	idleTimeout time.Duration
+	idle        *time.Timer
+}
+
+// newTestMetricsRegistry registers defs in a new registry, each metric set to
+// its value.
+func newTestMetricsRegistry(defs []TestMetricDef) (*prometheus.Registry, error) {
+	reg := prometheus.NewRegistry()
+	for _, def := range defs {
+		if !model.IsValidLegacyMetricName(def.Name) {
+			return nil, fmt.Errorf("metric %q: invalid metric name", def.Name)
+		}
+		help := def.Help
+		if help == "" {
+			help = "Test metric " + def.Name
+		}
+		labelNames := slices.Sorted(maps.Keys(def.Labels))
+
+		var (
+			collector prometheus.Collector
+			set       func()
+		)
+		switch strings.ToLower(def.Type) {
+		case TestMetricGauge:
+			vec := prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: def.Name, Help: help}, labelNames)
+			collector, set = vec, func() { vec.With(def.Labels).Set(def.Value) }
+		case TestMetricCounter:
+			if def.Value < 0 {
+				return nil, fmt.Errorf("metric %q: counter value must not be negative", def.Name)
+			}
+			vec := prometheus.NewCounterVec(prometheus.CounterOpts{Name: def.Name, Help: help}, labelNames)
+			collector, set = vec, func() { vec.With(def.Labels).Add(def.Value) }
+		default:
+			return nil, fmt.Errorf("metric %q: type must be %q or %q, got %q", def.Name, TestMetricGauge, TestMetricCounter, def.Type)
+		}
+
+		// Register validates the metric and label names before With can
+		// panic on them.
+		if err := reg.Register(collector); err != nil {
+			return nil, fmt.Errorf("metric %q: %w", def.Name, err)
+		}
+		set()
+	}
+	return reg, nil
+}
+
+// StartTestTarget serves defs on /metrics of a new HTTP listener on a free
+// localhost port, for use as a Prometheus scrape target when testing alerting
+// and recording rules. The target belongs to scope (an OAuth user or an MCP
+// session), which may run up to MaxTestTargets of them. It runs until
+// StopTestTarget, StopTestTargets or Shutdown, or until it has not been
+// scraped for an hour.
+func (sc *ServerContext) StartTestTarget(scope string, defs []TestMetricDef) (TestTarget, error) {
+	if len(defs) == 0 {
+		return TestTarget{}, errors.New("at least one metric definition is required")
+	}
+	reg, err := newTestMetricsRegistry(defs)
+	if err != nil {
+		return TestTarget{}, err
+	}
+
+	sc.testTargetsMu.Lock()
+	defer sc.testTargetsMu.Unlock()
+	if len(sc.testTargets) >= MaxTestTargetsTotal {
+		return TestTarget{}, fmt.Errorf("%w: the server runs %d test targets", ErrTestTargetLimit, MaxTestTargetsTotal)
+	}
+	owned := 0
+	for _, target := range sc.testTargets {
+		if target.owner == scope {
+			owned++
+		}
+	}
+	if owned >= MaxTestTargets {
+		return TestTarget{}, fmt.Errorf("%w: at most %d test targets can run at once; stop one with deregister_test_target", ErrTestTargetLimit, MaxTestTargets)
+	}
+
+	listener, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		return TestTarget{}, fmt.Errorf("listen for test target: %w", err)
+	}
+
+	if sc.testTargets == nil {
+		sc.testTargets = make(map[string]*testTarget)
+	}
+	sc.testTargetSeq++
+	target := &testTarget{
+		TestTarget: TestTarget{
+			ID:  fmt.Sprintf("test-target-%d", sc.testTargetSeq),
+			URL: "http://" + listener.Addr().String() + "/metrics",
+		},
+		owner:       scope,
+		idleTimeout: testTargetIdleTimeout,
+	}
+	for _, def := range defs {
+		target.Metrics = append(target.Metrics, def.Name)
+	}
+	target.lastScrape.Store(time.Now().UnixNano())
+
+	metrics := promhttp.HandlerFor(reg, promhttp.HandlerOpts{})
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+		target.lastScrape.Store(time.Now().UnixNano())
+		metrics.ServeHTTP(w, r)
+	})
+	target.server = &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	target.idle = time.AfterFunc(target.idleTimeout, func() { sc.expireTestTarget(target) })
+	sc.testTargets[target.ID] = target
+
+	go func() {
+		if err := target.server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			sc.Logger().Error("Test target stopped", "id", target.ID, "error", err)
+		}
+	}()
+
+	return target.TestTarget, nil
+}
+
+// expireTestTarget stops target when it has not been scraped for its idle
+// timeout, and otherwise checks again once it could have been.
+func (sc *ServerContext) expireTestTarget(target *testTarget) {
+	sc.testTargetsMu.Lock()
+	if sc.testTargets[target.ID] != target {
+		sc.testTargetsMu.Unlock()
+		return
+	}
+	if idle := time.Since(time.Unix(0, target.lastScrape.Load())); idle < target.idleTimeout {
+		target.idle.Reset(target.idleTimeout - idle)
+		sc.testTargetsMu.Unlock()
+		return
+	}
+	delete(sc.testTargets, target.ID)
+	sc.testTargetsMu.Unlock()
+
+	sc.Logger().Info("Stopping idle test target", "id", target.ID, "idle_timeout", target.idleTimeout)
+	if err := target.stop(); err != nil {
+		sc.Logger().Warn("Test target did not stop cleanly", "id", target.ID, "error", err)
+	}
+}
+
+// StopTestTarget shuts down the test target of scope with the given ID and
+// reports whether it existed.
+func (sc *ServerContext) StopTestTarget(scope, id string) (bool, error) {
+	sc.testTargetsMu.Lock()
+	target, ok := sc.testTargets[id]
+	if ok && target.owner != scope {
+		ok = false
+	}
+	if ok {
+		delete(sc.testTargets, id)
+	}
+	sc.testTargetsMu.Unlock()
+	if !ok {
+		return false, nil
+	}
+	return true, target.stop()
+}
+
+// StopTestTargets shuts down every test target of scope, e.g. when the MCP
+// session owning them ends.
+func (sc *ServerContext) StopTestTargets(scope string) error {
+	sc.testTargetsMu.Lock()
+	var targets []*testTarget
+	for id, target := range sc.testTargets {
+		if target.owner == scope {
+			targets = append(targets, target)
+			delete(sc.testTargets, id)
+		}
+	}
+	sc.testTargetsMu.Unlock()
+
+	var errs []error
+	for _, target := range targets {
+		errs = append(errs, target.stop())
+	}
+	return errors.Join(errs...)
+}
+
+// TestTargets returns the running test targets of scope ordered by ID.
+func (sc *ServerContext) TestTargets(scope string) []TestTarget {
+	sc.testTargetsMu.Lock()
+	defer sc.testTargetsMu.Unlock()
+	var targets []TestTarget
+	for _, id := range slices.Sorted(maps.Keys(sc.testTargets)) {
+		if target := sc.testTargets[id]; target.owner == scope {
+			targets = append(targets, target.TestTarget)
+		}
+	}
+	return targets
+}
+
+// stopTestTargets shuts down every running test target.
+func (sc *ServerContext) stopTestTargets() error {
+	sc.testTargetsMu.Lock()
+	targets := sc.testTargets
+	sc.testTargets = nil
+	sc.testTargetsMu.Unlock()
+
+	var errs []error
+	for _, target := range targets {
+		errs = append(errs, target.stop())
+	}
+	return errors.Join(errs...)
+}
+
+// stop shuts down the target's HTTP server.
+func (t *testTarget) stop() error {
+	t.idle.Stop()
+	ctx, cancel := context.WithTimeout(context.Background(), testTargetShutdownTimeout)
+	defer cancel()
+	if err := t.server.Shutdown(ctx); err != nil {
+		return fmt.Errorf("stop test target %s: %w", t.ID, err)
+	}
+	return nil
+}
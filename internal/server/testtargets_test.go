@@ -0,0 +1,201 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func scrape(t *testing.T, url string) string {
+	t.Helper()
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatalf("GET %s: %v", url, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("read %s: %v", url, err)
+	}
+	return string(body)
+}
+
+func TestTestTargets(t *testing.T) {
+	sc, err := NewServerContext(context.Background())
+	if err != nil {
+		t.Fatalf("NewServerContext: %v", err)
+	}
+	defer func() { _ = sc.Shutdown() }()
+
+	target, err := sc.StartTestTarget("", []TestMetricDef{
+		{Name: "test_queue_depth", Type: "gauge", Value: 42, Labels: map[string]string{"queue": "orders"}},
+		{Name: "test_errors_total", Type: "counter", Help: "Errors.", Value: 3},
+	})
+	if err != nil {
+		t.Fatalf("StartTestTarget: %v", err)
+	}
+	if !strings.HasPrefix(target.URL, "http://127.0.0.1:") && !strings.HasPrefix(target.URL, "http://[::1]:") {
+		t.Errorf("URL = %q, want a localhost URL", target.URL)
+	}
+
+	body := scrape(t, target.URL)
+	for _, want := range []string{
+		`test_queue_depth{queue="orders"} 42`,
+		"# HELP test_errors_total Errors.",
+		"# TYPE test_errors_total counter",
+		"test_errors_total 3",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("scrape missing %q:\n%s", want, body)
+		}
+	}
+
+	if got := sc.TestTargets(""); len(got) != 1 || got[0].ID != target.ID {
+		t.Errorf("TestTargets() = %+v", got)
+	}
+
+	stopped, err := sc.StopTestTarget("", target.ID)
+	if err != nil || !stopped {
+		t.Fatalf("StopTestTarget() = %v, %v", stopped, err)
+	}
+	if _, err := http.Get(target.URL); err == nil {
+		t.Error("expected the stopped target to refuse connections")
+	}
+	if stopped, _ := sc.StopTestTarget("", target.ID); stopped {
+		t.Error("second StopTestTarget() reported a running target")
+	}
+}
+
+func TestStartTestTargetInvalid(t *testing.T) {
+	sc, err := NewServerContext(context.Background())
+	if err != nil {
+		t.Fatalf("NewServerContext: %v", err)
+	}
+	defer func() { _ = sc.Shutdown() }()
+
+	tests := []struct {
+		name    string
+		defs    []TestMetricDef
+		wantErr string
+	}{
+		{name: "no metrics", wantErr: "at least one"},
+		{name: "unknown type", defs: []TestMetricDef{{Name: "m", Type: "histogram"}}, wantErr: "type must be"},
+		{name: "negative counter", defs: []TestMetricDef{{Name: "m_total", Type: "counter", Value: -1}}, wantErr: "must not be negative"},
+		{name: "invalid name", defs: []TestMetricDef{{Name: "1bad", Type: "gauge"}}, wantErr: "1bad"},
+		{name: "invalid label", defs: []TestMetricDef{{Name: "m", Type: "gauge", Labels: map[string]string{"__bad": "x"}}}, wantErr: "metric \"m\""},
+		{name: "duplicate", defs: []TestMetricDef{{Name: "m", Type: "gauge"}, {Name: "m", Type: "gauge"}}, wantErr: "metric \"m\""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := sc.StartTestTarget("", tt.defs)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("StartTestTarget() error = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+	if got := sc.TestTargets(""); len(got) != 0 {
+		t.Errorf("failed starts left targets running: %+v", got)
+	}
+}
+
+func TestShutdownStopsTestTargets(t *testing.T) {
+	sc, err := NewServerContext(context.Background())
+	if err != nil {
+		t.Fatalf("NewServerContext: %v", err)
+	}
+	target, err := sc.StartTestTarget("", []TestMetricDef{{Name: "m", Type: "gauge", Value: 1}})
+	if err != nil {
+		t.Fatalf("StartTestTarget: %v", err)
+	}
+	if err := sc.Shutdown(); err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+	if _, err := http.Get(target.URL); err == nil {
+		t.Error("expected Shutdown to stop the test target")
+	}
+}
+
+func TestTestTargetsScopedAndLimited(t *testing.T) {
+	sc, err := NewServerContext(context.Background())
+	if err != nil {
+		t.Fatalf("NewServerContext: %v", err)
+	}
+	defer func() { _ = sc.Shutdown() }()
+	defs := []TestMetricDef{{Name: "m", Type: "gauge", Value: 1}}
+
+	var alice []TestTarget
+	for range MaxTestTargets {
+		target, err := sc.StartTestTarget("session:alice", defs)
+		if err != nil {
+			t.Fatalf("StartTestTarget: %v", err)
+		}
+		alice = append(alice, target)
+	}
+	if _, err := sc.StartTestTarget("session:alice", defs); !errors.Is(err, ErrTestTargetLimit) {
+		t.Errorf("StartTestTarget() past the limit = %v, want ErrTestTargetLimit", err)
+	}
+	bob, err := sc.StartTestTarget("session:bob", defs)
+	if err != nil {
+		t.Fatalf("StartTestTarget for another scope: %v", err)
+	}
+
+	if got := sc.TestTargets("session:bob"); len(got) != 1 || got[0].ID != bob.ID {
+		t.Errorf("TestTargets(bob) = %+v", got)
+	}
+	if stopped, _ := sc.StopTestTarget("session:bob", alice[0].ID); stopped {
+		t.Error("another scope stopped the target")
+	}
+
+	if err := sc.StopTestTargets("session:alice"); err != nil {
+		t.Fatalf("StopTestTargets: %v", err)
+	}
+	if got := sc.TestTargets("session:alice"); len(got) != 0 {
+		t.Errorf("TestTargets(alice) after StopTestTargets = %+v", got)
+	}
+	if _, err := http.Get(alice[0].URL); err == nil {
+		t.Error("expected StopTestTargets to stop the scope's targets")
+	}
+	if got := sc.TestTargets("session:bob"); len(got) != 1 {
+		t.Errorf("StopTestTargets stopped another scope's targets: %+v", got)
+	}
+}
+
+func TestTestTargetIdleTimeout(t *testing.T) {
+	timeout := testTargetIdleTimeout
+	testTargetIdleTimeout = 200 * time.Millisecond
+	t.Cleanup(func() { testTargetIdleTimeout = timeout })
+
+	sc, err := NewServerContext(context.Background())
+	if err != nil {
+		t.Fatalf("NewServerContext: %v", err)
+	}
+	defer func() { _ = sc.Shutdown() }()
+
+	target, err := sc.StartTestTarget("", []TestMetricDef{{Name: "m", Type: "gauge", Value: 1}})
+	if err != nil {
+		t.Fatalf("StartTestTarget: %v", err)
+	}
+	// Scrapes keep the target running past the timeout.
+	for range 4 {
+		time.Sleep(100 * time.Millisecond)
+		scrape(t, target.URL)
+	}
+	if got := sc.TestTargets(""); len(got) != 1 {
+		t.Fatalf("a scraped target was stopped: %+v", got)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for len(sc.TestTargets("")) > 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("the idle target was not stopped")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	if _, err := http.Get(target.URL); err == nil {
+		t.Error("expected the idle target to refuse connections")
+	}
+}
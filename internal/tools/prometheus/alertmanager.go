@@ -0,0 +1,394 @@
+package prometheus
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/labels"
+	"gopkg.in/yaml.v3"
+
+	"github.com/giantswarm/mcp-prometheus/internal/server"
+)
+
+// alertmanagerConfig is the subset of the Alertmanager configuration needed
+// to describe receivers and simulate routing. Credentials are never kept:
+// receivers are reduced to the count of each *_configs integration.
+type alertmanagerConfig struct {
+	Route     *alertmanagerRoute `yaml:"route"`
+	Receivers []map[string]any   `yaml:"receivers"`
+}
+
+// alertmanagerRoute is a node of the Alertmanager routing tree.
+type alertmanagerRoute struct {
+	Receiver string               `yaml:"receiver"`
+	Match    map[string]string    `yaml:"match"`
+	MatchRE  map[string]string    `yaml:"match_re"`
+	Matchers []string             `yaml:"matchers"`
+	Continue bool                 `yaml:"continue"`
+	Routes   []*alertmanagerRoute `yaml:"routes"`
+
+	matchers []*labels.Matcher
+}
+
+// parseAlertmanagerConfig parses an Alertmanager YAML configuration,
+// compiles the route matchers and propagates receivers to child routes that
+// do not set their own.
+func parseAlertmanagerConfig(raw string) (*alertmanagerConfig, error) {
+	var cfg alertmanagerConfig
+	if err := yaml.Unmarshal([]byte(raw), &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse Alertmanager configuration: %w", err)
+	}
+	if cfg.Route != nil {
+		if err := cfg.Route.compile(""); err != nil {
+			return nil, err
+		}
+	}
+	return &cfg, nil
+}
+
+// receiverIntegrations returns, for every receiver, how many integrations of
+// each type it configures (e.g. slack_configs with two entries counts as
+// "slack": 2).
+func (c *alertmanagerConfig) receiverIntegrations() map[string]map[string]int {
+	result := make(map[string]map[string]int, len(c.Receivers))
+	for _, receiver := range c.Receivers {
+		name, _ := receiver["name"].(string)
+		counts := map[string]int{}
+		for key, value := range receiver {
+			integration, ok := strings.CutSuffix(key, "_configs")
+			if !ok {
+				continue
+			}
+			if entries, ok := value.([]any); ok && len(entries) > 0 {
+				counts[integration] = len(entries)
+			}
+		}
+		result[name] = counts
+	}
+	return result
+}
+
+// amMatcherRegex splits an Alertmanager matcher such as severity=~"crit.*"
+// into label name, operator and value.
+var amMatcherRegex = regexp.MustCompile(`^\s*([a-zA-Z_][a-zA-Z0-9_]*)\s*(=~|!~|!=|=)\s*(.*?)\s*$`)
+
+// amMatchTypes maps Alertmanager matcher operators to label matcher types.
+var amMatchTypes = map[string]labels.MatchType{
+	"=":  labels.MatchEqual,
+	"!=": labels.MatchNotEqual,
+	"=~": labels.MatchRegexp,
+	"!~": labels.MatchNotRegexp,
+}
+
+// parseAlertmanagerMatcher parses a single entry of a route's matchers list.
+func parseAlertmanagerMatcher(s string) (*labels.Matcher, error) {
+	m := amMatcherRegex.FindStringSubmatch(s)
+	if m == nil {
+		return nil, fmt.Errorf("invalid route matcher %q", s)
+	}
+	value := m[3]
+	if strings.HasPrefix(value, `"`) {
+		unquoted, err := strconv.Unquote(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid route matcher %q: %w", s, err)
+		}
+		value = unquoted
+	}
+	return labels.NewMatcher(amMatchTypes[m[2]], m[1], value)
+}
+
+// compile builds the label matchers of r and its children from the match,
+// match_re and matchers fields.
+func (r *alertmanagerRoute) compile(parentReceiver string) error {
+	if r.Receiver == "" {
+		r.Receiver = parentReceiver
+	}
+	for name, value := range r.Match {
+		r.matchers = append(r.matchers, labels.MustNewMatcher(labels.MatchEqual, name, value))
+	}
+	for name, value := range r.MatchRE {
+		m, err := labels.NewMatcher(labels.MatchRegexp, name, value)
+		if err != nil {
+			return fmt.Errorf("invalid route match_re %s=%q: %w", name, value, err)
+		}
+		r.matchers = append(r.matchers, m)
+	}
+	for _, s := range r.Matchers {
+		m, err := parseAlertmanagerMatcher(s)
+		if err != nil {
+			return err
+		}
+		r.matchers = append(r.matchers, m)
+	}
+	for _, child := range r.Routes {
+		if err := child.compile(r.Receiver); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// match returns the routes an alert with the given labels is delivered to,
+// following Alertmanager semantics: children are tried in order, the first
+// matching child stops the search unless it sets continue, and a route
+// without any matching child handles the alert itself.
+func (r *alertmanagerRoute) match(lset map[string]string) []*alertmanagerRoute {
+	for _, m := range r.matchers {
+		if !m.Matches(lset[m.Name]) {
+			return nil
+		}
+	}
+	var matched []*alertmanagerRoute
+	for _, child := range r.Routes {
+		routes := child.match(lset)
+		matched = append(matched, routes...)
+		if routes != nil && !child.Continue {
+			break
+		}
+	}
+	if len(matched) == 0 {
+		matched = append(matched, r)
+	}
+	return matched
+}
+
+// formatIntegrations renders integration counts as "pagerduty, slack x2".
+func formatIntegrations(integrations map[string]int) string {
+	if integrations == nil {
+		return "(unknown)"
+	}
+	if len(integrations) == 0 {
+		return "(none)"
+	}
+	names := make([]string, 0, len(integrations))
+	for name := range integrations {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	parts := make([]string, len(names))
+	for i, name := range names {
+		parts[i] = name
+		if n := integrations[name]; n > 1 {
+			parts[i] = fmt.Sprintf("%s x%d", name, n)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// handleListAlertmanagerReceivers handles the list_alertmanager_receivers tool
+func handleListAlertmanagerReceivers(ctx context.Context, request mcp.CallToolRequest, client *Client, sc *server.ServerContext) (*mcp.CallToolResult, error) {
+	params := extractParams(request)
+
+	simulate := map[string]string{}
+	if raw, ok := params["simulate_routing"].(map[string]any); ok {
+		for k, v := range raw {
+			s, ok := v.(string)
+			if !ok {
+				return &mcp.CallToolResult{
+					IsError: true,
+					Content: []mcp.Content{
+						mcp.TextContent{
+							Type: contentTypeText,
+							Text: fmt.Sprintf("Error: label %q must have a string value", k),
+						},
+					},
+				}, nil
+			}
+			simulate[k] = s
+		}
+	}
+
+	sc.Logger().Debug("Listing Alertmanager receivers", "simulate_routing", simulate)
+
+	receivers, err := client.ListAlertmanagerReceivers(ctx)
+	if err != nil {
+		sc.Logger().Error("Failed to list Alertmanager receivers", "error", err)
+		return &mcp.CallToolResult{
+			IsError: true,
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: contentTypeText,
+					Text: fmt.Sprintf("Error listing Alertmanager receivers: %v", err),
+				},
+			},
+		}, nil
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Found %d receivers:\n\nreceiver | integrations\n", len(receivers))
+	for _, r := range receivers {
+		fmt.Fprintf(&b, "%s | %s\n", r.Name, formatIntegrations(r.Integrations))
+	}
+
+	if len(simulate) > 0 {
+		cfg, err := client.GetAlertmanagerConfig(ctx)
+		if err != nil {
+			sc.Logger().Error("Failed to read Alertmanager configuration", "error", err)
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{
+					mcp.TextContent{
+						Type: contentTypeText,
+						Text: fmt.Sprintf("Error simulating routing: %v", err),
+					},
+				},
+			}, nil
+		}
+		if cfg.Route == nil {
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{
+					mcp.TextContent{
+						Type: contentTypeText,
+						Text: "Error simulating routing: Alertmanager configuration has no route",
+					},
+				},
+			}, nil
+		}
+
+		lset := model.LabelSet{}
+		for k, v := range simulate {
+			lset[model.LabelName(k)] = model.LabelValue(v)
+		}
+		fmt.Fprintf(&b, "\nAn alert with labels %s is routed to:\n", lset)
+		for _, route := range cfg.Route.match(simulate) {
+			fmt.Fprintf(&b, "- %s\n", valueOrNone(route.Receiver))
+		}
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.TextContent{
+				Type: contentTypeText,
+				Text: b.String(),
+			},
+		},
+	}, nil
+}
+
+// alertmanagerAlertStates are the tool parameters that include or exclude
+// alerts by state; they map to the query parameters of GET /api/v2/alerts.
+var alertmanagerAlertStates = []string{"active", "silenced", "inhibited", "unprocessed"}
+
+// handleGetAlertmanagerAlerts handles the get_alertmanager_alerts tool
+func handleGetAlertmanagerAlerts(ctx context.Context, request mcp.CallToolRequest, client *Client, sc *server.ServerContext) (*mcp.CallToolResult, error) {
+	params := extractParams(request)
+
+	filter := AlertmanagerAlertsFilter{
+		Receiver: getStringParam(params, "receiver"),
+		Filter:   extractStringArray(params, "filter"),
+	}
+	invalid := func(msg string) *mcp.CallToolResult {
+		return &mcp.CallToolResult{
+			IsError: true,
+			Content: []mcp.Content{
+				mcp.TextContent{Type: contentTypeText, Text: "Error: " + msg},
+			},
+		}
+	}
+	if filter.Receiver != "" {
+		if _, err := regexp.Compile(filter.Receiver); err != nil {
+			return invalid(fmt.Sprintf("invalid receiver regular expression: %v", err)), nil
+		}
+	}
+	for _, m := range filter.Filter {
+		if _, err := parseAlertmanagerMatcher(m); err != nil {
+			return invalid(fmt.Sprintf("invalid filter: %v", err)), nil
+		}
+	}
+	for _, name := range alertmanagerAlertStates {
+		raw := getStringParam(params, name)
+		if raw == "" {
+			continue
+		}
+		include, err := strconv.ParseBool(raw)
+		if err != nil {
+			return invalid(fmt.Sprintf("%s must be 'true' or 'false', got %q", name, raw)), nil
+		}
+		switch name {
+		case "active":
+			filter.Active = &include
+		case "silenced":
+			filter.Silenced = &include
+		case "inhibited":
+			filter.Inhibited = &include
+		case "unprocessed":
+			filter.Unprocessed = &include
+		}
+	}
+
+	sc.Logger().Debug("Getting Alertmanager alerts", "receiver", filter.Receiver, "filter", filter.Filter)
+
+	alerts, err := client.ListAlertmanagerAlerts(ctx, filter)
+	if err != nil {
+		sc.Logger().Error("Failed to get Alertmanager alerts", "error", err)
+		return &mcp.CallToolResult{
+			IsError: true,
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: contentTypeText,
+					Text: fmt.Sprintf("Error getting Alertmanager alerts: %v", err),
+				},
+			},
+		}, nil
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.TextContent{
+				Type: contentTypeText,
+				Text: formatAlertmanagerAlerts(alerts),
+			},
+		},
+	}, nil
+}
+
+// formatAlertmanagerAlerts renders alerts sorted by label set, each with its
+// state, receivers, start time, the silences or alerts suppressing it and
+// its summary annotation.
+func formatAlertmanagerAlerts(alerts []AlertmanagerAlert) string {
+	type entry struct {
+		alert  AlertmanagerAlert
+		labels string
+	}
+	entries := make([]entry, len(alerts))
+	for i, a := range alerts {
+		lset := model.LabelSet{}
+		for k, v := range a.Labels {
+			lset[model.LabelName(k)] = model.LabelValue(v)
+		}
+		entries[i] = entry{a, lset.String()}
+	}
+	slices.SortFunc(entries, func(a, b entry) int { return strings.Compare(a.labels, b.labels) })
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Found %d alerts in Alertmanager:\n", len(entries))
+	for i, e := range entries {
+		a := e.alert
+		receivers := make([]string, len(a.Receivers))
+		for j, r := range a.Receivers {
+			receivers[j] = r.Name
+		}
+		fmt.Fprintf(&b, "\n%d. %s [%s]\n", i+1, valueOrNone(a.Labels["alertname"]), valueOrNone(a.Status.State))
+		fmt.Fprintf(&b, "   labels: %s\n", e.labels)
+		fmt.Fprintf(&b, "   receivers: %s\n", valueOrNone(strings.Join(receivers, ", ")))
+		fmt.Fprintf(&b, "   started: %s\n", a.StartsAt.UTC().Format(time.RFC3339))
+		if len(a.Status.SilencedBy) > 0 {
+			fmt.Fprintf(&b, "   silenced by: %s\n", strings.Join(a.Status.SilencedBy, ", "))
+		}
+		if len(a.Status.InhibitedBy) > 0 {
+			fmt.Fprintf(&b, "   inhibited by: %s\n", strings.Join(a.Status.InhibitedBy, ", "))
+		}
+		if summary := a.Annotations["summary"]; summary != "" {
+			fmt.Fprintf(&b, "   summary: %s\n", summary)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,372 @@
+package prometheus
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/giantswarm/mcp-prometheus/internal/server"
+)
+
+const testAlertmanagerConfig = `
+route:
+  receiver: default
+  routes:
+    - matchers:
+        - severity="critical"
+      receiver: pager
+      continue: true
+    - match:
+        team: db
+      receiver: db-team
+      routes:
+        - match_re:
+            service: "postgres|mysql"
+          receiver: dba
+    - matchers:
+        - team=~"db|infra"
+receivers:
+  - name: default
+    email_configs:
+      - to: ops@example.com
+        auth_password: secret
+  - name: pager
+    pagerduty_configs:
+      - routing_key: super-secret-key
+    slack_configs:
+      - api_url: https://hooks.slack.com/services/T000/B000/XXXX
+      - api_url: https://hooks.slack.com/services/T000/B000/YYYY
+  - name: db-team
+    webhook_configs:
+      - url: http://hooks.internal/db
+  - name: dba
+  - name: blackhole
+`
+
+func TestAlertmanagerRouteMatch(t *testing.T) {
+	cfg, err := parseAlertmanagerConfig(testAlertmanagerConfig)
+	if err != nil {
+		t.Fatalf("parseAlertmanagerConfig: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		labels map[string]string
+		want   []string
+	}{
+		{"no child matches", map[string]string{"alertname": "Foo"}, []string{"default"}},
+		{"continue to next route", map[string]string{"severity": "critical", "team": "db"}, []string{"pager", "db-team"}},
+		{"nested route", map[string]string{"team": "db", "service": "mysql"}, []string{"dba"}},
+		{"first match stops", map[string]string{"team": "db", "service": "redis"}, []string{"db-team"}},
+		{"inherits parent receiver", map[string]string{"team": "infra"}, []string{"default"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, r := range cfg.Route.match(tt.labels) {
+				got = append(got, r.Receiver)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("match(%v) = %v, want %v", tt.labels, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseAlertmanagerConfigInvalidMatcher(t *testing.T) {
+	_, err := parseAlertmanagerConfig("route:\n  receiver: default\n  routes:\n    - matchers: ['severity']\n")
+	if err == nil || !strings.Contains(err.Error(), "invalid route matcher") {
+		t.Errorf("expected invalid matcher error, got %v", err)
+	}
+}
+
+func TestHandleListAlertmanagerReceivers(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/v2/receivers":
+			_, _ = w.Write([]byte(`[{"name":"default"},{"name":"pager"},{"name":"db-team"},{"name":"dba"},{"name":"blackhole"}]`))
+		case "/api/v2/status":
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"config": map[string]any{"original": testAlertmanagerConfig},
+			})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer mockServer.Close()
+
+	ctx := context.Background()
+	sc, err := server.NewServerContext(ctx,
+		server.WithPrometheusConfig(server.PrometheusConfig{URL: "http://prometheus:9090", Alertmanager: server.AlertmanagerConfig{URL: mockServer.URL}}),
+		server.WithSlogLogger(discardLogger()),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create server context: %v", err)
+	}
+	defer func() { _ = sc.Shutdown() }()
+
+	client, err := NewClient(sc.PrometheusConfig(), sc.Logger())
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+
+	request := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Name: "list_alertmanager_receivers",
+			Arguments: map[string]any{
+				"simulate_routing": map[string]any{"severity": "critical", "team": "db"},
+			},
+		},
+	}
+	result, err := handleListAlertmanagerReceivers(ctx, request, client, sc)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.IsError {
+		t.Fatalf("expected success, got error: %v", result.Content)
+	}
+	text := result.Content[0].(mcp.TextContent).Text
+
+	for _, want := range []string{
+		"Found 5 receivers:",
+		"default | email\n",
+		"pager | pagerduty, slack x2\n",
+		"db-team | webhook\n",
+		"dba | (none)\n",
+		`An alert with labels {severity="critical", team="db"} is routed to:` + "\n- pager\n- db-team\n",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("output missing %q:\n%s", want, text)
+		}
+	}
+	for _, secret := range []string{"secret", "hooks.slack.com", "ops@example.com", "hooks.internal"} {
+		if strings.Contains(text, secret) {
+			t.Errorf("output leaks %q:\n%s", secret, text)
+		}
+	}
+}
+
+func TestListAlertmanagerReceiversWithoutConfig(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v2/receivers" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`[{"name":"default"}]`))
+	}))
+	defer mockServer.Close()
+
+	client, err := NewClient(server.PrometheusConfig{URL: "http://prometheus:9090", Alertmanager: server.AlertmanagerConfig{URL: mockServer.URL}}, discardLogger())
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+
+	receivers, err := client.ListAlertmanagerReceivers(context.Background())
+	if err != nil {
+		t.Fatalf("ListAlertmanagerReceivers: %v", err)
+	}
+	if len(receivers) != 1 || receivers[0].Name != "default" || receivers[0].Integrations != nil {
+		t.Errorf("unexpected receivers: %+v", receivers)
+	}
+	if got := formatIntegrations(receivers[0].Integrations); got != "(unknown)" {
+		t.Errorf("formatIntegrations() = %q, want (unknown)", got)
+	}
+}
+
+func TestHandleGetAlertmanagerAlerts(t *testing.T) {
+	var gotQuery url.Values
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v2/alerts" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		gotQuery = r.URL.Query()
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`[
+			{"labels":{"alertname":"HighLatency","severity":"critical"},"annotations":{"summary":"p99 above 2s"},
+			 "startsAt":"2024-01-15T10:00:00.123Z","receivers":[{"name":"pager"},{"name":"slack"}],
+			 "status":{"state":"active","silencedBy":[],"inhibitedBy":[]}},
+			{"labels":{"alertname":"DiskFull","severity":"warning"},"annotations":{},
+			 "startsAt":"2024-01-15T09:00:00Z","receivers":[{"name":"default"}],
+			 "status":{"state":"suppressed","silencedBy":["a1b2"],"inhibitedBy":[]}}
+		]`))
+	}))
+	defer mockServer.Close()
+
+	ctx := context.Background()
+	sc, err := server.NewServerContext(ctx,
+		server.WithPrometheusConfig(server.PrometheusConfig{URL: "http://prometheus:9090", Alertmanager: server.AlertmanagerConfig{URL: mockServer.URL}}),
+		server.WithSlogLogger(discardLogger()),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create server context: %v", err)
+	}
+	defer func() { _ = sc.Shutdown() }()
+
+	client, err := NewClient(sc.PrometheusConfig(), sc.Logger())
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+
+	call := func(args map[string]any) *mcp.CallToolResult {
+		t.Helper()
+		request := mcp.CallToolRequest{Params: mcp.CallToolParams{Name: "get_alertmanager_alerts", Arguments: args}}
+		result, err := handleGetAlertmanagerAlerts(ctx, request, client, sc)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return result
+	}
+
+	result := call(map[string]any{
+		"receiver":  "pager|default",
+		"filter":    []any{`severity=~"critical|warning"`},
+		"inhibited": "false",
+	})
+	if result.IsError {
+		t.Fatalf("expected success, got error: %v", result.Content)
+	}
+	if gotQuery.Get("receiver") != "pager|default" || gotQuery.Get("filter") != `severity=~"critical|warning"` ||
+		gotQuery.Get("inhibited") != "false" || gotQuery.Has("silenced") {
+		t.Errorf("unexpected query parameters: %v", gotQuery)
+	}
+
+	text := result.Content[0].(mcp.TextContent).Text
+	for _, want := range []string{
+		"Found 2 alerts in Alertmanager:\n",
+		"\n1. DiskFull [suppressed]\n" +
+			`   labels: {alertname="DiskFull", severity="warning"}` + "\n" +
+			"   receivers: default\n" +
+			"   started: 2024-01-15T09:00:00Z\n" +
+			"   silenced by: a1b2\n",
+		"\n2. HighLatency [active]\n",
+		"   receivers: pager, slack\n",
+		"   summary: p99 above 2s\n",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("output missing %q:\n%s", want, text)
+		}
+	}
+
+	for _, args := range []map[string]any{
+		{"filter": []any{"severity"}},
+		{"receiver": "("},
+		{"silenced": "no"},
+	} {
+		if result := call(args); !result.IsError {
+			t.Errorf("expected an error for %v, got %v", args, result.Content)
+		}
+	}
+}
+
+func TestAlertmanagerCredentials(t *testing.T) {
+	var gotAuth, gotOrgID string
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth, gotOrgID = r.Header.Get("Authorization"), r.Header.Get("X-Scope-OrgID")
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`[]`))
+	}))
+	defer mockServer.Close()
+
+	tests := []struct {
+		name         string
+		alertmanager server.AlertmanagerConfig
+		wantAuth     string
+	}{
+		{"inherits Prometheus credentials", server.AlertmanagerConfig{URL: mockServer.URL}, "Bearer prom-token"},
+		{"own bearer token", server.AlertmanagerConfig{URL: mockServer.URL, Token: "am-token"}, "Bearer am-token"},
+		{"own basic auth", server.AlertmanagerConfig{URL: mockServer.URL, Username: "am", Password: "pw"}, "Basic YW06cHc="},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := NewClient(server.PrometheusConfig{
+				URL:          "http://prometheus:9090",
+				Token:        "prom-token",
+				OrgID:        "tenant-a",
+				Alertmanager: tt.alertmanager,
+			}, discardLogger())
+			if err != nil {
+				t.Fatalf("NewClient: %v", err)
+			}
+			if _, err := client.ListAlertmanagerAlerts(context.Background(), AlertmanagerAlertsFilter{}); err != nil {
+				t.Fatalf("ListAlertmanagerAlerts: %v", err)
+			}
+			if gotAuth != tt.wantAuth || gotOrgID != "tenant-a" {
+				t.Errorf("Authorization = %q, X-Scope-OrgID = %q; want %q, tenant-a", gotAuth, gotOrgID, tt.wantAuth)
+			}
+		})
+	}
+
+	if _, err := NewClient(server.PrometheusConfig{
+		URL:          "http://prometheus:9090",
+		Alertmanager: server.AlertmanagerConfig{Token: "a", TokenFile: "b"},
+	}, discardLogger()); err == nil {
+		t.Error("expected an error for an Alertmanager token and token file")
+	}
+}
+
+func TestAlertmanagerURLParam(t *testing.T) {
+	var hit bool
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hit = r.URL.Path == "/api/v2/alerts"
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`[]`))
+	}))
+	defer mockServer.Close()
+
+	ctx := context.Background()
+	sc, err := server.NewServerContext(ctx,
+		server.WithPrometheusConfig(server.PrometheusConfig{URL: "http://prometheus:9090", Alertmanager: server.AlertmanagerConfig{URL: "http://alertmanager.invalid"}}),
+		server.WithSlogLogger(discardLogger()),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create server context: %v", err)
+	}
+	defer func() { _ = sc.Shutdown() }()
+	defaultClient, err := NewClient(sc.PrometheusConfig(), sc.Logger())
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+
+	handler := withDynamicPrometheusClient(handleGetAlertmanagerAlerts, defaultClient, sc)
+	request := mcp.CallToolRequest{Params: mcp.CallToolParams{Name: "get_alertmanager_alerts", Arguments: map[string]any{"alertmanager_url": mockServer.URL}}}
+	result, err := handler(ctx, request)
+	if err != nil || result.IsError {
+		t.Fatalf("unexpected failure: %v %v", err, result)
+	}
+	if !hit {
+		t.Error("expected the request to go to alertmanager_url")
+	}
+
+	request.Params.Arguments = map[string]any{"alertmanager_url": "http://169.254.169.254"}
+	if result, _ := handler(ctx, request); !result.IsError {
+		t.Error("expected a link-local alertmanager_url to be rejected")
+	}
+
+	// Credentials are never sent to a caller-chosen Alertmanager.
+	for name, config := range map[string]server.PrometheusConfig{
+		"Prometheus credentials":   {URL: "http://prometheus:9090", Token: "prom-token"},
+		"Alertmanager credentials": {URL: "http://prometheus:9090", Alertmanager: server.AlertmanagerConfig{URL: "http://alertmanager.invalid", Token: "am-token"}},
+	} {
+		secured, err := server.NewServerContext(ctx, server.WithPrometheusConfig(config), server.WithSlogLogger(discardLogger()))
+		if err != nil {
+			t.Fatalf("Failed to create server context: %v", err)
+		}
+		defer func() { _ = secured.Shutdown() }()
+		hit = false
+		handler := withDynamicPrometheusClient(handleGetAlertmanagerAlerts, nil, secured)
+		request.Params.Arguments = map[string]any{"alertmanager_url": mockServer.URL}
+		if result, _ := handler(ctx, request); !result.IsError || hit {
+			t.Errorf("%s: expected alertmanager_url to be rejected", name)
+		}
+	}
+}
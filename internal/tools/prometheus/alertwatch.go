@@ -0,0 +1,358 @@
+package prometheus
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	mcpserver "github.com/mark3labs/mcp-go/server"
+	v1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/prometheus/common/model"
+
+	"github.com/giantswarm/mcp-prometheus/internal/server"
+)
+
+// toolSubscribeAlerts is the registered name of the alert subscription tool.
+const toolSubscribeAlerts = "subscribe_alerts"
+
+const (
+	// defaultAlertPollInterval is how often subscribe_alerts polls the alerts
+	// when no interval is given.
+	defaultAlertPollInterval = 30 * time.Second
+
+	// minAlertPollInterval is the shortest interval subscribe_alerts accepts.
+	minAlertPollInterval = 5 * time.Second
+
+	// alertNotificationLogger is the logger name of the notifications/message
+	// notifications sent when alerts start or stop firing.
+	alertNotificationLogger = "mcp-prometheus.alerts"
+
+	// maxAlertSubscriptions is the number of sessions that may subscribe at
+	// once. A session has at most one subscription; subscribing again
+	// replaces it.
+	maxAlertSubscriptions = 100
+)
+
+// notificationSender sends a notification to the session with the given ID.
+// It is satisfied by *mcpserver.MCPServer.
+type notificationSender interface {
+	SendNotificationToSpecificClient(sessionID string, method string, params map[string]any) error
+}
+
+// alertSubscriptions tracks the alert subscription of each MCP session. A
+// subscription polls /api/v1/alerts and notifies its session when alerts
+// start or stop firing, until it is stopped, replaced by a new subscription
+// of the same session, the session ends or the server shuts down.
+type alertSubscriptions struct {
+	sender notificationSender
+
+	mu    sync.Mutex
+	watch map[string]*alertWatch
+}
+
+// alertWatch is the subscription of one session.
+type alertWatch struct {
+	sessionID string
+	// uri is the alerts resource of the Prometheus server and tenant the
+	// subscription polls, announced when its alerts change.
+	uri    string
+	ctx    context.Context
+	cancel context.CancelFunc
+	// firing holds the alerts firing at the last poll, by fingerprint.
+	firing map[model.Fingerprint]v1.Alert
+}
+
+func newAlertSubscriptions(sender notificationSender) *alertSubscriptions {
+	return &alertSubscriptions{sender: sender, watch: make(map[string]*alertWatch)}
+}
+
+// handle handles the subscribe_alerts tool. Subscribing replies with the
+// alerts firing now; later changes arrive as notifications only.
+func (a *alertSubscriptions) handle(ctx context.Context, request mcp.CallToolRequest, client *Client, sc *server.ServerContext) (*mcp.CallToolResult, error) {
+	params := extractParams(request)
+
+	session := mcpserver.ClientSessionFromContext(ctx)
+	if session == nil || session.SessionID() == "" {
+		return &mcp.CallToolResult{
+			IsError: true,
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: contentTypeText,
+					Text: "Error: subscribe_alerts needs a transport with sessions (stdio, SSE or stateful streamable HTTP) to send notifications",
+				},
+			},
+		}, nil
+	}
+	sessionID := session.SessionID()
+
+	if getStringParam(params, "stop") == "true" {
+		text := "No alert subscription to stop."
+		if a.stop(sessionID, nil) {
+			text = "Stopped the alert subscription."
+		}
+		sc.Logger().Debug("Stopping alert subscription", "session", sessionID)
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: contentTypeText,
+					Text: text,
+				},
+			},
+		}, nil
+	}
+
+	interval := defaultAlertPollInterval
+	if raw := getStringParam(params, "interval"); raw != "" {
+		d, err := model.ParseDuration(raw)
+		if err != nil || time.Duration(d) < minAlertPollInterval {
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{
+					mcp.TextContent{
+						Type: contentTypeText,
+						Text: fmt.Sprintf("Error: interval must be a duration of at least %s, got %q", model.Duration(minAlertPollInterval), raw),
+					},
+				},
+			}, nil
+		}
+		interval = time.Duration(d)
+	}
+
+	alerts, err := client.GetAlerts(ctx)
+	if err != nil {
+		sc.Logger().Error("Failed to get alerts", "error", err)
+		return &mcp.CallToolResult{
+			IsError: true,
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: contentTypeText,
+					Text: fmt.Sprintf("Error getting alerts: %v", err),
+				},
+			},
+		}, nil
+	}
+
+	firing := firingAlerts(alerts)
+	uri := alertsResourceURI(params)
+	w, err := a.start(sc, sessionID, uri, firing)
+	if err != nil {
+		return &mcp.CallToolResult{
+			IsError: true,
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: contentTypeText,
+					Text: fmt.Sprintf("Error: %v", err),
+				},
+			},
+		}, nil
+	}
+	go a.run(sc, client, w, interval)
+
+	sc.Logger().Debug("Subscribed to alerts", "session", sessionID, "interval", interval)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Subscribed to alert changes, polling every %s.\n", model.Duration(interval))
+	fmt.Fprintf(&b, "When an alert starts or stops firing, a notifications/message notification (logger %q) and a notifications/resources/updated notification for %s are sent.\n", alertNotificationLogger, uri)
+	b.WriteString("Call subscribe_alerts with stop 'true' to unsubscribe.\n")
+	fmt.Fprintf(&b, "\n%d alerts firing now:\n", len(firing))
+	for _, alert := range sortedAlerts(firing) {
+		fmt.Fprintf(&b, "- %s since %s\n", alert.Labels, alert.ActiveAt.UTC().Format(time.RFC3339))
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.TextContent{
+				Type: contentTypeText,
+				Text: b.String(),
+			},
+		},
+	}, nil
+}
+
+// start registers a subscription for sessionID with the alerts firing now,
+// stopping the one it had. It fails when maxAlertSubscriptions other
+// sessions are subscribed. The subscription ends with the server context.
+func (a *alertSubscriptions) start(sc *server.ServerContext, sessionID, uri string, firing map[model.Fingerprint]v1.Alert) (*alertWatch, error) {
+	a.mu.Lock()
+	old := a.watch[sessionID]
+	if old == nil && len(a.watch) >= maxAlertSubscriptions {
+		a.mu.Unlock()
+		return nil, fmt.Errorf("%d sessions are subscribed to alerts, the most the server allows; try again later", maxAlertSubscriptions)
+	}
+	ctx, cancel := context.WithCancel(sc.Context())
+	w := &alertWatch{sessionID: sessionID, uri: uri, ctx: ctx, cancel: cancel, firing: firing}
+	a.watch[sessionID] = w
+	a.mu.Unlock()
+
+	context.AfterFunc(ctx, func() { a.stop(sessionID, w) })
+	if old != nil {
+		old.cancel()
+	}
+	return w, nil
+}
+
+// stopScope cancels the subscription of the MCP session a caller scope
+// names, for use with onSessionEnd.
+func (a *alertSubscriptions) stopScope(scope string) {
+	if sessionID, ok := strings.CutPrefix(scope, sessionScopePrefix); ok {
+		a.stop(sessionID, nil)
+	}
+}
+
+// stop cancels the subscription of sessionID, if it is w or w is nil, and
+// reports whether there was one.
+func (a *alertSubscriptions) stop(sessionID string, w *alertWatch) bool {
+	a.mu.Lock()
+	current, ok := a.watch[sessionID]
+	if ok && (w == nil || current == w) {
+		delete(a.watch, sessionID)
+	}
+	a.mu.Unlock()
+	if !ok || w != nil && current != w {
+		return false
+	}
+	current.cancel()
+	return true
+}
+
+// run polls the alerts every interval until w is stopped.
+func (a *alertSubscriptions) run(sc *server.ServerContext, client *Client, w *alertWatch, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-w.ctx.Done():
+			return
+		case <-ticker.C:
+			a.poll(w.ctx, sc, client, w)
+		}
+	}
+}
+
+// poll fetches the alerts once and notifies w's session of the alerts that
+// started or stopped firing since the previous poll. The subscription is
+// stopped when the session is gone.
+func (a *alertSubscriptions) poll(ctx context.Context, sc *server.ServerContext, client *Client, w *alertWatch) {
+	alerts, err := client.GetAlerts(ctx)
+	if err != nil {
+		sc.Logger().Warn("Failed to poll alerts", "session", w.sessionID, "error", err)
+		return
+	}
+	firing := firingAlerts(alerts)
+	events := diffAlerts(w.firing, firing)
+	w.firing = firing
+	if len(events) == 0 {
+		return
+	}
+
+	for _, e := range events {
+		err = a.sender.SendNotificationToSpecificClient(w.sessionID, string(mcp.MethodNotificationMessage), e.params())
+		if err != nil {
+			break
+		}
+	}
+	if err == nil {
+		err = a.sender.SendNotificationToSpecificClient(w.sessionID, mcp.MethodNotificationResourceUpdated, map[string]any{"uri": w.uri})
+	}
+	switch {
+	case errors.Is(err, mcpserver.ErrSessionNotFound), errors.Is(err, mcpserver.ErrSessionNotInitialized):
+		sc.Logger().Debug("Session gone, stopping alert subscription", "session", w.sessionID)
+		a.stop(w.sessionID, w)
+	case err != nil:
+		sc.Logger().Warn("Failed to send alert notification", "session", w.sessionID, "error", err)
+	}
+}
+
+// alertsResourceURI returns the URI of the alerts resource that reads the
+// Prometheus server and tenant the org_id, profile and prometheus_url
+// parameters of a tool call select: prometheus://alerts when there are none,
+// which follows the reader's tenant like the tool call did.
+func alertsResourceURI(params map[string]any) string {
+	var query []string
+	for _, name := range alertsResourceParams {
+		if v := getStringParam(params, name); v != "" {
+			// The URI template expects spaces as %20, not +.
+			query = append(query, name+"="+strings.ReplaceAll(url.QueryEscape(v), "+", "%20"))
+		}
+	}
+	if len(query) == 0 {
+		return resourceAlerts
+	}
+	return resourceAlerts + "?" + strings.Join(query, "&")
+}
+
+// alertEvent is an alert that started (firing) or stopped (resolved)
+// firing between two polls.
+type alertEvent struct {
+	firing bool
+	alert  v1.Alert
+}
+
+// params returns the notifications/message parameters of e: a warning for a
+// firing alert and an info message for a resolved one.
+func (e alertEvent) params() map[string]any {
+	level, event := mcp.LoggingLevelInfo, "resolved"
+	if e.firing {
+		level, event = mcp.LoggingLevelWarning, "firing"
+	}
+	return map[string]any{
+		"level":  level,
+		"logger": alertNotificationLogger,
+		"data": map[string]any{
+			"event":       event,
+			"alertname":   string(e.alert.Labels[model.AlertNameLabel]),
+			"labels":      labelMap(e.alert.Labels),
+			"annotations": labelMap(e.alert.Annotations),
+			"activeAt":    e.alert.ActiveAt.UTC().Format(time.RFC3339),
+			"value":       e.alert.Value,
+		},
+	}
+}
+
+// firingAlerts returns the firing alerts of a Client.GetAlerts result by
+// label fingerprint; pending alerts are left out.
+func firingAlerts(alerts interface{}) map[model.Fingerprint]v1.Alert {
+	result, _ := alerts.(v1.AlertsResult)
+	firing := make(map[model.Fingerprint]v1.Alert)
+	for _, alert := range result.Alerts {
+		if alert.State == v1.AlertStateFiring {
+			firing[alert.Labels.Fingerprint()] = alert
+		}
+	}
+	return firing
+}
+
+// diffAlerts returns the alerts of cur not firing in prev, then those of prev
+// no longer firing in cur, each sorted by labels.
+func diffAlerts(prev, cur map[model.Fingerprint]v1.Alert) []alertEvent {
+	var events []alertEvent
+	for _, alert := range sortedAlerts(cur) {
+		if _, ok := prev[alert.Labels.Fingerprint()]; !ok {
+			events = append(events, alertEvent{firing: true, alert: alert})
+		}
+	}
+	for _, alert := range sortedAlerts(prev) {
+		if _, ok := cur[alert.Labels.Fingerprint()]; !ok {
+			events = append(events, alertEvent{alert: alert})
+		}
+	}
+	return events
+}
+
+// sortedAlerts returns the alerts sorted by their labels.
+func sortedAlerts(alerts map[model.Fingerprint]v1.Alert) []v1.Alert {
+	out := make([]v1.Alert, 0, len(alerts))
+	for _, alert := range alerts {
+		out = append(out, alert)
+	}
+	slices.SortFunc(out, func(a, b v1.Alert) int {
+		return cmp.Compare(a.Labels.String(), b.Labels.String())
+	})
+	return out
+}
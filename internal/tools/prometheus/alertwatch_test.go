@@ -0,0 +1,237 @@
+package prometheus
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	mcpserver "github.com/mark3labs/mcp-go/server"
+
+	"github.com/giantswarm/mcp-prometheus/internal/server"
+)
+
+// recordingSender records the notifications sent to it, failing with err.
+type recordingSender struct {
+	sessions []string
+	methods  []string
+	params   []map[string]any
+	err      error
+}
+
+func (r *recordingSender) SendNotificationToSpecificClient(sessionID, method string, params map[string]any) error {
+	r.sessions = append(r.sessions, sessionID)
+	r.methods = append(r.methods, method)
+	r.params = append(r.params, params)
+	return r.err
+}
+
+func TestSubscribeAlerts(t *testing.T) {
+	// The server answers with each of these alert lists in turn.
+	responses := []string{
+		`[{"labels":{"alertname":"HighLatency","job":"api"},"annotations":{},"state":"firing","activeAt":"2024-01-01T00:00:00Z","value":"1"},
+		  {"labels":{"alertname":"DiskFull","instance":"a"},"annotations":{},"state":"pending","activeAt":"2024-01-01T00:00:00Z","value":"1"}]`,
+		`[{"labels":{"alertname":"DiskFull","instance":"a"},"annotations":{"summary":"Disk full"},"state":"firing","activeAt":"2024-01-01T00:00:00Z","value":"1"}]`,
+		`[{"labels":{"alertname":"DiskFull","instance":"a"},"annotations":{"summary":"Disk full"},"state":"firing","activeAt":"2024-01-01T00:00:00Z","value":"1"}]`,
+	}
+	var calls atomic.Int32
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/alerts" {
+			http.NotFound(w, r)
+			return
+		}
+		i := min(int(calls.Add(1))-1, len(responses)-1)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"status":"success","data":{"alerts":` + responses[i] + `}}`))
+	}))
+	defer mockServer.Close()
+
+	sc, err := server.NewServerContext(context.Background(),
+		server.WithPrometheusConfig(server.PrometheusConfig{URL: mockServer.URL}),
+		server.WithSlogLogger(discardLogger()),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create server context: %v", err)
+	}
+	defer func() { _ = sc.Shutdown() }()
+
+	client, err := NewClient(sc.PrometheusConfig(), sc.Logger())
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+
+	sender := &recordingSender{}
+	subs := newAlertSubscriptions(sender)
+	call := func(ctx context.Context, args map[string]any) (*mcp.CallToolResult, string) {
+		t.Helper()
+		request := mcp.CallToolRequest{Params: mcp.CallToolParams{Name: toolSubscribeAlerts, Arguments: args}}
+		result, err := subs.handle(ctx, request, client, sc)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return result, result.Content[0].(mcp.TextContent).Text
+	}
+
+	if result, text := call(context.Background(), map[string]any{}); !result.IsError || !strings.Contains(text, "needs a transport with sessions") {
+		t.Errorf("expected an error without a session, got: %s", text)
+	}
+
+	ctx := mcpserver.NewMCPServer("test", "1.0.0").WithContext(context.Background(), &capabilitySession{})
+	if result, text := call(ctx, map[string]any{"interval": "1s"}); !result.IsError || !strings.Contains(text, "at least 5s") {
+		t.Errorf("expected an interval error, got: %s", text)
+	}
+
+	result, text := call(ctx, map[string]any{"interval": "1m"})
+	if result.IsError {
+		t.Fatalf("expected success, got error: %s", text)
+	}
+	if !strings.Contains(text, "polling every 1m") || !strings.Contains(text, "1 alerts firing now:\n- {alertname=\"HighLatency\", job=\"api\"} since 2024-01-01T00:00:00Z\n") {
+		t.Errorf("unexpected output:\n%s", text)
+	}
+
+	// DiskFull started firing and HighLatency resolved.
+	w := subs.watch["test"]
+	subs.poll(ctx, sc, client, w)
+	if got := strings.Join(sender.methods, ","); got != "notifications/message,notifications/message,notifications/resources/updated" {
+		t.Fatalf("sent %s", got)
+	}
+	firing := sender.params[0]["data"].(map[string]any)
+	if firing["event"] != "firing" || firing["alertname"] != "DiskFull" || sender.params[0]["level"] != mcp.LoggingLevelWarning {
+		t.Errorf("first notification = %v", sender.params[0])
+	}
+	if resolved := sender.params[1]["data"].(map[string]any); resolved["event"] != "resolved" || resolved["alertname"] != "HighLatency" {
+		t.Errorf("second notification = %v", sender.params[1])
+	}
+	if sender.params[2]["uri"] != resourceAlerts || sender.sessions[2] != "test" {
+		t.Errorf("resource notification = %v to %s", sender.params[2], sender.sessions[2])
+	}
+
+	// Nothing changed: no notifications.
+	sender.methods = nil
+	subs.poll(ctx, sc, client, w)
+	if len(sender.methods) != 0 {
+		t.Errorf("expected no notifications, sent %v", sender.methods)
+	}
+
+	if _, text := call(ctx, map[string]any{"stop": "true"}); text != "Stopped the alert subscription." {
+		t.Errorf("stop returned %q", text)
+	}
+	if w.ctx.Err() == nil {
+		t.Error("expected the subscription context to be cancelled")
+	}
+	if _, text := call(ctx, map[string]any{"stop": "true"}); text != "No alert subscription to stop." {
+		t.Errorf("second stop returned %q", text)
+	}
+}
+
+func TestAlertSubscriptionEndsWithSession(t *testing.T) {
+	sc, err := server.NewServerContext(context.Background(), server.WithSlogLogger(discardLogger()))
+	if err != nil {
+		t.Fatalf("Failed to create server context: %v", err)
+	}
+	defer func() { _ = sc.Shutdown() }()
+
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"status":"success","data":{"alerts":[{"labels":{"alertname":"A"},"annotations":{},"state":"firing","activeAt":"2024-01-01T00:00:00Z","value":"1"}]}}`))
+	}))
+	defer mockServer.Close()
+	client, err := NewClient(server.PrometheusConfig{URL: mockServer.URL}, discardLogger())
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+
+	subs := newAlertSubscriptions(&recordingSender{err: mcpserver.ErrSessionNotFound})
+	w, _ := subs.start(sc, "gone", resourceAlerts, nil)
+	subs.poll(context.Background(), sc, client, w)
+	if _, ok := subs.watch["gone"]; ok || w.ctx.Err() == nil {
+		t.Error("expected the subscription of a closed session to end")
+	}
+
+	// Replacing a subscription cancels the previous one only.
+	first, _ := subs.start(sc, "s", resourceAlerts, nil)
+	second, _ := subs.start(sc, "s", resourceAlerts, nil)
+	if first.ctx.Err() == nil || second.ctx.Err() != nil || subs.watch["s"] != second {
+		t.Error("expected the second subscription to replace the first")
+	}
+
+	// The subscription is cancelled when its session unregisters, before
+	// any notification fails.
+	subs.stopScope(sessionScopePrefix + "s")
+	if _, ok := subs.watch["s"]; ok || second.ctx.Err() == nil {
+		t.Error("expected the subscription to end with its session")
+	}
+}
+
+func TestAlertSubscriptionLimit(t *testing.T) {
+	sc, err := server.NewServerContext(context.Background(), server.WithSlogLogger(discardLogger()))
+	if err != nil {
+		t.Fatalf("Failed to create server context: %v", err)
+	}
+	defer func() { _ = sc.Shutdown() }()
+
+	subs := newAlertSubscriptions(&recordingSender{})
+	for i := range maxAlertSubscriptions {
+		if _, err := subs.start(sc, fmt.Sprintf("s%d", i), resourceAlerts, nil); err != nil {
+			t.Fatalf("start: %v", err)
+		}
+	}
+	if _, err := subs.start(sc, "one-too-many", resourceAlerts, nil); err == nil {
+		t.Error("expected an error past the subscription limit")
+	}
+	// A subscribed session may still replace its subscription.
+	if _, err := subs.start(sc, "s0", resourceAlerts, nil); err != nil {
+		t.Errorf("replacing a subscription at the limit: %v", err)
+	}
+}
+
+func TestAlertsResourceURI(t *testing.T) {
+	for _, tt := range []struct {
+		params map[string]any
+		want   string
+	}{
+		{map[string]any{}, resourceAlerts},
+		{map[string]any{"interval": "1m", "org_id": "team-a"}, resourceAlerts + "?org_id=team-a"},
+		{map[string]any{"profile": "prod eu", "org_id": "a|b"}, resourceAlerts + "?org_id=a%7Cb&profile=prod%20eu"},
+	} {
+		if got := alertsResourceURI(tt.params); got != tt.want {
+			t.Errorf("alertsResourceURI(%v) = %q, want %q", tt.params, got, tt.want)
+		}
+	}
+}
+
+func TestAlertsResourceFollowsSubscription(t *testing.T) {
+	var orgIDs []string
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		orgIDs = append(orgIDs, r.Header.Get("X-Scope-OrgID"))
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"status":"success","data":{"alerts":[]}}`))
+	}))
+	defer mockServer.Close()
+
+	sc, err := server.NewServerContext(context.Background(),
+		server.WithPrometheusConfig(server.PrometheusConfig{URL: mockServer.URL}),
+		server.WithSlogLogger(discardLogger()),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create server context: %v", err)
+	}
+	defer func() { _ = sc.Shutdown() }()
+	s := mcpserver.NewMCPServer("test", "1.0.0")
+	if err := RegisterPrometheusTools(s, sc); err != nil {
+		t.Fatalf("RegisterPrometheusTools: %v", err)
+	}
+
+	for _, uri := range []string{resourceAlerts, alertsResourceURI(map[string]any{"org_id": "team-a"})} {
+		if text, ok := readResource(t, s, uri); !ok {
+			t.Fatalf("reading %s failed: %s", uri, text)
+		}
+	}
+	if len(orgIDs) != 2 || orgIDs[0] != "" || orgIDs[1] != "team-a" {
+		t.Errorf("alerts read with org IDs %q, want the default then team-a", orgIDs)
+	}
+}
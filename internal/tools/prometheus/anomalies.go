@@ -0,0 +1,231 @@
+package prometheus
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/prometheus/common/model"
+
+	"github.com/giantswarm/mcp-prometheus/internal/analysis"
+	"github.com/giantswarm/mcp-prometheus/internal/server"
+)
+
+// defaultAnomalyStep is the range query resolution used by analyze_anomalies
+// when no step is given.
+const defaultAnomalyStep = "1m"
+
+// seriesAnomaly is the peak deviation of one series in the comparison window.
+type seriesAnomaly struct {
+	Metric   model.Metric
+	Baseline analysis.Baseline
+	Peak     analysis.AnomalyEvent
+}
+
+// handleAnalyzeAnomalies handles the analyze_anomalies tool
+func handleAnalyzeAnomalies(ctx context.Context, request mcp.CallToolRequest, client *Client, sc *server.ServerContext) (*mcp.CallToolResult, error) {
+	params := extractParams(request)
+
+	query, ok := params["query"].(string)
+	if !ok || query == "" {
+		return &mcp.CallToolResult{
+			IsError: true,
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: contentTypeText,
+					Text: errQueryParameterRequired,
+				},
+			},
+		}, nil
+	}
+
+	window := map[string]string{}
+	for _, key := range []string{"baseline_start", "baseline_end", "comparison_start", "comparison_end"} {
+		v := getStringParam(params, key)
+		if v == "" {
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{
+					mcp.TextContent{
+						Type: contentTypeText,
+						Text: fmt.Sprintf("Error: %s parameter is required and must be a string", key),
+					},
+				},
+			}, nil
+		}
+		window[key] = v
+	}
+
+	step := getStringParam(params, "step")
+	if step == "" {
+		step = defaultAnomalyStep
+		if minStep := sc.QueryPolicy().MinStep; parseStep(step) < minStep {
+			step = model.Duration(minStep).String()
+		}
+	}
+	// Both windows must pass the policy and the cost guard; invalid times
+	// are reported by the queries themselves.
+	var costWarning string
+	for _, w := range [][2]string{{"baseline_start", "baseline_end"}, {"comparison_start", "comparison_end"}} {
+		start, end, err := parseFilterTimes(window[w[0]], window[w[1]])
+		if refused := checkQueryPolicy(sc, query, start, end, parseStep(step)); refused != nil {
+			return refused, nil
+		}
+		if steps := rangeSteps(start, end, step); err == nil && steps > 0 {
+			warning, refused := checkQueryCost(ctx, client, sc, query, start, end, steps)
+			if refused != nil {
+				return refused, nil
+			}
+			costWarning = cmp.Or(costWarning, warning)
+		}
+	}
+
+	threshold := analysis.DefaultZScoreThreshold
+	if v := getStringParam(params, "z_score_threshold"); v != "" {
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil || f <= 0 || math.IsInf(f, 0) {
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{
+					mcp.TextContent{
+						Type: contentTypeText,
+						Text: fmt.Sprintf("Error: invalid z_score_threshold %q: must be a positive number", v),
+					},
+				},
+			}, nil
+		}
+		threshold = f
+	}
+
+	sc.Logger().Debug("Analyzing anomalies", "query", query, "window", window, "step", step, "threshold", threshold)
+
+	baseline, err := client.ExecuteRangeQuery(ctx, query, window["baseline_start"], window["baseline_end"], step)
+	if err != nil {
+		sc.Logger().Error("Failed to execute baseline query", "error", err)
+		return &mcp.CallToolResult{
+			IsError: true,
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: contentTypeText,
+					Text: describeQueryError("Error executing baseline query", err),
+				},
+			},
+		}, nil
+	}
+	comparison, err := client.ExecuteRangeQuery(ctx, query, window["comparison_start"], window["comparison_end"], step)
+	if err != nil {
+		sc.Logger().Error("Failed to execute comparison query", "error", err)
+		return &mcp.CallToolResult{
+			IsError: true,
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: contentTypeText,
+					Text: describeQueryError("Error executing comparison query", err),
+				},
+			},
+		}, nil
+	}
+
+	baselineMatrix, ok1 := baseline.Result.(model.Matrix)
+	comparisonMatrix, ok2 := comparison.Result.(model.Matrix)
+	if !ok1 || !ok2 {
+		return &mcp.CallToolResult{
+			IsError: true,
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: contentTypeText,
+					Text: fmt.Sprintf("Error: expected matrix results, got %s and %s", baseline.ResultType, comparison.ResultType),
+				},
+			},
+		}, nil
+	}
+
+	anomalies, missing := findAnomalies(baselineMatrix, comparisonMatrix, threshold)
+
+	var b strings.Builder
+	if costWarning != "" {
+		b.WriteString(costWarning + "\n")
+	}
+	fmt.Fprintf(&b, "Anomaly analysis for: %s\n", query)
+	fmt.Fprintf(&b, "Baseline: %s to %s\n", window["baseline_start"], window["baseline_end"])
+	fmt.Fprintf(&b, "Comparison: %s to %s\n", window["comparison_start"], window["comparison_end"])
+	fmt.Fprintf(&b, "Threshold: |z| > %g (step %s)\n\n", threshold, step)
+
+	if len(anomalies) == 0 {
+		fmt.Fprintf(&b, "No anomalies found in %d series.\n", len(comparisonMatrix))
+	} else {
+		fmt.Fprintf(&b, "%d of %d series exceed the threshold:\n", len(anomalies), len(comparisonMatrix))
+		b.WriteString("series | peak_z | direction | peak_time | value | baseline_mean | baseline_stddev\n")
+		for _, a := range anomalies {
+			fmt.Fprintf(&b, "%s | %s | %s | %s | %g | %g | %g\n",
+				a.Metric, formatZScore(a.Peak.ZScore), a.Peak.Direction,
+				a.Peak.Timestamp.Time().UTC().Format(time.RFC3339), a.Peak.Value,
+				a.Baseline.Mean, a.Baseline.StdDev)
+		}
+	}
+	if missing > 0 {
+		fmt.Fprintf(&b, "\n%d series had no baseline data and were skipped.\n", missing)
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.TextContent{
+				Type: contentTypeText,
+				Text: b.String(),
+			},
+		},
+	}, nil
+}
+
+// findAnomalies matches comparison series to baseline series by label set and
+// returns those whose peak |z| exceeds threshold, largest first, along with
+// the number of comparison series that had no baseline.
+func findAnomalies(baseline, comparison model.Matrix, threshold float64) ([]seriesAnomaly, int) {
+	byFingerprint := make(map[model.Fingerprint][]model.SamplePair, len(baseline))
+	for _, s := range baseline {
+		byFingerprint[s.Metric.Fingerprint()] = s.Values
+	}
+
+	var anomalies []seriesAnomaly
+	missing := 0
+	for _, s := range comparison {
+		values, ok := byFingerprint[s.Metric.Fingerprint()]
+		if !ok {
+			missing++
+			continue
+		}
+		base, ok := analysis.NewBaseline(values)
+		if !ok {
+			missing++
+			continue
+		}
+		peak, ok := analysis.PeakEvent(analysis.ComputeZScore(values, s.Values))
+		if !ok || math.Abs(peak.ZScore) <= threshold {
+			continue
+		}
+		anomalies = append(anomalies, seriesAnomaly{Metric: s.Metric, Baseline: base, Peak: peak})
+	}
+
+	sort.SliceStable(anomalies, func(i, j int) bool {
+		return math.Abs(anomalies[i].Peak.ZScore) > math.Abs(anomalies[j].Peak.ZScore)
+	})
+	return anomalies, missing
+}
+
+// formatZScore renders a z-score with two decimals, or ±Inf for deviations
+// from a constant baseline.
+func formatZScore(z float64) string {
+	if math.IsInf(z, 0) {
+		if z > 0 {
+			return "+Inf"
+		}
+		return "-Inf"
+	}
+	return strconv.FormatFloat(z, 'f', 2, 64)
+}
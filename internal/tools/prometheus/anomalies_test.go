@@ -0,0 +1,147 @@
+package prometheus
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/giantswarm/mcp-prometheus/internal/server"
+)
+
+const (
+	anomalyBaselineStart   = "2024-01-15T09:00:00Z"
+	anomalyComparisonStart = "2024-01-15T10:00:00Z"
+)
+
+// anomalyMatrix builds a query_range matrix with one series per instance.
+func anomalyMatrix(start float64, series map[string][]string) map[string]any {
+	var result []any
+	for instance, values := range series {
+		pairs := make([]any, len(values))
+		for i, v := range values {
+			pairs[i] = []any{start + float64(i*60), v}
+		}
+		result = append(result, map[string]any{
+			"metric": map[string]string{"__name__": "http_requests_total", "instance": instance},
+			"values": pairs,
+		})
+	}
+	return map[string]any{respKeyResultType: "matrix", respKeyResult: result}
+}
+
+func TestHandleAnalyzeAnomalies(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/query_range" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_ = r.ParseForm()
+		var data map[string]any
+		if strings.HasPrefix(r.Form.Get("start"), "1705309200") { // baseline_start
+			data = anomalyMatrix(1705309200, map[string][]string{
+				"a": {"8", "12", "8", "12"},
+				"b": {"8", "12", "8", "12"},
+			})
+		} else {
+			data = anomalyMatrix(1705312800, map[string][]string{
+				"a": {"10", "18", "11"}, // z=4 spike at the second sample
+				"b": {"10", "11", "9"},  // within 1 stddev
+				"c": {"100"},            // no baseline
+			})
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{respKeyStatus: respValSuccess, respKeyData: data})
+	}))
+	defer mockServer.Close()
+
+	ctx := context.Background()
+	sc, err := server.NewServerContext(ctx,
+		server.WithPrometheusConfig(server.PrometheusConfig{URL: mockServer.URL}),
+		server.WithSlogLogger(discardLogger()),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create server context: %v", err)
+	}
+	defer func() { _ = sc.Shutdown() }()
+
+	client, err := NewClient(sc.PrometheusConfig(), sc.Logger())
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+
+	request := mcp.CallToolRequest{Params: mcp.CallToolParams{
+		Name: "analyze_anomalies",
+		Arguments: map[string]any{
+			paramKeyQuery:      "http_requests_total",
+			"baseline_start":   anomalyBaselineStart,
+			"baseline_end":     "2024-01-15T09:03:00Z",
+			"comparison_start": anomalyComparisonStart,
+			"comparison_end":   "2024-01-15T10:02:00Z",
+		},
+	}}
+	result, err := handleAnalyzeAnomalies(ctx, request, client, sc)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.IsError {
+		t.Fatalf("expected success, got error: %v", result.Content)
+	}
+	text := result.Content[0].(mcp.TextContent).Text
+
+	for _, want := range []string{
+		"1 of 3 series exceed the threshold",
+		`instance="a"} | 4.00 | spike | 2024-01-15T10:01:00Z | 18 | 10 | 2`,
+		"1 series had no baseline data",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("expected output to contain %q, got:\n%s", want, text)
+		}
+	}
+	if strings.Contains(text, `instance="b"`) {
+		t.Errorf("expected series b to stay below the threshold, got:\n%s", text)
+	}
+}
+
+func TestHandleAnalyzeAnomaliesInvalidParams(t *testing.T) {
+	sc, err := server.NewServerContext(context.Background(),
+		server.WithPrometheusConfig(server.PrometheusConfig{URL: "http://localhost:9090"}),
+		server.WithSlogLogger(discardLogger()),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create server context: %v", err)
+	}
+	defer func() { _ = sc.Shutdown() }()
+
+	valid := map[string]any{
+		paramKeyQuery:      "up",
+		"baseline_start":   anomalyBaselineStart,
+		"baseline_end":     anomalyComparisonStart,
+		"comparison_start": anomalyComparisonStart,
+		"comparison_end":   "2024-01-15T11:00:00Z",
+	}
+	for name, mutate := range map[string]func(map[string]any){
+		"missing query":          func(m map[string]any) { delete(m, paramKeyQuery) },
+		"missing baseline_start": func(m map[string]any) { delete(m, "baseline_start") },
+		"bad threshold":          func(m map[string]any) { m["z_score_threshold"] = "-1" },
+	} {
+		t.Run(name, func(t *testing.T) {
+			args := map[string]any{}
+			for k, v := range valid {
+				args[k] = v
+			}
+			mutate(args)
+			result, err := handleAnalyzeAnomalies(context.Background(), mcp.CallToolRequest{Params: mcp.CallToolParams{Arguments: args}}, nil, sc)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !result.IsError {
+				t.Error("expected IsError=true")
+			}
+		})
+	}
+}
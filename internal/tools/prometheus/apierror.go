@@ -0,0 +1,87 @@
+package prometheus
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	v1 "github.com/prometheus/client_golang/api/prometheus/v1"
+)
+
+// PrometheusAPIError is the error envelope returned by the Prometheus HTTP
+// API, e.g. {"status":"error","errorType":"bad_data","error":"..."}.
+//
+// The message is held in Message rather than a field named Error, which
+// would clash with the error interface method.
+type PrometheusAPIError struct {
+	// StatusCode is the HTTP status of the response, or 0 when unknown.
+	StatusCode int    `json:"-"`
+	Status     string `json:"status"`
+	ErrorType  string `json:"errorType"`
+	Message    string `json:"error"`
+}
+
+func (e *PrometheusAPIError) Error() string {
+	return fmt.Sprintf("%s: %s", e.ErrorType, e.Message)
+}
+
+// toAPIError converts an error returned by the client_golang API into a
+// *PrometheusAPIError when it carries a Prometheus error envelope. Other
+// errors are returned unchanged.
+func toAPIError(err error) error {
+	var apiErr *v1.Error
+	if !errors.As(err, &apiErr) {
+		return err
+	}
+
+	// For status codes client_golang does not decode itself (e.g. 500), the
+	// raw body is kept in Detail and may still hold the JSON envelope.
+	if decoded, ok := decodeAPIError(0, []byte(apiErr.Detail)); ok {
+		return decoded
+	}
+	return &PrometheusAPIError{Status: "error", ErrorType: string(apiErr.Type), Message: apiErr.Msg}
+}
+
+// decodeAPIError parses body as a Prometheus error envelope.
+func decodeAPIError(statusCode int, body []byte) (*PrometheusAPIError, bool) {
+	var apiErr PrometheusAPIError
+	if err := json.Unmarshal(body, &apiErr); err != nil || apiErr.Status != "error" || apiErr.Message == "" {
+		return nil, false
+	}
+	apiErr.StatusCode = statusCode
+	return &apiErr, true
+}
+
+// httpStatusError returns the error for a non-2xx response read directly
+// with the HTTP client: the decoded envelope when body holds one, a generic
+// status error otherwise.
+func httpStatusError(statusCode int, body []byte) error {
+	if apiErr, ok := decodeAPIError(statusCode, body); ok {
+		return apiErr
+	}
+	return fmt.Errorf("server returned HTTP %d: %s", statusCode, strings.TrimSpace(string(body)))
+}
+
+// parseErrorPattern matches PromQL parser messages such as
+// `1:17: parse error: unexpected "."`.
+var parseErrorPattern = regexp.MustCompile(`^(\d+):(\d+): parse error: (.*)$`)
+
+// describeQueryError renders err for a query tool result. PromQL parse
+// errors become `Query parse error: <reason> at position <column>`; other
+// errors are prefixed with prefix.
+func describeQueryError(prefix string, err error) string {
+	var apiErr *PrometheusAPIError
+	if errors.As(err, &apiErr) && apiErr.ErrorType == string(v1.ErrBadData) {
+		if m := parseErrorPattern.FindStringSubmatch(apiErr.Message); m != nil {
+			line, _ := strconv.Atoi(m[1])
+			if line > 1 {
+				return fmt.Sprintf("Query parse error: %s at line %d, position %s", m[3], line, m[2])
+			}
+			return fmt.Sprintf("Query parse error: %s at position %s", m[3], m[2])
+		}
+	}
+	return fmt.Sprintf("%s: %v", prefix, err)
+}
@@ -0,0 +1,152 @@
+package prometheus
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/giantswarm/mcp-prometheus/internal/server"
+)
+
+func TestPrometheusAPIErrorExtraction(t *testing.T) {
+	tests := []struct {
+		name          string
+		status        int
+		body          string
+		wantErrorType string
+		wantMessage   string
+		wantDescribed string
+	}{
+		{
+			name:          "400 parse error",
+			status:        http.StatusBadRequest,
+			body:          `{"status":"error","errorType":"bad_data","error":"1:17: parse error: unexpected \".\""}`,
+			wantErrorType: "bad_data",
+			wantMessage:   `1:17: parse error: unexpected "."`,
+			wantDescribed: `Query parse error: unexpected "." at position 17`,
+		},
+		{
+			name:          "400 multi-line parse error",
+			status:        http.StatusBadRequest,
+			body:          `{"status":"error","errorType":"bad_data","error":"2:5: parse error: unclosed left parenthesis"}`,
+			wantErrorType: "bad_data",
+			wantMessage:   "2:5: parse error: unclosed left parenthesis",
+			wantDescribed: "Query parse error: unclosed left parenthesis at line 2, position 5",
+		},
+		{
+			name:          "422 execution error",
+			status:        http.StatusUnprocessableEntity,
+			body:          `{"status":"error","errorType":"execution","error":"query processing would load too many samples into memory"}`,
+			wantErrorType: "execution",
+			wantMessage:   "query processing would load too many samples into memory",
+			wantDescribed: "Error executing query: failed to execute query: execution: query processing would load too many samples into memory",
+		},
+		{
+			name:          "503 unavailable",
+			status:        http.StatusServiceUnavailable,
+			body:          `{"status":"error","errorType":"unavailable","error":"TSDB not ready"}`,
+			wantErrorType: "unavailable",
+			wantMessage:   "TSDB not ready",
+			wantDescribed: "Error executing query: failed to execute query: unavailable: TSDB not ready",
+		},
+		{
+			name:          "500 with envelope",
+			status:        http.StatusInternalServerError,
+			body:          `{"status":"error","errorType":"internal","error":"something broke"}`,
+			wantErrorType: "internal",
+			wantMessage:   "something broke",
+			wantDescribed: "Error executing query: failed to execute query: internal: something broke",
+		},
+		{
+			name:          "502 without envelope",
+			status:        http.StatusBadGateway,
+			body:          `<html>bad gateway</html>`,
+			wantErrorType: "server_error",
+			wantMessage:   "server error: 502",
+			wantDescribed: "Error executing query: failed to execute query: server_error: server error: 502",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer mockServer.Close()
+
+			client, err := NewClient(server.PrometheusConfig{URL: mockServer.URL}, discardLogger())
+			if err != nil {
+				t.Fatalf("NewClient: %v", err)
+			}
+
+			_, err = client.ExecuteQuery(context.Background(), "up.", "")
+			var apiErr *PrometheusAPIError
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("expected *PrometheusAPIError, got %T: %v", err, err)
+			}
+			if apiErr.ErrorType != tt.wantErrorType || apiErr.Message != tt.wantMessage {
+				t.Errorf("got errorType %q, error %q; want %q, %q", apiErr.ErrorType, apiErr.Message, tt.wantErrorType, tt.wantMessage)
+			}
+			if got := describeQueryError("Error executing query", err); got != tt.wantDescribed {
+				t.Errorf("describeQueryError() = %q, want %q", got, tt.wantDescribed)
+			}
+		})
+	}
+}
+
+func TestHTTPStatusError(t *testing.T) {
+	err := httpStatusError(http.StatusBadRequest, []byte(`{"status":"error","errorType":"bad_data","error":"invalid parameter"}`))
+	var apiErr *PrometheusAPIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusBadRequest || apiErr.Message != "invalid parameter" {
+		t.Errorf("expected decoded API error with status 400, got %#v", err)
+	}
+
+	err = httpStatusError(http.StatusNotFound, []byte("404 page not found\n"))
+	if errors.As(err, &apiErr) {
+		t.Errorf("expected a plain error for a non-JSON body, got %#v", err)
+	}
+	if want := "server returned HTTP 404: 404 page not found"; err.Error() != want {
+		t.Errorf("error = %q, want %q", err.Error(), want)
+	}
+}
+
+func TestHandleExecuteQueryParseError(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"status":"error","errorType":"bad_data","error":"1:17: parse error: unexpected \".\""}`))
+	}))
+	defer mockServer.Close()
+
+	sc, err := server.NewServerContext(context.Background(),
+		server.WithPrometheusConfig(server.PrometheusConfig{URL: mockServer.URL}),
+		server.WithSlogLogger(discardLogger()),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create server context: %v", err)
+	}
+	defer func() { _ = sc.Shutdown() }()
+
+	client, err := NewClient(sc.PrometheusConfig(), discardLogger())
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+
+	var req mcp.CallToolRequest
+	req.Params.Arguments = map[string]any{"query": "rate(http_total.[5m])"}
+	result, err := handleExecuteQuery(context.Background(), req, client, sc)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	text := result.Content[0].(mcp.TextContent).Text
+	if !result.IsError || !strings.Contains(text, `Query parse error: unexpected "." at position 17`) {
+		t.Errorf("unexpected result: %s", text)
+	}
+}
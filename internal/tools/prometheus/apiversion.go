@@ -0,0 +1,210 @@
+package prometheus
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// apiFeature is a set of Prometheus API features, one bit per feature.
+type apiFeature uint32
+
+// API features that older Prometheus 2.x releases lack.
+const (
+	featureExemplars apiFeature = 1 << iota // /api/v1/query_exemplars
+	featureLimit                            // limit parameter of query and metadata endpoints
+
+	allFeatures = featureExemplars | featureLimit
+)
+
+// apiFeatureVersions lists the name of each feature and the Prometheus
+// release that introduced it.
+var apiFeatureVersions = []struct {
+	feature      apiFeature
+	name         string
+	major, minor int
+}{
+	{featureExemplars, "exemplar queries", 2, 26},
+	{featureLimit, "the limit parameter", 2, 33},
+}
+
+// FeatureNotSupportedError reports an API feature the Prometheus server is
+// too old for.
+type FeatureNotSupportedError struct {
+	Feature       string
+	MinVersion    string
+	ServerVersion string
+}
+
+func (e *FeatureNotSupportedError) Error() string {
+	return fmt.Sprintf("%s requires Prometheus %s or later, but the server runs %s", e.Feature, e.MinVersion, e.ServerVersion)
+}
+
+// parseMajorMinor parses the major and minor version of "2.45.0",
+// "v3.1.0-rc.0" and similar.
+func parseMajorMinor(version string) (major, minor int, ok bool) {
+	parts := strings.SplitN(strings.TrimPrefix(version, "v"), ".", 3)
+	if len(parts) < 2 {
+		return 0, 0, false
+	}
+	major, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, 0, false
+	}
+	digits := strings.IndexFunc(parts[1], func(r rune) bool { return r < '0' || r > '9' })
+	if digits < 0 {
+		digits = len(parts[1])
+	}
+	minor, err = strconv.Atoi(parts[1][:digits])
+	if err != nil {
+		return 0, 0, false
+	}
+	return major, minor, true
+}
+
+// supportedFeaturesFor returns the features a server reporting version
+// supports. Only Prometheus 2.x is gated: Prometheus 3 supports every
+// feature, and compatible servers (Mimir, Cortex, Thanos) either report an
+// application name or version numbers unrelated to Prometheus releases.
+func supportedFeaturesFor(version, application string) apiFeature {
+	major, minor, ok := parseMajorMinor(version)
+	if application != "" || !ok || major != 2 {
+		return allFeatures
+	}
+	var features apiFeature
+	for _, f := range apiFeatureVersions {
+		if minor >= f.minor {
+			features |= f.feature
+		}
+	}
+	return features
+}
+
+// negotiationRetryDelay is how long a failed API version negotiation is
+// not repeated.
+const negotiationRetryDelay = time.Minute
+
+// NegotiateAPIVersion reads the server version from
+// /api/v1/status/buildinfo and records the API features it supports. Until
+// the version has been read every feature is assumed to be supported, so a
+// server without the build info endpoint is not locked out; the error is
+// returned for logging. A successful negotiation is kept for the lifetime of
+// the client. A failed one is retried by the first call after
+// negotiationRetryDelay, or by the next call if ctx was cancelled. The
+// request is sent without holding the client's feature lock; concurrent
+// callers wait for it instead of sending their own.
+func (c *Client) NegotiateAPIVersion(ctx context.Context) error {
+	if c.config.DisableAPIVersionNegotiation {
+		return nil
+	}
+	if c.httpClient == nil {
+		return fmt.Errorf("prometheus client not initialized")
+	}
+
+	c.featuresMu.Lock()
+	if c.featuresNegotiated || time.Now().Before(c.negotiationRetryAt) {
+		c.featuresMu.Unlock()
+		return nil
+	}
+	if wait := c.negotiating; wait != nil {
+		c.featuresMu.Unlock()
+		select {
+		case <-wait:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		return c.NegotiateAPIVersion(ctx)
+	}
+	done := make(chan struct{})
+	c.negotiating = done
+	c.featuresMu.Unlock()
+
+	version, application, err := c.fetchBuildInfo(ctx)
+
+	c.featuresMu.Lock()
+	defer c.featuresMu.Unlock()
+	c.negotiating = nil
+	close(done)
+	if err != nil {
+		if ctx.Err() == nil {
+			c.negotiationRetryAt = time.Now().Add(negotiationRetryDelay)
+		}
+		return err
+	}
+	c.featuresNegotiated = true
+	c.serverVersion = version
+	c.supportedFeatures = supportedFeaturesFor(version, application)
+	c.logger.Debug("Negotiated Prometheus API version", "version", version, "application", application, "features", fmt.Sprintf("%b", c.supportedFeatures))
+	return nil
+}
+
+// fetchBuildInfo returns the version and application name the server
+// reports in /api/v1/status/buildinfo.
+func (c *Client) fetchBuildInfo(ctx context.Context) (version, application string, err error) {
+	reqCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	// The build info is fetched directly because v1.API.Buildinfo drops the
+	// application field that Mimir and Cortex report.
+	req, err := http.NewRequestWithContext(reqCtx, http.MethodGet, c.baseURL+"/api/v1/status/buildinfo", nil)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to create build info request: %w", err)
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to get build info: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return "", "", fmt.Errorf("failed to get build info: %w", httpStatusError(resp.StatusCode, body))
+	}
+
+	var envelope struct {
+		Data struct {
+			Version     string `json:"version"`
+			Application string `json:"application"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&envelope); err != nil {
+		return "", "", fmt.Errorf("failed to decode build info: %w", err)
+	}
+	return envelope.Data.Version, envelope.Data.Application, nil
+}
+
+// Supports reports whether the server supports f. Before negotiation, and
+// when negotiation is disabled or has failed, every feature is supported.
+func (c *Client) Supports(f apiFeature) bool {
+	c.featuresMu.Lock()
+	defer c.featuresMu.Unlock()
+	return !c.featuresNegotiated || c.supportedFeatures&f == f
+}
+
+// requireFeature negotiates the API version on first use and returns a
+// FeatureNotSupportedError when the server lacks f.
+func (c *Client) requireFeature(ctx context.Context, f apiFeature) error {
+	if err := c.NegotiateAPIVersion(ctx); err != nil {
+		c.logger.Debug("API version negotiation failed; assuming all features are supported", "error", err)
+	}
+	if c.Supports(f) {
+		return nil
+	}
+	for _, v := range apiFeatureVersions {
+		if v.feature == f {
+			c.featuresMu.Lock()
+			defer c.featuresMu.Unlock()
+			return &FeatureNotSupportedError{
+				Feature:       v.name,
+				MinVersion:    fmt.Sprintf("%d.%d", v.major, v.minor),
+				ServerVersion: c.serverVersion,
+			}
+		}
+	}
+	return nil
+}
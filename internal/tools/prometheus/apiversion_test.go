@@ -0,0 +1,273 @@
+package prometheus
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/giantswarm/mcp-prometheus/internal/server"
+)
+
+func TestSupportedFeaturesFor(t *testing.T) {
+	tests := []struct {
+		version     string
+		application string
+		want        apiFeature
+	}{
+		{version: "2.19.0", want: 0},
+		{version: "2.26.0", want: featureExemplars},
+		{version: "2.32.1", want: featureExemplars},
+		{version: "2.33.0", want: featureExemplars | featureLimit},
+		{version: "v2.26.0-rc.0", want: featureExemplars},
+		{version: "3.0.0", want: allFeatures},
+		{version: "2.10.0", application: "Mimir", want: allFeatures},
+		{version: "unknown", want: allFeatures},
+		{version: "", want: allFeatures},
+	}
+	for _, tt := range tests {
+		t.Run(tt.version+"/"+tt.application, func(t *testing.T) {
+			if got := supportedFeaturesFor(tt.version, tt.application); got != tt.want {
+				t.Errorf("supportedFeaturesFor(%q, %q) = %b, want %b", tt.version, tt.application, got, tt.want)
+			}
+		})
+	}
+}
+
+// newBuildInfoServer serves build info reporting version and counts the
+// build info requests.
+func newBuildInfoServer(t *testing.T, version string, requests *atomic.Int32) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/v1/status/buildinfo":
+			requests.Add(1)
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				respKeyStatus: respValSuccess,
+				respKeyData:   map[string]string{"version": version},
+			})
+		case "/api/v1/query_exemplars":
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				respKeyStatus: respValSuccess,
+				respKeyData:   []any{},
+			})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestQueryExemplarsFeatureGate(t *testing.T) {
+	tests := []struct {
+		name        string
+		version     string
+		opts        []server.ServerOption
+		wantErr     bool
+		wantLookups int32
+	}{
+		{name: "too old", version: "2.25.0", wantErr: true, wantLookups: 1},
+		{name: "supported", version: "2.26.0", wantLookups: 1},
+		{name: "prometheus 3", version: "3.1.0", wantLookups: 1},
+		{name: "negotiation disabled", version: "2.25.0", opts: []server.ServerOption{server.WithAPIVersionNegotiation(false)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests atomic.Int32
+			mockServer := newBuildInfoServer(t, tt.version, &requests)
+
+			opts := append([]server.ServerOption{
+				server.WithPrometheusConfig(server.PrometheusConfig{URL: mockServer.URL}),
+				server.WithSlogLogger(discardLogger()),
+			}, tt.opts...)
+			sc, err := server.NewServerContext(context.Background(), opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			client, err := NewClient(sc.PrometheusConfig(), sc.Logger())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// Negotiation runs once, so the second call reuses the result.
+			for range 2 {
+				_, err = client.QueryExemplars(context.Background(), "up", "", "")
+			}
+			var featureErr *FeatureNotSupportedError
+			if got := errors.As(err, &featureErr); got != tt.wantErr {
+				t.Fatalf("FeatureNotSupportedError = %v, want %v (err: %v)", got, tt.wantErr, err)
+			}
+			if tt.wantErr && (featureErr.MinVersion != "2.26" || featureErr.ServerVersion != tt.version) {
+				t.Errorf("unexpected error: %v", featureErr)
+			}
+			if got := requests.Load(); got != tt.wantLookups {
+				t.Errorf("build info requests = %d, want %d", got, tt.wantLookups)
+			}
+		})
+	}
+}
+
+func TestLimitFeatureGate(t *testing.T) {
+	var requests atomic.Int32
+	mockServer := newBuildInfoServer(t, "2.30.0", &requests)
+
+	sc, err := server.NewServerContext(context.Background(),
+		server.WithPrometheusConfig(server.PrometheusConfig{URL: mockServer.URL}),
+		server.WithSlogLogger(discardLogger()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, err := NewClient(sc.PrometheusConfig(), sc.Logger())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = client.ListLabelNames(context.Background(), LabelOptions{Limit: "10"})
+	var featureErr *FeatureNotSupportedError
+	if !errors.As(err, &featureErr) || featureErr.MinVersion != "2.33" {
+		t.Fatalf("expected FeatureNotSupportedError for 2.33, got %v", err)
+	}
+	if client.Supports(featureLimit) || !client.Supports(featureExemplars) {
+		t.Errorf("unexpected features after negotiation: %b", client.supportedFeatures)
+	}
+}
+
+func TestNegotiateAPIVersionFailureAssumesAllFeatures(t *testing.T) {
+	mockServer := httptest.NewServer(http.NotFoundHandler())
+	defer mockServer.Close()
+
+	sc, err := server.NewServerContext(context.Background(),
+		server.WithPrometheusConfig(server.PrometheusConfig{URL: mockServer.URL}),
+		server.WithSlogLogger(discardLogger()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, err := NewClient(sc.PrometheusConfig(), sc.Logger())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := client.NegotiateAPIVersion(context.Background()); err == nil {
+		t.Error("expected negotiation error")
+	}
+	if !client.Supports(featureExemplars) || !client.Supports(featureLimit) {
+		t.Error("expected all features after failed negotiation")
+	}
+}
+
+func TestNegotiateAPIVersionRetriedAfterCancellation(t *testing.T) {
+	mockServer := buildInfoServer("2.25.0", 0)
+	defer mockServer.Close()
+
+	sc, err := server.NewServerContext(context.Background(),
+		server.WithPrometheusConfig(server.PrometheusConfig{URL: mockServer.URL}),
+		server.WithSlogLogger(discardLogger()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, err := NewClient(sc.PrometheusConfig(), sc.Logger())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := client.NegotiateAPIVersion(ctx); err == nil {
+		t.Fatal("expected negotiation error with a cancelled context")
+	}
+	if err := client.NegotiateAPIVersion(context.Background()); err != nil {
+		t.Fatalf("NegotiateAPIVersion: %v", err)
+	}
+	if client.Supports(featureExemplars) {
+		t.Error("expected exemplars to be unsupported on Prometheus 2.25 after negotiation was retried")
+	}
+}
+
+func TestNegotiateAPIVersionFailureBackoff(t *testing.T) {
+	var requests atomic.Int32
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		http.NotFound(w, r)
+	}))
+	defer mockServer.Close()
+
+	sc, err := server.NewServerContext(context.Background(),
+		server.WithPrometheusConfig(server.PrometheusConfig{URL: mockServer.URL}),
+		server.WithSlogLogger(discardLogger()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, err := NewClient(sc.PrometheusConfig(), sc.Logger())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := client.NegotiateAPIVersion(context.Background()); err == nil {
+		t.Fatal("expected negotiation error")
+	}
+	if err := client.NegotiateAPIVersion(context.Background()); err != nil || requests.Load() != 1 {
+		t.Errorf("second call: err %v after %d requests; want no request during the backoff", err, requests.Load())
+	}
+
+	client.featuresMu.Lock()
+	client.negotiationRetryAt = time.Now().Add(-time.Second)
+	client.featuresMu.Unlock()
+	if err := client.NegotiateAPIVersion(context.Background()); err == nil || requests.Load() != 2 {
+		t.Errorf("call after the backoff: err %v after %d requests; want a new request", err, requests.Load())
+	}
+}
+
+func TestNegotiateAPIVersionConcurrent(t *testing.T) {
+	var requests atomic.Int32
+	release := make(chan struct{})
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		<-release
+		_ = json.NewEncoder(w).Encode(map[string]any{respKeyStatus: respValSuccess, respKeyData: map[string]string{"version": "2.25.0"}})
+	}))
+	defer mockServer.Close()
+
+	sc, err := server.NewServerContext(context.Background(),
+		server.WithPrometheusConfig(server.PrometheusConfig{URL: mockServer.URL}),
+		server.WithSlogLogger(discardLogger()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, err := NewClient(sc.PrometheusConfig(), sc.Logger())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	for range 5 {
+		wg.Go(func() { _ = client.NegotiateAPIVersion(context.Background()) })
+	}
+	for requests.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	// The feature lock is free while the request is in flight.
+	if !client.Supports(featureExemplars) {
+		t.Error("expected all features while negotiation is in flight")
+	}
+	close(release)
+	wg.Wait()
+
+	if n := requests.Load(); n != 1 {
+		t.Errorf("sent %d build info requests, want 1", n)
+	}
+	if client.Supports(featureExemplars) {
+		t.Error("expected exemplars to be unsupported on Prometheus 2.25")
+	}
+}
@@ -0,0 +1,62 @@
+package prometheus
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"golang.org/x/oauth2"
+
+	"github.com/giantswarm/mcp-prometheus/internal/server"
+)
+
+// azureMonitorPrometheusScope is the scope of access tokens accepted by the
+// query endpoint of Azure Monitor workspaces.
+const azureMonitorPrometheusScope = "https://prometheus.monitor.azure.com/.default"
+
+// azureTokenSource adapts an Azure credential to oauth2.TokenSource so it
+// plugs into the same oauth2.Transport as the other token based modes.
+type azureTokenSource struct {
+	credential azcore.TokenCredential
+}
+
+func (s azureTokenSource) Token() (*oauth2.Token, error) {
+	token, err := s.credential.GetToken(context.Background(), policy.TokenRequestOptions{Scopes: []string{azureMonitorPrometheusScope}})
+	if err != nil {
+		return nil, fmt.Errorf("acquire Azure AD token: %w", err)
+	}
+	return &oauth2.Token{AccessToken: token.Token, TokenType: "Bearer", Expiry: token.ExpiresOn}, nil
+}
+
+// newAzureCredential returns the credential selected by config: a service
+// principal when a client secret is set, otherwise the managed identity of
+// the host (user-assigned when a client ID is set).
+func newAzureCredential(config server.AzureConfig) (azcore.TokenCredential, error) {
+	if config.ClientSecret != "" {
+		if config.TenantID == "" || config.ClientID == "" {
+			return nil, fmt.Errorf("azure auth with a client secret needs a tenant ID and client ID")
+		}
+		return azidentity.NewClientSecretCredential(config.TenantID, config.ClientID, config.ClientSecret, nil)
+	}
+	options := &azidentity.ManagedIdentityCredentialOptions{}
+	if config.ClientID != "" {
+		options.ID = azidentity.ClientID(config.ClientID)
+	}
+	return azidentity.NewManagedIdentityCredential(options)
+}
+
+// azureTokenSourceFor returns the cached token source for the Azure identity
+// of config. The Azure credentials cache tokens themselves; ReuseTokenSource
+// avoids asking them on every request.
+func azureTokenSourceFor(config server.PrometheusConfig) (oauth2.TokenSource, error) {
+	c := config.Azure
+	return cachedTokenSource(func() (oauth2.TokenSource, error) {
+		credential, err := newAzureCredential(c)
+		if err != nil {
+			return nil, fmt.Errorf("create Azure credential: %w", err)
+		}
+		return oauth2.ReuseTokenSource(nil, azureTokenSource{credential: credential}), nil
+	}, server.AuthModeAzure, c.TenantID, c.ClientID, c.ClientSecret)
+}
@@ -0,0 +1,79 @@
+package prometheus
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"golang.org/x/oauth2"
+
+	"github.com/giantswarm/mcp-prometheus/internal/server"
+)
+
+// fakeAzureCredential hands out a fixed token and records requested scopes.
+type fakeAzureCredential struct {
+	token  string
+	err    error
+	scopes []string
+}
+
+func (f *fakeAzureCredential) GetToken(_ context.Context, options policy.TokenRequestOptions) (azcore.AccessToken, error) {
+	f.scopes = options.Scopes
+	return azcore.AccessToken{Token: f.token, ExpiresOn: time.Now().Add(time.Hour)}, f.err
+}
+
+func TestAzureTokenSource(t *testing.T) {
+	credential := &fakeAzureCredential{token: "eyJ.azure"}
+	prometheus := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer eyJ.azure" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		tlsQueryHandler(w, r)
+	}))
+	defer prometheus.Close()
+
+	httpClient := &http.Client{Transport: &oauth2.Transport{Source: azureTokenSource{credential: credential}, Base: http.DefaultTransport}}
+	resp, err := httpClient.Get(prometheus.URL + "/api/v1/query?query=up")
+	if err != nil {
+		t.Fatalf("request: %v", err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("status = %d, want the token to be accepted", resp.StatusCode)
+	}
+	if !slices.Equal(credential.scopes, []string{azureMonitorPrometheusScope}) {
+		t.Errorf("scopes = %q", credential.scopes)
+	}
+
+	credential.err = errors.New("no managed identity endpoint")
+	if _, err := (azureTokenSource{credential: credential}).Token(); err == nil {
+		t.Error("expected the credential error")
+	}
+}
+
+func TestNewClientAzureAuth(t *testing.T) {
+	resetTokenSources(t)
+
+	for name, azure := range map[string]server.AzureConfig{
+		"service principal":                {TenantID: "tenant", ClientID: "client", ClientSecret: "secret"},
+		"system-assigned managed identity": {},
+		"user-assigned managed identity":   {ClientID: "client"},
+	} {
+		config := server.PrometheusConfig{URL: "https://workspace.westeurope.prometheus.monitor.azure.com", AuthMode: server.AuthModeAzure, Azure: azure}
+		if _, err := NewClient(config, discardLogger()); err != nil {
+			t.Errorf("%s: NewClient: %v", name, err)
+		}
+	}
+
+	config := server.PrometheusConfig{URL: "https://workspace.westeurope.prometheus.monitor.azure.com", AuthMode: server.AuthModeAzure, Azure: server.AzureConfig{ClientSecret: "secret"}}
+	if _, err := NewClient(config, discardLogger()); err == nil {
+		t.Error("expected an error for a client secret without tenant and client ID")
+	}
+}
@@ -0,0 +1,64 @@
+package prometheus
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// ttlCache holds values fetched from Prometheus for a fixed time, keyed by
+// string. It backs the argument completions and the server resources, which
+// clients request far more often than the underlying data changes.
+type ttlCache[T any] struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	now     func() time.Time
+	entries map[string]ttlEntry[T]
+}
+
+type ttlEntry[T any] struct {
+	value   T
+	expires time.Time
+}
+
+func newTTLCache[T any](ttl time.Duration) *ttlCache[T] {
+	return &ttlCache[T]{ttl: ttl, now: time.Now, entries: make(map[string]ttlEntry[T])}
+}
+
+// get returns the value cached under key, calling fetch when there is none
+// or it has expired. Errors are not cached.
+func (c *ttlCache[T]) get(ctx context.Context, key string, fetch func(context.Context) (T, error)) (T, error) {
+	c.mu.Lock()
+	if e, ok := c.entries[key]; ok && c.now().Before(e.expires) {
+		c.mu.Unlock()
+		return e.value, nil
+	}
+	c.mu.Unlock()
+
+	value, err := fetch(ctx)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.now()
+	for k, e := range c.entries {
+		if !now.Before(e.expires) {
+			delete(c.entries, k)
+		}
+	}
+	c.entries[key] = ttlEntry[T]{value: value, expires: now.Add(c.ttl)}
+	return value, nil
+}
+
+// clientScopedKey qualifies key with the server, tenant and credentials of
+// client, so values fetched as one tenant are never served to another.
+func clientScopedKey(client *Client, key string) string {
+	id, err := clientCacheKey(client.config)
+	if err != nil {
+		id = client.baseURL + "\x00" + client.config.OrgID
+	}
+	return id + "\x00" + key
+}
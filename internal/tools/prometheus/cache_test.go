@@ -0,0 +1,31 @@
+package prometheus
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestTTLCacheExpiry(t *testing.T) {
+	c := newTTLCache[[]string](completionCacheTTL)
+	now := time.Now()
+	c.now = func() time.Time { return now }
+
+	fetches := 0
+	fetch := func(context.Context) ([]string, error) {
+		fetches++
+		return []string{"a"}, nil
+	}
+	for range 2 {
+		if _, err := c.get(context.Background(), "k", fetch); err != nil {
+			t.Fatal(err)
+		}
+	}
+	now = now.Add(completionCacheTTL)
+	if _, err := c.get(context.Background(), "k", fetch); err != nil {
+		t.Fatal(err)
+	}
+	if fetches != 2 {
+		t.Errorf("fetched %d times, want 2", fetches)
+	}
+}
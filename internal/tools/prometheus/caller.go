@@ -0,0 +1,44 @@
+package prometheus
+
+import (
+	"cmp"
+	"context"
+
+	"github.com/giantswarm/mcp-oauth/handler"
+	mcpserver "github.com/mark3labs/mcp-go/server"
+)
+
+// sessionScopePrefix marks caller scopes bound to an MCP session rather than
+// an OAuth user.
+const sessionScopePrefix = "session:"
+
+// callerScope identifies who owns state created by a tool call, such as
+// registered templates: the OAuth user when authentication is enabled, else
+// the MCP session. It is empty when neither is known, e.g. for direct
+// handler calls, which then share one scope.
+func callerScope(ctx context.Context) string {
+	if userInfo, ok := handler.UserInfoFromContext(ctx); ok {
+		if id := cmp.Or(userInfo.ID, userInfo.Email); id != "" {
+			return "user:" + id
+		}
+	}
+	if session := mcpserver.ClientSessionFromContext(ctx); session != nil && session.SessionID() != "" {
+		return sessionScopePrefix + session.SessionID()
+	}
+	return ""
+}
+
+// onSessionEnd runs fn with the caller scope of every MCP session that
+// unregisters, so state owned by the session can be released.
+func onSessionEnd(s *mcpserver.MCPServer, fn func(scope string)) {
+	hooks := s.GetHooks()
+	if hooks == nil {
+		hooks = &mcpserver.Hooks{}
+		mcpserver.WithHooks(hooks)(s)
+	}
+	hooks.AddOnUnregisterSession(func(_ context.Context, session mcpserver.ClientSession) {
+		if id := session.SessionID(); id != "" {
+			fn(sessionScopePrefix + id)
+		}
+	})
+}
@@ -0,0 +1,92 @@
+package prometheus
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// maxConcurrentCardinalityLookups caps the number of ListLabelValues calls
+// list_label_names issues in parallel when with_cardinality is set.
+const maxConcurrentCardinalityLookups = 10
+
+// labelCardinality is a label name and its number of distinct values.
+type labelCardinality struct {
+	Name   string
+	Values int
+}
+
+// fetchLabelCardinalities looks up the number of distinct values of each
+// label, honouring the time range and matchers in options. The result keeps
+// the order of names.
+func fetchLabelCardinalities(ctx context.Context, client *Client, names []string, options LabelOptions) ([]labelCardinality, error) {
+	valueOptions := LabelOptions{
+		StartTime: options.StartTime,
+		EndTime:   options.EndTime,
+		Matches:   options.Matches,
+	}
+
+	result := make([]labelCardinality, len(names))
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(maxConcurrentCardinalityLookups)
+	for i, name := range names {
+		g.Go(func() error {
+			values, err := client.ListLabelValues(gctx, name, valueOptions)
+			if err != nil {
+				return fmt.Errorf("label %q: %w", name, err)
+			}
+			result[i] = labelCardinality{Name: name, Values: len(values.LabelValues)}
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// filterLabelCardinalities drops labels with fewer than minCardinality
+// values and optionally sorts by cardinality (descending, ties by name).
+func filterLabelCardinalities(labels []labelCardinality, minCardinality int, sortByCardinality bool) []labelCardinality {
+	filtered := make([]labelCardinality, 0, len(labels))
+	for _, l := range labels {
+		if l.Values >= minCardinality {
+			filtered = append(filtered, l)
+		}
+	}
+	if sortByCardinality {
+		sort.SliceStable(filtered, func(i, j int) bool {
+			if filtered[i].Values != filtered[j].Values {
+				return filtered[i].Values > filtered[j].Values
+			}
+			return filtered[i].Name < filtered[j].Name
+		})
+	}
+	return filtered
+}
+
+// formatLabelCardinalities renders label cardinalities, already filtered by
+// filterLabelCardinalities, as a two-column table.
+func formatLabelCardinalities(filtered []labelCardinality, minCardinality int) string {
+	if len(filtered) == 0 {
+		return fmt.Sprintf("No label names with at least %d distinct values found", minCardinality)
+	}
+
+	width := len("label_name")
+	for _, l := range filtered {
+		width = max(width, len(l.Name))
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Found %d label names:\n", len(filtered))
+	fmt.Fprintf(&b, "%-*s | distinct_values\n", width, "label_name")
+	fmt.Fprintf(&b, "%s-|-%s\n", strings.Repeat("-", width), strings.Repeat("-", len("distinct_values")))
+	for _, l := range filtered {
+		fmt.Fprintf(&b, "%-*s | %s\n", width, l.Name, strconv.Itoa(l.Values))
+	}
+	return b.String()
+}
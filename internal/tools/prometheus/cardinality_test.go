@@ -0,0 +1,134 @@
+package prometheus
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/giantswarm/mcp-prometheus/internal/server"
+)
+
+// cardinalityFixture maps label names to the values the mock server returns.
+var cardinalityFixture = map[string][]string{
+	"__name__": {"up", "http_requests_total", "go_goroutines"},
+	"job":      {"api", "batch"},
+	"cluster":  {"prod"},
+	"instance": {"a", "b", "c", "d"},
+}
+
+func newCardinalityMockServer(t *testing.T, inFlight, peak *atomic.Int32) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var data any
+		switch {
+		case r.URL.Path == "/api/v1/labels":
+			data = []string{"__name__", "cluster", "instance", "job"}
+		case strings.HasPrefix(r.URL.Path, "/api/v1/label/") && strings.HasSuffix(r.URL.Path, "/values"):
+			n := inFlight.Add(1)
+			defer inFlight.Add(-1)
+			for {
+				p := peak.Load()
+				if n <= p || peak.CompareAndSwap(p, n) {
+					break
+				}
+			}
+			name := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/api/v1/label/"), "/values")
+			data = cardinalityFixture[name]
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{respKeyStatus: respValSuccess, respKeyData: data})
+	}))
+}
+
+func TestHandleListLabelNamesWithCardinality(t *testing.T) {
+	var inFlight, peak atomic.Int32
+	mockServer := newCardinalityMockServer(t, &inFlight, &peak)
+	defer mockServer.Close()
+
+	ctx := context.Background()
+	sc, err := server.NewServerContext(ctx,
+		server.WithPrometheusConfig(server.PrometheusConfig{URL: mockServer.URL}),
+		server.WithSlogLogger(discardLogger()),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create server context: %v", err)
+	}
+	defer func() { _ = sc.Shutdown() }()
+
+	client, err := NewClient(sc.PrometheusConfig(), sc.Logger())
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+
+	call := func(args map[string]any) string {
+		t.Helper()
+		result, err := handleListLabelNames(ctx, mcp.CallToolRequest{Params: mcp.CallToolParams{Arguments: args}}, client, sc)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if result.IsError {
+			t.Fatalf("expected success, got error: %v", result.Content)
+		}
+		return result.Content[0].(mcp.TextContent).Text
+	}
+
+	text := call(map[string]any{"with_cardinality": "true", "sort_by": "cardinality"})
+	want := "Found 4 label names:\n" +
+		"label_name | distinct_values\n" +
+		"-----------|----------------\n" +
+		"instance   | 4\n" +
+		"__name__   | 3\n" +
+		"job        | 2\n" +
+		"cluster    | 1\n"
+	if text != want {
+		t.Errorf("unexpected table:\n%s\nwant:\n%s", text, want)
+	}
+
+	text = call(map[string]any{"with_cardinality": "true", "min_cardinality": "2"})
+	if strings.Contains(text, "cluster") || !strings.Contains(text, "Found 3 label names") {
+		t.Errorf("expected cluster to be filtered out, got:\n%s", text)
+	}
+	// Default order is the order returned by Prometheus.
+	if strings.Index(text, "__name__") > strings.Index(text, "instance") {
+		t.Errorf("expected name order to be preserved, got:\n%s", text)
+	}
+
+	if p := peak.Load(); p > maxConcurrentCardinalityLookups {
+		t.Errorf("peak concurrent lookups = %d, want <= %d", p, maxConcurrentCardinalityLookups)
+	}
+}
+
+func TestHandleListLabelNamesInvalidCardinalityParams(t *testing.T) {
+	sc, err := server.NewServerContext(context.Background(),
+		server.WithPrometheusConfig(server.PrometheusConfig{URL: "http://localhost:9090"}),
+		server.WithSlogLogger(discardLogger()),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create server context: %v", err)
+	}
+	defer func() { _ = sc.Shutdown() }()
+
+	for name, args := range map[string]map[string]any{
+		"bad sort_by":         {"with_cardinality": "true", "sort_by": "size"},
+		"bad min_cardinality": {"with_cardinality": "true", "min_cardinality": "-1"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			result, err := handleListLabelNames(context.Background(), mcp.CallToolRequest{Params: mcp.CallToolParams{Arguments: args}}, nil, sc)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !result.IsError {
+				t.Error("expected IsError=true")
+			}
+		})
+	}
+}
@@ -1,22 +1,36 @@
 package prometheus
 
 import (
+	"bytes"
 	"context"
 	"crypto/tls"
 	"crypto/x509"
+	"encoding/json"
 	"fmt"
 	"io"
 	"log/slog"
+	"maps"
+	"math"
 	"net/http"
 	"net/url"
 	"os"
+	"slices"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/prometheus/client_golang/api"
 	v1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
 	"github.com/prometheus/common/model"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/oauth2"
 
 	"github.com/giantswarm/mcp-prometheus/internal/server"
 )
@@ -34,15 +48,90 @@ func (o *orgIDRoundTripper) RoundTrip(req *http.Request) (*http.Response, error)
 	return o.rt.RoundTrip(req)
 }
 
-// basicAuthRoundTripper adds basic authentication to requests
+// toolCallHeaderRoundTripper adds the headers collected in the request
+// context's server.ToolCall, letting ToolMiddleware decorate Prometheus
+// requests.
+type toolCallHeaderRoundTripper struct {
+	rt http.RoundTripper
+}
+
+func (t *toolCallHeaderRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	if call, ok := server.ToolCallFromContext(req.Context()); ok {
+		for key, values := range call.Header {
+			for _, v := range values {
+				req.Header.Add(key, v)
+			}
+		}
+	}
+	return t.rt.RoundTrip(req)
+}
+
+// Span names of Prometheus client requests: PromQL evaluation and everything
+// else (labels, series, metadata, targets, status endpoints).
+const (
+	spanNameQuery    = "prometheus.query"
+	spanNameMetadata = "prometheus.metadata"
+)
+
+// otelRoundTripper records the request duration on the span otelhttp starts
+// for each Prometheus request. Wrap it with newOTelRoundTripper.
+type otelRoundTripper struct {
+	rt http.RoundTripper
+}
+
+func (o *otelRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	span := trace.SpanFromContext(req.Context())
+	start := time.Now()
+	resp, err := o.rt.RoundTrip(req)
+	span.SetAttributes(attribute.Float64("prometheus.request.duration_ms", float64(time.Since(start).Microseconds())/1000))
+	return resp, err
+}
+
+// newOTelRoundTripper traces requests as children of the span in the request
+// context. otelhttp starts the span, records the response status code and
+// injects W3C traceparent/tracestate headers; tp defaults to the global
+// tracer provider.
+func newOTelRoundTripper(rt http.RoundTripper, tp trace.TracerProvider) http.RoundTripper {
+	if tp == nil {
+		tp = otel.GetTracerProvider()
+	}
+	return otelhttp.NewTransport(&otelRoundTripper{rt: rt},
+		otelhttp.WithTracerProvider(tp),
+		otelhttp.WithPropagators(propagation.TraceContext{}),
+		otelhttp.WithSpanNameFormatter(func(_ string, req *http.Request) string {
+			return prometheusSpanName(req.URL.Path)
+		}),
+	)
+}
+
+// prometheusSpanName names the span of a request to path.
+func prometheusSpanName(path string) string {
+	for _, endpoint := range []string{"/api/v1/query", "/api/v1/query_range", "/api/v1/query_exemplars"} {
+		if strings.HasSuffix(path, endpoint) {
+			return spanNameQuery
+		}
+	}
+	return spanNameMetadata
+}
+
+// basicAuthRoundTripper adds basic authentication to requests. The password
+// is read from passwordFile when it is set.
 type basicAuthRoundTripper struct {
-	username string
-	password string
-	rt       http.RoundTripper
+	username     string
+	password     string
+	passwordFile *tokenFile
+	rt           http.RoundTripper
 }
 
 func (b *basicAuthRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
-	req.SetBasicAuth(b.username, b.password)
+	password := b.password
+	if b.passwordFile != nil {
+		var err error
+		if password, err = b.passwordFile.Token(); err != nil {
+			return nil, err
+		}
+	}
+	req.SetBasicAuth(b.username, password)
 	return b.rt.RoundTrip(req)
 }
 
@@ -62,7 +151,38 @@ type Client struct {
 	client     v1.API
 	httpClient *http.Client // for raw HTTP calls (health/ready endpoints)
 	config     server.PrometheusConfig
+	baseURL    string // config.URL joined with config.PathPrefix
 	logger     *slog.Logger
+
+	// transport is the client's own transport when its TLS settings need
+	// one; nil when it shares http.DefaultTransport.
+	transport *http.Transport
+
+	// adminHTTPClient sends the retried idempotent admin requests. It has
+	// no overall timeout, which would cut off the retries; requests are
+	// bounded by adminRequestTimeout instead.
+	adminHTTPClient     *http.Client
+	adminRequestTimeout time.Duration
+
+	// alertmanagerHTTPClient sends Alertmanager requests; it is httpClient
+	// unless Alertmanager has credentials of its own.
+	alertmanagerHTTPClient *http.Client
+
+	// alertmanagerURL caches the result of DiscoverAlertmanagerURL until
+	// alertmanagerExpires.
+	alertmanagerMu      sync.Mutex
+	alertmanagerURL     string
+	alertmanagerExpires time.Time
+
+	// API features of the server, set by NegotiateAPIVersion. negotiating
+	// is closed when the build info request in flight completes, and a
+	// failed negotiation is not repeated before negotiationRetryAt.
+	featuresMu         sync.Mutex
+	featuresNegotiated bool
+	supportedFeatures  apiFeature
+	serverVersion      string
+	negotiating        chan struct{}
+	negotiationRetryAt time.Time
 }
 
 // NewClient creates a new Prometheus client using the official client library.
@@ -75,9 +195,33 @@ func NewClient(config server.PrometheusConfig, logger *slog.Logger) (*Client, er
 	if config.URL == "" {
 		return nil, fmt.Errorf("prometheus URL is required")
 	}
+	if err := validatePathPrefix(config.PathPrefix); err != nil {
+		return nil, err
+	}
+	switch config.AuthMode {
+	case "", server.AuthModeGCP, server.AuthModeAzure:
+	default:
+		return nil, fmt.Errorf("unsupported auth mode %q (supported: %s, %s)", config.AuthMode, server.AuthModeGCP, server.AuthModeAzure)
+	}
+	if config.Token != "" && config.TokenFile != "" {
+		return nil, fmt.Errorf("set a bearer token or a token file, not both")
+	}
+	if config.Password != "" && config.PasswordFile != "" {
+		return nil, fmt.Errorf("set a password or a password file, not both")
+	}
+	if config.Alertmanager.Token != "" && config.Alertmanager.TokenFile != "" {
+		return nil, fmt.Errorf("set an Alertmanager bearer token or token file, not both")
+	}
+	if config.OAuth2.Enabled() && config.AuthMode == "" {
+		if err := validateOAuth2Config(config.OAuth2); err != nil {
+			return nil, err
+		}
+	}
+	baseURL := joinPathPrefix(config.URL, config.PathPrefix)
 
 	// Start with default transport, or a custom TLS transport when needed
 	var roundTripper = http.DefaultTransport
+	var ownTransport *http.Transport
 
 	if config.TLSSkipVerify || config.TLSCACert != "" {
 		tlsConfig := &tls.Config{
@@ -101,12 +245,59 @@ func NewClient(config server.PrometheusConfig, logger *slog.Logger) (*Client, er
 		transport := http.DefaultTransport.(*http.Transport).Clone()
 		transport.TLSClientConfig = tlsConfig
 		roundTripper = transport
+		ownTransport = transport
 	}
 
+	// Token requests share the TLS settings but not the Prometheus-specific layers
+	tokenTransport := roundTripper
+
+	// Apply the server's request limits below the retries, so every attempt
+	// counts against them
+	if config.RequestLimiter != nil {
+		roundTripper = &limitedRoundTripper{limiter: config.RequestLimiter, rt: roundTripper}
+	}
+
+	// Retry idempotent admin requests that fail transiently
+	retrier := newIdempotentRetryRoundTripper(roundTripper, logger)
+	roundTripper = retrier
+	// Alertmanager requests with credentials of their own start from here
+	baseTransport := roundTripper
+
 	// Add authentication layer
-	if config.Token != "" {
+	if config.AuthMode == server.AuthModeGCP {
+		ts, err := gcpTokenSource(config)
+		if err != nil {
+			return nil, err
+		}
+		roundTripper = &oauth2.Transport{Source: ts, Base: roundTripper}
+		logger.Debug("Using Google Cloud authentication", "credentialsFile", config.GCPCredentialsFile)
+	} else if config.AuthMode == server.AuthModeAzure {
+		ts, err := azureTokenSourceFor(config)
+		if err != nil {
+			return nil, err
+		}
+		roundTripper = &oauth2.Transport{Source: ts, Base: roundTripper}
+		logger.Debug("Using Azure AD authentication", "tenantID", config.Azure.TenantID, "clientID", config.Azure.ClientID)
+	} else if config.OAuth2.Enabled() {
+		roundTripper = &oauth2.Transport{Source: oauth2TokenSource(config, tokenTransport), Base: roundTripper}
+		logger.Debug("Using OAuth2 client credentials authentication", "tokenURL", config.OAuth2.TokenURL, "clientID", config.OAuth2.ClientID)
+	} else if config.TokenFile != "" {
+		file, err := newTokenFile(config.TokenFile, logger)
+		if err != nil {
+			return nil, err
+		}
+		roundTripper = &tokenFileRoundTripper{file: file, rt: roundTripper}
+		logger.Debug("Using bearer token file authentication", "path", config.TokenFile)
+	} else if config.Token != "" {
 		roundTripper = &bearerTokenRoundTripper{token: config.Token, rt: roundTripper}
 		logger.Debug("Using bearer token authentication")
+	} else if config.Username != "" && config.PasswordFile != "" {
+		file, err := newTokenFile(config.PasswordFile, logger)
+		if err != nil {
+			return nil, err
+		}
+		roundTripper = &basicAuthRoundTripper{username: config.Username, passwordFile: file, rt: roundTripper}
+		logger.Debug("Using basic authentication with a password file", "username", config.Username, "path", config.PasswordFile)
 	} else if config.Username != "" && config.Password != "" {
 		roundTripper = &basicAuthRoundTripper{username: config.Username, password: config.Password, rt: roundTripper}
 		logger.Debug("Using basic authentication", "username", config.Username)
@@ -116,34 +307,166 @@ func NewClient(config server.PrometheusConfig, logger *slog.Logger) (*Client, er
 
 	// Add organization ID layer if specified
 	if config.OrgID != "" {
-		roundTripper = &orgIDRoundTripper{orgID: config.OrgID, rt: roundTripper}
 		logger.Debug("Using organization ID", "orgID", config.OrgID)
 	}
+	roundTripper = withRequestLayers(roundTripper, config)
 
 	promClient, err := api.NewClient(api.Config{
-		Address:      config.URL,
+		Address:      baseURL,
 		RoundTripper: roundTripper,
 	})
 	if err != nil {
-		return nil, fmt.Errorf("create Prometheus API client for %q: %w", config.URL, err)
+		return nil, fmt.Errorf("create Prometheus API client for %q: %w", baseURL, err)
 	}
 
-	logger.Debug("Successfully created Prometheus client", "address", config.URL)
+	logger.Debug("Successfully created Prometheus client", "address", baseURL)
+
+	httpClient := &http.Client{Transport: roundTripper, Timeout: httpRequestTimeout}
+	alertmanagerHTTPClient := httpClient
+	if am := config.Alertmanager; am.HasAuth() {
+		amRoundTripper := baseTransport
+		switch {
+		case am.TokenFile != "":
+			file, err := newTokenFile(am.TokenFile, logger)
+			if err != nil {
+				return nil, err
+			}
+			amRoundTripper = &tokenFileRoundTripper{file: file, rt: amRoundTripper}
+		case am.Token != "":
+			amRoundTripper = &bearerTokenRoundTripper{token: am.Token, rt: amRoundTripper}
+		default:
+			amRoundTripper = &basicAuthRoundTripper{username: am.Username, password: am.Password, rt: amRoundTripper}
+		}
+		logger.Debug("Using separate Alertmanager credentials")
+		alertmanagerHTTPClient = &http.Client{Transport: withRequestLayers(amRoundTripper, config), Timeout: httpRequestTimeout}
+	}
 
 	return &Client{
-		client:     v1.NewAPI(promClient),
-		httpClient: &http.Client{Transport: roundTripper, Timeout: 10 * time.Second},
-		config:     config,
-		logger:     logger,
+		client:                 v1.NewAPI(promClient),
+		httpClient:             httpClient,
+		adminHTTPClient:        &http.Client{Transport: roundTripper},
+		adminRequestTimeout:    retrier.budget(),
+		transport:              ownTransport,
+		alertmanagerHTTPClient: alertmanagerHTTPClient,
+		config:                 config,
+		baseURL:                baseURL,
+		logger:                 logger,
 	}, nil
 }
 
+// CloseIdleConnections closes the idle connections of the client's own
+// transport. Connections of the shared http.DefaultTransport are left to
+// other clients.
+func (c *Client) CloseIdleConnections() {
+	if c.transport != nil {
+		c.transport.CloseIdleConnections()
+	}
+}
+
+// withRequestLayers wraps an authenticated round tripper with the layers
+// every request shares: the org ID header, headers injected by tool
+// middleware, and a span that is a child of the tool call span.
+func withRequestLayers(rt http.RoundTripper, config server.PrometheusConfig) http.RoundTripper {
+	if config.OrgID != "" {
+		rt = &orgIDRoundTripper{orgID: config.OrgID, rt: rt}
+	}
+	rt = &toolCallHeaderRoundTripper{rt: rt}
+	return newOTelRoundTripper(rt, config.TracerProvider)
+}
+
+// limitedRoundTripper waits for the request limiter before each request,
+// including each retry of an idempotent admin request. The concurrency slot
+// is held until the response body is closed, so slow downloads of large
+// results count as in flight.
+type limitedRoundTripper struct {
+	limiter *server.RequestLimiter
+	rt      http.RoundTripper
+}
+
+func (l *limitedRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	release, err := l.limiter.Acquire(req.Context())
+	if err != nil {
+		return nil, err
+	}
+	resp, err := l.rt.RoundTrip(req)
+	if err != nil {
+		release()
+		return nil, err
+	}
+	resp.Body = &releasingBody{ReadCloser: resp.Body, release: sync.OnceFunc(release)}
+	return resp, nil
+}
+
+// releasingBody calls release when the response body is closed.
+type releasingBody struct {
+	io.ReadCloser
+	release func()
+}
+
+func (b *releasingBody) Close() error {
+	defer b.release()
+	return b.ReadCloser.Close()
+}
+
 // QueryResult represents the result of an instant query
 type QueryResult struct {
 	ResultType string      `json:"resultType"`
 	Result     interface{} `json:"result"`
 }
 
+// millisecondPrefix marks a time parameter as Unix milliseconds
+// (e.g. "ms:1704067200500").
+const millisecondPrefix = "ms:"
+
+// parseTimeParam parses a query time given as RFC3339, integer Unix seconds,
+// fractional Unix seconds ("1704067200.500", microsecond precision), Unix
+// milliseconds with the "ms:" prefix or a time expression such as "yesterday"
+// (see parseNaturalTime).
+func parseTimeParam(timeParam string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, timeParam); err == nil {
+		return t, nil
+	}
+
+	if ms, ok := strings.CutPrefix(timeParam, millisecondPrefix); ok {
+		val, err := strconv.ParseInt(ms, 10, 64)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid time parameter %q: %q is not a Unix millisecond timestamp", timeParam, ms)
+		}
+		return time.UnixMilli(val), nil
+	}
+
+	if ts, err := strconv.ParseInt(timeParam, 10, 64); err == nil {
+		return time.Unix(ts, 0), nil
+	}
+
+	if t, ok := parseNaturalTime(timeParam, time.Now()); ok {
+		return t, nil
+	}
+
+	val, err := strconv.ParseFloat(timeParam, 64)
+	if err != nil || math.IsNaN(val) || math.IsInf(val, 0) {
+		return time.Time{}, fmt.Errorf("invalid time parameter %q: not RFC3339, a Unix timestamp or a supported time expression", timeParam)
+	}
+	return time.UnixMicro(int64(math.Round(val * 1e6))), nil
+}
+
+// parseFilterTimes parses the optional start and end of a label or series
+// lookup with parseTimeParam. An empty value yields the zero time, which
+// leaves that end of the range open.
+func parseFilterTimes(start, end string) (startTime, endTime time.Time, err error) {
+	if start != "" {
+		if startTime, err = parseTimeParam(start); err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid start time: %w", err)
+		}
+	}
+	if end != "" {
+		if endTime, err = parseTimeParam(end); err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid end time: %w", err)
+		}
+	}
+	return startTime, endTime, nil
+}
+
 // ExecuteQuery executes an instant PromQL query
 func (c *Client) ExecuteQuery(ctx context.Context, query string, timeParam string) (*QueryResult, error) {
 	if c.client == nil {
@@ -154,14 +477,9 @@ func (c *Client) ExecuteQuery(ctx context.Context, query string, timeParam strin
 	var err error
 
 	if timeParam != "" {
-		// Parse the time parameter — try RFC3339 first, then Unix seconds.
-		queryTime, err = time.Parse(time.RFC3339, timeParam)
+		queryTime, err = parseTimeParam(timeParam)
 		if err != nil {
-			ts, parseErr := strconv.ParseInt(timeParam, 10, 64)
-			if parseErr != nil {
-				return nil, fmt.Errorf("invalid time parameter %q: not RFC3339 and not a Unix timestamp", timeParam)
-			}
-			queryTime = time.Unix(ts, 0)
+			return nil, err
 		}
 	} else {
 		queryTime = time.Now()
@@ -172,7 +490,7 @@ func (c *Client) ExecuteQuery(ctx context.Context, query string, timeParam strin
 
 	result, warnings, err := c.client.Query(ctx, query, queryTime)
 	if err != nil {
-		return nil, fmt.Errorf("failed to execute query: %w", err)
+		return nil, fmt.Errorf("failed to execute query: %w", toAPIError(err))
 	}
 
 	if len(warnings) > 0 {
@@ -195,14 +513,9 @@ func (c *Client) ExecuteQueryWithOptions(ctx context.Context, query string, time
 	var err error
 
 	if timeParam != "" {
-		// Parse the time parameter — try RFC3339 first, then Unix seconds.
-		queryTime, err = time.Parse(time.RFC3339, timeParam)
+		queryTime, err = parseTimeParam(timeParam)
 		if err != nil {
-			ts, parseErr := strconv.ParseInt(timeParam, 10, 64)
-			if parseErr != nil {
-				return nil, fmt.Errorf("invalid time parameter %q: not RFC3339 and not a Unix timestamp", timeParam)
-			}
-			queryTime = time.Unix(ts, 0)
+			return nil, err
 		}
 	} else {
 		queryTime = time.Now()
@@ -222,6 +535,9 @@ func (c *Client) ExecuteQueryWithOptions(ctx context.Context, query string, time
 	// Build API options
 	var apiOptions []v1.Option
 	if options.Limit != "" {
+		if err := c.requireFeature(ctx, featureLimit); err != nil {
+			return nil, err
+		}
 		if limit, err := strconv.ParseUint(options.Limit, 10, 64); err == nil {
 			apiOptions = append(apiOptions, v1.WithLimit(limit))
 		}
@@ -240,7 +556,7 @@ func (c *Client) ExecuteQueryWithOptions(ctx context.Context, query string, time
 
 	result, warnings, err := c.client.Query(ctx, query, queryTime, apiOptions...)
 	if err != nil {
-		return nil, fmt.Errorf("failed to execute query: %w", err)
+		return nil, fmt.Errorf("failed to execute query: %w", toAPIError(err))
 	}
 
 	if len(warnings) > 0 {
@@ -260,13 +576,13 @@ func (c *Client) ExecuteRangeQuery(ctx context.Context, query, start, end, step
 	}
 
 	// Parse start time
-	startTime, err := time.Parse(time.RFC3339, start)
+	startTime, err := parseTimeParam(start)
 	if err != nil {
 		return nil, fmt.Errorf("invalid start time: %w", err)
 	}
 
 	// Parse end time
-	endTime, err := time.Parse(time.RFC3339, end)
+	endTime, err := parseTimeParam(end)
 	if err != nil {
 		return nil, fmt.Errorf("invalid end time: %w", err)
 	}
@@ -288,7 +604,7 @@ func (c *Client) ExecuteRangeQuery(ctx context.Context, query, start, end, step
 
 	result, warnings, err := c.client.QueryRange(ctx, query, queryRange)
 	if err != nil {
-		return nil, fmt.Errorf("failed to execute range query: %w", err)
+		return nil, fmt.Errorf("failed to execute range query: %w", toAPIError(err))
 	}
 
 	if len(warnings) > 0 {
@@ -308,13 +624,13 @@ func (c *Client) ExecuteRangeQueryWithOptions(ctx context.Context, query, start,
 	}
 
 	// Parse start time
-	startTime, err := time.Parse(time.RFC3339, start)
+	startTime, err := parseTimeParam(start)
 	if err != nil {
 		return nil, fmt.Errorf("invalid start time: %w", err)
 	}
 
 	// Parse end time
-	endTime, err := time.Parse(time.RFC3339, end)
+	endTime, err := parseTimeParam(end)
 	if err != nil {
 		return nil, fmt.Errorf("invalid end time: %w", err)
 	}
@@ -345,6 +661,9 @@ func (c *Client) ExecuteRangeQueryWithOptions(ctx context.Context, query, start,
 	// Build API options
 	var apiOptions []v1.Option
 	if options.Limit != "" {
+		if err := c.requireFeature(ctx, featureLimit); err != nil {
+			return nil, err
+		}
 		if limit, err := strconv.ParseUint(options.Limit, 10, 64); err == nil {
 			apiOptions = append(apiOptions, v1.WithLimit(limit))
 		}
@@ -363,7 +682,7 @@ func (c *Client) ExecuteRangeQueryWithOptions(ctx context.Context, query, start,
 
 	result, warnings, err := c.client.QueryRange(ctx, query, queryRange, apiOptions...)
 	if err != nil {
-		return nil, fmt.Errorf("failed to execute range query: %w", err)
+		return nil, fmt.Errorf("failed to execute range query: %w", toAPIError(err))
 	}
 
 	if len(warnings) > 0 {
@@ -408,7 +727,7 @@ func (c *Client) GetMetricMetadataWithOptions(ctx context.Context, metric string
 
 	metadata, err := c.client.Metadata(ctx, metric, options.Limit)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get metric metadata: %w", err)
+		return nil, fmt.Errorf("failed to get metric metadata: %w", toAPIError(err))
 	}
 
 	// Convert to our MetricMetadata format
@@ -432,6 +751,23 @@ func (c *Client) GetMetricMetadataWithOptions(ctx context.Context, metric string
 	return result, nil
 }
 
+// ListMetricMetadata gets the metadata of every metric known to the server,
+// keyed by metric name.
+func (c *Client) ListMetricMetadata(ctx context.Context) (map[string][]v1.Metadata, error) {
+	if c.client == nil {
+		return nil, fmt.Errorf("prometheus client not initialized")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	metadata, err := c.client.Metadata(ctx, "", "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get metric metadata: %w", toAPIError(err))
+	}
+	return metadata, nil
+}
+
 // TargetsResult represents the result of the targets API
 type TargetsResult struct {
 	ActiveTargets  []interface{} `json:"activeTargets"`
@@ -449,7 +785,7 @@ func (c *Client) GetTargets(ctx context.Context) (*TargetsResult, error) {
 
 	targets, err := c.client.Targets(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get targets: %w", err)
+		return nil, fmt.Errorf("failed to get targets: %w", toAPIError(err))
 	}
 
 	// Convert v1.TargetsResult to our TargetsResult format
@@ -498,26 +834,17 @@ func (c *Client) ListLabelNames(ctx context.Context, options LabelOptions) (*Lab
 	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
 	defer cancel()
 
-	var startTime, endTime time.Time
-	var err error
-
-	if options.StartTime != "" {
-		startTime, err = time.Parse(time.RFC3339, options.StartTime)
-		if err != nil {
-			return nil, fmt.Errorf("invalid start time: %w", err)
-		}
-	}
-
-	if options.EndTime != "" {
-		endTime, err = time.Parse(time.RFC3339, options.EndTime)
-		if err != nil {
-			return nil, fmt.Errorf("invalid end time: %w", err)
-		}
+	startTime, endTime, err := parseFilterTimes(options.StartTime, options.EndTime)
+	if err != nil {
+		return nil, err
 	}
 
 	// Build API options
 	var apiOptions []v1.Option
 	if options.Limit != "" {
+		if err := c.requireFeature(ctx, featureLimit); err != nil {
+			return nil, err
+		}
 		if limit, err := strconv.ParseUint(options.Limit, 10, 64); err == nil {
 			apiOptions = append(apiOptions, v1.WithLimit(limit))
 		}
@@ -525,7 +852,13 @@ func (c *Client) ListLabelNames(ctx context.Context, options LabelOptions) (*Lab
 
 	labelNames, warnings, err := c.client.LabelNames(ctx, options.Matches, startTime, endTime, apiOptions...)
 	if err != nil {
-		return nil, fmt.Errorf("failed to list label names: %w", err)
+		return nil, fmt.Errorf("failed to list label names: %w", toAPIError(err))
+	}
+
+	// Convert to string slice
+	names := make([]string, len(labelNames))
+	for i, n := range labelNames {
+		names[i] = string(n)
 	}
 
 	// Convert warnings to string slice
@@ -535,7 +868,7 @@ func (c *Client) ListLabelNames(ctx context.Context, options LabelOptions) (*Lab
 	}
 
 	return &LabelNamesResult{
-		LabelNames: labelNames,
+		LabelNames: names,
 		Warnings:   warningStrs,
 	}, nil
 }
@@ -563,26 +896,17 @@ func (c *Client) ListLabelValues(ctx context.Context, label string, options Labe
 	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
 	defer cancel()
 
-	var startTime, endTime time.Time
-	var err error
-
-	if options.StartTime != "" {
-		startTime, err = time.Parse(time.RFC3339, options.StartTime)
-		if err != nil {
-			return nil, fmt.Errorf("invalid start time: %w", err)
-		}
-	}
-
-	if options.EndTime != "" {
-		endTime, err = time.Parse(time.RFC3339, options.EndTime)
-		if err != nil {
-			return nil, fmt.Errorf("invalid end time: %w", err)
-		}
+	startTime, endTime, err := parseFilterTimes(options.StartTime, options.EndTime)
+	if err != nil {
+		return nil, err
 	}
 
 	// Build API options
 	var apiOptions []v1.Option
 	if options.Limit != "" {
+		if err := c.requireFeature(ctx, featureLimit); err != nil {
+			return nil, err
+		}
 		if limit, err := strconv.ParseUint(options.Limit, 10, 64); err == nil {
 			apiOptions = append(apiOptions, v1.WithLimit(limit))
 		}
@@ -590,7 +914,7 @@ func (c *Client) ListLabelValues(ctx context.Context, label string, options Labe
 
 	labelValues, warnings, err := c.client.LabelValues(ctx, label, options.Matches, startTime, endTime, apiOptions...)
 	if err != nil {
-		return nil, fmt.Errorf("failed to list label values: %w", err)
+		return nil, fmt.Errorf("failed to list label values: %w", toAPIError(err))
 	}
 
 	// Convert to string slice
@@ -633,26 +957,17 @@ func (c *Client) FindSeries(ctx context.Context, matches []string, options Serie
 	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
 	defer cancel()
 
-	var startTime, endTime time.Time
-	var err error
-
-	if options.StartTime != "" {
-		startTime, err = time.Parse(time.RFC3339, options.StartTime)
-		if err != nil {
-			return nil, fmt.Errorf("invalid start time: %w", err)
-		}
-	}
-
-	if options.EndTime != "" {
-		endTime, err = time.Parse(time.RFC3339, options.EndTime)
-		if err != nil {
-			return nil, fmt.Errorf("invalid end time: %w", err)
-		}
+	startTime, endTime, err := parseFilterTimes(options.StartTime, options.EndTime)
+	if err != nil {
+		return nil, err
 	}
 
 	// Build API options
 	var apiOptions []v1.Option
 	if options.Limit != "" {
+		if err := c.requireFeature(ctx, featureLimit); err != nil {
+			return nil, err
+		}
 		if limit, err := strconv.ParseUint(options.Limit, 10, 64); err == nil {
 			apiOptions = append(apiOptions, v1.WithLimit(limit))
 		}
@@ -660,7 +975,7 @@ func (c *Client) FindSeries(ctx context.Context, matches []string, options Serie
 
 	series, warnings, err := c.client.Series(ctx, matches, startTime, endTime, apiOptions...)
 	if err != nil {
-		return nil, fmt.Errorf("failed to find series: %w", err)
+		return nil, fmt.Errorf("failed to find series: %w", toAPIError(err))
 	}
 
 	// Convert to our format
@@ -693,9 +1008,9 @@ func (c *Client) GetRules(ctx context.Context) (interface{}, error) {
 	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
 	defer cancel()
 
-	rules, err := c.client.Rules(ctx)
+	rules, err := c.client.Rules(ctx, nil)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get rules: %w", err)
+		return nil, fmt.Errorf("failed to get rules: %w", toAPIError(err))
 	}
 
 	return rules, nil
@@ -712,16 +1027,16 @@ func (c *Client) GetAlerts(ctx context.Context) (interface{}, error) {
 
 	alerts, err := c.client.Alerts(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get alerts: %w", err)
+		return nil, fmt.Errorf("failed to get alerts: %w", toAPIError(err))
 	}
 
 	return alerts, nil
 }
 
 // GetAlertManagers gets AlertManager discovery info
-func (c *Client) GetAlertManagers(ctx context.Context) (interface{}, error) {
+func (c *Client) GetAlertManagers(ctx context.Context) (v1.AlertManagersResult, error) {
 	if c.client == nil {
-		return nil, fmt.Errorf("prometheus client not initialized")
+		return v1.AlertManagersResult{}, fmt.Errorf("prometheus client not initialized")
 	}
 
 	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
@@ -729,16 +1044,243 @@ func (c *Client) GetAlertManagers(ctx context.Context) (interface{}, error) {
 
 	alertManagers, err := c.client.AlertManagers(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get alert managers: %w", err)
+		return v1.AlertManagersResult{}, fmt.Errorf("failed to get alert managers: %w", toAPIError(err))
 	}
 
 	return alertManagers, nil
 }
 
+// alertmanagerAPISuffixes are the API paths Prometheus appends to the
+// Alertmanager URLs it reports; they are stripped to get the base URL.
+var alertmanagerAPISuffixes = []string{"/api/v2/alerts", "/api/v1/alerts"}
+
+// httpRequestTimeout bounds the raw HTTP requests of a client, such as
+// health checks, and each attempt of a retried admin request. A variable so
+// tests can shorten it.
+var httpRequestTimeout = 10 * time.Second
+
+// alertmanagerDiscoveryTTL is how long a discovered Alertmanager URL is used
+// before Prometheus is asked again. Prometheus reports pod IPs in
+// Kubernetes, which change when the Alertmanager is rescheduled.
+var alertmanagerDiscoveryTTL = 5 * time.Minute
+
+// DiscoverAlertmanagerURL asks Prometheus which Alertmanagers it sends alerts
+// to and returns the base URL of the first active one. The result is cached
+// on the client for alertmanagerDiscoveryTTL and returned by AlertmanagerURL.
+func (c *Client) DiscoverAlertmanagerURL(ctx context.Context) (string, error) {
+	result, err := c.GetAlertManagers(ctx)
+	if err != nil {
+		return "", err
+	}
+	if len(result.Active) == 0 {
+		return "", fmt.Errorf("prometheus reports no active Alertmanagers")
+	}
+
+	discovered := result.Active[0].URL
+	for _, suffix := range alertmanagerAPISuffixes {
+		if trimmed, ok := strings.CutSuffix(discovered, suffix); ok {
+			discovered = trimmed
+			break
+		}
+	}
+
+	c.alertmanagerMu.Lock()
+	c.alertmanagerURL = discovered
+	c.alertmanagerExpires = time.Now().Add(alertmanagerDiscoveryTTL)
+	c.alertmanagerMu.Unlock()
+
+	c.logger.Debug("Discovered Alertmanager URL", "url", discovered)
+	return discovered, nil
+}
+
+// AlertmanagerURL returns the Alertmanager base URL to use with this client:
+// the configured ALERTMANAGER_URL if set, otherwise the URL discovered from
+// Prometheus, which is discovered again once it expires. Configured
+// credentials are only sent to the configured URL, so discovery fails when
+// the client has any.
+func (c *Client) AlertmanagerURL(ctx context.Context) (string, error) {
+	if c.config.Alertmanager.URL != "" {
+		return c.config.Alertmanager.URL, nil
+	}
+	if c.config.Alertmanager.HasAuth() || c.config.AuthType() != "none" {
+		return "", fmt.Errorf("the Alertmanager is not discovered when credentials are configured, as they would be sent to the discovered URL; set ALERTMANAGER_URL or the profile's Alertmanager URL")
+	}
+
+	c.alertmanagerMu.Lock()
+	cached := c.alertmanagerURL
+	fresh := time.Now().Before(c.alertmanagerExpires)
+	c.alertmanagerMu.Unlock()
+	if cached != "" && fresh {
+		return cached, nil
+	}
+
+	return c.DiscoverAlertmanagerURL(ctx)
+}
+
+// forgetAlertmanagerURL drops the discovered Alertmanager URL if it is still
+// url, so the next request discovers it again, e.g. after the Alertmanager
+// pod was rescheduled to another IP.
+func (c *Client) forgetAlertmanagerURL(url string) {
+	c.alertmanagerMu.Lock()
+	defer c.alertmanagerMu.Unlock()
+	if c.alertmanagerURL == url {
+		c.alertmanagerURL = ""
+	}
+}
+
+// getAlertmanagerJSON decodes the JSON response of GET <alertmanager>/<path>
+// into v.
+func (c *Client) getAlertmanagerJSON(ctx context.Context, path string, v any) error {
+	if c.alertmanagerHTTPClient == nil {
+		return fmt.Errorf("prometheus client not initialized")
+	}
+
+	baseURL, err := c.AlertmanagerURL(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to resolve Alertmanager URL: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimRight(baseURL, "/")+path, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create Alertmanager request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := c.alertmanagerHTTPClient.Do(req)
+	if err != nil {
+		c.forgetAlertmanagerURL(baseURL)
+		return fmt.Errorf("failed to query Alertmanager %s: %w", path, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("failed to query Alertmanager %s: %w", path, httpStatusError(resp.StatusCode, body))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("failed to decode Alertmanager %s response: %w", path, err)
+	}
+	return nil
+}
+
+// AlertmanagerReceiver is a receiver configured in Alertmanager with the
+// number of integrations of each type (e.g. "slack": 2, "email": 1).
+type AlertmanagerReceiver struct {
+	Name         string
+	Integrations map[string]int
+}
+
+// ListAlertmanagerReceivers lists the receivers reported by
+// GET /api/v2/receivers. That endpoint only returns names, so integration
+// counts are taken from the configuration; they are left empty when the
+// configuration cannot be read.
+func (c *Client) ListAlertmanagerReceivers(ctx context.Context) ([]AlertmanagerReceiver, error) {
+	var names []struct {
+		Name string `json:"name"`
+	}
+	if err := c.getAlertmanagerJSON(ctx, "/api/v2/receivers", &names); err != nil {
+		return nil, err
+	}
+
+	var integrations map[string]map[string]int
+	if cfg, err := c.GetAlertmanagerConfig(ctx); err != nil {
+		c.logger.Warn("Failed to read Alertmanager configuration; integration types unavailable", "error", err)
+	} else {
+		integrations = cfg.receiverIntegrations()
+	}
+
+	receivers := make([]AlertmanagerReceiver, len(names))
+	for i, n := range names {
+		receivers[i] = AlertmanagerReceiver{Name: n.Name, Integrations: integrations[n.Name]}
+	}
+	return receivers, nil
+}
+
+// GetAlertmanagerConfig fetches and parses the configuration reported by
+// GET /api/v2/status.
+func (c *Client) GetAlertmanagerConfig(ctx context.Context) (*alertmanagerConfig, error) {
+	var status struct {
+		Config struct {
+			Original string `json:"original"`
+		} `json:"config"`
+	}
+	if err := c.getAlertmanagerJSON(ctx, "/api/v2/status", &status); err != nil {
+		return nil, err
+	}
+	return parseAlertmanagerConfig(status.Config.Original)
+}
+
+// AlertmanagerAlertsFilter selects the alerts returned by
+// ListAlertmanagerAlerts. Receiver is a regular expression over receiver
+// names and Filter a list of label matchers such as severity="critical". A
+// nil state flag leaves the Alertmanager default, which includes the alerts.
+type AlertmanagerAlertsFilter struct {
+	Receiver    string
+	Filter      []string
+	Active      *bool
+	Silenced    *bool
+	Inhibited   *bool
+	Unprocessed *bool
+}
+
+// AlertmanagerAlert is an alert as reported by Alertmanager after grouping,
+// routing, silencing and inhibition.
+type AlertmanagerAlert struct {
+	Fingerprint  string            `json:"fingerprint"`
+	Labels       map[string]string `json:"labels"`
+	Annotations  map[string]string `json:"annotations"`
+	StartsAt     time.Time         `json:"startsAt"`
+	EndsAt       time.Time         `json:"endsAt"`
+	UpdatedAt    time.Time         `json:"updatedAt"`
+	GeneratorURL string            `json:"generatorURL"`
+	Receivers    []struct {
+		Name string `json:"name"`
+	} `json:"receivers"`
+	Status struct {
+		State       string   `json:"state"`
+		SilencedBy  []string `json:"silencedBy"`
+		InhibitedBy []string `json:"inhibitedBy"`
+	} `json:"status"`
+}
+
+// ListAlertmanagerAlerts lists the alerts known to Alertmanager via
+// GET /api/v2/alerts.
+func (c *Client) ListAlertmanagerAlerts(ctx context.Context, filter AlertmanagerAlertsFilter) ([]AlertmanagerAlert, error) {
+	query := url.Values{}
+	if filter.Receiver != "" {
+		query.Set("receiver", filter.Receiver)
+	}
+	for _, m := range filter.Filter {
+		query.Add("filter", m)
+	}
+	for name, flag := range map[string]*bool{
+		"active":      filter.Active,
+		"silenced":    filter.Silenced,
+		"inhibited":   filter.Inhibited,
+		"unprocessed": filter.Unprocessed,
+	} {
+		if flag != nil {
+			query.Set(name, strconv.FormatBool(*flag))
+		}
+	}
+
+	path := "/api/v2/alerts"
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+	var alerts []AlertmanagerAlert
+	if err := c.getAlertmanagerJSON(ctx, path, &alerts); err != nil {
+		return nil, err
+	}
+	return alerts, nil
+}
+
 // GetConfig gets Prometheus configuration
-func (c *Client) GetConfig(ctx context.Context) (interface{}, error) {
+func (c *Client) GetConfig(ctx context.Context) (v1.ConfigResult, error) {
 	if c.client == nil {
-		return nil, fmt.Errorf("prometheus client not initialized")
+		return v1.ConfigResult{}, fmt.Errorf("prometheus client not initialized")
 	}
 
 	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
@@ -746,14 +1288,14 @@ func (c *Client) GetConfig(ctx context.Context) (interface{}, error) {
 
 	config, err := c.client.Config(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get config: %w", err)
+		return v1.ConfigResult{}, fmt.Errorf("failed to get config: %w", toAPIError(err))
 	}
 
 	return config, nil
 }
 
 // GetFlags gets runtime flags
-func (c *Client) GetFlags(ctx context.Context) (interface{}, error) {
+func (c *Client) GetFlags(ctx context.Context) (v1.FlagsResult, error) {
 	if c.client == nil {
 		return nil, fmt.Errorf("prometheus client not initialized")
 	}
@@ -763,16 +1305,16 @@ func (c *Client) GetFlags(ctx context.Context) (interface{}, error) {
 
 	flags, err := c.client.Flags(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get flags: %w", err)
+		return nil, fmt.Errorf("failed to get flags: %w", toAPIError(err))
 	}
 
 	return flags, nil
 }
 
 // GetBuildInfo gets build information
-func (c *Client) GetBuildInfo(ctx context.Context) (interface{}, error) {
+func (c *Client) GetBuildInfo(ctx context.Context) (v1.BuildinfoResult, error) {
 	if c.client == nil {
-		return nil, fmt.Errorf("prometheus client not initialized")
+		return v1.BuildinfoResult{}, fmt.Errorf("prometheus client not initialized")
 	}
 
 	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
@@ -780,27 +1322,112 @@ func (c *Client) GetBuildInfo(ctx context.Context) (interface{}, error) {
 
 	buildInfo, err := c.client.Buildinfo(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get build info: %w", err)
+		return v1.BuildinfoResult{}, fmt.Errorf("failed to get build info: %w", toAPIError(err))
 	}
 
 	return buildInfo, nil
 }
 
+// RuntimeInfo is the response of /api/v1/status/runtimeinfo. It extends the
+// client library's result with fields that library does not decode.
+type RuntimeInfo struct {
+	v1.RuntimeinfoResult
+
+	// GOMEMLIMIT is the Go soft memory limit in bytes; reported by
+	// Prometheus 2.47 and later.
+	GOMEMLIMIT int64 `json:"GOMEMLIMIT"`
+}
+
 // GetRuntimeInfo gets runtime information
-func (c *Client) GetRuntimeInfo(ctx context.Context) (interface{}, error) {
-	if c.client == nil {
+func (c *Client) GetRuntimeInfo(ctx context.Context) (RuntimeInfo, error) {
+	if c.httpClient == nil {
+		return RuntimeInfo{}, fmt.Errorf("prometheus client not initialized")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	// The status endpoint is fetched directly because v1.API.Runtimeinfo
+	// drops GOMEMLIMIT.
+	endpoint := c.baseURL + "/api/v1/status/runtimeinfo"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return RuntimeInfo{}, fmt.Errorf("failed to create runtime info request: %w", err)
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return RuntimeInfo{}, fmt.Errorf("failed to get runtime info: %w", toAPIError(err))
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return RuntimeInfo{}, fmt.Errorf("failed to get runtime info: %w", httpStatusError(resp.StatusCode, body))
+	}
+
+	var envelope struct {
+		Status string      `json:"status"`
+		Data   RuntimeInfo `json:"data"`
+		Error  string      `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&envelope); err != nil {
+		return RuntimeInfo{}, fmt.Errorf("failed to decode runtime info: %w", err)
+	}
+	if envelope.Status != "success" {
+		return RuntimeInfo{}, fmt.Errorf("failed to get runtime info: %s", envelope.Error)
+	}
+
+	return envelope.Data, nil
+}
+
+// federateAcceptHeader requests the classic text exposition format from
+// /federate, which expfmt's text parser understands.
+const federateAcceptHeader = "text/plain;version=0.0.4"
+
+// QueryFederated fetches the latest samples of all series matching any of
+// the given selectors from the /federate endpoint and returns them as metric
+// families sorted by name.
+func (c *Client) QueryFederated(ctx context.Context, matches []string) ([]*dto.MetricFamily, error) {
+	if c.httpClient == nil {
 		return nil, fmt.Errorf("prometheus client not initialized")
 	}
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("at least one match[] selector is required")
+	}
 
 	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
 	defer cancel()
 
-	runtimeInfo, err := c.client.Runtimeinfo(ctx)
+	query := url.Values{"match[]": matches}
+	endpoint := c.baseURL + "/federate?" + query.Encode()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create federate request: %w", err)
+	}
+	req.Header.Set("Accept", federateAcceptHeader)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query federate endpoint: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("failed to query federate endpoint: %w", httpStatusError(resp.StatusCode, body))
+	}
+
+	parser := expfmt.NewTextParser(model.UTF8Validation)
+	families, err := parser.TextToMetricFamilies(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get runtime info: %w", err)
+		return nil, fmt.Errorf("failed to parse federate response: %w", err)
 	}
 
-	return runtimeInfo, nil
+	result := make([]*dto.MetricFamily, 0, len(families))
+	for _, name := range slices.Sorted(maps.Keys(families)) {
+		result = append(result, families[name])
+	}
+	return result, nil
 }
 
 // GetTSDBStats gets TSDB cardinality statistics
@@ -815,6 +1442,9 @@ func (c *Client) GetTSDBStats(ctx context.Context, options TSDBOptions) (interfa
 	// Build API options
 	var apiOptions []v1.Option
 	if options.Limit != "" {
+		if err := c.requireFeature(ctx, featureLimit); err != nil {
+			return nil, err
+		}
 		if limit, err := strconv.ParseUint(options.Limit, 10, 64); err == nil {
 			apiOptions = append(apiOptions, v1.WithLimit(limit))
 		}
@@ -822,7 +1452,7 @@ func (c *Client) GetTSDBStats(ctx context.Context, options TSDBOptions) (interfa
 
 	tsdbStats, err := c.client.TSDB(ctx, apiOptions...)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get TSDB stats: %w", err)
+		return nil, fmt.Errorf("failed to get TSDB stats: %w", toAPIError(err))
 	}
 
 	return tsdbStats, nil
@@ -834,39 +1464,43 @@ type TSDBOptions struct {
 }
 
 // QueryExemplars queries exemplars for traces
-func (c *Client) QueryExemplars(ctx context.Context, query, start, end string) (interface{}, error) {
+func (c *Client) QueryExemplars(ctx context.Context, query, start, end string) ([]v1.ExemplarQueryResult, error) {
 	if c.client == nil {
 		return nil, fmt.Errorf("prometheus client not initialized")
 	}
+	if err := c.requireFeature(ctx, featureExemplars); err != nil {
+		return nil, err
+	}
 
 	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
 	defer cancel()
 
 	// Parse start time
-	startTime, err := time.Parse(time.RFC3339, start)
+	startTime, err := parseTimeParam(start)
 	if err != nil {
 		return nil, fmt.Errorf("invalid start time: %w", err)
 	}
 
 	// Parse end time
-	endTime, err := time.Parse(time.RFC3339, end)
+	endTime, err := parseTimeParam(end)
 	if err != nil {
 		return nil, fmt.Errorf("invalid end time: %w", err)
 	}
 
 	exemplars, err := c.client.QueryExemplars(ctx, query, startTime, endTime)
 	if err != nil {
-		return nil, fmt.Errorf("failed to query exemplars: %w", err)
+		return nil, fmt.Errorf("failed to query exemplars: %w", toAPIError(err))
 	}
 
 	return exemplars, nil
 }
 
-// HealthStatus represents the result of a readiness check
+// HealthStatus represents the result of a health or readiness check
 type HealthStatus struct {
-	Ready      bool   `json:"ready"`
-	StatusCode int    `json:"statusCode"`
-	Message    string `json:"message"`
+	Ready      bool          `json:"ready"`
+	StatusCode int           `json:"statusCode"`
+	Message    string        `json:"message"`
+	Latency    time.Duration `json:"latency"`
 }
 
 // CheckReady checks whether the Prometheus/Mimir server is ready to serve traffic.
@@ -879,7 +1513,8 @@ type HealthStatus struct {
 // Using the scheme+host (not the full path) avoids the common misconfiguration
 // where PROMETHEUS_URL is set to http://mimir-gateway/prometheus and appending
 // /-/ready produces .../prometheus/-/ready, which the Mimir gateway has no
-// route for.
+// route for. An explicit PROMETHEUS_PATH_PREFIX is still honoured, since it
+// names where Prometheus itself is served.
 func (c *Client) CheckReady(ctx context.Context) (*HealthStatus, error) {
 	if c.httpClient == nil {
 		return nil, fmt.Errorf("prometheus client not initialized")
@@ -889,9 +1524,9 @@ func (c *Client) CheckReady(ctx context.Context) (*HealthStatus, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse Prometheus URL: %w", err)
 	}
-	base := parsed.Scheme + "://" + parsed.Host
+	base := joinPathPrefix(parsed.Scheme+"://"+parsed.Host, c.config.PathPrefix)
 
-	status, err := c.doReadyCheck(ctx, base+"/-/ready")
+	status, err := c.doHealthCheck(ctx, base+"/-/ready")
 	if err != nil {
 		return nil, err
 	}
@@ -899,33 +1534,201 @@ func (c *Client) CheckReady(ctx context.Context) (*HealthStatus, error) {
 	// nginx gateway endpoint instead.
 	if status.StatusCode == http.StatusNotFound {
 		c.logger.Debug("/-/ready returned 404, falling back to /ready (Mimir gateway)")
-		return c.doReadyCheck(ctx, base+"/ready")
+		return c.doHealthCheck(ctx, base+"/ready")
 	}
 	return status, nil
 }
 
-// doReadyCheck performs a single GET request to the given readiness URL.
-func (c *Client) doReadyCheck(ctx context.Context, readyURL string) (*HealthStatus, error) {
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, readyURL, nil)
+// CheckHealthy checks whether the Prometheus process is up with GET
+// /-/healthy against the same base URL as CheckReady. Ready in the result
+// reports a healthy server. The Mimir gateway has no such endpoint and
+// answers 404.
+func (c *Client) CheckHealthy(ctx context.Context) (*HealthStatus, error) {
+	if c.httpClient == nil {
+		return nil, fmt.Errorf("prometheus client not initialized")
+	}
+
+	parsed, err := url.Parse(c.config.URL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse Prometheus URL: %w", err)
+	}
+	return c.doHealthCheck(ctx, joinPathPrefix(parsed.Scheme+"://"+parsed.Host, c.config.PathPrefix)+"/-/healthy")
+}
+
+// Reload asks Prometheus to reload its configuration with POST /-/reload,
+// which needs the lifecycle API (--web.enable-lifecycle). It returns once
+// the reload has finished; a configuration that fails to load is reported
+// as an error and leaves the previous one running. Transient failures are
+// retried, each attempt within httpRequestTimeout.
+func (c *Client) Reload(ctx context.Context) error {
+	if c.adminHTTPClient == nil {
+		return fmt.Errorf("prometheus client not initialized")
+	}
+
+	parsed, err := url.Parse(c.config.URL)
+	if err != nil {
+		return fmt.Errorf("failed to parse Prometheus URL: %w", err)
+	}
+	reloadURL := joinPathPrefix(parsed.Scheme+"://"+parsed.Host, c.config.PathPrefix) + "/-/reload"
+
+	ctx, cancel := context.WithTimeout(ctx, c.adminRequestTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, reloadURL, http.NoBody)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create readiness check request: %w", err)
+		return fmt.Errorf("failed to create reload request: %w", err)
 	}
+	resp, err := c.adminHTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("reload request failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		if resp.StatusCode == http.StatusForbidden {
+			return fmt.Errorf("failed to reload configuration: %w (is Prometheus started with --web.enable-lifecycle?)", httpStatusError(resp.StatusCode, body))
+		}
+		return fmt.Errorf("failed to reload configuration: %w", httpStatusError(resp.StatusCode, body))
+	}
+	return nil
+}
+
+// doHealthCheck performs a single GET request to the given health or
+// readiness URL and times it.
+func (c *Client) doHealthCheck(ctx context.Context, checkURL string) (*HealthStatus, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, checkURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create health check request: %w", err)
+	}
+	start := time.Now()
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("readiness check request failed: %w", err)
+		return nil, fmt.Errorf("health check request failed: %w", err)
 	}
 	defer func() { _ = resp.Body.Close() }()
 	body, err := io.ReadAll(io.LimitReader(resp.Body, 1024))
 	if err != nil {
-		c.logger.Warn("Failed to read readiness response body", "error", err)
+		c.logger.Warn("Failed to read health check response body", "error", err)
 	}
 	return &HealthStatus{
 		Ready:      resp.StatusCode == http.StatusOK,
 		StatusCode: resp.StatusCode,
 		Message:    strings.TrimSpace(string(body)),
+		Latency:    time.Since(start),
 	}, nil
 }
 
+// PushMetric writes a single sample to a Prometheus remote write endpoint
+// (remote write 1.0, snappy-compressed protobuf). remoteWriteURL overrides
+// the configured PROMETHEUS_REMOTE_WRITE_URL when non-empty; it is refused
+// when the client has credentials. The client's authentication and org ID
+// headers are sent with the request.
+func (c *Client) PushMetric(ctx context.Context, remoteWriteURL, name string, labels map[string]string, value float64, ts time.Time) error {
+	if c.httpClient == nil {
+		return fmt.Errorf("prometheus client not initialized")
+	}
+	switch {
+	case remoteWriteURL == "":
+		remoteWriteURL = c.config.RemoteWriteURL
+	case remoteWriteURL != c.config.RemoteWriteURL && c.config.AuthType() != "none":
+		// The request carries the client's credentials, which are never
+		// sent to a URL the caller chose.
+		return fmt.Errorf("remote_write_url cannot be combined with %s credentials; set the remote write URL in the server configuration or a profile instead", c.config.AuthType())
+	}
+	if remoteWriteURL == "" {
+		return fmt.Errorf("no remote write URL configured: set PROMETHEUS_REMOTE_WRITE_URL or pass remote_write_url")
+	}
+
+	writeRequest, err := buildWriteRequest(name, labels, value, ts)
+	if err != nil {
+		return err
+	}
+	body, err := encodeWriteRequest(writeRequest)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, remoteWriteURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create remote write request: %w", err)
+	}
+	req.Header.Set("Content-Type", remoteWriteContentType)
+	req.Header.Set("Content-Encoding", remoteWriteEncoding)
+	req.Header.Set("X-Prometheus-Remote-Write-Version", remoteWriteVersion)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("remote write request failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("remote write failed: %w", httpStatusError(resp.StatusCode, msg))
+	}
+	return nil
+}
+
+// DeleteSeries deletes the data of the series matching matches between start
+// and end through the TSDB admin API, which Prometheus only serves with
+// --web.enable-admin-api. A zero start or end leaves that side of the range
+// open.
+func (c *Client) DeleteSeries(ctx context.Context, matches []string, start, end time.Time) error {
+	if c.client == nil {
+		return fmt.Errorf("prometheus client not initialized")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 60*time.Second)
+	defer cancel()
+
+	if err := c.client.DeleteSeries(ctx, matches, start, end); err != nil {
+		return fmt.Errorf("failed to delete series: %w", toAPIError(err))
+	}
+	return nil
+}
+
+// Snapshot creates a TSDB snapshot via POST /api/v1/admin/tsdb/snapshot and
+// returns its directory name under <data-dir>/snapshots. With skipHead the
+// data still in the head block is left out. The request is never retried, as
+// every attempt that reaches Prometheus writes another full snapshot.
+func (c *Client) Snapshot(ctx context.Context, skipHead bool) (string, error) {
+	if c.client == nil {
+		return "", fmt.Errorf("prometheus client not initialized")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+	defer cancel()
+
+	result, err := c.client.Snapshot(ctx, skipHead)
+	if err != nil {
+		return "", fmt.Errorf("failed to create snapshot: %w", toAPIError(err))
+	}
+	return result.Name, nil
+}
+
+// FormatQuery returns query pretty-printed by the server's
+// /api/v1/format_query endpoint.
+func (c *Client) FormatQuery(ctx context.Context, query string) (string, error) {
+	if c.client == nil {
+		return "", fmt.Errorf("prometheus client not initialized")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	data, err := c.client.FormatQuery(ctx, query)
+	if err != nil {
+		return "", fmt.Errorf("failed to format query: %w", toAPIError(err))
+	}
+
+	// client_golang returns the "data" field still JSON encoded.
+	var formatted string
+	if err := json.Unmarshal([]byte(data), &formatted); err != nil {
+		return "", fmt.Errorf("failed to decode formatted query: %w", err)
+	}
+	return formatted, nil
+}
+
 // GetTargetsMetadata gets metadata about metrics from specific targets
 func (c *Client) GetTargetsMetadata(ctx context.Context, matchTarget, metric, limit string) (interface{}, error) {
 	if c.client == nil {
@@ -937,7 +1740,7 @@ func (c *Client) GetTargetsMetadata(ctx context.Context, matchTarget, metric, li
 
 	targetsMetadata, err := c.client.TargetsMetadata(ctx, matchTarget, metric, limit)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get targets metadata: %w", err)
+		return nil, fmt.Errorf("failed to get targets metadata: %w", toAPIError(err))
 	}
 
 	return targetsMetadata, nil
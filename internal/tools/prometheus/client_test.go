@@ -3,10 +3,20 @@ package prometheus
 import (
 	"context"
 	"encoding/pem"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 
 	"github.com/giantswarm/mcp-prometheus/internal/server"
 )
@@ -140,6 +150,38 @@ func TestNewClientEmptyURL(t *testing.T) {
 
 // TestNewClientTLSSkipVerifyWithCustomCA verifies that setting both TLSSkipVerify
 // and TLSCACert simultaneously is a valid combination.
+func TestNewClientPathPrefix(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/custom/prometheus/api/v1/query", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(queryResponse))
+	})
+	mux.HandleFunc("/custom/prometheus/-/ready", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("Prometheus Server is Ready.\n"))
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	client, err := NewClient(server.PrometheusConfig{URL: srv.URL + "/", PathPrefix: "/custom//prometheus/"}, discardLogger())
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	if _, err := client.ExecuteQuery(context.Background(), "up", ""); err != nil {
+		t.Errorf("ExecuteQuery under path prefix: %v", err)
+	}
+	status, err := client.CheckReady(context.Background())
+	if err != nil || !status.Ready {
+		t.Errorf("CheckReady under path prefix: status %+v, err %v", status, err)
+	}
+}
+
+func TestNewClientInvalidPathPrefix(t *testing.T) {
+	_, err := NewClient(server.PrometheusConfig{URL: "http://localhost:9090", PathPrefix: "/prometheus/api"}, discardLogger())
+	if err == nil {
+		t.Fatal("expected an error for a path prefix ending in /api")
+	}
+}
+
 func TestNewClientTLSSkipVerifyWithCustomCA(t *testing.T) {
 	mockServer := httptest.NewTLSServer(http.HandlerFunc(tlsQueryHandler))
 	defer mockServer.Close()
@@ -187,3 +229,495 @@ func TestNewClientTLSUntrustedException(t *testing.T) {
 		t.Error("expected TLS error when connecting without a trusted CA, got nil")
 	}
 }
+
+// alertmanagersResponse mimics /api/v1/alertmanagers with one active and one
+// dropped Alertmanager.
+const alertmanagersResponse = `{"status":"success","data":{
+	"activeAlertmanagers":[{"url":"http://alertmanager-0.monitoring:9093/api/v2/alerts"},{"url":"http://alertmanager-1.monitoring:9093/api/v2/alerts"}],
+	"droppedAlertmanagers":[{"url":"http://old-alertmanager:9093/api/v1/alerts"}]}}`
+
+// TestDiscoverAlertmanagerURL verifies that the first active Alertmanager is
+// returned without the API path Prometheus appends.
+func TestDiscoverAlertmanagerURL(t *testing.T) {
+	calls := 0
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v1/alertmanagers" {
+			calls++
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(alertmanagersResponse))
+		} else {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer mockServer.Close()
+
+	client, err := NewClient(server.PrometheusConfig{URL: mockServer.URL}, discardLogger())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	const want = "http://alertmanager-0.monitoring:9093"
+	got, err := client.DiscoverAlertmanagerURL(context.Background())
+	if err != nil {
+		t.Fatalf("DiscoverAlertmanagerURL: %v", err)
+	}
+	if got != want {
+		t.Errorf("DiscoverAlertmanagerURL() = %q, want %q", got, want)
+	}
+
+	// AlertmanagerURL reuses the cached discovery result.
+	got, err = client.AlertmanagerURL(context.Background())
+	if err != nil || got != want {
+		t.Errorf("AlertmanagerURL() = %q, %v; want %q", got, err, want)
+	}
+	if calls != 1 {
+		t.Errorf("expected 1 discovery request, got %d", calls)
+	}
+}
+
+// TestAlertmanagerURLOverride verifies that a configured Alertmanager URL
+// takes precedence and Prometheus is not asked.
+func TestAlertmanagerURLOverride(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request to %s", r.URL.Path)
+	}))
+	defer mockServer.Close()
+
+	client, err := NewClient(server.PrometheusConfig{
+		URL:          mockServer.URL,
+		Alertmanager: server.AlertmanagerConfig{URL: "http://alertmanager.example.com"},
+	}, discardLogger())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got, err := client.AlertmanagerURL(context.Background())
+	if err != nil || got != "http://alertmanager.example.com" {
+		t.Errorf("AlertmanagerURL() = %q, %v", got, err)
+	}
+}
+
+// TestAlertmanagerRediscovery verifies that the discovered Alertmanager URL
+// is discovered again after a connection error and once it expires.
+func TestAlertmanagerRediscovery(t *testing.T) {
+	alertmanager := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`[]`))
+	}))
+	defer alertmanager.Close()
+	gone := httptest.NewServer(http.NotFoundHandler())
+	gone.Close()
+
+	var discoveries atomic.Int32
+	var current atomic.Value
+	current.Store(gone.URL)
+	prometheus := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		discoveries.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprintf(w, `{"status":"success","data":{"activeAlertmanagers":[{"url":"%s/api/v2/alerts"}],"droppedAlertmanagers":[]}}`, current.Load())
+	}))
+	defer prometheus.Close()
+
+	client, err := NewClient(server.PrometheusConfig{URL: prometheus.URL}, discardLogger())
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+
+	// The Alertmanager moved after it was discovered.
+	if _, err := client.ListAlertmanagerAlerts(context.Background(), AlertmanagerAlertsFilter{}); err == nil {
+		t.Fatal("expected an error from the unreachable Alertmanager")
+	}
+	current.Store(alertmanager.URL)
+	if _, err := client.ListAlertmanagerAlerts(context.Background(), AlertmanagerAlertsFilter{}); err != nil {
+		t.Fatalf("ListAlertmanagerAlerts after the move: %v", err)
+	}
+	if got := discoveries.Load(); got != 2 {
+		t.Errorf("discovered %d times, want 2", got)
+	}
+
+	// A fresh URL is reused; an expired one is discovered again.
+	if _, err := client.AlertmanagerURL(context.Background()); err != nil {
+		t.Fatalf("AlertmanagerURL: %v", err)
+	}
+	client.alertmanagerMu.Lock()
+	client.alertmanagerExpires = time.Now()
+	client.alertmanagerMu.Unlock()
+	if _, err := client.AlertmanagerURL(context.Background()); err != nil {
+		t.Fatalf("AlertmanagerURL: %v", err)
+	}
+	if got := discoveries.Load(); got != 3 {
+		t.Errorf("discovered %d times, want 3", got)
+	}
+}
+
+// TestAlertmanagerDiscoveryWithCredentials verifies that configured
+// credentials are never sent to a discovered Alertmanager.
+func TestAlertmanagerDiscoveryWithCredentials(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request to %s", r.URL.Path)
+	}))
+	defer mockServer.Close()
+
+	for name, config := range map[string]server.PrometheusConfig{
+		"Prometheus credentials":   {URL: mockServer.URL, Token: "prom-token"},
+		"Alertmanager credentials": {URL: mockServer.URL, Alertmanager: server.AlertmanagerConfig{Token: "am-token"}},
+	} {
+		client, err := NewClient(config, discardLogger())
+		if err != nil {
+			t.Fatalf("NewClient: %v", err)
+		}
+		if _, err := client.AlertmanagerURL(context.Background()); err == nil || !strings.Contains(err.Error(), "ALERTMANAGER_URL") {
+			t.Errorf("%s: AlertmanagerURL error = %v, want a hint to set ALERTMANAGER_URL", name, err)
+		}
+	}
+}
+
+// TestDiscoverAlertmanagerURLNoneActive verifies the error when Prometheus
+// has no active Alertmanagers.
+func TestDiscoverAlertmanagerURLNoneActive(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"status":"success","data":{"activeAlertmanagers":[],"droppedAlertmanagers":[]}}`))
+	}))
+	defer mockServer.Close()
+
+	client, err := NewClient(server.PrometheusConfig{URL: mockServer.URL}, discardLogger())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := client.DiscoverAlertmanagerURL(context.Background()); err == nil {
+		t.Error("expected error when no Alertmanager is active")
+	}
+}
+
+func TestParseTimeParam(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  time.Time
+	}{
+		{name: "RFC3339", input: "2024-01-01T00:00:00Z", want: time.Unix(1704067200, 0)},
+		{name: "integer seconds", input: "1704067200", want: time.Unix(1704067200, 0)},
+		{name: "fractional seconds", input: "1704067200.500", want: time.Unix(1704067200, 500_000_000)},
+		{name: "three decimal places", input: "1704067200.123", want: time.Unix(1704067200, 123_000_000)},
+		{name: "millisecond prefix", input: "ms:1704067200500", want: time.Unix(1704067200, 500_000_000)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseTimeParam(tt.input)
+			if err != nil {
+				t.Fatalf("parseTimeParam(%q): %v", tt.input, err)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("parseTimeParam(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+			if got.Nanosecond() != tt.want.Nanosecond() {
+				t.Errorf("nanoseconds = %d, want %d", got.Nanosecond(), tt.want.Nanosecond())
+			}
+		})
+	}
+}
+
+func TestParseTimeParamInvalid(t *testing.T) {
+	for _, input := range []string{"next week", "ms:", "ms:1.5", "NaN", "1704067200.5x"} {
+		if _, err := parseTimeParam(input); err == nil {
+			t.Errorf("parseTimeParam(%q) expected error", input)
+		}
+	}
+}
+
+func TestFindSeriesAcceptsUnixTimestamps(t *testing.T) {
+	var start, end string
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		start, end = r.Form.Get("start"), r.Form.Get("end")
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"status":"success","data":[]}`))
+	}))
+	defer mockServer.Close()
+
+	client, err := NewClient(server.PrometheusConfig{URL: mockServer.URL, DisableAPIVersionNegotiation: true}, discardLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.FindSeries(context.Background(), []string{"up"}, SeriesOptions{StartTime: "1704067200.5", EndTime: "ms:1704070800000"}); err != nil {
+		t.Fatalf("FindSeries: %v", err)
+	}
+	if start != "1704067200.5" || end != "1704070800" {
+		t.Errorf("start, end = %q, %q; want 1704067200.5, 1704070800", start, end)
+	}
+
+	if _, err := client.ListLabelNames(context.Background(), LabelOptions{StartTime: "not a time"}); err == nil || !strings.Contains(err.Error(), "invalid start time") {
+		t.Errorf("ListLabelNames() error = %v, want an invalid start time", err)
+	}
+}
+
+func TestClientTracesPrometheusRequests(t *testing.T) {
+	var (
+		mu           sync.Mutex
+		traceparents []string
+	)
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		traceparents = append(traceparents, r.Header.Get("traceparent"))
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case apiQueryPath:
+			_, _ = w.Write([]byte(queryResponse))
+		case "/api/v1/labels":
+			_, _ = w.Write([]byte(`{"status":"success","data":["__name__","job"]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer mockServer.Close()
+
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	defer func() { _ = tp.Shutdown(context.Background()) }()
+
+	sc, err := server.NewServerContext(context.Background(),
+		server.WithPrometheusConfig(server.PrometheusConfig{URL: mockServer.URL}),
+		server.WithTracerProvider(tp),
+		server.WithSlogLogger(discardLogger()),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create server context: %v", err)
+	}
+	defer func() { _ = sc.Shutdown() }()
+
+	client, err := NewClient(sc.PrometheusConfig(), sc.Logger())
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		handler  func(context.Context, mcp.CallToolRequest, *Client, *server.ServerContext) (*mcp.CallToolResult, error)
+		args     map[string]any
+		wantSpan string
+	}{
+		{"execute_query", handleExecuteQuery, map[string]any{paramKeyQuery: "up"}, spanNameQuery},
+		{"list_label_names", handleListLabelNames, map[string]any{}, spanNameMetadata},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exporter.Reset()
+			mu.Lock()
+			traceparents = nil
+			mu.Unlock()
+
+			ctx, toolSpan := tp.Tracer("test").Start(context.Background(), "mcp.tool/"+tt.name)
+			request := mcp.CallToolRequest{Params: mcp.CallToolParams{Name: tt.name, Arguments: tt.args}}
+			result, err := tt.handler(ctx, request, client, sc)
+			toolSpan.End()
+			if err != nil || result.IsError {
+				t.Fatalf("tool call failed: %v %v", err, result)
+			}
+
+			var span *tracetest.SpanStub
+			for _, s := range exporter.GetSpans() {
+				if s.Name == tt.wantSpan {
+					span = &s
+				}
+			}
+			if span == nil {
+				t.Fatalf("no %s span recorded, got %v", tt.wantSpan, exporter.GetSpans())
+			}
+			if span.Parent.SpanID() != toolSpan.SpanContext().SpanID() {
+				t.Errorf("span parent = %s, want tool span %s", span.Parent.SpanID(), toolSpan.SpanContext().SpanID())
+			}
+
+			attrs := map[attribute.Key]attribute.Value{}
+			for _, kv := range span.Attributes {
+				attrs[kv.Key] = kv.Value
+			}
+			if got := attrs["http.response.status_code"].AsInt64(); got != http.StatusOK {
+				t.Errorf("http.response.status_code = %d, want 200", got)
+			}
+			if _, ok := attrs["prometheus.request.duration_ms"]; !ok {
+				t.Errorf("missing prometheus.request.duration_ms attribute: %v", span.Attributes)
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			if len(traceparents) == 0 {
+				t.Fatal("Prometheus received no requests")
+			}
+			wantTraceID := toolSpan.SpanContext().TraceID().String()
+			for _, header := range traceparents {
+				if !strings.Contains(header, wantTraceID) {
+					t.Errorf("traceparent %q does not carry trace ID %s", header, wantTraceID)
+				}
+			}
+		})
+	}
+}
+
+func TestPrometheusSpanName(t *testing.T) {
+	tests := map[string]string{
+		"/api/v1/query":                   spanNameQuery,
+		"/prefix/api/v1/query_range":      spanNameQuery,
+		"/api/v1/query_exemplars":         spanNameQuery,
+		"/api/v1/labels":                  spanNameMetadata,
+		"/api/v1/metadata":                spanNameMetadata,
+		"/api/v1/status/buildinfo":        spanNameMetadata,
+		"/prometheus/api/v1/label/x/vals": spanNameMetadata,
+	}
+	for path, want := range tests {
+		if got := prometheusSpanName(path); got != want {
+			t.Errorf("prometheusSpanName(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
+
+func TestExecuteQueryAbortsOnCancelledCallContext(t *testing.T) {
+	received := make(chan struct{})
+	aborted := make(chan struct{})
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The server only notices the client going away once the query form
+		// has been read.
+		_ = r.ParseForm()
+		close(received)
+		<-r.Context().Done()
+		close(aborted)
+	}))
+	defer mockServer.Close()
+
+	sc, err := server.NewServerContext(context.Background(),
+		server.WithPrometheusConfig(server.PrometheusConfig{URL: mockServer.URL, DisableAPIVersionNegotiation: true}),
+		server.WithSlogLogger(discardLogger()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = sc.Shutdown() }()
+	client, err := NewClient(sc.PrometheusConfig(), sc.Logger())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-received
+		cancel()
+	}()
+	request := mcp.CallToolRequest{Params: mcp.CallToolParams{Name: "execute_query", Arguments: map[string]any{"query": "up"}}}
+	result, err := handleExecuteQuery(ctx, request, client, sc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.IsError {
+		t.Errorf("expected an error result for a cancelled call, got %v", result.Content)
+	}
+
+	select {
+	case <-aborted:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the Prometheus request was not aborted")
+	}
+}
+
+func TestClientsShareRequestLimiter(t *testing.T) {
+	var mu sync.Mutex
+	inFlight, peak, total := 0, 0, 0
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		inFlight++
+		total++
+		peak = max(peak, inFlight)
+		mu.Unlock()
+		time.Sleep(20 * time.Millisecond)
+		mu.Lock()
+		inFlight--
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(queryResponse))
+	}))
+	defer mockServer.Close()
+
+	limiter, err := server.NewRequestLimiter(0, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sc, err := server.NewServerContext(context.Background(),
+		server.WithPrometheusConfig(server.PrometheusConfig{URL: mockServer.URL, DisableAPIVersionNegotiation: true}),
+		server.WithSlogLogger(discardLogger()),
+		server.WithRequestLimiter(limiter),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = sc.Shutdown() }()
+
+	// Separate clients, as created for per-request parameters, share the
+	// server's limit.
+	var wg sync.WaitGroup
+	for range 4 {
+		client, err := NewClient(sc.PrometheusConfig(), sc.Logger())
+		if err != nil {
+			t.Fatal(err)
+		}
+		wg.Go(func() {
+			if _, err := client.ExecuteQuery(context.Background(), "up", ""); err != nil {
+				t.Errorf("ExecuteQuery: %v", err)
+			}
+		})
+	}
+	wg.Wait()
+
+	if total != 4 || peak != 1 {
+		t.Errorf("got %d requests with up to %d in flight, want 4 with 1", total, peak)
+	}
+}
+
+func TestRequestLimiterCountsRetries(t *testing.T) {
+	var requests atomic.Int32
+	mockServer := statusSequence(t, &requests, http.StatusServiceUnavailable, http.StatusOK)
+
+	// One request per second: the first attempt uses it up, so a retry
+	// within the deadline must be refused by the limiter.
+	limiter, err := server.NewRequestLimiter(1, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, err := NewClient(server.PrometheusConfig{
+		URL:                          mockServer.URL,
+		DisableAPIVersionNegotiation: true,
+		RequestLimiter:               limiter,
+	}, discardLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+	if err := client.Reload(ctx); err == nil {
+		t.Error("expected the reload to fail")
+	}
+	if got := requests.Load(); got != 1 {
+		t.Errorf("got %d requests, want 1", got)
+	}
+}
+
+func TestClientOwnsTransportOnlyWithTLSSettings(t *testing.T) {
+	shared, err := NewClient(server.PrometheusConfig{URL: "http://prometheus:9090"}, discardLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if shared.transport != nil {
+		t.Error("expected a client without TLS settings to share http.DefaultTransport")
+	}
+	shared.CloseIdleConnections()
+
+	own, err := NewClient(server.PrometheusConfig{URL: "https://prometheus:9090", TLSSkipVerify: true}, discardLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if own.transport == nil || own.transport == http.DefaultTransport {
+		t.Error("expected a client with TLS settings to own its transport")
+	}
+	own.CloseIdleConnections()
+}
@@ -0,0 +1,305 @@
+package prometheus
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	v1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/prometheus/common/model"
+
+	"github.com/giantswarm/mcp-prometheus/internal/server"
+)
+
+const (
+	// toolCollectionSummary is the registered name of the on-call summary
+	// report tool.
+	toolCollectionSummary = "collection_summary"
+
+	// collectionSummaryMaxLength keeps the report small enough to pass to an
+	// LLM without truncation.
+	collectionSummaryMaxLength = 5000
+
+	// summaryTopMetrics is the number of highest-cardinality metrics listed.
+	summaryTopMetrics = 5
+
+	// summaryUnhealthyTargets is the number of failing targets listed.
+	summaryUnhealthyTargets = 5
+
+	// engineStatsQuery reads the storage size and query engine metrics that
+	// Prometheus exposes about itself. Mimir and Prometheus instances that do
+	// not scrape themselves return nothing.
+	engineStatsQuery = `prometheus_tsdb_storage_blocks_bytes or prometheus_engine_queries or prometheus_engine_queries_concurrent_max or prometheus_engine_query_duration_seconds{slice="inner_eval",quantile="0.9"}`
+)
+
+// collectionSummary holds the data behind a collection_summary report. Each
+// source has its own error; a failed source only empties its section.
+type collectionSummary struct {
+	targets    *TargetsResult
+	targetsErr error
+
+	alerts    v1.AlertsResult
+	alertsErr error
+
+	tsdb    v1.TSDBResult
+	tsdbErr error
+
+	runtime    RuntimeInfo
+	runtimeErr error
+	build      v1.BuildinfoResult
+	buildErr   error
+
+	engine    model.Vector
+	engineErr error
+}
+
+// fetchCollectionSummary queries the summary sources in five concurrent
+// goroutines.
+func fetchCollectionSummary(ctx context.Context, client *Client) *collectionSummary {
+	var s collectionSummary
+	var wg sync.WaitGroup
+	wg.Go(func() {
+		s.targets, s.targetsErr = client.GetTargets(ctx)
+	})
+	wg.Go(func() {
+		var alerts any
+		if alerts, s.alertsErr = client.GetAlerts(ctx); s.alertsErr == nil {
+			s.alerts, _ = alerts.(v1.AlertsResult)
+		}
+	})
+	wg.Go(func() {
+		var stats any
+		if stats, s.tsdbErr = client.GetTSDBStats(ctx, TSDBOptions{Limit: fmt.Sprint(summaryTopMetrics)}); s.tsdbErr == nil {
+			s.tsdb, _ = stats.(v1.TSDBResult)
+		}
+	})
+	wg.Go(func() {
+		s.runtime, s.runtimeErr = client.GetRuntimeInfo(ctx)
+		s.build, s.buildErr = client.GetBuildInfo(ctx)
+	})
+	wg.Go(func() {
+		var result *QueryResult
+		if result, s.engineErr = client.ExecuteQuery(ctx, engineStatsQuery, ""); s.engineErr == nil {
+			s.engine, _ = result.Result.(model.Vector)
+		}
+	})
+	wg.Wait()
+	return &s
+}
+
+// summaryHeading renders a section heading line.
+func summaryHeading(title string) string {
+	return "\n=== " + title + " ===\n"
+}
+
+// writeTargetsSection summarises target health overall and lists up to
+// summaryUnhealthyTargets failing targets.
+func (s *collectionSummary) writeTargetsSection(b *strings.Builder) {
+	b.WriteString(summaryHeading("Scrape targets"))
+	if s.targetsErr != nil {
+		fmt.Fprintf(b, "unavailable: %v\n", s.targetsErr)
+		return
+	}
+
+	healthy := 0
+	for _, p := range summarizeScrapePools(s.targets.ActiveTargets) {
+		healthy += p.healthy
+	}
+	total := len(s.targets.ActiveTargets)
+	fmt.Fprintf(b, "%d active targets: %d up, %d down; %d dropped\n", total, healthy, total-healthy, len(s.targets.DroppedTargets))
+
+	listed := 0
+	for _, t := range s.targets.ActiveTargets {
+		target, _ := t.(map[string]interface{})
+		if health, _ := target["health"].(v1.HealthStatus); health == v1.HealthGood {
+			continue
+		}
+		if listed == summaryUnhealthyTargets {
+			fmt.Fprintf(b, "  ... and %d more unhealthy targets\n", total-healthy-listed)
+			break
+		}
+		pool, _ := target["scrapePool"].(string)
+		url, _ := target["scrapeUrl"].(string)
+		lastError, _ := target["lastError"].(string)
+		fmt.Fprintf(b, "  %s %s: %s\n", pool, url, lastError)
+		listed++
+	}
+}
+
+// writeAlertsSection counts firing and pending alerts by severity label.
+func (s *collectionSummary) writeAlertsSection(b *strings.Builder) {
+	b.WriteString(summaryHeading("Active alerts"))
+	if s.alertsErr != nil {
+		fmt.Fprintf(b, "unavailable: %v\n", s.alertsErr)
+		return
+	}
+	if len(s.alerts.Alerts) == 0 {
+		b.WriteString("none\n")
+		return
+	}
+
+	bySeverity := map[string][2]int{} // firing, pending
+	for _, a := range s.alerts.Alerts {
+		severity := string(a.Labels["severity"])
+		if severity == "" {
+			severity = "(no severity)"
+		}
+		counts := bySeverity[severity]
+		if a.State == v1.AlertStateFiring {
+			counts[0]++
+		} else {
+			counts[1]++
+		}
+		bySeverity[severity] = counts
+	}
+	fmt.Fprintf(b, "%d alerts\n", len(s.alerts.Alerts))
+	for _, severity := range slices.Sorted(maps.Keys(bySeverity)) {
+		counts := bySeverity[severity]
+		fmt.Fprintf(b, "  %s: %d firing, %d pending\n", severity, counts[0], counts[1])
+	}
+}
+
+// writeCardinalitySection lists the metrics with the most series.
+func (s *collectionSummary) writeCardinalitySection(b *strings.Builder) {
+	b.WriteString(summaryHeading(fmt.Sprintf("Top %d metrics by series count", summaryTopMetrics)))
+	if s.tsdbErr != nil {
+		fmt.Fprintf(b, "unavailable: %v\n", s.tsdbErr)
+		return
+	}
+	stats := s.tsdb.SeriesCountByMetricName
+	if len(stats) == 0 {
+		b.WriteString("no data\n")
+		return
+	}
+	for i, stat := range stats[:min(summaryTopMetrics, len(stats))] {
+		fmt.Fprintf(b, "  %d. %s: %d series\n", i+1, stat.Name, stat.Value)
+	}
+}
+
+// writeStorageSection reports the TSDB head, retention and block size.
+func (s *collectionSummary) writeStorageSection(b *strings.Builder) {
+	b.WriteString(summaryHeading("TSDB storage"))
+	if s.tsdbErr == nil {
+		head := s.tsdb.HeadStats
+		fmt.Fprintf(b, "head: %d series, %d chunks\n", head.NumSeries, head.ChunkCount)
+	}
+	if s.runtimeErr != nil {
+		fmt.Fprintf(b, "runtime info unavailable: %v\n", s.runtimeErr)
+	} else {
+		fmt.Fprintf(b, "retention: %s\n", s.runtime.StorageRetention)
+		if s.runtime.CorruptionCount > 0 {
+			fmt.Fprintf(b, "⚠️ corrupted blocks: %d\n", s.runtime.CorruptionCount)
+		}
+	}
+	if v, ok := engineStat(s.engine, "prometheus_tsdb_storage_blocks_bytes"); ok {
+		fmt.Fprintf(b, "block storage: %s\n", formatBytes(int64(v)))
+	}
+}
+
+// writeEngineSection reports the query engine metrics, if scraped.
+func (s *collectionSummary) writeEngineSection(b *strings.Builder) {
+	b.WriteString(summaryHeading("Query engine"))
+	if s.engineErr != nil {
+		fmt.Fprintf(b, "unavailable: %v\n", s.engineErr)
+		return
+	}
+	queries, hasQueries := engineStat(s.engine, "prometheus_engine_queries")
+	maxQueries, hasMax := engineStat(s.engine, "prometheus_engine_queries_concurrent_max")
+	p90, hasP90 := engineStat(s.engine, "prometheus_engine_query_duration_seconds")
+	if !hasQueries && !hasMax && !hasP90 {
+		b.WriteString("not available (engine metrics are not scraped)\n")
+		return
+	}
+	if hasQueries || hasMax {
+		fmt.Fprintf(b, "running queries: %.0f of %.0f max concurrent\n", queries, maxQueries)
+	}
+	if hasP90 {
+		fmt.Fprintf(b, "p90 inner evaluation time: %.3fs\n", p90)
+	}
+}
+
+// writeBuildSection reports the Prometheus version.
+func (s *collectionSummary) writeBuildSection(b *strings.Builder) {
+	b.WriteString(summaryHeading("Build"))
+	if s.buildErr != nil {
+		fmt.Fprintf(b, "unavailable: %v\n", s.buildErr)
+		return
+	}
+	fmt.Fprintf(b, "version %s (revision %s, %s)\n", s.build.Version, s.build.Revision, s.build.GoVersion)
+}
+
+// engineStat returns the largest value of the named metric in v; several
+// values exist when multiple Prometheus instances are scraped.
+func engineStat(v model.Vector, name string) (float64, bool) {
+	found, value := false, 0.0
+	for _, s := range v {
+		if string(s.Metric[model.MetricNameLabel]) != name {
+			continue
+		}
+		if !found || float64(s.Value) > value {
+			value = float64(s.Value)
+		}
+		found = true
+	}
+	return value, found
+}
+
+// formatCollectionSummary renders the report, cut to
+// collectionSummaryMaxLength bytes.
+func formatCollectionSummary(s *collectionSummary) string {
+	var b strings.Builder
+	b.WriteString("Prometheus collection summary\n")
+	s.writeTargetsSection(&b)
+	s.writeAlertsSection(&b)
+	s.writeCardinalitySection(&b)
+	s.writeStorageSection(&b)
+	s.writeEngineSection(&b)
+	s.writeBuildSection(&b)
+
+	text := b.String()
+	if len(text) <= collectionSummaryMaxLength {
+		return text
+	}
+	const marker = "\n... (summary truncated)\n"
+	text = text[:collectionSummaryMaxLength-len(marker)]
+	for !utf8.ValidString(text) {
+		text = text[:len(text)-1]
+	}
+	return text + marker
+}
+
+// handleCollectionSummary handles the collection_summary tool. Sources that
+// fail are reported in their section instead of failing the report; the call
+// only fails when every source failed.
+func handleCollectionSummary(ctx context.Context, _ mcp.CallToolRequest, client *Client, sc *server.ServerContext) (*mcp.CallToolResult, error) {
+	sc.Logger().Debug("Building collection summary")
+
+	summary := fetchCollectionSummary(ctx, client)
+	if summary.targetsErr != nil && summary.alertsErr != nil && summary.tsdbErr != nil &&
+		summary.runtimeErr != nil && summary.buildErr != nil && summary.engineErr != nil {
+		sc.Logger().Error("Failed to build collection summary", "error", summary.buildErr)
+		return &mcp.CallToolResult{
+			IsError: true,
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: contentTypeText,
+					Text: fmt.Sprintf("Error building collection summary: %v", summary.buildErr),
+				},
+			},
+		}, nil
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.TextContent{
+				Type: contentTypeText,
+				Text: formatCollectionSummary(summary),
+			},
+		},
+	}, nil
+}
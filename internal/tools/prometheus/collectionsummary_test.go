@@ -0,0 +1,153 @@
+package prometheus
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/giantswarm/mcp-prometheus/internal/server"
+)
+
+// collectionSummaryResponses are the API responses of a healthy Prometheus
+// keyed by path.
+var collectionSummaryResponses = map[string]any{
+	"/api/v1/targets": map[string]any{
+		"activeTargets": []any{
+			map[string]any{"scrapePool": "node", "scrapeUrl": "http://node-1:9100/metrics", "health": "up", "lastError": "", "labels": map[string]string{}, "discoveredLabels": map[string]string{}},
+			map[string]any{"scrapePool": "node", "scrapeUrl": "http://node-2:9100/metrics", "health": "down", "lastError": "connection refused", "labels": map[string]string{}, "discoveredLabels": map[string]string{}},
+		},
+		"droppedTargets": []any{},
+	},
+	"/api/v1/alerts": map[string]any{"alerts": []any{
+		map[string]any{"labels": map[string]string{"alertname": "NodeDown", "severity": "critical"}, "state": "firing", "activeAt": "2024-01-01T00:00:00Z", "value": "1"},
+		map[string]any{"labels": map[string]string{"alertname": "DiskFilling", "severity": "warning"}, "state": "pending", "activeAt": "2024-01-01T00:00:00Z", "value": "1"},
+		map[string]any{"labels": map[string]string{"alertname": "HighLoad", "severity": "warning"}, "state": "firing", "activeAt": "2024-01-01T00:00:00Z", "value": "1"},
+	}},
+	"/api/v1/status/tsdb": map[string]any{
+		"headStats": map[string]any{"numSeries": 1200, "chunkCount": 3400, "minTime": 0, "maxTime": 0},
+		"seriesCountByMetricName": []any{
+			map[string]any{"name": "http_request_duration_seconds_bucket", "value": 800},
+			map[string]any{"name": "up", "value": 10},
+		},
+		"labelValueCountByLabelName":  []any{},
+		"memoryInBytesByLabelName":    []any{},
+		"seriesCountByLabelValuePair": []any{},
+	},
+	"/api/v1/status/runtimeinfo": map[string]any{"storageRetention": "15d", "corruptionCount": 0},
+	"/api/v1/status/buildinfo":   map[string]any{"version": "3.1.0", "revision": "abc123", "goVersion": "go1.23.4"},
+	apiQueryPath: map[string]any{respKeyResultType: respValVector, respKeyResult: []any{
+		map[string]any{"metric": map[string]string{"__name__": "prometheus_tsdb_storage_blocks_bytes"}, "value": []any{1704067200, "2147483648"}},
+		map[string]any{"metric": map[string]string{"__name__": "prometheus_engine_queries"}, "value": []any{1704067200, "2"}},
+		map[string]any{"metric": map[string]string{"__name__": "prometheus_engine_queries_concurrent_max"}, "value": []any{1704067200, "20"}},
+		map[string]any{"metric": map[string]string{"__name__": "prometheus_engine_query_duration_seconds"}, "value": []any{1704067200, "0.0125"}},
+	}},
+}
+
+func runCollectionSummary(t *testing.T, failing map[string]bool) *mcp.CallToolResult {
+	t.Helper()
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, ok := collectionSummaryResponses[r.URL.Path]
+		if !ok || failing[r.URL.Path] {
+			w.WriteHeader(http.StatusInternalServerError)
+			_, _ = w.Write([]byte(`{"status":"error","errorType":"internal","error":"unavailable"}`))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{respKeyStatus: respValSuccess, respKeyData: data})
+	}))
+	t.Cleanup(mockServer.Close)
+
+	ctx := context.Background()
+	sc, err := server.NewServerContext(ctx,
+		server.WithPrometheusConfig(server.PrometheusConfig{URL: mockServer.URL}),
+		server.WithSlogLogger(discardLogger()),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create server context: %v", err)
+	}
+	t.Cleanup(func() { _ = sc.Shutdown() })
+
+	client, err := NewClient(sc.PrometheusConfig(), sc.Logger())
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+
+	result, err := handleCollectionSummary(ctx, mcp.CallToolRequest{}, client, sc)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return result
+}
+
+func TestHandleCollectionSummary(t *testing.T) {
+	result := runCollectionSummary(t, nil)
+	text := result.Content[0].(mcp.TextContent).Text
+	if result.IsError {
+		t.Fatalf("expected success, got error: %s", text)
+	}
+
+	for _, want := range []string{
+		"=== Scrape targets ===\n2 active targets: 1 up, 1 down; 0 dropped\n  node http://node-2:9100/metrics: connection refused\n",
+		"=== Active alerts ===\n3 alerts\n  critical: 1 firing, 0 pending\n  warning: 1 firing, 1 pending\n",
+		"=== Top 5 metrics by series count ===\n  1. http_request_duration_seconds_bucket: 800 series\n  2. up: 10 series\n",
+		"=== TSDB storage ===\nhead: 1200 series, 3400 chunks\nretention: 15d\nblock storage: 2.0 GiB\n",
+		"=== Query engine ===\nrunning queries: 2 of 20 max concurrent\np90 inner evaluation time: 0.013s\n",
+		"=== Build ===\nversion 3.1.0 (revision abc123, go1.23.4)\n",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("summary missing %q:\n%s", want, text)
+		}
+	}
+	if len(text) > collectionSummaryMaxLength {
+		t.Errorf("summary is %d bytes, want at most %d", len(text), collectionSummaryMaxLength)
+	}
+}
+
+func TestHandleCollectionSummaryPartialFailure(t *testing.T) {
+	result := runCollectionSummary(t, map[string]bool{"/api/v1/alerts": true, apiQueryPath: true})
+	text := result.Content[0].(mcp.TextContent).Text
+	if result.IsError {
+		t.Fatalf("expected partial report, got error: %s", text)
+	}
+	for _, want := range []string{
+		"=== Active alerts ===\nunavailable: ",
+		"=== Query engine ===\nunavailable: ",
+		"=== Build ===\nversion 3.1.0",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("summary missing %q:\n%s", want, text)
+		}
+	}
+}
+
+func TestHandleCollectionSummaryAllFailed(t *testing.T) {
+	failing := map[string]bool{}
+	for path := range collectionSummaryResponses {
+		failing[path] = true
+	}
+	if result := runCollectionSummary(t, failing); !result.IsError {
+		t.Errorf("expected error when every source fails, got: %s", result.Content[0].(mcp.TextContent).Text)
+	}
+}
+
+func TestFormatCollectionSummaryTruncates(t *testing.T) {
+	var active []any
+	for i := range 500 {
+		active = append(active, map[string]any{"scrapePool": "pool", "scrapeUrl": fmt.Sprintf("http://host-%d/metrics", i), "lastError": strings.Repeat("é", 1000)})
+	}
+	summary := &collectionSummary{targets: &TargetsResult{ActiveTargets: active}}
+	text := formatCollectionSummary(summary)
+	if len(text) > collectionSummaryMaxLength {
+		t.Errorf("summary is %d bytes, want at most %d", len(text), collectionSummaryMaxLength)
+	}
+	if !utf8.ValidString(text) || !strings.HasSuffix(text, "... (summary truncated)\n") {
+		t.Errorf("expected a valid UTF-8 summary ending in the truncation marker, got tail %q", text[len(text)-40:])
+	}
+}
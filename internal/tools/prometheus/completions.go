@@ -0,0 +1,279 @@
+package prometheus
+
+import (
+	"context"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	mcpserver "github.com/mark3labs/mcp-go/server"
+	"github.com/prometheus/common/model"
+
+	"github.com/giantswarm/mcp-prometheus/internal/filter"
+	"github.com/giantswarm/mcp-prometheus/internal/server"
+)
+
+const (
+	// completionCacheTTL is how long metric names, label names and label
+	// values fetched for completions are reused. Completion requests arrive
+	// on every keystroke; the lists rarely change within a minute.
+	completionCacheTTL = time.Minute
+
+	// maxCompletionValues is the most values a completion response may hold
+	// under the MCP specification.
+	maxCompletionValues = 100
+)
+
+// completionProvider completes the arguments of the resource templates and
+// prompts from the configured Prometheus server, as the caller's tenant: metric with metric names,
+// label with label names, matches with metric names, label names or label
+// values depending on where the selector being typed ends, and the prompts'
+// alertname, job and namespace with label values.
+type completionProvider struct {
+	client *Client
+	sc     *server.ServerContext
+	cache  *ttlCache[[]string]
+}
+
+// registerCompletions enables the MCP completion capability on s for the
+// resource template and prompt arguments. client may be nil when no default
+// Prometheus URL is configured; completions are then always empty.
+func registerCompletions(s *mcpserver.MCPServer, client *Client, sc *server.ServerContext) {
+	p := &completionProvider{client: client, sc: sc, cache: newTTLCache[[]string](completionCacheTTL)}
+	mcpserver.WithCompletions()(s)
+	mcpserver.WithResourceCompletionProvider(p)(s)
+	mcpserver.WithPromptCompletionProvider(p)(s)
+}
+
+// promptArgumentLabels maps the prompt arguments completed with label values
+// to the label and the metric whose series they are looked up among.
+var promptArgumentLabels = map[string]struct{ label, metric string }{
+	"alertname": {"alertname", "ALERTS"},
+	"job":       {"job", ""},
+	"namespace": {"namespace", ""},
+}
+
+// CompletePromptArgument implements mcpserver.PromptCompletionProvider,
+// completing the alert names, jobs and namespaces of the investigation
+// prompts.
+func (p *completionProvider) CompletePromptArgument(ctx context.Context, prompt string, argument mcp.CompleteArgument, _ mcp.CompleteContext) (*mcp.Completion, error) {
+	var values []string
+	if l, ok := promptArgumentLabels[argument.Name]; ok && p.client != nil {
+		all, err := p.promptLabelValues(ctx, l.label, l.metric)
+		if err != nil {
+			p.sc.Logger().Warn("Failed to complete argument", "prompt", prompt, "argument", argument.Name, "error", err)
+		}
+		values = matchCompletions(all, argument.Value)
+	}
+	return newCompletion(values), nil
+}
+
+// promptLabelValues returns the values of label for the caller's tenant.
+func (p *completionProvider) promptLabelValues(ctx context.Context, label, metric string) ([]string, error) {
+	client, err := callerClient(ctx, p.client, p.sc)
+	if err != nil {
+		return nil, err
+	}
+	return p.labelValues(ctx, client, label, metric)
+}
+
+// CompleteResourceArgument implements mcpserver.ResourceCompletionProvider.
+// Failing to reach Prometheus yields no completions rather than an error, so
+// clients keep accepting input.
+func (p *completionProvider) CompleteResourceArgument(ctx context.Context, uri string, argument mcp.CompleteArgument, _ mcp.CompleteContext) (*mcp.Completion, error) {
+	if p.client == nil {
+		return newCompletion(nil), nil
+	}
+
+	client, err := callerClient(ctx, p.client, p.sc)
+	if err != nil {
+		p.sc.Logger().Warn("Failed to complete argument", "uri", uri, "argument", argument.Name, "error", err)
+		return newCompletion(nil), nil
+	}
+
+	var values []string
+	switch argument.Name {
+	case "metric":
+		values, err = p.completeMetric(ctx, client, "", argument.Value)
+	case "label":
+		values, err = p.completeLabel(ctx, client, "", argument.Value)
+	case "matches":
+		values, err = p.completeSelector(ctx, client, argument.Value)
+	}
+	if err != nil {
+		p.sc.Logger().Warn("Failed to complete argument", "uri", uri, "argument", argument.Name, "error", err)
+		values = nil
+	}
+
+	return newCompletion(values), nil
+}
+
+// newCompletion returns the first maxCompletionValues of values, flagging
+// the rest.
+func newCompletion(values []string) *mcp.Completion {
+	completion := &mcp.Completion{Values: values, Total: len(values)}
+	if len(values) > maxCompletionValues {
+		completion.Values = values[:maxCompletionValues]
+		completion.HasMore = true
+	}
+	if completion.Values == nil {
+		completion.Values = []string{}
+	}
+	return completion
+}
+
+// completeMetric completes partial with metric names, each prefixed with
+// base.
+func (p *completionProvider) completeMetric(ctx context.Context, client *Client, base, partial string) ([]string, error) {
+	names, err := p.cache.get(ctx, clientScopedKey(client, "metrics"), func(ctx context.Context) ([]string, error) {
+		result, err := client.ListLabelValues(ctx, model.MetricNameLabel, LabelOptions{})
+		if err != nil {
+			return nil, err
+		}
+		kept, _ := filter.Exclude(result.LabelValues, p.sc.ExcludedMetrics())
+		return kept, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return prefixAll(base, matchCompletions(names, partial)), nil
+}
+
+// completeLabel completes partial with label names, each prefixed with base.
+func (p *completionProvider) completeLabel(ctx context.Context, client *Client, base, partial string) ([]string, error) {
+	names, err := p.cache.get(ctx, clientScopedKey(client, "labels"), func(ctx context.Context) ([]string, error) {
+		result, err := client.ListLabelNames(ctx, LabelOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return result.LabelNames, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return prefixAll(base, matchCompletions(names, partial)), nil
+}
+
+// completeSelector completes a partially typed series selector such as
+// `up{job="ap`: the metric name before the braces, a label name inside them,
+// or the value of the label being matched. Label values are looked up among
+// the series of the selector's metric, if it names one.
+func (p *completionProvider) completeSelector(ctx context.Context, client *Client, selector string) ([]string, error) {
+	part := splitSelector(selector)
+	switch {
+	case part.closed:
+		return nil, nil
+	case !part.inBraces:
+		return p.completeMetric(ctx, client, part.base, part.partial)
+	case part.label == "":
+		return p.completeLabel(ctx, client, part.base, part.partial)
+	}
+
+	values, err := p.labelValues(ctx, client, part.label, part.metric)
+	if err != nil {
+		return nil, err
+	}
+	out := matchCompletions(values, part.partial)
+	for i, v := range out {
+		out[i] = part.base + strconv.Quote(v)
+	}
+	return out, nil
+}
+
+// labelValues returns the values of label, among the series of metric when
+// it is a metric name.
+func (p *completionProvider) labelValues(ctx context.Context, client *Client, label, metric string) ([]string, error) {
+	var matches []string
+	if model.IsValidLegacyMetricName(metric) {
+		matches = []string{metric}
+	}
+	return p.cache.get(ctx, clientScopedKey(client, "values\x00"+label+"\x00"+metric), func(ctx context.Context) ([]string, error) {
+		result, err := client.ListLabelValues(ctx, label, LabelOptions{Matches: matches})
+		if err != nil {
+			return nil, err
+		}
+		return result.LabelValues, nil
+	})
+}
+
+// selectorPart describes where a partially typed series selector ends.
+type selectorPart struct {
+	// metric is the metric name before the braces.
+	metric string
+	// inBraces is set when the selector ends inside its label matchers, and
+	// closed when they have been closed.
+	inBraces, closed bool
+	// label is the label whose value is being typed; empty when a metric or
+	// label name is.
+	label string
+	// partial is the text being completed, without an opening quote; base is
+	// everything before it.
+	base, partial string
+}
+
+// splitSelector splits a partially typed series selector into the text
+// completions keep and the token they complete.
+func splitSelector(selector string) selectorPart {
+	open := strings.IndexByte(selector, '{')
+	if open < 0 {
+		return selectorPart{metric: selector, partial: selector}
+	}
+	part := selectorPart{metric: strings.TrimSpace(selector[:open]), inBraces: true}
+
+	// Find the start of the last matcher, skipping commas in quoted values.
+	start, inQuote := open+1, false
+	for i := open + 1; i < len(selector); i++ {
+		switch c := selector[i]; {
+		case inQuote && c == '\\':
+			i++
+		case c == '"':
+			inQuote = !inQuote
+		case !inQuote && c == ',':
+			start = i + 1
+		case !inQuote && c == '}':
+			part.closed = true
+			return part
+		}
+	}
+
+	matcher := selector[start:]
+	opStart := strings.IndexAny(matcher, "=!")
+	if opStart < 0 {
+		part.partial = strings.TrimSpace(matcher)
+		part.base = selector[:len(selector)-len(part.partial)]
+		return part
+	}
+	part.label = strings.TrimSpace(matcher[:opStart])
+	value := strings.TrimLeft(matcher[opStart:], "=!~ ")
+	part.base = selector[:len(selector)-len(value)]
+	if unquoted, ok := strings.CutPrefix(value, `"`); ok {
+		value = unquoted
+	}
+	part.partial = value
+	return part
+}
+
+// matchCompletions returns the candidates starting with partial followed by
+// those containing it elsewhere, each group in candidate order.
+func matchCompletions(candidates []string, partial string) []string {
+	var prefixed, contained []string
+	for _, c := range candidates {
+		switch {
+		case strings.HasPrefix(c, partial):
+			prefixed = append(prefixed, c)
+		case strings.Contains(c, partial):
+			contained = append(contained, c)
+		}
+	}
+	return slices.Concat(prefixed, contained)
+}
+
+// prefixAll prepends base to each of values in place.
+func prefixAll(base string, values []string) []string {
+	for i, v := range values {
+		values[i] = base + v
+	}
+	return values
+}
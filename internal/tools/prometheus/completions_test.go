@@ -0,0 +1,267 @@
+package prometheus
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/giantswarm/mcp-oauth/handler"
+	"github.com/giantswarm/mcp-oauth/providers"
+	"github.com/mark3labs/mcp-go/mcp"
+	mcpserver "github.com/mark3labs/mcp-go/server"
+
+	"github.com/giantswarm/mcp-prometheus/internal/server"
+)
+
+// newCompletionTestServer serves metric names, label names, job values (only
+// "api" for series of http_requests_total), metadata and series. requests
+// counts the label API calls.
+func newCompletionTestServer(t *testing.T, requests *atomic.Int32) *mcpserver.MCPServer {
+	t.Helper()
+
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		var data string
+		switch r.URL.Path {
+		case "/api/v1/label/__name__/values":
+			requests.Add(1)
+			data = `["go_goroutines","http_requests_total","process_cpu_seconds_total","up"]`
+		case "/api/v1/labels":
+			requests.Add(1)
+			data = `["__name__","instance","job"]`
+		case "/api/v1/label/job/values":
+			requests.Add(1)
+			data = `["api","node-exporter","prometheus"]`
+			if r.URL.Query().Get("match[]") == "http_requests_total" {
+				data = `["api"]`
+			}
+		case "/api/v1/metadata":
+			data = `{"http_requests_total":[{"type":"counter","help":"Total HTTP requests.","unit":""}]}`
+		case "/api/v1/series":
+			data = `[{"__name__":"up","job":"api","instance":"a:80"},{"__name__":"up","job":"api","instance":"b:80"}]`
+		default:
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(`{"status":"success","data":` + data + `}`))
+	}))
+	t.Cleanup(mockServer.Close)
+
+	sc, err := server.NewServerContext(context.Background(),
+		server.WithPrometheusConfig(server.PrometheusConfig{URL: mockServer.URL}),
+		server.WithSlogLogger(discardLogger()),
+		server.WithExcludedMetrics([]string{"go_*"}),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create server context: %v", err)
+	}
+	t.Cleanup(func() { _ = sc.Shutdown() })
+
+	s := mcpserver.NewMCPServer("test", "1.0.0")
+	if err := RegisterPrometheusTools(s, sc); err != nil {
+		t.Fatalf("RegisterPrometheusTools: %v", err)
+	}
+	return s
+}
+
+// complete requests completions of argument for the resource template uri
+// through the MCP message handler, as a client would.
+func complete(t *testing.T, s *mcpserver.MCPServer, uri, argument, value string) []string {
+	t.Helper()
+
+	params, err := json.Marshal(map[string]any{
+		"ref":      map[string]string{"type": "ref/resource", "uri": uri},
+		"argument": map[string]string{"name": argument, "value": value},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := `{"jsonrpc":"2.0","id":1,"method":"completion/complete","params":` + string(params) + `}`
+	data, err := json.Marshal(s.HandleMessage(context.Background(), []byte(msg)))
+	if err != nil {
+		t.Fatalf("marshal response: %v", err)
+	}
+
+	var decoded struct {
+		Result *struct {
+			Completion struct {
+				Values []string `json:"values"`
+			} `json:"completion"`
+		} `json:"result"`
+	}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("unmarshal response: %v", err)
+	}
+	if decoded.Result == nil {
+		t.Fatalf("completion failed: %s", data)
+	}
+	return decoded.Result.Completion.Values
+}
+
+func TestResourceCompletions(t *testing.T) {
+	var requests atomic.Int32
+	s := newCompletionTestServer(t, &requests)
+
+	tests := []struct {
+		name     string
+		uri      string
+		argument string
+		value    string
+		want     []string
+	}{
+		{"metric prefix then substring", resourceMetricTemplate, "metric", "p", []string{"process_cpu_seconds_total", "http_requests_total", "up"}},
+		{"excluded metrics", resourceMetricTemplate, "metric", "go", nil},
+		{"label", resourceLabelValuesTemplate, "label", "j", []string{"job"}},
+		{"selector metric", resourceSeriesTemplate, "matches", "up", []string{"up"}},
+		{"selector label", resourceSeriesTemplate, "matches", `up{job="api", ins`, []string{`up{job="api", instance`}},
+		{"selector value", resourceSeriesTemplate, "matches", `up{job="pr`, []string{`up{job="prometheus"`}},
+		{"selector value scoped to metric", resourceSeriesTemplate, "matches", `http_requests_total{job=~"`, []string{`http_requests_total{job=~"api"`}},
+		{"closed selector", resourceSeriesTemplate, "matches", `up{job="api"}`, nil},
+		{"unknown argument", resourceSeriesTemplate, "other", "", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := complete(t, s, tt.uri, tt.argument, tt.value)
+			if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+				t.Errorf("completions of %s %q = %q, want %q", tt.argument, tt.value, got, tt.want)
+			}
+		})
+	}
+
+	// Metric names, label names and the two job value lists were each
+	// fetched once.
+	if got := requests.Load(); got != 4 {
+		t.Errorf("made %d label API requests, want 4", got)
+	}
+}
+
+func TestCompletionsWithoutDefaultClient(t *testing.T) {
+	sc, err := server.NewServerContext(context.Background(), server.WithSlogLogger(discardLogger()))
+	if err != nil {
+		t.Fatalf("Failed to create server context: %v", err)
+	}
+	defer func() { _ = sc.Shutdown() }()
+
+	s := mcpserver.NewMCPServer("test", "1.0.0")
+	if err := RegisterPrometheusTools(s, sc); err != nil {
+		t.Fatalf("RegisterPrometheusTools: %v", err)
+	}
+	if got := complete(t, s, resourceMetricTemplate, "metric", "up"); len(got) != 0 {
+		t.Errorf("expected no completions without a default client, got %q", got)
+	}
+}
+
+// groupTenants resolves each OAuth group to the tenants listed for it.
+type groupTenants map[string][]string
+
+func (g groupTenants) TenantsForGroups(_ context.Context, groups []string) ([]string, error) {
+	var tenants []string
+	for _, group := range groups {
+		tenants = append(tenants, g[group]...)
+	}
+	return tenants, nil
+}
+
+// withGroups returns ctx carrying an OAuth user in groups.
+func withGroups(ctx context.Context, groups ...string) context.Context {
+	return handler.ContextWithUserInfo(ctx, &providers.UserInfo{ID: strings.Join(groups, ","), Groups: groups})
+}
+
+func TestCompletionsPerTenant(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"status":"success","data":["` + r.Header.Get("X-Scope-OrgID") + `_metric"]}`))
+	}))
+	defer mockServer.Close()
+
+	sc, err := server.NewServerContext(context.Background(),
+		server.WithPrometheusConfig(server.PrometheusConfig{URL: mockServer.URL}),
+		server.WithSlogLogger(discardLogger()),
+		server.WithOAuthEnabled(true),
+		server.WithTenancyResolver(groupTenants{"team-a": {"tenant-a"}, "team-b": {"tenant-b"}}),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create server context: %v", err)
+	}
+	defer func() { _ = sc.Shutdown() }()
+
+	client, err := NewClient(sc.PrometheusConfig(), discardLogger())
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	p := &completionProvider{client: client, sc: sc, cache: newTTLCache[[]string](completionCacheTTL)}
+	argument := mcp.CompleteArgument{Name: "metric"}
+
+	for _, tenant := range []string{"a", "b", "a"} {
+		completion, err := p.CompleteResourceArgument(withGroups(context.Background(), "team-"+tenant), resourceMetricTemplate, argument, mcp.CompleteContext{})
+		if err != nil {
+			t.Fatalf("CompleteResourceArgument: %v", err)
+		}
+		if want := "tenant-" + tenant + "_metric"; strings.Join(completion.Values, "|") != want {
+			t.Errorf("team-%s completions = %q, want %q", tenant, completion.Values, want)
+		}
+	}
+
+	// Without a user the tenant is unknown and nothing is completed.
+	completion, err := p.CompleteResourceArgument(context.Background(), resourceMetricTemplate, argument, mcp.CompleteContext{})
+	if err != nil {
+		t.Fatalf("CompleteResourceArgument: %v", err)
+	}
+	if len(completion.Values) != 0 {
+		t.Errorf("completions without a user = %q, want none", completion.Values)
+	}
+}
+
+func TestSplitSelector(t *testing.T) {
+	tests := []struct {
+		selector string
+		want     selectorPart
+	}{
+		{"up", selectorPart{metric: "up", partial: "up"}},
+		{"up{", selectorPart{metric: "up", inBraces: true, base: "up{"}},
+		{`up{job="a,b", in`, selectorPart{metric: "up", inBraces: true, base: `up{job="a,b", `, partial: "in"}},
+		{`up{job!~"ap`, selectorPart{metric: "up", inBraces: true, label: "job", base: `up{job!~`, partial: "ap"}},
+		{`{job = ap`, selectorPart{inBraces: true, label: "job", base: `{job = `, partial: "ap"}},
+		{`up{job="a\"}", in`, selectorPart{metric: "up", inBraces: true, base: `up{job="a\"}", `, partial: "in"}},
+		{`up{job="api"}`, selectorPart{metric: "up", inBraces: true, closed: true}},
+	}
+	for _, tt := range tests {
+		if got := splitSelector(tt.selector); got != tt.want {
+			t.Errorf("splitSelector(%q) = %+v, want %+v", tt.selector, got, tt.want)
+		}
+	}
+}
+
+func TestResourceTemplates(t *testing.T) {
+	var requests atomic.Int32
+	s := newCompletionTestServer(t, &requests)
+
+	tests := []struct {
+		uri  string
+		want string
+	}{
+		{"prometheus://metrics/http_requests_total", "http_requests_total (counter): Total HTTP requests.\n"},
+		{"prometheus://labels/job/values", "api\nnode-exporter\nprometheus\n"},
+		{"prometheus://labels/__name__/values", "http_requests_total\nprocess_cpu_seconds_total\nup\n"},
+		{"prometheus://series?matches=up%7Bjob%3D%22api%22%7D", `up{instance="a:80", job="api"}` + "\n" + `up{instance="b:80", job="api"}` + "\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.uri, func(t *testing.T) {
+			text, ok := readResource(t, s, tt.uri)
+			if !ok {
+				t.Fatalf("reading %s failed: %s", tt.uri, text)
+			}
+			if text != tt.want {
+				t.Errorf("reading %s = %q, want %q", tt.uri, text, tt.want)
+			}
+		})
+	}
+
+	if text, ok := readResource(t, s, "prometheus://metrics/missing"); ok {
+		t.Errorf("expected an error for a metric without metadata, got %q", text)
+	}
+}
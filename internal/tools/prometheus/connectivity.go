@@ -0,0 +1,272 @@
+package prometheus
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	mcpserver "github.com/mark3labs/mcp-go/server"
+
+	"github.com/giantswarm/mcp-prometheus/internal/server"
+)
+
+const (
+	// toolCheckConnectivity is the registered name of the connectivity probe.
+	toolCheckConnectivity = "check_connectivity"
+
+	// defaultConnectivityTimeout bounds each probe when no timeout is given.
+	defaultConnectivityTimeout = 5 * time.Second
+
+	// defaultServerName names the server configured via PROMETHEUS_URL.
+	defaultServerName = "default"
+
+	// maxConnectivityURLs is the number of distinct urls one
+	// check_connectivity call may probe.
+	maxConnectivityURLs = 10
+)
+
+// connectivityTarget is one server probed by check_connectivity. err is set
+// instead of client when no client could be created for it.
+type connectivityTarget struct {
+	name   string
+	url    string
+	client *Client
+	err    error
+}
+
+// connectivityResult is the outcome of probing one server.
+type connectivityResult struct {
+	name    string
+	url     string
+	version string
+	latency time.Duration
+	err     error
+}
+
+// registerConnectivityTool registers check_connectivity. It is a diagnostic
+// that probes several servers itself, so it bypasses the dynamic client
+// wrapper and its per-call prometheus_url/org_id handling. Without profile
+// it probes the default server and every endpoint profile; profile selects
+// the one endpoint probed instead.
+func registerConnectivityTool(s *mcpserver.MCPServer, client *Client, sc *server.ServerContext, middleware []ToolMiddleware) {
+	tool := mcp.NewTool(toolCheckConnectivity,
+		mcp.WithDescription("Probe the configured Prometheus server, every endpoint profile and any additional URLs in parallel with a build info request and report status, version and latency for each"),
+		mcp.WithArray("urls", mcp.Description(fmt.Sprintf("Additional Prometheus/Mimir base URLs to probe, at most %d. They are rejected when the default server or profile has credentials; duplicates are probed once (e.g., ['http://prometheus-2:9090'])", maxConnectivityURLs))),
+		mcp.WithString("timeout", mcp.Description(fmt.Sprintf("Per-server timeout (default: %q)", defaultConnectivityTimeout.String()))),
+		mcp.WithString("profile", mcp.Description("Name of a Prometheus endpoint profile to probe instead of the default server and all profiles")),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithDestructiveHintAnnotation(false),
+		mcp.WithOpenWorldHintAnnotation(false),
+		mcp.WithSchemaAdditionalProperties(false),
+	)
+
+	h := withServerMiddleware(sc, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return handleCheckConnectivity(ctx, request, client, sc)
+	})
+	for _, mw := range middleware {
+		h = mw(toolCheckConnectivity, h)
+	}
+	s.AddTool(tool, h)
+}
+
+// probeConnectivity requests build info from every target concurrently and
+// returns the results in target order.
+func probeConnectivity(ctx context.Context, targets []connectivityTarget, timeout time.Duration) []connectivityResult {
+	results := make([]connectivityResult, len(targets))
+	var wg sync.WaitGroup
+	for i, target := range targets {
+		if target.err != nil {
+			results[i] = connectivityResult{name: target.name, url: target.url, err: target.err}
+			continue
+		}
+		wg.Go(func() {
+			ctx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+
+			start := time.Now()
+			info, err := target.client.GetBuildInfo(ctx)
+			results[i] = connectivityResult{
+				name:    target.name,
+				url:     target.url,
+				version: info.Version,
+				latency: time.Since(start),
+				err:     err,
+			}
+		})
+	}
+	wg.Wait()
+	return results
+}
+
+// uniqueURLs returns urls without repeats, in order. URLs that differ only
+// in a trailing slash are the same server.
+func uniqueURLs(urls []string) []string {
+	seen := make(map[string]bool, len(urls))
+	var out []string
+	for _, u := range urls {
+		key := strings.TrimRight(u, "/")
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		out = append(out, u)
+	}
+	return out
+}
+
+// handleCheckConnectivity handles the check_connectivity tool
+func handleCheckConnectivity(ctx context.Context, request mcp.CallToolRequest, client *Client, sc *server.ServerContext) (*mcp.CallToolResult, error) {
+	params := extractParams(request)
+
+	timeout := defaultConnectivityTimeout
+	if v := getStringParam(params, "timeout"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{
+					mcp.TextContent{
+						Type: contentTypeText,
+						Text: fmt.Sprintf("Error: timeout must be a positive duration such as '5s' (got %q)", v),
+					},
+				},
+			}, nil
+		}
+		timeout = d
+	}
+
+	// Probes bypass the request limiter, so a server saturated by other
+	// tool calls can still be checked. Their clients are only used once;
+	// their connections are closed when the call returns.
+	var targets []connectivityTarget
+	var created []*Client
+	defer func() {
+		for _, c := range created {
+			c.CloseIdleConnections()
+		}
+	}()
+	addTarget := func(name string, config server.PrometheusConfig) {
+		config.RequestLimiter = nil
+		c, err := NewClient(config, sc.Logger())
+		if err != nil {
+			targets = append(targets, connectivityTarget{name: name, url: redactURL(config.URL), err: fmt.Errorf("create client: %w", err)})
+			return
+		}
+		created = append(created, c)
+		targets = append(targets, connectivityTarget{name: name, url: redactURL(config.URL), client: c})
+	}
+
+	// The default server, or the selected profile, also supplies the TLS
+	// settings for the additional URLs. Its credentials are never sent to
+	// a caller-chosen URL.
+	baseConfig := sc.PrometheusConfig()
+	if profile := getStringParam(params, "profile"); profile != "" {
+		config, err := sc.ProfileConfig(profile)
+		if err != nil {
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{
+					mcp.TextContent{
+						Type: contentTypeText,
+						Text: fmt.Sprintf("Error: %v", err),
+					},
+				},
+			}, nil
+		}
+		addTarget(profile, config)
+		baseConfig = config
+	} else {
+		if client != nil && client.client != nil {
+			addTarget(defaultServerName, client.config)
+		}
+		for _, name := range sc.Profiles().Names() {
+			config, err := sc.ProfileConfig(name)
+			if err != nil {
+				targets = append(targets, connectivityTarget{name: name, err: err})
+				continue
+			}
+			addTarget(name, config)
+		}
+	}
+	urls := uniqueURLs(extractStringArray(params, "urls"))
+	if len(urls) > maxConnectivityURLs {
+		return &mcp.CallToolResult{
+			IsError: true,
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: contentTypeText,
+					Text: fmt.Sprintf("Error: at most %d urls can be probed in one call (got %d)", maxConnectivityURLs, len(urls)),
+				},
+			},
+		}, nil
+	}
+	if len(urls) > 0 && baseConfig.AuthType() != "none" {
+		return &mcp.CallToolResult{
+			IsError: true,
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: contentTypeText,
+					Text: fmt.Sprintf("Error: urls cannot be probed while the %s server carries %s credentials, which are never sent to caller-supplied URLs; add the servers as profiles and probe them with profile instead", cmp.Or(getStringParam(params, "profile"), defaultServerName), baseConfig.AuthType()),
+				},
+			},
+		}, nil
+	}
+	for _, u := range urls {
+		if err := validatePrometheusURL(u); err != nil {
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{
+					mcp.TextContent{
+						Type: contentTypeText,
+						Text: fmt.Sprintf("Error: %v", err),
+					},
+				},
+			}, nil
+		}
+		config := baseConfig
+		config.URL = u
+		config.PathPrefix = ""
+		addTarget(redactURL(u), config)
+	}
+	if len(targets) == 0 {
+		return &mcp.CallToolResult{
+			IsError: true,
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: contentTypeText,
+					Text: "Error: no Prometheus server configured; set PROMETHEUS_URL, configure profiles or pass urls",
+				},
+			},
+		}, nil
+	}
+
+	sc.Logger().Debug("Checking connectivity", "servers", len(targets), "timeout", timeout)
+
+	results := probeConnectivity(ctx, targets, timeout)
+
+	reachable := 0
+	var b strings.Builder
+	b.WriteString("server_name | url | status | version | latency_ms\n")
+	for _, r := range results {
+		status := "ok"
+		if r.err != nil {
+			status = fmt.Sprintf("error: %v", r.err)
+		} else {
+			reachable++
+		}
+		fmt.Fprintf(&b, "%s | %s | %s | %s | %d\n", r.name, r.url, status, valueOrNone(r.version), r.latency.Milliseconds())
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.TextContent{
+				Type: contentTypeText,
+				Text: fmt.Sprintf("%d of %d servers reachable (timeout %s):\n\n%s", reachable, len(results), timeout, b.String()),
+			},
+		},
+	}, nil
+}
@@ -0,0 +1,288 @@
+package prometheus
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/giantswarm/mcp-prometheus/internal/server"
+)
+
+// buildInfoServer answers /api/v1/status/buildinfo with version after delay.
+func buildInfoServer(version string, delay time.Duration) *httptest.Server {
+	return httptest.NewServer(buildInfoHandler(version, delay))
+}
+
+// buildInfoHandler is the handler of buildInfoServer.
+func buildInfoHandler(version string, delay time.Duration) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(delay):
+		case <-r.Context().Done():
+			return
+		}
+		if r.URL.Path != "/api/v1/status/buildinfo" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"status":"success","data":{"version":"` + version + `","revision":"abc","branch":"main","buildUser":"","buildDate":"","goVersion":"go1.26"}}`))
+	})
+}
+
+func TestHandleCheckConnectivity(t *testing.T) {
+	primary := buildInfoServer("3.1.0", 600*time.Millisecond)
+	defer primary.Close()
+	secondary := buildInfoServer("2.53.0", 600*time.Millisecond)
+	defer secondary.Close()
+	slow := buildInfoServer("3.0.0", 5*time.Second)
+	defer slow.Close()
+
+	ctx := context.Background()
+	sc, err := server.NewServerContext(ctx,
+		server.WithPrometheusConfig(server.PrometheusConfig{URL: primary.URL}),
+		server.WithSlogLogger(discardLogger()),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create server context: %v", err)
+	}
+	defer func() { _ = sc.Shutdown() }()
+
+	client, err := NewClient(sc.PrometheusConfig(), sc.Logger())
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+
+	request := mcp.CallToolRequest{Params: mcp.CallToolParams{Name: toolCheckConnectivity, Arguments: map[string]any{
+		"urls":    []any{secondary.URL, slow.URL, secondary.URL + "/"},
+		"timeout": "1s",
+	}}}
+	start := time.Now()
+	result, err := handleCheckConnectivity(ctx, request, client, sc)
+	elapsed := time.Since(start)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	text := result.Content[0].(mcp.TextContent).Text
+	if result.IsError {
+		t.Fatalf("expected success, got error: %s", text)
+	}
+
+	// Probes run in parallel: the total is bounded by the slowest probe's
+	// timeout, not by the sum of all probes.
+	if elapsed > 1900*time.Millisecond {
+		t.Errorf("probes took %s; expected them to run in parallel", elapsed)
+	}
+
+	lines := strings.Split(strings.TrimSpace(text), "\n")
+	if lines[0] != "2 of 3 servers reachable (timeout 1s):" || lines[2] != "server_name | url | status | version | latency_ms" {
+		t.Fatalf("unexpected header:\n%s", text)
+	}
+	for i, want := range []string{
+		"default | " + primary.URL + " | ok | 3.1.0 | ",
+		secondary.URL + " | " + secondary.URL + " | ok | 2.53.0 | ",
+		slow.URL + " | " + slow.URL + " | error: ",
+	} {
+		if !strings.HasPrefix(lines[3+i], want) {
+			t.Errorf("row %d = %q, want prefix %q", i, lines[3+i], want)
+		}
+	}
+	if !strings.Contains(lines[5], "| (none) |") {
+		t.Errorf("failed probe should report no version: %q", lines[5])
+	}
+}
+
+func TestHandleCheckConnectivityErrors(t *testing.T) {
+	ctx := context.Background()
+	sc, err := server.NewServerContext(ctx, server.WithSlogLogger(discardLogger()))
+	if err != nil {
+		t.Fatalf("Failed to create server context: %v", err)
+	}
+	defer func() { _ = sc.Shutdown() }()
+
+	var tooManyURLs []any
+	for i := range maxConnectivityURLs + 1 {
+		tooManyURLs = append(tooManyURLs, fmt.Sprintf("http://prometheus-%d:9090", i))
+	}
+
+	for name, args := range map[string]map[string]any{
+		"no servers":      {},
+		"invalid timeout": {"timeout": "soon"},
+		"invalid url":     {"urls": []any{"ftp://prometheus"}},
+		"unknown profile": {"profile": "prod"},
+		"too many urls":   {"urls": tooManyURLs},
+	} {
+		request := mcp.CallToolRequest{Params: mcp.CallToolParams{Name: toolCheckConnectivity, Arguments: args}}
+		result, err := handleCheckConnectivity(ctx, request, nil, sc)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", name, err)
+		}
+		if !result.IsError {
+			t.Errorf("%s: expected error result, got %v", name, result.Content)
+		}
+	}
+}
+
+func TestHandleCheckConnectivityProfile(t *testing.T) {
+	prod := buildInfoServer("3.1.0", 0)
+	defer prod.Close()
+
+	configFile := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(configFile, []byte("profiles:\n  prod:\n    url: "+prod.URL+"\n  secured:\n    url: "+prod.URL+"\n    token: secret\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	sc, err := server.NewServerContext(ctx,
+		server.WithPrometheusConfig(server.PrometheusConfig{URL: "http://default.invalid:9090"}),
+		server.WithConfigFile(configFile),
+		server.WithSlogLogger(discardLogger()),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create server context: %v", err)
+	}
+	defer func() { _ = sc.Shutdown() }()
+
+	client, err := NewClient(sc.PrometheusConfig(), sc.Logger())
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+
+	request := mcp.CallToolRequest{Params: mcp.CallToolParams{Name: toolCheckConnectivity, Arguments: map[string]any{"profile": "prod"}}}
+	result, err := handleCheckConnectivity(ctx, request, client, sc)
+	if err != nil || result.IsError {
+		t.Fatalf("unexpected failure: %v %v", err, result.Content)
+	}
+	text := result.Content[0].(mcp.TextContent).Text
+	if !strings.HasPrefix(text, "1 of 1 servers reachable") || !strings.Contains(text, "prod | "+prod.URL+" | ok | 3.1.0 | ") {
+		t.Errorf("expected only the prod profile to be probed:\n%s", text)
+	}
+
+	// Without profile, the default server and every profile are probed.
+	request = mcp.CallToolRequest{Params: mcp.CallToolParams{Name: toolCheckConnectivity, Arguments: map[string]any{"timeout": "1s"}}}
+	result, err = handleCheckConnectivity(ctx, request, client, sc)
+	if err != nil || result.IsError {
+		t.Fatalf("unexpected failure: %v %v", err, result.Content)
+	}
+	text = result.Content[0].(mcp.TextContent).Text
+	for _, want := range []string{"2 of 3 servers reachable", "\ndefault | http://default.invalid:9090 | error: ", "\nprod | " + prod.URL + " | ok | ", "\nsecured | " + prod.URL + " | ok | "} {
+		if !strings.Contains(text, want) {
+			t.Errorf("expected %q in:\n%s", want, text)
+		}
+	}
+
+	// Credentials of the profile are never sent to caller-supplied URLs.
+	var leaked bool
+	other := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		leaked = r.Header.Get("Authorization") != ""
+	}))
+	defer other.Close()
+	request = mcp.CallToolRequest{Params: mcp.CallToolParams{Name: toolCheckConnectivity, Arguments: map[string]any{"profile": "secured", "urls": []any{other.URL}}}}
+	result, err = handleCheckConnectivity(ctx, request, client, sc)
+	if err != nil || !result.IsError {
+		t.Errorf("expected urls to be rejected with a credentialed profile, got %v %v", err, result.Content)
+	}
+	if leaked {
+		t.Error("profile credentials were sent to a caller-supplied URL")
+	}
+}
+
+func TestHandleCheckConnectivityClosesConnections(t *testing.T) {
+	// Only clients with their own TLS transport hold connections of their
+	// own; the others share http.DefaultTransport.
+	var mu sync.Mutex
+	open := 0
+	srv := httptest.NewUnstartedServer(buildInfoHandler("3.1.0", 0))
+	srv.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch state {
+		case http.StateNew:
+			open++
+		case http.StateClosed, http.StateHijacked:
+			open--
+		}
+	}
+	srv.StartTLS()
+	defer srv.Close()
+
+	t.Setenv("PROMETHEUS_TLS_SKIP_VERIFY", "true")
+	ctx := context.Background()
+	sc, err := server.NewServerContext(ctx, server.WithSlogLogger(discardLogger()))
+	if err != nil {
+		t.Fatalf("Failed to create server context: %v", err)
+	}
+	defer func() { _ = sc.Shutdown() }()
+
+	request := mcp.CallToolRequest{Params: mcp.CallToolParams{Name: toolCheckConnectivity, Arguments: map[string]any{"urls": []any{srv.URL}}}}
+	result, err := handleCheckConnectivity(ctx, request, nil, sc)
+	if err != nil || result.IsError || !strings.HasPrefix(result.Content[0].(mcp.TextContent).Text, "1 of 1 servers reachable") {
+		t.Fatalf("unexpected failure: %v %v", err, result.Content)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		mu.Lock()
+		n := open
+		mu.Unlock()
+		if n == 0 {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("%d connections of the probe client are still open", n)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestHandleCheckConnectivityBypassesRequestLimiter(t *testing.T) {
+	srv := buildInfoServer("3.1.0", 0)
+	defer srv.Close()
+	other := buildInfoServer("3.1.0", 0)
+	defer other.Close()
+
+	// Hold the only request slot, as a long-running query would.
+	limiter, err := server.NewRequestLimiter(0, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	release, err := limiter.Acquire(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer release()
+
+	ctx := context.Background()
+	sc, err := server.NewServerContext(ctx,
+		server.WithPrometheusConfig(server.PrometheusConfig{URL: srv.URL}),
+		server.WithRequestLimiter(limiter),
+		server.WithSlogLogger(discardLogger()),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create server context: %v", err)
+	}
+	defer func() { _ = sc.Shutdown() }()
+	client, err := NewClient(sc.PrometheusConfig(), sc.Logger())
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+
+	request := mcp.CallToolRequest{Params: mcp.CallToolParams{Name: toolCheckConnectivity, Arguments: map[string]any{"urls": []any{other.URL}, "timeout": "1s"}}}
+	result, err := handleCheckConnectivity(ctx, request, client, sc)
+	if err != nil || result.IsError {
+		t.Fatalf("unexpected failure: %v %v", err, result.Content)
+	}
+	if text := result.Content[0].(mcp.TextContent).Text; !strings.HasPrefix(text, "2 of 2 servers reachable") {
+		t.Errorf("expected the probes to bypass the request limiter:\n%s", text)
+	}
+}
@@ -0,0 +1,255 @@
+package prometheus
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/promql/parser"
+
+	"github.com/giantswarm/mcp-prometheus/internal/promql"
+	"github.com/giantswarm/mcp-prometheus/internal/server"
+)
+
+const (
+	// defaultLookbackDelta is how far back Prometheus looks for the sample
+	// of an instant vector selector unless configured otherwise.
+	defaultLookbackDelta = 5 * time.Minute
+
+	// maxCostGuidanceSelectors is the number of selectors named when
+	// suggesting which to narrow.
+	maxCostGuidanceSelectors = 3
+)
+
+// selectorCost is the number of series one selector of a query matches over
+// the window the query reads.
+type selectorCost struct {
+	selector string
+	series   int
+	// capped is set when the lookup stopped at its limit, so the selector
+	// matches at least series series.
+	capped bool
+}
+
+// queryCost is the estimated cost of running a query.
+type queryCost struct {
+	// selectors are sorted by series, largest first.
+	selectors []selectorCost
+	// series is the total over all selectors.
+	series int
+	capped bool
+	// steps is the number of evaluations: 1 for instant queries.
+	steps int
+}
+
+// samples returns the number of samples the query returns at most: every
+// series at every step.
+func (c queryCost) samples() int64 {
+	return int64(c.series) * int64(c.steps)
+}
+
+// seriesString returns the series count, as "at least n" when capped.
+func (c queryCost) seriesString() string {
+	return seriesCountString(c.series, c.capped)
+}
+
+// seriesCountString returns n, as "at least n" when the count stopped at a
+// limit.
+func seriesCountString(n int, capped bool) string {
+	if capped {
+		return fmt.Sprintf("at least %d", n)
+	}
+	return strconv.Itoa(n)
+}
+
+// selectorWindow is a vector selector of a query and how far before each
+// evaluation time it reads.
+type selectorWindow struct {
+	selector string
+	lookback time.Duration
+}
+
+// selectorWindows returns the distinct selectors of expr with their
+// lookback: the range of a range vector selector or the default lookback
+// delta, plus offsets and the ranges of enclosing subqueries.
+func selectorWindows(expr parser.Expr) []selectorWindow {
+	var windows []selectorWindow
+	seen := make(map[selectorWindow]bool)
+	parser.Inspect(expr, func(node parser.Node, path []parser.Node) error {
+		vs, ok := node.(*parser.VectorSelector)
+		if !ok {
+			return nil
+		}
+		lookback := defaultLookbackDelta + vs.OriginalOffset
+		if len(path) > 0 {
+			if ms, ok := path[len(path)-1].(*parser.MatrixSelector); ok {
+				lookback = ms.Range + vs.OriginalOffset
+			}
+		}
+		for _, n := range path {
+			if sq, ok := n.(*parser.SubqueryExpr); ok {
+				lookback += sq.Range + sq.OriginalOffset
+			}
+		}
+		w := selectorWindow{selector: promql.SelectorString(vs), lookback: lookback}
+		if !seen[w] {
+			seen[w] = true
+			windows = append(windows, w)
+		}
+		return nil
+	})
+	return windows
+}
+
+// estimateQueryCost counts the series each selector of query matches between
+// start, minus its lookback, and end, stopping at maxSeries+1 per selector.
+// steps is the number of evaluations of the query. The estimate fails when
+// the server does not support the limit parameter, as the lookups would then
+// fetch every matching series.
+func estimateQueryCost(ctx context.Context, client *Client, query string, start, end time.Time, steps, maxSeries int) (queryCost, error) {
+	expr, err := promql.Parse(query)
+	if err != nil {
+		return queryCost{}, err
+	}
+	if err := client.requireFeature(ctx, featureLimit); err != nil {
+		return queryCost{}, err
+	}
+	limit := strconv.Itoa(maxSeries + 1)
+
+	cost := queryCost{steps: steps}
+	for _, w := range selectorWindows(expr) {
+		result, err := client.FindSeries(ctx, []string{w.selector}, SeriesOptions{
+			StartTime: strconv.FormatInt(start.Add(-w.lookback).Unix(), 10),
+			EndTime:   strconv.FormatInt(end.Unix(), 10),
+			Limit:     limit,
+		})
+		if err != nil {
+			return queryCost{}, fmt.Errorf("count series of %s: %w", w.selector, err)
+		}
+		sc := selectorCost{selector: w.selector, series: len(result.Series), capped: len(result.Series) > maxSeries}
+		cost.selectors = append(cost.selectors, sc)
+		cost.series += sc.series
+		cost.capped = cost.capped || sc.capped
+	}
+	slices.SortStableFunc(cost.selectors, func(a, b selectorCost) int { return cmp.Compare(b.series, a.series) })
+	return cost, nil
+}
+
+// violations describes the limits of guard that c exceeds.
+func (c queryCost) violations(guard server.QueryCostGuard) []string {
+	var out []string
+	if guard.MaxSeries > 0 && c.series > guard.MaxSeries {
+		out = append(out, fmt.Sprintf("its selectors match %s series (limit %d)", c.seriesString(), guard.MaxSeries))
+	}
+	if guard.MaxSamples > 0 && c.samples() > guard.MaxSamples {
+		out = append(out, fmt.Sprintf("it may return %d samples, %s series over %d steps (limit %d)", c.samples(), c.seriesString(), c.steps, guard.MaxSamples))
+	}
+	return out
+}
+
+// guidance suggests how to bring the cost of a query down.
+func (c queryCost) guidance() string {
+	var b strings.Builder
+	b.WriteString("To narrow the query:\n")
+	b.WriteString("- Add label matchers (e.g. job, namespace, instance) to its largest selectors:\n")
+	for _, s := range c.selectors[:min(len(c.selectors), maxCostGuidanceSelectors)] {
+		fmt.Fprintf(&b, "  - %s: %s series\n", s.selector, seriesCountString(s.series, s.capped))
+	}
+	if c.steps > 1 {
+		fmt.Fprintf(&b, "- Shorten the time range or use a larger step (now %d steps).\n", c.steps)
+	}
+	b.WriteString("- Aggregate with sum by (...) or topk() to return fewer series, or run get_tsdb_stats to see which labels drive the cardinality.\n")
+	return b.String()
+}
+
+// checkQueryCost applies the server's query cost guard to query evaluated
+// steps times between start and end. It returns an error result when the
+// guard refuses the query and otherwise a warning to show with the result,
+// empty when the query is within the limits or the guard is off. A query
+// whose cost cannot be estimated is treated as expensive unless the guard
+// fails open.
+func checkQueryCost(ctx context.Context, client *Client, sc *server.ServerContext, query string, start, end time.Time, steps int) (string, *mcp.CallToolResult) {
+	guard := sc.QueryCostGuard()
+	if !guard.Enabled() {
+		return "", nil
+	}
+
+	cost, err := estimateQueryCost(ctx, client, query, start, end, steps, costLookupLimit(guard, steps))
+	if err != nil {
+		sc.Logger().Warn("Failed to estimate query cost", "query", query, "error", err, "fail_open", guard.FailOpen)
+		switch {
+		case guard.FailOpen:
+			return "", nil
+		case guard.Mode == server.QueryCostRefuse:
+			return "", &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{
+					mcp.TextContent{
+						Type: contentTypeText,
+						Text: fmt.Sprintf("Error: query refused by the cost guard: its cost could not be estimated: %v", err),
+					},
+				},
+			}
+		}
+		return fmt.Sprintf("Warning: the cost of this query could not be estimated: %v\n", err), nil
+	}
+	violations := cost.violations(guard)
+	sc.Logger().Debug("Estimated query cost", "query", query, "series", cost.series, "steps", cost.steps, "exceeded", len(violations) > 0)
+	if len(violations) == 0 {
+		return "", nil
+	}
+
+	reason := strings.Join(violations, " and ")
+	if guard.Mode == server.QueryCostRefuse {
+		return "", &mcp.CallToolResult{
+			IsError: true,
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: contentTypeText,
+					Text: fmt.Sprintf("Error: query refused by the cost guard: %s.\n\n%s", reason, cost.guidance()),
+				},
+			},
+		}
+	}
+	return fmt.Sprintf("Warning: this query is expensive: %s.\n%s", reason, cost.guidance()), nil
+}
+
+// costLookupLimit returns the number of series a selector lookup may stop
+// after: the series limit, or without one the series that stay within the
+// samples limit over steps evaluations.
+func costLookupLimit(guard server.QueryCostGuard, steps int) int {
+	if guard.MaxSeries > 0 {
+		return guard.MaxSeries
+	}
+	return int(min(guard.MaxSamples/int64(max(steps, 1)), math.MaxInt32))
+}
+
+// rangeSteps returns the number of evaluations of a range query from start
+// to end every step, given as a duration or a number of seconds. It returns
+// 0 when step is invalid.
+func rangeSteps(start, end time.Time, step string) int {
+	d := parseStep(step)
+	if d <= 0 || end.Before(start) {
+		return 0
+	}
+	return int(end.Sub(start)/d) + 1
+}
+
+// parseStep parses a range query step given as a duration or a number of
+// seconds. It returns 0 when step is invalid.
+func parseStep(step string) time.Duration {
+	if md, err := model.ParseDuration(step); err == nil {
+		return time.Duration(md)
+	}
+	if secs, err := strconv.ParseFloat(step, 64); err == nil {
+		return time.Duration(secs * float64(time.Second))
+	}
+	return 0
+}
@@ -0,0 +1,231 @@
+package prometheus
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/giantswarm/mcp-prometheus/internal/promql"
+	"github.com/giantswarm/mcp-prometheus/internal/server"
+)
+
+func TestSelectorWindows(t *testing.T) {
+	expr, err := promql.Parse(`sum(rate(http_requests_total{job="api"}[5m] offset 1h)) / sum(up) + max_over_time(rate(errors_total[1m])[30m:1m])`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := selectorWindows(expr)
+	want := []selectorWindow{
+		{`http_requests_total{job="api"}`, time.Hour + 5*time.Minute},
+		{"up", 5 * time.Minute},
+		{"errors_total", 31 * time.Minute},
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("selectorWindows() = %v, want %v", got, want)
+	}
+}
+
+func TestRangeSteps(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := start.Add(time.Hour)
+	for step, want := range map[string]int{"1m": 61, "60": 61, "1.5": 2401, "bogus": 0, "0s": 0} {
+		if got := rangeSteps(start, end, step); got != want {
+			t.Errorf("rangeSteps(%q) = %d, want %d", step, got, want)
+		}
+	}
+}
+
+// newCostGuardServer serves series lookups with the given number of series
+// per metric name, honouring the limit parameter, and empty query results.
+func newCostGuardServer(t *testing.T, seriesPerMetric map[string]int) *httptest.Server {
+	t.Helper()
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/v1/series":
+			expr, err := promql.Parse(r.Form.Get("match[]"))
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			n := seriesPerMetric[promql.VectorSelectors(expr)[0].Name]
+			if limit, err := strconv.Atoi(r.Form.Get("limit")); err == nil {
+				n = min(n, limit)
+			}
+			series := make([]string, n)
+			for i := range series {
+				series[i] = fmt.Sprintf(`{"__name__":"m","instance":"%d"}`, i)
+			}
+			_, _ = w.Write([]byte(`{"status":"success","data":[` + strings.Join(series, ",") + `]}`))
+		case "/api/v1/query":
+			_, _ = w.Write([]byte(queryResponse))
+		case "/api/v1/query_range":
+			_, _ = w.Write([]byte(`{"status":"success","data":{"resultType":"matrix","result":[]}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(mockServer.Close)
+	return mockServer
+}
+
+func TestQueryCostGuard(t *testing.T) {
+	mockServer := newCostGuardServer(t, map[string]int{"small": 5, "large": 50})
+
+	tests := []struct {
+		name  string
+		mode  server.QueryCostMode
+		tool  string
+		args  map[string]any
+		error bool
+		want  []string
+	}{
+		{"within limits", server.QueryCostRefuse, "execute_query", map[string]any{"query": "sum(small)"}, false,
+			nil},
+		{"too many series", server.QueryCostRefuse, "execute_query", map[string]any{"query": "small + large"}, true,
+			[]string{"query refused by the cost guard: its selectors match at least 26 series (limit 20)", "  - large: at least 21 series\n  - small: 5 series\n"}},
+		{"warn", server.QueryCostWarn, "execute_query", map[string]any{"query": "large"}, false,
+			[]string{"Warning: this query is expensive: its selectors match at least 21 series (limit 20)"}},
+		{"too many samples", server.QueryCostRefuse, "execute_range_query", map[string]any{"query": "small", "start": "1704067200", "end": "1704070800", "step": "1m"}, true,
+			[]string{"it may return 305 samples, 5 series over 61 steps (limit 100)", "use a larger step (now 61 steps)"}},
+		{"off", server.QueryCostOff, "execute_query", map[string]any{"query": "large"}, false,
+			nil},
+		{"summarize_range_query", server.QueryCostRefuse, "summarize_range_query", map[string]any{"query": "large", "start": "1704067200", "end": "1704070800", "step": "1m"}, true,
+			[]string{"query refused by the cost guard: its selectors match at least 21 series (limit 20)"}},
+		{"analyze_anomalies", server.QueryCostRefuse, "analyze_anomalies", map[string]any{"query": "small", "baseline_start": "1704067200", "baseline_end": "1704070800", "comparison_start": "1704070800", "comparison_end": "1704074400", "step": "1m"}, true,
+			[]string{"it may return 305 samples, 5 series over 61 steps (limit 100)"}},
+		{"evaluate_rule_timeline", server.QueryCostWarn, "evaluate_rule_timeline", map[string]any{"rule_expr": "large > 0", "start": "1704067200", "end": "1704067260", "step": "1m"}, false,
+			[]string{"Warning: this query is expensive: its selectors match at least 21 series (limit 20)", "Rule timeline for: large > 0"}},
+		{"get_series_count_history", server.QueryCostRefuse, "get_series_count_history", map[string]any{"matches": []any{"large"}, "start": "1704067200", "end": "1704070800", "step": "1h"}, true,
+			[]string{"query refused by the cost guard: its selectors match at least 21 series (limit 20)"}},
+	}
+	handlers := map[string]func(context.Context, mcp.CallToolRequest, *Client, *server.ServerContext) (*mcp.CallToolResult, error){
+		"execute_query":            handleExecuteQuery,
+		"execute_range_query":      handleExecuteRangeQuery,
+		"summarize_range_query":    handleSummarizeRangeQuery,
+		"analyze_anomalies":        handleAnalyzeAnomalies,
+		"evaluate_rule_timeline":   handleEvaluateRuleTimeline,
+		"get_series_count_history": handleGetSeriesCountHistory,
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sc, err := server.NewServerContext(context.Background(),
+				server.WithPrometheusConfig(server.PrometheusConfig{URL: mockServer.URL, DisableAPIVersionNegotiation: true}),
+				server.WithSlogLogger(discardLogger()),
+				server.WithQueryCostGuard(server.QueryCostGuard{Mode: tt.mode, MaxSeries: 20, MaxSamples: 100}),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer func() { _ = sc.Shutdown() }()
+			client, err := NewClient(sc.PrometheusConfig(), sc.Logger())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			request := mcp.CallToolRequest{Params: mcp.CallToolParams{Name: tt.tool, Arguments: tt.args}}
+			result, err := handlers[tt.tool](context.Background(), request, client, sc)
+			if err != nil {
+				t.Fatal(err)
+			}
+			text := result.Content[0].(mcp.TextContent).Text
+			if result.IsError != tt.error {
+				t.Fatalf("IsError = %t, want %t:\n%s", result.IsError, tt.error, text)
+			}
+			if tt.want == nil && strings.Contains(text, "cost guard") || strings.Contains(text, "expensive") && tt.mode != server.QueryCostWarn {
+				t.Errorf("unexpected cost guard output:\n%s", text)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(text, want) {
+					t.Errorf("expected %q in:\n%s", want, text)
+				}
+			}
+		})
+	}
+}
+
+func TestQueryCostGuardEstimateFailure(t *testing.T) {
+	// An old Prometheus lacks the limit parameter, so its series lookups
+	// are not run; a failing one cannot answer them.
+	oldServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/v1/status/buildinfo":
+			_, _ = w.Write([]byte(`{"status":"success","data":{"version":"2.30.0"}}`))
+		case "/api/v1/query":
+			_, _ = w.Write([]byte(queryResponse))
+		default:
+			t.Errorf("unexpected request to %s", r.URL.Path)
+			http.NotFound(w, r)
+		}
+	}))
+	defer oldServer.Close()
+	failingServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/api/v1/query" {
+			_, _ = w.Write([]byte(queryResponse))
+			return
+		}
+		http.Error(w, `{"status":"error","errorType":"internal","error":"boom"}`, http.StatusInternalServerError)
+	}))
+	defer failingServer.Close()
+
+	tests := []struct {
+		name     string
+		url      string
+		mode     server.QueryCostMode
+		failOpen bool
+		error    bool
+		want     string
+	}{
+		{"refuse without the limit parameter", oldServer.URL, server.QueryCostRefuse, false, true,
+			"query refused by the cost guard: its cost could not be estimated: the limit parameter requires Prometheus 2.33 or later"},
+		{"refuse when the lookup fails", failingServer.URL, server.QueryCostRefuse, false, true,
+			"query refused by the cost guard: its cost could not be estimated"},
+		{"warn when the lookup fails", failingServer.URL, server.QueryCostWarn, false, false,
+			"Warning: the cost of this query could not be estimated"},
+		{"fail open", failingServer.URL, server.QueryCostRefuse, true, false,
+			""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sc, err := server.NewServerContext(context.Background(),
+				server.WithPrometheusConfig(server.PrometheusConfig{URL: tt.url}),
+				server.WithSlogLogger(discardLogger()),
+				server.WithQueryCostGuard(server.QueryCostGuard{Mode: tt.mode, MaxSeries: 20, FailOpen: tt.failOpen}),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer func() { _ = sc.Shutdown() }()
+			client, err := NewClient(sc.PrometheusConfig(), sc.Logger())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			request := mcp.CallToolRequest{Params: mcp.CallToolParams{Name: "execute_query", Arguments: map[string]any{"query": "up"}}}
+			result, err := handleExecuteQuery(context.Background(), request, client, sc)
+			if err != nil {
+				t.Fatal(err)
+			}
+			text := result.Content[0].(mcp.TextContent).Text
+			if result.IsError != tt.error {
+				t.Fatalf("IsError = %t, want %t:\n%s", result.IsError, tt.error, text)
+			}
+			if tt.want == "" && strings.Contains(text, "cost") {
+				t.Errorf("unexpected cost guard output:\n%s", text)
+			}
+			if !strings.Contains(text, tt.want) {
+				t.Errorf("expected %q in:\n%s", tt.want, text)
+			}
+		})
+	}
+}
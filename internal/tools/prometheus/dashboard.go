@@ -0,0 +1,125 @@
+package prometheus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	mcpserver "github.com/mark3labs/mcp-go/server"
+
+	"github.com/giantswarm/mcp-prometheus/internal/format"
+	"github.com/giantswarm/mcp-prometheus/internal/server"
+)
+
+// toolGenerateDashboardJSON is the registered name of the Grafana export tool.
+const toolGenerateDashboardJSON = "generate_dashboard_json"
+
+// registerDashboardTool registers generate_dashboard_json. It only renders
+// JSON from its arguments, so like get_server_config it bypasses the dynamic
+// client wrapper and takes no connection parameters.
+func registerDashboardTool(s *mcpserver.MCPServer, sc *server.ServerContext, middleware []ToolMiddleware) {
+	tool := mcp.NewTool(toolGenerateDashboardJSON,
+		mcp.WithDescription("Generate an importable Grafana (9+) dashboard JSON with a single panel plotting a PromQL query. The query is not executed"),
+		mcp.WithString("query", mcp.Required(), mcp.Description("PromQL expression used as the panel's target")),
+		mcp.WithString("title", mcp.Description("Dashboard and panel title (default: the query)")),
+		mcp.WithString("description", mcp.Description("Panel description")),
+		mcp.WithString("panel_type", mcp.Description("Panel type: 'timeseries' (default), 'stat', 'gauge' or 'bar'")),
+		mcp.WithString("legend_format", mcp.Description("Legend format, e.g. '{{instance}}' (default: '__auto')")),
+		mcp.WithString("start", mcp.Description("Dashboard time range start: a Grafana relative time such as 'now-6h', RFC3339 or Unix timestamp (default: now-1h)")),
+		mcp.WithString("end", mcp.Description("Dashboard time range end: 'now', RFC3339 or Unix timestamp (default: now)")),
+		mcp.WithString("data_source_name", mcp.Description("Name of the Prometheus data source offered on import (default: Prometheus)")),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithDestructiveHintAnnotation(false),
+		mcp.WithOpenWorldHintAnnotation(false),
+		mcp.WithSchemaAdditionalProperties(false),
+	)
+
+	h := withServerMiddleware(sc, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return handleGenerateDashboardJSON(ctx, request, sc)
+	})
+	for _, mw := range middleware {
+		h = mw(toolGenerateDashboardJSON, h)
+	}
+	s.AddTool(tool, h)
+}
+
+// grafanaTime converts a start/end parameter to a Grafana time range value.
+// Relative times ("now", "now-6h") are passed through; anything else must be
+// a timestamp accepted by parseTimeParam.
+func grafanaTime(value string) (string, error) {
+	if value == "" || strings.HasPrefix(value, "now") {
+		return value, nil
+	}
+	t, err := parseTimeParam(value)
+	if err != nil {
+		return "", err
+	}
+	return t.UTC().Format(resultTimeLayout), nil
+}
+
+// handleGenerateDashboardJSON handles the generate_dashboard_json tool
+func handleGenerateDashboardJSON(_ context.Context, request mcp.CallToolRequest, sc *server.ServerContext) (*mcp.CallToolResult, error) {
+	params := extractParams(request)
+
+	query := getStringParam(params, "query")
+	if query == "" {
+		return &mcp.CallToolResult{
+			IsError: true,
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: contentTypeText,
+					Text: errQueryParameterRequired,
+				},
+			},
+		}, nil
+	}
+
+	from, fromErr := grafanaTime(getStringParam(params, "start"))
+	to, toErr := grafanaTime(getStringParam(params, "end"))
+	if err := errors.Join(fromErr, toErr); err != nil {
+		return &mcp.CallToolResult{
+			IsError: true,
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: contentTypeText,
+					Text: fmt.Sprintf("Error: invalid time range: %v", err),
+				},
+			},
+		}, nil
+	}
+
+	sc.Logger().Debug("Generating Grafana dashboard", "query", query, "panel_type", getStringParam(params, "panel_type"))
+
+	dashboard, err := format.GrafanaDashboardJSON(format.GrafanaPanel{
+		Title:        getStringParam(params, "title"),
+		Description:  getStringParam(params, "description"),
+		PanelType:    getStringParam(params, "panel_type"),
+		Query:        query,
+		LegendFormat: getStringParam(params, "legend_format"),
+		DataSource:   getStringParam(params, "data_source_name"),
+		From:         from,
+		To:           to,
+	})
+	if err != nil {
+		return &mcp.CallToolResult{
+			IsError: true,
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: contentTypeText,
+					Text: fmt.Sprintf("Error generating dashboard: %v", err),
+				},
+			},
+		}, nil
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.TextContent{
+				Type: contentTypeText,
+				Text: dashboard,
+			},
+		},
+	}, nil
+}
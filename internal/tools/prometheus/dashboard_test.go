@@ -0,0 +1,97 @@
+package prometheus
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/giantswarm/mcp-prometheus/internal/server"
+)
+
+func TestHandleGenerateDashboardJSON(t *testing.T) {
+	sc, err := server.NewServerContext(context.Background(), server.WithSlogLogger(discardLogger()))
+	if err != nil {
+		t.Fatalf("Failed to create server context: %v", err)
+	}
+	defer func() { _ = sc.Shutdown() }()
+
+	tests := []struct {
+		name     string
+		args     map[string]any
+		wantFrom string
+		wantTo   string
+		wantErr  string
+	}{
+		{
+			name:     "defaults",
+			args:     map[string]any{paramKeyQuery: "rate(http_requests_total[5m])"},
+			wantFrom: "now-1h",
+			wantTo:   "now",
+		},
+		{
+			name:     "absolute range",
+			args:     map[string]any{paramKeyQuery: "rate(http_requests_total[5m])", "start": "1704067200", "end": "2024-01-01T06:00:00Z", "panel_type": "stat"},
+			wantFrom: "2024-01-01T00:00:00.000Z",
+			wantTo:   "2024-01-01T06:00:00.000Z",
+		},
+		{
+			name:    "missing query",
+			args:    map[string]any{},
+			wantErr: "query parameter is required",
+		},
+		{
+			name:    "invalid start",
+			args:    map[string]any{paramKeyQuery: "up", "start": "the day before"},
+			wantErr: "invalid time range",
+		},
+		{
+			name:    "invalid panel type",
+			args:    map[string]any{paramKeyQuery: "up", "panel_type": "heatmap"},
+			wantErr: "unsupported panel type",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := mcp.CallToolRequest{Params: mcp.CallToolParams{Name: toolGenerateDashboardJSON, Arguments: tt.args}}
+			result, err := handleGenerateDashboardJSON(context.Background(), request, sc)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			text := result.Content[0].(mcp.TextContent).Text
+			if tt.wantErr != "" {
+				if !result.IsError || !strings.Contains(text, tt.wantErr) {
+					t.Errorf("expected error containing %q, got %q", tt.wantErr, text)
+				}
+				return
+			}
+			if result.IsError {
+				t.Fatalf("expected success, got %s", text)
+			}
+
+			var dashboard struct {
+				Time struct {
+					From string `json:"from"`
+					To   string `json:"to"`
+				} `json:"time"`
+				Panels []struct {
+					Targets []struct {
+						Expr string `json:"expr"`
+					} `json:"targets"`
+				} `json:"panels"`
+			}
+			if err := json.Unmarshal([]byte(text), &dashboard); err != nil {
+				t.Fatalf("result is not valid JSON: %v\n%s", err, text)
+			}
+			if got := dashboard.Panels[0].Targets[0].Expr; got != tt.args[paramKeyQuery] {
+				t.Errorf("expr = %q, want %q", got, tt.args[paramKeyQuery])
+			}
+			if dashboard.Time.From != tt.wantFrom || dashboard.Time.To != tt.wantTo {
+				t.Errorf("time = %+v, want %s to %s", dashboard.Time, tt.wantFrom, tt.wantTo)
+			}
+		})
+	}
+}
@@ -0,0 +1,27 @@
+package prometheus
+
+import (
+	"github.com/prometheus/common/model"
+
+	"github.com/giantswarm/mcp-prometheus/internal/analysis"
+)
+
+// parseDeduplicate reads the deduplicate and aggregate parameters of
+// execute_query. ok is false when deduplication was not requested.
+func parseDeduplicate(params map[string]any) (agg analysis.Aggregate, ok bool, err error) {
+	if getStringParam(params, "deduplicate") != "true" {
+		return "", false, nil
+	}
+	agg, err = analysis.ParseAggregate(getStringParam(params, "aggregate"))
+	return agg, err == nil, err
+}
+
+// deduplicateQueryResult collapses the series of a vector result that only
+// differ in the replica label (PROMETHEUS_REPLICA_LABEL); other result types
+// are returned unchanged.
+func deduplicateQueryResult(result any, agg analysis.Aggregate) any {
+	if v, ok := result.(model.Vector); ok {
+		return analysis.DeduplicateVector([]model.Vector{v}, analysis.ReplicaLabelFromEnv(), agg)
+	}
+	return result
+}
@@ -0,0 +1,66 @@
+package prometheus
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/giantswarm/mcp-prometheus/internal/server"
+)
+
+func TestHandleExecuteQueryDeduplicate(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		samples := []any{
+			map[string]any{"metric": map[string]string{"__name__": "up", "job": "api", "prometheus_replica": "a"}, "value": []any{1704067200, "1"}},
+			map[string]any{"metric": map[string]string{"__name__": "up", "job": "api", "prometheus_replica": "b"}, "value": []any{1704067201, "3"}},
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{respKeyStatus: respValSuccess, respKeyData: map[string]any{respKeyResultType: respValVector, respKeyResult: samples}})
+	}))
+	defer mockServer.Close()
+
+	ctx := context.Background()
+	sc, err := server.NewServerContext(ctx,
+		server.WithPrometheusConfig(server.PrometheusConfig{URL: mockServer.URL}),
+		server.WithSlogLogger(discardLogger()),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create server context: %v", err)
+	}
+	defer func() { _ = sc.Shutdown() }()
+
+	client, err := NewClient(sc.PrometheusConfig(), sc.Logger())
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+
+	call := func(args map[string]any) *mcp.CallToolResult {
+		t.Helper()
+		args[paramKeyQuery] = "up"
+		result, err := handleExecuteQuery(ctx, mcp.CallToolRequest{Params: mcp.CallToolParams{Name: toolExecuteQuery, Arguments: args}}, client, sc)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return result
+	}
+
+	text := call(map[string]any{}).Content[0].(mcp.TextContent).Text
+	if strings.Count(text, "prometheus_replica") != 2 {
+		t.Errorf("expected both replicas without deduplicate, got:\n%s", text)
+	}
+
+	result := call(map[string]any{"deduplicate": "true", "aggregate": "avg"})
+	text = result.Content[0].(mcp.TextContent).Text
+	if result.IsError || strings.Contains(text, "prometheus_replica") || !strings.Contains(text, "| 2 ") {
+		t.Errorf("expected one averaged series, got:\n%s", text)
+	}
+
+	if result := call(map[string]any{"deduplicate": "true", "aggregate": "median"}); !result.IsError {
+		t.Error("expected an error for an unknown aggregate")
+	}
+}
@@ -0,0 +1,325 @@
+package prometheus
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	v1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/prometheus/common/model"
+
+	"github.com/giantswarm/mcp-prometheus/internal/server"
+)
+
+const (
+	// toolDeleteSeries is the registered name of the series deletion tool.
+	toolDeleteSeries = "delete_series"
+
+	// deletePreviewSeries is the number of matching series listed by a dry run.
+	deletePreviewSeries = 10
+
+	// deleteTokenTTL is how long the confirm_token of a dry run is valid.
+	deleteTokenTTL = 10 * time.Minute
+)
+
+// deleteTokenKey signs confirmation tokens. It is drawn once per process,
+// so tokens cannot be forged by clients and do not outlive a restart.
+var deleteTokenKey = func() []byte {
+	key := make([]byte, 32)
+	_, _ = rand.Read(key)
+	return key
+}()
+
+// errDeleteTokenMismatch and errDeleteTokenExpired reject the confirm_token
+// of a deletion.
+var (
+	errDeleteTokenMismatch = errors.New("confirm_token does not match these matches, time range, server, org ID and profile; run a new dry run for the selection you want to delete")
+	errDeleteTokenExpired  = fmt.Errorf("confirm_token has expired (tokens are valid for %s); run a new dry run", deleteTokenTTL)
+)
+
+// deleteSelection is what a confirmation token is bound to: the matchers,
+// the resolved time range and the server the deletion is sent to.
+type deleteSelection struct {
+	matches    []string
+	start, end time.Time
+	target     string
+	orgID      string
+	profile    string
+}
+
+// deleteSeriesToken derives the confirmation token of a deletion issued at
+// issued: the issue time followed by an HMAC of it and of sel. The matcher
+// order does not matter. A token from a dry run only confirms a deletion of
+// exactly the same selection on the same server within deleteTokenTTL.
+func deleteSeriesToken(sel deleteSelection, issued time.Time) string {
+	mac := hmac.New(sha256.New, deleteTokenKey)
+	for _, m := range slices.Sorted(slices.Values(sel.matches)) {
+		mac.Write([]byte(m))
+		mac.Write([]byte{0})
+	}
+	fmt.Fprintf(mac, "start=%s\x00end=%s\x00target=%s\x00org=%s\x00profile=%s\x00issued=%d",
+		formatDeleteTime(sel.start), formatDeleteTime(sel.end), sel.target, sel.orgID, sel.profile, issued.Unix())
+	return fmt.Sprintf("%d.%s", issued.Unix(), hex.EncodeToString(mac.Sum(nil)))
+}
+
+// checkDeleteSeriesToken verifies that token was issued for sel by this
+// process no longer than deleteTokenTTL before now.
+func checkDeleteSeriesToken(token string, sel deleteSelection, now time.Time) error {
+	issuedText, _, ok := strings.Cut(token, ".")
+	seconds, err := strconv.ParseInt(issuedText, 10, 64)
+	if !ok || err != nil {
+		return errDeleteTokenMismatch
+	}
+	issued := time.Unix(seconds, 0)
+	if subtle.ConstantTimeCompare([]byte(token), []byte(deleteSeriesToken(sel, issued))) != 1 {
+		return errDeleteTokenMismatch
+	}
+	if now.Sub(issued) > deleteTokenTTL || issued.After(now) {
+		return errDeleteTokenExpired
+	}
+	return nil
+}
+
+// formatDeleteTime renders a resolved bound of a deletion, or an empty
+// string for an open bound.
+func formatDeleteTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339Nano)
+}
+
+// describeDeleteRange renders the time range of a deletion.
+func describeDeleteRange(start, end string) string {
+	switch {
+	case start == "" && end == "":
+		return "all time"
+	case start == "":
+		return "up to " + end
+	case end == "":
+		return "from " + start
+	}
+	return fmt.Sprintf("from %s to %s", start, end)
+}
+
+// estimateDeleteImpact compares the number of series to delete with the TSDB
+// head. It returns an empty string when the head is empty.
+func estimateDeleteImpact(count int, stats v1.TSDBResult) string {
+	head := stats.HeadStats
+	if head.NumSeries == 0 {
+		return ""
+	}
+	share := float64(count) / float64(head.NumSeries)
+	return fmt.Sprintf("%d of %d head series (%.2f%%), roughly %.0f of %d head chunks",
+		count, head.NumSeries, share*100, share*float64(head.ChunkCount), head.ChunkCount)
+}
+
+// handleDeleteSeries handles the delete_series tool. Without confirm it runs
+// a dry run that previews the matching series and returns a confirm_token;
+// the deletion only runs when a later call repeats the selection with
+// confirm=true and that token.
+func handleDeleteSeries(ctx context.Context, request mcp.CallToolRequest, client *Client, sc *server.ServerContext) (*mcp.CallToolResult, error) {
+	params := extractParams(request)
+
+	matches := extractStringArray(params, "matches")
+	if len(matches) == 0 {
+		return &mcp.CallToolResult{
+			IsError: true,
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: contentTypeText,
+					Text: "Error: matches parameter is required and must contain at least one series selector",
+				},
+			},
+		}, nil
+	}
+
+	start := getStringParam(params, "start")
+	end := getStringParam(params, "end")
+	var startTime, endTime time.Time
+	for _, p := range []struct {
+		name  string
+		value string
+		t     *time.Time
+	}{{"start", start, &startTime}, {"end", end, &endTime}} {
+		if p.value == "" {
+			continue
+		}
+		t, err := parseTimeParam(p.value)
+		if err != nil {
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{
+					mcp.TextContent{
+						Type: contentTypeText,
+						Text: fmt.Sprintf("Error: invalid %s: %v", p.name, err),
+					},
+				},
+			}, nil
+		}
+		*p.t = t
+	}
+	if !startTime.IsZero() && !endTime.IsZero() && endTime.Before(startTime) {
+		return &mcp.CallToolResult{
+			IsError: true,
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: contentTypeText,
+					Text: "Error: end must not be before start",
+				},
+			},
+		}, nil
+	}
+
+	confirm := getStringParam(params, "confirm") == "true"
+	if confirm && getStringParam(params, "dry_run") == "true" {
+		return &mcp.CallToolResult{
+			IsError: true,
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: contentTypeText,
+					Text: "Error: set either dry_run or confirm, not both",
+				},
+			},
+		}, nil
+	}
+
+	sel := deleteSelection{
+		matches: matches,
+		start:   startTime,
+		end:     endTime,
+		target:  client.config.URL + client.config.PathPrefix,
+		orgID:   client.config.OrgID,
+		profile: getStringParam(params, "profile"),
+	}
+	selection := fmt.Sprintf("%s (%s)", strings.Join(matches, ", "), describeDeleteRange(start, end))
+
+	if confirm {
+		given := getStringParam(params, "confirm_token")
+		if given == "" {
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{
+					mcp.TextContent{
+						Type: contentTypeText,
+						Text: "Error: confirm_token is required; run delete_series with dry_run 'true' first to preview the deletion and get a token",
+					},
+				},
+			}, nil
+		}
+		if err := checkDeleteSeriesToken(given, sel, time.Now()); err != nil {
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{
+					mcp.TextContent{
+						Type: contentTypeText,
+						Text: fmt.Sprintf("Error: %v", err),
+					},
+				},
+			}, nil
+		}
+
+		sc.Logger().Info("Deleting series", "matches", matches, "start", start, "end", end)
+
+		if err := client.DeleteSeries(ctx, matches, startTime, endTime); err != nil {
+			sc.Logger().Error("Failed to delete series", "error", err)
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{
+					mcp.TextContent{
+						Type: contentTypeText,
+						Text: fmt.Sprintf("Error deleting series: %v", err),
+					},
+				},
+			}, nil
+		}
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: contentTypeText,
+					Text: fmt.Sprintf("Deleted series matching %s. The data is tombstoned and removed from disk at the next compaction.", selection),
+				},
+			},
+		}, nil
+	}
+
+	sc.Logger().Debug("Previewing series deletion", "matches", matches, "start", start, "end", end)
+
+	options := SeriesOptions{}
+	if !startTime.IsZero() {
+		options.StartTime = startTime.UTC().Format(time.RFC3339Nano)
+	}
+	if !endTime.IsZero() {
+		options.EndTime = endTime.UTC().Format(time.RFC3339Nano)
+	}
+	series, err := client.FindSeries(ctx, matches, options)
+	if err != nil {
+		sc.Logger().Error("Failed to find series to delete", "error", err)
+		return &mcp.CallToolResult{
+			IsError: true,
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: contentTypeText,
+					Text: fmt.Sprintf("Error finding series to delete: %v", err),
+				},
+			},
+		}, nil
+	}
+
+	if len(series.Series) == 0 {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: contentTypeText,
+					Text: fmt.Sprintf("Dry run: no series match %s. Nothing to delete.", selection),
+				},
+			},
+		}, nil
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Dry run: %d series match %s. Nothing was deleted.\n\n", len(series.Series), selection)
+	fmt.Fprintf(&b, "Sample series (%d of %d):\n", min(deletePreviewSeries, len(series.Series)), len(series.Series))
+	for _, s := range series.Series[:min(deletePreviewSeries, len(series.Series))] {
+		labels := maps.Clone(s)
+		delete(labels, model.MetricNameLabel)
+		fmt.Fprintf(&b, "  %s\n", formatSeriesLabels(s[model.MetricNameLabel], labels))
+	}
+
+	if stats, err := client.GetTSDBStats(ctx, TSDBOptions{}); err != nil {
+		sc.Logger().Warn("Failed to get TSDB stats for delete impact", "error", err)
+	} else if result, ok := stats.(v1.TSDBResult); ok {
+		if impact := estimateDeleteImpact(len(series.Series), result); impact != "" {
+			fmt.Fprintf(&b, "\nEstimated storage impact: %s.\n", impact)
+		}
+	}
+
+	fmt.Fprintf(&b, "\nTo delete, call delete_series again within %s with the same matches, server and org ID", deleteTokenTTL)
+	if !startTime.IsZero() {
+		fmt.Fprintf(&b, ", start %q", formatDeleteTime(startTime))
+	}
+	if !endTime.IsZero() {
+		fmt.Fprintf(&b, ", end %q", formatDeleteTime(endTime))
+	}
+	fmt.Fprintf(&b, ", confirm 'true' and confirm_token %q.\n", deleteSeriesToken(sel, time.Now()))
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.TextContent{
+				Type: contentTypeText,
+				Text: b.String(),
+			},
+		},
+	}, nil
+}
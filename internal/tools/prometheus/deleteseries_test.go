@@ -0,0 +1,219 @@
+package prometheus
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	mcpserver "github.com/mark3labs/mcp-go/server"
+
+	"github.com/giantswarm/mcp-prometheus/internal/server"
+)
+
+// confirmTokenPattern extracts the confirm_token from a dry run result.
+var confirmTokenPattern = regexp.MustCompile(`confirm_token "([0-9]+\.[0-9a-f]{64})"`)
+
+func TestHandleDeleteSeriesConfirmation(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		deletes []string
+	)
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = r.ParseForm()
+		switch r.URL.Path {
+		case "/api/v1/series":
+			_ = json.NewEncoder(w).Encode(map[string]any{respKeyStatus: respValSuccess, respKeyData: []map[string]string{
+				{"__name__": "test_metric", "job": "test", "instance": "a"},
+				{"__name__": "test_metric", "job": "test", "instance": "b"},
+			}})
+		case "/api/v1/status/tsdb":
+			_ = json.NewEncoder(w).Encode(map[string]any{respKeyStatus: respValSuccess, respKeyData: map[string]any{
+				"headStats": map[string]any{"numSeries": 200, "chunkCount": 1000},
+			}})
+		case "/api/v1/admin/tsdb/delete_series":
+			mu.Lock()
+			deletes = append(deletes, r.Form.Encode())
+			mu.Unlock()
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer mockServer.Close()
+
+	ctx := context.Background()
+	sc, err := server.NewServerContext(ctx,
+		server.WithPrometheusConfig(server.PrometheusConfig{URL: mockServer.URL}),
+		server.WithSlogLogger(discardLogger()),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create server context: %v", err)
+	}
+	defer func() { _ = sc.Shutdown() }()
+
+	client, err := NewClient(sc.PrometheusConfig(), sc.Logger())
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+
+	call := func(args map[string]any) *mcp.CallToolResult {
+		t.Helper()
+		request := mcp.CallToolRequest{Params: mcp.CallToolParams{Name: toolDeleteSeries, Arguments: args}}
+		result, err := withDynamicPrometheusClient(handleDeleteSeries, client, sc)(ctx, request)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return result
+	}
+	selection := func(extra map[string]any) map[string]any {
+		args := map[string]any{
+			"matches": []any{`{job="test"}`},
+			"start":   "2024-01-15T00:00:00Z",
+			"end":     "2024-01-15T12:00:00Z",
+		}
+		for k, v := range extra {
+			args[k] = v
+		}
+		return args
+	}
+
+	preview := call(selection(map[string]any{"dry_run": "true"}))
+	if preview.IsError {
+		t.Fatalf("dry run failed: %v", preview.Content)
+	}
+	text := preview.Content[0].(mcp.TextContent).Text
+	for _, want := range []string{
+		`Dry run: 2 series match {job="test"} (from 2024-01-15T00:00:00Z to 2024-01-15T12:00:00Z). Nothing was deleted.`,
+		`test_metric{instance="a", job="test"}`,
+		"Estimated storage impact: 2 of 200 head series (1.00%), roughly 10 of 1000 head chunks.",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("dry run output missing %q:\n%s", want, text)
+		}
+	}
+	m := confirmTokenPattern.FindStringSubmatch(text)
+	if m == nil {
+		t.Fatalf("dry run returned no confirm_token:\n%s", text)
+	}
+	token := m[1]
+
+	rejected := []struct {
+		name string
+		args map[string]any
+		want string
+	}{
+		{"no token", selection(map[string]any{"confirm": "true"}), "confirm_token is required"},
+		{"stale token for other matchers", selection(map[string]any{"confirm": "true", "confirm_token": token, "matches": []any{`{job=~".+"}`}}), "does not match"},
+		{"stale token for other time range", selection(map[string]any{"confirm": "true", "confirm_token": token, "end": "2024-01-16T00:00:00Z"}), "does not match"},
+		{"token for another org ID", selection(map[string]any{"confirm": "true", "confirm_token": token, "org_id": "other"}), "does not match"},
+		{"unsigned token", selection(map[string]any{"confirm": "true", "confirm_token": "0." + strings.Repeat("0", 64)}), "does not match"},
+		{"dry run and confirm", selection(map[string]any{"confirm": "true", "confirm_token": token, "dry_run": "true"}), "not both"},
+	}
+	for _, tt := range rejected {
+		t.Run(tt.name, func(t *testing.T) {
+			result := call(tt.args)
+			if !result.IsError {
+				t.Fatalf("expected rejection, got: %v", result.Content)
+			}
+			if text := result.Content[0].(mcp.TextContent).Text; !strings.Contains(text, tt.want) {
+				t.Errorf("error %q does not contain %q", text, tt.want)
+			}
+		})
+	}
+	mu.Lock()
+	if len(deletes) != 0 {
+		t.Errorf("rejected calls deleted series: %v", deletes)
+	}
+	mu.Unlock()
+
+	result := call(selection(map[string]any{"confirm": "true", "confirm_token": token}))
+	if result.IsError {
+		t.Fatalf("confirmed deletion failed: %v", result.Content)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(deletes) != 1 {
+		t.Fatalf("expected one delete request, got %v", deletes)
+	}
+	for _, want := range []string{"match%5B%5D=%7Bjob%3D%22test%22%7D", "start=1705276800", "end=1705320000"} {
+		if !strings.Contains(deletes[0], want) {
+			t.Errorf("delete request %q missing %q", deletes[0], want)
+		}
+	}
+}
+
+func TestDeleteSeriesToken(t *testing.T) {
+	now := time.Unix(1705320000, 0)
+	sel := deleteSelection{matches: []string{"up", `{job="x"}`}, start: time.Unix(1, 0), end: time.Unix(2, 0), target: "http://prometheus:9090", orgID: "a", profile: "prod"}
+	token := deleteSeriesToken(sel, now)
+
+	reordered := sel
+	reordered.matches = []string{`{job="x"}`, "up"}
+	if err := checkDeleteSeriesToken(token, reordered, now); err != nil {
+		t.Errorf("token should not depend on matcher order: %v", err)
+	}
+	if err := checkDeleteSeriesToken(token, sel, now.Add(deleteTokenTTL+time.Second)); !errors.Is(err, errDeleteTokenExpired) {
+		t.Errorf("expired token: err = %v", err)
+	}
+
+	for name, change := range map[string]func(*deleteSelection){
+		"matches":  func(s *deleteSelection) { s.matches = []string{"up"} },
+		"end":      func(s *deleteSelection) { s.end = time.Unix(3, 0) },
+		"open end": func(s *deleteSelection) { s.end = time.Time{} },
+		"target":   func(s *deleteSelection) { s.target = "http://other:9090" },
+		"org ID":   func(s *deleteSelection) { s.orgID = "b" },
+		"profile":  func(s *deleteSelection) { s.profile = "" },
+	} {
+		other := sel
+		change(&other)
+		if err := checkDeleteSeriesToken(token, other, now); !errors.Is(err, errDeleteTokenMismatch) {
+			t.Errorf("%s changed: err = %v, want a mismatch", name, err)
+		}
+	}
+}
+
+func TestAdminToolsRequireOptIn(t *testing.T) {
+	sc, err := server.NewServerContext(context.Background(),
+		server.WithPrometheusConfig(server.PrometheusConfig{URL: "http://prometheus:9090"}),
+		server.WithSlogLogger(discardLogger()),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create server context: %v", err)
+	}
+	defer func() { _ = sc.Shutdown() }()
+
+	srv := mcpserver.NewMCPServer("test", "1.0.0", mcpserver.WithToolCapabilities(true))
+	if err := RegisterPrometheusTools(srv, sc); err != nil {
+		t.Fatalf("RegisterPrometheusTools: %v", err)
+	}
+	for _, name := range []string{toolPushMetric, toolDeleteSeries, toolSnapshotTSDB} {
+		if srv.GetTool(name) != nil {
+			t.Errorf("%s is registered without admin tools enabled", name)
+		}
+	}
+}
+
+func TestDeleteSeriesAnnotations(t *testing.T) {
+	st := newStructuredServer(t).GetTool(toolDeleteSeries)
+	if st == nil {
+		t.Fatalf("%s is not registered", toolDeleteSeries)
+	}
+	a := st.Tool.Annotations
+	if a.ReadOnlyHint == nil || *a.ReadOnlyHint || a.DestructiveHint == nil || !*a.DestructiveHint {
+		t.Errorf("annotations = %+v, want a destructive, non-read-only tool", a)
+	}
+	for _, param := range []string{"matches", "start", "end", "confirm", "confirm_token"} {
+		if _, ok := st.Tool.InputSchema.Properties[param]; !ok {
+			t.Errorf("missing parameter %q", param)
+		}
+	}
+}
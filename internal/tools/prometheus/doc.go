@@ -19,6 +19,10 @@
 // All tools support the standard Prometheus HTTP API and handle
 // authentication automatically based on the server configuration.
 //
+// Tools that return Prometheus API data declare an output schema and return
+// structured content (see QueryOutput and the other *Output types) alongside
+// the text rendering.
+//
 // Example tool usage:
 //
 //	execute_query: {"query": "up", "time": "2023-01-01T00:00:00Z"}
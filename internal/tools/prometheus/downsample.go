@@ -0,0 +1,38 @@
+package prometheus
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/prometheus/common/model"
+
+	"github.com/giantswarm/mcp-prometheus/internal/analysis"
+)
+
+// parseMaxPointsPerSeries reads max_points_per_series; 0 means the result is
+// not downsampled.
+func parseMaxPointsPerSeries(params map[string]any) (int, error) {
+	s := getStringParam(params, "max_points_per_series")
+	if s == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 2 {
+		return 0, fmt.Errorf("invalid max_points_per_series %q: must be an integer of at least 2", s)
+	}
+	return n, nil
+}
+
+// downsampleMatrix reduces the float samples of every series of m to at most
+// maxPoints in place (see analysis.Downsample) and returns the number of
+// series that had more. Native histogram samples are left unchanged.
+func downsampleMatrix(m model.Matrix, maxPoints int) int {
+	reduced := 0
+	for _, s := range m {
+		if len(s.Values) > maxPoints {
+			s.Values = analysis.Downsample(s.Values, maxPoints)
+			reduced++
+		}
+	}
+	return reduced
+}
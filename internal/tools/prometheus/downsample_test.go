@@ -0,0 +1,73 @@
+package prometheus
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/giantswarm/mcp-prometheus/internal/server"
+)
+
+func TestHandleExecuteRangeQueryMaxPointsPerSeries(t *testing.T) {
+	values := make([]any, 100)
+	for i := range values {
+		values[i] = []any{1704067200 + 15*i, "1"}
+	}
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			respKeyStatus: respValSuccess,
+			respKeyData: map[string]any{respKeyResultType: "matrix", respKeyResult: []any{
+				map[string]any{"metric": map[string]string{"job": "long"}, "values": values},
+				map[string]any{"metric": map[string]string{"job": "short"}, "values": values[:5]},
+			}},
+		})
+	}))
+	defer mockServer.Close()
+
+	ctx := context.Background()
+	sc, err := server.NewServerContext(ctx,
+		server.WithPrometheusConfig(server.PrometheusConfig{URL: mockServer.URL}),
+		server.WithSlogLogger(discardLogger()),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create server context: %v", err)
+	}
+	defer func() { _ = sc.Shutdown() }()
+	client, err := NewClient(sc.PrometheusConfig(), sc.Logger())
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+
+	args := map[string]any{
+		paramKeyQuery: "up",
+		"start":       "1704067200",
+		"end":         "1704068700",
+		"step":        "15s",
+	}
+	request := mcp.CallToolRequest{Params: mcp.CallToolParams{Name: toolExecuteRangeQuery, Arguments: args}}
+	args["max_points_per_series"] = "10"
+	result, err := handleExecuteRangeQuery(ctx, request, client, sc)
+	if err != nil || result.IsError {
+		t.Fatalf("unexpected failure: %v %v", err, result)
+	}
+	out := result.StructuredContent.(QueryOutput)
+	if out.Downsampled != 1 || len(out.Series[0].Samples) != 10 || len(out.Series[1].Samples) != 5 {
+		t.Errorf("downsampled = %d, samples = %d and %d; want 1, 10 and 5", out.Downsampled, len(out.Series[0].Samples), len(out.Series[1].Samples))
+	}
+	if text := result.Content[0].(mcp.TextContent).Text; !strings.HasPrefix(text, "Downsampled 1 series to 10 points each.") {
+		t.Errorf("expected a downsampling note:\n%s", text)
+	}
+
+	for _, v := range []string{"1", "ten"} {
+		args["max_points_per_series"] = v
+		if result, _ := handleExecuteRangeQuery(ctx, request, client, sc); !result.IsError {
+			t.Errorf("max_points_per_series=%q: expected an error", v)
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package prometheus
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/giantswarm/mcp-prometheus/internal/server"
+)
+
+// Error codes reported instead of the error text when the server runs with
+// server.ErrorVerbositySafe.
+const (
+	errCodeToolFailed  = "PROM-E000"
+	errCodeQuery       = "PROM-E001"
+	errCodeClient      = "PROM-E002"
+	errCodeDiscovery   = "PROM-E003"
+	errCodeStatus      = "PROM-E004"
+	errCodeRemoteWrite = "PROM-E005"
+	errCodeFederation  = "PROM-E006"
+	errCodeAdmin       = "PROM-E007"
+)
+
+// clientErrorPrefix starts the error result of a tool call whose Prometheus
+// client could not be created.
+const clientErrorPrefix = "Error creating Prometheus client"
+
+// errorCodeRegistry maps each error code to the message shown to clients.
+var errorCodeRegistry = map[string]string{
+	errCodeToolFailed:  "tool call failed",
+	errCodeQuery:       "query execution failed",
+	errCodeClient:      "could not connect to Prometheus",
+	errCodeDiscovery:   "metric discovery request failed",
+	errCodeStatus:      "Prometheus status request failed",
+	errCodeRemoteWrite: "remote write failed",
+	errCodeFederation:  "federation request failed",
+	errCodeAdmin:       "TSDB admin request failed",
+}
+
+// toolErrorCodes maps tool names to the code their failures are reported
+// under. Tools not listed use errCodeToolFailed.
+var toolErrorCodes = map[string]string{
+	toolExecuteQuery:              errCodeQuery,
+	toolExecuteRangeQuery:         errCodeQuery,
+	toolQueryTemplates:            errCodeQuery,
+	toolExecuteMultiQuery:         errCodeQuery,
+	"query_exemplars":             errCodeQuery,
+	"analyze_anomalies":           errCodeQuery,
+	"suggest_label_filters":       errCodeQuery,
+	toolGetSeriesCountHistory:     errCodeQuery,
+	toolSummarizeRangeQuery:       errCodeQuery,
+	toolFormatPromQL:              errCodeQuery,
+	toolQueryBuilder:              errCodeQuery,
+	toolGenerateQuery:             errCodeQuery,
+	"get_metric_metadata":         errCodeDiscovery,
+	"list_label_names":            errCodeDiscovery,
+	"list_label_values":           errCodeDiscovery,
+	"find_series":                 errCodeDiscovery,
+	"find_metrics_by_help_text":   errCodeDiscovery,
+	toolSearchMetrics:             errCodeDiscovery,
+	"get_targets_metadata":        errCodeDiscovery,
+	"get_targets":                 errCodeStatus,
+	"get_build_info":              errCodeStatus,
+	"get_runtime_info":            errCodeStatus,
+	"get_flags":                   errCodeStatus,
+	"get_config":                  errCodeStatus,
+	"get_alerts":                  errCodeStatus,
+	toolSubscribeAlerts:           errCodeStatus,
+	"get_alertmanagers":           errCodeStatus,
+	"list_alertmanager_receivers": errCodeStatus,
+	"get_alertmanager_alerts":     errCodeStatus,
+	"get_rules":                   errCodeStatus,
+	"get_tsdb_stats":              errCodeStatus,
+	toolCollectionSummary:         errCodeStatus,
+	"check_ready":                 errCodeStatus,
+	toolCheckHealth:               errCodeStatus,
+	toolReloadConfig:              errCodeStatus,
+	toolPushMetric:                errCodeRemoteWrite,
+	"query_federated_metrics":     errCodeFederation,
+	toolDeleteSeries:              errCodeAdmin,
+	toolSnapshotTSDB:              errCodeAdmin,
+}
+
+// newRequestID returns a short random ID that ties an opaque client error to
+// the server log entry holding its details.
+func newRequestID() string {
+	b := make([]byte, 6)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// redactToolError applies the server's error verbosity to a tool result. In
+// safe mode a failed call (an error result, or a Go error) is logged in full
+// and replaced by "Error <code>: <message> (request ID: <id>)"; otherwise
+// result and err are returned unchanged.
+func redactToolError(sc *server.ServerContext, toolName string, result *mcp.CallToolResult, err error) (*mcp.CallToolResult, error) {
+	if sc.ErrorVerbosity() != server.ErrorVerbositySafe {
+		return result, err
+	}
+
+	var detail string
+	switch {
+	case err != nil:
+		detail = err.Error()
+	case result != nil && result.IsError:
+		var text strings.Builder
+		for _, c := range result.Content {
+			if tc, ok := c.(mcp.TextContent); ok {
+				text.WriteString(tc.Text)
+			}
+		}
+		detail = text.String()
+	default:
+		return result, err
+	}
+
+	return &mcp.CallToolResult{
+		IsError: true,
+		Content: []mcp.Content{
+			mcp.TextContent{
+				Type: contentTypeText,
+				Text: safeErrorText(sc, toolName, detail),
+			},
+		},
+	}, nil
+}
+
+// safeErrorText logs the failure detail of toolName and returns the opaque
+// "Error <code>: <message> (request ID: <id>)" text reported in its place.
+func safeErrorText(sc *server.ServerContext, toolName, detail string) string {
+	code, ok := toolErrorCodes[toolName]
+	if !ok {
+		code = errCodeToolFailed
+	}
+	if strings.HasPrefix(detail, clientErrorPrefix) {
+		code = errCodeClient
+	}
+	requestID := newRequestID()
+	sc.Logger().Error("Tool call failed", "tool", toolName, "error_code", code, "request_id", requestID, "error", detail)
+	return fmt.Sprintf("Error %s: %s (request ID: %s)", code, errorCodeRegistry[code], requestID)
+}
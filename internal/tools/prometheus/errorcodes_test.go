@@ -0,0 +1,106 @@
+package prometheus
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/giantswarm/mcp-prometheus/internal/server"
+)
+
+func TestErrorVerbosity(t *testing.T) {
+	const query = `sum(rate(secret_internal_metric{team="payments"}[5m]))`
+
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		_, _ = w.Write([]byte(`{"status":"error","errorType":"execution","error":"query ` + strings.ReplaceAll(query, `"`, `\"`) + ` timed out"}`))
+	}))
+	defer mockServer.Close()
+
+	tests := []struct {
+		name      string
+		verbosity server.ErrorVerbosity
+		args      map[string]any
+		want      *regexp.Regexp
+		leaks     bool
+	}{
+		{
+			name:      "detailed",
+			verbosity: server.ErrorVerbosityDetailed,
+			args:      map[string]any{paramKeyQuery: query},
+			want:      regexp.MustCompile(`^Error executing query: .*timed out$`),
+			leaks:     true,
+		},
+		{
+			name:      "safe query failure",
+			verbosity: server.ErrorVerbositySafe,
+			args:      map[string]any{paramKeyQuery: query},
+			want:      regexp.MustCompile(`^Error PROM-E001: query execution failed \(request ID: [0-9a-f]{12}\)$`),
+		},
+		{
+			name:      "safe client failure",
+			verbosity: server.ErrorVerbositySafe,
+			args:      map[string]any{paramKeyQuery: query, "prometheus_url": "file:///etc/passwd"},
+			want:      regexp.MustCompile(`^Error PROM-E002: could not connect to Prometheus \(request ID: [0-9a-f]{12}\)$`),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			sc, err := server.NewServerContext(ctx,
+				server.WithPrometheusConfig(server.PrometheusConfig{URL: mockServer.URL}),
+				server.WithSlogLogger(discardLogger()),
+				server.WithErrorVerbosity(tt.verbosity),
+			)
+			if err != nil {
+				t.Fatalf("Failed to create server context: %v", err)
+			}
+			defer func() { _ = sc.Shutdown() }()
+
+			client, err := NewClient(sc.PrometheusConfig(), sc.Logger())
+			if err != nil {
+				t.Fatalf("NewClient: %v", err)
+			}
+
+			handler := withDynamicPrometheusClient(handleExecuteQuery, client, sc)
+			request := mcp.CallToolRequest{Params: mcp.CallToolParams{Name: toolExecuteQuery, Arguments: tt.args}}
+			result, err := handler(ctx, request)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !result.IsError {
+				t.Fatalf("expected an error result, got %v", result.Content)
+			}
+			text := result.Content[0].(mcp.TextContent).Text
+			if !tt.want.MatchString(text) {
+				t.Errorf("result %q does not match %s", text, tt.want)
+			}
+			if leaked := strings.Contains(text, "secret_internal_metric"); leaked != tt.leaks {
+				t.Errorf("query text in output = %t, want %t: %q", leaked, tt.leaks, text)
+			}
+		})
+	}
+}
+
+func TestRedactToolErrorPassesSuccess(t *testing.T) {
+	sc, err := server.NewServerContext(context.Background(),
+		server.WithSlogLogger(discardLogger()),
+		server.WithErrorVerbosity(server.ErrorVerbositySafe),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create server context: %v", err)
+	}
+	defer func() { _ = sc.Shutdown() }()
+
+	ok := &mcp.CallToolResult{Content: []mcp.Content{mcp.TextContent{Type: contentTypeText, Text: "fine"}}}
+	if got, err := redactToolError(sc, "unknown_tool", ok, nil); got != ok || err != nil {
+		t.Errorf("expected successful result to pass through, got %v, %v", got, err)
+	}
+}
@@ -0,0 +1,213 @@
+package prometheus
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	v1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/prometheus/common/model"
+
+	"github.com/giantswarm/mcp-prometheus/internal/server"
+)
+
+// Trace backends accepted by the trace_backend parameter of query_exemplars.
+const (
+	traceBackendJaeger  = "jaeger"
+	traceBackendTempo   = "tempo"
+	traceBackendZipkin  = "zipkin"
+	traceBackendGeneric = "generic"
+)
+
+// Exemplar label names that carry the trace and span ID, in lookup order.
+// Instrumentation libraries disagree on the spelling.
+var (
+	traceIDLabels = []model.LabelName{"traceID", "trace_id", "traceId"}
+	spanIDLabels  = []model.LabelName{"spanID", "span_id", "spanId"}
+)
+
+// exemplarLabel returns the value of the first of names present in labels.
+func exemplarLabel(labels model.LabelSet, names []model.LabelName) string {
+	for _, name := range names {
+		if v, ok := labels[name]; ok && v != "" {
+			return string(v)
+		}
+	}
+	return ""
+}
+
+// traceLinkBuilder turns a trace and span ID into a deep link to the trace
+// UI.
+type traceLinkBuilder struct {
+	backend  string
+	baseURL  string
+	template string
+}
+
+// newTraceLinkBuilder validates backend and template. Jaeger always uses its
+// native /trace/{traceID}?uiFind={spanID} link; tempo and zipkin use their
+// built-in link unless template is set; generic requires template.
+func newTraceLinkBuilder(backend, baseURL, template string) (*traceLinkBuilder, error) {
+	if backend == "" {
+		backend = traceBackendJaeger
+	}
+	switch backend {
+	case traceBackendJaeger, traceBackendTempo, traceBackendZipkin:
+	case traceBackendGeneric:
+		if template == "" {
+			return nil, fmt.Errorf("trace_url_template is required when trace_backend is %q", traceBackendGeneric)
+		}
+	default:
+		return nil, fmt.Errorf("trace_backend must be one of jaeger, tempo, zipkin or generic (got %q)", backend)
+	}
+	return &traceLinkBuilder{backend: backend, baseURL: strings.TrimRight(baseURL, "/"), template: template}, nil
+}
+
+// link returns the deep link for traceID and spanID, or "" when traceID is
+// empty or the backend needs a base URL that is not configured.
+func (b *traceLinkBuilder) link(traceID, spanID string) string {
+	if traceID == "" {
+		return ""
+	}
+	if b.template != "" && b.backend != traceBackendJaeger {
+		return strings.NewReplacer(
+			"{baseURL}", b.baseURL,
+			"{traceID}", url.QueryEscape(traceID),
+			"{spanID}", url.QueryEscape(spanID),
+		).Replace(b.template)
+	}
+	if b.baseURL == "" {
+		return ""
+	}
+
+	switch b.backend {
+	case traceBackendTempo:
+		// Grafana Explore link querying the Tempo data source by trace ID.
+		left, _ := json.Marshal(map[string]any{
+			"datasource": "tempo",
+			"queries":    []map[string]string{{"refId": "A", "queryType": "traceql", "query": traceID}},
+		})
+		return b.baseURL + "/explore?left=" + url.QueryEscape(string(left))
+	case traceBackendZipkin:
+		return b.baseURL + "/zipkin/traces/" + url.PathEscape(traceID)
+	default:
+		link := b.baseURL + "/trace/" + url.PathEscape(traceID)
+		if spanID != "" {
+			link += "?uiFind=" + url.QueryEscape(spanID)
+		}
+		return link
+	}
+}
+
+// formatExemplars renders exemplars series by series as
+// "timestamp | value | trace_id | span_id | link" rows.
+func formatExemplars(query string, results []v1.ExemplarQueryResult, links *traceLinkBuilder) string {
+	total := 0
+	for _, r := range results {
+		total += len(r.Exemplars)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Exemplars for query '%s': %d exemplars in %d series\n", query, total, len(results))
+	for _, r := range results {
+		fmt.Fprintf(&b, "\n%s:\n", r.SeriesLabels)
+		b.WriteString("timestamp | value | trace_id | span_id | link\n")
+		for _, e := range r.Exemplars {
+			traceID := exemplarLabel(e.Labels, traceIDLabels)
+			spanID := exemplarLabel(e.Labels, spanIDLabels)
+			fmt.Fprintf(&b, "%s | %s | %s | %s | %s\n",
+				formatResultTime(e.Timestamp), e.Value, valueOrNone(traceID), valueOrNone(spanID), valueOrNone(links.link(traceID, spanID)))
+		}
+	}
+	return b.String()
+}
+
+// handleQueryExemplars handles the query_exemplars tool
+func handleQueryExemplars(ctx context.Context, request mcp.CallToolRequest, client *Client, sc *server.ServerContext) (*mcp.CallToolResult, error) {
+	params := extractParams(request)
+
+	query, ok := params["query"].(string)
+	if !ok || query == "" {
+		return &mcp.CallToolResult{
+			IsError: true,
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: contentTypeText,
+					Text: errQueryParameterRequired,
+				},
+			},
+		}, nil
+	}
+
+	start, ok := params["start"].(string)
+	if !ok || start == "" {
+		return &mcp.CallToolResult{
+			IsError: true,
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: contentTypeText,
+					Text: "Error: start parameter is required and must be a string",
+				},
+			},
+		}, nil
+	}
+
+	end, ok := params["end"].(string)
+	if !ok || end == "" {
+		return &mcp.CallToolResult{
+			IsError: true,
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: contentTypeText,
+					Text: "Error: end parameter is required and must be a string",
+				},
+			},
+		}, nil
+	}
+
+	// Invalid times are reported by the query itself.
+	startTime, endTime, _ := parseFilterTimes(start, end)
+	if refused := checkQueryPolicy(sc, query, startTime, endTime, 0); refused != nil {
+		return refused, nil
+	}
+
+	links, err := newTraceLinkBuilder(getStringParam(params, "trace_backend"), sc.PrometheusConfig().TraceBaseURL, getStringParam(params, "trace_url_template"))
+	if err != nil {
+		return &mcp.CallToolResult{
+			IsError: true,
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: contentTypeText,
+					Text: fmt.Sprintf("Error: %v", err),
+				},
+			},
+		}, nil
+	}
+	sc.Logger().Debug("Querying exemplars", "query", query, "start", start, "end", end, "trace_backend", links.backend)
+
+	exemplars, err := client.QueryExemplars(ctx, query, start, end)
+	if err != nil {
+		sc.Logger().Error("Failed to query exemplars", "error", err)
+		return &mcp.CallToolResult{
+			IsError: true,
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: contentTypeText,
+					Text: fmt.Sprintf("Error querying exemplars: %v", err),
+				},
+			},
+		}, nil
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.TextContent{
+				Type: contentTypeText,
+				Text: formatExemplars(query, exemplars, links),
+			},
+		},
+	}, nil
+}
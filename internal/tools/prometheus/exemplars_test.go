@@ -0,0 +1,181 @@
+package prometheus
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/prometheus/common/model"
+
+	"github.com/giantswarm/mcp-prometheus/internal/server"
+)
+
+const (
+	sampleTraceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	sampleSpanID  = "00f067aa0ba902b7"
+)
+
+func TestTraceLinkBuilder(t *testing.T) {
+	tests := []struct {
+		name     string
+		backend  string
+		baseURL  string
+		template string
+		spanID   string
+		want     string
+	}{
+		{
+			name:    "jaeger",
+			backend: traceBackendJaeger,
+			baseURL: "http://jaeger:16686/",
+			spanID:  sampleSpanID,
+			want:    "http://jaeger:16686/trace/" + sampleTraceID + "?uiFind=" + sampleSpanID,
+		},
+		{
+			name:    "jaeger is the default",
+			baseURL: "http://jaeger:16686",
+			want:    "http://jaeger:16686/trace/" + sampleTraceID,
+		},
+		{
+			name:     "jaeger ignores the template",
+			backend:  traceBackendJaeger,
+			baseURL:  "http://jaeger:16686",
+			template: "http://other/{traceID}",
+			spanID:   sampleSpanID,
+			want:     "http://jaeger:16686/trace/" + sampleTraceID + "?uiFind=" + sampleSpanID,
+		},
+		{
+			name:    "tempo",
+			backend: traceBackendTempo,
+			baseURL: "http://tempo:3000",
+			spanID:  sampleSpanID,
+			want:    "http://tempo:3000/explore?left=%7B%22datasource%22%3A%22tempo%22%2C%22queries%22%3A%5B%7B%22query%22%3A%22" + sampleTraceID + "%22%2C%22queryType%22%3A%22traceql%22%2C%22refId%22%3A%22A%22%7D%5D%7D",
+		},
+		{
+			name:    "zipkin",
+			backend: traceBackendZipkin,
+			baseURL: "http://zipkin:9411",
+			want:    "http://zipkin:9411/zipkin/traces/" + sampleTraceID,
+		},
+		{
+			name:     "tempo with template",
+			backend:  traceBackendTempo,
+			baseURL:  "http://grafana",
+			template: "{baseURL}/a/tempo-app/trace/{traceID}",
+			want:     "http://grafana/a/tempo-app/trace/" + sampleTraceID,
+		},
+		{
+			name:     "generic",
+			backend:  traceBackendGeneric,
+			template: "https://traces.example.com/t/{traceID}/s/{spanID}",
+			spanID:   sampleSpanID,
+			want:     "https://traces.example.com/t/" + sampleTraceID + "/s/" + sampleSpanID,
+		},
+		{
+			name:    "no base URL",
+			backend: traceBackendZipkin,
+			want:    "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := newTraceLinkBuilder(tt.backend, tt.baseURL, tt.template)
+			if err != nil {
+				t.Fatalf("newTraceLinkBuilder: %v", err)
+			}
+			if got := b.link(sampleTraceID, tt.spanID); got != tt.want {
+				t.Errorf("link() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewTraceLinkBuilderErrors(t *testing.T) {
+	if _, err := newTraceLinkBuilder(traceBackendGeneric, "", ""); err == nil || !strings.Contains(err.Error(), "trace_url_template is required") {
+		t.Errorf("expected missing template error, got %v", err)
+	}
+	if _, err := newTraceLinkBuilder("honeycomb", "", ""); err == nil || !strings.Contains(err.Error(), "trace_backend must be one of") {
+		t.Errorf("expected unknown backend error, got %v", err)
+	}
+}
+
+func TestExemplarLabel(t *testing.T) {
+	for _, labels := range []model.LabelSet{
+		{"traceID": sampleTraceID},
+		{"trace_id": sampleTraceID},
+		{"traceId": sampleTraceID},
+	} {
+		if got := exemplarLabel(labels, traceIDLabels); got != sampleTraceID {
+			t.Errorf("exemplarLabel(%v) = %q, want %q", labels, got, sampleTraceID)
+		}
+	}
+	if got := exemplarLabel(model.LabelSet{"span_id": sampleSpanID}, spanIDLabels); got != sampleSpanID {
+		t.Errorf("exemplarLabel() = %q, want %q", got, sampleSpanID)
+	}
+}
+
+func TestHandleQueryExemplars(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/query_exemplars" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"status":"success","data":[{
+			"seriesLabels":{"__name__":"http_request_duration_seconds_bucket","job":"api"},
+			"exemplars":[
+				{"labels":{"trace_id":"` + sampleTraceID + `","span_id":"` + sampleSpanID + `"},"value":"0.25","timestamp":1704067200.5},
+				{"labels":{"user":"alice"},"value":"0.5","timestamp":1704067201}
+			]}]}`))
+	}))
+	defer mockServer.Close()
+
+	ctx := context.Background()
+	sc, err := server.NewServerContext(ctx,
+		server.WithPrometheusConfig(server.PrometheusConfig{URL: mockServer.URL, TraceBaseURL: "http://jaeger:16686"}),
+		server.WithSlogLogger(discardLogger()),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create server context: %v", err)
+	}
+	defer func() { _ = sc.Shutdown() }()
+
+	client, err := NewClient(sc.PrometheusConfig(), sc.Logger())
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+
+	request := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Name: "query_exemplars",
+			Arguments: map[string]any{
+				"query": "http_request_duration_seconds_bucket",
+				"start": "1704067000",
+				"end":   "1704067300",
+			},
+		},
+	}
+	result, err := handleQueryExemplars(ctx, request, client, sc)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.IsError {
+		t.Fatalf("expected success, got error: %v", result.Content)
+	}
+	text := result.Content[0].(mcp.TextContent).Text
+
+	for _, want := range []string{
+		"2 exemplars in 1 series",
+		`{__name__="http_request_duration_seconds_bucket", job="api"}:`,
+		"2024-01-01T00:00:00.500Z | 0.25 | " + sampleTraceID + " | " + sampleSpanID + " | http://jaeger:16686/trace/" + sampleTraceID + "?uiFind=" + sampleSpanID,
+		"2024-01-01T00:00:01.000Z | 0.5 | (none) | (none) | (none)",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("expected output to contain %q, got:\n%s", want, text)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package prometheus
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	mcpserver "github.com/mark3labs/mcp-go/server"
+
+	"github.com/giantswarm/mcp-prometheus/internal/promql"
+	"github.com/giantswarm/mcp-prometheus/internal/server"
+)
+
+// toolExplainPromQL is the registered name of the local PromQL explanation
+// tool.
+const toolExplainPromQL = "explain_promql"
+
+// registerExplainPromQLTool registers explain_promql. Like validate_promql it
+// works on the parsed expression only and takes no connection parameters.
+func registerExplainPromQLTool(s *mcpserver.MCPServer, sc *server.ServerContext, middleware []ToolMiddleware) {
+	tool := mcp.NewTool(toolExplainPromQL,
+		mcp.WithDescription("Explain a PromQL expression without contacting the server: a plain-English description of what it computes, plus its series selectors with matchers and range windows, functions, aggregations and binary operations. Useful for queries found in dashboards and alert rules"),
+		mcp.WithString("query", mcp.Required(), mcp.Description("PromQL expression to explain")),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithDestructiveHintAnnotation(false),
+		mcp.WithOpenWorldHintAnnotation(false),
+		mcp.WithSchemaAdditionalProperties(false),
+	)
+
+	h := withServerMiddleware(sc, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return handleExplainPromQL(ctx, request, sc)
+	})
+	for _, mw := range middleware {
+		h = mw(toolExplainPromQL, h)
+	}
+	s.AddTool(tool, h)
+}
+
+// handleExplainPromQL handles the explain_promql tool
+func handleExplainPromQL(_ context.Context, request mcp.CallToolRequest, sc *server.ServerContext) (*mcp.CallToolResult, error) {
+	params := extractParams(request)
+
+	query := getStringParam(params, "query")
+	if query == "" {
+		return &mcp.CallToolResult{
+			IsError: true,
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: contentTypeText,
+					Text: errQueryParameterRequired,
+				},
+			},
+		}, nil
+	}
+
+	sc.Logger().Debug("Explaining PromQL", "query", query)
+
+	expr, err := promql.Parse(query)
+	if err != nil {
+		return &mcp.CallToolResult{
+			IsError: true,
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: contentTypeText,
+					Text: formatSyntaxErrors(query, promql.SyntaxErrors(query, err)),
+				},
+			},
+		}, nil
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.TextContent{
+				Type: contentTypeText,
+				Text: formatExplanation(promql.Explain(expr)),
+			},
+		},
+	}, nil
+}
+
+// formatExplanation renders e as the description followed by one section
+// per non-empty part of the breakdown.
+func formatExplanation(e promql.Explanation) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s\n\nResult type: %s\n", e.Text, e.Type)
+
+	if len(e.Selectors) > 0 {
+		fmt.Fprintf(&b, "\nSelectors (%d):\n", len(e.Selectors))
+		for _, s := range e.Selectors {
+			fmt.Fprintf(&b, "  %s\n", s.Selector)
+			if s.Metric != "" {
+				fmt.Fprintf(&b, "    metric: %s\n", s.Metric)
+			}
+			if len(s.Matchers) > 0 {
+				fmt.Fprintf(&b, "    matchers: %s\n", strings.Join(s.Matchers, ", "))
+			}
+			if s.Range != "" {
+				fmt.Fprintf(&b, "    range: %s\n", s.Range)
+			}
+		}
+	}
+	for _, section := range []struct {
+		title string
+		items []string
+	}{
+		{"Functions", e.Functions},
+		{"Aggregations", e.Aggregations},
+		{"Binary operations", e.BinaryOperations},
+	} {
+		if len(section.items) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "\n%s (%d):\n", section.title, len(section.items))
+		for _, item := range section.items {
+			fmt.Fprintf(&b, "  %s\n", item)
+		}
+	}
+	return b.String()
+}
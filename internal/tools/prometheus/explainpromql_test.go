@@ -0,0 +1,60 @@
+package prometheus
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/giantswarm/mcp-prometheus/internal/server"
+)
+
+func TestHandleExplainPromQL(t *testing.T) {
+	sc, err := server.NewServerContext(context.Background(), server.WithSlogLogger(discardLogger()))
+	if err != nil {
+		t.Fatalf("Failed to create server context: %v", err)
+	}
+	defer func() { _ = sc.Shutdown() }()
+
+	call := func(query string) (string, bool) {
+		t.Helper()
+		request := mcp.CallToolRequest{Params: mcp.CallToolParams{Name: toolExplainPromQL, Arguments: map[string]any{paramKeyQuery: query}}}
+		result, err := handleExplainPromQL(context.Background(), request, sc)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return result.Content[0].(mcp.TextContent).Text, result.IsError
+	}
+
+	text, isErr := call(`sum by (job) (rate(http_requests_total{code=~"5..",job!=""}[5m]))`)
+	if isErr {
+		t.Fatalf("expected success, got %q", text)
+	}
+	want := `Computes the sum of the per-second average rate of increase of http_requests_total{code=~"5..",job!=""} over the last 5m per job.
+
+Result type: vector
+
+Selectors (1):
+  http_requests_total{code=~"5..",job!=""}[5m]
+    metric: http_requests_total
+    matchers: code=~"5..", job!=""
+    range: 5m
+
+Functions (1):
+  rate(range vector) → instant vector
+
+Aggregations (1):
+  sum by (job)
+`
+	if text != want {
+		t.Errorf("output =\n%s\nwant\n%s", text, want)
+	}
+
+	if text, isErr := call("rate(up[5m]"); !isErr || !strings.Contains(text, "unclosed left parenthesis") {
+		t.Errorf("syntax error = %q (error %t)", text, isErr)
+	}
+	if _, isErr := call(""); !isErr {
+		t.Error("expected an error without a query")
+	}
+}
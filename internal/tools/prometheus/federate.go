@@ -0,0 +1,119 @@
+package prometheus
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+	"github.com/prometheus/common/model"
+
+	"github.com/giantswarm/mcp-prometheus/internal/server"
+)
+
+// handleQueryFederatedMetrics handles the query_federated_metrics tool
+func handleQueryFederatedMetrics(ctx context.Context, request mcp.CallToolRequest, client *Client, sc *server.ServerContext) (*mcp.CallToolResult, error) {
+	params := extractParams(request)
+
+	matches := extractStringArray(params, "matches")
+	if len(matches) == 0 {
+		return &mcp.CallToolResult{
+			IsError: true,
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: contentTypeText,
+					Text: "Error: matches parameter is required and must be an array of strings",
+				},
+			},
+		}, nil
+	}
+
+	limit := 0
+	if v := getStringParam(params, "limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{
+					mcp.TextContent{
+						Type: contentTypeText,
+						Text: fmt.Sprintf("Error: limit must be a positive integer (got %q)", v),
+					},
+				},
+			}, nil
+		}
+		limit = n
+	}
+	outputFormat := getStringParam(params, "format")
+
+	sc.Logger().Debug("Querying federate endpoint", "matches", matches, "limit", limit, "format", outputFormat)
+
+	families, err := client.QueryFederated(ctx, matches)
+	if err != nil {
+		sc.Logger().Error("Failed to query federate endpoint", "error", err)
+		return &mcp.CallToolResult{
+			IsError: true,
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: contentTypeText,
+					Text: fmt.Sprintf("Error querying federate endpoint: %v", err),
+				},
+			},
+		}, nil
+	}
+
+	text, err := formatFederatedMetrics(families, limit, outputFormat == "json")
+	if err != nil {
+		return &mcp.CallToolResult{
+			IsError: true,
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: contentTypeText,
+					Text: fmt.Sprintf("Error formatting federated metrics: %v", err),
+				},
+			},
+		}, nil
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.TextContent{
+				Type: contentTypeText,
+				Text: text,
+			},
+		},
+	}, nil
+}
+
+// formatFederatedMetrics flattens families into samples (histograms and
+// summaries become their _bucket, _sum and _count series), keeps at most
+// limit of them (0 keeps all) and renders them as a vector table or, with
+// asJSON, as a JSON array of samples.
+func formatFederatedMetrics(families []*dto.MetricFamily, limit int, asJSON bool) (string, error) {
+	samples, err := expfmt.ExtractSamples(&expfmt.DecodeOptions{Timestamp: model.Now()}, families...)
+	if err != nil {
+		return "", fmt.Errorf("extract samples: %w", err)
+	}
+
+	total := len(samples)
+	if limit > 0 && total > limit {
+		samples = samples[:limit]
+	}
+
+	if asJSON {
+		data, err := json.MarshalIndent(samples, "", "  ")
+		if err != nil {
+			return "", fmt.Errorf("marshal samples: %w", err)
+		}
+		return string(data), nil
+	}
+
+	header := fmt.Sprintf("Federated metrics: %d metric families, %d series", len(families), total)
+	if len(samples) < total {
+		header += fmt.Sprintf(" (showing the first %d; raise limit to see more)", len(samples))
+	}
+	return header + "\n\n" + formatVector(samples, ""), nil
+}
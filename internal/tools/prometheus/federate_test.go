@@ -0,0 +1,133 @@
+package prometheus
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/giantswarm/mcp-prometheus/internal/server"
+)
+
+// federateResponse is a /federate response in the text exposition format,
+// as returned by Prometheus.
+const federateResponse = `# TYPE up untyped
+up{instance="localhost:9090",job="prometheus"} 1 1704067200000
+up{instance="localhost:9100",job="node"} 0 1704067200000
+# TYPE http_request_duration_seconds histogram
+http_request_duration_seconds_bucket{job="api",le="0.1"} 5 1704067200000
+http_request_duration_seconds_bucket{job="api",le="+Inf"} 8 1704067200000
+http_request_duration_seconds_sum{job="api"} 1.5 1704067200000
+http_request_duration_seconds_count{job="api"} 8 1704067200000
+`
+
+func newFederateTestClient(t *testing.T) (*Client, *[]string) {
+	t.Helper()
+
+	var gotMatches []string
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/federate" {
+			http.NotFound(w, r)
+			return
+		}
+		gotMatches = r.URL.Query()["match[]"]
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		_, _ = w.Write([]byte(federateResponse))
+	}))
+	t.Cleanup(mockServer.Close)
+
+	client, err := NewClient(server.PrometheusConfig{URL: mockServer.URL}, discardLogger())
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	return client, &gotMatches
+}
+
+func TestQueryFederated(t *testing.T) {
+	client, gotMatches := newFederateTestClient(t)
+
+	families, err := client.QueryFederated(context.Background(), []string{"up", `{__name__=~"http_.*"}`})
+	if err != nil {
+		t.Fatalf("QueryFederated: %v", err)
+	}
+
+	if want := []string{"up", `{__name__=~"http_.*"}`}; !reflect.DeepEqual(*gotMatches, want) {
+		t.Errorf("match[] = %v, want %v", *gotMatches, want)
+	}
+
+	if len(families) != 2 {
+		t.Fatalf("expected 2 metric families, got %d", len(families))
+	}
+	if families[0].GetName() != "http_request_duration_seconds" || families[1].GetName() != "up" {
+		t.Errorf("families not sorted by name: %s, %s", families[0].GetName(), families[1].GetName())
+	}
+	if got := len(families[1].GetMetric()); got != 2 {
+		t.Errorf("expected 2 up series, got %d", got)
+	}
+	if got := families[0].GetMetric()[0].GetHistogram().GetSampleCount(); got != 8 {
+		t.Errorf("histogram sample count = %d, want 8", got)
+	}
+}
+
+func TestHandleQueryFederatedMetrics(t *testing.T) {
+	client, _ := newFederateTestClient(t)
+	sc, err := server.NewServerContext(context.Background(), server.WithSlogLogger(discardLogger()))
+	if err != nil {
+		t.Fatalf("Failed to create server context: %v", err)
+	}
+	defer func() { _ = sc.Shutdown() }()
+
+	call := func(args map[string]any) *mcp.CallToolResult {
+		var req mcp.CallToolRequest
+		req.Params.Arguments = args
+		result, err := handleQueryFederatedMetrics(context.Background(), req, client, sc)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return result
+	}
+
+	t.Run("text", func(t *testing.T) {
+		result := call(map[string]any{"matches": []any{"up"}})
+		text := result.Content[0].(mcp.TextContent).Text
+		for _, want := range []string{
+			"Federated metrics: 2 metric families, 6 series",
+			`up{instance="localhost:9090", job="prometheus"} | 1 | 2024-01-01T00:00:00.000Z`,
+			`http_request_duration_seconds_bucket{job="api", le="+Inf"} | 8 |`,
+			`http_request_duration_seconds_count{job="api"} | 8 |`,
+		} {
+			if !strings.Contains(text, want) {
+				t.Errorf("expected %q in output:\n%s", want, text)
+			}
+		}
+	})
+
+	t.Run("limit", func(t *testing.T) {
+		text := call(map[string]any{"matches": []any{"up"}, "limit": "2"}).Content[0].(mcp.TextContent).Text
+		if !strings.Contains(text, "(showing the first 2; raise limit to see more)") || !strings.Contains(text, "2 series:") {
+			t.Errorf("expected output limited to 2 series:\n%s", text)
+		}
+	})
+
+	t.Run("json", func(t *testing.T) {
+		text := call(map[string]any{"matches": []any{"up"}, "format": "json"}).Content[0].(mcp.TextContent).Text
+		var samples []map[string]any
+		if err := json.Unmarshal([]byte(text), &samples); err != nil {
+			t.Fatalf("output is not JSON: %v\n%s", err, text)
+		}
+		if len(samples) != 6 {
+			t.Errorf("expected 6 samples, got %d", len(samples))
+		}
+	})
+
+	t.Run("missing matches", func(t *testing.T) {
+		if result := call(map[string]any{}); !result.IsError {
+			t.Error("expected an error without matches")
+		}
+	})
+}
@@ -0,0 +1,87 @@
+package prometheus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	v1 "github.com/prometheus/client_golang/api/prometheus/v1"
+
+	"github.com/giantswarm/mcp-prometheus/internal/promql"
+	"github.com/giantswarm/mcp-prometheus/internal/server"
+)
+
+// toolFormatPromQL is the registered name of the PromQL formatting tool.
+const toolFormatPromQL = "format_promql"
+
+// handleFormatPromQL handles the format_promql tool. The server's
+// format_query endpoint is preferred so the result matches the server's
+// parser; when the endpoint fails for any reason other than a syntax error
+// (e.g. Prometheus before 2.38 answers 404), the expression is formatted
+// with the bundled parser instead.
+func handleFormatPromQL(ctx context.Context, request mcp.CallToolRequest, client *Client, sc *server.ServerContext) (*mcp.CallToolResult, error) {
+	params := extractParams(request)
+
+	query := getStringParam(params, "query")
+	if query == "" {
+		return &mcp.CallToolResult{
+			IsError: true,
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: contentTypeText,
+					Text: errQueryParameterRequired,
+				},
+			},
+		}, nil
+	}
+
+	sc.Logger().Debug("Formatting PromQL", "query", query)
+
+	formatted, err := client.FormatQuery(ctx, query)
+	var apiErr *PrometheusAPIError
+	switch {
+	case err == nil:
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: contentTypeText,
+					Text: formatted,
+				},
+			},
+		}, nil
+	case errors.As(err, &apiErr) && apiErr.ErrorType == string(v1.ErrBadData):
+		return &mcp.CallToolResult{
+			IsError: true,
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: contentTypeText,
+					Text: describeQueryError("Error formatting query", err),
+				},
+			},
+		}, nil
+	}
+
+	sc.Logger().Info("format_query endpoint unavailable, formatting locally", "error", err)
+
+	formatted, localErr := promql.Format(query)
+	if localErr != nil {
+		return &mcp.CallToolResult{
+			IsError: true,
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: contentTypeText,
+					Text: formatSyntaxErrors(query, promql.SyntaxErrors(query, localErr)),
+				},
+			},
+		}, nil
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.TextContent{
+				Type: contentTypeText,
+				Text: fmt.Sprintf("%s\n\nNote: formatted locally because the server's format_query endpoint failed: %v", formatted, err),
+			},
+		},
+	}, nil
+}
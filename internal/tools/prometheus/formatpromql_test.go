@@ -0,0 +1,84 @@
+package prometheus
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/giantswarm/mcp-prometheus/internal/server"
+)
+
+func TestHandleFormatPromQL(t *testing.T) {
+	endpoint := true
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/format_query" || !endpoint {
+			// Prometheus before 2.38.
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_ = r.ParseForm()
+		w.Header().Set("Content-Type", "application/json")
+		if strings.Contains(r.Form.Get(paramKeyQuery), "(((") {
+			w.WriteHeader(http.StatusBadRequest)
+			_ = json.NewEncoder(w).Encode(map[string]any{respKeyStatus: "error", "errorType": "bad_data", "error": `1:4: parse error: unexpected "}"`})
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{respKeyStatus: respValSuccess, respKeyData: "server formatted: " + r.Form.Get(paramKeyQuery)})
+	}))
+	defer mockServer.Close()
+
+	ctx := context.Background()
+	sc, err := server.NewServerContext(ctx,
+		server.WithPrometheusConfig(server.PrometheusConfig{URL: mockServer.URL}),
+		server.WithSlogLogger(discardLogger()),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create server context: %v", err)
+	}
+	defer func() { _ = sc.Shutdown() }()
+
+	client, err := NewClient(sc.PrometheusConfig(), sc.Logger())
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+
+	call := func(query string) (string, bool) {
+		t.Helper()
+		request := mcp.CallToolRequest{Params: mcp.CallToolParams{Name: toolFormatPromQL, Arguments: map[string]any{paramKeyQuery: query}}}
+		result, err := handleFormatPromQL(ctx, request, client, sc)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return result.Content[0].(mcp.TextContent).Text, result.IsError
+	}
+
+	if text, isErr := call("sum(up)"); isErr || text != "server formatted: sum(up)" {
+		t.Errorf("server format = %q (error %t)", text, isErr)
+	}
+	if text, isErr := call("((("); !isErr || !strings.Contains(text, "Query parse error") {
+		t.Errorf("server syntax error = %q (error %t)", text, isErr)
+	}
+	if _, isErr := call(""); !isErr {
+		t.Error("expected an error without a query")
+	}
+
+	endpoint = false
+	text, isErr := call(`sum by (job) (rate(http_requests_total{code=~"5.."}[5m])) / sum by (job) (rate(http_requests_total[5m]))`)
+	if isErr {
+		t.Fatalf("expected the local fallback to succeed, got %q", text)
+	}
+	want := `  sum by (job) (rate(http_requests_total{code=~"5.."}[5m]))
+/
+  sum by (job) (rate(http_requests_total[5m]))`
+	if !strings.HasPrefix(text, want+"\n\nNote: formatted locally") {
+		t.Errorf("local format = %q, want it to start with %q", text, want)
+	}
+	if text, isErr := call("rate(up[5m]"); !isErr || !strings.Contains(text, "unclosed left parenthesis") {
+		t.Errorf("local syntax error = %q (error %t)", text, isErr)
+	}
+}
@@ -0,0 +1,44 @@
+package prometheus
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+
+	"github.com/giantswarm/mcp-prometheus/internal/server"
+)
+
+// gcpMonitoringReadScope is the OAuth2 scope needed to query Google Cloud
+// Managed Service for Prometheus.
+const gcpMonitoringReadScope = "https://www.googleapis.com/auth/monitoring.read"
+
+// gcpTokenSource returns a token source for Google Cloud Managed Service for
+// Prometheus: from the service account key config.GCPCredentialsFile, or
+// from Application Default Credentials (GOOGLE_APPLICATION_CREDENTIALS, the
+// gcloud user credentials or the metadata server) when it is empty. Tokens
+// are requested from Google directly, not through the Prometheus TLS
+// settings.
+func gcpTokenSource(config server.PrometheusConfig) (oauth2.TokenSource, error) {
+	return cachedTokenSource(func() (oauth2.TokenSource, error) {
+		ctx := context.Background()
+		if config.GCPCredentialsFile == "" {
+			credentials, err := google.FindDefaultCredentials(ctx, gcpMonitoringReadScope)
+			if err != nil {
+				return nil, fmt.Errorf("find Google Application Default Credentials: %w", err)
+			}
+			return credentials.TokenSource, nil
+		}
+		data, err := os.ReadFile(config.GCPCredentialsFile)
+		if err != nil {
+			return nil, fmt.Errorf("read GCP credentials %q: %w", config.GCPCredentialsFile, err)
+		}
+		credentials, err := google.CredentialsFromJSON(ctx, data, gcpMonitoringReadScope)
+		if err != nil {
+			return nil, fmt.Errorf("parse GCP credentials %q: %w", config.GCPCredentialsFile, err)
+		}
+		return credentials.TokenSource, nil
+	}, server.AuthModeGCP, config.GCPCredentialsFile)
+}
@@ -0,0 +1,125 @@
+package prometheus
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/giantswarm/mcp-prometheus/internal/server"
+)
+
+// writeServiceAccountKey writes a service account key whose token_uri points
+// at tokenURL and returns its path.
+func writeServiceAccountKey(t *testing.T, tokenURL string) string {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+	data, err := json.Marshal(map[string]string{
+		"type":           "service_account",
+		"project_id":     "my-project",
+		"private_key_id": "key-1",
+		"private_key":    string(keyPEM),
+		"client_email":   "gmp-reader@my-project.iam.gserviceaccount.com",
+		"token_uri":      tokenURL,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "sa.json")
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// resetTokenSources empties the token source cache after the test, so
+// cached sources pointing at closed test servers are not reused.
+func resetTokenSources(t *testing.T) {
+	t.Cleanup(func() {
+		tokenSourcesMu.Lock()
+		defer tokenSourcesMu.Unlock()
+		clear(tokenSources)
+	})
+}
+
+func TestNewClientGCPAuth(t *testing.T) {
+	resetTokenSources(t)
+
+	var issued atomic.Int32
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil || r.PostForm.Get("grant_type") != "urn:ietf:params:oauth:grant-type:jwt-bearer" || r.PostForm.Get("assertion") == "" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		issued.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"access_token":"ya29.gmp","token_type":"Bearer","expires_in":3600}`))
+	}))
+	defer tokenServer.Close()
+	prometheus := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer ya29.gmp" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		tlsQueryHandler(w, r)
+	}))
+	defer prometheus.Close()
+
+	keyFile := writeServiceAccountKey(t, tokenServer.URL)
+
+	t.Run("credentials file", func(t *testing.T) {
+		config := server.PrometheusConfig{URL: prometheus.URL, AuthMode: server.AuthModeGCP, GCPCredentialsFile: keyFile}
+		client, err := NewClient(config, discardLogger())
+		if err != nil {
+			t.Fatalf("NewClient: %v", err)
+		}
+		if _, err := client.ExecuteQuery(context.Background(), "up", ""); err != nil {
+			t.Fatalf("ExecuteQuery: %v", err)
+		}
+	})
+
+	t.Run("application default credentials", func(t *testing.T) {
+		t.Setenv("GOOGLE_APPLICATION_CREDENTIALS", keyFile)
+		config := server.PrometheusConfig{URL: prometheus.URL, AuthMode: server.AuthModeGCP}
+		client, err := NewClient(config, discardLogger())
+		if err != nil {
+			t.Fatalf("NewClient: %v", err)
+		}
+		if _, err := client.ExecuteQuery(context.Background(), "up", ""); err != nil {
+			t.Fatalf("ExecuteQuery: %v", err)
+		}
+	})
+
+	if n := issued.Load(); n != 2 {
+		t.Errorf("token endpoint called %d times, want once per credential source", n)
+	}
+}
+
+func TestNewClientGCPAuthErrors(t *testing.T) {
+	resetTokenSources(t)
+
+	for name, config := range map[string]server.PrometheusConfig{
+		"missing key file": {URL: "http://prometheus:9090", AuthMode: server.AuthModeGCP, GCPCredentialsFile: filepath.Join(t.TempDir(), "missing.json")},
+		"unknown mode":     {URL: "http://prometheus:9090", AuthMode: "kerberos"},
+	} {
+		if _, err := NewClient(config, discardLogger()); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
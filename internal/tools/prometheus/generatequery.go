@@ -0,0 +1,338 @@
+package prometheus
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	mcpserver "github.com/mark3labs/mcp-go/server"
+	"github.com/prometheus/common/model"
+
+	"github.com/giantswarm/mcp-prometheus/internal/promql"
+	"github.com/giantswarm/mcp-prometheus/internal/server"
+)
+
+// toolGenerateQuery is the registered name of the natural-language query
+// tool.
+const toolGenerateQuery = "generate_query"
+
+const (
+	// maxCatalogMetrics caps the metric names sent to the client's model;
+	// with more metrics on the server, those sharing the most words with the
+	// question are sent.
+	maxCatalogMetrics = 500
+
+	// maxCatalogHelp caps the catalog metrics listed with their type and
+	// help text.
+	maxCatalogHelp = 100
+
+	// maxCatalogLabels caps the label names sent to the client's model.
+	maxCatalogLabels = 200
+
+	// maxGenerateAttempts is the number of sampling requests made for one
+	// question: a reply that does not parse is sent back once with the
+	// syntax error.
+	maxGenerateAttempts = 2
+
+	// generateQueryMaxTokens bounds the length of the model's reply.
+	generateQueryMaxTokens = 512
+)
+
+// generateQuerySystemPrompt instructs the client's model how to answer.
+const generateQuerySystemPrompt = `You translate questions about Prometheus metrics into PromQL.
+Reply with a single PromQL expression and nothing else: no explanation, no Markdown.
+Only use metric and label names from the catalog in the request.
+Apply rate() or increase() to counters and histogram_quantile() to the _bucket series of histograms.`
+
+// sampler requests a completion from the MCP client's model. It is
+// satisfied by *mcpserver.MCPServer.
+type sampler interface {
+	RequestSampling(ctx context.Context, request mcp.CreateMessageRequest) (*mcp.CreateMessageResult, error)
+}
+
+// generateQueryHandler returns the handler of the generate_query tool, which
+// asks the client's model through smp.
+func generateQueryHandler(smp sampler) PrometheusHandler {
+	return func(ctx context.Context, request mcp.CallToolRequest, client *Client, sc *server.ServerContext) (*mcp.CallToolResult, error) {
+		return handleGenerateQuery(ctx, request, client, sc, smp)
+	}
+}
+
+// handleGenerateQuery handles the generate_query tool. The question is sent
+// to the client's model together with a catalog of the server's metrics and
+// label names; the reply is validated with the PromQL parser and, unless
+// execute is "false", run like query_templates: as a range query when start
+// or end is given and as an instant query otherwise.
+func handleGenerateQuery(ctx context.Context, request mcp.CallToolRequest, client *Client, sc *server.ServerContext, smp sampler) (*mcp.CallToolResult, error) {
+	params := extractParams(request)
+
+	question := strings.TrimSpace(getStringParam(params, "question"))
+	if question == "" {
+		return &mcp.CallToolResult{
+			IsError: true,
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: contentTypeText,
+					Text: "Error: question parameter is required",
+				},
+			},
+		}, nil
+	}
+
+	if err := checkSamplingSupport(ctx); err != nil {
+		return &mcp.CallToolResult{
+			IsError: true,
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: contentTypeText,
+					Text: fmt.Sprintf("Error: %v. Use query_builder or execute_query instead.", err),
+				},
+			},
+		}, nil
+	}
+
+	metrics, catalog, err := buildQueryCatalog(ctx, client, question)
+	if err != nil {
+		sc.Logger().Error("Failed to build metric catalog", "error", err)
+		return &mcp.CallToolResult{
+			IsError: true,
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: contentTypeText,
+					Text: fmt.Sprintf("Error building the metric catalog: %v", err),
+				},
+			},
+		}, nil
+	}
+
+	query, modelName, err := sampleQuery(ctx, smp, catalog, question)
+	if err != nil {
+		sc.Logger().Error("Failed to generate query", "question", question, "error", err)
+		return &mcp.CallToolResult{
+			IsError: true,
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: contentTypeText,
+					Text: fmt.Sprintf("Error generating query: %v", err),
+				},
+			},
+		}, nil
+	}
+
+	sc.Logger().Debug("Generated query", "question", question, "query", query, "model", modelName)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Generated query: %s\n", query)
+	if modelName != "" {
+		fmt.Fprintf(&b, "Model: %s\n", modelName)
+	}
+	if unknown := unknownMetrics(query, metrics); len(unknown) > 0 {
+		fmt.Fprintf(&b, "⚠️  Metrics not found on the server: %s\n", strings.Join(unknown, ", "))
+	}
+	header := b.String()
+
+	if getStringParam(params, "execute") == "false" {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: contentTypeText,
+					Text: header,
+				},
+			},
+		}, nil
+	}
+
+	args := maps.Clone(params)
+	delete(args, "question")
+	delete(args, "execute")
+	args["query"] = query
+	request.Params.Arguments = args
+
+	var result *mcp.CallToolResult
+	if getStringParam(params, "start") != "" || getStringParam(params, "end") != "" {
+		result, err = handleExecuteRangeQuery(ctx, request, client, sc)
+	} else {
+		result, err = handleExecuteQuery(ctx, request, client, sc)
+	}
+	if err != nil || result == nil {
+		return result, err
+	}
+
+	if len(result.Content) > 0 {
+		if tc, ok := result.Content[0].(mcp.TextContent); ok {
+			tc.Text = header + "\n" + tc.Text
+			result.Content[0] = tc
+		}
+	}
+	return result, nil
+}
+
+// checkSamplingSupport fails when the calling client did not declare the
+// sampling capability. Sessions that do not report capabilities are assumed
+// to support it; the sampling request then fails if they do not.
+func checkSamplingSupport(ctx context.Context) error {
+	session, ok := mcpserver.ClientSessionFromContext(ctx).(mcpserver.SessionWithClientInfo)
+	if ok && session.GetClientCapabilities().Sampling == nil {
+		return errors.New("the MCP client does not support sampling")
+	}
+	return nil
+}
+
+// buildQueryCatalog fetches the server's metric names, metadata and label
+// names and renders the catalog sent with the question. It also returns the
+// set of all metric names, to check the generated query against.
+func buildQueryCatalog(ctx context.Context, client *Client, question string) (map[string]bool, string, error) {
+	names, err := client.ListLabelValues(ctx, model.MetricNameLabel, LabelOptions{})
+	if err != nil {
+		return nil, "", err
+	}
+	labelNames, err := client.ListLabelNames(ctx, LabelOptions{})
+	if err != nil {
+		return nil, "", err
+	}
+	// Metadata only adds type and help text; the catalog works without it.
+	metadata, _ := client.ListMetricMetadata(ctx)
+
+	all := make(map[string]bool, len(names.LabelValues))
+	for _, name := range names.LabelValues {
+		all[name] = true
+	}
+	selected := relevantMetrics(names.LabelValues, question, maxCatalogMetrics)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Metrics (%d of %d):\n", len(selected), len(all))
+	for i, name := range selected {
+		if md, ok := metadata[name]; ok && len(md) > 0 && i < maxCatalogHelp {
+			fmt.Fprintf(&b, "%s (%s): %s\n", name, md[0].Type, md[0].Help)
+			continue
+		}
+		fmt.Fprintln(&b, name)
+	}
+	labels := labelNames.LabelNames
+	if len(labels) > maxCatalogLabels {
+		labels = labels[:maxCatalogLabels]
+	}
+	fmt.Fprintf(&b, "\nLabel names: %s\n", strings.Join(labels, ", "))
+	return all, b.String(), nil
+}
+
+// relevantMetrics returns at most n of names, preferring those containing
+// the most words of question (words of three or more letters, split on
+// anything that is not a letter or digit). Ties keep the server's order.
+func relevantMetrics(names []string, question string, n int) []string {
+	words := strings.FieldsFunc(strings.ToLower(question), func(r rune) bool {
+		return !('a' <= r && r <= 'z' || '0' <= r && r <= '9')
+	})
+	words = slices.DeleteFunc(words, func(w string) bool { return len(w) < 3 })
+
+	type scored struct {
+		name  string
+		score int
+	}
+	ranked := make([]scored, len(names))
+	for i, name := range names {
+		lower := strings.ToLower(name)
+		ranked[i].name = name
+		for _, w := range words {
+			if strings.Contains(lower, w) {
+				ranked[i].score++
+			}
+		}
+	}
+	slices.SortStableFunc(ranked, func(a, b scored) int { return cmp.Compare(b.score, a.score) })
+
+	out := make([]string, 0, min(n, len(ranked)))
+	for _, r := range ranked[:min(n, len(ranked))] {
+		out = append(out, r.name)
+	}
+	return out
+}
+
+// sampleQuery asks the client's model to translate question into PromQL and
+// returns the validated expression and the name of the model that answered.
+func sampleQuery(ctx context.Context, smp sampler, catalog, question string) (string, string, error) {
+	messages := []mcp.SamplingMessage{{
+		Role:    mcp.RoleUser,
+		Content: mcp.NewTextContent(catalog + "\nQuestion: " + question),
+	}}
+
+	var lastErr error
+	for range maxGenerateAttempts {
+		result, err := smp.RequestSampling(ctx, mcp.CreateMessageRequest{
+			CreateMessageParams: mcp.CreateMessageParams{
+				Messages:     messages,
+				SystemPrompt: generateQuerySystemPrompt,
+				MaxTokens:    generateQueryMaxTokens,
+			},
+		})
+		if err != nil {
+			return "", "", fmt.Errorf("sampling request failed: %w", err)
+		}
+
+		reply := samplingText(result.Content)
+		query := extractQuery(reply)
+		if query == "" {
+			return "", "", errors.New("the model returned no query")
+		}
+		expr, err := promql.Parse(query)
+		if err == nil {
+			return expr.String(), result.Model, nil
+		}
+		lastErr = fmt.Errorf("the model returned invalid PromQL %q: %w", query, err)
+		messages = append(messages,
+			mcp.SamplingMessage{Role: mcp.RoleAssistant, Content: mcp.NewTextContent(reply)},
+			mcp.SamplingMessage{Role: mcp.RoleUser, Content: mcp.NewTextContent(fmt.Sprintf("That is not valid PromQL: %v. Reply with the corrected expression only.", err))},
+		)
+	}
+	return "", "", lastErr
+}
+
+// samplingText returns the text of a sampling result's content.
+func samplingText(content any) string {
+	switch c := content.(type) {
+	case mcp.TextContent:
+		return c.Text
+	case *mcp.TextContent:
+		return c.Text
+	case map[string]any:
+		text, _ := c["text"].(string)
+		return text
+	}
+	return ""
+}
+
+// extractQuery strips the Markdown code fences or backticks models tend to
+// wrap expressions in despite being told not to.
+func extractQuery(reply string) string {
+	s := strings.TrimSpace(reply)
+	if rest, ok := strings.CutPrefix(s, "```"); ok {
+		// Drop the info string, e.g. ```promql.
+		if i := strings.IndexByte(rest, '\n'); i >= 0 {
+			rest = rest[i+1:]
+		}
+		s, _, _ = strings.Cut(rest, "```")
+	}
+	return strings.TrimSpace(strings.Trim(strings.TrimSpace(s), "`"))
+}
+
+// unknownMetrics returns the metric names query selects that are not in
+// known, sorted.
+func unknownMetrics(query string, known map[string]bool) []string {
+	expr, err := promql.Parse(query)
+	if err != nil {
+		return nil
+	}
+	var unknown []string
+	for _, vs := range promql.VectorSelectors(expr) {
+		if vs.Name != "" && !known[vs.Name] && !slices.Contains(unknown, vs.Name) {
+			unknown = append(unknown, vs.Name)
+		}
+	}
+	slices.Sort(unknown)
+	return unknown
+}
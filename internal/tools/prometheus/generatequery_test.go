@@ -0,0 +1,149 @@
+package prometheus
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	mcpserver "github.com/mark3labs/mcp-go/server"
+
+	"github.com/giantswarm/mcp-prometheus/internal/server"
+)
+
+// fakeSampler answers sampling requests with canned replies in order and
+// records the requests.
+type fakeSampler struct {
+	replies  []string
+	requests []mcp.CreateMessageRequest
+}
+
+func (f *fakeSampler) RequestSampling(_ context.Context, request mcp.CreateMessageRequest) (*mcp.CreateMessageResult, error) {
+	f.requests = append(f.requests, request)
+	reply := f.replies[0]
+	f.replies = f.replies[1:]
+	return &mcp.CreateMessageResult{
+		SamplingMessage: mcp.SamplingMessage{Role: mcp.RoleAssistant, Content: mcp.NewTextContent(reply)},
+		Model:           "test-model",
+	}, nil
+}
+
+// capabilitySession is a client session reporting fixed capabilities.
+type capabilitySession struct {
+	capabilities mcp.ClientCapabilities
+}
+
+func (s *capabilitySession) Initialize()                                         {}
+func (s *capabilitySession) Initialized() bool                                   { return true }
+func (s *capabilitySession) NotificationChannel() chan<- mcp.JSONRPCNotification { return nil }
+func (s *capabilitySession) SessionID() string                                   { return "test" }
+func (s *capabilitySession) GetClientInfo() mcp.Implementation                   { return mcp.Implementation{} }
+func (s *capabilitySession) SetClientInfo(mcp.Implementation)                    {}
+func (s *capabilitySession) GetClientCapabilities() mcp.ClientCapabilities       { return s.capabilities }
+func (s *capabilitySession) SetClientCapabilities(c mcp.ClientCapabilities)      { s.capabilities = c }
+
+func TestHandleGenerateQuery(t *testing.T) {
+	var gotQuery string
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		w.Header().Set("Content-Type", "application/json")
+		var data any
+		switch r.URL.Path {
+		case "/api/v1/label/__name__/values":
+			data = []string{"go_goroutines", "http_requests_total", "up"}
+		case "/api/v1/labels":
+			data = []string{"__name__", "code", "job"}
+		case "/api/v1/metadata":
+			data = map[string]any{"http_requests_total": []any{map[string]any{"type": "counter", "help": "Total HTTP requests.", "unit": ""}}}
+		case apiQueryPath:
+			gotQuery = r.Form.Get(paramKeyQuery)
+			data = map[string]any{respKeyResultType: respValVector, respKeyResult: []any{}}
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{respKeyStatus: respValSuccess, respKeyData: data})
+	}))
+	defer mockServer.Close()
+
+	ctx := context.Background()
+	sc, err := server.NewServerContext(ctx,
+		server.WithPrometheusConfig(server.PrometheusConfig{URL: mockServer.URL}),
+		server.WithSlogLogger(discardLogger()),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create server context: %v", err)
+	}
+	defer func() { _ = sc.Shutdown() }()
+
+	client, err := NewClient(sc.PrometheusConfig(), sc.Logger())
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+
+	call := func(ctx context.Context, smp sampler, args map[string]any) (*mcp.CallToolResult, string) {
+		t.Helper()
+		request := mcp.CallToolRequest{Params: mcp.CallToolParams{Name: toolGenerateQuery, Arguments: args}}
+		result, err := handleGenerateQuery(ctx, request, client, sc, smp)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return result, result.Content[0].(mcp.TextContent).Text
+	}
+
+	// The first reply does not parse and is sent back with the error.
+	smp := &fakeSampler{replies: []string{
+		"sum(rate(http_requests_total{code=~\"5..\"}[5m])",
+		"```promql\nsum by (job) (rate(http_requests_total{code=~\"5..\"}[5m]))\n```",
+	}}
+	result, text := call(ctx, smp, map[string]any{"question": "How many HTTP errors per job?"})
+	if result.IsError {
+		t.Fatalf("expected success, got error: %s", text)
+	}
+	const want = `sum by (job) (rate(http_requests_total{code=~"5.."}[5m]))`
+	if gotQuery != want || !strings.HasPrefix(text, "Generated query: "+want+"\nModel: test-model\n\n") {
+		t.Errorf("Prometheus received %q, output:\n%s", gotQuery, text)
+	}
+	if len(smp.requests) != 2 || len(smp.requests[1].Messages) != 3 {
+		t.Fatalf("expected a retry with the syntax error, got %d requests", len(smp.requests))
+	}
+	prompt := samplingText(smp.requests[0].Messages[0].Content)
+	for _, s := range []string{"http_requests_total (counter): Total HTTP requests.", "Label names: __name__, code, job", "Question: How many HTTP errors per job?"} {
+		if !strings.Contains(prompt, s) {
+			t.Errorf("prompt lacks %q:\n%s", s, prompt)
+		}
+	}
+
+	// Metrics the server does not have are flagged; execute=false skips
+	// the query.
+	gotQuery = ""
+	result, text = call(ctx, &fakeSampler{replies: []string{"rate(http_errors_total[5m])"}}, map[string]any{"question": "errors?", "execute": "false"})
+	if result.IsError || !strings.Contains(text, "Metrics not found on the server: http_errors_total") || gotQuery != "" {
+		t.Errorf("unexpected output (query %q):\n%s", gotQuery, text)
+	}
+
+	// Two invalid replies fail.
+	result, text = call(ctx, &fakeSampler{replies: []string{"sum(", "rate("}}, map[string]any{"question": "anything"})
+	if !result.IsError || !strings.Contains(text, "invalid PromQL") {
+		t.Errorf("expected an invalid PromQL error, got %q", text)
+	}
+
+	// Clients without sampling are told so before the catalog is fetched.
+	noSampling := mcpserver.NewMCPServer("test", "1.0").WithContext(ctx, &capabilitySession{})
+	result, text = call(noSampling, &fakeSampler{}, map[string]any{"question": "anything"})
+	if !result.IsError || !strings.Contains(text, "does not support sampling") {
+		t.Errorf("expected a sampling support error, got %q", text)
+	}
+}
+
+func TestRelevantMetrics(t *testing.T) {
+	names := []string{"go_goroutines", "http_requests_total", "node_cpu_seconds_total", "http_request_duration_seconds_bucket"}
+	got := relevantMetrics(names, "p99 HTTP request duration", 2)
+	if want := []string{"http_request_duration_seconds_bucket", "http_requests_total"}; !slices.Equal(got, want) {
+		t.Errorf("relevantMetrics() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,64 @@
+package prometheus
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/giantswarm/mcp-prometheus/internal/server"
+)
+
+// toolCheckHealth is the registered name of the health and readiness probe.
+const toolCheckHealth = "check_health"
+
+// handleCheckHealth handles the check_health tool. It probes /-/healthy and
+// the readiness endpoint of check_ready, lists status and latency of each and
+// ends with a verdict telling an unreachable or starting server apart from a
+// working one. The result is an error unless the server is ready.
+func handleCheckHealth(ctx context.Context, request mcp.CallToolRequest, client *Client, sc *server.ServerContext) (*mcp.CallToolResult, error) {
+	sc.Logger().Debug("Checking Prometheus health")
+
+	healthy, healthyErr := client.CheckHealthy(ctx)
+	ready, readyErr := client.CheckReady(ctx)
+
+	var b strings.Builder
+	b.WriteString("check | status | latency_ms | response\n")
+	for _, probe := range []struct {
+		name   string
+		status *HealthStatus
+		err    error
+	}{{"healthy", healthy, healthyErr}, {"ready", ready, readyErr}} {
+		if probe.err != nil {
+			fmt.Fprintf(&b, "%s | unreachable | - | %v\n", probe.name, probe.err)
+			continue
+		}
+		fmt.Fprintf(&b, "%s | %d | %d | %s\n", probe.name, probe.status.StatusCode, probe.status.Latency.Milliseconds(), valueOrNone(probe.status.Message))
+	}
+	b.WriteString("\n")
+
+	isError := true
+	switch {
+	case healthyErr != nil && readyErr != nil:
+		b.WriteString("Prometheus is unreachable. Queries fail until it is back; the query itself is not the problem.")
+	case healthyErr == nil && !healthy.Ready && healthy.StatusCode != http.StatusNotFound:
+		fmt.Fprintf(&b, "Prometheus is not healthy (HTTP %d). Queries are likely to fail regardless of the query.", healthy.StatusCode)
+	case readyErr != nil || !ready.Ready:
+		b.WriteString("Prometheus is up but not ready to serve queries, e.g. while it replays its write-ahead log after a restart. Retry shortly.")
+	default:
+		isError = false
+		b.WriteString("Prometheus is healthy and ready. A failing query is most likely a problem with the query itself.")
+	}
+
+	return &mcp.CallToolResult{
+		IsError: isError,
+		Content: []mcp.Content{
+			mcp.TextContent{
+				Type: contentTypeText,
+				Text: b.String(),
+			},
+		},
+	}, nil
+}
@@ -0,0 +1,109 @@
+package prometheus
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/giantswarm/mcp-prometheus/internal/server"
+)
+
+func TestHandleCheckHealth(t *testing.T) {
+	tests := []struct {
+		name        string
+		statuses    map[string]int
+		wantIsError bool
+		wantText    []string
+	}{
+		{
+			name:     "healthy and ready",
+			statuses: map[string]int{"/-/healthy": http.StatusOK, "/-/ready": http.StatusOK},
+			wantText: []string{"healthy | 200 | ", "ready | 200 | ", "healthy and ready"},
+		},
+		{
+			name:        "replaying the WAL",
+			statuses:    map[string]int{"/-/healthy": http.StatusOK, "/-/ready": http.StatusServiceUnavailable},
+			wantIsError: true,
+			wantText:    []string{"ready | 503 | ", "up but not ready"},
+		},
+		{
+			name:     "Mimir gateway",
+			statuses: map[string]int{"/ready": http.StatusOK},
+			wantText: []string{"healthy | 404 | ", "ready | 200 | ", "healthy and ready"},
+		},
+		{
+			name:        "unhealthy",
+			statuses:    map[string]int{"/-/healthy": http.StatusInternalServerError, "/-/ready": http.StatusOK},
+			wantIsError: true,
+			wantText:    []string{"not healthy (HTTP 500)"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				status, ok := tt.statuses[r.URL.Path]
+				if !ok {
+					status = http.StatusNotFound
+				}
+				w.WriteHeader(status)
+				_, _ = w.Write([]byte(http.StatusText(status)))
+			}))
+			defer srv.Close()
+
+			ctx := context.Background()
+			sc, err := server.NewServerContext(ctx,
+				server.WithPrometheusConfig(server.PrometheusConfig{URL: srv.URL}),
+				server.WithSlogLogger(discardLogger()),
+			)
+			if err != nil {
+				t.Fatalf("failed to create server context: %v", err)
+			}
+			defer func() { _ = sc.Shutdown() }()
+
+			client, err := NewClient(sc.PrometheusConfig(), sc.Logger())
+			if err != nil {
+				t.Fatalf("NewClient: %v", err)
+			}
+			result, err := handleCheckHealth(ctx, mcp.CallToolRequest{}, client, sc)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.IsError != tt.wantIsError {
+				t.Errorf("IsError=%v, want %v", result.IsError, tt.wantIsError)
+			}
+			text := result.Content[0].(mcp.TextContent).Text
+			for _, want := range tt.wantText {
+				if !strings.Contains(text, want) {
+					t.Errorf("result text %q does not contain %q", text, want)
+				}
+			}
+		})
+	}
+}
+
+func TestHandleCheckHealthUnreachable(t *testing.T) {
+	ctx := context.Background()
+	client, err := NewClient(server.PrometheusConfig{URL: "http://127.0.0.1:1"}, discardLogger())
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	sc, err := server.NewServerContext(ctx, server.WithSlogLogger(discardLogger()))
+	if err != nil {
+		t.Fatalf("failed to create server context: %v", err)
+	}
+	defer func() { _ = sc.Shutdown() }()
+
+	result, err := handleCheckHealth(ctx, mcp.CallToolRequest{}, client, sc)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	text := result.Content[0].(mcp.TextContent).Text
+	if !result.IsError || !strings.Contains(text, "healthy | unreachable | - |") || !strings.Contains(text, "Prometheus is unreachable") {
+		t.Errorf("unexpected result: %s", text)
+	}
+}
@@ -0,0 +1,123 @@
+package prometheus
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	v1 "github.com/prometheus/client_golang/api/prometheus/v1"
+
+	"github.com/giantswarm/mcp-prometheus/internal/server"
+)
+
+// helpTextMatch is a metric whose help string contains at least one of the
+// searched keywords.
+type helpTextMatch struct {
+	Name  string
+	Type  string
+	Help  string
+	Score int
+}
+
+// scoreHelpText returns how many of the lowercased keywords appear in help.
+func scoreHelpText(help string, keywords []string) int {
+	help = strings.ToLower(help)
+	score := 0
+	for _, keyword := range keywords {
+		if strings.Contains(help, keyword) {
+			score++
+		}
+	}
+	return score
+}
+
+// searchHelpText scores every metric's help string against keywords and
+// returns the matches ranked by score, then by name. With requireAll only
+// metrics matching every keyword are kept. A non-empty metricType restricts
+// the search to metrics of that type.
+func searchHelpText(metadata map[string][]v1.Metadata, keywords []string, requireAll bool, metricType string) []helpTextMatch {
+	var matches []helpTextMatch
+	for name, entries := range metadata {
+		for _, md := range entries {
+			if metricType != "" && !strings.EqualFold(string(md.Type), metricType) {
+				continue
+			}
+			score := scoreHelpText(md.Help, keywords)
+			if score == 0 || (requireAll && score < len(keywords)) {
+				continue
+			}
+			matches = append(matches, helpTextMatch{Name: name, Type: string(md.Type), Help: md.Help, Score: score})
+			break
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].Score != matches[j].Score {
+			return matches[i].Score > matches[j].Score
+		}
+		return matches[i].Name < matches[j].Name
+	})
+	return matches
+}
+
+// handleFindMetricsByHelpText handles the find_metrics_by_help_text tool
+func handleFindMetricsByHelpText(ctx context.Context, request mcp.CallToolRequest, client *Client, sc *server.ServerContext) (*mcp.CallToolResult, error) {
+	params := extractParams(request)
+
+	keywords := strings.Fields(strings.ToLower(getStringParam(params, "keywords")))
+	if len(keywords) == 0 {
+		return &mcp.CallToolResult{
+			IsError: true,
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: contentTypeText,
+					Text: "Error: keywords parameter is required and must contain at least one word",
+				},
+			},
+		}, nil
+	}
+	requireAll := getStringParam(params, "require_all") == "true"
+	metricType := getStringParam(params, "metric_type")
+
+	sc.Logger().Debug("Searching metric help text", "keywords", keywords, "require_all", requireAll, "metric_type", metricType)
+
+	metadata, err := client.ListMetricMetadata(ctx)
+	if err != nil {
+		sc.Logger().Error("Failed to get metric metadata", "error", err)
+		return &mcp.CallToolResult{
+			IsError: true,
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: contentTypeText,
+					Text: fmt.Sprintf("Error getting metric metadata: %v", err),
+				},
+			},
+		}, nil
+	}
+
+	matches := searchHelpText(metadata, keywords, requireAll, metricType)
+
+	mode := "any"
+	if requireAll {
+		mode = "all"
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "Metrics whose help text matches %s of: %s", mode, strings.Join(keywords, " "))
+	if metricType != "" {
+		fmt.Fprintf(&b, " (type %s)", metricType)
+	}
+	fmt.Fprintf(&b, "\nFound %d of %d metrics\n", len(matches), len(metadata))
+	for _, m := range matches {
+		fmt.Fprintf(&b, "\n%s (%s) — %s", m.Name, m.Type, m.Help)
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.TextContent{
+				Type: contentTypeText,
+				Text: b.String(),
+			},
+		},
+	}, nil
+}
@@ -0,0 +1,133 @@
+package prometheus
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/giantswarm/mcp-prometheus/internal/server"
+)
+
+// helpSearchMetadata is the fixture returned by the mock metadata endpoint.
+var helpSearchMetadata = map[string][]map[string]string{
+	"node_disk_written_bytes_total": {{"type": "counter", "help": "The total number of bytes written to disk successfully."}},
+	"node_disk_read_bytes_total":    {{"type": "counter", "help": "The total number of bytes read from disk successfully."}},
+	"node_memory_MemFree_bytes":     {{"type": "gauge", "help": "Memory information field MemFree_bytes."}},
+	"http_request_duration_seconds": {{"type": "histogram", "help": "Duration of HTTP requests in seconds."}},
+	"process_open_fds":              {{"type": "gauge", "help": "Number of open file descriptors."}},
+}
+
+func newHelpSearchMockServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/metadata" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{respKeyStatus: respValSuccess, respKeyData: helpSearchMetadata})
+	}))
+}
+
+func runFindMetricsByHelpText(t *testing.T, args map[string]any) *mcp.CallToolResult {
+	t.Helper()
+	mockServer := newHelpSearchMockServer(t)
+	t.Cleanup(mockServer.Close)
+
+	ctx := context.Background()
+	sc, err := server.NewServerContext(ctx,
+		server.WithPrometheusConfig(server.PrometheusConfig{URL: mockServer.URL}),
+		server.WithSlogLogger(discardLogger()),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create server context: %v", err)
+	}
+	t.Cleanup(func() { _ = sc.Shutdown() })
+
+	client, err := NewClient(sc.PrometheusConfig(), sc.Logger())
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+
+	request := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{Name: "find_metrics_by_help_text", Arguments: args},
+	}
+	result, err := handleFindMetricsByHelpText(ctx, request, client, sc)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return result
+}
+
+func TestHandleFindMetricsByHelpText(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    map[string]any
+		want    []string // expected metric names in rank order
+		notWant []string
+	}{
+		{
+			name:    "any keyword ranks most matches first",
+			args:    map[string]any{"keywords": "Bytes written disk"},
+			want:    []string{"node_disk_written_bytes_total", "node_disk_read_bytes_total", "node_memory_MemFree_bytes"},
+			notWant: []string{"http_request_duration_seconds", "process_open_fds"},
+		},
+		{
+			name:    "require_all",
+			args:    map[string]any{"keywords": "bytes disk", "require_all": "true"},
+			want:    []string{"node_disk_read_bytes_total", "node_disk_written_bytes_total"},
+			notWant: []string{"node_memory_MemFree_bytes"},
+		},
+		{
+			name:    "metric_type filter",
+			args:    map[string]any{"keywords": "bytes", "metric_type": "gauge"},
+			want:    []string{"node_memory_MemFree_bytes"},
+			notWant: []string{"node_disk_written_bytes_total", "node_disk_read_bytes_total"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := runFindMetricsByHelpText(t, tt.args)
+			if result.IsError {
+				t.Fatalf("expected success, got error: %v", result.Content)
+			}
+			text := result.Content[0].(mcp.TextContent).Text
+
+			last := -1
+			for _, name := range tt.want {
+				idx := strings.Index(text, "\n"+name+" (")
+				if idx < 0 {
+					t.Fatalf("expected %s in output:\n%s", name, text)
+				}
+				if idx < last {
+					t.Errorf("expected %s to rank after the previous match:\n%s", name, text)
+				}
+				last = idx
+			}
+			for _, name := range tt.notWant {
+				if strings.Contains(text, name) {
+					t.Errorf("did not expect %s in output:\n%s", name, text)
+				}
+			}
+		})
+	}
+
+	result := runFindMetricsByHelpText(t, map[string]any{"keywords": "written"})
+	want := "node_disk_written_bytes_total (counter) — The total number of bytes written to disk successfully."
+	if text := result.Content[0].(mcp.TextContent).Text; !strings.Contains(text, want) {
+		t.Errorf("expected output to contain %q, got:\n%s", want, text)
+	}
+}
+
+func TestHandleFindMetricsByHelpTextRequiresKeywords(t *testing.T) {
+	result := runFindMetricsByHelpText(t, map[string]any{"keywords": "   "})
+	if !result.IsError {
+		t.Fatal("expected an error for empty keywords")
+	}
+}
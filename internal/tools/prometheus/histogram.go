@@ -0,0 +1,237 @@
+package prometheus
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/prometheus/common/model"
+
+	"github.com/giantswarm/mcp-prometheus/internal/promql"
+	"github.com/giantswarm/mcp-prometheus/internal/server"
+)
+
+// histogramGroup collects the classic histogram series sharing every label
+// but le: one _bucket series per bucket plus the matching _count and _sum.
+type histogramGroup struct {
+	labels   model.LabelSet
+	buckets  map[string]*float64 // le label → value at evaluation time (nil when unknown)
+	count    *float64
+	hasCount bool
+	hasSum   bool
+}
+
+// histogramGroupKey returns the labels identifying the histogram a series
+// belongs to: all labels except __name__ and le.
+func histogramGroupKey(series map[string]string) model.LabelSet {
+	key := make(model.LabelSet, len(series))
+	for name, value := range series {
+		if name == model.MetricNameLabel || name == model.BucketLabel {
+			continue
+		}
+		key[model.LabelName(name)] = model.LabelValue(value)
+	}
+	return key
+}
+
+// problems returns the validation failures of g, in a stable order.
+func (g *histogramGroup) problems() []string {
+	var problems []string
+
+	type bucket struct {
+		raw   string
+		le    float64
+		value *float64
+	}
+	buckets := make([]bucket, 0, len(g.buckets))
+	for raw, value := range g.buckets {
+		le, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("le=%q is not a number", raw))
+			continue
+		}
+		buckets = append(buckets, bucket{raw: raw, le: le, value: value})
+	}
+	sort.Slice(buckets, func(i, j int) bool { return buckets[i].le < buckets[j].le })
+
+	if len(buckets) == 0 || !math.IsInf(buckets[len(buckets)-1].le, 1) {
+		problems = append(problems, "missing +Inf bucket")
+	}
+	for i := 1; i < len(buckets); i++ {
+		if buckets[i].le == buckets[i-1].le {
+			problems = append(problems, fmt.Sprintf("le values are not strictly increasing: le=%q and le=%q are the same bound", buckets[i-1].raw, buckets[i].raw))
+		}
+	}
+
+	var prev *bucket
+	for i, b := range buckets {
+		if b.value == nil {
+			continue
+		}
+		if *b.value < 0 {
+			problems = append(problems, fmt.Sprintf("bucket le=%q has negative value %g", b.raw, *b.value))
+		}
+		if prev != nil && *b.value < *prev.value {
+			problems = append(problems, fmt.Sprintf("bucket le=%q (%g) is lower than bucket le=%q (%g); buckets must be cumulative", b.raw, *b.value, prev.raw, *prev.value))
+		}
+		prev = &buckets[i]
+	}
+
+	if !g.hasCount {
+		problems = append(problems, "missing _count series")
+	}
+	if !g.hasSum {
+		problems = append(problems, "missing _sum series")
+	}
+	if g.count != nil && len(buckets) > 0 {
+		if inf := buckets[len(buckets)-1]; math.IsInf(inf.le, 1) && inf.value != nil && *inf.value != *g.count {
+			problems = append(problems, fmt.Sprintf("_count (%g) does not equal the +Inf bucket (%g)", *g.count, *inf.value))
+		}
+	}
+	return problems
+}
+
+// handleValidateHistogram handles the validate_histogram tool
+func handleValidateHistogram(ctx context.Context, request mcp.CallToolRequest, client *Client, sc *server.ServerContext) (*mcp.CallToolResult, error) {
+	params := extractParams(request)
+
+	metric := strings.TrimSuffix(getStringParam(params, "metric_name"), "_bucket")
+	if metric == "" {
+		return &mcp.CallToolResult{
+			IsError: true,
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: contentTypeText,
+					Text: "Error: metric_name parameter is required and must be a string",
+				},
+			},
+		}, nil
+	}
+	// Counter and summary suffixes cannot name a histogram; answering early
+	// saves a series lookup that would find nothing.
+	if t := promql.InferMetricType(metric); t == string(model.MetricTypeCounter) || t == string(model.MetricTypeSummary) {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: contentTypeText,
+					Text: fmt.Sprintf("'%s' looks like a %s by its name, not a histogram. Pass the histogram base name without the _bucket, _count or _sum suffix (e.g. 'http_request_duration_seconds').", metric, t),
+				},
+			},
+		}, nil
+	}
+	selector := func(suffix string) string {
+		return fmt.Sprintf("{%s=%s}", model.MetricNameLabel, strconv.Quote(metric+suffix))
+	}
+
+	for _, suffix := range []string{"_bucket", "_count"} {
+		if refused := checkQueryPolicy(sc, selector(suffix), time.Time{}, time.Time{}, 0); refused != nil {
+			return refused, nil
+		}
+	}
+
+	sc.Logger().Debug("Validating histogram", "metric", metric)
+
+	groups := make(map[model.Fingerprint]*histogramGroup)
+	series, err := client.FindSeries(ctx, []string{selector("_bucket"), selector("_count"), selector("_sum")}, SeriesOptions{})
+	if err != nil {
+		sc.Logger().Error("Failed to find histogram series", "error", err)
+		return &mcp.CallToolResult{
+			IsError: true,
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: contentTypeText,
+					Text: fmt.Sprintf("Error finding series for histogram '%s': %v", metric, err),
+				},
+			},
+		}, nil
+	}
+	for _, s := range series.Series {
+		key := histogramGroupKey(s)
+		g, ok := groups[key.Fingerprint()]
+		if !ok {
+			g = &histogramGroup{labels: key, buckets: map[string]*float64{}}
+			groups[key.Fingerprint()] = g
+		}
+		switch s[model.MetricNameLabel] {
+		case metric + "_bucket":
+			g.buckets[s[model.BucketLabel]] = nil
+		case metric + "_count":
+			g.hasCount = true
+		case metric + "_sum":
+			g.hasSum = true
+		}
+	}
+
+	if len(groups) == 0 {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: contentTypeText,
+					Text: fmt.Sprintf("No series found for histogram '%s' (looked for %s_bucket, %s_count and %s_sum).", metric, metric, metric, metric),
+				},
+			},
+		}, nil
+	}
+
+	// Bucket and _count values at one evaluation time, for the value checks.
+	// Series that are stale now simply skip them.
+	evalTime := strconv.FormatInt(time.Now().Unix(), 10)
+	for _, suffix := range []string{"_bucket", "_count"} {
+		result, err := client.ExecuteQuery(ctx, selector(suffix), evalTime)
+		if err != nil {
+			sc.Logger().Warn("Failed to query histogram values", "metric", metric+suffix, "error", err)
+			continue
+		}
+		vector, _ := result.Result.(model.Vector)
+		for _, sample := range vector {
+			key := make(map[string]string, len(sample.Metric))
+			for name, value := range sample.Metric {
+				key[string(name)] = string(value)
+			}
+			g, ok := groups[histogramGroupKey(key).Fingerprint()]
+			if !ok {
+				continue
+			}
+			value := float64(sample.Value)
+			if suffix == "_count" {
+				g.count = &value
+			} else if _, known := g.buckets[key[model.BucketLabel]]; known {
+				g.buckets[key[model.BucketLabel]] = &value
+			}
+		}
+	}
+
+	ordered := slices.Collect(maps.Values(groups))
+	sort.Slice(ordered, func(i, j int) bool { return ordered[i].labels.String() < ordered[j].labels.String() })
+
+	var b strings.Builder
+	failed := 0
+	for _, g := range ordered {
+		problems := g.problems()
+		if len(problems) == 0 {
+			fmt.Fprintf(&b, "\n%s: OK (%d buckets)\n", g.labels, len(g.buckets))
+			continue
+		}
+		failed++
+		fmt.Fprintf(&b, "\n%s: FAIL (%d buckets)\n", g.labels, len(g.buckets))
+		for _, p := range problems {
+			fmt.Fprintf(&b, "  - %s\n", p)
+		}
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.TextContent{
+				Type: contentTypeText,
+				Text: fmt.Sprintf("Histogram validation for '%s': %d of %d series groups have problems\n", metric, failed, len(groups)) + b.String(),
+			},
+		},
+	}, nil
+}
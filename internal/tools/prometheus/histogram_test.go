@@ -0,0 +1,157 @@
+package prometheus
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/giantswarm/mcp-prometheus/internal/server"
+)
+
+// histogramSample is one series of the validate_histogram fixture with its
+// current value.
+type histogramSample struct {
+	labels map[string]string
+	value  string
+}
+
+// histogramFixture holds a healthy histogram (job="api") and a broken one
+// (job="batch": non-cumulative buckets, no +Inf bucket, no _sum).
+var histogramFixture = []histogramSample{
+	{map[string]string{"__name__": "req_seconds_bucket", "job": "api", "le": "0.1"}, "5"},
+	{map[string]string{"__name__": "req_seconds_bucket", "job": "api", "le": "1"}, "8"},
+	{map[string]string{"__name__": "req_seconds_bucket", "job": "api", "le": "+Inf"}, "10"},
+	{map[string]string{"__name__": "req_seconds_count", "job": "api"}, "10"},
+	{map[string]string{"__name__": "req_seconds_sum", "job": "api"}, "3.5"},
+	{map[string]string{"__name__": "req_seconds_bucket", "job": "batch", "le": "0.1"}, "5"},
+	{map[string]string{"__name__": "req_seconds_bucket", "job": "batch", "le": "1.0"}, "3"},
+	{map[string]string{"__name__": "req_seconds_count", "job": "batch"}, "9"},
+}
+
+func newHistogramMockServer(t *testing.T, fixture []histogramSample) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		var data any
+		switch r.URL.Path {
+		case "/api/v1/series":
+			series := []map[string]string{}
+			for _, s := range fixture {
+				series = append(series, s.labels)
+			}
+			data = series
+		case "/api/v1/query":
+			name := strings.TrimSuffix(strings.TrimPrefix(r.Form.Get("query"), `{__name__="`), `"}`)
+			result := []any{}
+			for _, s := range fixture {
+				if s.labels["__name__"] == name {
+					result = append(result, map[string]any{"metric": s.labels, "value": []any{1704067200, s.value}})
+				}
+			}
+			data = map[string]any{"resultType": "vector", "result": result}
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{respKeyStatus: respValSuccess, respKeyData: data})
+	}))
+}
+
+func runValidateHistogram(t *testing.T, fixture []histogramSample, metric string) string {
+	t.Helper()
+	mockServer := newHistogramMockServer(t, fixture)
+	t.Cleanup(mockServer.Close)
+
+	ctx := context.Background()
+	sc, err := server.NewServerContext(ctx,
+		server.WithPrometheusConfig(server.PrometheusConfig{URL: mockServer.URL}),
+		server.WithSlogLogger(discardLogger()),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create server context: %v", err)
+	}
+	t.Cleanup(func() { _ = sc.Shutdown() })
+
+	client, err := NewClient(sc.PrometheusConfig(), sc.Logger())
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+
+	request := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{Name: "validate_histogram", Arguments: map[string]any{"metric_name": metric}},
+	}
+	result, err := handleValidateHistogram(ctx, request, client, sc)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.IsError {
+		t.Fatalf("expected success, got error: %v", result.Content)
+	}
+	return result.Content[0].(mcp.TextContent).Text
+}
+
+func TestHandleValidateHistogram(t *testing.T) {
+	text := runValidateHistogram(t, histogramFixture, "req_seconds_bucket")
+
+	for _, want := range []string{
+		"Histogram validation for 'req_seconds': 1 of 2 series groups have problems",
+		`{job="api"}: OK (3 buckets)`,
+		`{job="batch"}: FAIL (2 buckets)`,
+		"  - missing +Inf bucket\n",
+		`  - bucket le="1.0" (3) is lower than bucket le="0.1" (5); buckets must be cumulative`,
+		"  - missing _sum series\n",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("expected output to contain %q, got:\n%s", want, text)
+		}
+	}
+	if strings.Contains(text, "missing _count") {
+		t.Errorf("did not expect a missing _count problem, got:\n%s", text)
+	}
+}
+
+func TestHistogramGroupProblems(t *testing.T) {
+	f := func(v float64) *float64 { return &v }
+	g := &histogramGroup{
+		buckets:  map[string]*float64{"1": f(-1), "1e0": f(2), "+Inf": f(4), "fast": nil},
+		count:    f(5),
+		hasCount: true,
+		hasSum:   true,
+	}
+	got := strings.Join(g.problems(), "\n")
+	for _, want := range []string{
+		`le="fast" is not a number`,
+		"le values are not strictly increasing",
+		`bucket le="1" has negative value -1`,
+		"_count (5) does not equal the +Inf bucket (4)",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected problems to contain %q, got:\n%s", want, got)
+		}
+	}
+}
+
+func TestHandleValidateHistogramNoSeries(t *testing.T) {
+	text := runValidateHistogram(t, nil, "missing_seconds")
+	if !strings.Contains(text, "No series found for histogram 'missing_seconds'") {
+		t.Errorf("unexpected output: %s", text)
+	}
+}
+
+func TestHandleValidateHistogramNotAHistogram(t *testing.T) {
+	for _, name := range []string{"http_requests_total", "http_request_duration_seconds_count", "rpc_latency_seconds_sum"} {
+		text := runValidateHistogram(t, nil, name)
+		if !strings.Contains(text, "'"+name+"' looks like a") || !strings.Contains(text, "not a histogram") {
+			t.Errorf("%s: unexpected output: %s", name, text)
+		}
+	}
+}
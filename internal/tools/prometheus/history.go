@@ -0,0 +1,263 @@
+package prometheus
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/giantswarm/mcp-oauth/handler"
+	"github.com/mark3labs/mcp-go/mcp"
+	mcpserver "github.com/mark3labs/mcp-go/server"
+
+	"github.com/giantswarm/mcp-prometheus/internal/server"
+)
+
+const (
+	// toolGetInvocationHistory is the registered name of the audit history tool.
+	toolGetInvocationHistory = "get_invocation_history"
+
+	// defaultHistoryLimit is the number of records returned when no limit is given.
+	defaultHistoryLimit = 50
+
+	// maxHistoryErrorLength caps the error message shown per record.
+	maxHistoryErrorLength = 120
+
+	// hiddenValue replaces the Prometheus URLs of invocation records under
+	// server.ErrorVerbositySafe.
+	hiddenValue = "(hidden)"
+)
+
+// invocationRecordedKey carries, in the context of calls wrapped by
+// auditToolCalls, whether the tool recorded its invocation itself.
+type invocationRecordedKey struct{}
+
+// auditToolCalls returns a server-wide tool handler middleware recording the
+// tools that do not record their own invocations (those not registered with
+// registerPrometheusTools, including plugin tools). get_invocation_history is
+// never recorded.
+func auditToolCalls(sc *server.ServerContext) mcpserver.ToolHandlerMiddleware {
+	return func(next mcpserver.ToolHandlerFunc) mcpserver.ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			if request.Params.Name == toolGetInvocationHistory {
+				return next(ctx, request)
+			}
+			recorded := new(bool)
+			start := time.Now()
+			result, err := next(context.WithValue(ctx, invocationRecordedKey{}, recorded), request)
+			if !*recorded {
+				recordInvocation(ctx, sc, request, "", "", start, result, err)
+			}
+			return result, err
+		}
+	}
+}
+
+// recordInvocation appends a completed tool call to the server's audit log.
+// URLs are stored with user info redacted and arguments with secrets
+// redacted; the error message is taken from err or, for IsError results,
+// from the first text content, so callers pass the result after
+// redactToolError. The caller is the OAuth user's email (or ID)
+// and the session that of the MCP client, when known; the record belongs to
+// the caller's scope.
+func recordInvocation(ctx context.Context, sc *server.ServerContext, request mcp.CallToolRequest, prometheusURL, orgID string, start time.Time, result *mcp.CallToolResult, err error) {
+	if !sc.RecordsInvocations() {
+		return
+	}
+	if recorded, ok := ctx.Value(invocationRecordedKey{}).(*bool); ok {
+		*recorded = true
+	}
+
+	rec := server.ToolInvocationRecord{
+		ToolName:      request.Params.Name,
+		Arguments:     redactArguments(extractParams(request)),
+		PrometheusURL: redactURL(prometheusURL),
+		OrgID:         orgID,
+		StartTime:     start,
+		Duration:      time.Since(start),
+		Success:       err == nil && (result == nil || !result.IsError),
+	}
+	if userInfo, ok := handler.UserInfoFromContext(ctx); ok {
+		rec.Caller = cmp.Or(userInfo.Email, userInfo.ID)
+	}
+	if session := mcpserver.ClientSessionFromContext(ctx); session != nil {
+		rec.SessionID = session.SessionID()
+	}
+	rec.Scope = callerScope(ctx)
+	if result != nil {
+		for _, c := range result.Content {
+			if tc, ok := c.(mcp.TextContent); ok {
+				rec.ResultSize += len(tc.Text)
+			}
+		}
+	}
+	switch {
+	case err != nil:
+		rec.ErrorMessage = err.Error()
+	case result != nil && result.IsError:
+		for _, c := range result.Content {
+			if tc, ok := c.(mcp.TextContent); ok {
+				rec.ErrorMessage = tc.Text
+				break
+			}
+		}
+	}
+	sc.RecordInvocation(rec)
+}
+
+// redactArguments returns a copy of tool arguments fit for the audit log:
+// values of credential-like keys (see isSensitiveKey) are replaced with
+// redactedValue, also in nested objects, and user info is removed from the
+// URLs of keys ending in url or urls.
+func redactArguments(args map[string]any) map[string]any {
+	if len(args) == 0 {
+		return nil
+	}
+	out := redactYAML(args).(map[string]any)
+	for k, v := range out {
+		if !strings.HasSuffix(k, "url") && !strings.HasSuffix(k, "urls") {
+			continue
+		}
+		switch v := v.(type) {
+		case string:
+			out[k] = redactURL(v)
+		case []any:
+			urls := make([]any, len(v))
+			for i, u := range v {
+				if s, ok := u.(string); ok {
+					u = redactURL(s)
+				}
+				urls[i] = u
+			}
+			out[k] = urls
+		}
+	}
+	return out
+}
+
+// registerInvocationHistoryTool registers get_invocation_history. Like
+// get_server_config it only reads server state, so it bypasses the dynamic
+// client wrapper and is not itself recorded.
+func registerInvocationHistoryTool(s *mcpserver.MCPServer, sc *server.ServerContext, middleware []ToolMiddleware) {
+	tool := mcp.NewTool(toolGetInvocationHistory,
+		mcp.WithDescription("List your most recent tool invocations on this server (tool, Prometheus URL, org ID, start time, duration, outcome). Requires the server to run with --audit-log-entries"),
+		mcp.WithString("limit", mcp.Description(fmt.Sprintf("Maximum number of records to return, newest first (default: %d)", defaultHistoryLimit))),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithDestructiveHintAnnotation(false),
+		mcp.WithOpenWorldHintAnnotation(false),
+		mcp.WithSchemaAdditionalProperties(false),
+	)
+
+	h := withServerMiddleware(sc, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return handleGetInvocationHistory(ctx, request, sc)
+	})
+	for _, mw := range middleware {
+		h = mw(toolGetInvocationHistory, h)
+	}
+	s.AddTool(tool, h)
+}
+
+// handleGetInvocationHistory handles the get_invocation_history tool. It
+// lists the calls of the caller's scope only, never those of other users or
+// sessions.
+func handleGetInvocationHistory(ctx context.Context, request mcp.CallToolRequest, sc *server.ServerContext) (*mcp.CallToolResult, error) {
+	params := extractParams(request)
+
+	limit := defaultHistoryLimit
+	if v := getStringParam(params, "limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{
+					mcp.TextContent{
+						Type: contentTypeText,
+						Text: fmt.Sprintf("Error: limit must be a positive integer (got %q)", v),
+					},
+				},
+			}, nil
+		}
+		limit = n
+	}
+
+	sc.Logger().Debug("Getting invocation history", "limit", limit)
+
+	if !sc.AuditLogEnabled() {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: contentTypeText,
+					Text: "Invocation history is disabled. Start the server with --audit-log-entries to record tool calls.",
+				},
+			},
+		}, nil
+	}
+
+	records := sc.InvocationHistoryOf(callerScope(ctx), limit)
+	if sc.ErrorVerbosity() == server.ErrorVerbositySafe {
+		records = safeInvocationRecords(records)
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.TextContent{
+				Type: contentTypeText,
+				Text: formatInvocationHistory(records),
+			},
+		},
+	}, nil
+}
+
+// safeInvocationRecords applies server.ErrorVerbositySafe to records: the
+// Prometheus URLs are hidden and error messages are reduced to the error code
+// of the tool. Errors already redacted when the call failed keep their
+// request ID.
+func safeInvocationRecords(records []server.ToolInvocationRecord) []server.ToolInvocationRecord {
+	out := make([]server.ToolInvocationRecord, len(records))
+	for i, r := range records {
+		if r.PrometheusURL != "" {
+			r.PrometheusURL = hiddenValue
+		}
+		if r.ErrorMessage != "" && !strings.HasPrefix(r.ErrorMessage, "Error PROM-") {
+			code, ok := toolErrorCodes[r.ToolName]
+			if !ok {
+				code = errCodeToolFailed
+			}
+			r.ErrorMessage = fmt.Sprintf("Error %s: %s", code, errorCodeRegistry[code])
+		}
+		out[i] = r
+	}
+	return out
+}
+
+// formatInvocationHistory renders records as a pipe-separated table.
+func formatInvocationHistory(records []server.ToolInvocationRecord) string {
+	if len(records) == 0 {
+		return "No tool invocations recorded yet."
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Tool invocation history (%d most recent, newest first):\n\n", len(records))
+	b.WriteString("start_time | tool | prometheus_url | org_id | duration | status | error\n")
+	for _, r := range records {
+		status := "ok"
+		if !r.Success {
+			status = "error"
+		}
+		errMsg := strings.Join(strings.Fields(r.ErrorMessage), " ")
+		if r := []rune(errMsg); len(r) > maxHistoryErrorLength {
+			errMsg = string(r[:maxHistoryErrorLength]) + "…"
+		}
+		fmt.Fprintf(&b, "%s | %s | %s | %s | %s | %s | %s\n",
+			r.StartTime.UTC().Format(time.RFC3339),
+			r.ToolName,
+			valueOrNone(r.PrometheusURL),
+			valueOrNone(r.OrgID),
+			r.Duration.Round(time.Millisecond),
+			status,
+			errMsg,
+		)
+	}
+	return b.String()
+}
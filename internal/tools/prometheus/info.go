@@ -0,0 +1,122 @@
+package prometheus
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	v1 "github.com/prometheus/client_golang/api/prometheus/v1"
+)
+
+// uptimeLabel prefixes the server uptime line in get_build_info and
+// get_runtime_info output.
+const uptimeLabel = "⏱ Server uptime:"
+
+// infoRow is one key/value line of a formatted info table.
+type infoRow struct {
+	Key   string
+	Value string
+}
+
+// formatInfoTable renders rows as an aligned "key | value" table under title.
+func formatInfoTable(title string, rows []infoRow) string {
+	width := 0
+	for _, r := range rows {
+		width = max(width, len(r.Key))
+	}
+
+	var b strings.Builder
+	b.WriteString(title + "\n")
+	for _, r := range rows {
+		fmt.Fprintf(&b, "%-*s | %s\n", width, r.Key, r.Value)
+	}
+	return b.String()
+}
+
+// formatUptime renders the time elapsed since start, rounded to seconds.
+// ok is false when start is unknown.
+func formatUptime(start, now time.Time) (string, bool) {
+	if start.IsZero() {
+		return "", false
+	}
+	return now.Sub(start).Round(time.Second).String(), true
+}
+
+// formatBuildInfo renders build information as a table. startTime is the
+// server start time used for the uptime line; the zero time omits it.
+func formatBuildInfo(info v1.BuildinfoResult, startTime, now time.Time) string {
+	text := formatInfoTable("Prometheus Build Information:", []infoRow{
+		{"Version", info.Version},
+		{"Revision", info.Revision},
+		{"Branch", info.Branch},
+		{"Build user", info.BuildUser},
+		{"Build date", info.BuildDate},
+		{"Go version", info.GoVersion},
+	})
+	if uptime, ok := formatUptime(startTime, now); ok {
+		text += fmt.Sprintf("\n%s %s\n", uptimeLabel, uptime)
+	}
+	return text
+}
+
+// formatRuntimeInfo renders runtime information as a table. chunkCount is
+// the number of head chunks from the TSDB status, or -1 when unavailable.
+func formatRuntimeInfo(info RuntimeInfo, chunkCount int, now time.Time) string {
+	memLimit := "unlimited"
+	if info.GOMEMLIMIT > 0 && info.GOMEMLIMIT < 1<<62 {
+		memLimit = formatBytes(info.GOMEMLIMIT)
+	}
+	chunks := "n/a"
+	if chunkCount >= 0 {
+		chunks = strconv.Itoa(chunkCount)
+	}
+
+	text := formatInfoTable("Prometheus Runtime Information:", []infoRow{
+		{"Start time", info.StartTime.UTC().Format(time.RFC3339)},
+		{"Goroutines", strconv.Itoa(info.GoroutineCount)},
+		{"GOMAXPROCS", strconv.Itoa(info.GOMAXPROCS)},
+		{"GOMEMLIMIT", memLimit},
+		{"GOGC", valueOrNone(info.GOGC)},
+		{"Storage retention", valueOrNone(info.StorageRetention)},
+		{"Head chunks", chunks},
+		{"Corruption count", strconv.Itoa(info.CorruptionCount)},
+		{"Config reload successful", strconv.FormatBool(info.ReloadConfigSuccess)},
+		{"Last config time", info.LastConfigTime.UTC().Format(time.RFC3339)},
+		{"Working directory", valueOrNone(info.CWD)},
+	})
+	if uptime, ok := formatUptime(info.StartTime, now); ok {
+		text += fmt.Sprintf("\n%s %s\n", uptimeLabel, uptime)
+	}
+	return text
+}
+
+// formatInfoJSON renders v with an added uptime field as indented JSON.
+func formatInfoJSON(v any, startTime, now time.Time) (string, error) {
+	out := struct {
+		Info   any    `json:"info"`
+		Uptime string `json:"uptime,omitempty"`
+	}{Info: v}
+	out.Uptime, _ = formatUptime(startTime, now)
+
+	data, err := json.MarshalIndent(out, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("marshal info: %w", err)
+	}
+	return string(data), nil
+}
+
+// formatBytes renders n in binary units (KiB, MiB, GiB, …).
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
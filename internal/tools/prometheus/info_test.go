@@ -0,0 +1,118 @@
+package prometheus
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	v1 "github.com/prometheus/client_golang/api/prometheus/v1"
+)
+
+var updateGolden = flag.Bool("update", false, "update golden files in testdata")
+
+// assertGolden compares got with testdata/<name>.golden, rewriting the file
+// when the test binary runs with -update.
+func assertGolden(t *testing.T, name, got string) {
+	t.Helper()
+
+	path := filepath.Join("testdata", name+".golden")
+	if *updateGolden {
+		if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+			t.Fatalf("write golden file: %v", err)
+		}
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read golden file: %v", err)
+	}
+	if got != string(want) {
+		t.Errorf("output does not match %s:\n--- got ---\n%s\n--- want ---\n%s", path, got, want)
+	}
+}
+
+var (
+	infoStartTime = time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC)
+	infoNow       = infoStartTime.Add(49*time.Hour + 3*time.Minute + 7*time.Second + 400*time.Millisecond)
+)
+
+func TestFormatBuildInfo(t *testing.T) {
+	info := v1.BuildinfoResult{
+		Version:   "3.1.0",
+		Revision:  "7d4cd4e8b1d8a1d8e4b7b0b14b62c0e3a1e8f2f2",
+		Branch:    "HEAD",
+		BuildUser: "root@buildkitsandbox",
+		BuildDate: "20260115-10:22:31",
+		GoVersion: "go1.26.0",
+	}
+
+	t.Run("with uptime", func(t *testing.T) {
+		assertGolden(t, "build_info", formatBuildInfo(info, infoStartTime, infoNow))
+	})
+
+	t.Run("without uptime", func(t *testing.T) {
+		got := formatBuildInfo(info, time.Time{}, infoNow)
+		if strings.Contains(got, uptimeLabel) {
+			t.Errorf("expected no uptime line without a start time, got:\n%s", got)
+		}
+	})
+}
+
+func TestFormatRuntimeInfo(t *testing.T) {
+	info := RuntimeInfo{
+		RuntimeinfoResult: v1.RuntimeinfoResult{
+			StartTime:           infoStartTime,
+			CWD:                 "/prometheus",
+			ReloadConfigSuccess: true,
+			LastConfigTime:      infoStartTime.Add(time.Minute),
+			GoroutineCount:      312,
+			GOMAXPROCS:          8,
+			GOGC:                "75",
+			StorageRetention:    "15d",
+		},
+		GOMEMLIMIT: 4 << 30,
+	}
+
+	t.Run("with chunk count", func(t *testing.T) {
+		assertGolden(t, "runtime_info", formatRuntimeInfo(info, 120345, infoNow))
+	})
+
+	t.Run("unknown chunk count and no memory limit", func(t *testing.T) {
+		info := info
+		info.GOMEMLIMIT = 1<<63 - 1
+		got := formatRuntimeInfo(info, -1, infoNow)
+		for _, want := range []string{"GOMEMLIMIT               | unlimited", "Head chunks              | n/a"} {
+			if !strings.Contains(got, want) {
+				t.Errorf("expected %q in output:\n%s", want, got)
+			}
+		}
+	})
+}
+
+func TestFormatInfoJSON(t *testing.T) {
+	got, err := formatInfoJSON(v1.BuildinfoResult{Version: "3.1.0"}, infoStartTime, infoNow)
+	if err != nil {
+		t.Fatalf("formatInfoJSON: %v", err)
+	}
+	for _, want := range []string{`"version": "3.1.0"`, `"uptime": "49h3m7s"`} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected %s in output:\n%s", want, got)
+		}
+	}
+}
+
+func TestFormatBytes(t *testing.T) {
+	tests := map[int64]string{
+		512:     "512 B",
+		2048:    "2.0 KiB",
+		4 << 30: "4.0 GiB",
+	}
+	for n, want := range tests {
+		if got := formatBytes(n); got != want {
+			t.Errorf("formatBytes(%d) = %q, want %q", n, got, want)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package prometheus
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync/atomic"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	mcpserver "github.com/mark3labs/mcp-go/server"
+
+	"github.com/giantswarm/mcp-prometheus/internal/server"
+)
+
+func TestWithDynamicPrometheusClientRunsToolMiddleware(t *testing.T) {
+	var gotHeader string
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotHeader = r.Header.Get("X-Cost-Center")
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[]}}`))
+	}))
+	defer mockServer.Close()
+
+	var order []string
+	var calls atomic.Int64
+
+	injectHeader := func(ctx context.Context, toolName string, next func() (*mcp.CallToolResult, error)) (*mcp.CallToolResult, error) {
+		order = append(order, "inject-header")
+		call, ok := server.ToolCallFromContext(ctx)
+		if !ok {
+			t.Fatal("expected a ToolCall in the middleware context")
+		}
+		if call.Name != toolName || call.Arguments["query"] != "up" {
+			t.Errorf("unexpected tool call: %+v", call)
+		}
+		call.Header.Set("X-Cost-Center", "team-a")
+		return next()
+	}
+	countCalls := func(ctx context.Context, toolName string, next func() (*mcp.CallToolResult, error)) (*mcp.CallToolResult, error) {
+		order = append(order, "count")
+		calls.Add(1)
+		result, err := next()
+		if result == nil || result.IsError {
+			t.Errorf("post-hook expected a successful result, got %+v", result)
+		}
+		return result, err
+	}
+
+	sc, err := server.NewServerContext(context.Background(),
+		server.WithPrometheusConfig(server.PrometheusConfig{URL: mockServer.URL}),
+		server.WithSlogLogger(discardLogger()),
+		server.WithToolMiddleware(injectHeader, countCalls),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create server context: %v", err)
+	}
+	defer func() { _ = sc.Shutdown() }()
+
+	client, err := NewClient(sc.PrometheusConfig(), discardLogger())
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+
+	h := withDynamicPrometheusClient(handleExecuteQuery, client, sc)
+	var req mcp.CallToolRequest
+	req.Params.Name = toolExecuteQuery
+	req.Params.Arguments = map[string]any{"query": "up"}
+
+	result, err := h(context.Background(), req)
+	if err != nil || result.IsError {
+		t.Fatalf("unexpected failure: %v %+v", err, result)
+	}
+
+	if gotHeader != "team-a" {
+		t.Errorf("X-Cost-Center = %q, want %q", gotHeader, "team-a")
+	}
+	if calls.Load() != 1 {
+		t.Errorf("expected 1 counted call, got %d", calls.Load())
+	}
+	if want := []string{"inject-header", "count"}; !reflect.DeepEqual(order, want) {
+		t.Errorf("middleware order = %v, want %v", order, want)
+	}
+}
+
+func TestEveryToolRunsToolMiddleware(t *testing.T) {
+	// The middleware rejects every call, so no tool handler runs.
+	seen := map[string]bool{}
+	reject := func(ctx context.Context, toolName string, _ func() (*mcp.CallToolResult, error)) (*mcp.CallToolResult, error) {
+		if call, ok := server.ToolCallFromContext(ctx); !ok || call.Name != toolName {
+			t.Errorf("%s: unexpected tool call in the middleware context: %+v", toolName, call)
+		}
+		seen[toolName] = true
+		return mcp.NewToolResultError("rejected"), nil
+	}
+
+	sc, err := server.NewServerContext(context.Background(),
+		server.WithPrometheusConfig(server.PrometheusConfig{URL: "http://prometheus:9090"}),
+		server.WithSlogLogger(discardLogger()),
+		server.WithAdminTools(true),
+		server.WithAuditLog(10),
+		server.WithToolMiddleware(reject),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create server context: %v", err)
+	}
+	defer func() { _ = sc.Shutdown() }()
+
+	s := mcpserver.NewMCPServer("test", "1.0.0", mcpserver.WithToolCapabilities(true))
+	if err := RegisterPrometheusTools(s, sc); err != nil {
+		t.Fatalf("RegisterPrometheusTools: %v", err)
+	}
+	for name := range s.ListTools() {
+		params, err := json.Marshal(map[string]any{"name": name, "arguments": map[string]any{}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		s.HandleMessage(context.Background(), []byte(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":`+string(params)+`}`))
+		if !seen[name] {
+			t.Errorf("%s does not run the server's tool middleware", name)
+		}
+	}
+}
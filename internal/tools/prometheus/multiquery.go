@@ -0,0 +1,225 @@
+package prometheus
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/giantswarm/mcp-prometheus/internal/server"
+)
+
+const (
+	// toolExecuteMultiQuery is the registered name of the batch instant-query
+	// tool.
+	toolExecuteMultiQuery = "execute_multi_query"
+
+	// maxMultiQueries is the number of queries one execute_multi_query call
+	// may run.
+	maxMultiQueries = 10
+)
+
+// MultiQueryResult is the outcome of an execute_multi_query call, one entry
+// per query in the order they were given. It is also the structured result
+// of the tool.
+type MultiQueryResult struct {
+	Results []SingleQueryResult `json:"results" jsonschema:"One entry per query, in the order they were given"`
+}
+
+// SingleQueryResult is the outcome of one query of execute_multi_query.
+// Result is set on success and Error on failure.
+type SingleQueryResult struct {
+	Name    string       `json:"name"`
+	Query   string       `json:"query"`
+	Success bool         `json:"success"`
+	Error   string       `json:"error,omitempty" jsonschema:"Why the query failed; an error code under --error-verbosity=safe"`
+	Result  *QueryResult `json:"result,omitempty" jsonschema:"Result type and data as returned by the Prometheus query API"`
+}
+
+// succeeded returns the number of successful queries.
+func (r MultiQueryResult) succeeded() int {
+	n := 0
+	for _, q := range r.Results {
+		if q.Success {
+			n++
+		}
+	}
+	return n
+}
+
+// multiQuery is one named entry of the queries parameter.
+type multiQuery struct {
+	name  string
+	query string
+}
+
+// parseMultiQueries reads the queries parameter: an array of {"name",
+// "query"} objects, or of plain query strings named after their position.
+func parseMultiQueries(params map[string]any) ([]multiQuery, error) {
+	items, _ := params["queries"].([]any)
+	if len(items) == 0 {
+		return nil, fmt.Errorf("queries parameter is required and must be a non-empty array")
+	}
+	if len(items) > maxMultiQueries {
+		return nil, fmt.Errorf("at most %d queries can run in one call (got %d)", maxMultiQueries, len(items))
+	}
+
+	queries := make([]multiQuery, 0, len(items))
+	for i, item := range items {
+		q := multiQuery{name: fmt.Sprintf("query_%d", i+1)}
+		switch v := item.(type) {
+		case string:
+			q.query = v
+		case map[string]any:
+			q.query = getStringParam(v, "query")
+			if name := getStringParam(v, "name"); name != "" {
+				q.name = name
+			}
+		}
+		if strings.TrimSpace(q.query) == "" {
+			return nil, fmt.Errorf("queries[%d] has no query", i)
+		}
+		queries = append(queries, q)
+	}
+	return queries, nil
+}
+
+// handleExecuteMultiQuery handles the execute_multi_query tool. The queries
+// run in parallel at the same evaluation time. Without partial_success the
+// call fails if any query fails; with it, failed queries are reported inline
+// and the call succeeds.
+func handleExecuteMultiQuery(ctx context.Context, request mcp.CallToolRequest, client *Client, sc *server.ServerContext) (*mcp.CallToolResult, error) {
+	params := extractParams(request)
+
+	queries, err := parseMultiQueries(params)
+	if err != nil {
+		return &mcp.CallToolResult{
+			IsError: true,
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: contentTypeText,
+					Text: fmt.Sprintf("Error: %v", err),
+				},
+			},
+		}, nil
+	}
+
+	timeParam := getStringParam(params, "time")
+	evalTime := time.Now()
+	if timeParam != "" {
+		if evalTime, err = parseTimeParam(timeParam); err != nil {
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{
+					mcp.TextContent{
+						Type: contentTypeText,
+						Text: fmt.Sprintf("Error: invalid time: %v", err),
+					},
+				},
+			}, nil
+		}
+	}
+	partial := getStringParam(params, "partial_success") == "true"
+
+	sc.Logger().Debug("Executing PromQL queries", "queries", len(queries), "time", timeParam, "partial_success", partial)
+
+	out := MultiQueryResult{Results: make([]SingleQueryResult, len(queries))}
+	var wg sync.WaitGroup
+	for i, q := range queries {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			out.Results[i] = runMultiQuery(ctx, client, sc, q, evalTime)
+		}()
+	}
+	wg.Wait()
+
+	succeeded := out.succeeded()
+	summary := fmt.Sprintf("%d/%d queries succeeded", succeeded, len(queries))
+	if succeeded < len(queries) && !partial {
+		var b strings.Builder
+		fmt.Fprintf(&b, "Error: %s; set partial_success to 'true' to get the results of the others:\n", summary)
+		for _, r := range out.Results {
+			if !r.Success {
+				fmt.Fprintf(&b, "- %s: %s\n", r.Name, r.Error)
+			}
+		}
+		return &mcp.CallToolResult{
+			IsError: true,
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: contentTypeText,
+					Text: b.String(),
+				},
+			},
+		}, nil
+	}
+
+	// Inline errors bypass the tool-level redaction of failed calls.
+	if sc.ErrorVerbosity() == server.ErrorVerbositySafe {
+		for i, r := range out.Results {
+			if !r.Success {
+				out.Results[i].Error = safeErrorText(sc, toolExecuteMultiQuery, r.Error)
+			}
+		}
+	}
+
+	data, err := json.MarshalIndent(out, "", "  ")
+	if err != nil {
+		return &mcp.CallToolResult{
+			IsError: true,
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: contentTypeText,
+					Text: fmt.Sprintf("Error encoding results: %v", err),
+				},
+			},
+		}, nil
+	}
+	return withStructuredContent(&mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.TextContent{
+				Type: contentTypeText,
+				Text: summary + "\n\n" + string(data),
+			},
+		},
+	}, out), nil
+}
+
+// runMultiQuery runs one query of execute_multi_query at evalTime after
+// applying the query policy and cost guard that execute_query applies.
+func runMultiQuery(ctx context.Context, client *Client, sc *server.ServerContext, q multiQuery, evalTime time.Time) SingleQueryResult {
+	out := SingleQueryResult{Name: q.name, Query: q.query}
+	if refused := checkQueryPolicy(sc, q.query, time.Time{}, time.Time{}, 0); refused != nil {
+		out.Error = resultText(refused)
+		return out
+	}
+	if _, refused := checkQueryCost(ctx, client, sc, q.query, evalTime, evalTime, 1); refused != nil {
+		out.Error = resultText(refused)
+		return out
+	}
+
+	result, err := client.ExecuteQuery(ctx, q.query, evalTime.Format(time.RFC3339Nano))
+	if err != nil {
+		sc.Logger().Debug("Query of a batch failed", "name", q.name, "error", err)
+		out.Error = describeQueryError("Error executing query", err)
+		return out
+	}
+	out.Success, out.Result = true, result
+	return out
+}
+
+// resultText returns the concatenated text content of result.
+func resultText(result *mcp.CallToolResult) string {
+	var b strings.Builder
+	for _, c := range result.Content {
+		if tc, ok := c.(mcp.TextContent); ok {
+			b.WriteString(tc.Text)
+		}
+	}
+	return b.String()
+}
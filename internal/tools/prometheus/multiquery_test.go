@@ -0,0 +1,168 @@
+package prometheus
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/giantswarm/mcp-prometheus/internal/server"
+)
+
+// multiQueryMockServer answers "up" with one sample and rejects any other
+// query as a parse error.
+func multiQueryMockServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		w.Header().Set("Content-Type", "application/json")
+		if r.Form.Get("query") != "up" {
+			w.WriteHeader(http.StatusBadRequest)
+			_ = json.NewEncoder(w).Encode(map[string]any{respKeyStatus: "error", "errorType": "bad_data", "error": "1:3: parse error: unexpected end of input"})
+			return
+		}
+		samples := []any{map[string]any{"metric": map[string]string{"__name__": "up", "job": "api"}, "value": []any{1704067200, "1"}}}
+		_ = json.NewEncoder(w).Encode(map[string]any{respKeyStatus: respValSuccess, respKeyData: map[string]any{respKeyResultType: respValVector, respKeyResult: samples}})
+	}))
+}
+
+func TestHandleExecuteMultiQuery(t *testing.T) {
+	mockServer := multiQueryMockServer()
+	defer mockServer.Close()
+
+	ctx := context.Background()
+	for _, verbosity := range []server.ErrorVerbosity{server.ErrorVerbosityDetailed, server.ErrorVerbositySafe} {
+		sc, err := server.NewServerContext(ctx,
+			server.WithPrometheusConfig(server.PrometheusConfig{URL: mockServer.URL}),
+			server.WithErrorVerbosity(verbosity),
+			server.WithSlogLogger(discardLogger()),
+		)
+		if err != nil {
+			t.Fatalf("Failed to create server context: %v", err)
+		}
+		client, err := NewClient(sc.PrometheusConfig(), sc.Logger())
+		if err != nil {
+			t.Fatalf("NewClient: %v", err)
+		}
+
+		call := func(args map[string]any) *mcp.CallToolResult {
+			t.Helper()
+			args["queries"] = []any{
+				map[string]any{"name": "targets", "query": "up"},
+				"sum(",
+				map[string]any{"name": "again", "query": "up"},
+			}
+			result, err := handleExecuteMultiQuery(ctx, mcp.CallToolRequest{Params: mcp.CallToolParams{Name: toolExecuteMultiQuery, Arguments: args}}, client, sc)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			return result
+		}
+
+		result := call(map[string]any{})
+		text := result.Content[0].(mcp.TextContent).Text
+		if !result.IsError || !strings.Contains(text, "2/3 queries succeeded") || !strings.Contains(text, "query_2") {
+			t.Errorf("%s: expected the batch to fail without partial_success, got:\n%s", verbosity, text)
+		}
+
+		result = call(map[string]any{"partial_success": "true"})
+		text = result.Content[0].(mcp.TextContent).Text
+		if result.IsError || !strings.HasPrefix(text, "2/3 queries succeeded\n\n") {
+			t.Fatalf("%s: expected a partial success, got:\n%s", verbosity, text)
+		}
+		var out MultiQueryResult
+		if err := json.Unmarshal([]byte(strings.TrimPrefix(text, "2/3 queries succeeded\n\n")), &out); err != nil {
+			t.Fatalf("%s: result is not JSON: %v\n%s", verbosity, err, text)
+		}
+		if len(out.Results) != 3 || !out.Results[0].Success || out.Results[0].Name != "targets" || out.Results[0].Result == nil ||
+			out.Results[1].Success || out.Results[1].Result != nil || !out.Results[2].Success {
+			t.Errorf("%s: unexpected results: %+v", verbosity, out.Results)
+		}
+		leaked := strings.Contains(out.Results[1].Error, "unexpected end of input")
+		if verbosity == server.ErrorVerbositySafe && (leaked || !strings.Contains(out.Results[1].Error, errCodeQuery)) {
+			t.Errorf("safe: expected an opaque error, got %q", out.Results[1].Error)
+		}
+		if verbosity == server.ErrorVerbosityDetailed && !leaked {
+			t.Errorf("detailed: expected the parse error, got %q", out.Results[1].Error)
+		}
+		_ = sc.Shutdown()
+	}
+}
+
+func TestHandleExecuteMultiQuerySharesEvaluationTime(t *testing.T) {
+	var mu sync.Mutex
+	times := map[string]int{}
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		mu.Lock()
+		times[r.Form.Get("time")]++
+		mu.Unlock()
+		// Queries evaluated at their own time.Now() would each get another time.
+		time.Sleep(20 * time.Millisecond)
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{respKeyStatus: respValSuccess, respKeyData: map[string]any{respKeyResultType: respValVector, respKeyResult: []any{}}})
+	}))
+	defer mockServer.Close()
+
+	limiter, err := server.NewRequestLimiter(0, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	sc, err := server.NewServerContext(ctx,
+		server.WithPrometheusConfig(server.PrometheusConfig{URL: mockServer.URL}),
+		server.WithRequestLimiter(limiter),
+		server.WithSlogLogger(discardLogger()),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create server context: %v", err)
+	}
+	defer func() { _ = sc.Shutdown() }()
+	client, err := NewClient(sc.PrometheusConfig(), sc.Logger())
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+
+	queries := make([]any, maxMultiQueries)
+	for i := range queries {
+		queries[i] = "up"
+	}
+	result, err := handleExecuteMultiQuery(ctx, mcp.CallToolRequest{Params: mcp.CallToolParams{Name: toolExecuteMultiQuery, Arguments: map[string]any{"queries": queries}}}, client, sc)
+	if err != nil || result.IsError {
+		t.Fatalf("unexpected failure: %v %v", err, result.Content)
+	}
+	if len(times) != 1 {
+		t.Errorf("expected every query to run at one evaluation time, got %v", times)
+	}
+	out, ok := result.StructuredContent.(MultiQueryResult)
+	if !ok || len(out.Results) != maxMultiQueries {
+		t.Errorf("expected a structured MultiQueryResult, got %#v", result.StructuredContent)
+	}
+}
+
+func TestParseMultiQueries(t *testing.T) {
+	queries, err := parseMultiQueries(map[string]any{"queries": []any{"up", map[string]any{"name": "n", "query": "rate(x[5m])"}}})
+	if err != nil || len(queries) != 2 || queries[0].name != "query_1" || queries[1].name != "n" {
+		t.Errorf("parseMultiQueries = %+v, %v", queries, err)
+	}
+
+	tooMany := make([]any, maxMultiQueries+1)
+	for i := range tooMany {
+		tooMany[i] = "up"
+	}
+	for _, params := range []map[string]any{
+		{},
+		{"queries": []any{}},
+		{"queries": []any{map[string]any{"name": "empty"}}},
+		{"queries": tooMany},
+	} {
+		if _, err := parseMultiQueries(params); err == nil {
+			t.Errorf("expected an error for %v", params)
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package prometheus
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/prometheus/common/model"
+)
+
+const (
+	// namespaceLabel is the Kubernetes namespace label used by
+	// group_by_namespace and namespace_filter.
+	namespaceLabel = "namespace"
+
+	// noNamespaceGroup names the group of series without a namespace label.
+	noNamespaceGroup = "(no namespace)"
+)
+
+// filterByNamespace keeps the items whose namespace contains filter. Items
+// without a namespace never match a non-empty filter.
+func filterByNamespace[T any](items []T, namespace func(T) string, filter string) []T {
+	if filter == "" {
+		return items
+	}
+	return slices.DeleteFunc(slices.Clone(items), func(item T) bool {
+		ns := namespace(item)
+		return ns == "" || !strings.Contains(ns, filter)
+	})
+}
+
+// groupByNamespace splits items by namespace. Namespaces are returned sorted,
+// with noNamespaceGroup last.
+func groupByNamespace[T any](items []T, namespace func(T) string) ([]string, map[string][]T) {
+	groups := make(map[string][]T)
+	for _, item := range items {
+		ns := namespace(item)
+		if ns == "" {
+			ns = noNamespaceGroup
+		}
+		groups[ns] = append(groups[ns], item)
+	}
+
+	names := make([]string, 0, len(groups))
+	for ns := range groups {
+		if ns != noNamespaceGroup {
+			names = append(names, ns)
+		}
+	}
+	slices.Sort(names)
+	if _, ok := groups[noNamespaceGroup]; ok {
+		names = append(names, noNamespaceGroup)
+	}
+	return names, groups
+}
+
+// namespaceHeader renders the section header of one namespace group.
+func namespaceHeader(ns string, count int) string {
+	return fmt.Sprintf("=== namespace: %s (%d series) ===\n", ns, count)
+}
+
+// indentLines prefixes every non-empty line of s with two spaces.
+func indentLines(s string) string {
+	lines := strings.SplitAfter(s, "\n")
+	for i, line := range lines {
+		if strings.TrimSpace(line) != "" {
+			lines[i] = "  " + line
+		}
+	}
+	return strings.Join(lines, "")
+}
+
+func vectorSampleNamespace(s *model.Sample) string {
+	return string(s.Metric[namespaceLabel])
+}
+
+func sampleStreamNamespace(s *model.SampleStream) string {
+	return string(s.Metric[namespaceLabel])
+}
+
+func seriesNamespace(s map[string]string) string {
+	return s[namespaceLabel]
+}
+
+// filterQueryResultByNamespace applies namespace_filter to vector and matrix
+// results; other result types are returned unchanged.
+func filterQueryResultByNamespace(result any, filter string) any {
+	switch v := result.(type) {
+	case model.Vector:
+		return model.Vector(filterByNamespace(v, vectorSampleNamespace, filter))
+	case model.Matrix:
+		return model.Matrix(filterByNamespace(v, sampleStreamNamespace, filter))
+	}
+	return result
+}
+
+// formatQueryResultByNamespace renders vector and matrix results as one
+// section per namespace, with unit as in formatVector. It reports false for
+// other result types, which have no labels to group by.
+func formatQueryResultByNamespace(result any, unit string) (string, bool) {
+	var b strings.Builder
+	switch v := result.(type) {
+	case model.Vector:
+		if len(v) == 0 {
+			return formatVector(v, unit), true
+		}
+		names, groups := groupByNamespace(v, vectorSampleNamespace)
+		fmt.Fprintf(&b, "%d series in %d namespaces:\n", len(v), len(names))
+		for _, ns := range names {
+			b.WriteString("\n" + namespaceHeader(ns, len(groups[ns])))
+			b.WriteString(indentLines(formatVectorRows(groups[ns], unit)))
+		}
+	case model.Matrix:
+		if len(v) == 0 {
+			return formatMatrix(v, unit), true
+		}
+		names, groups := groupByNamespace(v, sampleStreamNamespace)
+		fmt.Fprintf(&b, "%d series in %d namespaces:\n", len(v), len(names))
+		for _, ns := range names {
+			b.WriteString("\n" + namespaceHeader(ns, len(groups[ns])))
+			b.WriteString(indentLines(strings.TrimPrefix(formatMatrixSeries(groups[ns], unit), "\n")))
+		}
+	default:
+		return "", false
+	}
+	return b.String(), true
+}
+
+// formatSeriesByNamespace renders find_series results as one section per
+// namespace, listing at most limit series in total.
+func formatSeriesByNamespace(series []map[string]string, limit int) string {
+	names, groups := groupByNamespace(series, seriesNamespace)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Found %d series in %d namespaces:\n", len(series), len(names))
+	listed := 0
+	for _, ns := range names {
+		if listed >= limit {
+			break
+		}
+		b.WriteString("\n" + namespaceHeader(ns, len(groups[ns])))
+		for _, s := range groups[ns] {
+			if listed >= limit {
+				break
+			}
+			fmt.Fprintf(&b, "  %+v\n", s)
+			listed++
+		}
+	}
+	if listed < len(series) {
+		fmt.Fprintf(&b, "... and %d more series\n", len(series)-listed)
+	}
+	return b.String()
+}
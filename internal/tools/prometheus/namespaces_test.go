@@ -0,0 +1,159 @@
+package prometheus
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/giantswarm/mcp-prometheus/internal/server"
+)
+
+// namespaceMockServer serves the same mixed-namespace series from the query
+// and series APIs.
+func namespaceMockServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	series := []map[string]string{
+		{"__name__": "up", "namespace": "production", "pod": "api-1"},
+		{"__name__": "up", "namespace": "monitoring", "pod": "prometheus-0"},
+		{"__name__": "up", "pod": "node-exporter"},
+		{"__name__": "up", "namespace": "production", "pod": "api-2"},
+		{"__name__": "up", "namespace": "production-canary", "pod": "api-3"},
+	}
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var data any
+		switch r.URL.Path {
+		case apiQueryPath:
+			samples := make([]any, len(series))
+			for i, s := range series {
+				samples[i] = map[string]any{"metric": s, "value": []any{1704067200, "1"}}
+			}
+			data = map[string]any{respKeyResultType: respValVector, respKeyResult: samples}
+		case "/api/v1/series":
+			data = series
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{respKeyStatus: respValSuccess, respKeyData: data})
+	}))
+}
+
+func TestHandleExecuteQueryGroupByNamespace(t *testing.T) {
+	mockServer := namespaceMockServer(t)
+	defer mockServer.Close()
+
+	ctx := context.Background()
+	sc, err := server.NewServerContext(ctx,
+		server.WithPrometheusConfig(server.PrometheusConfig{URL: mockServer.URL}),
+		server.WithSlogLogger(discardLogger()),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create server context: %v", err)
+	}
+	defer func() { _ = sc.Shutdown() }()
+
+	client, err := NewClient(sc.PrometheusConfig(), sc.Logger())
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+
+	run := func(args map[string]any) string {
+		t.Helper()
+		request := mcp.CallToolRequest{Params: mcp.CallToolParams{Name: toolExecuteQuery, Arguments: args}}
+		result, err := handleExecuteQuery(ctx, request, client, sc)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		text := result.Content[0].(mcp.TextContent).Text
+		if result.IsError {
+			t.Fatalf("expected success, got error: %s", text)
+		}
+		return text
+	}
+
+	text := run(map[string]any{paramKeyQuery: "up", "group_by_namespace": "true"})
+	want := "5 series in 4 namespaces:\n" +
+		"\n=== namespace: monitoring (1 series) ===\n" +
+		"  series | value | timestamp\n" +
+		`  up{namespace="monitoring", pod="prometheus-0"} | 1 | 2024-01-01T00:00:00.000Z` + "\n" +
+		"\n=== namespace: production (2 series) ===\n" +
+		"  series | value | timestamp\n" +
+		`  up{namespace="production", pod="api-1"} | 1 | 2024-01-01T00:00:00.000Z` + "\n" +
+		`  up{namespace="production", pod="api-2"} | 1 | 2024-01-01T00:00:00.000Z` + "\n" +
+		"\n=== namespace: production-canary (1 series) ===\n" +
+		"  series | value | timestamp\n" +
+		`  up{namespace="production-canary", pod="api-3"} | 1 | 2024-01-01T00:00:00.000Z` + "\n" +
+		"\n=== namespace: (no namespace) (1 series) ===\n" +
+		"  series | value | timestamp\n" +
+		`  up{pod="node-exporter"} | 1 | 2024-01-01T00:00:00.000Z` + "\n"
+	if !strings.HasSuffix(text, want) {
+		t.Errorf("grouped output =\n%s\nwant suffix\n%s", text, want)
+	}
+
+	text = run(map[string]any{paramKeyQuery: "up", "namespace_filter": "prod"})
+	if !strings.Contains(text, "3 series:") || strings.Contains(text, "monitoring") || strings.Contains(text, "node-exporter") {
+		t.Errorf("filtered output kept unexpected series:\n%s", text)
+	}
+}
+
+func TestHandleFindSeriesGroupByNamespace(t *testing.T) {
+	mockServer := namespaceMockServer(t)
+	defer mockServer.Close()
+
+	ctx := context.Background()
+	sc, err := server.NewServerContext(ctx,
+		server.WithPrometheusConfig(server.PrometheusConfig{URL: mockServer.URL}),
+		server.WithSlogLogger(discardLogger()),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create server context: %v", err)
+	}
+	defer func() { _ = sc.Shutdown() }()
+
+	client, err := NewClient(sc.PrometheusConfig(), sc.Logger())
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+
+	request := mcp.CallToolRequest{Params: mcp.CallToolParams{Name: "find_series", Arguments: map[string]any{
+		"matches":            []any{"up"},
+		"group_by_namespace": "true",
+		"namespace_filter":   "production",
+	}}}
+	result, err := handleFindSeries(ctx, request, client, sc)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	text := result.Content[0].(mcp.TextContent).Text
+	if result.IsError {
+		t.Fatalf("expected success, got error: %s", text)
+	}
+
+	for _, want := range []string{
+		"Found 3 series in 2 namespaces:\n",
+		"=== namespace: production (2 series) ===\n",
+		"=== namespace: production-canary (1 series) ===\n",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("output missing %q:\n%s", want, text)
+		}
+	}
+	if strings.Contains(text, noNamespaceGroup) || strings.Contains(text, "monitoring") {
+		t.Errorf("namespace_filter kept unexpected series:\n%s", text)
+	}
+
+	formatted := formatSeriesByNamespace([]map[string]string{{"pod": "a"}, {"namespace": "x"}, {"pod": "b"}}, 2)
+	want := "Found 3 series in 2 namespaces:\n" +
+		"\n=== namespace: x (1 series) ===\n  map[namespace:x]\n" +
+		"\n=== namespace: (no namespace) (2 series) ===\n  map[pod:a]\n" +
+		"... and 1 more series\n"
+	if formatted != want {
+		t.Errorf("formatSeriesByNamespace() =\n%s\nwant\n%s", formatted, want)
+	}
+}
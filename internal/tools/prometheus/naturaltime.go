@@ -0,0 +1,96 @@
+package prometheus
+
+import (
+	"regexp"
+	"strings"
+	"time"
+)
+
+// naturalTimePattern maps a time expression to the instant it names,
+// relative to now.
+type naturalTimePattern struct {
+	re      *regexp.Regexp
+	resolve func(now time.Time, match []string) time.Time
+}
+
+// weekdays maps lower-case weekday names to time.Weekday.
+var weekdays = map[string]time.Weekday{
+	"sunday":    time.Sunday,
+	"monday":    time.Monday,
+	"tuesday":   time.Tuesday,
+	"wednesday": time.Wednesday,
+	"thursday":  time.Thursday,
+	"friday":    time.Friday,
+	"saturday":  time.Saturday,
+}
+
+// naturalTimePatterns are the supported time expressions, tried in order.
+// The list is deliberately small; anything else is left to the timestamp
+// parsers of parseTimeParam.
+var naturalTimePatterns = []naturalTimePattern{
+	{
+		re: regexp.MustCompile(`^today$`),
+		resolve: func(now time.Time, _ []string) time.Time {
+			return startOfDay(now)
+		},
+	},
+	{
+		re: regexp.MustCompile(`^yesterday$`),
+		resolve: func(now time.Time, _ []string) time.Time {
+			return startOfDay(now).AddDate(0, 0, -1)
+		},
+	},
+	{
+		re: regexp.MustCompile(`^last (monday|tuesday|wednesday|thursday|friday|saturday|sunday)$`),
+		resolve: func(now time.Time, match []string) time.Time {
+			today := startOfDay(now)
+			days := (int(today.Weekday()) - int(weekdays[match[1]]) + 7) % 7
+			if days == 0 {
+				days = 7
+			}
+			return today.AddDate(0, 0, -days)
+		},
+	},
+	{
+		re: regexp.MustCompile(`^(this|last) week$`),
+		resolve: func(now time.Time, match []string) time.Time {
+			today := startOfDay(now)
+			monday := today.AddDate(0, 0, -(int(today.Weekday())+6)%7)
+			if match[1] == "last" {
+				return monday.AddDate(0, 0, -7)
+			}
+			return monday
+		},
+	},
+	{
+		re: regexp.MustCompile(`^(this|last) month$`),
+		resolve: func(now time.Time, match []string) time.Time {
+			now = now.UTC()
+			first := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+			if match[1] == "last" {
+				return first.AddDate(0, -1, 0)
+			}
+			return first
+		},
+	},
+}
+
+// startOfDay returns midnight UTC of the day of t.
+func startOfDay(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// parseNaturalTime resolves a time expression such as "yesterday",
+// "last Monday" or "this month" to the start of the day, week or month it
+// names, in UTC. Matching ignores case and extra whitespace. ok is false for
+// unsupported expressions.
+func parseNaturalTime(expr string, now time.Time) (t time.Time, ok bool) {
+	expr = strings.ToLower(strings.Join(strings.Fields(expr), " "))
+	for _, p := range naturalTimePatterns {
+		if match := p.re.FindStringSubmatch(expr); match != nil {
+			return p.resolve(now, match), true
+		}
+	}
+	return time.Time{}, false
+}
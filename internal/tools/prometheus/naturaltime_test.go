@@ -0,0 +1,74 @@
+package prometheus
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseNaturalTime(t *testing.T) {
+	// Wednesday
+	now := time.Date(2024, 3, 13, 15, 4, 5, 0, time.UTC)
+	day := func(year int, month time.Month, d int) time.Time {
+		return time.Date(year, month, d, 0, 0, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name string
+		expr string
+		now  time.Time
+		want time.Time
+	}{
+		{name: "today", expr: "today", now: now, want: day(2024, 3, 13)},
+		{name: "yesterday", expr: "yesterday", now: now, want: day(2024, 3, 12)},
+		{name: "yesterday across month", expr: "yesterday", now: day(2024, 3, 1), want: day(2024, 2, 29)},
+		{name: "last monday", expr: "last monday", now: now, want: day(2024, 3, 11)},
+		{name: "last tuesday", expr: "last tuesday", now: now, want: day(2024, 3, 12)},
+		{name: "last wednesday is a week ago", expr: "last wednesday", now: now, want: day(2024, 3, 6)},
+		{name: "last thursday", expr: "last thursday", now: now, want: day(2024, 3, 7)},
+		{name: "last friday", expr: "last friday", now: now, want: day(2024, 3, 8)},
+		{name: "last saturday", expr: "last saturday", now: now, want: day(2024, 3, 9)},
+		{name: "last sunday", expr: "last sunday", now: now, want: day(2024, 3, 10)},
+		{name: "this week", expr: "this week", now: now, want: day(2024, 3, 11)},
+		{name: "last week", expr: "last week", now: now, want: day(2024, 3, 4)},
+		{name: "this week on monday", expr: "this week", now: day(2024, 3, 11), want: day(2024, 3, 11)},
+		{name: "this week on sunday", expr: "this week", now: day(2024, 3, 17), want: day(2024, 3, 11)},
+		{name: "this month", expr: "this month", now: now, want: day(2024, 3, 1)},
+		{name: "last month", expr: "last month", now: now, want: day(2024, 2, 1)},
+		{name: "last month across year", expr: "last month", now: day(2024, 1, 20), want: day(2023, 12, 1)},
+		{name: "case insensitive", expr: "Last Monday", now: now, want: day(2024, 3, 11)},
+		{name: "extra whitespace", expr: "  last   week ", now: now, want: day(2024, 3, 4)},
+		{name: "upper case", expr: "YESTERDAY", now: now, want: day(2024, 3, 12)},
+		{name: "non-UTC now", expr: "today", now: time.Date(2024, 3, 13, 23, 30, 0, 0, time.FixedZone("UTC-2", -2*3600)), want: day(2024, 3, 14)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := parseNaturalTime(tt.expr, tt.now)
+			if !ok {
+				t.Fatalf("parseNaturalTime(%q) not recognised", tt.expr)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("parseNaturalTime(%q) = %v, want %v", tt.expr, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseNaturalTimeUnsupported(t *testing.T) {
+	now := time.Date(2024, 3, 13, 15, 4, 5, 0, time.UTC)
+	for _, expr := range []string{"", "tomorrow", "next week", "last year", "last mon", "2 days ago", "this monday", "1704067200"} {
+		if got, ok := parseNaturalTime(expr, now); ok {
+			t.Errorf("parseNaturalTime(%q) = %v, expected unsupported", expr, got)
+		}
+	}
+}
+
+func TestParseTimeParamNaturalTime(t *testing.T) {
+	got, err := parseTimeParam("yesterday")
+	if err != nil {
+		t.Fatalf("parseTimeParam(yesterday): %v", err)
+	}
+	if want := startOfDay(time.Now()).AddDate(0, 0, -1); !got.Equal(want) {
+		t.Errorf("parseTimeParam(yesterday) = %v, want %v", got, want)
+	}
+}
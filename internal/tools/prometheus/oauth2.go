@@ -0,0 +1,76 @@
+package prometheus
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
+
+	"github.com/giantswarm/mcp-prometheus/internal/server"
+)
+
+// tokenSources caches token sources by configuration. Clients created for a
+// single tool call (profile, prometheus_url or org_id overrides) share the
+// cached access token instead of requesting a new one on every call.
+var (
+	tokenSourcesMu sync.Mutex
+	tokenSources   = map[[sha256.Size]byte]oauth2.TokenSource{}
+)
+
+// cachedTokenSource returns the token source cached for the given key parts,
+// creating it with create on first use. Failed creations are not cached.
+func cachedTokenSource(create func() (oauth2.TokenSource, error), keyParts ...string) (oauth2.TokenSource, error) {
+	key := sha256.Sum256([]byte(strings.Join(keyParts, "\x00")))
+
+	tokenSourcesMu.Lock()
+	defer tokenSourcesMu.Unlock()
+	if ts, ok := tokenSources[key]; ok {
+		return ts, nil
+	}
+	ts, err := create()
+	if err != nil {
+		return nil, err
+	}
+	tokenSources[key] = ts
+	return ts, nil
+}
+
+// validateOAuth2Config checks the client credentials settings of config.
+func validateOAuth2Config(config server.OAuth2Config) error {
+	if err := validateTargetURL("OAuth2 token URL", config.TokenURL); err != nil {
+		return err
+	}
+	if config.ClientID == "" {
+		return fmt.Errorf("OAuth2 client ID is required with a token URL")
+	}
+	return nil
+}
+
+// oauth2TokenSource returns the token source for the client credentials of
+// config. Token requests go through transport so they use the same TLS
+// settings as requests to Prometheus; tokens are refreshed shortly before
+// they expire.
+func oauth2TokenSource(config server.PrometheusConfig, transport http.RoundTripper) oauth2.TokenSource {
+	c := config.OAuth2
+	ts, _ := cachedTokenSource(func() (oauth2.TokenSource, error) {
+		credentials := clientcredentials.Config{
+			ClientID:     c.ClientID,
+			ClientSecret: c.ClientSecret,
+			TokenURL:     c.TokenURL,
+			Scopes:       c.Scopes,
+		}
+		// The token source outlives any single request, so it gets its own
+		// context carrying the HTTP client for token requests.
+		ctx := context.WithValue(context.Background(), oauth2.HTTPClient, &http.Client{Transport: transport, Timeout: 10 * time.Second})
+		return credentials.TokenSource(ctx), nil
+	}, "oauth2", c.TokenURL, c.ClientID, c.ClientSecret, strings.Join(c.Scopes, " "),
+		config.TLSCACert, strconv.FormatBool(config.TLSSkipVerify))
+	return ts
+}
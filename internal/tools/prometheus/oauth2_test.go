@@ -0,0 +1,147 @@
+package prometheus
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/giantswarm/mcp-prometheus/internal/server"
+)
+
+// oauth2Backend is a token endpoint plus a Prometheus that only accepts the
+// tokens it issued.
+type oauth2Backend struct {
+	token      *httptest.Server
+	prometheus *httptest.Server
+
+	mu        sync.Mutex
+	issued    int
+	lastScope string
+	seen      []string
+}
+
+func newOAuth2Backend(t *testing.T, expiresIn int) *oauth2Backend {
+	t.Helper()
+	b := &oauth2Backend{}
+	b.token = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, secret, _ := r.BasicAuth()
+		if err := r.ParseForm(); err != nil || r.PostForm.Get("grant_type") != "client_credentials" || id != "mcp" || secret != "s3cret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		b.mu.Lock()
+		b.issued++
+		b.lastScope = r.PostForm.Get("scope")
+		token := fmt.Sprintf("token-%d", b.issued)
+		b.mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{"access_token": token, "token_type": "bearer", "expires_in": expiresIn})
+	}))
+	t.Cleanup(b.token.Close)
+	b.prometheus = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth := r.Header.Get("Authorization")
+		b.mu.Lock()
+		b.seen = append(b.seen, auth)
+		b.mu.Unlock()
+		if !strings.HasPrefix(auth, "Bearer token-") {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		tlsQueryHandler(w, r)
+	}))
+	t.Cleanup(b.prometheus.Close)
+	return b
+}
+
+func (b *oauth2Backend) config() server.PrometheusConfig {
+	return server.PrometheusConfig{
+		URL: b.prometheus.URL,
+		OAuth2: server.OAuth2Config{
+			TokenURL:     b.token.URL,
+			ClientID:     "mcp",
+			ClientSecret: "s3cret",
+			Scopes:       []string{"metrics.read", "metrics.query"},
+		},
+	}
+}
+
+func TestNewClientOAuth2(t *testing.T) {
+	b := newOAuth2Backend(t, 3600)
+	ctx := context.Background()
+
+	// Clients created per tool call share the cached token.
+	for range 3 {
+		client, err := NewClient(b.config(), discardLogger())
+		if err != nil {
+			t.Fatalf("NewClient: %v", err)
+		}
+		if _, err := client.ExecuteQuery(ctx, "up", ""); err != nil {
+			t.Fatalf("ExecuteQuery: %v", err)
+		}
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.issued != 1 {
+		t.Errorf("token endpoint called %d times, want 1", b.issued)
+	}
+	if b.lastScope != "metrics.read metrics.query" {
+		t.Errorf("scope = %q", b.lastScope)
+	}
+	for _, auth := range b.seen {
+		if auth != "Bearer token-1" {
+			t.Errorf("Authorization = %q, want Bearer token-1", auth)
+		}
+	}
+}
+
+func TestNewClientOAuth2Refresh(t *testing.T) {
+	// Tokens expiring within the refresh margin are replaced on every use.
+	b := newOAuth2Backend(t, 1)
+	client, err := NewClient(b.config(), discardLogger())
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	for range 2 {
+		if _, err := client.ExecuteQuery(context.Background(), "up", ""); err != nil {
+			t.Fatalf("ExecuteQuery: %v", err)
+		}
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.issued != 2 || b.seen[1] != "Bearer token-2" {
+		t.Errorf("issued %d tokens, requests used %q; want a refreshed token", b.issued, b.seen)
+	}
+}
+
+func TestNewClientOAuth2Errors(t *testing.T) {
+	b := newOAuth2Backend(t, 3600)
+
+	config := b.config()
+	config.OAuth2.ClientID = ""
+	if _, err := NewClient(config, discardLogger()); err == nil {
+		t.Error("expected an error without a client ID")
+	}
+
+	config = b.config()
+	config.OAuth2.TokenURL = "file:///etc/token"
+	if _, err := NewClient(config, discardLogger()); err == nil {
+		t.Error("expected an error for a non-HTTP token URL")
+	}
+
+	config = b.config()
+	config.OAuth2.ClientSecret = "wrong"
+	client, err := NewClient(config, discardLogger())
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	if _, err := client.ExecuteQuery(context.Background(), "up", ""); err == nil || !strings.Contains(err.Error(), "oauth2") {
+		t.Errorf("ExecuteQuery error = %v, want the token request failure", err)
+	}
+}
@@ -0,0 +1,50 @@
+package prometheus
+
+import (
+	"encoding/base64"
+	"errors"
+	"slices"
+)
+
+// defaultLabelValuesPageSize is the number of values list_label_values
+// returns per page when page_size is not given.
+const defaultLabelValuesPageSize = 100
+
+// Cursors are keyset cursors: they encode the last value of a page, and the
+// next page starts at the first value sorting after it. Unlike an offset, a
+// cursor stays valid when values are added or removed between calls, so
+// walking a growing metric catalog neither repeats nor skips names.
+
+// encodeCursor returns the opaque cursor for the page ending at last.
+func encodeCursor(last string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(last))
+}
+
+// decodeCursor returns the value a cursor from encodeCursor points after. An
+// empty cursor decodes to the empty string, i.e. the first page.
+func decodeCursor(cursor string) (string, error) {
+	last, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return "", errors.New("cursor is not a value returned by a previous call")
+	}
+	return string(last), nil
+}
+
+// paginate returns up to pageSize of the sorted values after the value after
+// (all values when after is empty), the index of the first returned value and
+// the cursor of the next page, empty on the last page.
+func paginate(values []string, after string, pageSize int) (page []string, start int, next string) {
+	if after != "" {
+		var found bool
+		start, found = slices.BinarySearch(values, after)
+		if found {
+			start++
+		}
+	}
+	end := min(start+pageSize, len(values))
+	page = values[start:end]
+	if end < len(values) {
+		next = encodeCursor(values[end-1])
+	}
+	return page, start, next
+}
@@ -0,0 +1,124 @@
+package prometheus
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/giantswarm/mcp-prometheus/internal/server"
+)
+
+func TestPaginate(t *testing.T) {
+	values := []string{"a", "b", "c", "d", "e"}
+	tests := []struct {
+		name      string
+		after     string
+		pageSize  int
+		wantPage  []string
+		wantStart int
+		wantNext  string
+	}{
+		{name: "first page", pageSize: 2, wantPage: []string{"a", "b"}, wantStart: 0, wantNext: "b"},
+		{name: "middle page", after: "b", pageSize: 2, wantPage: []string{"c", "d"}, wantStart: 2, wantNext: "d"},
+		{name: "last page", after: "d", pageSize: 2, wantPage: []string{"e"}, wantStart: 4},
+		{name: "exact fit", pageSize: 5, wantPage: values},
+		{name: "cursor value removed since", after: "bb", pageSize: 2, wantPage: []string{"c", "d"}, wantStart: 2, wantNext: "d"},
+		{name: "past the end", after: "z", pageSize: 2, wantPage: []string{}, wantStart: 5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page, start, next := paginate(values, tt.after, tt.pageSize)
+			if !slices.Equal(page, tt.wantPage) || start != tt.wantStart {
+				t.Errorf("page = %q from %d, want %q from %d", page, start, tt.wantPage, tt.wantStart)
+			}
+			wantNext := ""
+			if tt.wantNext != "" {
+				wantNext = encodeCursor(tt.wantNext)
+			}
+			if next != wantNext {
+				t.Errorf("next = %q, want %q", next, wantNext)
+			}
+		})
+	}
+}
+
+func TestDecodeCursor(t *testing.T) {
+	if got, err := decodeCursor(""); err != nil || got != "" {
+		t.Errorf("empty cursor: got %q, %v", got, err)
+	}
+	if got, err := decodeCursor(encodeCursor("node_load1")); err != nil || got != "node_load1" {
+		t.Errorf("round trip: got %q, %v", got, err)
+	}
+	if _, err := decodeCursor("not a cursor!"); err == nil {
+		t.Error("expected an error for a malformed cursor")
+	}
+}
+
+func TestHandleListLabelValuesPagination(t *testing.T) {
+	names := []string{"up", "go_goroutines", "node_load1", "http_requests_total", "process_cpu_seconds_total"}
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{respKeyStatus: respValSuccess, respKeyData: names})
+	}))
+	defer mockServer.Close()
+
+	ctx := context.Background()
+	sc, err := server.NewServerContext(ctx,
+		server.WithPrometheusConfig(server.PrometheusConfig{URL: mockServer.URL}),
+		server.WithSlogLogger(discardLogger()),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create server context: %v", err)
+	}
+	defer func() { _ = sc.Shutdown() }()
+
+	client, err := NewClient(sc.PrometheusConfig(), sc.Logger())
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+
+	var walked []string
+	cursor := ""
+	for range len(names) {
+		args := map[string]any{"label": "__name__", "page_size": "2"}
+		if cursor != "" {
+			args["cursor"] = cursor
+		}
+		request := mcp.CallToolRequest{Params: mcp.CallToolParams{Name: "list_label_values", Arguments: args}}
+		result, err := handleListLabelValues(ctx, request, client, sc)
+		if err != nil || result.IsError {
+			t.Fatalf("unexpected error: %v %v", err, result.Content)
+		}
+		out := result.StructuredContent.(LabelValuesOutput)
+		walked = append(walked, out.Values...)
+
+		text := result.Content[0].(mcp.TextContent).Text
+		if out.NextCursor != "" && !strings.Contains(text, out.NextCursor) {
+			t.Errorf("text does not mention the next cursor:\n%s", text)
+		}
+		if cursor = out.NextCursor; cursor == "" {
+			break
+		}
+	}
+
+	want := slices.Sorted(slices.Values(names))
+	if !slices.Equal(walked, want) {
+		t.Errorf("walked %q, want %q", walked, want)
+	}
+
+	for _, args := range []map[string]any{
+		{"label": "__name__", "cursor": "%%%"},
+		{"label": "__name__", "page_size": "0"},
+		{"label": "__name__", "page_size": "1001"},
+	} {
+		request := mcp.CallToolRequest{Params: mcp.CallToolParams{Name: "list_label_values", Arguments: args}}
+		if result, _ := handleListLabelValues(ctx, request, client, sc); !result.IsError {
+			t.Errorf("expected an error for %v", args)
+		}
+	}
+}
@@ -0,0 +1,215 @@
+package prometheus
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	mcpserver "github.com/mark3labs/mcp-go/server"
+	"github.com/prometheus/common/model"
+)
+
+// Names of the investigation prompts.
+const (
+	promptInvestigateAlert = "investigate_alert"
+	promptCardinalityAudit = "cardinality_audit"
+	promptCapacityReview   = "capacity_review"
+	promptTargetDownTriage = "target_down_triage"
+)
+
+// investigationPrompt is a prompt walking the client's model through an
+// investigation with the server's tools. render returns the instructions for
+// the given arguments, with times resolved against now, since the tools do
+// not accept relative times.
+type investigationPrompt struct {
+	prompt mcp.Prompt
+	render func(args map[string]string, now time.Time) (string, error)
+}
+
+// investigationPrompts returns the prompts registered by
+// registerInvestigationPrompts.
+func investigationPrompts() []investigationPrompt {
+	return []investigationPrompt{
+		{
+			prompt: mcp.NewPrompt(promptInvestigateAlert,
+				mcp.WithPromptDescription("Find out why an alert fires: its rule, active instances, timeline and silences"),
+				mcp.WithArgument("alertname", mcp.RequiredArgument(), mcp.ArgumentDescription("Name of the alert")),
+				mcp.WithArgument("lookback", mcp.ArgumentDescription("How far back to look, as a Prometheus duration (default: 6h)")),
+			),
+			render: renderInvestigateAlert,
+		},
+		{
+			prompt: mcp.NewPrompt(promptCardinalityAudit,
+				mcp.WithPromptDescription("Find the metrics and labels with the most series and how to reduce them"),
+				mcp.WithArgument("job", mcp.ArgumentDescription("Only audit the series of this job")),
+				mcp.WithArgument("top", mcp.ArgumentDescription("Number of metrics and labels to report (default: 10)")),
+			),
+			render: renderCardinalityAudit,
+		},
+		{
+			prompt: mcp.NewPrompt(promptCapacityReview,
+				mcp.WithPromptDescription("Review CPU, memory and disk usage and project when resources run out"),
+				mcp.WithArgument("namespace", mcp.ArgumentDescription("Only review workloads of this Kubernetes namespace; nodes are reviewed otherwise")),
+				mcp.WithArgument("lookback", mcp.ArgumentDescription("History to base the trends on, as a Prometheus duration (default: 7d)")),
+			),
+			render: renderCapacityReview,
+		},
+		{
+			prompt: mcp.NewPrompt(promptTargetDownTriage,
+				mcp.WithPromptDescription("Triage scrape targets that are down: which ones, since when and why"),
+				mcp.WithArgument("job", mcp.ArgumentDescription("Only triage the targets of this job")),
+			),
+			render: renderTargetDownTriage,
+		},
+	}
+}
+
+// registerInvestigationPrompts registers the investigation prompts.
+func registerInvestigationPrompts(s *mcpserver.MCPServer) {
+	for _, p := range investigationPrompts() {
+		s.AddPrompt(p.prompt, func(_ context.Context, request mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+			text, err := p.render(request.Params.Arguments, time.Now())
+			if err != nil {
+				return nil, err
+			}
+			return mcp.NewGetPromptResult(p.prompt.Description, []mcp.PromptMessage{
+				mcp.NewPromptMessage(mcp.RoleUser, mcp.NewTextContent(text)),
+			}), nil
+		})
+	}
+}
+
+// lookbackWindow parses the lookback argument, defaulting to def, and
+// returns the start and end of the window ending at now as RFC3339.
+func lookbackWindow(args map[string]string, def string, now time.Time) (lookback, start, end string, err error) {
+	lookback = args["lookback"]
+	if lookback == "" {
+		lookback = def
+	}
+	d, err := model.ParseDuration(lookback)
+	if err != nil || d <= 0 {
+		return "", "", "", fmt.Errorf("invalid lookback %q: want a Prometheus duration such as 6h or 7d", lookback)
+	}
+	now = now.UTC().Truncate(time.Second)
+	return lookback, now.Add(-time.Duration(d)).Format(time.RFC3339), now.Format(time.RFC3339), nil
+}
+
+// jobSelector returns the matcher restricting queries to job, or "" for all
+// jobs.
+func jobSelector(job string) string {
+	if job == "" {
+		return ""
+	}
+	return "job=" + strconv.Quote(job)
+}
+
+func renderInvestigateAlert(args map[string]string, now time.Time) (string, error) {
+	alert := strings.TrimSpace(args["alertname"])
+	if alert == "" {
+		return "", fmt.Errorf("alertname argument is required")
+	}
+	lookback, start, end, err := lookbackWindow(args, "6h", now)
+	if err != nil {
+		return "", err
+	}
+	matcher := "alertname=" + strconv.Quote(alert)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Investigate why the Prometheus alert %s fires, looking at the last %s (%s to %s).\n\n", alert, lookback, start, end)
+	fmt.Fprintf(&b, "1. Call get_alerts and list the instances of %s: their labels, state (pending or firing) and since when they are active.\n", alert)
+	fmt.Fprintf(&b, "2. Call get_rules and find the alerting rule %s. Note its expression, its for duration, labels and annotations (summary, description, runbook_url).\n", alert)
+	fmt.Fprintf(&b, "3. Call evaluate_rule_timeline with the rule expression as rule_expr, its for duration as for_duration, start %s, end %s and a step of about a hundredth of the window, to see when the alert fired and resolved.\n", start, end)
+	fmt.Fprintf(&b, "4. Run the rule expression without its threshold comparison with execute_range_query over the same window, to see which series drive it and how far they are from the threshold.\n")
+	fmt.Fprintf(&b, "5. For the job and instance labels of the active instances, call get_targets with summary_only 'true' to check scrape health, and query related metrics of the same job with execute_query.\n")
+	fmt.Fprintf(&b, "6. Call get_alertmanager_alerts with filter ['%s'] to see whether the alert is silenced or inhibited and which receivers it goes to.\n\n", matcher)
+	b.WriteString("Summarise what fires, since when, the most likely cause and the next steps. Quote the queries you ran.")
+	return b.String(), nil
+}
+
+func renderCardinalityAudit(args map[string]string, _ time.Time) (string, error) {
+	top := 10
+	if raw := args["top"]; raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 {
+			return "", fmt.Errorf("invalid top %q: want a positive integer", raw)
+		}
+		top = n
+	}
+	job := jobSelector(args["job"])
+
+	var b strings.Builder
+	if job != "" {
+		fmt.Fprintf(&b, "Audit the series cardinality of job %q and find how to reduce it.\n\n", args["job"])
+	} else {
+		b.WriteString("Audit the series cardinality of this Prometheus server and find how to reduce it.\n\n")
+	}
+	fmt.Fprintf(&b, "1. Call get_tsdb_stats with limit '%d' for the total series count and the metrics, labels and label pairs with the most series.\n", top)
+	if job != "" {
+		fmt.Fprintf(&b, "2. Run execute_query with topk(%d, count by (__name__) ({%s})) to find the largest metrics of the job.\n", top, job)
+	} else {
+		fmt.Fprintf(&b, "2. Run execute_query with topk(%d, count by (job) ({__name__=~\".+\"})) to find the jobs exposing the most series.\n", top)
+	}
+	fmt.Fprintf(&b, "3. For each of the %d largest metrics, run execute_query with count(count by (<label>) (<metric>%s)) for each of its labels, to find the labels with the most values. Labels holding IDs, URLs, paths or timestamps are the usual culprits.\n", top, selectorSuffix(job))
+	b.WriteString("4. Call get_series_count_history over the last 7 days with a step of 6h to see whether the series count grows.\n")
+	b.WriteString("5. Call get_metric_metadata for the largest metrics to see what they measure, and find_series to look at a few of their series.\n\n")
+	fmt.Fprintf(&b, "Report the top %d metrics and labels by series count with their share of the total. For each, suggest a fix: dropping the metric or label with metric_relabel_configs, aggregating it with a recording rule, or bucketing the label values.", top)
+	return b.String(), nil
+}
+
+// selectorSuffix returns matcher wrapped in braces, or "" without one.
+func selectorSuffix(matcher string) string {
+	if matcher == "" {
+		return ""
+	}
+	return "{" + matcher + "}"
+}
+
+func renderCapacityReview(args map[string]string, now time.Time) (string, error) {
+	lookback, start, end, err := lookbackWindow(args, "7d", now)
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	if ns := args["namespace"]; ns != "" {
+		sel := "{namespace=" + strconv.Quote(ns) + ", container!=\"\"}"
+		fmt.Fprintf(&b, "Review the CPU and memory capacity of the workloads in namespace %q over the last %s (%s to %s).\n\n", ns, lookback, start, end)
+		fmt.Fprintf(&b, "1. Use search_metrics to confirm the cAdvisor and kube-state-metrics metrics below exist, and adapt the queries if they are named differently.\n")
+		fmt.Fprintf(&b, "2. Run execute_range_query from %s to %s with sum by (pod) (rate(container_cpu_usage_seconds_total%s[5m])) and compare it with sum by (pod) (kube_pod_container_resource_requests{namespace=%q, resource=\"cpu\"}) and the matching limits.\n", start, end, sel, ns)
+		fmt.Fprintf(&b, "3. Do the same for memory with sum by (pod) (container_memory_working_set_bytes%s) against the memory requests and limits.\n", sel)
+		fmt.Fprintf(&b, "4. Run execute_query with sum by (pod) (increase(kube_pod_container_status_restarts_total{namespace=%q}[%s])) and look for OOM kills in kube_pod_container_status_last_terminated_reason.\n", ns, lookback)
+		fmt.Fprintf(&b, "5. Call execute_range_query with include_trend 'true' on the namespace totals to project usage a week past %s.\n\n", end)
+		b.WriteString("Report the pods that are over- or under-provisioned with suggested requests and limits, and the projected headroom of the namespace.")
+		return b.String(), nil
+	}
+
+	fmt.Fprintf(&b, "Review the CPU, memory and disk capacity of the nodes over the last %s (%s to %s).\n\n", lookback, start, end)
+	b.WriteString("1. Use search_metrics to confirm the node_exporter metrics below exist, and adapt the queries if they are named differently.\n")
+	fmt.Fprintf(&b, "2. Run execute_range_query from %s to %s with 1 - avg by (instance) (rate(node_cpu_seconds_total{mode=\"idle\"}[5m])) for CPU utilisation.\n", start, end)
+	b.WriteString("3. Do the same with 1 - node_memory_MemAvailable_bytes / node_memory_MemTotal_bytes for memory utilisation.\n")
+	b.WriteString("4. Do the same with 1 - node_filesystem_avail_bytes{fstype!~\"tmpfs|overlay\"} / node_filesystem_size_bytes{fstype!~\"tmpfs|overlay\"} for disk utilisation.\n")
+	b.WriteString("5. Repeat the queries with include_trend 'true' and project_steps covering a week, and run predict_linear(node_filesystem_avail_bytes{fstype!~\"tmpfs|overlay\"}[1d], 7 * 86400) < 0 with execute_query to find disks filling up within a week.\n\n")
+	b.WriteString("Report per resource the busiest nodes with peak and average utilisation, the projected time until they run out, and whether to add capacity or rebalance.")
+	return b.String(), nil
+}
+
+func renderTargetDownTriage(args map[string]string, _ time.Time) (string, error) {
+	job := jobSelector(args["job"])
+
+	var b strings.Builder
+	if job != "" {
+		fmt.Fprintf(&b, "Triage the scrape targets of job %q that are down.\n\n", args["job"])
+	} else {
+		b.WriteString("Triage the scrape targets that are down.\n\n")
+	}
+	b.WriteString("1. Call get_targets with summary_only 'true' to find the scrape pools with unhealthy targets.\n")
+	b.WriteString("2. Call get_targets and, for every target whose health is not up, note its scrape URL, last error and last scrape time.\n")
+	fmt.Fprintf(&b, "3. Run execute_query with up%s == 0 to confirm which series are down, and execute_range_query over the last 24 hours with the same query to see since when and whether they flap.\n", selectorSuffix(job))
+	fmt.Fprintf(&b, "4. Run execute_query with scrape_duration_seconds%s and scrape_samples_scraped%s to spot targets that time out or exceed sample limits.\n", selectorSuffix(job), selectorSuffix(job))
+	b.WriteString("5. Call get_config and read the scrape config of the affected jobs: scheme, timeouts, TLS and authentication settings.\n\n")
+	b.WriteString("Group the down targets by cause (connection refused, DNS, timeout, TLS, authentication, HTTP status, sample limit) and suggest a fix for each group.")
+	return b.String(), nil
+}
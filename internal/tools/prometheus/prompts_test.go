@@ -0,0 +1,148 @@
+package prometheus
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	mcpserver "github.com/mark3labs/mcp-go/server"
+
+	"github.com/giantswarm/mcp-prometheus/internal/server"
+)
+
+// getPrompt gets the prompt name with args through the MCP message handler,
+// as a client would, and returns the text of its message or the error.
+func getPrompt(t *testing.T, s *mcpserver.MCPServer, name string, args map[string]string) (string, bool) {
+	t.Helper()
+
+	params, err := json.Marshal(map[string]any{"name": name, "arguments": args})
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := `{"jsonrpc":"2.0","id":1,"method":"prompts/get","params":` + string(params) + `}`
+	data, err := json.Marshal(s.HandleMessage(context.Background(), []byte(msg)))
+	if err != nil {
+		t.Fatalf("marshal response: %v", err)
+	}
+
+	var decoded struct {
+		Result *struct {
+			Messages []struct {
+				Role    string `json:"role"`
+				Content struct {
+					Text string `json:"text"`
+				} `json:"content"`
+			} `json:"messages"`
+		} `json:"result"`
+	}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("unmarshal response: %v", err)
+	}
+	if decoded.Result == nil || len(decoded.Result.Messages) != 1 {
+		return string(data), false
+	}
+	return decoded.Result.Messages[0].Content.Text, true
+}
+
+func TestInvestigationPrompts(t *testing.T) {
+	sc, err := server.NewServerContext(context.Background(), server.WithSlogLogger(discardLogger()))
+	if err != nil {
+		t.Fatalf("Failed to create server context: %v", err)
+	}
+	defer func() { _ = sc.Shutdown() }()
+
+	s := mcpserver.NewMCPServer("test", "1.0.0")
+	if err := RegisterPrometheusTools(s, sc); err != nil {
+		t.Fatalf("RegisterPrometheusTools: %v", err)
+	}
+
+	tests := []struct {
+		prompt string
+		args   map[string]string
+		// tools must be registered and named in the prompt.
+		tools []string
+		want  []string
+	}{
+		{promptInvestigateAlert, map[string]string{"alertname": "HighErrorRate", "lookback": "2h"},
+			[]string{"get_alerts", "get_rules", "evaluate_rule_timeline", "execute_range_query", "get_targets", "get_alertmanager_alerts"},
+			[]string{"alert HighErrorRate", "last 2h", `filter ['alertname="HighErrorRate"']`}},
+		{promptCardinalityAudit, map[string]string{"job": "node", "top": "5"},
+			[]string{"get_tsdb_stats", "execute_query", "get_series_count_history", "get_metric_metadata", "find_series"},
+			[]string{`job "node"`, "limit '5'", `topk(5, count by (__name__) ({job="node"}))`}},
+		{promptCardinalityAudit, nil, nil, []string{"this Prometheus server", "count by (job)", "top 10"}},
+		{promptCapacityReview, nil,
+			[]string{"search_metrics", "execute_range_query", "execute_query"},
+			[]string{"nodes over the last 7d", "node_filesystem_avail_bytes"}},
+		{promptCapacityReview, map[string]string{"namespace": "payments"}, nil,
+			[]string{`namespace "payments"`, `container_memory_working_set_bytes{namespace="payments", container!=""}`}},
+		{promptTargetDownTriage, map[string]string{"job": "api"},
+			[]string{"get_targets", "execute_query", "execute_range_query", "get_config"},
+			[]string{`up{job="api"} == 0`}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.prompt, func(t *testing.T) {
+			text, ok := getPrompt(t, s, tt.prompt, tt.args)
+			if !ok {
+				t.Fatalf("getting %s failed: %s", tt.prompt, text)
+			}
+			for _, tool := range tt.tools {
+				if s.GetTool(tool) == nil {
+					t.Errorf("%s refers to unregistered tool %s", tt.prompt, tool)
+				}
+				if !strings.Contains(text, tool) {
+					t.Errorf("expected %s in %s:\n%s", tool, tt.prompt, text)
+				}
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(text, want) {
+					t.Errorf("expected %q in %s:\n%s", want, tt.prompt, text)
+				}
+			}
+		})
+	}
+}
+
+func TestInvestigationPromptErrors(t *testing.T) {
+	s := mcpserver.NewMCPServer("test", "1.0.0")
+	registerInvestigationPrompts(s)
+
+	tests := []struct {
+		prompt string
+		args   map[string]string
+	}{
+		{promptInvestigateAlert, nil},
+		{promptInvestigateAlert, map[string]string{"alertname": "A", "lookback": "yesterday"}},
+		{promptCardinalityAudit, map[string]string{"top": "0"}},
+		{promptCapacityReview, map[string]string{"lookback": "-1h"}},
+	}
+	for _, tt := range tests {
+		if text, ok := getPrompt(t, s, tt.prompt, tt.args); ok {
+			t.Errorf("%s with %v: expected an error, got:\n%s", tt.prompt, tt.args, text)
+		}
+	}
+}
+
+func TestLookbackWindow(t *testing.T) {
+	now := time.Date(2024, 1, 2, 12, 0, 0, 500, time.UTC)
+	lookback, start, end, err := lookbackWindow(map[string]string{}, "1d", now)
+	if err != nil || lookback != "1d" || start != "2024-01-01T12:00:00Z" || end != "2024-01-02T12:00:00Z" {
+		t.Errorf("lookbackWindow() = %q, %q, %q, %v", lookback, start, end, err)
+	}
+}
+
+func TestPromptCompletions(t *testing.T) {
+	var requests atomic.Int32
+	s := newCompletionTestServer(t, &requests)
+
+	msg := `{"jsonrpc":"2.0","id":1,"method":"completion/complete","params":{"ref":{"type":"ref/prompt","name":"` + promptTargetDownTriage + `"},"argument":{"name":"job","value":"no"}}}`
+	data, err := json.Marshal(s.HandleMessage(context.Background(), []byte(msg)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"values":["node-exporter"]`) {
+		t.Errorf("expected node-exporter completed, got %s", data)
+	}
+}
@@ -0,0 +1,223 @@
+package prometheus
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/promql/parser"
+
+	"github.com/giantswarm/mcp-prometheus/internal/promql"
+	"github.com/giantswarm/mcp-prometheus/internal/server"
+)
+
+// toolQueryBuilder is the registered name of the structured query builder.
+const toolQueryBuilder = "query_builder"
+
+// defaultBuilderRange is the range window query_builder uses with a range
+// function when none is given.
+const defaultBuilderRange = "5m"
+
+// builderRangeFunctions are the range_function values query_builder accepts.
+var builderRangeFunctions = []string{
+	"rate", "irate", "increase", "delta", "idelta", "deriv", "changes", "resets",
+	"avg_over_time", "min_over_time", "max_over_time", "sum_over_time", "count_over_time",
+	"last_over_time", "stddev_over_time", "stdvar_over_time", "present_over_time", "quantile_over_time",
+}
+
+// builderAggregations are the aggregation values query_builder accepts.
+var builderAggregations = []string{
+	"sum", "avg", "min", "max", "count", "group", "stddev", "stdvar",
+	"topk", "bottomk", "quantile", "histogram_quantile",
+}
+
+// querySpec holds the structured inputs of query_builder.
+type querySpec struct {
+	Metric        string
+	Filters       []string
+	RangeFunction string
+	Range         string
+	Aggregation   string
+	GroupBy       []string
+	Quantile      string
+	K             string
+}
+
+// buildQuery composes spec into a PromQL expression, innermost first: the
+// selector, the range function applied to it, then the aggregation. The
+// result is parsed and returned in the parser's canonical form, so label
+// values are quoted and escaped correctly whatever the input.
+func buildQuery(spec querySpec) (string, error) {
+	if !model.IsValidLegacyMetricName(spec.Metric) {
+		return "", fmt.Errorf("invalid metric name %q", spec.Metric)
+	}
+
+	matchers := []*labels.Matcher{labels.MustNewMatcher(labels.MatchEqual, labels.MetricName, spec.Metric)}
+	for _, f := range spec.Filters {
+		m, err := promql.ParseMatchers("{" + f + "}")
+		if err != nil || len(m) != 1 {
+			return "", fmt.Errorf("invalid filter %q: want one label matcher such as job=\"api\" or code=~\"5..\"", f)
+		}
+		matchers = append(matchers, m[0])
+	}
+	query := (&parser.VectorSelector{Name: spec.Metric, LabelMatchers: matchers}).String()
+
+	needsQuantile := spec.RangeFunction == "quantile_over_time" || spec.Aggregation == "quantile" || spec.Aggregation == "histogram_quantile"
+	switch {
+	case needsQuantile && spec.Quantile == "":
+		return "", fmt.Errorf("quantile is required with quantile_over_time, quantile and histogram_quantile")
+	case !needsQuantile && spec.Quantile != "":
+		return "", fmt.Errorf("quantile is only used with quantile_over_time, quantile and histogram_quantile")
+	case spec.Quantile != "":
+		if q, err := strconv.ParseFloat(spec.Quantile, 64); err != nil || q < 0 || q > 1 {
+			return "", fmt.Errorf("quantile must be a number between 0 and 1, got %q", spec.Quantile)
+		}
+	}
+
+	if spec.RangeFunction != "" {
+		if !slices.Contains(builderRangeFunctions, spec.RangeFunction) {
+			return "", fmt.Errorf("unsupported range_function %q; use one of %s", spec.RangeFunction, strings.Join(builderRangeFunctions, ", "))
+		}
+		window := spec.Range
+		if window == "" {
+			window = defaultBuilderRange
+		}
+		if _, err := model.ParseDuration(window); err != nil {
+			return "", fmt.Errorf("invalid range %q: %w", window, err)
+		}
+		if spec.RangeFunction == "quantile_over_time" {
+			query = fmt.Sprintf("quantile_over_time(%s, %s[%s])", spec.Quantile, query, window)
+		} else {
+			query = fmt.Sprintf("%s(%s[%s])", spec.RangeFunction, query, window)
+		}
+	} else if spec.Range != "" {
+		return "", fmt.Errorf("range is only used with range_function")
+	}
+
+	for _, l := range spec.GroupBy {
+		if !model.LabelName(l).IsValid() {
+			return "", fmt.Errorf("invalid group_by label name %q", l)
+		}
+	}
+	isTopK := spec.Aggregation == "topk" || spec.Aggregation == "bottomk"
+	if isTopK != (spec.K != "") {
+		return "", fmt.Errorf("k is required with topk and bottomk and not used otherwise")
+	}
+	if isTopK {
+		if k, err := strconv.Atoi(spec.K); err != nil || k < 1 {
+			return "", fmt.Errorf("k must be a positive integer, got %q", spec.K)
+		}
+	}
+
+	switch spec.Aggregation {
+	case "":
+		if len(spec.GroupBy) > 0 {
+			return "", fmt.Errorf("group_by is only used with aggregation")
+		}
+	case "histogram_quantile":
+		// Buckets are summed per le; dropping it would mix the buckets.
+		grouping := []string{model.BucketLabel}
+		for _, l := range spec.GroupBy {
+			if l != model.BucketLabel {
+				grouping = append(grouping, l)
+			}
+		}
+		query = fmt.Sprintf("histogram_quantile(%s, sum by (%s) (%s))", spec.Quantile, strings.Join(grouping, ", "), query)
+	default:
+		if !slices.Contains(builderAggregations, spec.Aggregation) {
+			return "", fmt.Errorf("unsupported aggregation %q; use one of %s", spec.Aggregation, strings.Join(builderAggregations, ", "))
+		}
+		by := ""
+		if len(spec.GroupBy) > 0 {
+			by = " by (" + strings.Join(spec.GroupBy, ", ") + ")"
+		}
+		switch spec.Aggregation {
+		case "topk", "bottomk":
+			query = fmt.Sprintf("%s%s (%s, %s)", spec.Aggregation, by, spec.K, query)
+		case "quantile":
+			query = fmt.Sprintf("quantile%s (%s, %s)", by, spec.Quantile, query)
+		default:
+			query = fmt.Sprintf("%s%s (%s)", spec.Aggregation, by, query)
+		}
+	}
+
+	expr, err := promql.Parse(query)
+	if err != nil {
+		return "", fmt.Errorf("built query %q is not valid PromQL: %w", query, err)
+	}
+	return expr.String(), nil
+}
+
+// handleQueryBuilder handles the query_builder tool. The built query is only
+// returned unless execute is "true"; then it runs like query_templates, as a
+// range query when start or end is given and as an instant query otherwise.
+func handleQueryBuilder(ctx context.Context, request mcp.CallToolRequest, client *Client, sc *server.ServerContext) (*mcp.CallToolResult, error) {
+	params := extractParams(request)
+
+	spec := querySpec{
+		Metric:        getStringParam(params, "metric"),
+		Filters:       extractStringArray(params, "filters"),
+		RangeFunction: getStringParam(params, "range_function"),
+		Range:         getStringParam(params, "range"),
+		Aggregation:   getStringParam(params, "aggregation"),
+		GroupBy:       extractStringArray(params, "group_by"),
+		Quantile:      getStringParam(params, "quantile"),
+		K:             getStringParam(params, "k"),
+	}
+	query, err := buildQuery(spec)
+	if err != nil {
+		return &mcp.CallToolResult{
+			IsError: true,
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: contentTypeText,
+					Text: fmt.Sprintf("Error: %v", err),
+				},
+			},
+		}, nil
+	}
+
+	sc.Logger().Debug("Built query", "query", query)
+
+	if getStringParam(params, "execute") != "true" {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: contentTypeText,
+					Text: query,
+				},
+			},
+		}, nil
+	}
+
+	args := maps.Clone(params)
+	for _, key := range []string{"metric", "filters", "range_function", "range", "aggregation", "group_by", "quantile", "k", "execute"} {
+		delete(args, key)
+	}
+	args["query"] = query
+	request.Params.Arguments = args
+
+	var result *mcp.CallToolResult
+	if getStringParam(params, "start") != "" || getStringParam(params, "end") != "" {
+		result, err = handleExecuteRangeQuery(ctx, request, client, sc)
+	} else {
+		result, err = handleExecuteQuery(ctx, request, client, sc)
+	}
+	if err != nil || result == nil {
+		return result, err
+	}
+
+	if len(result.Content) > 0 {
+		if tc, ok := result.Content[0].(mcp.TextContent); ok {
+			tc.Text = fmt.Sprintf("Built query: %s\n\n%s", query, tc.Text)
+			result.Content[0] = tc
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,153 @@
+package prometheus
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/giantswarm/mcp-prometheus/internal/server"
+)
+
+func TestBuildQuery(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    querySpec
+		want    string
+		wantErr string
+	}{
+		{
+			name: "selector",
+			spec: querySpec{Metric: "up", Filters: []string{`job="api"`, `instance!~"10\\..*"`}},
+			want: `up{instance!~"10\\..*",job="api"}`,
+		},
+		{
+			name: "error rate per job",
+			spec: querySpec{Metric: "http_requests_total", Filters: []string{`code=~"5.."`}, RangeFunction: "rate", Aggregation: "sum", GroupBy: []string{"job"}},
+			want: `sum by (job) (rate(http_requests_total{code=~"5.."}[5m]))`,
+		},
+		{
+			name: "histogram quantile",
+			spec: querySpec{Metric: "http_request_duration_seconds_bucket", RangeFunction: "rate", Range: "10m", Aggregation: "histogram_quantile", GroupBy: []string{"route"}, Quantile: "0.99"},
+			want: `histogram_quantile(0.99, sum by (le, route) (rate(http_request_duration_seconds_bucket[10m])))`,
+		},
+		{
+			name: "topk",
+			spec: querySpec{Metric: "node_memory_MemAvailable_bytes", Aggregation: "bottomk", K: "3"},
+			want: `bottomk(3, node_memory_MemAvailable_bytes)`,
+		},
+		{
+			name: "quantile over time",
+			spec: querySpec{Metric: "queue_length", RangeFunction: "quantile_over_time", Range: "1h", Quantile: "0.9"},
+			want: `quantile_over_time(0.9, queue_length[1h])`,
+		},
+		{
+			name: "label value needing escapes",
+			spec: querySpec{Metric: "up", Filters: []string{`path="/a\"b"`}},
+			want: `up{path="/a\"b"}`,
+		},
+		{name: "invalid metric", spec: querySpec{Metric: "up{"}, wantErr: "invalid metric name"},
+		{name: "invalid filter", spec: querySpec{Metric: "up", Filters: []string{"job"}}, wantErr: "invalid filter"},
+		{name: "two matchers in one filter", spec: querySpec{Metric: "up", Filters: []string{`job="a",env="b"`}}, wantErr: "invalid filter"},
+		{name: "unknown range function", spec: querySpec{Metric: "up", RangeFunction: "predict_linear"}, wantErr: "unsupported range_function"},
+		{name: "invalid range", spec: querySpec{Metric: "up", RangeFunction: "rate", Range: "5 minutes"}, wantErr: "invalid range"},
+		{name: "range without function", spec: querySpec{Metric: "up", Range: "5m"}, wantErr: "range is only used"},
+		{name: "missing quantile", spec: querySpec{Metric: "x_bucket", Aggregation: "histogram_quantile"}, wantErr: "quantile is required"},
+		{name: "quantile out of range", spec: querySpec{Metric: "x", Aggregation: "quantile", Quantile: "99"}, wantErr: "between 0 and 1"},
+		{name: "unused quantile", spec: querySpec{Metric: "x", Aggregation: "sum", Quantile: "0.5"}, wantErr: "only used"},
+		{name: "missing k", spec: querySpec{Metric: "x", Aggregation: "topk"}, wantErr: "k is required"},
+		{name: "group_by without aggregation", spec: querySpec{Metric: "x", GroupBy: []string{"job"}}, wantErr: "group_by is only used"},
+		{name: "unknown aggregation", spec: querySpec{Metric: "x", Aggregation: "median"}, wantErr: "unsupported aggregation"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := buildQuery(tt.spec)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("buildQuery() error = %v, want it to contain %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("buildQuery() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("buildQuery() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHandleQueryBuilder(t *testing.T) {
+	var gotQuery string
+	requests := 0
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		_ = r.ParseForm()
+		gotQuery = r.Form.Get(paramKeyQuery)
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			respKeyStatus: respValSuccess,
+			respKeyData:   map[string]any{respKeyResultType: respValVector, respKeyResult: []any{}},
+		})
+	}))
+	defer mockServer.Close()
+
+	ctx := context.Background()
+	sc, err := server.NewServerContext(ctx,
+		server.WithPrometheusConfig(server.PrometheusConfig{URL: mockServer.URL}),
+		server.WithSlogLogger(discardLogger()),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create server context: %v", err)
+	}
+	defer func() { _ = sc.Shutdown() }()
+
+	client, err := NewClient(sc.PrometheusConfig(), sc.Logger())
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+
+	call := func(args map[string]any) (*mcp.CallToolResult, string) {
+		t.Helper()
+		request := mcp.CallToolRequest{Params: mcp.CallToolParams{Name: toolQueryBuilder, Arguments: args}}
+		result, err := handleQueryBuilder(ctx, request, client, sc)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return result, result.Content[0].(mcp.TextContent).Text
+	}
+
+	args := map[string]any{
+		"metric":         "http_requests_total",
+		"filters":        []any{`code=~"5.."`},
+		"range_function": "rate",
+		"aggregation":    "sum",
+		"group_by":       []any{"job"},
+	}
+	const want = `sum by (job) (rate(http_requests_total{code=~"5.."}[5m]))`
+	result, text := call(args)
+	if result.IsError || text != want {
+		t.Errorf("build only = %q (error %t), want %q", text, result.IsError, want)
+	}
+	if requests != 0 {
+		t.Errorf("Prometheus received %d requests without execute", requests)
+	}
+
+	args["execute"] = "true"
+	result, text = call(args)
+	if result.IsError {
+		t.Fatalf("expected success, got error: %s", text)
+	}
+	if gotQuery != want || !strings.HasPrefix(text, "Built query: "+want+"\n\n") {
+		t.Errorf("Prometheus received %q, output:\n%s", gotQuery, text)
+	}
+
+	if result, text := call(map[string]any{"metric": "up", "aggregation": "topk"}); !result.IsError || !strings.Contains(text, "k is required") {
+		t.Errorf("expected a k error, got %q", text)
+	}
+}
@@ -0,0 +1,97 @@
+package prometheus
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/promql/parser"
+
+	"github.com/giantswarm/mcp-prometheus/internal/promql"
+	"github.com/giantswarm/mcp-prometheus/internal/server"
+)
+
+// queryPolicyViolations describes how query, evaluated from start to end
+// every step, breaks policy, each with what to change. Zero times skip the
+// range check and a zero step the step check, as for instant queries. A query
+// that fails to parse only gets the range and step checks; Prometheus
+// reports the parse error itself.
+func queryPolicyViolations(policy server.QueryPolicy, query string, start, end time.Time, step time.Duration) []string {
+	var out []string
+	if policy.MaxRange > 0 && end.Sub(start) > policy.MaxRange {
+		out = append(out, fmt.Sprintf("the time range of %s exceeds the maximum of %s; move start closer to end",
+			model.Duration(end.Sub(start)), model.Duration(policy.MaxRange)))
+	}
+	if policy.MinStep > 0 && step > 0 && step < policy.MinStep {
+		out = append(out, fmt.Sprintf("the step of %s is below the minimum of %s; use step=%s or larger",
+			model.Duration(step), model.Duration(policy.MinStep), model.Duration(policy.MinStep)))
+	}
+
+	expr, err := promql.Parse(query)
+	if err != nil {
+		return out
+	}
+	parser.Inspect(expr, func(node parser.Node, _ []parser.Node) error {
+		switch n := node.(type) {
+		case *parser.MatrixSelector:
+			if policy.MaxRange > 0 && n.Range > policy.MaxRange {
+				out = append(out, fmt.Sprintf("the range vector %s reads %s, more than the maximum of %s; use a shorter [range]",
+					n, model.Duration(n.Range), model.Duration(policy.MaxRange)))
+			}
+		case *parser.SubqueryExpr:
+			if policy.MaxRange > 0 && n.Range > policy.MaxRange {
+				out = append(out, fmt.Sprintf("the subquery %s reads %s, more than the maximum of %s; use a shorter [range:step]",
+					n, model.Duration(n.Range), model.Duration(policy.MaxRange)))
+			}
+			if policy.MinStep > 0 && n.Step > 0 && n.Step < policy.MinStep {
+				out = append(out, fmt.Sprintf("the subquery %s has a step of %s, below the minimum of %s; use [range:%s] or larger",
+					n, model.Duration(n.Step), model.Duration(policy.MinStep), model.Duration(policy.MinStep)))
+			}
+		case *parser.VectorSelector:
+			if policy.RequireMatcher && !hasEqualityMatcher(n) {
+				out = append(out, fmt.Sprintf("the selector %s has no non-regex label matcher; add a metric name or a matcher such as job=\"api\"",
+					promql.SelectorString(n)))
+			}
+		}
+		return nil
+	})
+	return out
+}
+
+// hasEqualityMatcher reports whether vs has a label="value" matcher with a
+// non-empty value. A metric name counts as one.
+func hasEqualityMatcher(vs *parser.VectorSelector) bool {
+	for _, m := range vs.LabelMatchers {
+		if m.Type == labels.MatchEqual && m.Value != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// checkQueryPolicy applies the server's query policy to query, evaluated from
+// start to end every step (zero values for instant queries). It returns an
+// error result listing every violation, or nil when the query is allowed.
+func checkQueryPolicy(sc *server.ServerContext, query string, start, end time.Time, step time.Duration) *mcp.CallToolResult {
+	policy := sc.QueryPolicy()
+	if !policy.Enabled() {
+		return nil
+	}
+	violations := queryPolicyViolations(policy, query, start, end, step)
+	if len(violations) == 0 {
+		return nil
+	}
+	sc.Logger().Debug("Query refused by the query policy", "query", query, "violations", len(violations))
+	return &mcp.CallToolResult{
+		IsError: true,
+		Content: []mcp.Content{
+			mcp.TextContent{
+				Type: contentTypeText,
+				Text: fmt.Sprintf("Error: query refused by the server's query policy:\n- %s\n", strings.Join(violations, "\n- ")),
+			},
+		},
+	}
+}
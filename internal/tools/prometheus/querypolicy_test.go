@@ -0,0 +1,141 @@
+package prometheus
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/giantswarm/mcp-prometheus/internal/server"
+)
+
+func TestQueryPolicyViolations(t *testing.T) {
+	policy := server.QueryPolicy{MaxRange: 24 * time.Hour, MinStep: 30 * time.Second, RequireMatcher: true}
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name  string
+		query string
+		end   time.Time
+		step  time.Duration
+		want  []string
+	}{
+		{"allowed", `sum(rate(http_requests_total{job="api"}[5m]))`, start.Add(time.Hour), time.Minute, nil},
+		{"instant", "up", time.Time{}, 0, nil},
+		{"range", "up", start.Add(48 * time.Hour), time.Minute, []string{"the time range of 2d exceeds the maximum of 1d"}},
+		{"step", "up", start.Add(time.Hour), 10 * time.Second, []string{"the step of 10s is below the minimum of 30s; use step=30s or larger"}},
+		{"range vector", "rate(up[7d])", time.Time{}, 0, []string{"the range vector up[1w] reads 1w, more than the maximum of 1d"}},
+		{"subquery", "max_over_time(up[2d:10s])", time.Time{}, 0, []string{"the subquery up[2d:10s] reads 2d", "has a step of 10s, below the minimum of 30s"}},
+		{"regex only", `{job=~"api.+"} + on() {instance!="a", job=~".+"}`, time.Time{}, 0, []string{`the selector {job=~"api.+"} has no non-regex label matcher`, `the selector {instance!="a",job=~".+"}`}},
+		{"unparsable", "sum(", time.Time{}, 0, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var from time.Time
+			if !tt.end.IsZero() {
+				from = start
+			}
+			got := queryPolicyViolations(policy, tt.query, from, tt.end, tt.step)
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d violations %q, want %d", len(got), got, len(tt.want))
+			}
+			for i, want := range tt.want {
+				if !strings.Contains(got[i], want) {
+					t.Errorf("violation %d = %q, want it to contain %q", i, got[i], want)
+				}
+			}
+		})
+	}
+
+	if got := queryPolicyViolations(server.QueryPolicy{}, `{job=~".+"}[30d]`, start, start.Add(1000*time.Hour), time.Second); got != nil {
+		t.Errorf("expected no violations without a policy, got %q", got)
+	}
+}
+
+func TestQueryPolicyTools(t *testing.T) {
+	var steps []string
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/v1/query":
+			_, _ = w.Write([]byte(queryResponse))
+		case "/api/v1/series":
+			_, _ = w.Write([]byte(`{"status":"success","data":[{"__name__":"up","job":"api"}]}`))
+		case "/api/v1/query_range":
+			steps = append(steps, r.Form.Get("step"))
+			_, _ = w.Write([]byte(`{"status":"success","data":{"resultType":"matrix","result":[]}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer mockServer.Close()
+
+	sc, err := server.NewServerContext(context.Background(),
+		server.WithPrometheusConfig(server.PrometheusConfig{URL: mockServer.URL, DisableAPIVersionNegotiation: true}),
+		server.WithSlogLogger(discardLogger()),
+		server.WithQueryPolicy(server.QueryPolicy{MaxRange: 24 * time.Hour, MinStep: 5 * time.Minute, RequireMatcher: true}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = sc.Shutdown() }()
+	client, err := NewClient(sc.PrometheusConfig(), sc.Logger())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	call := func(handler PrometheusHandler, args map[string]any) (*mcp.CallToolResult, string) {
+		t.Helper()
+		result, err := handler(context.Background(), mcp.CallToolRequest{Params: mcp.CallToolParams{Arguments: args}}, client, sc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return result, result.Content[0].(mcp.TextContent).Text
+	}
+
+	if result, text := call(handleExecuteQuery, map[string]any{"query": `count({job=~".+"})`}); !result.IsError ||
+		!strings.Contains(text, "Error: query refused by the server's query policy:\n- the selector {job=~\".+\"} has no non-regex label matcher") {
+		t.Errorf("expected the matcher policy to refuse the query, got:\n%s", text)
+	}
+	if result, text := call(handleExecuteQuery, map[string]any{"query": "up"}); result.IsError {
+		t.Errorf("expected success, got:\n%s", text)
+	}
+	if result, text := call(handleEvaluateRuleTimeline, map[string]any{"rule_expr": "up == 0", "start": "2024-01-01T00:00:00Z", "end": "2024-01-03T00:00:00Z", "step": "5m"}); !result.IsError ||
+		!strings.Contains(text, "the time range of 2d exceeds the maximum of 1d") {
+		t.Errorf("expected the range policy to refuse the rule, got:\n%s", text)
+	}
+
+	// A computed step is raised to the minimum instead of being refused.
+	if result, text := call(handleExecuteRangeQuery, map[string]any{"query": "up", "start": "2024-01-01T00:00:00Z", "end": "2024-01-01T01:00:00Z"}); result.IsError {
+		t.Fatalf("expected success, got:\n%s", text)
+	}
+	if result, text := call(handleExecuteRangeQuery, map[string]any{"query": "up", "start": "2024-01-01T00:00:00Z", "end": "2024-01-01T01:00:00Z", "step": "1m"}); !result.IsError ||
+		!strings.Contains(text, "use step=5m or larger") {
+		t.Errorf("expected the step policy to refuse the query, got:\n%s", text)
+	}
+	if len(steps) != 1 || steps[0] != "300" {
+		t.Errorf("range query steps = %q, want only the raised 300", steps)
+	}
+
+	// Tools that build their queries themselves apply the policy too.
+	if result, text := call(handleGetSeriesCountHistory, map[string]any{"start": "2024-01-01T00:00:00Z", "end": "2024-01-03T00:00:00Z", "step": "1h"}); !result.IsError ||
+		!strings.Contains(text, "the time range of 2d exceeds the maximum of 1d") {
+		t.Errorf("expected the range policy to refuse the series count history, got:\n%s", text)
+	}
+	steps = nil
+	if result, text := call(handleFindSeries, map[string]any{"matches": []any{"up"}, "show_activity_range": "true", "start_time": "2024-01-01T00:00:00Z", "end_time": "2024-01-03T00:00:00Z"}); result.IsError ||
+		!strings.Contains(text, "... stopped: query refused by the server's query policy") {
+		t.Errorf("expected the range policy to stop the activity bars, got:\n%s", text)
+	}
+	if result, text := call(handleFindSeries, map[string]any{"matches": []any{"up"}, "show_activity_range": "true", "start_time": "2024-01-01T00:00:00Z", "end_time": "2024-01-01T01:00:00Z"}); result.IsError {
+		t.Errorf("expected activity bars, got:\n%s", text)
+	}
+	if len(steps) != 1 || steps[0] != "300" {
+		t.Errorf("activity query steps = %q, want only the raised 300", steps)
+	}
+}
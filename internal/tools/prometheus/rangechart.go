@@ -0,0 +1,40 @@
+package prometheus
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/prometheus/common/model"
+
+	"github.com/giantswarm/mcp-prometheus/internal/chart"
+)
+
+// maxChartSeries is the number of series a range chart draws, one per
+// palette colour so every line has its own.
+var maxChartSeries = len(chart.Palette)
+
+// rangeChart draws the first maxChartSeries series of m as a PNG line chart
+// and returns it as image content, with a legend naming each series' colour.
+func rangeChart(m model.Matrix) (mcp.ImageContent, string, error) {
+	charted := m[:min(len(m), maxChartSeries)]
+	series := make([][]model.SamplePair, len(charted))
+	for i, s := range charted {
+		series[i] = s.Values
+	}
+	data, err := chart.LinePNG(series, chart.DefaultWidth, chart.DefaultHeight)
+	if err != nil {
+		return mcp.ImageContent{}, "", err
+	}
+
+	var b strings.Builder
+	b.WriteString("Chart legend:\n")
+	for i, s := range charted {
+		fmt.Fprintf(&b, "  %s: %s\n", chart.ColorName(i), s.Metric)
+	}
+	if len(m) > len(charted) {
+		fmt.Fprintf(&b, "  ... %d more series not charted\n", len(m)-len(charted))
+	}
+	return mcp.NewImageContent(base64.StdEncoding.EncodeToString(data), "image/png"), b.String(), nil
+}
@@ -0,0 +1,91 @@
+package prometheus
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/giantswarm/mcp-prometheus/internal/server"
+)
+
+func TestHandleExecuteRangeQueryChart(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		series := []any{}
+		if r.Form.Get(paramKeyQuery) != "absent" {
+			for i := range 12 {
+				series = append(series, map[string]any{
+					"metric": map[string]string{"instance": string(rune('a' + i))},
+					"values": []any{[]any{1704067200, "1"}, []any{1704067260, "3"}},
+				})
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			respKeyStatus: respValSuccess,
+			respKeyData:   map[string]any{respKeyResultType: "matrix", respKeyResult: series},
+		})
+	}))
+	defer mockServer.Close()
+
+	ctx := context.Background()
+	sc, err := server.NewServerContext(ctx,
+		server.WithPrometheusConfig(server.PrometheusConfig{URL: mockServer.URL}),
+		server.WithSlogLogger(discardLogger()),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create server context: %v", err)
+	}
+	defer func() { _ = sc.Shutdown() }()
+	client, err := NewClient(sc.PrometheusConfig(), sc.Logger())
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+
+	call := func(args map[string]any) *mcp.CallToolResult {
+		t.Helper()
+		args["start"], args["end"], args["step"] = "1704067200", "1704067260", "1m"
+		request := mcp.CallToolRequest{Params: mcp.CallToolParams{Name: toolExecuteRangeQuery, Arguments: args}}
+		result, err := handleExecuteRangeQuery(ctx, request, client, sc)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return result
+	}
+
+	result := call(map[string]any{paramKeyQuery: "up", "chart": "true"})
+	if result.IsError || len(result.Content) != 2 {
+		t.Fatalf("expected text and image content, got %v", result.Content)
+	}
+	text := result.Content[0].(mcp.TextContent).Text
+	for _, want := range []string{"Chart legend:\n  blue: {instance=\"a\"}\n", "cyan: {instance=\"j\"}", "... 2 more series not charted"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("text missing %q:\n%s", want, text)
+		}
+	}
+	img := result.Content[1].(mcp.ImageContent)
+	data, err := base64.StdEncoding.DecodeString(img.Data)
+	if err != nil || img.MIMEType != "image/png" {
+		t.Fatalf("image content: %v, MIME type %q", err, img.MIMEType)
+	}
+	if _, err := png.Decode(bytes.NewReader(data)); err != nil {
+		t.Errorf("image is not a PNG: %v", err)
+	}
+
+	result = call(map[string]any{paramKeyQuery: "absent", "chart": "true"})
+	if len(result.Content) != 1 || !strings.Contains(result.Content[0].(mcp.TextContent).Text, "Chart unavailable") {
+		t.Errorf("expected a note instead of a chart for an empty result, got %v", result.Content)
+	}
+
+	if result := call(map[string]any{paramKeyQuery: "up", "chart": "true", "format": "csv"}); !result.IsError {
+		t.Error("expected an error for chart with format csv")
+	}
+}
@@ -0,0 +1,335 @@
+package prometheus
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/prometheus/common/model"
+
+	"github.com/giantswarm/mcp-prometheus/internal/analysis"
+	"github.com/giantswarm/mcp-prometheus/internal/server"
+)
+
+const (
+	// toolSummarizeRangeQuery is the registered name of the range query
+	// summary tool.
+	toolSummarizeRangeQuery = "summarize_range_query"
+
+	// defaultSummaryTopN is the number of series summarize_range_query lists
+	// when top_n is not given.
+	defaultSummaryTopN = 10
+)
+
+// summaryQuantiles are the quantiles estimated for classic histograms.
+var summaryQuantiles = []float64{0.5, 0.95, 0.99}
+
+// seriesSummary holds the statistics of one series over a range.
+type seriesSummary struct {
+	metric           model.Metric
+	first, current   float64
+	peak, trough     model.SamplePair
+	counter          bool
+	ratePerSecond    float64
+	hasRatePerSecond bool
+}
+
+// isCounterName reports whether a metric name follows the counter naming
+// conventions, including the _count and _sum series of histograms and
+// summaries.
+func isCounterName(name string) bool {
+	return strings.HasSuffix(name, "_total") || strings.HasSuffix(name, "_count") || strings.HasSuffix(name, "_sum")
+}
+
+// summarizeSeries computes the statistics of a series. ok is false when the
+// series has no float samples.
+func summarizeSeries(s *model.SampleStream) (summary seriesSummary, ok bool) {
+	var firstAt, lastAt model.Time
+	for _, p := range s.Values {
+		v := float64(p.Value)
+		if math.IsNaN(v) {
+			continue
+		}
+		if !ok {
+			ok = true
+			summary.first, firstAt = v, p.Timestamp
+			summary.peak, summary.trough = p, p
+		}
+		summary.current, lastAt = v, p.Timestamp
+		if p.Value > summary.peak.Value {
+			summary.peak = p
+		}
+		if p.Value < summary.trough.Value {
+			summary.trough = p
+		}
+	}
+	if !ok {
+		return summary, false
+	}
+
+	summary.metric = s.Metric
+	summary.counter = isCounterName(string(s.Metric[model.MetricNameLabel]))
+	if seconds := lastAt.Sub(firstAt).Seconds(); summary.counter && seconds > 0 {
+		summary.ratePerSecond = analysis.Increase(s.Values) / seconds
+		summary.hasRatePerSecond = true
+	}
+	return summary, true
+}
+
+// histogramSummary holds the quantiles of one classic histogram estimated
+// from the increase of its buckets over a range.
+type histogramSummary struct {
+	metric       model.Metric
+	observations float64
+	quantiles    []float64
+}
+
+// summarizeHistograms groups the _bucket series of m by their labels
+// without le and estimates summaryQuantiles for each group. The remaining
+// series are returned unchanged.
+func summarizeHistograms(m model.Matrix) (histograms []histogramSummary, rest model.Matrix) {
+	groups := make(map[model.Fingerprint]*histogramSummary)
+	buckets := make(map[model.Fingerprint][]analysis.Bucket)
+	for _, s := range m {
+		name, isBucket := strings.CutSuffix(string(s.Metric[model.MetricNameLabel]), "_bucket")
+		le, hasLe := s.Metric[model.BucketLabel]
+		if !isBucket || !hasLe {
+			rest = append(rest, s)
+			continue
+		}
+		upperBound, err := strconv.ParseFloat(string(le), 64)
+		if err != nil {
+			rest = append(rest, s)
+			continue
+		}
+
+		metric := s.Metric.Clone()
+		delete(metric, model.BucketLabel)
+		metric[model.MetricNameLabel] = model.LabelValue(name)
+		key := metric.Fingerprint()
+		if _, ok := groups[key]; !ok {
+			groups[key] = &histogramSummary{metric: metric}
+		}
+		buckets[key] = append(buckets[key], analysis.Bucket{UpperBound: upperBound, Count: analysis.Increase(s.Values)})
+	}
+
+	for key, h := range groups {
+		for _, b := range buckets[key] {
+			if math.IsInf(b.UpperBound, 1) {
+				h.observations = b.Count
+			}
+		}
+		for _, q := range summaryQuantiles {
+			h.quantiles = append(h.quantiles, analysis.BucketQuantile(q, buckets[key]))
+		}
+		histograms = append(histograms, *h)
+	}
+	slices.SortFunc(histograms, func(a, b histogramSummary) int {
+		return cmp.Or(cmp.Compare(b.observations, a.observations), strings.Compare(a.metric.String(), b.metric.String()))
+	})
+	return histograms, rest
+}
+
+// formatSummaryValue renders a value with up to six significant digits.
+func formatSummaryValue(v float64) string {
+	if math.IsNaN(v) {
+		return "—"
+	}
+	return strconv.FormatFloat(v, 'g', 6, 64)
+}
+
+// markdownCell escapes the pipes of a Markdown table cell.
+func markdownCell(s string) string {
+	return strings.ReplaceAll(s, "|", `\|`)
+}
+
+// formatRangeSummary renders the series and histogram summaries as Markdown
+// tables, keeping the topN series with the highest current value and the
+// topN histograms with the most observations.
+func formatRangeSummary(series []seriesSummary, histograms []histogramSummary, topN int) string {
+	var b strings.Builder
+
+	if len(series) > 0 {
+		slices.SortFunc(series, func(a, b seriesSummary) int {
+			return cmp.Or(cmp.Compare(b.current, a.current), strings.Compare(a.metric.String(), b.metric.String()))
+		})
+		fmt.Fprintf(&b, "### Series (top %d of %d by current value)\n\n", min(topN, len(series)), len(series))
+		b.WriteString("| series | current | delta | change | rate/s | peak | trough |\n")
+		b.WriteString("|---|---|---|---|---|---|---|\n")
+		for _, s := range series[:min(topN, len(series))] {
+			change := "n/a"
+			if s.first != 0 {
+				change = fmt.Sprintf("%+.1f%%", (s.current-s.first)/math.Abs(s.first)*100)
+			}
+			rate := "—"
+			if s.hasRatePerSecond {
+				rate = formatSummaryValue(s.ratePerSecond)
+			}
+			fmt.Fprintf(&b, "| %s | %s | %s | %s | %s | %s @ %s | %s @ %s |\n",
+				markdownCell(s.metric.String()), formatSummaryValue(s.current), formatSummaryValue(s.current-s.first), change, rate,
+				formatSummaryValue(float64(s.peak.Value)), formatResultTime(s.peak.Timestamp),
+				formatSummaryValue(float64(s.trough.Value)), formatResultTime(s.trough.Timestamp))
+		}
+	}
+
+	if len(histograms) > 0 {
+		if b.Len() > 0 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "### Histograms (top %d of %d by observations in range)\n\n", min(topN, len(histograms)), len(histograms))
+		b.WriteString("Quantiles are estimated from the bucket increases over the range, like histogram_quantile().\n\n")
+		b.WriteString("| histogram | observations | p50 | p95 | p99 |\n")
+		b.WriteString("|---|---|---|---|---|\n")
+		for _, h := range histograms[:min(topN, len(histograms))] {
+			fmt.Fprintf(&b, "| %s | %s | %s | %s | %s |\n", markdownCell(h.metric.String()), formatSummaryValue(h.observations),
+				formatSummaryValue(h.quantiles[0]), formatSummaryValue(h.quantiles[1]), formatSummaryValue(h.quantiles[2]))
+		}
+	}
+	return b.String()
+}
+
+// handleSummarizeRangeQuery handles the summarize_range_query tool. It runs
+// a single range query and summarizes the resulting matrix per series.
+func handleSummarizeRangeQuery(ctx context.Context, request mcp.CallToolRequest, client *Client, sc *server.ServerContext) (*mcp.CallToolResult, error) {
+	params := extractParams(request)
+
+	for _, key := range []string{"query", "start", "end", "step"} {
+		if getStringParam(params, key) == "" {
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{
+					mcp.TextContent{
+						Type: contentTypeText,
+						Text: fmt.Sprintf("Error: %s parameter is required and must be a string", key),
+					},
+				},
+			}, nil
+		}
+	}
+
+	query := getStringParam(params, "query")
+	start := getStringParam(params, "start")
+	end := getStringParam(params, "end")
+	step := getStringParam(params, "step")
+
+	startTime, startErr := parseTimeParam(start)
+	endTime, endErr := parseTimeParam(end)
+	stepDuration, stepErr := model.ParseDuration(step)
+	if err := errors.Join(startErr, endErr, stepErr); err != nil {
+		return &mcp.CallToolResult{
+			IsError: true,
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: contentTypeText,
+					Text: fmt.Sprintf("Error: %v", err),
+				},
+			},
+		}, nil
+	}
+	if stepDuration <= 0 || !endTime.After(startTime) {
+		return &mcp.CallToolResult{
+			IsError: true,
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: contentTypeText,
+					Text: "Error: step must be positive and end must be after start",
+				},
+			},
+		}, nil
+	}
+
+	if refused := checkQueryPolicy(sc, query, startTime, endTime, time.Duration(stepDuration)); refused != nil {
+		return refused, nil
+	}
+	steps := int(endTime.Sub(startTime)/time.Duration(stepDuration)) + 1
+	costWarning, refused := checkQueryCost(ctx, client, sc, query, startTime, endTime, steps)
+	if refused != nil {
+		return refused, nil
+	}
+
+	topN := defaultSummaryTopN
+	if v := getStringParam(params, "top_n"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{
+					mcp.TextContent{
+						Type: contentTypeText,
+						Text: fmt.Sprintf("Error: top_n must be a positive integer (got %q)", v),
+					},
+				},
+			}, nil
+		}
+		topN = n
+	}
+
+	sc.Logger().Debug("Summarizing range query", "query", query, "start", start, "end", end, "step", step, "top_n", topN)
+
+	result, err := client.ExecuteRangeQuery(ctx, query, start, end, step)
+	if err != nil {
+		sc.Logger().Error("Failed to execute range query", "error", err)
+		return &mcp.CallToolResult{
+			IsError: true,
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: contentTypeText,
+					Text: describeQueryError("Error executing range query", err),
+				},
+			},
+		}, nil
+	}
+	matrix, ok := result.Result.(model.Matrix)
+	if !ok {
+		return &mcp.CallToolResult{
+			IsError: true,
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: contentTypeText,
+					Text: fmt.Sprintf("Error: expected a matrix result, got %s", result.ResultType),
+				},
+			},
+		}, nil
+	}
+
+	histograms, rest := summarizeHistograms(matrix)
+	var series []seriesSummary
+	skipped := 0
+	for _, s := range rest {
+		if summary, ok := summarizeSeries(s); ok {
+			series = append(series, summary)
+		} else {
+			skipped++
+		}
+	}
+
+	var b strings.Builder
+	if costWarning != "" {
+		b.WriteString(costWarning + "\n")
+	}
+	fmt.Fprintf(&b, "## Summary of `%s`\n\n", query)
+	fmt.Fprintf(&b, "%d series from %s to %s, step %s.\n\n", len(matrix),
+		formatResultTime(model.TimeFromUnixNano(startTime.UnixNano())), formatResultTime(model.TimeFromUnixNano(endTime.UnixNano())), time.Duration(stepDuration))
+	if len(series) > 0 || len(histograms) > 0 {
+		b.WriteString(formatRangeSummary(series, histograms, topN))
+	}
+	if skipped > 0 {
+		fmt.Fprintf(&b, "\n%d series without float samples (such as native histograms) are not summarized.\n", skipped)
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.TextContent{
+				Type: contentTypeText,
+				Text: b.String(),
+			},
+		},
+	}, nil
+}
@@ -0,0 +1,149 @@
+package prometheus
+
+import (
+	"context"
+	"encoding/json"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/giantswarm/mcp-prometheus/internal/server"
+)
+
+// summarySeries builds one matrix series with one sample per hour starting
+// at seriesHistoryStart.
+func summarySeries(metric map[string]string, values ...string) map[string]any {
+	var pairs []any
+	for i, v := range values {
+		pairs = append(pairs, []any{seriesHistoryStart + i*3600, v})
+	}
+	return map[string]any{"metric": metric, "values": pairs}
+}
+
+func runSummarizeRangeQuery(t *testing.T, args map[string]any) *mcp.CallToolResult {
+	t.Helper()
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		result := []any{
+			summarySeries(map[string]string{"__name__": "http_requests_total", "job": "api"}, "0", "3600", "7200", "10800"),
+			summarySeries(map[string]string{"__name__": "restarts_total", "job": "api"}, "100", "3700", "0", "3600"),
+			summarySeries(map[string]string{"__name__": "queue_depth", "job": "api"}, "50", "80", "20", "40"),
+			summarySeries(map[string]string{"__name__": "latency_seconds_bucket", "le": "0.1"}, "0", "10", "30", "50"),
+			summarySeries(map[string]string{"__name__": "latency_seconds_bucket", "le": "0.5"}, "0", "20", "60", "90"),
+			summarySeries(map[string]string{"__name__": "latency_seconds_bucket", "le": "1"}, "0", "30", "70", "100"),
+			summarySeries(map[string]string{"__name__": "latency_seconds_bucket", "le": "+Inf"}, "0", "30", "70", "100"),
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			respKeyStatus: respValSuccess,
+			respKeyData:   map[string]any{respKeyResultType: "matrix", respKeyResult: result},
+		})
+	}))
+	defer mockServer.Close()
+
+	ctx := context.Background()
+	sc, err := server.NewServerContext(ctx,
+		server.WithPrometheusConfig(server.PrometheusConfig{URL: mockServer.URL}),
+		server.WithSlogLogger(discardLogger()),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create server context: %v", err)
+	}
+	defer func() { _ = sc.Shutdown() }()
+
+	client, err := NewClient(sc.PrometheusConfig(), sc.Logger())
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+
+	request := mcp.CallToolRequest{Params: mcp.CallToolParams{Name: toolSummarizeRangeQuery, Arguments: args}}
+	result, err := handleSummarizeRangeQuery(ctx, request, client, sc)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return result
+}
+
+func TestHandleSummarizeRangeQuery(t *testing.T) {
+	result := runSummarizeRangeQuery(t, map[string]any{
+		"query": `{job="api"}`,
+		"start": "2024-01-15T00:00:00Z",
+		"end":   "2024-01-15T03:00:00Z",
+		"step":  "1h",
+	})
+	if result.IsError {
+		t.Fatalf("expected success, got error: %v", result.Content)
+	}
+	text := result.Content[0].(mcp.TextContent).Text
+
+	for _, want := range []string{
+		"7 series from 2024-01-15T00:00:00.000Z to 2024-01-15T03:00:00.000Z, step 1h0m0s.\n",
+		"### Series (top 3 of 3 by current value)\n",
+		// Counter: 10800 over 3h is 1/s.
+		`| http_requests_total{job="api"} | 10800 | 10800 | n/a | 1 | 10800 @ 2024-01-15T03:00:00.000Z | 0 @ 2024-01-15T00:00:00.000Z |`,
+		// Counter reset: 3600 + 0 + 3600 over 3h.
+		`| restarts_total{job="api"} | 3600 | 3500 | +3500.0% | 0.666667 | 3700 @ 2024-01-15T01:00:00.000Z | 0 @ 2024-01-15T02:00:00.000Z |`,
+		// Gauge: no rate.
+		`| queue_depth{job="api"} | 40 | -10 | -20.0% | — | 80 @ 2024-01-15T01:00:00.000Z | 20 @ 2024-01-15T02:00:00.000Z |`,
+		"### Histograms (top 1 of 1 by observations in range)\n",
+		"| latency_seconds | 100 | 0.1 | 0.75 | 0.95 |",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("output missing %q:\n%s", want, text)
+		}
+	}
+	if strings.Index(text, "http_requests_total") > strings.Index(text, "restarts_total") {
+		t.Errorf("expected series ordered by current value:\n%s", text)
+	}
+}
+
+func TestHandleSummarizeRangeQueryTopN(t *testing.T) {
+	result := runSummarizeRangeQuery(t, map[string]any{
+		"query": `{job="api"}`,
+		"start": "2024-01-15T00:00:00Z",
+		"end":   "2024-01-15T03:00:00Z",
+		"step":  "1h",
+		"top_n": "1",
+	})
+	if result.IsError {
+		t.Fatalf("expected success, got error: %v", result.Content)
+	}
+	text := result.Content[0].(mcp.TextContent).Text
+	if !strings.Contains(text, "top 1 of 3") || strings.Contains(text, "restarts_total") || strings.Contains(text, "queue_depth") {
+		t.Errorf("expected only the highest series:\n%s", text)
+	}
+}
+
+func TestHandleSummarizeRangeQueryInvalidParams(t *testing.T) {
+	valid := map[string]any{
+		"query": "up",
+		"start": "2024-01-15T00:00:00Z",
+		"end":   "2024-01-15T03:00:00Z",
+		"step":  "1h",
+	}
+	tests := []struct {
+		name     string
+		override map[string]any
+		want     string
+	}{
+		{name: "missing step", override: map[string]any{"step": ""}, want: "step parameter is required"},
+		{name: "end before start", override: map[string]any{"end": "2024-01-14T00:00:00Z"}, want: "end must be after start"},
+		{name: "invalid top_n", override: map[string]any{"top_n": "0"}, want: "top_n must be a positive integer"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := maps.Clone(valid)
+			maps.Copy(args, tt.override)
+			result := runSummarizeRangeQuery(t, args)
+			if !result.IsError {
+				t.Fatal("expected an error result")
+			}
+			if text := result.Content[0].(mcp.TextContent).Text; !strings.Contains(text, tt.want) {
+				t.Errorf("error %q does not contain %q", text, tt.want)
+			}
+		})
+	}
+}
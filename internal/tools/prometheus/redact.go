@@ -0,0 +1,88 @@
+package prometheus
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// redactedValue replaces the value of any configuration key that looks like
+// it holds a credential.
+const redactedValue = "<REDACTED>"
+
+// sensitiveKeySuffixes lists the key suffixes treated as credentials by
+// redactYAML. Matching is case-insensitive. Keys such as password_file or
+// credentials_file are intentionally not matched: they hold file paths, not
+// secrets.
+var sensitiveKeySuffixes = []string{
+	"password",
+	"token",
+	"bearer_token",
+	"secret",
+	"credentials",
+}
+
+// isSensitiveKey reports whether a configuration key holds a credential.
+func isSensitiveKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, suffix := range sensitiveKeySuffixes {
+		if strings.HasSuffix(key, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactYAML walks a YAML document decoded into generic maps and slices and
+// returns a copy in which the values of all sensitive keys (see
+// isSensitiveKey) are replaced with redactedValue. Non-scalar values under a
+// sensitive key are replaced wholesale.
+func redactYAML(node interface{}) interface{} {
+	switch n := node.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(n))
+		for k, v := range n {
+			if isSensitiveKey(k) && v != nil {
+				out[k] = redactedValue
+				continue
+			}
+			out[k] = redactYAML(v)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(n))
+		for i, v := range n {
+			out[i] = redactYAML(v)
+		}
+		return out
+	default:
+		return node
+	}
+}
+
+// formatConfigYAML parses a Prometheus configuration document, redacts
+// credentials unless raw is set, and re-marshals it as indented YAML.
+func formatConfigYAML(config string, raw bool) (string, error) {
+	var doc map[string]interface{}
+	if err := yaml.Unmarshal([]byte(config), &doc); err != nil {
+		return "", fmt.Errorf("parse configuration YAML: %w", err)
+	}
+
+	var node interface{} = doc
+	if !raw {
+		node = redactYAML(doc)
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(node); err != nil {
+		return "", fmt.Errorf("marshal configuration YAML: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return "", fmt.Errorf("marshal configuration YAML: %w", err)
+	}
+	return buf.String(), nil
+}
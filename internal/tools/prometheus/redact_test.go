@@ -0,0 +1,124 @@
+package prometheus
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"gopkg.in/yaml.v3"
+
+	"github.com/giantswarm/mcp-prometheus/internal/server"
+)
+
+const sampleConfigYAML = `global:
+  scrape_interval: 15s
+scrape_configs:
+  - job_name: node
+    basic_auth:
+      username: admin
+      password: hunter2
+      password_file: /etc/secrets/pass
+  - job_name: api
+    authorization:
+      credentials: s3cr3t
+    bearer_token: abc123
+`
+
+func TestRedactYAML(t *testing.T) {
+	var doc map[string]interface{}
+	if err := yaml.Unmarshal([]byte(sampleConfigYAML), &doc); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+
+	redacted := redactYAML(doc).(map[string]interface{})
+	scrapeConfigs := redacted["scrape_configs"].([]interface{})
+
+	basicAuth := scrapeConfigs[0].(map[string]interface{})["basic_auth"].(map[string]interface{})
+	if got := basicAuth["password"]; got != redactedValue {
+		t.Errorf("basic_auth.password = %v, want %q", got, redactedValue)
+	}
+	if got := basicAuth["username"]; got != "admin" {
+		t.Errorf("basic_auth.username = %v, want admin", got)
+	}
+	if got := basicAuth["password_file"]; got != "/etc/secrets/pass" {
+		t.Errorf("basic_auth.password_file = %v, want path preserved", got)
+	}
+
+	api := scrapeConfigs[1].(map[string]interface{})
+	if got := api["bearer_token"]; got != redactedValue {
+		t.Errorf("bearer_token = %v, want %q", got, redactedValue)
+	}
+	if got := api["authorization"].(map[string]interface{})["credentials"]; got != redactedValue {
+		t.Errorf("authorization.credentials = %v, want %q", got, redactedValue)
+	}
+
+	// The input must not be modified.
+	original := doc["scrape_configs"].([]interface{})[0].(map[string]interface{})["basic_auth"].(map[string]interface{})
+	if original["password"] != "hunter2" {
+		t.Error("redactYAML modified its input")
+	}
+}
+
+func TestFormatConfigYAML(t *testing.T) {
+	redacted, err := formatConfigYAML(sampleConfigYAML, false)
+	if err != nil {
+		t.Fatalf("formatConfigYAML: %v", err)
+	}
+	if strings.Contains(redacted, "hunter2") || !strings.Contains(redacted, redactedValue) {
+		t.Errorf("expected password to be redacted, got:\n%s", redacted)
+	}
+
+	raw, err := formatConfigYAML(sampleConfigYAML, true)
+	if err != nil {
+		t.Fatalf("formatConfigYAML: %v", err)
+	}
+	if !strings.Contains(raw, "hunter2") {
+		t.Errorf("expected raw output to keep the password, got:\n%s", raw)
+	}
+
+	if _, err := formatConfigYAML("global: [", false); err == nil {
+		t.Error("expected error for invalid YAML")
+	}
+}
+
+func TestHandleGetConfigRaw(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{respKeyStatus: respValSuccess, respKeyData: map[string]any{"yaml": sampleConfigYAML}})
+	}))
+	defer mockServer.Close()
+
+	ctx := context.Background()
+	for _, allowed := range []bool{false, true} {
+		sc, err := server.NewServerContext(ctx,
+			server.WithPrometheusConfig(server.PrometheusConfig{URL: mockServer.URL}),
+			server.WithRawConfig(allowed),
+			server.WithSlogLogger(discardLogger()),
+		)
+		if err != nil {
+			t.Fatalf("Failed to create server context: %v", err)
+		}
+		client, err := NewClient(sc.PrometheusConfig(), sc.Logger())
+		if err != nil {
+			t.Fatalf("NewClient: %v", err)
+		}
+
+		request := mcp.CallToolRequest{Params: mcp.CallToolParams{Name: "get_config", Arguments: map[string]any{"raw": "true"}}}
+		result, err := handleGetConfig(ctx, request, client, sc)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		text := result.Content[0].(mcp.TextContent).Text
+		switch {
+		case allowed && (result.IsError || !strings.Contains(text, "hunter2")):
+			t.Errorf("with raw config allowed, expected the unredacted config, got %s", text)
+		case !allowed && (!result.IsError || strings.Contains(text, "hunter2") || !strings.Contains(text, "--allow-raw-config")):
+			t.Errorf("with raw config disallowed, expected an error, got %s", text)
+		}
+		_ = sc.Shutdown()
+	}
+}
@@ -0,0 +1,82 @@
+package prometheus
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/giantswarm/mcp-prometheus/internal/server"
+)
+
+// toolReloadConfig is the registered name of the configuration reload tool.
+const toolReloadConfig = "reload_config"
+
+// configHash returns a short SHA-256 digest of a configuration as reported by
+// /api/v1/status/config, so two versions can be compared at a glance.
+func configHash(yaml string) string {
+	sum := sha256.Sum256([]byte(yaml))
+	return hex.EncodeToString(sum[:6])
+}
+
+// handleReloadConfig handles the reload_config tool. It reads the
+// configuration before and after POST /-/reload and reports whether its hash
+// changed.
+func handleReloadConfig(ctx context.Context, request mcp.CallToolRequest, client *Client, sc *server.ServerContext) (*mcp.CallToolResult, error) {
+	sc.Logger().Info("Reloading Prometheus configuration")
+
+	before := "(unknown)"
+	if config, err := client.GetConfig(ctx); err != nil {
+		sc.Logger().Warn("Failed to read configuration before reload", "error", err)
+	} else {
+		before = configHash(config.YAML)
+	}
+
+	if err := client.Reload(ctx); err != nil {
+		sc.Logger().Error("Failed to reload configuration", "error", err)
+		return &mcp.CallToolResult{
+			IsError: true,
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: contentTypeText,
+					Text: fmt.Sprintf("Error reloading configuration: %v", err),
+				},
+			},
+		}, nil
+	}
+
+	config, err := client.GetConfig(ctx)
+	if err != nil {
+		sc.Logger().Error("Failed to read configuration after reload", "error", err)
+		return &mcp.CallToolResult{
+			IsError: true,
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: contentTypeText,
+					Text: fmt.Sprintf("Configuration reloaded, but reading it back failed: %v", err),
+				},
+			},
+		}, nil
+	}
+	after := configHash(config.YAML)
+
+	text := fmt.Sprintf("Configuration reloaded. Config hash %s -> %s: ", before, after)
+	switch {
+	case before == after:
+		text += "unchanged, the running configuration already matched the file."
+	case before == "(unknown)":
+		text += "the previous configuration could not be read to compare."
+	default:
+		text += "the new configuration is active."
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.TextContent{
+				Type: contentTypeText,
+				Text: text,
+			},
+		},
+	}, nil
+}
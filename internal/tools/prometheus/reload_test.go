@@ -0,0 +1,82 @@
+package prometheus
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/giantswarm/mcp-prometheus/internal/server"
+)
+
+func TestHandleReloadConfig(t *testing.T) {
+	running, onDisk := "global:\n  scrape_interval: 15s\n", "global:\n  scrape_interval: 30s\n"
+	lifecycle := true
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/status/config":
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(map[string]any{respKeyStatus: respValSuccess, respKeyData: map[string]any{"yaml": running}})
+		case "/-/reload":
+			if r.Method != http.MethodPost {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+				return
+			}
+			if !lifecycle {
+				w.WriteHeader(http.StatusForbidden)
+				_, _ = w.Write([]byte("Lifecycle API is not enabled."))
+				return
+			}
+			running = onDisk
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer mockServer.Close()
+
+	ctx := context.Background()
+	sc, err := server.NewServerContext(ctx,
+		server.WithPrometheusConfig(server.PrometheusConfig{URL: mockServer.URL}),
+		server.WithSlogLogger(discardLogger()),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create server context: %v", err)
+	}
+	defer func() { _ = sc.Shutdown() }()
+
+	client, err := NewClient(sc.PrometheusConfig(), sc.Logger())
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+
+	call := func() *mcp.CallToolResult {
+		t.Helper()
+		request := mcp.CallToolRequest{Params: mcp.CallToolParams{Name: toolReloadConfig}}
+		result, err := handleReloadConfig(ctx, request, client, sc)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return result
+	}
+
+	before, after := configHash(running), configHash(onDisk)
+	result := call()
+	if text := result.Content[0].(mcp.TextContent).Text; result.IsError || !strings.Contains(text, before+" -> "+after+": the new configuration is active") {
+		t.Errorf("unexpected result for a changed configuration: %s", text)
+	}
+
+	result = call()
+	if text := result.Content[0].(mcp.TextContent).Text; result.IsError || !strings.Contains(text, after+" -> "+after+": unchanged") {
+		t.Errorf("unexpected result for an unchanged configuration: %s", text)
+	}
+
+	lifecycle = false
+	result = call()
+	if text := result.Content[0].(mcp.TextContent).Text; !result.IsError || !strings.Contains(text, "--web.enable-lifecycle") {
+		t.Errorf("expected a lifecycle API error, got %s", text)
+	}
+}
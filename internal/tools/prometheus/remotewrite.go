@@ -0,0 +1,192 @@
+package prometheus
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang/snappy"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/prompb"
+
+	"github.com/giantswarm/mcp-prometheus/internal/server"
+)
+
+// toolPushMetric is the registered name of the remote write tool.
+const toolPushMetric = "push_metric"
+
+// Remote write 1.0 request headers.
+const (
+	remoteWriteContentType = "application/x-protobuf"
+	remoteWriteEncoding    = "snappy"
+	remoteWriteVersion     = "0.1.0"
+)
+
+// buildWriteRequest returns a remote write request carrying a single sample.
+// Labels are sorted by name with __name__ set to name, as receivers require.
+func buildWriteRequest(name string, labels map[string]string, value float64, ts time.Time) (*prompb.WriteRequest, error) {
+	if !model.IsValidMetricName(model.LabelValue(name)) {
+		return nil, fmt.Errorf("invalid metric name %q", name)
+	}
+
+	all := map[string]string{model.MetricNameLabel: name}
+	for k, v := range labels {
+		if k == model.MetricNameLabel {
+			return nil, fmt.Errorf("label %q is reserved; use metric_name instead", k)
+		}
+		if !model.LabelName(k).IsValid() {
+			return nil, fmt.Errorf("invalid label name %q", k)
+		}
+		all[k] = v
+	}
+
+	series := prompb.TimeSeries{
+		Samples: []prompb.Sample{{Value: value, Timestamp: ts.UnixMilli()}},
+	}
+	for _, k := range slices.Sorted(maps.Keys(all)) {
+		series.Labels = append(series.Labels, prompb.Label{Name: k, Value: all[k]})
+	}
+	return &prompb.WriteRequest{Timeseries: []prompb.TimeSeries{series}}, nil
+}
+
+// encodeWriteRequest serialises req as a snappy-compressed protobuf body.
+func encodeWriteRequest(req *prompb.WriteRequest) ([]byte, error) {
+	data, err := req.Marshal()
+	if err != nil {
+		return nil, fmt.Errorf("marshal write request: %w", err)
+	}
+	return snappy.Encode(nil, data), nil
+}
+
+// handlePushMetric handles the push_metric tool
+func handlePushMetric(ctx context.Context, request mcp.CallToolRequest, client *Client, sc *server.ServerContext) (*mcp.CallToolResult, error) {
+	params := extractParams(request)
+
+	if getStringParam(params, "confirm") != "true" {
+		return &mcp.CallToolResult{
+			IsError: true,
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: contentTypeText,
+					Text: "Error: push_metric writes data into Prometheus; set confirm to 'true' to proceed",
+				},
+			},
+		}, nil
+	}
+
+	name := getStringParam(params, "metric_name")
+	if name == "" {
+		return &mcp.CallToolResult{
+			IsError: true,
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: contentTypeText,
+					Text: "Error: metric_name parameter is required",
+				},
+			},
+		}, nil
+	}
+
+	value, err := strconv.ParseFloat(getStringParam(params, "value"), 64)
+	if err != nil {
+		return &mcp.CallToolResult{
+			IsError: true,
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: contentTypeText,
+					Text: fmt.Sprintf("Error: value must be a number (got %q)", getStringParam(params, "value")),
+				},
+			},
+		}, nil
+	}
+
+	labels := map[string]string{}
+	if raw, ok := params["labels"].(map[string]any); ok {
+		for k, v := range raw {
+			s, ok := v.(string)
+			if !ok {
+				return &mcp.CallToolResult{
+					IsError: true,
+					Content: []mcp.Content{
+						mcp.TextContent{
+							Type: contentTypeText,
+							Text: fmt.Sprintf("Error: label %q must have a string value", k),
+						},
+					},
+				}, nil
+			}
+			labels[k] = s
+		}
+	}
+
+	ts := time.Now()
+	if v := getStringParam(params, "timestamp"); v != "" {
+		if ts, err = parseTimeParam(v); err != nil {
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{
+					mcp.TextContent{
+						Type: contentTypeText,
+						Text: fmt.Sprintf("Error: %v", err),
+					},
+				},
+			}, nil
+		}
+	}
+
+	remoteWriteURL := getStringParam(params, "remote_write_url")
+	if remoteWriteURL != "" {
+		if err := validateTargetURL("remote_write_url", remoteWriteURL); err != nil {
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{
+					mcp.TextContent{
+						Type: contentTypeText,
+						Text: fmt.Sprintf("Error: %v", err),
+					},
+				},
+			}, nil
+		}
+	}
+
+	sc.Logger().Debug("Pushing metric", "metric", name, "labels", labels, "value", value, "timestamp", ts)
+
+	if err := client.PushMetric(ctx, remoteWriteURL, name, labels, value, ts); err != nil {
+		return &mcp.CallToolResult{
+			IsError: true,
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: contentTypeText,
+					Text: fmt.Sprintf("Error pushing metric: %v", err),
+				},
+			},
+		}, nil
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.TextContent{
+				Type: contentTypeText,
+				Text: fmt.Sprintf("Pushed %s = %s at %s",
+					formatSeriesLabels(name, labels), strconv.FormatFloat(value, 'g', -1, 64), ts.UTC().Format(time.RFC3339Nano)),
+			},
+		},
+	}, nil
+}
+
+// formatSeriesLabels renders name and labels in PromQL selector notation.
+func formatSeriesLabels(name string, labels map[string]string) string {
+	if len(labels) == 0 {
+		return name
+	}
+	pairs := make([]string, 0, len(labels))
+	for _, k := range slices.Sorted(maps.Keys(labels)) {
+		pairs = append(pairs, fmt.Sprintf("%s=%q", k, labels[k]))
+	}
+	return name + "{" + strings.Join(pairs, ", ") + "}"
+}
@@ -0,0 +1,217 @@
+package prometheus
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/snappy"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/prometheus/prometheus/prompb"
+
+	"github.com/giantswarm/mcp-prometheus/internal/server"
+)
+
+func TestBuildWriteRequest(t *testing.T) {
+	ts := time.Date(2026, 1, 2, 3, 4, 5, 678_000_000, time.UTC)
+	req, err := buildWriteRequest("test_metric", map[string]string{"job": "synthetic", "env": "dev"}, 42.5, ts)
+	if err != nil {
+		t.Fatalf("buildWriteRequest: %v", err)
+	}
+
+	body, err := encodeWriteRequest(req)
+	if err != nil {
+		t.Fatalf("encodeWriteRequest: %v", err)
+	}
+	data, err := snappy.Decode(nil, body)
+	if err != nil {
+		t.Fatalf("snappy decode: %v", err)
+	}
+	var decoded prompb.WriteRequest
+	if err := decoded.Unmarshal(data); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+
+	if len(decoded.Timeseries) != 1 {
+		t.Fatalf("expected 1 series, got %d", len(decoded.Timeseries))
+	}
+	series := decoded.Timeseries[0]
+
+	wantLabels := []prompb.Label{
+		{Name: "__name__", Value: "test_metric"},
+		{Name: "env", Value: "dev"},
+		{Name: "job", Value: "synthetic"},
+	}
+	if len(series.Labels) != len(wantLabels) {
+		t.Fatalf("labels = %v, want %v", series.Labels, wantLabels)
+	}
+	for i, want := range wantLabels {
+		if series.Labels[i].Name != want.Name || series.Labels[i].Value != want.Value {
+			t.Errorf("label %d = %v, want %v", i, series.Labels[i], want)
+		}
+	}
+
+	if len(series.Samples) != 1 {
+		t.Fatalf("expected 1 sample, got %d", len(series.Samples))
+	}
+	if got := series.Samples[0]; got.Value != 42.5 || got.Timestamp != ts.UnixMilli() {
+		t.Errorf("sample = %+v, want value 42.5 at %d", got, ts.UnixMilli())
+	}
+}
+
+func TestBuildWriteRequestInvalid(t *testing.T) {
+	tests := map[string]struct {
+		name   string
+		labels map[string]string
+	}{
+		"empty metric name":  {name: ""},
+		"reserved __name__":  {name: "up", labels: map[string]string{"__name__": "other"}},
+		"invalid label name": {name: "up", labels: map[string]string{"": "x"}},
+	}
+	for desc, tt := range tests {
+		t.Run(desc, func(t *testing.T) {
+			if _, err := buildWriteRequest(tt.name, tt.labels, 1, time.Now()); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
+
+func TestHandlePushMetric(t *testing.T) {
+	var gotHeaders http.Header
+	var gotBody []byte
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/write" {
+			http.NotFound(w, r)
+			return
+		}
+		gotHeaders = r.Header.Clone()
+		gotBody, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer mockServer.Close()
+
+	sc, err := server.NewServerContext(context.Background(),
+		server.WithPrometheusConfig(server.PrometheusConfig{
+			URL:            mockServer.URL,
+			OrgID:          "tenant-a",
+			RemoteWriteURL: mockServer.URL + "/api/v1/write",
+		}),
+		server.WithSlogLogger(discardLogger()),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create server context: %v", err)
+	}
+	defer func() { _ = sc.Shutdown() }()
+
+	client, err := NewClient(sc.PrometheusConfig(), discardLogger())
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+
+	call := func(args map[string]any) *mcp.CallToolResult {
+		var req mcp.CallToolRequest
+		req.Params.Arguments = args
+		result, err := handlePushMetric(context.Background(), req, client, sc)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return result
+	}
+
+	t.Run("requires confirm", func(t *testing.T) {
+		result := call(map[string]any{"metric_name": "test_metric", "value": "1"})
+		if !result.IsError {
+			t.Fatal("expected error without confirm")
+		}
+		if gotBody != nil {
+			t.Error("expected nothing to be pushed without confirm")
+		}
+	})
+
+	t.Run("pushes sample", func(t *testing.T) {
+		result := call(map[string]any{
+			"metric_name": "test_metric",
+			"labels":      map[string]any{"job": "synthetic"},
+			"value":       "3.5",
+			"timestamp":   "2026-01-02T03:04:05Z",
+			"confirm":     "true",
+		})
+		text := result.Content[0].(mcp.TextContent).Text
+		if result.IsError {
+			t.Fatalf("unexpected error result: %s", text)
+		}
+		if want := `Pushed test_metric{job="synthetic"} = 3.5 at 2026-01-02T03:04:05Z`; text != want {
+			t.Errorf("text = %q, want %q", text, want)
+		}
+
+		for header, want := range map[string]string{
+			"Content-Type":                      "application/x-protobuf",
+			"Content-Encoding":                  "snappy",
+			"X-Prometheus-Remote-Write-Version": "0.1.0",
+			"X-Scope-OrgID":                     "tenant-a",
+		} {
+			if got := gotHeaders.Get(header); got != want {
+				t.Errorf("header %s = %q, want %q", header, got, want)
+			}
+		}
+
+		data, err := snappy.Decode(nil, gotBody)
+		if err != nil {
+			t.Fatalf("snappy decode: %v", err)
+		}
+		var decoded prompb.WriteRequest
+		if err := decoded.Unmarshal(data); err != nil {
+			t.Fatalf("unmarshal: %v", err)
+		}
+		if n := len(decoded.Timeseries); n != 1 || decoded.Timeseries[0].Samples[0].Value != 3.5 {
+			t.Errorf("unexpected write request: %+v", decoded)
+		}
+	})
+
+	t.Run("remote write error", func(t *testing.T) {
+		result := call(map[string]any{
+			"metric_name":      "test_metric",
+			"value":            "1",
+			"remote_write_url": mockServer.URL + "/api/v1/push",
+			"confirm":          "true",
+		})
+		text := result.Content[0].(mcp.TextContent).Text
+		if !result.IsError || !strings.Contains(text, "HTTP 404") {
+			t.Errorf("expected HTTP 404 error, got %q", text)
+		}
+	})
+
+	t.Run("remote_write_url with credentials", func(t *testing.T) {
+		config := sc.PrometheusConfig()
+		config.Token = "secret"
+		credentialed, err := NewClient(config, discardLogger())
+		if err != nil {
+			t.Fatalf("NewClient: %v", err)
+		}
+		gotBody = nil
+		var req mcp.CallToolRequest
+		req.Params.Arguments = map[string]any{"metric_name": "m", "value": "1", "remote_write_url": mockServer.URL + "/api/v1/write?other", "confirm": "true"}
+		result, err := handlePushMetric(context.Background(), req, credentialed, sc)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if text := result.Content[0].(mcp.TextContent).Text; !result.IsError || !strings.Contains(text, "cannot be combined with bearer credentials") {
+			t.Errorf("expected credentials to be refused, got %q", text)
+		}
+		if gotBody != nil {
+			t.Error("expected nothing to be pushed to the caller's URL")
+		}
+	})
+
+	t.Run("invalid remote_write_url", func(t *testing.T) {
+		result := call(map[string]any{"metric_name": "m", "value": "1", "remote_write_url": "file:///tmp/x", "confirm": "true"})
+		if text := result.Content[0].(mcp.TextContent).Text; !result.IsError || !strings.Contains(text, "invalid remote_write_url") {
+			t.Errorf("expected remote_write_url validation error, got %q", text)
+		}
+	})
+}
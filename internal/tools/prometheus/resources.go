@@ -0,0 +1,423 @@
+package prometheus
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	mcpserver "github.com/mark3labs/mcp-go/server"
+	"github.com/prometheus/common/model"
+
+	"github.com/giantswarm/mcp-prometheus/internal/filter"
+	"github.com/giantswarm/mcp-prometheus/internal/promql"
+	"github.com/giantswarm/mcp-prometheus/internal/server"
+)
+
+// URIs of the read-only resources registered by RegisterPrometheusTools.
+const (
+	resourcePromQLFunctions = "promql://functions"
+	resourcePromQLOperators = "promql://operators"
+	resourceAPIVersion      = "prometheus://api-version"
+	resourceTargets         = "prometheus://targets"
+	resourceRules           = "prometheus://rules"
+	resourceConfig          = "prometheus://config"
+	resourceMetrics         = "prometheus://metrics"
+	resourceAlerts          = "prometheus://alerts"
+)
+
+// resourceCacheTTL is how long the content of the targets, rules, config and
+// metrics resources is served without asking Prometheus again.
+const resourceCacheTTL = 30 * time.Second
+
+// URI templates of the resources read from the configured Prometheus server.
+// Their arguments can be completed; see completions.go.
+const (
+	resourceMetricTemplate      = "prometheus://metrics/{metric}"
+	resourceLabelValuesTemplate = "prometheus://labels/{label}/values"
+	resourceSeriesTemplate      = "prometheus://series{?matches}"
+	resourceAlertsTemplate      = "prometheus://alerts{?org_id,profile,prometheus_url}"
+)
+
+// alertsResourceParams are the variables of resourceAlertsTemplate, in the
+// order the template expects them.
+var alertsResourceParams = []string{"org_id", "profile", "prometheus_url"}
+
+// maxResourceSeries caps the series listed by prometheus://series.
+const maxResourceSeries = 100
+
+// MIME types of the resources.
+const (
+	mimeTypeText = "text/plain"
+	mimeTypeJSON = "application/json"
+	mimeTypeYAML = "application/yaml"
+)
+
+// registerPrometheusResources registers the PromQL reference resources, the
+// Prometheus API version, targets, rules, config, metric name and alerts
+// resources and the metric, label value and series templates. client may be
+// nil when no default Prometheus URL is configured; reading from Prometheus
+// then fails.
+func registerPrometheusResources(s *mcpserver.MCPServer, client *Client, sc *server.ServerContext) {
+	s.AddResource(mcp.NewResource(resourcePromQLFunctions, "PromQL functions",
+		mcp.WithResourceDescription("PromQL aggregation operators and the signatures of all PromQL functions"),
+		mcp.WithMIMEType(mimeTypeText),
+	), staticTextResource(resourcePromQLFunctions, promQLFunctionsText()))
+
+	s.AddResource(mcp.NewResource(resourcePromQLOperators, "PromQL operators",
+		mcp.WithResourceDescription("PromQL binary operators in precedence order and vector matching modifiers"),
+		mcp.WithMIMEType(mimeTypeText),
+	), staticTextResource(resourcePromQLOperators, promQLOperatorsText()))
+
+	s.AddResource(mcp.NewResource(resourceAPIVersion, "Prometheus API version",
+		mcp.WithResourceDescription("Version of the configured Prometheus server, from its build information"),
+		mcp.WithMIMEType(mimeTypeText),
+	), func(ctx context.Context, _ mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		return readAPIVersion(ctx, client, sc)
+	})
+
+	cache := newTTLCache[string](resourceCacheTTL)
+	s.AddResource(mcp.NewResource(resourceTargets, "Scrape targets",
+		mcp.WithResourceDescription("Scrape pools with their health and every active target as JSON, like get_targets"),
+		mcp.WithMIMEType(mimeTypeJSON),
+	), cachedServerResource(cache, client, sc, resourceTargets, mimeTypeJSON, readTargets))
+
+	s.AddResource(mcp.NewResource(resourceRules, "Rules",
+		mcp.WithResourceDescription("Recording and alerting rule groups as JSON, like get_rules"),
+		mcp.WithMIMEType(mimeTypeJSON),
+	), cachedServerResource(cache, client, sc, resourceRules, mimeTypeJSON, readRules))
+
+	s.AddResource(mcp.NewResource(resourceConfig, "Configuration",
+		mcp.WithResourceDescription("Loaded Prometheus configuration YAML with secrets redacted, like get_config"),
+		mcp.WithMIMEType(mimeTypeYAML),
+	), cachedServerResource(cache, client, sc, resourceConfig, mimeTypeYAML, readConfig))
+
+	s.AddResource(mcp.NewResource(resourceMetrics, "Metric names",
+		mcp.WithResourceDescription("Names of all metrics, one per line, without the excluded metrics"),
+		mcp.WithMIMEType(mimeTypeText),
+	), cachedServerResource(cache, client, sc, resourceMetrics, mimeTypeText, readMetricNames))
+
+	// Not cached: subscribe_alerts announces changes to these resources,
+	// which clients then read. Like the tools, both resolve the reader's
+	// tenant; the template selects the server and tenant a subscription
+	// with org_id, profile or prometheus_url polls.
+	s.AddResource(mcp.NewResource(resourceAlerts, "Alerts",
+		mcp.WithResourceDescription("Pending and firing alerts as JSON, like get_alerts"),
+		mcp.WithMIMEType(mimeTypeJSON),
+	), func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		return readAlertsResource(ctx, request, client, sc)
+	})
+
+	s.AddResourceTemplate(mcp.NewResourceTemplate(resourceAlertsTemplate, "Alerts of a server or tenant",
+		mcp.WithTemplateDescription("Pending and firing alerts as JSON, like get_alerts with the same org_id, profile and prometheus_url"),
+		mcp.WithTemplateMIMEType(mimeTypeJSON),
+	), func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		return readAlertsResource(ctx, request, client, sc)
+	})
+
+	s.AddResourceTemplate(mcp.NewResourceTemplate(resourceMetricTemplate, "Metric metadata",
+		mcp.WithTemplateDescription("Type, help text and unit of a metric"),
+		mcp.WithTemplateMIMEType(mimeTypeText),
+	), func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		return readMetricMetadata(ctx, request, client, sc)
+	})
+
+	s.AddResourceTemplate(mcp.NewResourceTemplate(resourceLabelValuesTemplate, "Label values",
+		mcp.WithTemplateDescription("Values of a label, one per line"),
+		mcp.WithTemplateMIMEType(mimeTypeText),
+	), func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		return readLabelValues(ctx, request, client, sc)
+	})
+
+	s.AddResourceTemplate(mcp.NewResourceTemplate(resourceSeriesTemplate, "Series",
+		mcp.WithTemplateDescription(fmt.Sprintf("Up to %d series matching a selector such as up{job=\"api\"}, one per line", maxResourceSeries)),
+		mcp.WithTemplateMIMEType(mimeTypeText),
+	), func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		return readSeries(ctx, request, client, sc)
+	})
+}
+
+// staticTextResource returns a handler that always serves text.
+func staticTextResource(uri, text string) mcpserver.ResourceHandlerFunc {
+	return func(context.Context, mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		return []mcp.ResourceContents{
+			mcp.TextResourceContents{URI: uri, MIMEType: mimeTypeText, Text: text},
+		}, nil
+	}
+}
+
+// readAPIVersion serves prometheus://api-version.
+func readAPIVersion(ctx context.Context, client *Client, sc *server.ServerContext) ([]mcp.ResourceContents, error) {
+	client, err := resourceClient(ctx, client, sc, resourceAPIVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	sc.Logger().Debug("Reading API version resource")
+
+	buildInfo, err := client.GetBuildInfo(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get build info: %w", err)
+	}
+	return []mcp.ResourceContents{
+		mcp.TextResourceContents{URI: resourceAPIVersion, MIMEType: mimeTypeText, Text: buildInfo.Version},
+	}, nil
+}
+
+// cachedServerResource returns a handler serving the text read from the
+// configured Prometheus server as the caller's tenant, reusing it for
+// resourceCacheTTL for callers of the same tenant.
+func cachedServerResource(cache *ttlCache[string], client *Client, sc *server.ServerContext, uri, mimeType string, read func(context.Context, *Client, *server.ServerContext) (string, error)) mcpserver.ResourceHandlerFunc {
+	return func(ctx context.Context, _ mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		client, err := resourceClient(ctx, client, sc, uri)
+		if err != nil {
+			return nil, err
+		}
+		text, err := cache.get(ctx, clientScopedKey(client, uri), func(ctx context.Context) (string, error) {
+			sc.Logger().Debug("Reading server resource", "uri", uri)
+			return read(ctx, client, sc)
+		})
+		if err != nil {
+			return nil, err
+		}
+		return []mcp.ResourceContents{
+			mcp.TextResourceContents{URI: uri, MIMEType: mimeType, Text: text},
+		}, nil
+	}
+}
+
+// readTargets renders prometheus://targets.
+func readTargets(ctx context.Context, client *Client, _ *server.ServerContext) (string, error) {
+	targets, err := client.GetTargets(ctx)
+	if err != nil {
+		return "", err
+	}
+	return marshalResourceJSON(newTargetsOutput(targets, false))
+}
+
+// readRules renders prometheus://rules.
+func readRules(ctx context.Context, client *Client, _ *server.ServerContext) (string, error) {
+	rules, err := client.GetRules(ctx)
+	if err != nil {
+		return "", err
+	}
+	return marshalResourceJSON(rules)
+}
+
+// readAlerts renders prometheus://alerts.
+func readAlerts(ctx context.Context, client *Client, _ *server.ServerContext) (string, error) {
+	alerts, err := client.GetAlerts(ctx)
+	if err != nil {
+		return "", err
+	}
+	return marshalResourceJSON(newAlertsOutput(alerts))
+}
+
+// readConfig renders prometheus://config.
+func readConfig(ctx context.Context, client *Client, _ *server.ServerContext) (string, error) {
+	config, err := client.GetConfig(ctx)
+	if err != nil {
+		return "", err
+	}
+	return formatConfigYAML(config.YAML, false)
+}
+
+// readMetricNames renders prometheus://metrics.
+func readMetricNames(ctx context.Context, client *Client, sc *server.ServerContext) (string, error) {
+	names, err := client.ListLabelValues(ctx, model.MetricNameLabel, LabelOptions{})
+	if err != nil {
+		return "", err
+	}
+	kept, _ := filter.Exclude(names.LabelValues, sc.ExcludedMetrics())
+	var b strings.Builder
+	for _, name := range kept {
+		b.WriteString(name + "\n")
+	}
+	return b.String(), nil
+}
+
+// marshalResourceJSON renders v as indented JSON.
+func marshalResourceJSON(v any) (string, error) {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to encode resource: %w", err)
+	}
+	return string(data), nil
+}
+
+// readAlertsResource serves prometheus://alerts and its template, reading
+// the alerts from the server and tenant a tool call with the same arguments
+// would.
+func readAlertsResource(ctx context.Context, request mcp.ReadResourceRequest, client *Client, sc *server.ServerContext) ([]mcp.ResourceContents, error) {
+	params := make(map[string]any)
+	for _, name := range alertsResourceParams {
+		if v := templateArgument(request, name); v != "" {
+			params[name] = v
+		}
+	}
+	if client == nil && len(params) == 0 {
+		return nil, fmt.Errorf("no default Prometheus URL configured; set PROMETHEUS_URL to read %s", request.Params.URI)
+	}
+	target, err := createClientFromParams(ctx, params, client, sc)
+	if err != nil {
+		return nil, err
+	}
+	text, err := readAlerts(ctx, target, sc)
+	if err != nil {
+		return nil, err
+	}
+	return []mcp.ResourceContents{
+		mcp.TextResourceContents{URI: request.Params.URI, MIMEType: mimeTypeJSON, Text: text},
+	}, nil
+}
+
+// resourceClient returns the client a read of uri goes through: the default
+// client, or under OAuth tenancy one scoped to the caller's tenants.
+func resourceClient(ctx context.Context, client *Client, sc *server.ServerContext, uri string) (*Client, error) {
+	if client == nil {
+		return nil, fmt.Errorf("no default Prometheus URL configured; set PROMETHEUS_URL to read %s", uri)
+	}
+	return callerClient(ctx, client, sc)
+}
+
+// templateArgument returns the value of a URI template variable of request.
+func templateArgument(request mcp.ReadResourceRequest, name string) string {
+	switch v := request.Params.Arguments[name].(type) {
+	case string:
+		return v
+	case []string:
+		if len(v) > 0 {
+			return v[0]
+		}
+	}
+	return ""
+}
+
+// readMetricMetadata serves prometheus://metrics/{metric}.
+func readMetricMetadata(ctx context.Context, request mcp.ReadResourceRequest, client *Client, sc *server.ServerContext) ([]mcp.ResourceContents, error) {
+	metric := templateArgument(request, "metric")
+	client, err := resourceClient(ctx, client, sc, request.Params.URI)
+	if err != nil {
+		return nil, err
+	}
+
+	sc.Logger().Debug("Reading metric metadata resource", "metric", metric)
+
+	metadata, err := client.GetMetricMetadata(ctx, metric)
+	if err != nil {
+		return nil, err
+	}
+	entries, _ := metadata[metric].([]interface{})
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("no metadata found for metric %q", metric)
+	}
+
+	var b strings.Builder
+	for _, entry := range entries {
+		md, _ := entry.(map[string]interface{})
+		fmt.Fprintf(&b, "%s (%v): %v\n", metric, md["type"], md["help"])
+		if unit, _ := md["unit"].(string); unit != "" {
+			fmt.Fprintf(&b, "unit: %s\n", unit)
+		}
+	}
+	return []mcp.ResourceContents{
+		mcp.TextResourceContents{URI: request.Params.URI, MIMEType: mimeTypeText, Text: b.String()},
+	}, nil
+}
+
+// readLabelValues serves prometheus://labels/{label}/values.
+func readLabelValues(ctx context.Context, request mcp.ReadResourceRequest, client *Client, sc *server.ServerContext) ([]mcp.ResourceContents, error) {
+	label := templateArgument(request, "label")
+	client, err := resourceClient(ctx, client, sc, request.Params.URI)
+	if err != nil {
+		return nil, err
+	}
+
+	sc.Logger().Debug("Reading label values resource", "label", label)
+
+	result, err := client.ListLabelValues(ctx, label, LabelOptions{})
+	if err != nil {
+		return nil, err
+	}
+	values := result.LabelValues
+	if label == model.MetricNameLabel {
+		values, _ = filter.Exclude(values, sc.ExcludedMetrics())
+	}
+
+	var b strings.Builder
+	for _, v := range values {
+		b.WriteString(v + "\n")
+	}
+	return []mcp.ResourceContents{
+		mcp.TextResourceContents{URI: request.Params.URI, MIMEType: mimeTypeText, Text: b.String()},
+	}, nil
+}
+
+// readSeries serves prometheus://series{?matches}.
+func readSeries(ctx context.Context, request mcp.ReadResourceRequest, client *Client, sc *server.ServerContext) ([]mcp.ResourceContents, error) {
+	matches := templateArgument(request, "matches")
+	if matches == "" {
+		return nil, fmt.Errorf("a series selector is required, e.g. prometheus://series?matches=up")
+	}
+	client, err := resourceClient(ctx, client, sc, request.Params.URI)
+	if err != nil {
+		return nil, err
+	}
+
+	sc.Logger().Debug("Reading series resource", "matches", matches)
+
+	result, err := client.FindSeries(ctx, []string{matches}, SeriesOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	var b strings.Builder
+	for i, series := range result.Series {
+		if i == maxResourceSeries {
+			fmt.Fprintf(&b, "(%d of %d series shown; narrow the selector)\n", maxResourceSeries, len(result.Series))
+			break
+		}
+		metric := make(model.Metric, len(series))
+		for name, value := range series {
+			metric[model.LabelName(name)] = model.LabelValue(value)
+		}
+		b.WriteString(metric.String() + "\n")
+	}
+	return []mcp.ResourceContents{
+		mcp.TextResourceContents{URI: request.Params.URI, MIMEType: mimeTypeText, Text: b.String()},
+	}, nil
+}
+
+// promQLFunctionsText renders the aggregation operators followed by the
+// function signatures, one entry per line.
+func promQLFunctionsText() string {
+	var b strings.Builder
+	b.WriteString("# Aggregation operators\n")
+	writeOperators(&b, promql.Aggregations)
+	b.WriteString("\n# Aggregation modifiers\n")
+	writeOperators(&b, promql.AggregationModifiers)
+	b.WriteString("\n# Functions\n")
+	for _, sig := range promql.FunctionSignatures() {
+		b.WriteString(sig + "\n")
+	}
+	return b.String()
+}
+
+// promQLOperatorsText renders the binary operators and vector matching
+// modifiers, one entry per line.
+func promQLOperatorsText() string {
+	var b strings.Builder
+	b.WriteString("# Binary operators (highest precedence first)\n")
+	writeOperators(&b, promql.BinaryOperators)
+	b.WriteString("\n# Vector matching modifiers\n")
+	writeOperators(&b, promql.VectorMatchingModifiers)
+	return b.String()
+}
+
+func writeOperators(b *strings.Builder, ops []promql.Operator) {
+	for _, op := range ops {
+		fmt.Fprintf(b, "%s — %s\n", op.Name, op.Description)
+	}
+}
@@ -0,0 +1,246 @@
+package prometheus
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	mcpserver "github.com/mark3labs/mcp-go/server"
+
+	"github.com/giantswarm/mcp-prometheus/internal/server"
+)
+
+// readResource reads uri through the MCP message handler, as a client would.
+func readResource(t *testing.T, s *mcpserver.MCPServer, uri string) (string, bool) {
+	t.Helper()
+	return readResourceContext(t, context.Background(), s, uri)
+}
+
+// readResourceContext reads uri like readResource, with the request context
+// ctx.
+func readResourceContext(t *testing.T, ctx context.Context, s *mcpserver.MCPServer, uri string) (string, bool) {
+	t.Helper()
+
+	msg := `{"jsonrpc":"2.0","id":1,"method":"resources/read","params":{"uri":"` + uri + `"}}`
+	resp := s.HandleMessage(ctx, []byte(msg))
+	data, err := json.Marshal(resp)
+	if err != nil {
+		t.Fatalf("marshal response: %v", err)
+	}
+
+	var decoded struct {
+		Result *struct {
+			Contents []struct {
+				Text string `json:"text"`
+			} `json:"contents"`
+		} `json:"result"`
+	}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("unmarshal response: %v", err)
+	}
+	if decoded.Result == nil || len(decoded.Result.Contents) == 0 {
+		return string(data), false
+	}
+	return decoded.Result.Contents[0].Text, true
+}
+
+func TestPrometheusResources(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/status/buildinfo" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"status":"success","data":{"version":"3.1.0","revision":"abc","branch":"HEAD","buildUser":"u","buildDate":"d","goVersion":"go1.26.0"}}`))
+	}))
+	defer mockServer.Close()
+
+	sc, err := server.NewServerContext(context.Background(),
+		server.WithPrometheusConfig(server.PrometheusConfig{URL: mockServer.URL}),
+		server.WithSlogLogger(discardLogger()),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create server context: %v", err)
+	}
+	defer func() { _ = sc.Shutdown() }()
+
+	s := mcpserver.NewMCPServer("test", "1.0.0")
+	if err := RegisterPrometheusTools(s, sc); err != nil {
+		t.Fatalf("RegisterPrometheusTools: %v", err)
+	}
+
+	tests := []struct {
+		uri  string
+		want []string
+	}{
+		{resourcePromQLFunctions, []string{"# Aggregation operators\n", "topk(k, v) — ", "rate(range vector) → instant vector\n", "round(instant vector[, scalar]) → instant vector\n", "label_join(instant vector, string, string[, string ...]) → instant vector\n"}},
+		{resourcePromQLOperators, []string{"# Binary operators", "unless — ", "group_left (labels) — "}},
+		{resourceAPIVersion, []string{"3.1.0"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.uri, func(t *testing.T) {
+			text, ok := readResource(t, s, tt.uri)
+			if !ok {
+				t.Fatalf("reading %s failed: %s", tt.uri, text)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(text, want) {
+					t.Errorf("expected %q in %s:\n%s", want, tt.uri, text)
+				}
+			}
+		})
+	}
+}
+
+func TestAPIVersionResourceWithoutDefaultClient(t *testing.T) {
+	sc, err := server.NewServerContext(context.Background(), server.WithSlogLogger(discardLogger()))
+	if err != nil {
+		t.Fatalf("Failed to create server context: %v", err)
+	}
+	defer func() { _ = sc.Shutdown() }()
+
+	if _, err := readAPIVersion(context.Background(), nil, sc); err == nil || !strings.Contains(err.Error(), "PROMETHEUS_URL") {
+		t.Errorf("expected an error mentioning PROMETHEUS_URL, got %v", err)
+	}
+}
+
+func TestServerResources(t *testing.T) {
+	requests := map[string]int{}
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests[r.URL.Path]++
+		w.Header().Set("Content-Type", "application/json")
+		var data string
+		switch r.URL.Path {
+		case "/api/v1/targets":
+			data = `{"activeTargets":[{"scrapePool":"node","scrapeUrl":"http://node:9100/metrics","health":"up","labels":{"job":"node"},"discoveredLabels":{},"lastError":"","lastScrape":"2024-01-01T00:00:00Z","lastScrapeDuration":0.01}],"droppedTargets":[]}`
+		case "/api/v1/rules":
+			data = `{"groups":[{"name":"example","file":"rules.yml","interval":60,"rules":[{"type":"recording","name":"job:up:sum","query":"sum by (job) (up)","health":"ok","lastError":""}]}]}`
+		case "/api/v1/status/config":
+			data = `{"yaml":"scrape_configs:\n- job_name: node\n  basic_auth:\n    username: admin\n    password: hunter2\n"}`
+		case "/api/v1/label/__name__/values":
+			data = `["go_goroutines","up"]`
+		default:
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(`{"status":"success","data":` + data + `}`))
+	}))
+	defer mockServer.Close()
+
+	sc, err := server.NewServerContext(context.Background(),
+		server.WithPrometheusConfig(server.PrometheusConfig{URL: mockServer.URL}),
+		server.WithSlogLogger(discardLogger()),
+		server.WithExcludedMetrics([]string{"go_*"}),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create server context: %v", err)
+	}
+	defer func() { _ = sc.Shutdown() }()
+
+	s := mcpserver.NewMCPServer("test", "1.0.0")
+	if err := RegisterPrometheusTools(s, sc); err != nil {
+		t.Fatalf("RegisterPrometheusTools: %v", err)
+	}
+
+	tests := []struct {
+		uri     string
+		want    []string
+		notWant string
+	}{
+		{resourceTargets, []string{`"scrapePool": "node"`, `"scrapeUrl": "http://node:9100/metrics"`}, ""},
+		{resourceRules, []string{`"name": "job:up:sum"`, `"query": "sum by (job) (up)"`}, ""},
+		{resourceConfig, []string{"job_name: node", "username: admin"}, "hunter2"},
+		{resourceMetrics, []string{"up\n"}, "go_goroutines"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.uri, func(t *testing.T) {
+			text, ok := readResource(t, s, tt.uri)
+			if !ok {
+				t.Fatalf("reading %s failed: %s", tt.uri, text)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(text, want) {
+					t.Errorf("expected %q in %s:\n%s", want, tt.uri, text)
+				}
+			}
+			if tt.notWant != "" && strings.Contains(text, tt.notWant) {
+				t.Errorf("unexpected %q in %s:\n%s", tt.notWant, tt.uri, text)
+			}
+		})
+	}
+
+	// A second read within resourceCacheTTL is served from the cache.
+	if _, ok := readResource(t, s, resourceTargets); !ok {
+		t.Fatal("second read of targets failed")
+	}
+	if got := requests["/api/v1/targets"]; got != 1 {
+		t.Errorf("targets fetched %d times, want 1", got)
+	}
+}
+
+func TestServerResourcesPerTenant(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		orgID := r.Header.Get("X-Scope-OrgID")
+		var data string
+		switch r.URL.Path {
+		case "/api/v1/label/__name__/values", "/api/v1/label/job/values":
+			data = `["` + orgID + `_value"]`
+		case "/api/v1/status/buildinfo":
+			data = `{"version":"` + orgID + `"}`
+		default:
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(`{"status":"success","data":` + data + `}`))
+	}))
+	defer mockServer.Close()
+
+	sc, err := server.NewServerContext(context.Background(),
+		server.WithPrometheusConfig(server.PrometheusConfig{URL: mockServer.URL}),
+		server.WithSlogLogger(discardLogger()),
+		server.WithOAuthEnabled(true),
+		server.WithTenancyResolver(groupTenants{"team-a": {"tenant-a"}, "team-b": {"tenant-b"}}),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create server context: %v", err)
+	}
+	defer func() { _ = sc.Shutdown() }()
+
+	s := mcpserver.NewMCPServer("test", "1.0.0")
+	if err := RegisterPrometheusTools(s, sc); err != nil {
+		t.Fatalf("RegisterPrometheusTools: %v", err)
+	}
+
+	tests := []struct {
+		uri  string
+		want string
+	}{
+		{resourceMetrics, "%s_value\n"},
+		{"prometheus://labels/job/values", "%s_value\n"},
+		{resourceAPIVersion, "%s"},
+	}
+	for _, tt := range tests {
+		// tenant-a is read again after tenant-b to catch values cached
+		// across tenants.
+		for _, tenant := range []string{"a", "b", "a"} {
+			ctx := withGroups(context.Background(), "team-"+tenant)
+			text, ok := readResourceContext(t, ctx, s, tt.uri)
+			if !ok {
+				t.Fatalf("reading %s as team-%s failed: %s", tt.uri, tenant, text)
+			}
+			if want := fmt.Sprintf(tt.want, "tenant-"+tenant); text != want {
+				t.Errorf("%s as team-%s = %q, want %q", tt.uri, tenant, text, want)
+			}
+		}
+
+		// Without a user the tenant is unknown and the read fails.
+		if text, ok := readResource(t, s, tt.uri); ok {
+			t.Errorf("reading %s without a user succeeded: %q", tt.uri, text)
+		}
+	}
+}
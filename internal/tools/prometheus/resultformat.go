@@ -0,0 +1,268 @@
+package prometheus
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/prometheus/common/model"
+
+	"github.com/giantswarm/mcp-prometheus/internal/format"
+)
+
+// resultTimeLayout renders sample timestamps with millisecond precision,
+// matching the resolution Prometheus stores.
+const resultTimeLayout = "2006-01-02T15:04:05.000Z07:00"
+
+// formatResultTime renders a Prometheus timestamp in UTC.
+func formatResultTime(t model.Time) string {
+	return t.Time().UTC().Format(resultTimeLayout)
+}
+
+// formatSampleValue renders a float sample, or a native histogram when h is
+// non-nil.
+func formatSampleValue(v model.SampleValue, h *model.SampleHistogram) string {
+	if h != nil {
+		return h.String()
+	}
+	return v.String()
+}
+
+// unitSuffix renders the unit of converted values as " (unit)", or nothing
+// for unconverted values.
+func unitSuffix(unit string) string {
+	if unit == "" {
+		return ""
+	}
+	return " (" + unit + ")"
+}
+
+// formatVector renders an instant vector as a "series | value | timestamp"
+// table, one row per series. A non-empty unit is shown in the value column
+// header.
+func formatVector(v model.Vector, unit string) string {
+	if len(v) == 0 {
+		return "Empty vector: no series matched."
+	}
+
+	return fmt.Sprintf("%d series:\n%s", len(v), formatVectorRows(v, unit))
+}
+
+// formatVectorRows renders the "series | value | timestamp" table of v.
+func formatVectorRows(v model.Vector, unit string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "series | value%s | timestamp\n", unitSuffix(unit))
+	for _, s := range v {
+		fmt.Fprintf(&b, "%s | %s | %s\n", s.Metric, formatSampleValue(s.Value, s.Histogram), formatResultTime(s.Timestamp))
+	}
+	return b.String()
+}
+
+// formatMatrix renders a range vector series by series: a header with the
+// sample count, the unit of converted values and the covered time range,
+// followed by the samples.
+func formatMatrix(m model.Matrix, unit string) string {
+	if len(m) == 0 {
+		return "Empty matrix: no series matched."
+	}
+
+	return fmt.Sprintf("%d series:\n%s", len(m), formatMatrixSeries(m, unit))
+}
+
+// formatMatrixSeries renders each series of m as a header line followed by
+// its samples, separated by blank lines.
+func formatMatrixSeries(m model.Matrix, unit string) string {
+	var b strings.Builder
+	for _, s := range m {
+		b.WriteString("\n")
+		points := len(s.Values) + len(s.Histograms)
+		if points == 0 {
+			fmt.Fprintf(&b, "%s: no samples\n", s.Metric)
+			continue
+		}
+
+		first, last := seriesTimeRange(s)
+		noun := "samples"
+		if points == 1 {
+			noun = "sample"
+		}
+		fmt.Fprintf(&b, "%s: %d %s%s from %s to %s\n", s.Metric, points, noun, unitSuffix(unit), formatResultTime(first), formatResultTime(last))
+		for _, p := range s.Values {
+			fmt.Fprintf(&b, "  %s %s\n", formatResultTime(p.Timestamp), p.Value)
+		}
+		for _, p := range s.Histograms {
+			fmt.Fprintf(&b, "  %s %s\n", formatResultTime(p.Timestamp), p.Histogram)
+		}
+	}
+	return b.String()
+}
+
+// sparklineWidth is the number of cells of a format "sparkline" chart.
+const sparklineWidth = 60
+
+// formatMatrixSparklines renders each series of m as one line: its labels, a
+// sparkline and the min, max and last value. All sparklines cover the time
+// span of the whole result, so columns line up across series; each cell
+// shows the mean of the samples falling into it, and cells without samples
+// are blank. Native histogram samples are not charted.
+func formatMatrixSparklines(m model.Matrix, unit string) string {
+	if len(m) == 0 {
+		return "Empty matrix: no series matched."
+	}
+
+	var first, last model.Time
+	timestamps := map[model.Time]bool{}
+	for _, s := range m {
+		for _, p := range s.Values {
+			if len(timestamps) == 0 || p.Timestamp < first {
+				first = p.Timestamp
+			}
+			last = max(last, p.Timestamp)
+			timestamps[p.Timestamp] = true
+		}
+	}
+	if len(timestamps) == 0 {
+		return fmt.Sprintf("%d series without float samples.", len(m))
+	}
+	width := min(sparklineWidth, len(timestamps))
+	cell := time.Duration(last-first) * time.Millisecond / time.Duration(width)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d series from %s to %s", len(m), formatResultTime(first), formatResultTime(last))
+	if width > 1 {
+		fmt.Fprintf(&b, ", one cell per %s", model.Duration(cell))
+	}
+	b.WriteString(":\n")
+	for _, s := range m {
+		sums, counts := make([]float64, width), make([]int, width)
+		lo, hi := math.Inf(1), math.Inf(-1)
+		for _, p := range s.Values {
+			v := float64(p.Value)
+			if math.IsNaN(v) {
+				continue
+			}
+			i := width - 1
+			if last > first {
+				i = min(int(int64(p.Timestamp-first)*int64(width)/int64(last-first)), width-1)
+			}
+			sums[i] += v
+			counts[i]++
+			lo, hi = min(lo, v), max(hi, v)
+		}
+		if lo > hi {
+			fmt.Fprintf(&b, "\n%s: no float samples\n", s.Metric)
+			continue
+		}
+		cells := make([]float64, width)
+		for i := range cells {
+			cells[i] = math.NaN()
+			if counts[i] > 0 {
+				cells[i] = sums[i] / float64(counts[i])
+			}
+		}
+		lastValue := s.Values[len(s.Values)-1].Value
+		fmt.Fprintf(&b, "\n%s%s\n  %s  min: %s  max: %s  last: %s\n",
+			s.Metric, unitSuffix(unit), format.Sparkline(cells), model.SampleValue(lo), model.SampleValue(hi), lastValue)
+	}
+	return b.String()
+}
+
+// seriesTimeRange returns the earliest and latest timestamps of a series
+// holding float samples, histogram samples or both.
+func seriesTimeRange(s *model.SampleStream) (first, last model.Time) {
+	set := false
+	observe := func(t model.Time) {
+		if !set || t < first {
+			first = t
+		}
+		if !set || t > last {
+			last = t
+		}
+		set = true
+	}
+	for _, p := range s.Values {
+		observe(p.Timestamp)
+	}
+	for _, p := range s.Histograms {
+		observe(p.Timestamp)
+	}
+	return first, last
+}
+
+// formatScalar renders a scalar result as its value, unit and evaluation time.
+func formatScalar(s *model.Scalar, unit string) string {
+	if s == nil {
+		return "Empty scalar."
+	}
+	return fmt.Sprintf("Scalar: %s%s at %s", s.Value, unitSuffix(unit), formatResultTime(s.Timestamp))
+}
+
+// formatString renders a string result as its quoted value and evaluation
+// time.
+func formatString(s *model.String) string {
+	if s == nil {
+		return "Empty string."
+	}
+	return fmt.Sprintf("String: %q at %s", s.Value, formatResultTime(s.Timestamp))
+}
+
+// convertQueryResult converts the float samples of a vector, matrix or scalar
+// result in place. Native histogram samples are left unchanged.
+func convertQueryResult(result any, c format.UnitConverter) {
+	switch v := result.(type) {
+	case model.Vector:
+		for _, s := range v {
+			if s.Histogram == nil {
+				s.Value = model.SampleValue(c.Convert(float64(s.Value)))
+			}
+		}
+	case model.Matrix:
+		for _, s := range v {
+			for i := range s.Values {
+				s.Values[i].Value = model.SampleValue(c.Convert(float64(s.Values[i].Value)))
+			}
+		}
+	case *model.Scalar:
+		if v != nil {
+			v.Value = model.SampleValue(c.Convert(float64(v.Value)))
+		}
+	}
+}
+
+// fetchFamilyMetadata looks up HELP and TYPE for every metric name in v
+// with a single request for the metadata of all metrics, so a vector of many
+// metric families costs no more than one of a few. Counter metadata may be
+// stored without the _total suffix, so that form is tried when the full name
+// has none. A failed lookup is logged and leaves every metric without
+// metadata.
+func fetchFamilyMetadata(ctx context.Context, client *Client, v model.Vector, logger *slog.Logger) map[string]format.MetricFamilyMetadata {
+	result := make(map[string]format.MetricFamilyMetadata)
+	if len(v) == 0 {
+		return result
+	}
+	metadata, err := client.ListMetricMetadata(ctx)
+	if err != nil {
+		logger.Warn("Failed to get metric metadata", "error", err)
+		return result
+	}
+	for _, s := range v {
+		name := string(s.Metric[model.MetricNameLabel])
+		if name == "" {
+			continue
+		}
+		candidates := []string{name}
+		if base, ok := strings.CutSuffix(name, "_total"); ok {
+			candidates = append(candidates, base)
+		}
+		for _, candidate := range candidates {
+			if entries := metadata[candidate]; len(entries) > 0 {
+				result[candidate] = format.MetricFamilyMetadata{Type: string(entries[0].Type), Help: entries[0].Help}
+				break
+			}
+		}
+	}
+	return result
+}
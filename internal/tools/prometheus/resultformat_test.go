@@ -0,0 +1,448 @@
+package prometheus
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/prometheus/common/expfmt"
+	"github.com/prometheus/common/model"
+
+	"github.com/giantswarm/mcp-prometheus/internal/format"
+	"github.com/giantswarm/mcp-prometheus/internal/server"
+)
+
+// resultTestTime is 2024-01-01T00:00:00Z in milliseconds.
+const resultTestTime = model.Time(1704067200000)
+
+func TestFormatVector(t *testing.T) {
+	tests := []struct {
+		name   string
+		vector model.Vector
+		want   string
+	}{
+		{
+			name:   "empty",
+			vector: model.Vector{},
+			want:   "Empty vector: no series matched.",
+		},
+		{
+			name: "single series",
+			vector: model.Vector{
+				{Metric: model.Metric{"__name__": "up", "job": "prometheus"}, Value: 1, Timestamp: resultTestTime},
+			},
+			want: "1 series:\n" +
+				"series | value | timestamp\n" +
+				"up{job=\"prometheus\"} | 1 | 2024-01-01T00:00:00.000Z\n",
+		},
+		{
+			name: "multi series with many labels",
+			vector: model.Vector{
+				{
+					Metric:    model.Metric{"__name__": "http_requests_total", "code": "200", "handler": "/api", "instance": "a:9090", "job": "api", "method": "GET"},
+					Value:     1234.5,
+					Timestamp: resultTestTime,
+				},
+				{
+					Metric:    model.Metric{"code": "500", "handler": "/api"},
+					Value:     0.25,
+					Timestamp: resultTestTime + 1500,
+				},
+			},
+			want: "2 series:\n" +
+				"series | value | timestamp\n" +
+				"http_requests_total{code=\"200\", handler=\"/api\", instance=\"a:9090\", job=\"api\", method=\"GET\"} | 1234.5 | 2024-01-01T00:00:00.000Z\n" +
+				"{code=\"500\", handler=\"/api\"} | 0.25 | 2024-01-01T00:00:01.500Z\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := formatVector(tt.vector, ""); got != tt.want {
+				t.Errorf("formatVector() =\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatMatrix(t *testing.T) {
+	tests := []struct {
+		name   string
+		matrix model.Matrix
+		want   string
+	}{
+		{
+			name:   "empty",
+			matrix: model.Matrix{},
+			want:   "Empty matrix: no series matched.",
+		},
+		{
+			name: "single series",
+			matrix: model.Matrix{
+				{
+					Metric: model.Metric{"__name__": "up", "job": "node"},
+					Values: []model.SamplePair{
+						{Timestamp: resultTestTime, Value: 1},
+						{Timestamp: resultTestTime + 60000, Value: 0},
+					},
+				},
+			},
+			want: "1 series:\n\n" +
+				"up{job=\"node\"}: 2 samples from 2024-01-01T00:00:00.000Z to 2024-01-01T00:01:00.000Z\n" +
+				"  2024-01-01T00:00:00.000Z 1\n" +
+				"  2024-01-01T00:01:00.000Z 0\n",
+		},
+		{
+			name: "multi series with many labels",
+			matrix: model.Matrix{
+				{
+					Metric: model.Metric{"__name__": "node_load1", "instance": "a", "job": "node", "region": "eu", "zone": "eu-1a"},
+					Values: []model.SamplePair{{Timestamp: resultTestTime, Value: 0.5}},
+				},
+				{
+					Metric: model.Metric{"__name__": "node_load1", "instance": "b", "job": "node", "region": "us", "zone": "us-1b"},
+				},
+			},
+			want: "2 series:\n\n" +
+				"node_load1{instance=\"a\", job=\"node\", region=\"eu\", zone=\"eu-1a\"}: 1 sample from 2024-01-01T00:00:00.000Z to 2024-01-01T00:00:00.000Z\n" +
+				"  2024-01-01T00:00:00.000Z 0.5\n\n" +
+				"node_load1{instance=\"b\", job=\"node\", region=\"us\", zone=\"us-1b\"}: no samples\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := formatMatrix(tt.matrix, ""); got != tt.want {
+				t.Errorf("formatMatrix() =\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatScalar(t *testing.T) {
+	if got, want := formatScalar(&model.Scalar{Value: 42, Timestamp: resultTestTime}, ""), "Scalar: 42 at 2024-01-01T00:00:00.000Z"; got != want {
+		t.Errorf("formatScalar() = %q, want %q", got, want)
+	}
+	if got, want := formatScalar(nil, ""), "Empty scalar."; got != want {
+		t.Errorf("formatScalar(nil) = %q, want %q", got, want)
+	}
+}
+
+func TestFormatString(t *testing.T) {
+	if got, want := formatString(&model.String{Value: "hello", Timestamp: resultTestTime}), `String: "hello" at 2024-01-01T00:00:00.000Z`; got != want {
+		t.Errorf("formatString() = %q, want %q", got, want)
+	}
+	if got, want := formatString(nil), "Empty string."; got != want {
+		t.Errorf("formatString(nil) = %q, want %q", got, want)
+	}
+}
+
+func TestFormatQueryResultDispatch(t *testing.T) {
+	tests := []struct {
+		name   string
+		result any
+		want   string
+	}{
+		{name: "vector", result: model.Vector{}, want: "Empty vector"},
+		{name: "matrix", result: model.Matrix{}, want: "Empty matrix"},
+		{name: "scalar", result: &model.Scalar{Value: 1, Timestamp: resultTestTime}, want: "Scalar: 1"},
+		{name: "string", result: &model.String{Value: "x", Timestamp: resultTestTime}, want: `String: "x"`},
+		{name: "unknown", result: []int{1, 2}, want: "[1 2]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := formatQueryResult(tt.name, tt.result, "", false)
+			if !strings.HasPrefix(got, "Query executed successfully.\nResult Type: "+tt.name+"\nResult:\n") || !strings.Contains(got, tt.want) {
+				t.Errorf("formatQueryResult() = %q, want it to contain %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConvertQueryResult(t *testing.T) {
+	c, err := format.ParseUnitConversion("bytes->GiB")
+	if err != nil {
+		t.Fatal(err)
+	}
+	vector := model.Vector{{Metric: model.Metric{"job": "api"}, Value: 2147483648, Timestamp: resultTestTime}}
+	convertQueryResult(vector, c)
+	if got, want := formatVector(vector, c.To), "1 series:\nseries | value (GiB) | timestamp\n{job=\"api\"} | 2 | 2024-01-01T00:00:00.000Z\n"; got != want {
+		t.Errorf("converted vector =\n%s\nwant:\n%s", got, want)
+	}
+
+	matrix := model.Matrix{{Metric: model.Metric{"job": "api"}, Values: []model.SamplePair{{Timestamp: resultTestTime, Value: 1073741824}}}}
+	convertQueryResult(matrix, c)
+	if got, want := formatMatrix(matrix, c.To), "1 series:\n\n{job=\"api\"}: 1 sample (GiB) from 2024-01-01T00:00:00.000Z to 2024-01-01T00:00:00.000Z\n  2024-01-01T00:00:00.000Z 1\n"; got != want {
+		t.Errorf("converted matrix =\n%s\nwant:\n%s", got, want)
+	}
+
+	scalar := &model.Scalar{Value: 536870912, Timestamp: resultTestTime}
+	convertQueryResult(scalar, c)
+	if got, want := formatScalar(scalar, c.To), "Scalar: 0.5 (GiB) at 2024-01-01T00:00:00.000Z"; got != want {
+		t.Errorf("converted scalar = %q, want %q", got, want)
+	}
+}
+
+func TestHandleQueryConvertTo(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var data any
+		switch r.URL.Path {
+		case apiQueryPath:
+			data = map[string]any{respKeyResultType: respValVector, respKeyResult: []any{
+				map[string]any{"metric": map[string]string{"__name__": "request_duration_seconds"}, "value": []any{1704067200, "0.25"}},
+			}}
+		case "/api/v1/query_range":
+			data = map[string]any{respKeyResultType: "matrix", respKeyResult: []any{
+				map[string]any{"metric": map[string]string{"__name__": "request_duration_seconds"}, "values": []any{[]any{1704067200, "0.5"}}},
+			}}
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{respKeyStatus: respValSuccess, respKeyData: data})
+	}))
+	defer mockServer.Close()
+
+	ctx := context.Background()
+	sc, err := server.NewServerContext(ctx,
+		server.WithPrometheusConfig(server.PrometheusConfig{URL: mockServer.URL}),
+		server.WithSlogLogger(discardLogger()),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create server context: %v", err)
+	}
+	defer func() { _ = sc.Shutdown() }()
+
+	client, err := NewClient(sc.PrometheusConfig(), sc.Logger())
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		handler func(context.Context, mcp.CallToolRequest, *Client, *server.ServerContext) (*mcp.CallToolResult, error)
+		args    map[string]any
+		want    string
+		wantErr bool
+	}{
+		{
+			name:    "instant query",
+			handler: handleExecuteQuery,
+			args:    map[string]any{paramKeyQuery: "request_duration_seconds", "convert_to": "seconds->ms"},
+			want:    "series | value (ms) | timestamp\nrequest_duration_seconds | 250 |",
+		},
+		{
+			name:    "range query with slash separator",
+			handler: handleExecuteRangeQuery,
+			args:    map[string]any{paramKeyQuery: "request_duration_seconds", "start": "1704067200", "end": "1704067260", "step": "1m", "convert_to": "seconds/ms"},
+			want:    "request_duration_seconds: 1 sample (ms) from 2024-01-01T00:00:00.000Z to 2024-01-01T00:00:00.000Z\n  2024-01-01T00:00:00.000Z 500\n",
+		},
+		{
+			name:    "unknown conversion",
+			handler: handleExecuteQuery,
+			args:    map[string]any{paramKeyQuery: "up", "convert_to": "seconds->GiB"},
+			want:    "cannot convert seconds",
+			wantErr: true,
+		},
+		{
+			name:    "openmetrics",
+			handler: handleExecuteQuery,
+			args:    map[string]any{paramKeyQuery: "up", "convert_to": "bytes->GiB", "format": "openmetrics"},
+			want:    "cannot be combined with format 'openmetrics'",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := mcp.CallToolRequest{Params: mcp.CallToolParams{Arguments: tt.args}}
+			result, err := tt.handler(ctx, request, client, sc)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			text := result.Content[0].(mcp.TextContent).Text
+			if result.IsError != tt.wantErr || !strings.Contains(text, tt.want) {
+				t.Errorf("got (error=%v):\n%s\nwant it to contain %q", result.IsError, text, tt.want)
+			}
+		})
+	}
+}
+
+func TestHandleExecuteQueryOpenMetrics(t *testing.T) {
+	var metadataRequests []string
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		var data any
+		switch r.URL.Path {
+		case apiQueryPath:
+			resultType, result := respValVector, any([]any{
+				map[string]any{"metric": map[string]string{"__name__": "http_requests_total", "job": "api"}, "value": []any{1704067200, "42"}},
+				map[string]any{"metric": map[string]string{"__name__": "process_open_fds", "job": "api"}, "value": []any{1704067200, "12"}},
+				map[string]any{"metric": map[string]string{"__name__": "mystery_metric"}, "value": []any{1704067200, "1"}},
+			})
+			if r.Form.Get(paramKeyQuery) == "scalar(1)" {
+				resultType, result = "scalar", []any{1704067200, "1"}
+			}
+			data = map[string]any{respKeyResultType: resultType, respKeyResult: result}
+		case "/api/v1/metadata":
+			metadataRequests = append(metadataRequests, r.Form.Get("metric"))
+			data = map[string]any{
+				"http_requests":    []any{map[string]any{"type": "counter", "help": "Total HTTP requests.", "unit": ""}},
+				"process_open_fds": []any{map[string]any{"type": "gauge", "help": "Open file descriptors.", "unit": ""}},
+			}
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{respKeyStatus: respValSuccess, respKeyData: data})
+	}))
+	defer mockServer.Close()
+
+	ctx := context.Background()
+	sc, err := server.NewServerContext(ctx,
+		server.WithPrometheusConfig(server.PrometheusConfig{URL: mockServer.URL}),
+		server.WithSlogLogger(discardLogger()),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create server context: %v", err)
+	}
+	defer func() { _ = sc.Shutdown() }()
+
+	client, err := NewClient(sc.PrometheusConfig(), sc.Logger())
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+
+	call := func(query string) *mcp.CallToolResult {
+		t.Helper()
+		request := mcp.CallToolRequest{Params: mcp.CallToolParams{Name: toolExecuteQuery, Arguments: map[string]any{paramKeyQuery: query, "format": "openmetrics"}}}
+		result, err := handleExecuteQuery(ctx, request, client, sc)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return result
+	}
+
+	result := call("{job=\"api\"}")
+	text := result.Content[0].(mcp.TextContent).Text
+	if result.IsError {
+		t.Fatalf("expected success, got error: %s", text)
+	}
+	for _, want := range []string{
+		"# HELP http_requests Total HTTP requests.\n# TYPE http_requests counter\nhttp_requests_total{job=\"api\"} 42.0\n",
+		"# HELP process_open_fds Open file descriptors.\n# TYPE process_open_fds gauge\nprocess_open_fds{job=\"api\"} 12.0\n",
+		"# TYPE mystery_metric untyped\nmystery_metric 1\n",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("output missing %q:\n%s", want, text)
+		}
+	}
+	if !strings.HasSuffix(text, "# EOF\n") {
+		t.Errorf("output does not end with # EOF:\n%s", text)
+	}
+	parser := expfmt.NewTextParser(model.UTF8Validation)
+	if _, err := parser.TextToMetricFamilies(strings.NewReader(text)); err != nil {
+		t.Errorf("output does not parse: %v\n%s", err, text)
+	}
+	// One request without a metric filter covers every family.
+	if len(metadataRequests) != 1 || metadataRequests[0] != "" {
+		t.Errorf("metadata requests = %q, want one for all metrics", metadataRequests)
+	}
+
+	if result := call("scalar(1)"); !result.IsError {
+		t.Errorf("expected an error for a scalar result, got %v", result.Content)
+	}
+}
+
+func TestHandleExecuteRangeQueryCSV(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			respKeyStatus: respValSuccess,
+			respKeyData: map[string]any{respKeyResultType: "matrix", respKeyResult: []any{
+				map[string]any{"metric": map[string]string{"job": "api"}, "values": []any{[]any{1704067200, "1073741824"}, []any{1704067260, "2147483648"}}},
+			}},
+		})
+	}))
+	defer mockServer.Close()
+
+	ctx := context.Background()
+	sc, err := server.NewServerContext(ctx,
+		server.WithPrometheusConfig(server.PrometheusConfig{URL: mockServer.URL}),
+		server.WithSlogLogger(discardLogger()),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create server context: %v", err)
+	}
+	defer func() { _ = sc.Shutdown() }()
+	client, err := NewClient(sc.PrometheusConfig(), sc.Logger())
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+
+	args := map[string]any{paramKeyQuery: "mem", "start": "1704067200", "end": "1704067260", "step": "5s", "format": "csv", "convert_to": "bytes->GiB"}
+	request := mcp.CallToolRequest{Params: mcp.CallToolParams{Name: toolExecuteRangeQuery, Arguments: args}}
+	result, err := handleExecuteRangeQuery(ctx, request, client, sc)
+	if err != nil || result.IsError {
+		t.Fatalf("unexpected failure: %v %v", err, result)
+	}
+	// The 5s step warning is left out so the text stays valid CSV.
+	want := "timestamp,time,\"{job=\"\"api\"\"}\"\n1704067200,2024-01-01T00:00:00.000Z,1\n1704067260,2024-01-01T00:01:00.000Z,2\n"
+	if text := result.Content[0].(mcp.TextContent).Text; text != want {
+		t.Errorf("text =\n%s\nwant\n%s", text, want)
+	}
+	if out := result.StructuredContent.(QueryOutput); out.Unit != "GiB" || len(out.Series) != 1 {
+		t.Errorf("structured output = %+v", out)
+	}
+
+	args["format"] = "xlsx"
+	if result, _ := handleExecuteRangeQuery(ctx, request, client, sc); !result.IsError {
+		t.Error("expected an error for an unsupported format")
+	}
+}
+
+func TestFormatMatrixSparklines(t *testing.T) {
+	pairs := func(values ...float64) []model.SamplePair {
+		out := make([]model.SamplePair, len(values))
+		for i, v := range values {
+			out[i] = model.SamplePair{Timestamp: resultTestTime.Add(time.Duration(i) * time.Minute), Value: model.SampleValue(v)}
+		}
+		return out
+	}
+	m := model.Matrix{
+		{Metric: model.Metric{"job": "api"}, Values: pairs(0, 1, 2, 3, 4, 5, 6, 7)},
+		{Metric: model.Metric{"job": "db"}, Values: pairs(5, 5, 5, 5)},
+		{Metric: model.Metric{"job": "idle"}},
+	}
+	got := formatMatrixSparklines(m, "GiB")
+	want := `3 series from 2024-01-01T00:00:00.000Z to 2024-01-01T00:07:00.000Z, one cell per 52s500ms:
+
+{job="api"} (GiB)
+  ▁▂▃▄▅▆▇█  min: 0  max: 7  last: 7
+
+{job="db"} (GiB)
+  ▁▁▁▁      min: 5  max: 5  last: 5
+
+{job="idle"}: no float samples
+`
+	if got != want {
+		t.Errorf("formatMatrixSparklines() =\n%s\nwant\n%s", got, want)
+	}
+
+	// Long series are averaged into sparklineWidth cells.
+	long := make([]float64, 600)
+	for i := range long {
+		long[i] = float64(i)
+	}
+	lines := strings.Split(formatMatrixSparklines(model.Matrix{{Metric: model.Metric{}, Values: pairs(long...)}}, ""), "\n")
+	if chart, _, _ := strings.Cut(strings.TrimSpace(lines[3]), "  "); utf8.RuneCountInString(chart) != sparklineWidth {
+		t.Errorf("chart has %d cells, want %d: %q", utf8.RuneCountInString(chart), sparklineWidth, lines[3])
+	}
+}
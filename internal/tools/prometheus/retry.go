@@ -0,0 +1,171 @@
+package prometheus
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+)
+
+// idempotentRetries is how often an idempotent admin request is retried
+// after the first attempt.
+const idempotentRetries = 3
+
+// idempotentRetryBaseDelay is the base of the exponential backoff between
+// retries; each delay is drawn uniformly up to base*2^retry. A variable so
+// tests can shorten it.
+var idempotentRetryBaseDelay = 2 * time.Second
+
+// idempotentAdminPaths are the endpoints safe to repeat: a second reload or
+// tombstone cleanup leaves the server in the same state as the first. A
+// snapshot is not among them, as every call writes another full copy of the
+// TSDB.
+var idempotentAdminPaths = []string{
+	"/api/v1/admin/tsdb/clean_tombstones",
+	"/-/reload",
+}
+
+// idempotentRetryRoundTripper retries POST requests to idempotentAdminPaths
+// that fail with a connection error or 503 Service Unavailable, using full
+// jitter backoff. Other 5xx responses are retried until two consecutive
+// attempts return the same status, so a transient 500 that resolves to 200
+// is reported as success. Each attempt is bounded by attemptTimeout, so
+// callers bound the whole request by budget() rather than an
+// http.Client.Timeout, which would cut off the later retries. Every other
+// request passes through untouched; unlike queries, arbitrary writes may not
+// be safe to repeat.
+type idempotentRetryRoundTripper struct {
+	rt             http.RoundTripper
+	retries        int
+	baseDelay      time.Duration
+	attemptTimeout time.Duration
+	logger         *slog.Logger
+}
+
+// newIdempotentRetryRoundTripper wraps rt with the default retry policy and
+// bounds each attempt by httpRequestTimeout.
+func newIdempotentRetryRoundTripper(rt http.RoundTripper, logger *slog.Logger) *idempotentRetryRoundTripper {
+	return &idempotentRetryRoundTripper{
+		rt:             rt,
+		retries:        idempotentRetries,
+		baseDelay:      idempotentRetryBaseDelay,
+		attemptTimeout: httpRequestTimeout,
+		logger:         logger,
+	}
+}
+
+// budget is the longest a retried request can take: every attempt running
+// into attemptTimeout, plus the longest backoff before each retry.
+func (r *idempotentRetryRoundTripper) budget() time.Duration {
+	return time.Duration(r.retries+1)*r.attemptTimeout + r.baseDelay*(1<<r.retries-1)
+}
+
+// retryable reports whether req targets an idempotent admin endpoint and its
+// body, if any, can be replayed.
+func (r *idempotentRetryRoundTripper) retryable(req *http.Request) bool {
+	if req.Method != http.MethodPost || (req.Body != nil && req.Body != http.NoBody && req.GetBody == nil) {
+		return false
+	}
+	// Suffix matching keeps PROMETHEUS_PATH_PREFIX deployments covered.
+	return slices.ContainsFunc(idempotentAdminPaths, func(p string) bool {
+		return strings.HasSuffix(req.URL.Path, p)
+	})
+}
+
+func (r *idempotentRetryRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !r.retryable(req) {
+		return r.rt.RoundTrip(req)
+	}
+
+	previousStatus := 0
+	for attempt := 0; ; attempt++ {
+		if attempt > 0 {
+			if err := r.wait(req.Context(), attempt); err != nil {
+				return nil, err
+			}
+			if req.GetBody != nil {
+				body, err := req.GetBody()
+				if err != nil {
+					return nil, err
+				}
+				req = req.Clone(req.Context())
+				req.Body = body
+			}
+		}
+
+		resp, err := r.attempt(req)
+		if attempt == r.retries || !r.shouldRetry(req.Context(), resp, err, previousStatus) {
+			return resp, err
+		}
+
+		if err != nil {
+			r.logger.Warn("Retrying idempotent admin request", "path", req.URL.Path, "attempt", attempt+1, "error", err)
+		} else {
+			r.logger.Warn("Retrying idempotent admin request", "path", req.URL.Path, "attempt", attempt+1, "status", resp.StatusCode)
+			previousStatus = resp.StatusCode
+			_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+			_ = resp.Body.Close()
+		}
+	}
+}
+
+// attempt sends req once, bounded by attemptTimeout. The timeout stays in
+// force until the response body is closed.
+func (r *idempotentRetryRoundTripper) attempt(req *http.Request) (*http.Response, error) {
+	ctx, cancel := context.WithTimeout(req.Context(), r.attemptTimeout)
+	resp, err := r.rt.RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+// cancelOnClose cancels the context of a request when its response body is
+// closed.
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelOnClose) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
+// shouldRetry decides whether an attempt that returned resp or err is
+// repeated. previousStatus is the status of the attempt before, or 0.
+func (r *idempotentRetryRoundTripper) shouldRetry(ctx context.Context, resp *http.Response, err error, previousStatus int) bool {
+	switch {
+	case err != nil:
+		return ctx.Err() == nil
+	case resp.StatusCode == http.StatusServiceUnavailable:
+		return true
+	case resp.StatusCode >= 500:
+		return resp.StatusCode != previousStatus
+	}
+	return false
+}
+
+// wait sleeps for a random duration up to baseDelay*2^(attempt-1), or until
+// ctx is done.
+func (r *idempotentRetryRoundTripper) wait(ctx context.Context, attempt int) error {
+	limit := r.baseDelay << (attempt - 1)
+	if limit <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(rand.N(limit))
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,256 @@
+package prometheus
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/giantswarm/mcp-prometheus/internal/server"
+)
+
+// statusSequence serves the given statuses in order, repeating the last one,
+// and counts the requests.
+func statusSequence(t *testing.T, requests *atomic.Int32, statuses ...int) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(requests.Add(1))
+		w.WriteHeader(statuses[min(n, len(statuses))-1])
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func newTestRetryClient() *http.Client {
+	rt := newIdempotentRetryRoundTripper(http.DefaultTransport, discardLogger())
+	rt.baseDelay = time.Millisecond
+	return &http.Client{Transport: rt}
+}
+
+func TestIdempotentRetryRoundTripper(t *testing.T) {
+	tests := []struct {
+		name         string
+		method       string
+		path         string
+		statuses     []int
+		wantStatus   int
+		wantRequests int32
+	}{
+		{
+			name:         "503 twice then success",
+			method:       http.MethodPost,
+			path:         "/api/v1/admin/tsdb/clean_tombstones",
+			statuses:     []int{http.StatusServiceUnavailable, http.StatusServiceUnavailable, http.StatusOK},
+			wantStatus:   http.StatusOK,
+			wantRequests: 3,
+		},
+		{
+			name:         "gives up after three retries",
+			method:       http.MethodPost,
+			path:         "/-/reload",
+			statuses:     []int{http.StatusServiceUnavailable},
+			wantStatus:   http.StatusServiceUnavailable,
+			wantRequests: 4,
+		},
+		{
+			name:         "transient 500 resolves to success",
+			method:       http.MethodPost,
+			path:         "/api/v1/admin/tsdb/clean_tombstones",
+			statuses:     []int{http.StatusInternalServerError, http.StatusNoContent},
+			wantStatus:   http.StatusNoContent,
+			wantRequests: 2,
+		},
+		{
+			name:         "persistent 500 is returned once confirmed",
+			method:       http.MethodPost,
+			path:         "/api/v1/admin/tsdb/clean_tombstones",
+			statuses:     []int{http.StatusInternalServerError},
+			wantStatus:   http.StatusInternalServerError,
+			wantRequests: 2,
+		},
+		{
+			name:         "path prefix",
+			method:       http.MethodPost,
+			path:         "/prometheus/api/v1/admin/tsdb/clean_tombstones",
+			statuses:     []int{http.StatusServiceUnavailable, http.StatusOK},
+			wantStatus:   http.StatusOK,
+			wantRequests: 2,
+		},
+		{
+			name:         "client errors are not retried",
+			method:       http.MethodPost,
+			path:         "/-/reload",
+			statuses:     []int{http.StatusForbidden},
+			wantStatus:   http.StatusForbidden,
+			wantRequests: 1,
+		},
+		{
+			name:         "other endpoints are not retried",
+			method:       http.MethodPost,
+			path:         "/api/v1/admin/tsdb/delete_series",
+			statuses:     []int{http.StatusServiceUnavailable, http.StatusOK},
+			wantStatus:   http.StatusServiceUnavailable,
+			wantRequests: 1,
+		},
+		{
+			name:         "snapshots are not retried",
+			method:       http.MethodPost,
+			path:         "/api/v1/admin/tsdb/snapshot",
+			statuses:     []int{http.StatusServiceUnavailable, http.StatusOK},
+			wantStatus:   http.StatusServiceUnavailable,
+			wantRequests: 1,
+		},
+		{
+			name:         "GET is not retried",
+			method:       http.MethodGet,
+			path:         "/-/reload",
+			statuses:     []int{http.StatusServiceUnavailable, http.StatusOK},
+			wantStatus:   http.StatusServiceUnavailable,
+			wantRequests: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests atomic.Int32
+			server := statusSequence(t, &requests, tt.statuses...)
+
+			req, err := http.NewRequest(tt.method, server.URL+tt.path, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp, err := newTestRetryClient().Do(req)
+			if err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+			_ = resp.Body.Close()
+
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+			if got := requests.Load(); got != tt.wantRequests {
+				t.Errorf("requests = %d, want %d", got, tt.wantRequests)
+			}
+		})
+	}
+}
+
+func TestIdempotentRetryRoundTripperReplaysBody(t *testing.T) {
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		if len(bodies) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	resp, err := newTestRetryClient().Post(server.URL+"/-/reload", "application/x-www-form-urlencoded", strings.NewReader("reason=test"))
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	_ = resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK || len(bodies) != 2 || bodies[1] != "reason=test" {
+		t.Errorf("status %d, bodies %q; want 200 and the body sent twice", resp.StatusCode, bodies)
+	}
+}
+
+// failingRoundTripper fails the first failures requests with a connection
+// error and then succeeds.
+type failingRoundTripper struct {
+	failures int
+	calls    int
+}
+
+func (f *failingRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	f.calls++
+	if f.calls <= f.failures {
+		return nil, errors.New("connection refused")
+	}
+	return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody, Request: req}, nil
+}
+
+func TestIdempotentRetryRoundTripperConnectionError(t *testing.T) {
+	inner := &failingRoundTripper{failures: 2}
+	rt := newIdempotentRetryRoundTripper(inner, discardLogger())
+	rt.baseDelay = time.Millisecond
+
+	req, err := http.NewRequest(http.MethodPost, "http://prometheus:9090/-/reload", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := rt.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("expected success after retries, got %v", err)
+	}
+	if resp.StatusCode != http.StatusOK || inner.calls != 3 {
+		t.Errorf("status %d after %d calls, want 200 after 3", resp.StatusCode, inner.calls)
+	}
+}
+
+func TestReloadRetriesBeyondRequestTimeout(t *testing.T) {
+	// Each attempt fits in the request timeout, but together with the
+	// backoff the retries outlast it.
+	defer func(timeout, delay time.Duration) {
+		httpRequestTimeout, idempotentRetryBaseDelay = timeout, delay
+	}(httpRequestTimeout, idempotentRetryBaseDelay)
+	httpRequestTimeout, idempotentRetryBaseDelay = 200*time.Millisecond, 10*time.Millisecond
+
+	var requests atomic.Int32
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(100 * time.Millisecond)
+		if requests.Add(1) < 4 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer mockServer.Close()
+
+	client, err := NewClient(server.PrometheusConfig{URL: mockServer.URL}, discardLogger())
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	if err := client.Reload(context.Background()); err != nil {
+		t.Fatalf("Reload: %v", err)
+	}
+	if got := requests.Load(); got != 4 {
+		t.Errorf("made %d requests, want 4", got)
+	}
+}
+
+func TestIdempotentRetryRoundTripperAttemptTimeout(t *testing.T) {
+	var requests atomic.Int32
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) == 1 {
+			<-r.Context().Done()
+			return
+		}
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer mockServer.Close()
+
+	rt := newIdempotentRetryRoundTripper(http.DefaultTransport, discardLogger())
+	rt.baseDelay, rt.attemptTimeout = time.Millisecond, 100*time.Millisecond
+
+	req, err := http.NewRequest(http.MethodPost, mockServer.URL+"/-/reload", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := rt.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("expected the hung attempt to be retried, got %v", err)
+	}
+	body, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil || string(body) != "ok" {
+		t.Errorf("body = %q, %v; want ok", body, err)
+	}
+	if got := requests.Load(); got != 2 {
+		t.Errorf("made %d requests, want 2", got)
+	}
+}
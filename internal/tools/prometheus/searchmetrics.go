@@ -0,0 +1,291 @@
+package prometheus
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	v1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/prometheus/common/model"
+
+	"github.com/giantswarm/mcp-prometheus/internal/filter"
+	"github.com/giantswarm/mcp-prometheus/internal/promql"
+	"github.com/giantswarm/mcp-prometheus/internal/server"
+)
+
+// toolSearchMetrics is the registered name of the fuzzy metric search tool.
+const toolSearchMetrics = "search_metrics"
+
+const (
+	// defaultSearchLimit is the number of matches search_metrics returns
+	// when no limit is given.
+	defaultSearchLimit = 20
+
+	// maxSearchLimit caps the limit parameter of search_metrics.
+	maxSearchLimit = 200
+)
+
+// searchSynonyms maps search words to the words metric names and help
+// strings commonly use for the same thing, e.g. latency is exposed as
+// *_duration_seconds.
+var searchSynonyms = map[string][]string{
+	"latency":    {"duration", "seconds", "time"},
+	"duration":   {"latency", "seconds"},
+	"error":      {"errors", "failed", "failure", "failures"},
+	"failure":    {"failed", "error", "errors"},
+	"memory":     {"mem", "rss", "heap"},
+	"cpu":        {"processor", "cores"},
+	"disk":       {"filesystem", "storage", "fs"},
+	"network":    {"net", "transmit", "receive"},
+	"traffic":    {"bytes", "requests"},
+	"restart":    {"restarts", "restarted"},
+	"uptime":     {"start", "boot"},
+	"size":       {"bytes", "length"},
+	"connection": {"conns", "connections"},
+	"request":    {"requests", "http"},
+}
+
+// synonymsOf returns the synonyms of word, looking up plurals by their
+// singular.
+func synonymsOf(word string) []string {
+	if synonyms, ok := searchSynonyms[word]; ok {
+		return synonyms
+	}
+	return searchSynonyms[strings.TrimSuffix(word, "s")]
+}
+
+// metricMatch is a metric matching at least one search word.
+type metricMatch struct {
+	Name string
+	Type string
+	Help string
+	// Matched is the number of search words found in the name or help text.
+	Matched int
+	// Score weighs how closely the words matched; see scoreMetric.
+	Score int
+	// Reasons lists how each matched word was found, e.g. "ingress" or
+	// "latency→duration".
+	Reasons []string
+}
+
+// metricTokens splits a metric name into its lowercased words.
+func metricTokens(name string) []string {
+	return strings.FieldsFunc(strings.ToLower(name), func(r rune) bool { return r == '_' || r == ':' })
+}
+
+// textWords splits text into its lowercased words.
+func textWords(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !('a' <= r && r <= 'z' || '0' <= r && r <= '9')
+	})
+}
+
+// termScore returns how closely term matches a metric: 4 for a word of the
+// name, 3 for a prefix of one (or a word that is a prefix of term, e.g.
+// "request" for "requests"), 2 for a substring of the name or a word one
+// edit away from a term of five or more letters, 1 for a word of the help
+// text, 0 otherwise.
+func termScore(term, name string, tokens, helpWords []string) int {
+	score := 0
+	for _, t := range tokens {
+		switch {
+		case t == term:
+			return 4
+		case len(term) >= 3 && strings.HasPrefix(t, term), len(t) >= 4 && strings.HasPrefix(term, t):
+			score = max(score, 3)
+		case len(term) >= 5 && editDistance(t, term) <= 1:
+			score = max(score, 2)
+		}
+	}
+	if score == 0 && len(term) >= 3 && strings.Contains(strings.ToLower(name), term) {
+		score = 2
+	}
+	if score == 0 && slices.ContainsFunc(helpWords, func(w string) bool { return w == term || len(term) >= 4 && strings.HasPrefix(w, term) }) {
+		score = 1
+	}
+	return score
+}
+
+// scoreMetric scores a metric against the search words. Each word counts
+// with its best termScore, or that of one of its synonyms less one.
+func scoreMetric(name, help string, words []string) metricMatch {
+	m := metricMatch{Name: name, Help: help}
+	tokens, helpWords := metricTokens(name), textWords(help)
+	for _, word := range words {
+		best, reason := termScore(word, name, tokens, helpWords), word
+		for _, synonym := range synonymsOf(word) {
+			if s := termScore(synonym, name, tokens, helpWords) - 1; s > best {
+				best, reason = s, word+"→"+synonym
+			}
+		}
+		if best > 0 {
+			m.Matched++
+			m.Score += best
+			m.Reasons = append(m.Reasons, reason)
+		}
+	}
+	return m
+}
+
+// metadataFor returns the metadata of name, falling back to that of its
+// histogram or summary family for _bucket, _sum and _count series.
+func metadataFor(metadata map[string][]v1.Metadata, name string) (v1.Metadata, bool) {
+	if md := metadata[name]; len(md) > 0 {
+		return md[0], true
+	}
+	for _, suffix := range []string{"_bucket", "_sum", "_count"} {
+		if base, ok := strings.CutSuffix(name, suffix); ok {
+			if md := metadata[base]; len(md) > 0 {
+				return md[0], true
+			}
+		}
+	}
+	return v1.Metadata{}, false
+}
+
+// searchMetrics ranks names against words: by the number of words matched,
+// then by score, then shorter names first. A non-empty metricType keeps
+// metrics of that type only, inferring the type from the name for metrics
+// without metadata.
+func searchMetrics(names []string, metadata map[string][]v1.Metadata, words []string, metricType string) []metricMatch {
+	var matches []metricMatch
+	for _, name := range names {
+		md, ok := metadataFor(metadata, name)
+		typ := string(md.Type)
+		if !ok {
+			typ = promql.InferMetricType(name)
+		}
+		if metricType != "" && !strings.EqualFold(typ, metricType) {
+			continue
+		}
+		m := scoreMetric(name, md.Help, words)
+		if m.Matched == 0 {
+			continue
+		}
+		m.Type = typ
+		matches = append(matches, m)
+	}
+	slices.SortFunc(matches, func(a, b metricMatch) int {
+		return cmp.Or(
+			cmp.Compare(b.Matched, a.Matched),
+			cmp.Compare(b.Score, a.Score),
+			cmp.Compare(len(a.Name), len(b.Name)),
+			strings.Compare(a.Name, b.Name),
+		)
+	})
+	return matches
+}
+
+// editDistance returns the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+// handleSearchMetrics handles the search_metrics tool
+func handleSearchMetrics(ctx context.Context, request mcp.CallToolRequest, client *Client, sc *server.ServerContext) (*mcp.CallToolResult, error) {
+	params := extractParams(request)
+
+	words := textWords(getStringParam(params, "query"))
+	if len(words) == 0 {
+		return &mcp.CallToolResult{
+			IsError: true,
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: contentTypeText,
+					Text: "Error: query parameter is required and must contain at least one word",
+				},
+			},
+		}, nil
+	}
+	limit := defaultSearchLimit
+	if raw := getStringParam(params, "limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 || n > maxSearchLimit {
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{
+					mcp.TextContent{
+						Type: contentTypeText,
+						Text: fmt.Sprintf("Error: limit must be between 1 and %d, got %q", maxSearchLimit, raw),
+					},
+				},
+			}, nil
+		}
+		limit = n
+	}
+	metricType := getStringParam(params, "metric_type")
+
+	sc.Logger().Debug("Searching metrics", "words", words, "metric_type", metricType, "limit", limit)
+
+	names, err := client.ListLabelValues(ctx, model.MetricNameLabel, LabelOptions{})
+	if err != nil {
+		sc.Logger().Error("Failed to list metric names", "error", err)
+		return &mcp.CallToolResult{
+			IsError: true,
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: contentTypeText,
+					Text: fmt.Sprintf("Error listing metric names: %v", err),
+				},
+			},
+		}, nil
+	}
+	// Help text only improves the ranking; names are searched without it.
+	metadata, mdErr := client.ListMetricMetadata(ctx)
+	if mdErr != nil {
+		sc.Logger().Warn("Failed to get metric metadata, searching names only", "error", mdErr)
+	}
+
+	candidates, _ := filter.Exclude(names.LabelValues, sc.ExcludedMetrics())
+	matches := searchMetrics(candidates, metadata, words, metricType)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Found %d of %d metrics matching %q", len(matches), len(candidates), strings.Join(words, " "))
+	if metricType != "" {
+		fmt.Fprintf(&b, " (type %s)", metricType)
+	}
+	b.WriteString("\n")
+	if len(matches) > limit {
+		fmt.Fprintf(&b, "Showing the best %d; raise limit or add words to see more.\n", limit)
+		matches = matches[:limit]
+	}
+	if mdErr != nil {
+		b.WriteString("Metadata unavailable: matched metric names only.\n")
+	}
+	for i, m := range matches {
+		fmt.Fprintf(&b, "\n%d. %s (%s)", i+1, m.Name, m.Type)
+		if m.Help != "" {
+			fmt.Fprintf(&b, " — %s", m.Help)
+		}
+		fmt.Fprintf(&b, "\n   matched: %s\n", strings.Join(m.Reasons, ", "))
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.TextContent{
+				Type: contentTypeText,
+				Text: b.String(),
+			},
+		},
+	}, nil
+}
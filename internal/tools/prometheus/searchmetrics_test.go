@@ -0,0 +1,160 @@
+package prometheus
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	v1 "github.com/prometheus/client_golang/api/prometheus/v1"
+
+	"github.com/giantswarm/mcp-prometheus/internal/server"
+)
+
+// searchFixtureNames are the metric names of the search_metrics fixtures.
+var searchFixtureNames = []string{
+	"go_gc_duration_seconds",
+	"http_request_duration_seconds_bucket",
+	"nginx_ingress_controller_request_duration_seconds_bucket",
+	"nginx_ingress_controller_requests",
+	"node_disk_written_bytes_total",
+	"node_memory_MemAvailable_bytes",
+	"process_resident_memory_bytes",
+	"up",
+}
+
+// searchFixtureMetadata is the metadata of the search_metrics fixtures;
+// histogram metadata is keyed by the family name.
+var searchFixtureMetadata = map[string][]v1.Metadata{
+	"nginx_ingress_controller_request_duration_seconds": {{Type: "histogram", Help: "The request processing time in milliseconds"}},
+	"nginx_ingress_controller_requests":                 {{Type: "counter", Help: "The total number of client requests"}},
+	"node_disk_written_bytes_total":                     {{Type: "counter", Help: "The total number of bytes written successfully."}},
+	"process_resident_memory_bytes":                     {{Type: "gauge", Help: "Resident memory size in bytes."}},
+}
+
+func TestSearchMetrics(t *testing.T) {
+	tests := []struct {
+		query      string
+		metricType string
+		wantFirst  string
+		wantReason string
+	}{
+		{"latency ingress", "", "nginx_ingress_controller_request_duration_seconds_bucket", "latency→duration"},
+		{"ingres requests", "", "nginx_ingress_controller_requests", "ingres"},
+		{"disk writen", "", "node_disk_written_bytes_total", "writen"},
+		{"memory available", "gauge", "node_memory_MemAvailable_bytes", "available"},
+		{"resident", "", "process_resident_memory_bytes", "resident"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			matches := searchMetrics(searchFixtureNames, searchFixtureMetadata, textWords(tt.query), tt.metricType)
+			if len(matches) == 0 {
+				t.Fatalf("no matches for %q", tt.query)
+			}
+			if first := matches[0]; first.Name != tt.wantFirst || !strings.Contains(strings.Join(first.Reasons, ","), tt.wantReason) {
+				t.Errorf("best match for %q = %+v, want %s matched by %s", tt.query, first, tt.wantFirst, tt.wantReason)
+			}
+		})
+	}
+
+	if matches := searchMetrics(searchFixtureNames, searchFixtureMetadata, []string{"memory"}, "counter"); len(matches) != 0 {
+		t.Errorf("expected no counters for memory, got %+v", matches)
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	for _, tt := range []struct {
+		a, b string
+		want int
+	}{
+		{"latency", "latency", 0},
+		{"latncy", "latency", 1},
+		{"ingres", "ingress", 1},
+		{"", "abc", 3},
+		{"kitten", "sitting", 3},
+	} {
+		if got := editDistance(tt.a, tt.b); got != tt.want {
+			t.Errorf("editDistance(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestHandleSearchMetrics(t *testing.T) {
+	metadataStatus := http.StatusOK
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		var data any
+		switch r.URL.Path {
+		case "/api/v1/label/__name__/values":
+			data = searchFixtureNames
+		case "/api/v1/metadata":
+			if metadataStatus != http.StatusOK {
+				w.WriteHeader(metadataStatus)
+				return
+			}
+			data = searchFixtureMetadata
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{respKeyStatus: respValSuccess, respKeyData: data})
+	}))
+	defer mockServer.Close()
+
+	ctx := context.Background()
+	sc, err := server.NewServerContext(ctx,
+		server.WithPrometheusConfig(server.PrometheusConfig{URL: mockServer.URL}),
+		server.WithSlogLogger(discardLogger()),
+		server.WithExcludedMetrics([]string{"go_*"}),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create server context: %v", err)
+	}
+	defer func() { _ = sc.Shutdown() }()
+
+	client, err := NewClient(sc.PrometheusConfig(), sc.Logger())
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+
+	call := func(args map[string]any) (*mcp.CallToolResult, string) {
+		t.Helper()
+		request := mcp.CallToolRequest{Params: mcp.CallToolParams{Name: toolSearchMetrics, Arguments: args}}
+		result, err := handleSearchMetrics(ctx, request, client, sc)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return result, result.Content[0].(mcp.TextContent).Text
+	}
+
+	result, text := call(map[string]any{"query": "latency ingress", "limit": "1"})
+	if result.IsError {
+		t.Fatalf("expected success, got error: %s", text)
+	}
+	for _, want := range []string{
+		`Found 3 of 7 metrics matching "latency ingress"`,
+		"Showing the best 1;",
+		"1. nginx_ingress_controller_request_duration_seconds_bucket (histogram) — The request processing time in milliseconds\n   matched: latency→duration, ingress\n",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("output lacks %q:\n%s", want, text)
+		}
+	}
+	if strings.Contains(text, "go_gc_duration_seconds") {
+		t.Errorf("excluded metric listed:\n%s", text)
+	}
+
+	metadataStatus = http.StatusNotFound
+	if result, text := call(map[string]any{"query": "ingress"}); result.IsError || !strings.Contains(text, "Metadata unavailable") {
+		t.Errorf("expected a names-only search, got:\n%s", text)
+	}
+
+	for _, args := range []map[string]any{{"query": " "}, {"query": "up", "limit": "0"}} {
+		if result, _ := call(args); !result.IsError {
+			t.Errorf("expected an error for %v", args)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package prometheus
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/prometheus/common/model"
+
+	"github.com/giantswarm/mcp-prometheus/internal/format"
+	"github.com/giantswarm/mcp-prometheus/internal/server"
+)
+
+const (
+	// activitySeriesLimit caps the series find_series charts with
+	// show_activity_range, each of which costs one range query.
+	activitySeriesLimit = 10
+
+	// activityBarWidth is the number of cells of an activity bar.
+	activityBarWidth = 40
+)
+
+// activityStep returns the range query step that gives one sample per
+// activity bar cell, at least one second.
+func activityStep(start, end time.Time) model.Duration {
+	return model.Duration(max(end.Sub(start)/activityBarWidth, time.Second))
+}
+
+// formatSeriesActivity lists up to activitySeriesLimit series, each with an
+// activity bar from a count() range query over its exact label set. A series
+// whose query fails is listed with the error instead of a bar; the listing
+// stops when ctx is cancelled or the query policy refuses a query.
+func formatSeriesActivity(ctx context.Context, client *Client, sc *server.ServerContext, series []map[string]string, start, end time.Time) string {
+	startParam := start.UTC().Format(time.RFC3339Nano)
+	endParam := end.UTC().Format(time.RFC3339Nano)
+	// Like a computed range query step, the step is raised to the minimum
+	// of the query policy rather than refused.
+	stepDuration := max(time.Duration(activityStep(start, end)), sc.QueryPolicy().MinStep)
+	step := model.Duration(stepDuration).String()
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Found %d series, activity from %s to %s (█ active, ░ no samples):\n", len(series), startParam, endParam)
+	for i, s := range series {
+		if i >= activitySeriesLimit {
+			fmt.Fprintf(&b, "... and %d more series; activity is shown for the first %d\n", len(series)-activitySeriesLimit, activitySeriesLimit)
+			break
+		}
+
+		selector := formatSeriesLabels("", s)
+		query := "count(" + selector + ")"
+		if refused := checkQueryPolicy(sc, query, start, end, stepDuration); refused != nil {
+			fmt.Fprintf(&b, "... stopped: %s", strings.TrimPrefix(resultText(refused), "Error: "))
+			break
+		}
+
+		result, err := client.ExecuteRangeQuery(ctx, query, startParam, endParam, step)
+		if err != nil {
+			if ctx.Err() != nil {
+				fmt.Fprintf(&b, "... stopped: %v\n", ctx.Err())
+				break
+			}
+			sc.Logger().Warn("Failed to query series activity", "series", selector, "error", err)
+			fmt.Fprintf(&b, "%d. %s (activity unavailable: %v)\n", i+1, selector, err)
+			continue
+		}
+		matrix, _ := result.Result.(model.Matrix)
+		fmt.Fprintf(&b, "%d. %s %s\n", i+1, format.BuildActivityBar(matrix, start, end, activityBarWidth), selector)
+	}
+	return b.String()
+}
@@ -0,0 +1,172 @@
+package prometheus
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/giantswarm/mcp-prometheus/internal/server"
+)
+
+func TestActivityStep(t *testing.T) {
+	start := time.Unix(0, 0)
+	if got := activityStep(start, start.Add(40*time.Minute)).String(); got != "1m" {
+		t.Errorf("activityStep(40m) = %s, want 1m", got)
+	}
+	if got := activityStep(start, start.Add(10*time.Second)).String(); got != "1s" {
+		t.Errorf("activityStep(10s) = %s, want 1s", got)
+	}
+}
+
+func TestHandleFindSeriesShowActivityRange(t *testing.T) {
+	var series []map[string]string
+	for i := range activitySeriesLimit + 2 {
+		series = append(series, map[string]string{"__name__": "up", "instance": fmt.Sprintf("host-%d", i)})
+	}
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	var (
+		mu      sync.Mutex
+		queries []string
+	)
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/series":
+			_ = json.NewEncoder(w).Encode(map[string]any{respKeyStatus: respValSuccess, respKeyData: series})
+		case "/api/v1/query_range":
+			query := r.FormValue(paramKeyQuery)
+			mu.Lock()
+			queries = append(queries, query)
+			mu.Unlock()
+
+			var values []any
+			switch {
+			case strings.Contains(query, `"host-0"`):
+				// Active during the first half of the hour.
+				for m := 0; m < 30; m++ {
+					values = append(values, []any{float64(start.Add(time.Duration(m) * time.Minute).Unix()), "1"})
+				}
+			case strings.Contains(query, `"host-1"`):
+				w.WriteHeader(http.StatusUnprocessableEntity)
+				_, _ = w.Write([]byte(`{"status":"error","errorType":"execution","error":"query timed out"}`))
+				return
+			}
+			result := []any{}
+			if values != nil {
+				result = append(result, map[string]any{"metric": map[string]string{}, "values": values})
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				respKeyStatus: respValSuccess,
+				respKeyData:   map[string]any{respKeyResultType: "matrix", respKeyResult: result},
+			})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer mockServer.Close()
+
+	ctx := context.Background()
+	sc, err := server.NewServerContext(ctx,
+		server.WithPrometheusConfig(server.PrometheusConfig{URL: mockServer.URL}),
+		server.WithSlogLogger(discardLogger()),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create server context: %v", err)
+	}
+	defer func() { _ = sc.Shutdown() }()
+
+	client, err := NewClient(sc.PrometheusConfig(), sc.Logger())
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+
+	run := func(args map[string]any) *mcp.CallToolResult {
+		t.Helper()
+		args["matches"] = []any{"up"}
+		args["show_activity_range"] = "true"
+		request := mcp.CallToolRequest{Params: mcp.CallToolParams{Name: "find_series", Arguments: args}}
+		result, err := handleFindSeries(ctx, request, client, sc)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return result
+	}
+
+	result := run(map[string]any{"start_time": "2024-01-01T00:00:00Z", "end_time": "2024-01-01T01:00:00Z"})
+	text := result.Content[0].(mcp.TextContent).Text
+	if result.IsError {
+		t.Fatalf("expected success, got error: %s", text)
+	}
+
+	for _, want := range []string{
+		"Found 12 series, activity from 2024-01-01T00:00:00Z to 2024-01-01T01:00:00Z",
+		"1. [" + strings.Repeat("█", 20) + strings.Repeat("░", 20) + `] {__name__="up", instance="host-0"}`,
+		`2. {__name__="up", instance="host-1"} (activity unavailable:`,
+		"3. [" + strings.Repeat("░", 40) + `] {__name__="up", instance="host-2"}`,
+		"... and 2 more series; activity is shown for the first 10",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("output missing %q:\n%s", want, text)
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(queries) != activitySeriesLimit {
+		t.Errorf("ran %d range queries, want %d", len(queries), activitySeriesLimit)
+	}
+	if want := `count({__name__="up", instance="host-0"})`; len(queries) == 0 || queries[0] != want {
+		t.Errorf("first query = %v, want %q", queries, want)
+	}
+}
+
+func TestHandleFindSeriesShowActivityRangeInvalid(t *testing.T) {
+	ctx := context.Background()
+	sc, err := server.NewServerContext(ctx,
+		server.WithPrometheusConfig(server.PrometheusConfig{URL: "http://localhost:9090"}),
+		server.WithSlogLogger(discardLogger()),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create server context: %v", err)
+	}
+	defer func() { _ = sc.Shutdown() }()
+
+	client, err := NewClient(sc.PrometheusConfig(), sc.Logger())
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		args    map[string]any
+		wantErr string
+	}{
+		{name: "missing range", args: map[string]any{}, wantErr: "requires start_time and end_time"},
+		{name: "missing end", args: map[string]any{"start_time": "2024-01-01T00:00:00Z"}, wantErr: "requires start_time and end_time"},
+		{name: "invalid start", args: map[string]any{"start_time": "soon", "end_time": "2024-01-01T00:00:00Z"}, wantErr: "invalid time parameter"},
+		{name: "reversed range", args: map[string]any{"start_time": "2024-01-02T00:00:00Z", "end_time": "2024-01-01T00:00:00Z"}, wantErr: "end_time must be after start_time"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.args["matches"] = []any{"up"}
+			tt.args["show_activity_range"] = "true"
+			request := mcp.CallToolRequest{Params: mcp.CallToolParams{Name: "find_series", Arguments: tt.args}}
+			result, err := handleFindSeries(ctx, request, client, sc)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			text := result.Content[0].(mcp.TextContent).Text
+			if !result.IsError || !strings.Contains(text, tt.wantErr) {
+				t.Errorf("got %q, want error containing %q", text, tt.wantErr)
+			}
+		})
+	}
+}
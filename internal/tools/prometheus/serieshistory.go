@@ -0,0 +1,200 @@
+package prometheus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/prometheus/common/model"
+
+	"github.com/giantswarm/mcp-prometheus/internal/format"
+	"github.com/giantswarm/mcp-prometheus/internal/promql"
+	"github.com/giantswarm/mcp-prometheus/internal/server"
+)
+
+const (
+	// toolGetSeriesCountHistory is the registered name of the series count
+	// history tool.
+	toolGetSeriesCountHistory = "get_series_count_history"
+
+	// allSeriesSelector matches every series when no matches are given.
+	allSeriesSelector = `{__name__=~".+"}`
+
+	// scrapedSamplesQuery totals the samples ingested per scrape.
+	scrapedSamplesQuery = "sum(scrape_samples_scraped)"
+)
+
+// seriesCountQuery counts the series matched by any of the selectors.
+func seriesCountQuery(matches []string) string {
+	if len(matches) == 0 {
+		return "count(" + allSeriesSelector + ")"
+	}
+	return "count(" + strings.Join(matches, " or ") + ")"
+}
+
+// stepValues places the samples of a single-series matrix on the step grid
+// starting at start. Steps without a sample are NaN.
+func stepValues(matrix model.Matrix, start time.Time, step time.Duration, steps int) []float64 {
+	values := make([]float64, steps)
+	for i := range values {
+		values[i] = math.NaN()
+	}
+	if len(matrix) == 0 {
+		return values
+	}
+	for _, p := range matrix[0].Values {
+		i := int(p.Timestamp.Time().Sub(start) / step)
+		if i >= 0 && i < steps {
+			values[i] = float64(p.Value)
+		}
+	}
+	return values
+}
+
+// formatHistoryRow renders one chart row: label, sparkline and statistics.
+func formatHistoryRow(label string, values []float64) string {
+	lo, hi, sum, n := math.Inf(1), math.Inf(-1), 0.0, 0
+	first, last := math.NaN(), math.NaN()
+	for _, v := range values {
+		if math.IsNaN(v) {
+			continue
+		}
+		if n == 0 {
+			first = v
+		}
+		lo, hi, sum, last = min(lo, v), max(hi, v), sum+v, v
+		n++
+	}
+	if n == 0 {
+		return fmt.Sprintf("%-16s (no data)\n", label)
+	}
+
+	change := fmt.Sprintf("%+.0f", last-first)
+	if first != 0 {
+		change += fmt.Sprintf(" (%+.1f%%)", (last-first)/first*100)
+	}
+	return fmt.Sprintf("%-16s %s  min: %.0f  max: %.0f  mean: %.1f  change: %s\n",
+		label, format.Sparkline(values), lo, hi, sum/float64(n), change)
+}
+
+// handleGetSeriesCountHistory handles the get_series_count_history tool
+func handleGetSeriesCountHistory(ctx context.Context, request mcp.CallToolRequest, client *Client, sc *server.ServerContext) (*mcp.CallToolResult, error) {
+	params := extractParams(request)
+
+	for _, key := range []string{"start", "end", "step"} {
+		if getStringParam(params, key) == "" {
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{
+					mcp.TextContent{
+						Type: contentTypeText,
+						Text: fmt.Sprintf("Error: %s parameter is required and must be a string", key),
+					},
+				},
+			}, nil
+		}
+	}
+
+	start := getStringParam(params, "start")
+	end := getStringParam(params, "end")
+	step := getStringParam(params, "step")
+
+	startTime, startErr := parseTimeParam(start)
+	endTime, endErr := parseTimeParam(end)
+	stepDuration, stepErr := model.ParseDuration(step)
+	if err := errors.Join(startErr, endErr, stepErr); err != nil {
+		return &mcp.CallToolResult{
+			IsError: true,
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: contentTypeText,
+					Text: fmt.Sprintf("Error: %v", err),
+				},
+			},
+		}, nil
+	}
+	if stepDuration <= 0 || !endTime.After(startTime) {
+		return &mcp.CallToolResult{
+			IsError: true,
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: contentTypeText,
+					Text: "Error: step must be positive and end must be after start",
+				},
+			},
+		}, nil
+	}
+
+	query := seriesCountQuery(extractStringArray(params, "matches"))
+	if _, err := promql.Parse(query); err != nil {
+		return &mcp.CallToolResult{
+			IsError: true,
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: contentTypeText,
+					Text: fmt.Sprintf("Error: invalid matches: %v", err),
+				},
+			},
+		}, nil
+	}
+
+	if refused := checkQueryPolicy(sc, query, startTime, endTime, time.Duration(stepDuration)); refused != nil {
+		return refused, nil
+	}
+	// Without matches the query counts every series in the TSDB.
+	steps := int(endTime.Sub(startTime)/time.Duration(stepDuration)) + 1
+	costWarning, refused := checkQueryCost(ctx, client, sc, query, startTime, endTime, steps)
+	if refused != nil {
+		return refused, nil
+	}
+
+	sc.Logger().Debug("Getting series count history", "query", query, "start", start, "end", end, "step", step)
+
+	seriesResult, err := client.ExecuteRangeQuery(ctx, query, start, end, step)
+	if err != nil {
+		sc.Logger().Error("Failed to query series count", "error", err)
+		return &mcp.CallToolResult{
+			IsError: true,
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: contentTypeText,
+					Text: describeQueryError("Error querying series count", err),
+				},
+			},
+		}, nil
+	}
+	seriesMatrix, _ := seriesResult.Result.(model.Matrix)
+
+	// Scraped samples are context only; Mimir tenants fed by remote write
+	// have no scrape_samples_scraped, so a failure leaves the row empty.
+	var samplesMatrix model.Matrix
+	if samplesResult, err := client.ExecuteRangeQuery(ctx, scrapedSamplesQuery, start, end, step); err != nil {
+		sc.Logger().Warn("Failed to query scraped samples", "error", err)
+	} else {
+		samplesMatrix, _ = samplesResult.Result.(model.Matrix)
+	}
+
+	evalStart := model.TimeFromUnixNano(startTime.UnixNano())
+
+	var b strings.Builder
+	if costWarning != "" {
+		b.WriteString(costWarning + "\n")
+	}
+	fmt.Fprintf(&b, "Series count history: %s\n", query)
+	fmt.Fprintf(&b, "%d steps of %s from %s to %s\n\n", steps, stepDuration, formatResultTime(evalStart), formatResultTime(evalStart.Add(time.Duration(steps-1)*time.Duration(stepDuration))))
+	b.WriteString(formatHistoryRow("active series", stepValues(seriesMatrix, startTime, time.Duration(stepDuration), steps)))
+	b.WriteString(formatHistoryRow("scraped samples", stepValues(samplesMatrix, startTime, time.Duration(stepDuration), steps)))
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.TextContent{
+				Type: contentTypeText,
+				Text: b.String(),
+			},
+		},
+	}, nil
+}
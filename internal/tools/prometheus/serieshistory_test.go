@@ -0,0 +1,158 @@
+package prometheus
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/giantswarm/mcp-prometheus/internal/server"
+)
+
+// seriesHistoryStart is 2024-01-15T00:00:00Z as Unix seconds.
+const seriesHistoryStart = 1705276800
+
+// stepMatrix builds a single-series query_range matrix with one sample per
+// hour starting at seriesHistoryStart. Empty values leave a gap.
+func stepMatrix(values ...string) map[string]any {
+	var pairs []any
+	for i, v := range values {
+		if v != "" {
+			pairs = append(pairs, []any{seriesHistoryStart + i*3600, v})
+		}
+	}
+	return map[string]any{respKeyResultType: "matrix", respKeyResult: []any{
+		map[string]any{"metric": map[string]string{}, "values": pairs},
+	}}
+}
+
+func runSeriesCountHistory(t *testing.T, handler http.HandlerFunc, args map[string]any) *mcp.CallToolResult {
+	t.Helper()
+	mockServer := httptest.NewServer(handler)
+	defer mockServer.Close()
+
+	ctx := context.Background()
+	sc, err := server.NewServerContext(ctx,
+		server.WithPrometheusConfig(server.PrometheusConfig{URL: mockServer.URL}),
+		server.WithSlogLogger(discardLogger()),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create server context: %v", err)
+	}
+	defer func() { _ = sc.Shutdown() }()
+
+	client, err := NewClient(sc.PrometheusConfig(), sc.Logger())
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+
+	request := mcp.CallToolRequest{Params: mcp.CallToolParams{Name: toolGetSeriesCountHistory, Arguments: args}}
+	result, err := handleGetSeriesCountHistory(ctx, request, client, sc)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return result
+}
+
+func TestHandleGetSeriesCountHistory(t *testing.T) {
+	var queries []string
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		query := r.Form.Get(paramKeyQuery)
+		queries = append(queries, query)
+		var data map[string]any
+		if query == scrapedSamplesQuery {
+			data = stepMatrix("1000", "1000", "3000", "3000")
+		} else {
+			data = stepMatrix("100", "100", "", "300")
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{respKeyStatus: respValSuccess, respKeyData: data})
+	}
+
+	result := runSeriesCountHistory(t, handler, map[string]any{
+		"start":   "2024-01-15T00:00:00Z",
+		"end":     "2024-01-15T03:00:00Z",
+		"step":    "1h",
+		"matches": []any{`{job="node"}`, "up"},
+	})
+	if result.IsError {
+		t.Fatalf("expected success, got error: %v", result.Content)
+	}
+	text := result.Content[0].(mcp.TextContent).Text
+
+	wantQuery := `count({job="node"} or up)`
+	if len(queries) != 2 || queries[0] != wantQuery || queries[1] != scrapedSamplesQuery {
+		t.Errorf("queries = %q, want [%q %q]", queries, wantQuery, scrapedSamplesQuery)
+	}
+	for _, want := range []string{
+		"Series count history: " + wantQuery + "\n",
+		"4 steps of 1h from 2024-01-15T00:00:00.000Z to 2024-01-15T03:00:00.000Z\n",
+		"active series    ▁▁ █  min: 100  max: 300  mean: 166.7  change: +200 (+200.0%)\n",
+		"scraped samples  ▁▁██  min: 1000  max: 3000  mean: 2000.0  change: +2000 (+200.0%)\n",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("output missing %q:\n%s", want, text)
+		}
+	}
+}
+
+func TestHandleGetSeriesCountHistoryWithoutScrapeSamples(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		if r.Form.Get(paramKeyQuery) == scrapedSamplesQuery {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{respKeyStatus: respValSuccess, respKeyData: stepMatrix("5", "5")})
+	}
+
+	result := runSeriesCountHistory(t, handler, map[string]any{
+		"start": "2024-01-15T00:00:00Z",
+		"end":   "2024-01-15T01:00:00Z",
+		"step":  "1h",
+	})
+	if result.IsError {
+		t.Fatalf("expected success, got error: %v", result.Content)
+	}
+	text := result.Content[0].(mcp.TextContent).Text
+	for _, want := range []string{
+		"Series count history: count(" + allSeriesSelector + ")\n",
+		"active series    ▁▁  min: 5  max: 5  mean: 5.0  change: +0 (+0.0%)\n",
+		"scraped samples  (no data)\n",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("output missing %q:\n%s", want, text)
+		}
+	}
+}
+
+func TestHandleGetSeriesCountHistoryValidation(t *testing.T) {
+	tests := []struct {
+		name string
+		args map[string]any
+		want string
+	}{
+		{"missing step", map[string]any{"start": "2024-01-15T00:00:00Z", "end": "2024-01-15T01:00:00Z"}, "step parameter is required"},
+		{"end before start", map[string]any{"start": "2024-01-15T01:00:00Z", "end": "2024-01-15T00:00:00Z", "step": "1h"}, "end must be after start"},
+		{"invalid matcher", map[string]any{"start": "2024-01-15T00:00:00Z", "end": "2024-01-15T01:00:00Z", "step": "1h", "matches": []any{"{job="}}, "invalid matches"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := runSeriesCountHistory(t, func(w http.ResponseWriter, r *http.Request) {
+				t.Errorf("unexpected request to %s", r.URL.Path)
+			}, tt.args)
+			if !result.IsError {
+				t.Fatal("expected an error result")
+			}
+			if text := result.Content[0].(mcp.TextContent).Text; !strings.Contains(text, tt.want) {
+				t.Errorf("error %q does not contain %q", text, tt.want)
+			}
+		})
+	}
+}
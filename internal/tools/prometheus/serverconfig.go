@@ -0,0 +1,186 @@
+package prometheus
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"runtime/debug"
+	"sort"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	mcpserver "github.com/mark3labs/mcp-go/server"
+
+	"github.com/giantswarm/mcp-prometheus/internal/server"
+)
+
+// toolGetServerConfig is the registered name of the server configuration tool.
+const toolGetServerConfig = "get_server_config"
+
+// registerServerConfigTool registers get_server_config. Unlike the other
+// tools it never talks to Prometheus, so it bypasses the dynamic client
+// wrapper and takes no connection parameters.
+func registerServerConfigTool(s *mcpserver.MCPServer, sc *server.ServerContext, middleware []ToolMiddleware) {
+	tool := mcp.NewTool(toolGetServerConfig,
+		mcp.WithDescription("Describe this MCP server's Prometheus configuration (URL, org ID, auth type), version and registered tools. Use it to decide whether prometheus_url must be passed per call"),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithDestructiveHintAnnotation(false),
+		mcp.WithOpenWorldHintAnnotation(false),
+		mcp.WithSchemaAdditionalProperties(false),
+	)
+
+	h := withServerMiddleware(sc, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return handleGetServerConfig(ctx, request, s, sc)
+	})
+	for _, mw := range middleware {
+		h = mw(toolGetServerConfig, h)
+	}
+	s.AddTool(tool, h)
+}
+
+// handleGetServerConfig handles the get_server_config tool
+func handleGetServerConfig(_ context.Context, _ mcp.CallToolRequest, s *mcpserver.MCPServer, sc *server.ServerContext) (*mcp.CallToolResult, error) {
+	sc.Logger().Debug("Getting server config")
+
+	config := sc.PrometheusConfig()
+
+	var b strings.Builder
+	b.WriteString("MCP Server Configuration:\n")
+
+	b.WriteString("\nPrometheus:\n")
+	fmt.Fprintf(&b, "  URL: %s\n", valueOrNone(redactURL(config.URL)))
+	fmt.Fprintf(&b, "  URL source: %s\n", sc.PrometheusConfigSource())
+	fmt.Fprintf(&b, "  Path prefix: %s\n", valueOrNone(config.PathPrefix))
+	fmt.Fprintf(&b, "  Org ID: %s\n", valueOrNone(config.OrgID))
+	if routes := sc.TenantRouter(); len(routes) > 0 {
+		b.WriteString("  Tenant routes:\n")
+		tenants := make([]string, 0, len(routes))
+		for tenant := range routes {
+			tenants = append(tenants, tenant)
+		}
+		sort.Strings(tenants)
+		for _, tenant := range tenants {
+			fmt.Fprintf(&b, "    %s -> %s\n", tenant, redactURL(routes[tenant]))
+		}
+	}
+	if profiles := sc.Profiles(); len(profiles.Names()) > 0 {
+		b.WriteString("  Profiles:\n")
+		for _, name := range profiles.Names() {
+			profile, _ := profiles.Get(name)
+			marker := ""
+			if name == profiles.Default {
+				marker = " (default)"
+			}
+			fmt.Fprintf(&b, "    %s -> %s%s\n", name, redactURL(profile.URL), marker)
+		}
+	}
+	fmt.Fprintf(&b, "  Auth type: %s\n", config.AuthType())
+	fmt.Fprintf(&b, "  Backend type: %s\n", detectBackendType(config))
+	fmt.Fprintf(&b, "  TLS skip verify: %t\n", config.TLSSkipVerify)
+	fmt.Fprintf(&b, "  Custom CA: %t\n", config.TLSCACert != "")
+	if sc.PrometheusConfigSource() == server.ConfigSourceUnset {
+		b.WriteString("  Note: no default Prometheus URL is configured; pass prometheus_url on every call.\n")
+	}
+
+	b.WriteString("\nServer:\n")
+	fmt.Fprintf(&b, "  Version: %s\n", valueOrNone(sc.Version()))
+	fmt.Fprintf(&b, "  OAuth enabled: %t\n", sc.IsOAuthEnabled())
+	if info, ok := debug.ReadBuildInfo(); ok {
+		fmt.Fprintf(&b, "  Go version: %s\n", info.GoVersion)
+		for _, setting := range info.Settings {
+			switch setting.Key {
+			case "vcs.revision":
+				fmt.Fprintf(&b, "  Revision: %s\n", setting.Value)
+			case "vcs.time":
+				fmt.Fprintf(&b, "  Build time: %s\n", setting.Value)
+			}
+		}
+	}
+
+	b.WriteString("\nEnvironment variables:\n")
+	b.WriteString("name | value | default | description\n")
+	for _, env := range server.EnvVars() {
+		fmt.Fprintf(&b, "%s | %s | %s | %s\n", env.Name, envVarValue(env), valueOrNone(env.Default), env.Description)
+	}
+
+	tools := s.ListTools()
+	names := make([]string, 0, len(tools))
+	for name := range tools {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	fmt.Fprintf(&b, "\nRegistered tools (%d):\n", len(names))
+	for _, name := range names {
+		fmt.Fprintf(&b, "  - %s\n", name)
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.TextContent{
+				Type: contentTypeText,
+				Text: b.String(),
+			},
+		},
+	}, nil
+}
+
+// envVarValue returns the current value of env for display. Sensitive values
+// are replaced by redactedValue and URLs lose their user info.
+func envVarValue(env server.EnvVarDoc) string {
+	value := os.Getenv(env.Name)
+	switch {
+	case value == "":
+		return "(unset)"
+	case env.Sensitive:
+		return redactedValue
+	case strings.HasSuffix(env.Name, "_URL"):
+		return redactURL(value)
+	default:
+		return value
+	}
+}
+
+// detectBackendType makes a best-effort guess whether the configured
+// endpoint is Grafana Cloud (a *.grafana.net host), Mimir (multi-tenant,
+// served under /prometheus) or a plain Prometheus server.
+func detectBackendType(config server.PrometheusConfig) string {
+	if config.URL == "" {
+		return "unknown"
+	}
+	u, err := url.Parse(config.URL)
+	if err == nil && strings.HasSuffix(u.Hostname(), ".grafana.net") {
+		return "grafana-cloud"
+	}
+	if config.OrgID != "" {
+		return "mimir"
+	}
+	if err == nil && strings.HasSuffix(strings.TrimRight(u.Path, "/"), "/prometheus") {
+		return "mimir"
+	}
+	return "prometheus"
+}
+
+// redactURL strips user info from a URL so embedded credentials are never
+// reported. Unparseable URLs are returned as a placeholder.
+func redactURL(raw string) string {
+	if raw == "" {
+		return ""
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return redactedValue
+	}
+	if u.User != nil {
+		u.User = url.User(redactedValue)
+	}
+	return u.String()
+}
+
+// valueOrNone returns s, or "(none)" when s is empty.
+func valueOrNone(s string) string {
+	if s == "" {
+		return "(none)"
+	}
+	return s
+}
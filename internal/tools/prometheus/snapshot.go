@@ -0,0 +1,48 @@
+package prometheus
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/giantswarm/mcp-prometheus/internal/server"
+)
+
+// toolSnapshotTSDB is the registered name of the TSDB snapshot tool.
+const toolSnapshotTSDB = "snapshot_tsdb"
+
+// handleSnapshotTSDB handles the snapshot_tsdb tool
+func handleSnapshotTSDB(ctx context.Context, request mcp.CallToolRequest, client *Client, sc *server.ServerContext) (*mcp.CallToolResult, error) {
+	params := extractParams(request)
+	skipHead := getStringParam(params, "skip_head") == "true"
+
+	sc.Logger().Info("Creating TSDB snapshot", "skip_head", skipHead)
+
+	name, err := client.Snapshot(ctx, skipHead)
+	if err != nil {
+		sc.Logger().Error("Failed to create TSDB snapshot", "error", err)
+		return &mcp.CallToolResult{
+			IsError: true,
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: contentTypeText,
+					Text: fmt.Sprintf("Error creating TSDB snapshot: %v", err),
+				},
+			},
+		}, nil
+	}
+
+	text := fmt.Sprintf("Created TSDB snapshot %s in <data-dir>/snapshots/%s on the Prometheus server.", name, name)
+	if skipHead {
+		text += " Data still in the head block (roughly the last two hours) is not included."
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.TextContent{
+				Type: contentTypeText,
+				Text: text,
+			},
+		},
+	}, nil
+}
@@ -0,0 +1,83 @@
+package prometheus
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/giantswarm/mcp-prometheus/internal/server"
+)
+
+func TestHandleSnapshotTSDB(t *testing.T) {
+	var skipHead string
+	adminEnabled := true
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/admin/tsdb/snapshot" || r.Method != http.MethodPost {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_ = r.ParseForm()
+		skipHead = r.Form.Get("skip_head")
+		w.Header().Set("Content-Type", "application/json")
+		if !adminEnabled {
+			// A proxy in front of Prometheus blocking the admin API; 5xx
+			// responses would be retried.
+			w.WriteHeader(http.StatusForbidden)
+			_ = json.NewEncoder(w).Encode(map[string]any{respKeyStatus: "error", "errorType": "forbidden", "error": "admin API blocked"})
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{respKeyStatus: respValSuccess, respKeyData: map[string]any{"name": "20240115T100000Z-6b8d3c1f"}})
+	}))
+	defer mockServer.Close()
+
+	ctx := context.Background()
+	sc, err := server.NewServerContext(ctx,
+		server.WithPrometheusConfig(server.PrometheusConfig{URL: mockServer.URL}),
+		server.WithSlogLogger(discardLogger()),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create server context: %v", err)
+	}
+	defer func() { _ = sc.Shutdown() }()
+
+	client, err := NewClient(sc.PrometheusConfig(), sc.Logger())
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+
+	call := func(args map[string]any) *mcp.CallToolResult {
+		t.Helper()
+		request := mcp.CallToolRequest{Params: mcp.CallToolParams{Name: toolSnapshotTSDB, Arguments: args}}
+		result, err := handleSnapshotTSDB(ctx, request, client, sc)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return result
+	}
+
+	result := call(map[string]any{"skip_head": "true"})
+	if result.IsError {
+		t.Fatalf("expected success, got error: %v", result.Content)
+	}
+	text := result.Content[0].(mcp.TextContent).Text
+	if !strings.Contains(text, "<data-dir>/snapshots/20240115T100000Z-6b8d3c1f") || !strings.Contains(text, "head block") {
+		t.Errorf("unexpected output: %s", text)
+	}
+	if skipHead != "true" {
+		t.Errorf("skip_head = %q, want true", skipHead)
+	}
+
+	adminEnabled = false
+	result = call(map[string]any{})
+	if !result.IsError || !strings.Contains(result.Content[0].(mcp.TextContent).Text, "admin API blocked") {
+		t.Errorf("expected the admin API error, got %v", result.Content)
+	}
+	if skipHead != "false" {
+		t.Errorf("skip_head = %q, want false", skipHead)
+	}
+}
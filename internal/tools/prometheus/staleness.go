@@ -0,0 +1,112 @@
+package prometheus
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/common/model"
+)
+
+const (
+	// defaultStalenessDelta is how far execute_query steps back per attempt
+	// when stale_aware_time is enabled.
+	defaultStalenessDelta = 5 * time.Minute
+
+	// defaultMaxBacktrack is the number of steps execute_query takes back
+	// from the current time before giving up.
+	defaultMaxBacktrack = 3
+
+	// maxBacktrackLimit caps max_backtrack, as every step is another query
+	// against Prometheus.
+	maxBacktrackLimit = 10
+)
+
+// staleAwareResult is the outcome of executeStaleAware.
+type staleAwareResult struct {
+	Result *QueryResult
+	// Time is the evaluation timestamp of Result.
+	Time time.Time
+	// Steps is the number of staleness deltas stepped back from the start.
+	Steps int
+	// Found is false when every attempt returned an empty result.
+	Found bool
+}
+
+// header describes which timestamp was used, for prefixing the tool output.
+func (r staleAwareResult) header(maxBacktrack int) string {
+	ts := r.Time.UTC().Format(time.RFC3339)
+	switch {
+	case !r.Found:
+		return fmt.Sprintf("No data found at %s or in the %d preceding steps; showing result at the latest timestamp.", ts, maxBacktrack)
+	case r.Steps == 0:
+		return fmt.Sprintf("Using data from %s (no backtracking needed)", ts)
+	case r.Steps == 1:
+		return fmt.Sprintf("Using data from %s (backtracked 1 step)", ts)
+	default:
+		return fmt.Sprintf("Using data from %s (backtracked %d steps)", ts, r.Steps)
+	}
+}
+
+// parseStaleAwareParams reads staleness_delta and max_backtrack, applying
+// defaults when they are absent. max_backtrack is clamped to
+// maxBacktrackLimit.
+func parseStaleAwareParams(params map[string]any) (time.Duration, int, error) {
+	delta := defaultStalenessDelta
+	if s := getStringParam(params, "staleness_delta"); s != "" {
+		d, err := model.ParseDuration(s)
+		if err != nil || d <= 0 {
+			return 0, 0, fmt.Errorf("invalid staleness_delta %q: must be a positive duration such as '5m'", s)
+		}
+		delta = time.Duration(d)
+	}
+
+	maxBacktrack := defaultMaxBacktrack
+	if s := getStringParam(params, "max_backtrack"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 0 {
+			return 0, 0, fmt.Errorf("invalid max_backtrack %q: must be a non-negative integer", s)
+		}
+		maxBacktrack = min(n, maxBacktrackLimit)
+	}
+	return delta, maxBacktrack, nil
+}
+
+// executeStaleAware evaluates a query at start and, while the result is
+// empty, at start-delta, start-2*delta, … up to maxBacktrack steps back. It
+// returns the first non-empty result, or the result at start when all
+// attempts were empty.
+func executeStaleAware(ctx context.Context, start time.Time, delta time.Duration, maxBacktrack int,
+	exec func(ctx context.Context, timeParam string) (*QueryResult, error),
+) (staleAwareResult, error) {
+	var first staleAwareResult
+	for step := 0; step <= maxBacktrack; step++ {
+		ts := start.Add(-time.Duration(step) * delta)
+		result, err := exec(ctx, ts.UTC().Format(time.RFC3339))
+		if err != nil {
+			return staleAwareResult{}, err
+		}
+		if !isEmptyQueryResult(result) {
+			return staleAwareResult{Result: result, Time: ts, Steps: step, Found: true}, nil
+		}
+		if step == 0 {
+			first = staleAwareResult{Result: result, Time: ts}
+		}
+	}
+	return first, nil
+}
+
+// isEmptyQueryResult reports whether an instant query returned no samples.
+func isEmptyQueryResult(result *QueryResult) bool {
+	if result == nil || result.Result == nil {
+		return true
+	}
+	switch v := result.Result.(type) {
+	case model.Vector:
+		return len(v) == 0
+	case model.Matrix:
+		return len(v) == 0
+	}
+	return false
+}
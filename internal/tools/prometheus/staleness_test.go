@@ -0,0 +1,101 @@
+package prometheus
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/common/model"
+)
+
+func TestExecuteStaleAware(t *testing.T) {
+	start := time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC)
+	nonEmpty := &QueryResult{ResultType: "vector", Result: model.Vector{&model.Sample{Value: 1}}}
+	empty := &QueryResult{ResultType: "vector", Result: model.Vector{}}
+
+	tests := []struct {
+		name      string
+		dataAt    string // RFC3339 timestamp that returns data; "" for never
+		wantSteps int
+		wantFound bool
+		wantCalls int
+		wantTime  string
+	}{
+		{name: "data at start", dataAt: "2024-01-15T10:30:00Z", wantSteps: 0, wantFound: true, wantCalls: 1, wantTime: "2024-01-15T10:30:00Z"},
+		{name: "backtrack two steps", dataAt: "2024-01-15T10:20:00Z", wantSteps: 2, wantFound: true, wantCalls: 3, wantTime: "2024-01-15T10:20:00Z"},
+		{name: "no data", dataAt: "", wantFound: false, wantCalls: 4, wantTime: "2024-01-15T10:30:00Z"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			res, err := executeStaleAware(context.Background(), start, 5*time.Minute, 3,
+				func(_ context.Context, ts string) (*QueryResult, error) {
+					calls++
+					if ts == tt.dataAt {
+						return nonEmpty, nil
+					}
+					return empty, nil
+				})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("calls = %d, want %d", calls, tt.wantCalls)
+			}
+			if res.Found != tt.wantFound || res.Steps != tt.wantSteps {
+				t.Errorf("got found=%v steps=%d, want found=%v steps=%d", res.Found, res.Steps, tt.wantFound, tt.wantSteps)
+			}
+			if got := res.Time.UTC().Format(time.RFC3339); got != tt.wantTime {
+				t.Errorf("time = %s, want %s", got, tt.wantTime)
+			}
+		})
+	}
+
+	t.Run("error is returned", func(t *testing.T) {
+		_, err := executeStaleAware(context.Background(), start, time.Minute, 3,
+			func(context.Context, string) (*QueryResult, error) { return nil, errors.New("boom") })
+		if err == nil {
+			t.Fatal("expected error")
+		}
+	})
+}
+
+func TestStaleAwareResultHeader(t *testing.T) {
+	ts := time.Date(2024, 1, 15, 10, 20, 0, 0, time.UTC)
+	got := staleAwareResult{Time: ts, Steps: 1, Found: true}.header(3)
+	if want := "Using data from 2024-01-15T10:20:00Z (backtracked 1 step)"; got != want {
+		t.Errorf("header = %q, want %q", got, want)
+	}
+	if got := (staleAwareResult{Time: ts}).header(3); !strings.Contains(got, "No data found") {
+		t.Errorf("expected no-data header, got %q", got)
+	}
+}
+
+func TestParseStaleAwareParams(t *testing.T) {
+	delta, steps, err := parseStaleAwareParams(map[string]any{})
+	if err != nil || delta != defaultStalenessDelta || steps != defaultMaxBacktrack {
+		t.Errorf("defaults: got %v, %d, %v", delta, steps, err)
+	}
+
+	delta, steps, err = parseStaleAwareParams(map[string]any{"staleness_delta": "1m", "max_backtrack": "10"})
+	if err != nil || delta != time.Minute || steps != 10 {
+		t.Errorf("explicit: got %v, %d, %v", delta, steps, err)
+	}
+
+	if _, steps, err = parseStaleAwareParams(map[string]any{"max_backtrack": "100000"}); err != nil || steps != maxBacktrackLimit {
+		t.Errorf("clamped: got %d, %v; want %d", steps, err, maxBacktrackLimit)
+	}
+
+	for _, params := range []map[string]any{
+		{"staleness_delta": "soon"},
+		{"staleness_delta": "0s"},
+		{"max_backtrack": "-1"},
+	} {
+		if _, _, err := parseStaleAwareParams(params); err == nil {
+			t.Errorf("expected error for %v", params)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package prometheus
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/promql/parser"
+
+	"github.com/giantswarm/mcp-prometheus/internal/promql"
+)
+
+const (
+	// minRecommendedStep is the smallest useful range query step: the
+	// common default scrape interval.
+	minRecommendedStep = 15 * time.Second
+
+	// rangeWindowStepDivisor bounds the step from below for queries with
+	// range vector selectors: a step under a quarter of the widest window
+	// re-evaluates mostly overlapping windows.
+	rangeWindowStepDivisor = 4
+)
+
+// autoSteps are the steps autoStep rounds up to; longer steps are rounded
+// up to whole days.
+var autoSteps = []time.Duration{
+	15 * time.Second, 30 * time.Second,
+	time.Minute, 2 * time.Minute, 5 * time.Minute, 10 * time.Minute, 15 * time.Minute, 30 * time.Minute,
+	time.Hour, 2 * time.Hour, 3 * time.Hour, 6 * time.Hour, 12 * time.Hour, 24 * time.Hour,
+}
+
+// recommendedStep returns the smallest step that is not too small for
+// query: minRecommendedStep, or a quarter of the widest range vector
+// selector window. Queries that fail to parse get minRecommendedStep.
+func recommendedStep(query string) time.Duration {
+	recommended := minRecommendedStep
+	if expr, err := promql.Parse(query); err == nil {
+		parser.Inspect(expr, func(node parser.Node, _ []parser.Node) error {
+			if ms, ok := node.(*parser.MatrixSelector); ok {
+				recommended = max(recommended, ms.Range/rangeWindowStepDivisor)
+			}
+			return nil
+		})
+	}
+	return recommended
+}
+
+// autoStep returns the step of a range query over [start, end] that gives at
+// most about points samples per series: the range divided by points,
+// rounded up to the next of autoSteps, and no smaller than
+// recommendedStep(query).
+func autoStep(query string, start, end time.Time, points int) time.Duration {
+	raw := end.Sub(start) / time.Duration(max(points, 1))
+	step := raw.Truncate(24 * time.Hour)
+	if step < raw {
+		step += 24 * time.Hour
+	}
+	for _, s := range autoSteps {
+		if s >= raw {
+			step = s
+			break
+		}
+	}
+	return max(step, recommendedStep(query))
+}
+
+// validateStepSize returns advisory warnings for a range query step that is
+// too small for query: under minRecommendedStep, or under a quarter of the
+// widest range vector selector window. Queries that fail to parse produce no
+// warnings; Prometheus reports the parse error itself.
+func validateStepSize(query string, step time.Duration) []string {
+	recommended := recommendedStep(query)
+	if step >= recommended {
+		return nil
+	}
+	return []string{fmt.Sprintf("⚠️ Step (%s) is smaller than the minimum recommended value for this query. Consider using step=%s to avoid gaps.",
+		model.Duration(step), model.Duration(recommended))}
+}
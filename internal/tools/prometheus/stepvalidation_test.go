@@ -0,0 +1,177 @@
+package prometheus
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/giantswarm/mcp-prometheus/internal/server"
+)
+
+func TestValidateStepSize(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		step  time.Duration
+		want  string
+	}{
+		{"below absolute minimum", "up", 5 * time.Second, "⚠️ Step (5s) is smaller than the minimum recommended value for this query. Consider using step=15s to avoid gaps."},
+		{"absolute minimum", "up", 15 * time.Second, ""},
+		{"below quarter of range window", "rate(http_requests_total[10m])", time.Minute, "Consider using step=2m30s"},
+		{"quarter of range window", "rate(http_requests_total[4m])", time.Minute, ""},
+		{"widest window wins", "rate(a_total[1m]) / rate(b_total[20m])", 2 * time.Minute, "Consider using step=5m"},
+		{"window in subquery", "max_over_time(rate(a_total[8m])[1h:])", time.Minute, "Consider using step=2m"},
+		{"small window", "rate(a_total[30s])", 15 * time.Second, ""},
+		{"unparsable query", "rate(a_total[5m]", 15 * time.Second, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			warnings := validateStepSize(tt.query, tt.step)
+			if tt.want == "" {
+				if len(warnings) != 0 {
+					t.Errorf("validateStepSize(%q, %s) = %q, want no warnings", tt.query, tt.step, warnings)
+				}
+				return
+			}
+			if len(warnings) != 1 || !strings.Contains(warnings[0], tt.want) {
+				t.Errorf("validateStepSize(%q, %s) = %q, want a warning containing %q", tt.query, tt.step, warnings, tt.want)
+			}
+		})
+	}
+}
+
+func TestHandleExecuteRangeQueryStepWarning(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			respKeyStatus: respValSuccess,
+			respKeyData:   map[string]any{respKeyResultType: "matrix", respKeyResult: []any{}},
+		})
+	}))
+	defer mockServer.Close()
+
+	ctx := context.Background()
+	sc, err := server.NewServerContext(ctx,
+		server.WithPrometheusConfig(server.PrometheusConfig{URL: mockServer.URL}),
+		server.WithSlogLogger(discardLogger()),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create server context: %v", err)
+	}
+	defer func() { _ = sc.Shutdown() }()
+
+	client, err := NewClient(sc.PrometheusConfig(), sc.Logger())
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+
+	for step, wantWarning := range map[string]bool{"5s": true, "1m": false} {
+		request := mcp.CallToolRequest{Params: mcp.CallToolParams{Name: toolExecuteRangeQuery, Arguments: map[string]any{
+			paramKeyQuery: "rate(http_requests_total[1m])",
+			"start":       "2024-01-15T00:00:00Z",
+			"end":         "2024-01-15T01:00:00Z",
+			"step":        step,
+		}}}
+		result, err := handleExecuteRangeQuery(ctx, request, client, sc)
+		if err != nil || result.IsError {
+			t.Fatalf("step %s: unexpected failure: %v %v", step, err, result)
+		}
+		text := result.Content[0].(mcp.TextContent).Text
+		if got := strings.HasPrefix(text, "⚠️ Step ("+step+")"); got != wantWarning {
+			t.Errorf("step %s: warning prefix = %v, want %v:\n%s", step, got, wantWarning, text)
+		}
+	}
+}
+
+func TestAutoStep(t *testing.T) {
+	start := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name   string
+		query  string
+		length time.Duration
+		points int
+		want   time.Duration
+	}{
+		{"short range uses the minimum", "up", 10 * time.Minute, 250, 15 * time.Second},
+		{"one hour", "up", time.Hour, 250, 15 * time.Second},
+		{"one day", "up", 24 * time.Hour, 250, 10 * time.Minute},
+		{"seven days", "up", 7 * 24 * time.Hour, 250, time.Hour},
+		{"exact fit", "up", 250 * time.Minute, 250, time.Minute},
+		{"a year in whole days", "up", 365 * 24 * time.Hour, 100, 4 * 24 * time.Hour},
+		{"fewer points", "up", 24 * time.Hour, 24, time.Hour},
+		{"range window raises the step", "rate(a_total[1h])", time.Hour, 250, 15 * time.Minute},
+		{"end before start", "up", -time.Hour, 250, 15 * time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := autoStep(tt.query, start, start.Add(tt.length), tt.points); got != tt.want {
+				t.Errorf("autoStep(%q, %s, %d) = %s, want %s", tt.query, tt.length, tt.points, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHandleExecuteRangeQueryAutoStep(t *testing.T) {
+	var step string
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		step = r.Form.Get("step")
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			respKeyStatus: respValSuccess,
+			respKeyData:   map[string]any{respKeyResultType: "matrix", respKeyResult: []any{}},
+		})
+	}))
+	defer mockServer.Close()
+
+	ctx := context.Background()
+	for points, want := range map[int]string{0: "3600", 1000: "900"} {
+		sc, err := server.NewServerContext(ctx,
+			server.WithPrometheusConfig(server.PrometheusConfig{URL: mockServer.URL}),
+			server.WithSlogLogger(discardLogger()),
+			server.WithRangeQueryPoints(points),
+		)
+		if err != nil {
+			t.Fatalf("Failed to create server context: %v", err)
+		}
+		defer func() { _ = sc.Shutdown() }()
+		client, err := NewClient(sc.PrometheusConfig(), sc.Logger())
+		if err != nil {
+			t.Fatalf("NewClient: %v", err)
+		}
+
+		request := mcp.CallToolRequest{Params: mcp.CallToolParams{Name: toolExecuteRangeQuery, Arguments: map[string]any{
+			paramKeyQuery: "up",
+			"start":       "2024-01-08T00:00:00Z",
+			"end":         "2024-01-15T00:00:00Z",
+		}}}
+		result, err := handleExecuteRangeQuery(ctx, request, client, sc)
+		if err != nil || result.IsError {
+			t.Fatalf("points %d: unexpected failure: %v %v", points, err, result)
+		}
+		if step != want {
+			t.Errorf("points %d: step sent = %q, want %q", points, step, want)
+		}
+		if text := result.Content[0].(mcp.TextContent).Text; !strings.HasPrefix(text, "Step ") {
+			t.Errorf("points %d: expected a note naming the computed step:\n%s", points, text)
+		}
+	}
+
+	request := mcp.CallToolRequest{Params: mcp.CallToolParams{Name: toolExecuteRangeQuery, Arguments: map[string]any{
+		paramKeyQuery: "up", "start": "last fortnight", "end": "2024-01-15T00:00:00Z",
+	}}}
+	sc, err := server.NewServerContext(ctx, server.WithSlogLogger(discardLogger()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = sc.Shutdown() }()
+	if result, _ := handleExecuteRangeQuery(ctx, request, nil, sc); !result.IsError {
+		t.Error("expected an error for an unparsable start without a step")
+	}
+}
@@ -0,0 +1,373 @@
+package prometheus
+
+import (
+	"maps"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	v1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/prometheus/common/model"
+)
+
+// Tools that return Prometheus API data declare one of the output types
+// below with mcp.WithOutputSchema and return a value of it as
+// structuredContent next to the text rendering, so clients can consume typed
+// data instead of parsing the text. Sample values are strings, as in the
+// Prometheus HTTP API, so NaN and ±Inf survive JSON encoding.
+
+// maxStructuredItems caps the series, label names and label values in
+// structured listings.
+const maxStructuredItems = 1000
+
+// SampleOutput is one sample of a query result.
+type SampleOutput struct {
+	Timestamp string `json:"timestamp" jsonschema:"Sample time as RFC3339 with milliseconds, UTC"`
+	Value     string `json:"value" jsonschema:"Sample value as a string (NaN and +Inf/-Inf are possible); native histograms are rendered in Prometheus notation"`
+}
+
+// SeriesOutput is one series of a query result.
+type SeriesOutput struct {
+	Labels  map[string]string `json:"labels" jsonschema:"Series labels, including __name__ when the query keeps it"`
+	Samples []SampleOutput    `json:"samples" jsonschema:"One sample for vectors and scalars, one per step for matrices"`
+}
+
+// QueryOutput is the structured result of execute_query,
+// execute_range_query and query_templates.
+type QueryOutput struct {
+	Query       string         `json:"query" jsonschema:"The PromQL query that was run"`
+	ResultType  string         `json:"resultType" jsonschema:"vector, matrix, scalar or string"`
+	Unit        string         `json:"unit,omitempty" jsonschema:"Unit the values were converted to with convert_to"`
+	Series      []SeriesOutput `json:"series" jsonschema:"Result series; scalar and string results are a single series without labels"`
+	Truncated   bool           `json:"truncated,omitempty" jsonschema:"True when samples were dropped to stay within max_result_length; pass unlimited to get all"`
+	Downsampled int            `json:"downsampled,omitempty" jsonschema:"Number of series reduced to max_points_per_series samples"`
+}
+
+// MetricMetadataOutput is the structured result of get_metric_metadata.
+type MetricMetadataOutput struct {
+	Metrics map[string][]MetadataEntryOutput `json:"metrics" jsonschema:"Metadata entries keyed by metric name"`
+}
+
+// MetadataEntryOutput is one metadata entry reported for a metric.
+type MetadataEntryOutput struct {
+	Type string `json:"type" jsonschema:"Metric type: counter, gauge, histogram, gaugehistogram, summary, info, stateset or unknown"`
+	Help string `json:"help"`
+	Unit string `json:"unit"`
+}
+
+// TargetOutput is one active scrape target.
+type TargetOutput struct {
+	ScrapePool                string            `json:"scrapePool"`
+	ScrapeURL                 string            `json:"scrapeUrl"`
+	Health                    string            `json:"health" jsonschema:"up, down or unknown"`
+	Labels                    map[string]string `json:"labels"`
+	LastError                 string            `json:"lastError,omitempty"`
+	LastScrape                string            `json:"lastScrape,omitempty" jsonschema:"Time of the last scrape as RFC3339"`
+	LastScrapeDurationSeconds float64           `json:"lastScrapeDurationSeconds"`
+}
+
+// ScrapePoolOutput summarizes the active targets of one scrape pool.
+type ScrapePoolOutput struct {
+	ScrapePool               string  `json:"scrapePool"`
+	Total                    int     `json:"total"`
+	Healthy                  int     `json:"healthy"`
+	AvgScrapeDurationSeconds float64 `json:"avgScrapeDurationSeconds"`
+	MaxScrapeDurationSeconds float64 `json:"maxScrapeDurationSeconds"`
+}
+
+// TargetsOutput is the structured result of get_targets.
+type TargetsOutput struct {
+	ActiveCount   int                `json:"activeCount"`
+	DroppedCount  int                `json:"droppedCount"`
+	ScrapePools   []ScrapePoolOutput `json:"scrapePools"`
+	ActiveTargets []TargetOutput     `json:"activeTargets,omitempty" jsonschema:"Active targets; omitted with summary_only"`
+}
+
+// LabelCardinalityOutput is the number of distinct values of a label.
+type LabelCardinalityOutput struct {
+	Name           string `json:"name"`
+	DistinctValues int    `json:"distinctValues"`
+}
+
+// LabelNamesOutput is the structured result of list_label_names.
+type LabelNamesOutput struct {
+	LabelNames    []string                 `json:"labelNames"`
+	Truncated     bool                     `json:"truncated,omitempty" jsonschema:"True when names or cardinalities were dropped to keep the result small; use matches to narrow the listing"`
+	Cardinalities []LabelCardinalityOutput `json:"cardinalities,omitempty" jsonschema:"Distinct value counts, set with with_cardinality and filtered by min_cardinality"`
+	Warnings      []string                 `json:"warnings,omitempty"`
+}
+
+// LabelValuesOutput is the structured result of list_label_values.
+type LabelValuesOutput struct {
+	Label         string   `json:"label"`
+	Values        []string `json:"values"`
+	ExcludedCount int      `json:"excludedCount,omitempty" jsonschema:"Metric names hidden by PROMETHEUS_EXCLUDED_METRICS"`
+	Truncated     bool     `json:"truncated,omitempty" jsonschema:"True when values were dropped to keep the result small; use limit or matches to narrow the listing"`
+	NextCursor    string   `json:"nextCursor,omitempty" jsonschema:"Pass as cursor to get the next page; omitted on the last page"`
+	Warnings      []string `json:"warnings,omitempty"`
+}
+
+// SeriesListOutput is the structured result of find_series.
+type SeriesListOutput struct {
+	Series    []map[string]string `json:"series" jsonschema:"Label sets of the matching series"`
+	Truncated bool                `json:"truncated,omitempty" jsonschema:"True when series were dropped to keep the result small; use limit or narrower matchers"`
+	Warnings  []string            `json:"warnings,omitempty"`
+}
+
+// AlertOutput is one active alert.
+type AlertOutput struct {
+	Labels      map[string]string `json:"labels"`
+	Annotations map[string]string `json:"annotations"`
+	State       string            `json:"state" jsonschema:"pending or firing"`
+	ActiveAt    string            `json:"activeAt" jsonschema:"Time the alert became active as RFC3339"`
+	Value       string            `json:"value"`
+}
+
+// AlertsOutput is the structured result of get_alerts.
+type AlertsOutput struct {
+	Alerts []AlertOutput `json:"alerts"`
+}
+
+// FlagsOutput is the structured result of get_flags.
+type FlagsOutput struct {
+	Flags   map[string]string `json:"flags" jsonschema:"Runtime flags, filtered by flag_filter"`
+	Changed map[string]string `json:"changed,omitempty" jsonschema:"Flags that differ from their known default; set with show_changed_only"`
+}
+
+// BuildInfoOutput is the structured result of get_build_info.
+type BuildInfoOutput struct {
+	Version   string `json:"version"`
+	Revision  string `json:"revision"`
+	Branch    string `json:"branch"`
+	BuildUser string `json:"buildUser"`
+	BuildDate string `json:"buildDate"`
+	GoVersion string `json:"goVersion"`
+	StartTime string `json:"startTime,omitempty" jsonschema:"Server start time as RFC3339; omitted when runtime info is unavailable"`
+}
+
+// withStructuredContent attaches v to result as structured content.
+func withStructuredContent(result *mcp.CallToolResult, v any) *mcp.CallToolResult {
+	result.StructuredContent = v
+	return result
+}
+
+// labelMap converts a label set to a plain map.
+func labelMap(labels model.LabelSet) map[string]string {
+	m := make(map[string]string, len(labels))
+	for name, value := range labels {
+		m[string(name)] = string(value)
+	}
+	return m
+}
+
+// newQueryOutput converts a query result. When limit is positive, samples
+// are dropped once the JSON encoding of the output would grow past limit
+// bytes, and Truncated is set.
+func newQueryOutput(query string, result *QueryResult, unit string, limit int) QueryOutput {
+	out := QueryOutput{Query: query, ResultType: result.ResultType, Unit: unit, Series: []SeriesOutput{}}
+	// The envelope and the downsampled count take at most 96 bytes.
+	budget := limit - 96 - len(query) - len(unit) - len(result.ResultType)
+	add := func(labels model.Metric, samples []SampleOutput) {
+		if out.Truncated {
+			return
+		}
+		if limit > 0 {
+			// {"labels":{},"samples":[]}, plus "name":"value", per label
+			budget -= 27
+			for name, value := range labels {
+				budget -= len(name) + len(value) + 6
+			}
+			for i, sample := range samples {
+				// {"timestamp":"","value":""},
+				budget -= 28 + len(sample.Timestamp) + len(sample.Value)
+				if budget < 0 {
+					samples, out.Truncated = samples[:i], true
+					break
+				}
+			}
+		}
+		if len(samples) > 0 || !out.Truncated {
+			out.Series = append(out.Series, SeriesOutput{Labels: labelMap(model.LabelSet(labels)), Samples: samples})
+		}
+	}
+
+	switch r := result.Result.(type) {
+	case model.Vector:
+		for _, s := range r {
+			add(s.Metric, []SampleOutput{{Timestamp: formatResultTime(s.Timestamp), Value: formatSampleValue(s.Value, s.Histogram)}})
+		}
+	case model.Matrix:
+		for _, s := range r {
+			samples := make([]SampleOutput, 0, len(s.Values)+len(s.Histograms))
+			for _, p := range s.Values {
+				samples = append(samples, SampleOutput{Timestamp: formatResultTime(p.Timestamp), Value: p.Value.String()})
+			}
+			for _, p := range s.Histograms {
+				samples = append(samples, SampleOutput{Timestamp: formatResultTime(p.Timestamp), Value: p.Histogram.String()})
+			}
+			add(s.Metric, samples)
+		}
+	case *model.Scalar:
+		add(nil, []SampleOutput{{Timestamp: formatResultTime(r.Timestamp), Value: r.Value.String()}})
+	case *model.String:
+		add(nil, []SampleOutput{{Timestamp: formatResultTime(r.Timestamp), Value: r.Value}})
+	}
+	return out
+}
+
+// newMetricMetadataOutput converts the metadata returned by
+// Client.GetMetricMetadataWithOptions.
+func newMetricMetadataOutput(metadata MetricMetadata) MetricMetadataOutput {
+	out := MetricMetadataOutput{Metrics: make(map[string][]MetadataEntryOutput, len(metadata))}
+	for name, entries := range metadata {
+		list, _ := entries.([]interface{})
+		converted := make([]MetadataEntryOutput, 0, len(list))
+		for _, e := range list {
+			entry, _ := e.(map[string]interface{})
+			metricType, _ := entry["type"].(v1.MetricType)
+			help, _ := entry["help"].(string)
+			unit, _ := entry["unit"].(string)
+			converted = append(converted, MetadataEntryOutput{Type: string(metricType), Help: help, Unit: unit})
+		}
+		out.Metrics[name] = converted
+	}
+	return out
+}
+
+// newTargetsOutput converts the targets returned by Client.GetTargets. The
+// target list is left out when summaryOnly is set.
+func newTargetsOutput(targets *TargetsResult, summaryOnly bool) TargetsOutput {
+	out := TargetsOutput{
+		ActiveCount:  len(targets.ActiveTargets),
+		DroppedCount: len(targets.DroppedTargets),
+		ScrapePools:  []ScrapePoolOutput{},
+	}
+	for _, p := range summarizeScrapePools(targets.ActiveTargets) {
+		out.ScrapePools = append(out.ScrapePools, ScrapePoolOutput{
+			ScrapePool:               p.scrapePool,
+			Total:                    p.total,
+			Healthy:                  p.healthy,
+			AvgScrapeDurationSeconds: p.scrapeDurationSum / float64(p.total),
+			MaxScrapeDurationSeconds: p.maxScrapeDuration,
+		})
+	}
+	if summaryOnly {
+		return out
+	}
+
+	for _, t := range targets.ActiveTargets {
+		fields, ok := t.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		var target TargetOutput
+		target.ScrapePool, _ = fields["scrapePool"].(string)
+		target.ScrapeURL, _ = fields["scrapeUrl"].(string)
+		target.LastError, _ = fields["lastError"].(string)
+		target.LastScrapeDurationSeconds, _ = fields["lastScrapeDuration"].(float64)
+		health, _ := fields["health"].(v1.HealthStatus)
+		target.Health = string(health)
+		labels, _ := fields["labels"].(model.LabelSet)
+		target.Labels = labelMap(labels)
+		if lastScrape, _ := fields["lastScrape"].(time.Time); !lastScrape.IsZero() {
+			target.LastScrape = lastScrape.UTC().Format(time.RFC3339Nano)
+		}
+		out.ActiveTargets = append(out.ActiveTargets, target)
+	}
+	return out
+}
+
+// newLabelCardinalityOutputs converts label cardinalities.
+func newLabelCardinalityOutputs(labels []labelCardinality) []LabelCardinalityOutput {
+	out := make([]LabelCardinalityOutput, 0, len(labels))
+	for _, l := range labels {
+		out = append(out, LabelCardinalityOutput{Name: l.Name, DistinctValues: l.Values})
+	}
+	return out
+}
+
+// newLabelValuesOutput converts label values, keeping at most
+// maxStructuredItems.
+func newLabelValuesOutput(label string, result *LabelValuesResult, excluded int) LabelValuesOutput {
+	values := result.LabelValues
+	truncated := len(values) > maxStructuredItems
+	if truncated {
+		values = values[:maxStructuredItems]
+	}
+	return LabelValuesOutput{
+		Label:         label,
+		Values:        append([]string{}, values...),
+		ExcludedCount: excluded,
+		Truncated:     truncated,
+		Warnings:      result.Warnings,
+	}
+}
+
+// newLabelNamesOutput converts label names, keeping at most
+// maxStructuredItems.
+func newLabelNamesOutput(result *LabelNamesResult) LabelNamesOutput {
+	names := result.LabelNames
+	truncated := len(names) > maxStructuredItems
+	if truncated {
+		names = names[:maxStructuredItems]
+	}
+	return LabelNamesOutput{
+		LabelNames: append([]string{}, names...),
+		Truncated:  truncated,
+		Warnings:   result.Warnings,
+	}
+}
+
+// newSeriesListOutput converts the series found by Client.FindSeries,
+// keeping at most maxStructuredItems.
+func newSeriesListOutput(result *SeriesResult) SeriesListOutput {
+	series := result.Series
+	truncated := len(series) > maxStructuredItems
+	if truncated {
+		series = series[:maxStructuredItems]
+	}
+	return SeriesListOutput{
+		Series:    append([]map[string]string{}, series...),
+		Truncated: truncated,
+		Warnings:  result.Warnings,
+	}
+}
+
+// newAlertsOutput converts the alerts returned by Client.GetAlerts.
+func newAlertsOutput(alerts interface{}) AlertsOutput {
+	out := AlertsOutput{Alerts: []AlertOutput{}}
+	result, _ := alerts.(v1.AlertsResult)
+	for _, a := range result.Alerts {
+		out.Alerts = append(out.Alerts, AlertOutput{
+			Labels:      labelMap(a.Labels),
+			Annotations: labelMap(a.Annotations),
+			State:       string(a.State),
+			ActiveAt:    a.ActiveAt.UTC().Format(time.RFC3339Nano),
+			Value:       a.Value,
+		})
+	}
+	return out
+}
+
+// newFlagsOutput converts runtime flags. changed is only set with
+// show_changed_only.
+func newFlagsOutput(flags, changed map[string]string) FlagsOutput {
+	out := FlagsOutput{Flags: make(map[string]string, len(flags)), Changed: changed}
+	maps.Copy(out.Flags, flags)
+	return out
+}
+
+// newBuildInfoOutput converts build info and the optional start time.
+func newBuildInfoOutput(info v1.BuildinfoResult, startTime time.Time) BuildInfoOutput {
+	out := BuildInfoOutput{
+		Version:   info.Version,
+		Revision:  info.Revision,
+		Branch:    info.Branch,
+		BuildUser: info.BuildUser,
+		BuildDate: info.BuildDate,
+		GoVersion: info.GoVersion,
+	}
+	if !startTime.IsZero() {
+		out.StartTime = startTime.UTC().Format(time.RFC3339)
+	}
+	return out
+}
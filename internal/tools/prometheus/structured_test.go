@@ -0,0 +1,324 @@
+package prometheus
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	mcpserver "github.com/mark3labs/mcp-go/server"
+	"github.com/prometheus/common/model"
+
+	"github.com/giantswarm/mcp-prometheus/internal/server"
+)
+
+// structuredAPIResponses maps Prometheus API paths to the data field served
+// by newStructuredServer.
+var structuredAPIResponses = map[string]any{
+	apiQueryPath: map[string]any{
+		respKeyResultType: respValVector,
+		respKeyResult: []any{
+			map[string]any{"metric": map[string]any{"__name__": "up", "job": "prometheus"}, "value": []any{1704067200, "1"}},
+			map[string]any{"metric": map[string]any{"__name__": "up", "job": "node"}, "value": []any{1704067200, "NaN"}},
+		},
+	},
+	"/api/v1/query_range": map[string]any{
+		respKeyResultType: "matrix",
+		respKeyResult: []any{
+			map[string]any{"metric": map[string]any{"job": "prometheus"}, "values": []any{[]any{1704067200, "1"}, []any{1704067260, "+Inf"}}},
+		},
+	},
+	"/api/v1/labels":                []string{"__name__", "job"},
+	"/api/v1/label/job/values":      []string{"node", "prometheus"},
+	"/api/v1/label/__name__/values": []string{"go_goroutines", "up"},
+	"/api/v1/series":                []map[string]string{{"__name__": "up", "job": "prometheus"}},
+	"/api/v1/metadata": map[string]any{
+		"up": []any{map[string]any{"type": "gauge", "help": "Whether the target is up.", "unit": ""}},
+	},
+	"/api/v1/targets": map[string]any{
+		"activeTargets": []any{map[string]any{
+			"discoveredLabels":   map[string]string{},
+			"labels":             map[string]string{"job": "prometheus", "instance": "localhost:9090"},
+			"scrapePool":         "prometheus",
+			"scrapeUrl":          "http://localhost:9090/metrics",
+			"lastError":          "",
+			"lastScrape":         "2024-01-01T00:00:00Z",
+			"lastScrapeDuration": 0.012,
+			"health":             "up",
+		}},
+		"droppedTargets": []any{},
+	},
+	"/api/v1/alerts": map[string]any{
+		"alerts": []any{map[string]any{
+			"labels":      map[string]string{"alertname": "Watchdog"},
+			"annotations": map[string]string{"summary": "Always firing"},
+			"state":       "firing",
+			"activeAt":    "2024-01-01T00:00:00Z",
+			"value":       "1e+00",
+		}},
+	},
+	"/api/v1/status/flags": map[string]string{"log.level": "debug", "query.timeout": "2m"},
+	"/api/v1/status/buildinfo": map[string]string{
+		"version": "3.1.0", "revision": "abc", "branch": "HEAD",
+		"buildUser": "root", "buildDate": "20240101-00:00:00", "goVersion": "go1.23.4",
+	},
+}
+
+// newStructuredServer registers all Prometheus tools on an MCP server with
+// output schema validation enabled, so every structured result is checked
+// against the schema its tool declares.
+func newStructuredServer(t *testing.T) *mcpserver.MCPServer {
+	t.Helper()
+
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, ok := structuredAPIResponses[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{respKeyStatus: respValSuccess, respKeyData: data})
+	}))
+	t.Cleanup(mockServer.Close)
+
+	sc, err := server.NewServerContext(context.Background(),
+		server.WithPrometheusConfig(server.PrometheusConfig{URL: mockServer.URL}),
+		server.WithSlogLogger(discardLogger()),
+		server.WithAdminTools(true),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create server context: %v", err)
+	}
+	t.Cleanup(func() { _ = sc.Shutdown() })
+
+	srv := mcpserver.NewMCPServer("test", "0.0.0",
+		mcpserver.WithToolCapabilities(true),
+		mcpserver.WithInputSchemaValidation(),
+		mcpserver.WithOutputSchemaValidation(),
+	)
+	if err := RegisterPrometheusTools(srv, sc); err != nil {
+		t.Fatalf("RegisterPrometheusTools: %v", err)
+	}
+	return srv
+}
+
+func TestStructuredContentMatchesOutputSchema(t *testing.T) {
+	srv := newStructuredServer(t)
+
+	tests := []struct {
+		tool string
+		args map[string]any
+		// check inspects the structured content decoded from JSON.
+		check func(t *testing.T, content map[string]any)
+	}{
+		{toolExecuteQuery, map[string]any{paramKeyQuery: "up"}, func(t *testing.T, c map[string]any) {
+			series := c["series"].([]any)
+			if len(series) != 2 {
+				t.Fatalf("got %d series, want 2", len(series))
+			}
+			sample := series[1].(map[string]any)["samples"].([]any)[0].(map[string]any)
+			if sample["value"] != "NaN" || sample["timestamp"] != "2024-01-01T00:00:00.000Z" {
+				t.Errorf("unexpected sample %v", sample)
+			}
+		}},
+		{toolExecuteQuery, map[string]any{paramKeyQuery: "up", "format": "openmetrics"}, nil},
+		{toolExecuteRangeQuery, map[string]any{paramKeyQuery: "up", "start": "1704067200", "end": "1704067260", "step": "1m"}, func(t *testing.T, c map[string]any) {
+			if c["resultType"] != "matrix" {
+				t.Errorf("resultType = %v, want matrix", c["resultType"])
+			}
+			samples := c["series"].([]any)[0].(map[string]any)["samples"].([]any)
+			if len(samples) != 2 || samples[1].(map[string]any)["value"] != "+Inf" {
+				t.Errorf("unexpected samples %v", samples)
+			}
+		}},
+		{toolQueryTemplates, map[string]any{"template": "{{.metric}}", "variables": map[string]any{"metric": "up"}}, func(t *testing.T, c map[string]any) {
+			if c["query"] != "up" {
+				t.Errorf("query = %v, want the expanded query", c["query"])
+			}
+		}},
+		{toolExecuteMultiQuery, map[string]any{"queries": []any{"up", map[string]any{"name": "again", "query": "up"}}}, func(t *testing.T, c map[string]any) {
+			results := c["results"].([]any)
+			if len(results) != 2 || results[1].(map[string]any)["name"] != "again" || results[1].(map[string]any)["success"] != true {
+				t.Errorf("unexpected results %v", results)
+			}
+		}},
+		{"get_metric_metadata", map[string]any{"metric": "up"}, func(t *testing.T, c map[string]any) {
+			entry := c["metrics"].(map[string]any)["up"].([]any)[0].(map[string]any)
+			if entry["type"] != "gauge" {
+				t.Errorf("type = %v, want gauge", entry["type"])
+			}
+		}},
+		{"get_targets", map[string]any{}, func(t *testing.T, c map[string]any) {
+			target := c["activeTargets"].([]any)[0].(map[string]any)
+			if target["health"] != "up" || target["lastScrape"] != "2024-01-01T00:00:00Z" {
+				t.Errorf("unexpected target %v", target)
+			}
+		}},
+		{"get_targets", map[string]any{"summary_only": "true"}, func(t *testing.T, c map[string]any) {
+			if _, ok := c["activeTargets"]; ok {
+				t.Error("summary_only should omit activeTargets")
+			}
+			if pools := c["scrapePools"].([]any); len(pools) != 1 {
+				t.Errorf("got %d scrape pools, want 1", len(pools))
+			}
+		}},
+		{"list_label_names", map[string]any{}, nil},
+		{"list_label_names", map[string]any{"with_cardinality": "true", "min_cardinality": "2"}, func(t *testing.T, c map[string]any) {
+			cardinalities := c["cardinalities"].([]any)
+			if len(cardinalities) != 2 {
+				t.Errorf("got %d cardinalities, want 2", len(cardinalities))
+			}
+		}},
+		{"list_label_values", map[string]any{"label": "job"}, func(t *testing.T, c map[string]any) {
+			if values := c["values"].([]any); len(values) != 2 {
+				t.Errorf("got %d values, want 2", len(values))
+			}
+		}},
+		{"find_series", map[string]any{"matches": []string{"up"}}, nil},
+		{"get_alerts", map[string]any{}, func(t *testing.T, c map[string]any) {
+			alert := c["alerts"].([]any)[0].(map[string]any)
+			if alert["state"] != "firing" || alert["labels"].(map[string]any)["alertname"] != "Watchdog" {
+				t.Errorf("unexpected alert %v", alert)
+			}
+		}},
+		{"get_flags", map[string]any{}, nil},
+		{"get_flags", map[string]any{"show_changed_only": "true"}, func(t *testing.T, c map[string]any) {
+			if changed := c["changed"].(map[string]any); changed["log.level"] != "debug" {
+				t.Errorf("changed = %v, want log.level", changed)
+			}
+		}},
+		{"get_build_info", map[string]any{}, func(t *testing.T, c map[string]any) {
+			if c["version"] != "3.1.0" {
+				t.Errorf("version = %v, want 3.1.0", c["version"])
+			}
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tool, func(t *testing.T) {
+			resp, ok := dispatchToolCall(t, srv, tt.tool, tt.args).(mcp.JSONRPCResponse)
+			if !ok {
+				t.Fatal("expected JSON-RPC response")
+			}
+			result := resp.Result.(*mcp.CallToolResult)
+			if result.IsError {
+				t.Fatalf("unexpected error result: %v", result.Content)
+			}
+			if len(result.Content) == 0 {
+				t.Error("structured results must keep the text rendering")
+			}
+
+			raw, err := json.Marshal(result.StructuredContent)
+			if err != nil {
+				t.Fatalf("marshal structured content: %v", err)
+			}
+			var content map[string]any
+			if err := json.Unmarshal(raw, &content); err != nil || content == nil {
+				t.Fatalf("structured content is not a JSON object: %s", raw)
+			}
+			if tt.check != nil {
+				tt.check(t, content)
+			}
+		})
+	}
+}
+
+func TestToolsDeclareOutputSchema(t *testing.T) {
+	srv := newStructuredServer(t)
+	tools := srv.ListTools()
+	for _, name := range []string{
+		toolExecuteQuery, toolExecuteRangeQuery, toolQueryTemplates, toolExecuteMultiQuery,
+		"get_metric_metadata", "get_targets", "list_label_names", "list_label_values",
+		"find_series", "get_alerts", "get_flags", "get_build_info",
+	} {
+		st, ok := tools[name]
+		if !ok {
+			t.Errorf("tool %q is not registered", name)
+			continue
+		}
+		if st.Tool.OutputSchema.Type != "object" || len(st.Tool.OutputSchema.Properties) == 0 {
+			t.Errorf("tool %q: expected an object output schema, got %+v", name, st.Tool.OutputSchema)
+		}
+	}
+}
+
+func TestNewQueryOutputTruncates(t *testing.T) {
+	matrix := model.Matrix{
+		{Metric: model.Metric{"job": "a"}, Values: make([]model.SamplePair, 50)},
+		{Metric: model.Metric{"job": "b"}, Values: make([]model.SamplePair, 50)},
+		{Metric: model.Metric{"job": "c"}, Values: make([]model.SamplePair, 50)},
+	}
+	result := &QueryResult{ResultType: "matrix", Result: matrix}
+
+	const limit = 5000
+	out := newQueryOutput("x", result, "", limit)
+	if !out.Truncated {
+		t.Error("expected Truncated")
+	}
+	data, err := json.Marshal(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) > limit {
+		t.Errorf("structured output is %d bytes, want at most %d", len(data), limit)
+	}
+	total := 0
+	for _, s := range out.Series {
+		total += len(s.Samples)
+	}
+	if len(out.Series) != 2 || total < 80 {
+		t.Errorf("got %d samples in %d series, want 2 series filling most of the limit", total, len(out.Series))
+	}
+
+	out = newQueryOutput("x", result, "", 0)
+	if out.Truncated || len(out.Series) != 3 {
+		t.Errorf("unlimited: truncated=%v, series=%d", out.Truncated, len(out.Series))
+	}
+}
+
+func TestStructuredResultLimit(t *testing.T) {
+	sc, err := server.NewServerContext(context.Background(),
+		server.WithSlogLogger(discardLogger()),
+		server.WithMaxResultLength(2000),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = sc.Shutdown() }()
+
+	for _, tt := range []struct {
+		args map[string]any
+		want int
+	}{
+		{map[string]any{}, 2000},
+		{map[string]any{"max_result_length": "500"}, 500},
+		{map[string]any{"max_result_length": "9000"}, 2000},
+		{map[string]any{"unlimited": "true"}, 0},
+	} {
+		request := mcp.CallToolRequest{Params: mcp.CallToolParams{Arguments: tt.args}}
+		if got := structuredResultLimit(sc, request); got != tt.want {
+			t.Errorf("structuredResultLimit(%v) = %d, want %d", tt.args, got, tt.want)
+		}
+	}
+}
+
+func TestNewLabelNamesOutputTruncates(t *testing.T) {
+	names := make([]string, maxStructuredItems+1)
+	for i := range names {
+		names[i] = fmt.Sprintf("label_%d", i)
+	}
+	out := newLabelNamesOutput(&LabelNamesResult{LabelNames: names})
+	if !out.Truncated || len(out.LabelNames) != maxStructuredItems {
+		t.Errorf("got %d names (truncated=%v), want %d truncated", len(out.LabelNames), out.Truncated, maxStructuredItems)
+	}
+}
+
+func TestNewQueryOutputScalar(t *testing.T) {
+	result := &QueryResult{ResultType: "scalar", Result: &model.Scalar{Value: model.SampleValue(math.Inf(-1)), Timestamp: 1704067200000}}
+	out := newQueryOutput("-Inf", result, "", 0)
+	if len(out.Series) != 1 || len(out.Series[0].Labels) != 0 || out.Series[0].Samples[0].Value != "-Inf" {
+		t.Errorf("unexpected scalar output %+v", out)
+	}
+}
@@ -0,0 +1,215 @@
+package prometheus
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/giantswarm/mcp-prometheus/internal/promql"
+	"github.com/giantswarm/mcp-prometheus/internal/server"
+)
+
+const (
+	// maxSuggestionsPerMetric caps the number of label filters suggested for
+	// a single vector selector.
+	maxSuggestionsPerMetric = 5
+
+	// maxSuggestionCardinality excludes labels with this many distinct values
+	// or more. Filtering on such labels (request IDs, pod names, …) rarely
+	// expresses what the user actually wants to narrow down.
+	maxSuggestionCardinality = 500
+)
+
+// labelFilterSuggestion is a candidate equality filter for a vector selector
+// and the series count it would leave.
+type labelFilterSuggestion struct {
+	Label    string
+	Value    string
+	Selector string
+	Before   int
+	After    int
+}
+
+// reduction returns the fraction of series removed by the suggestion.
+func (s labelFilterSuggestion) reduction() float64 {
+	if s.Before == 0 {
+		return 0
+	}
+	return float64(s.Before-s.After) / float64(s.Before)
+}
+
+// handleSuggestLabelFilters handles the suggest_label_filters tool
+func handleSuggestLabelFilters(ctx context.Context, request mcp.CallToolRequest, client *Client, sc *server.ServerContext) (*mcp.CallToolResult, error) {
+	params := extractParams(request)
+
+	query, ok := params["query"].(string)
+	if !ok || query == "" {
+		return &mcp.CallToolResult{
+			IsError: true,
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: contentTypeText,
+					Text: errQueryParameterRequired,
+				},
+			},
+		}, nil
+	}
+
+	expr, err := promql.Parse(query)
+	if err != nil {
+		return &mcp.CallToolResult{
+			IsError: true,
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: contentTypeText,
+					Text: fmt.Sprintf("Error: %v", err),
+				},
+			},
+		}, nil
+	}
+
+	startTime := getStringParam(params, "start_time")
+	endTime := getStringParam(params, "end_time")
+
+	sc.Logger().Debug("Suggesting label filters", "query", query)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Label filter suggestions for: %s\n", query)
+
+	selectors := promql.VectorSelectors(expr)
+	if len(selectors) == 0 {
+		b.WriteString("\nThe query contains no vector selectors; nothing to suggest.")
+	}
+
+	for _, vs := range selectors {
+		selector := promql.SelectorString(vs)
+		suggestions, total, err := suggestLabelFilters(ctx, client, selector, promql.MatchedLabelNames(vs), startTime, endTime,
+			func(label, value string) string { return promql.WithMatcher(vs, label, value) })
+		if err != nil {
+			sc.Logger().Error("Failed to suggest label filters", "error", err, "selector", selector)
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{
+					mcp.TextContent{
+						Type: contentTypeText,
+						Text: fmt.Sprintf("Error suggesting label filters for '%s': %v", selector, err),
+					},
+				},
+			}, nil
+		}
+
+		fmt.Fprintf(&b, "\n%s (%d series):\n", selector, total)
+		if len(suggestions) == 0 {
+			b.WriteString("  No useful label filters found.\n")
+			continue
+		}
+		for i, s := range suggestions {
+			fmt.Fprintf(&b, "  %d. adding {%s=%q} reduces from %d to %d series (−%.0f%%)\n",
+				i+1, s.Label, s.Value, s.Before, s.After, s.reduction()*100)
+		}
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.TextContent{
+				Type: contentTypeText,
+				Text: b.String(),
+			},
+		},
+	}, nil
+}
+
+// suggestLabelFilters computes up to maxSuggestionsPerMetric label filters for
+// selector, sorted by how many series each filter removes.
+//
+// For every label not already matched by the selector whose cardinality is
+// within (1, maxSuggestionCardinality), the most common value is taken as the
+// candidate and FindSeries is called with the narrowed selector to get the
+// resulting series count.
+func suggestLabelFilters(ctx context.Context, client *Client, selector string, matched map[string]bool, startTime, endTime string, narrow func(label, value string) string) ([]labelFilterSuggestion, int, error) {
+	seriesOpts := SeriesOptions{StartTime: startTime, EndTime: endTime}
+
+	series, err := client.FindSeries(ctx, []string{selector}, seriesOpts)
+	if err != nil {
+		return nil, 0, err
+	}
+	total := len(series.Series)
+	if total == 0 {
+		return nil, 0, nil
+	}
+
+	// Tally label values across the matched series so the most common value
+	// of each label can be used as the candidate filter.
+	valueCounts := make(map[string]map[string]int)
+	for _, s := range series.Series {
+		for name, value := range s {
+			if matched[name] {
+				continue
+			}
+			if valueCounts[name] == nil {
+				valueCounts[name] = make(map[string]int)
+			}
+			valueCounts[name][value]++
+		}
+	}
+
+	names := make([]string, 0, len(valueCounts))
+	for name := range valueCounts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var suggestions []labelFilterSuggestion
+	for _, name := range names {
+		values, err := client.ListLabelValues(ctx, name, LabelOptions{
+			StartTime: startTime,
+			EndTime:   endTime,
+			Matches:   []string{selector},
+		})
+		if err != nil {
+			return nil, 0, err
+		}
+		cardinality := len(values.LabelValues)
+		if cardinality <= 1 || cardinality >= maxSuggestionCardinality {
+			continue
+		}
+
+		candidate := mostCommonValue(valueCounts[name])
+		narrowed := narrow(name, candidate)
+		filtered, err := client.FindSeries(ctx, []string{narrowed}, seriesOpts)
+		if err != nil {
+			return nil, 0, err
+		}
+		suggestions = append(suggestions, labelFilterSuggestion{
+			Label:    name,
+			Value:    candidate,
+			Selector: narrowed,
+			Before:   total,
+			After:    len(filtered.Series),
+		})
+	}
+
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		return suggestions[i].reduction() > suggestions[j].reduction()
+	})
+	if len(suggestions) > maxSuggestionsPerMetric {
+		suggestions = suggestions[:maxSuggestionsPerMetric]
+	}
+	return suggestions, total, nil
+}
+
+// mostCommonValue returns the key with the highest count, breaking ties by
+// lexical order so the result is deterministic.
+func mostCommonValue(counts map[string]int) string {
+	var best string
+	bestCount := -1
+	for value, count := range counts {
+		if count > bestCount || (count == bestCount && value < best) {
+			best, bestCount = value, count
+		}
+	}
+	return best
+}
@@ -0,0 +1,177 @@
+package prometheus
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/giantswarm/mcp-prometheus/internal/server"
+)
+
+// suggestSeries is the fixture returned by the mock series endpoint: six
+// http_requests_total series split across environment and job labels.
+var suggestSeries = []map[string]string{
+	{"__name__": "http_requests_total", "environment": "production", "job": "api", "instance": "a"},
+	{"__name__": "http_requests_total", "environment": "production", "job": "api", "instance": "b"},
+	{"__name__": "http_requests_total", "environment": "production", "job": "batch", "instance": "c"},
+	{"__name__": "http_requests_total", "environment": "staging", "job": "api", "instance": "d"},
+	{"__name__": "http_requests_total", "environment": "staging", "job": "api", "instance": "e"},
+	{"__name__": "http_requests_total", "environment": "dev", "job": "api", "instance": "f"},
+}
+
+// newSuggestMockServer serves /api/v1/series and /api/v1/label/<name>/values
+// from suggestSeries, honouring equality matchers in match[] so narrowed
+// selectors return the expected subset.
+func newSuggestMockServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		matched := filterSuggestSeries(r.Form["match[]"])
+
+		var data any
+		switch {
+		case r.URL.Path == "/api/v1/series":
+			data = matched
+		case strings.HasPrefix(r.URL.Path, "/api/v1/label/") && strings.HasSuffix(r.URL.Path, "/values"):
+			name := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/api/v1/label/"), "/values")
+			seen := map[string]bool{}
+			values := []string{}
+			for _, s := range matched {
+				if v, ok := s[name]; ok && !seen[v] {
+					seen[v] = true
+					values = append(values, v)
+				}
+			}
+			data = values
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{respKeyStatus: respValSuccess, respKeyData: data})
+	}))
+}
+
+// filterSuggestSeries applies the equality matchers of a selector like
+// http_requests_total{environment="production"} to suggestSeries.
+func filterSuggestSeries(matches []string) []map[string]string {
+	want := map[string]string{}
+	for _, m := range matches {
+		if i := strings.Index(m, "{"); i >= 0 {
+			for _, pair := range strings.Split(strings.Trim(m[i:], "{}"), ",") {
+				if k, v, ok := strings.Cut(pair, "="); ok {
+					want[k] = strings.Trim(v, `"`)
+				}
+			}
+		}
+	}
+	var out []map[string]string
+	for _, s := range suggestSeries {
+		ok := true
+		for k, v := range want {
+			if s[k] != v {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+func TestHandleSuggestLabelFilters(t *testing.T) {
+	mockServer := newSuggestMockServer(t)
+	defer mockServer.Close()
+
+	ctx := context.Background()
+	sc, err := server.NewServerContext(ctx,
+		server.WithPrometheusConfig(server.PrometheusConfig{URL: mockServer.URL}),
+		server.WithSlogLogger(discardLogger()),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create server context: %v", err)
+	}
+	defer func() { _ = sc.Shutdown() }()
+
+	client, err := NewClient(sc.PrometheusConfig(), sc.Logger())
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+
+	request := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Name:      "suggest_label_filters",
+			Arguments: map[string]any{paramKeyQuery: "rate(http_requests_total[5m])"},
+		},
+	}
+	result, err := handleSuggestLabelFilters(ctx, request, client, sc)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.IsError {
+		t.Fatalf("expected success, got error: %v", result.Content)
+	}
+	text := result.Content[0].(mcp.TextContent).Text
+
+	for _, want := range []string{
+		"http_requests_total (6 series)",
+		`adding {job="api"} reduces from 6 to 5 series (−17%)`,
+		`adding {environment="production"} reduces from 6 to 3 series (−50%)`,
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("expected output to contain %q, got:\n%s", want, text)
+		}
+	}
+	// environment removes more series than job, so it must be ranked first.
+	if strings.Index(text, "environment") > strings.Index(text, `{job=`) {
+		t.Errorf("expected environment suggestion before job suggestion, got:\n%s", text)
+	}
+	// instance has one value per series (6 distinct values) but still < 500,
+	// so it is eligible; __name__ is already matched and must be skipped.
+	if strings.Contains(text, "__name__") {
+		t.Errorf("expected __name__ to be excluded, got:\n%s", text)
+	}
+}
+
+func TestHandleSuggestLabelFiltersInvalidQuery(t *testing.T) {
+	sc, err := server.NewServerContext(context.Background(),
+		server.WithPrometheusConfig(server.PrometheusConfig{URL: "http://localhost:9090"}),
+		server.WithSlogLogger(discardLogger()),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create server context: %v", err)
+	}
+	defer func() { _ = sc.Shutdown() }()
+
+	client, err := NewClient(sc.PrometheusConfig(), sc.Logger())
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+
+	for name, args := range map[string]map[string]any{
+		"missing query": {},
+		"parse error":   {paramKeyQuery: "rate(http_requests_total[5m]"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			result, err := handleSuggestLabelFilters(context.Background(), mcp.CallToolRequest{
+				Params: mcp.CallToolParams{Arguments: args},
+			}, client, sc)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !result.IsError {
+				t.Error("expected IsError=true")
+			}
+		})
+	}
+}
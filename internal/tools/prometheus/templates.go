@@ -0,0 +1,343 @@
+package prometheus
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"text/template"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	mcpserver "github.com/mark3labs/mcp-go/server"
+
+	"github.com/giantswarm/mcp-prometheus/internal/promql"
+	"github.com/giantswarm/mcp-prometheus/internal/server"
+)
+
+const (
+	// toolQueryTemplates is the registered name of the template expansion tool.
+	toolQueryTemplates = "query_templates"
+
+	// toolRegisterTemplate, toolListTemplates and toolDeleteTemplate are the
+	// registered names of the template management tools.
+	toolRegisterTemplate = "register_template"
+	toolListTemplates    = "list_templates"
+	toolDeleteTemplate   = "delete_template"
+)
+
+// parseQueryTemplate parses a PromQL template. References to variables that
+// are not supplied fail at expansion instead of rendering "<no value>".
+func parseQueryTemplate(text string) (*template.Template, error) {
+	tmpl, err := template.New("query").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// expandQueryTemplate renders text with vars and checks that the result is
+// valid PromQL. Variable values are inserted verbatim and never evaluated as
+// template code, so a value containing "{{" cannot inject template actions;
+// values that break the query structure are rejected by the PromQL parser.
+func expandQueryTemplate(text string, vars map[string]string) (string, error) {
+	tmpl, err := parseQueryTemplate(text)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	if err := tmpl.Execute(&b, vars); err != nil {
+		return "", fmt.Errorf("failed to expand template: %w", err)
+	}
+	query := b.String()
+	if _, err := promql.Parse(query); err != nil {
+		return "", fmt.Errorf("expanded query %q is not valid PromQL: %w", query, err)
+	}
+	return query, nil
+}
+
+// extractTemplateVariables reads the variables object, requiring string values.
+func extractTemplateVariables(params map[string]any) (map[string]string, error) {
+	vars := map[string]string{}
+	raw, ok := params["variables"].(map[string]any)
+	if !ok {
+		return vars, nil
+	}
+	for k, v := range raw {
+		s, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("variable %q must have a string value", k)
+		}
+		vars[k] = s
+	}
+	return vars, nil
+}
+
+// handleQueryTemplates handles the query_templates tool. The expanded query
+// runs as a range query when start or end is given and as an instant query
+// otherwise.
+func handleQueryTemplates(ctx context.Context, request mcp.CallToolRequest, client *Client, sc *server.ServerContext) (*mcp.CallToolResult, error) {
+	params := extractParams(request)
+
+	text := getStringParam(params, "template")
+	if name := getStringParam(params, "template_name"); name != "" {
+		if text != "" {
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{
+					mcp.TextContent{
+						Type: contentTypeText,
+						Text: "Error: set either template or template_name, not both",
+					},
+				},
+			}, nil
+		}
+		registered, ok := sc.QueryTemplate(callerScope(ctx), name)
+		if !ok {
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{
+					mcp.TextContent{
+						Type: contentTypeText,
+						Text: fmt.Sprintf("Error: no template registered as %q (see list_templates)", name),
+					},
+				},
+			}, nil
+		}
+		text = registered
+	}
+	if text == "" {
+		return &mcp.CallToolResult{
+			IsError: true,
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: contentTypeText,
+					Text: "Error: template or template_name parameter is required",
+				},
+			},
+		}, nil
+	}
+
+	vars, err := extractTemplateVariables(params)
+	if err != nil {
+		return &mcp.CallToolResult{
+			IsError: true,
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: contentTypeText,
+					Text: fmt.Sprintf("Error: %v", err),
+				},
+			},
+		}, nil
+	}
+
+	query, err := expandQueryTemplate(text, vars)
+	if err != nil {
+		return &mcp.CallToolResult{
+			IsError: true,
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: contentTypeText,
+					Text: fmt.Sprintf("Error: %v", err),
+				},
+			},
+		}, nil
+	}
+
+	args := maps.Clone(params)
+	delete(args, "template")
+	delete(args, "template_name")
+	delete(args, "variables")
+	args["query"] = query
+	request.Params.Arguments = args
+
+	sc.Logger().Debug("Expanded query template", "query", query)
+
+	var result *mcp.CallToolResult
+	if getStringParam(params, "start") != "" || getStringParam(params, "end") != "" {
+		result, err = handleExecuteRangeQuery(ctx, request, client, sc)
+	} else {
+		result, err = handleExecuteQuery(ctx, request, client, sc)
+	}
+	if err != nil || result == nil {
+		return result, err
+	}
+
+	if len(result.Content) > 0 {
+		if tc, ok := result.Content[0].(mcp.TextContent); ok {
+			tc.Text = fmt.Sprintf("Expanded query: %s\n\n%s", query, tc.Text)
+			result.Content[0] = tc
+		}
+	}
+	return result, nil
+}
+
+// registerTemplateTools registers register_template, list_templates and
+// delete_template. They only touch the server's in-memory template store, so
+// like get_server_config they bypass the dynamic client wrapper. Templates
+// registered by an unauthenticated session are dropped when it ends.
+func registerTemplateTools(s *mcpserver.MCPServer, sc *server.ServerContext, middleware []ToolMiddleware) {
+	onSessionEnd(s, sc.DeleteQueryTemplates)
+
+	tools := []struct {
+		tool    mcp.Tool
+		handler func(context.Context, mcp.CallToolRequest, *server.ServerContext) (*mcp.CallToolResult, error)
+	}{
+		{
+			tool: mcp.NewTool(toolRegisterTemplate,
+				mcp.WithDescription("Store a named PromQL template with {{.var}} placeholders for use with query_templates. Templates are private to the caller (the OAuth user, else the MCP session), kept in memory and lost on server restart"),
+				mcp.WithString("name", mcp.Required(), mcp.Description("Template name (replaces an existing template of the same name)")),
+				mcp.WithString("template", mcp.Required(), mcp.Description("PromQL template using Go text/template syntax, e.g. 'rate({{.metric}}[{{.window}}])'")),
+				mcp.WithReadOnlyHintAnnotation(false),
+				mcp.WithDestructiveHintAnnotation(false),
+				mcp.WithOpenWorldHintAnnotation(false),
+				mcp.WithSchemaAdditionalProperties(false),
+			),
+			handler: handleRegisterTemplate,
+		},
+		{
+			tool: mcp.NewTool(toolListTemplates,
+				mcp.WithDescription("List the PromQL templates registered with register_template"),
+				mcp.WithReadOnlyHintAnnotation(true),
+				mcp.WithDestructiveHintAnnotation(false),
+				mcp.WithOpenWorldHintAnnotation(false),
+				mcp.WithSchemaAdditionalProperties(false),
+			),
+			handler: handleListTemplates,
+		},
+		{
+			tool: mcp.NewTool(toolDeleteTemplate,
+				mcp.WithDescription("Delete a PromQL template registered with register_template"),
+				mcp.WithString("name", mcp.Required(), mcp.Description("Name of the template to delete")),
+				mcp.WithReadOnlyHintAnnotation(false),
+				mcp.WithDestructiveHintAnnotation(true),
+				mcp.WithOpenWorldHintAnnotation(false),
+				mcp.WithSchemaAdditionalProperties(false),
+			),
+			handler: handleDeleteTemplate,
+		},
+	}
+
+	for _, t := range tools {
+		handler := t.handler
+		h := withServerMiddleware(sc, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return handler(ctx, request, sc)
+		})
+		for _, mw := range middleware {
+			h = mw(t.tool.Name, h)
+		}
+		s.AddTool(t.tool, h)
+	}
+}
+
+// handleRegisterTemplate handles the register_template tool
+func handleRegisterTemplate(ctx context.Context, request mcp.CallToolRequest, sc *server.ServerContext) (*mcp.CallToolResult, error) {
+	params := extractParams(request)
+
+	name := getStringParam(params, "name")
+	text := getStringParam(params, "template")
+	if name == "" || text == "" {
+		return &mcp.CallToolResult{
+			IsError: true,
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: contentTypeText,
+					Text: "Error: name and template parameters are required",
+				},
+			},
+		}, nil
+	}
+	if _, err := parseQueryTemplate(text); err != nil {
+		return &mcp.CallToolResult{
+			IsError: true,
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: contentTypeText,
+					Text: fmt.Sprintf("Error: %v", err),
+				},
+			},
+		}, nil
+	}
+
+	replaced, err := sc.SetQueryTemplate(callerScope(ctx), name, text)
+	if err != nil {
+		return &mcp.CallToolResult{
+			IsError: true,
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: contentTypeText,
+					Text: fmt.Sprintf("Error: %v", err),
+				},
+			},
+		}, nil
+	}
+	verb := "Registered"
+	if replaced {
+		verb = "Replaced"
+	}
+	sc.Logger().Debug("Registered query template", "name", name)
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.TextContent{
+				Type: contentTypeText,
+				Text: fmt.Sprintf("%s template %q: %s", verb, name, text),
+			},
+		},
+	}, nil
+}
+
+// handleListTemplates handles the list_templates tool
+func handleListTemplates(ctx context.Context, _ mcp.CallToolRequest, sc *server.ServerContext) (*mcp.CallToolResult, error) {
+	templates := sc.QueryTemplates(callerScope(ctx))
+	if len(templates) == 0 {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: contentTypeText,
+					Text: "No query templates registered. Use register_template to add one.",
+				},
+			},
+		}, nil
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d query templates:\n\nname | template\n", len(templates))
+	for _, name := range slices.Sorted(maps.Keys(templates)) {
+		fmt.Fprintf(&b, "%s | %s\n", name, templates[name])
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.TextContent{
+				Type: contentTypeText,
+				Text: b.String(),
+			},
+		},
+	}, nil
+}
+
+// handleDeleteTemplate handles the delete_template tool
+func handleDeleteTemplate(ctx context.Context, request mcp.CallToolRequest, sc *server.ServerContext) (*mcp.CallToolResult, error) {
+	name := getStringParam(extractParams(request), "name")
+	if !sc.DeleteQueryTemplate(callerScope(ctx), name) {
+		return &mcp.CallToolResult{
+			IsError: true,
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: contentTypeText,
+					Text: fmt.Sprintf("Error: no template registered as %q", name),
+				},
+			},
+		}, nil
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.TextContent{
+				Type: contentTypeText,
+				Text: fmt.Sprintf("Deleted template %q", name),
+			},
+		},
+	}, nil
+}
@@ -0,0 +1,261 @@
+package prometheus
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	mcpserver "github.com/mark3labs/mcp-go/server"
+
+	"github.com/giantswarm/mcp-prometheus/internal/server"
+)
+
+func TestExpandQueryTemplate(t *testing.T) {
+	tests := []struct {
+		name    string
+		tmpl    string
+		vars    map[string]string
+		want    string
+		wantErr string
+	}{
+		{
+			name: "substitutes variables",
+			tmpl: "rate({{.metric}}[{{.window}}])",
+			vars: map[string]string{"metric": "http_requests_total", "window": "5m"},
+			want: "rate(http_requests_total[5m])",
+		},
+		{
+			name:    "missing variable",
+			tmpl:    "rate({{.metric}}[{{.window}}])",
+			vars:    map[string]string{"metric": "http_requests_total"},
+			wantErr: "failed to expand template",
+		},
+		{
+			name:    "template actions in template are rejected",
+			tmpl:    "{{.metric = \"foo\n{{range\"}}",
+			vars:    map[string]string{"metric": "up"},
+			wantErr: "invalid template",
+		},
+		{
+			name:    "template actions in values are not evaluated",
+			tmpl:    "{{.metric}}",
+			vars:    map[string]string{"metric": "foo\n{{range .}}{{end}}"},
+			wantErr: "is not valid PromQL",
+		},
+		{
+			name:    "values breaking out of a selector are rejected",
+			tmpl:    `up{job="{{.job}}"}`,
+			vars:    map[string]string{"job": `api"}) or vector(1`},
+			wantErr: "is not valid PromQL",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := expandQueryTemplate(tt.tmpl, tt.vars)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expandQueryTemplate() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expandQueryTemplate() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("expandQueryTemplate() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHandleQueryTemplates(t *testing.T) {
+	var gotQuery, gotPath string
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		gotPath = r.URL.Path
+		gotQuery = r.Form.Get(paramKeyQuery)
+		resultType := respValVector
+		if r.URL.Path == "/api/v1/query_range" {
+			resultType = "matrix"
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			respKeyStatus: respValSuccess,
+			respKeyData:   map[string]any{respKeyResultType: resultType, respKeyResult: []any{}},
+		})
+	}))
+	defer mockServer.Close()
+
+	ctx := context.Background()
+	sc, err := server.NewServerContext(ctx,
+		server.WithPrometheusConfig(server.PrometheusConfig{URL: mockServer.URL}),
+		server.WithSlogLogger(discardLogger()),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create server context: %v", err)
+	}
+	defer func() { _ = sc.Shutdown() }()
+
+	client, err := NewClient(sc.PrometheusConfig(), sc.Logger())
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+
+	call := func(args map[string]any) (*mcp.CallToolResult, string) {
+		t.Helper()
+		request := mcp.CallToolRequest{Params: mcp.CallToolParams{Name: toolQueryTemplates, Arguments: args}}
+		result, err := handleQueryTemplates(ctx, request, client, sc)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return result, result.Content[0].(mcp.TextContent).Text
+	}
+
+	result, text := call(map[string]any{
+		"template":  "rate({{.metric}}[{{.window}}])",
+		"variables": map[string]any{"metric": "http_requests_total", "window": "5m"},
+	})
+	if result.IsError {
+		t.Fatalf("expected success, got error: %s", text)
+	}
+	if gotPath != apiQueryPath || gotQuery != "rate(http_requests_total[5m])" {
+		t.Errorf("Prometheus received %s %q", gotPath, gotQuery)
+	}
+	if !strings.HasPrefix(text, "Expanded query: rate(http_requests_total[5m])\n\n") {
+		t.Errorf("unexpected output:\n%s", text)
+	}
+
+	_, _ = sc.SetQueryTemplate("", "errors", `sum(rate({{.metric}}{code=~"5.."}[5m]))`)
+	result, text = call(map[string]any{
+		"template_name": "errors",
+		"variables":     map[string]any{"metric": "http_requests_total"},
+		"start":         "1704067200",
+		"end":           "1704070800",
+		"step":          "1m",
+	})
+	if result.IsError {
+		t.Fatalf("expected success, got error: %s", text)
+	}
+	if gotPath != "/api/v1/query_range" || gotQuery != `sum(rate(http_requests_total{code=~"5.."}[5m]))` {
+		t.Errorf("Prometheus received %s %q", gotPath, gotQuery)
+	}
+
+	for name, args := range map[string]map[string]any{
+		"no template":      {},
+		"unknown template": {"template_name": "missing"},
+		"non-string value": {"template": "{{.metric}}", "variables": map[string]any{"metric": 1}},
+	} {
+		if result, text := call(args); !result.IsError {
+			t.Errorf("%s: expected error, got:\n%s", name, text)
+		}
+	}
+}
+
+func TestTemplateManagementTools(t *testing.T) {
+	ctx := context.Background()
+	sc, err := server.NewServerContext(ctx,
+		server.WithPrometheusConfig(server.PrometheusConfig{URL: "http://prometheus:9090"}),
+		server.WithSlogLogger(discardLogger()),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create server context: %v", err)
+	}
+	defer func() { _ = sc.Shutdown() }()
+
+	request := func(args map[string]any) mcp.CallToolRequest {
+		return mcp.CallToolRequest{Params: mcp.CallToolParams{Arguments: args}}
+	}
+	text := func(result *mcp.CallToolResult) string {
+		return result.Content[0].(mcp.TextContent).Text
+	}
+
+	result, _ := handleListTemplates(ctx, request(nil), sc)
+	if !strings.HasPrefix(text(result), "No query templates registered") {
+		t.Errorf("unexpected empty list output: %s", text(result))
+	}
+
+	result, _ = handleRegisterTemplate(ctx, request(map[string]any{"name": "rate", "template": "rate({{.metric}}[5m])"}), sc)
+	if result.IsError || !strings.HasPrefix(text(result), `Registered template "rate"`) {
+		t.Errorf("unexpected register output: %s", text(result))
+	}
+	result, _ = handleRegisterTemplate(ctx, request(map[string]any{"name": "rate", "template": "rate({{.metric}}[{{.window}}])"}), sc)
+	if result.IsError || !strings.HasPrefix(text(result), `Replaced template "rate"`) {
+		t.Errorf("unexpected replace output: %s", text(result))
+	}
+	result, _ = handleRegisterTemplate(ctx, request(map[string]any{"name": "bad", "template": "{{range"}), sc)
+	if !result.IsError {
+		t.Errorf("expected invalid template to be rejected, got: %s", text(result))
+	}
+
+	result, _ = handleListTemplates(ctx, request(nil), sc)
+	if want := "1 query templates:\n\nname | template\nrate | rate({{.metric}}[{{.window}}])\n"; text(result) != want {
+		t.Errorf("list output = %q, want %q", text(result), want)
+	}
+
+	result, _ = handleDeleteTemplate(ctx, request(map[string]any{"name": "rate"}), sc)
+	if result.IsError {
+		t.Errorf("unexpected delete error: %s", text(result))
+	}
+	result, _ = handleDeleteTemplate(ctx, request(map[string]any{"name": "rate"}), sc)
+	if !result.IsError {
+		t.Errorf("expected deleting a missing template to fail, got: %s", text(result))
+	}
+}
+
+// idSession is a ClientSession with a chosen ID.
+type idSession struct {
+	notificationSession
+	id string
+}
+
+func (s *idSession) SessionID() string { return s.id }
+
+func TestTemplatesScopedPerSession(t *testing.T) {
+	ctx := context.Background()
+	sc, err := server.NewServerContext(ctx,
+		server.WithPrometheusConfig(server.PrometheusConfig{URL: "http://prometheus:9090"}),
+		server.WithSlogLogger(discardLogger()),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create server context: %v", err)
+	}
+	defer func() { _ = sc.Shutdown() }()
+
+	srv := mcpserver.NewMCPServer("test", "1.0.0", mcpserver.WithToolCapabilities(true))
+	registerTemplateTools(srv, sc, nil)
+	alice, bob := &idSession{id: "alice"}, &idSession{id: "bob"}
+	for _, session := range []*idSession{alice, bob} {
+		if err := srv.RegisterSession(ctx, session); err != nil {
+			t.Fatalf("RegisterSession: %v", err)
+		}
+	}
+	aliceCtx, bobCtx := srv.WithContext(ctx, alice), srv.WithContext(ctx, bob)
+
+	request := func(args map[string]any) mcp.CallToolRequest {
+		return mcp.CallToolRequest{Params: mcp.CallToolParams{Arguments: args}}
+	}
+	result, _ := handleRegisterTemplate(aliceCtx, request(map[string]any{"name": "rate", "template": "rate({{.metric}}[5m])"}), sc)
+	if result.IsError {
+		t.Fatalf("unexpected register error: %v", result.Content)
+	}
+
+	result, _ = handleListTemplates(bobCtx, request(nil), sc)
+	if text := result.Content[0].(mcp.TextContent).Text; !strings.HasPrefix(text, "No query templates registered") {
+		t.Errorf("another session sees the template:\n%s", text)
+	}
+	if result, _ = handleDeleteTemplate(bobCtx, request(map[string]any{"name": "rate"}), sc); !result.IsError {
+		t.Error("another session deleted the template")
+	}
+	if _, ok := sc.QueryTemplate(callerScope(aliceCtx), "rate"); !ok {
+		t.Fatal("template missing from its session's scope")
+	}
+
+	srv.UnregisterSession(ctx, alice.id)
+	if _, ok := sc.QueryTemplate(callerScope(aliceCtx), "rate"); ok {
+		t.Error("template kept after its session ended")
+	}
+}
@@ -0,0 +1,208 @@
+package prometheus
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	mcpserver "github.com/mark3labs/mcp-go/server"
+
+	"github.com/giantswarm/mcp-prometheus/internal/server"
+)
+
+const (
+	// toolRegisterTestTarget and toolDeregisterTestTarget are the registered
+	// names of the test scrape target tools.
+	toolRegisterTestTarget   = "register_test_target"
+	toolDeregisterTestTarget = "deregister_test_target"
+)
+
+// extractMetricDefs reads the metric_defs array of the register_test_target
+// tool. Values may be given as JSON numbers or numeric strings.
+func extractMetricDefs(params map[string]any) ([]server.TestMetricDef, error) {
+	raw, _ := params["metric_defs"].([]any)
+	if len(raw) == 0 {
+		return nil, fmt.Errorf("metric_defs parameter is required and must contain at least one metric definition")
+	}
+
+	defs := make([]server.TestMetricDef, 0, len(raw))
+	for i, item := range raw {
+		obj, ok := item.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("metric_defs[%d] must be an object", i)
+		}
+		def := server.TestMetricDef{
+			Name: getStringParam(obj, "name"),
+			Type: getStringParam(obj, "type"),
+			Help: getStringParam(obj, "help"),
+		}
+		if def.Name == "" {
+			return nil, fmt.Errorf("metric_defs[%d]: name is required", i)
+		}
+
+		switch v := obj["value"].(type) {
+		case nil:
+		case float64:
+			def.Value = v
+		case string:
+			f, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				return nil, fmt.Errorf("metric_defs[%d]: value %q is not a number", i, v)
+			}
+			def.Value = f
+		default:
+			return nil, fmt.Errorf("metric_defs[%d]: value must be a number", i)
+		}
+
+		if labels, ok := obj["labels"].(map[string]any); ok {
+			def.Labels = make(map[string]string, len(labels))
+			for k, v := range labels {
+				s, ok := v.(string)
+				if !ok {
+					return nil, fmt.Errorf("metric_defs[%d]: label %q must have a string value", i, k)
+				}
+				def.Labels[k] = s
+			}
+		}
+		defs = append(defs, def)
+	}
+	return defs, nil
+}
+
+// registerTestTargetTools registers the tools that start and stop local
+// scrape targets. They do not talk to Prometheus, so they take no
+// prometheus_url or org_id.
+func registerTestTargetTools(s *mcpserver.MCPServer, sc *server.ServerContext, middleware []ToolMiddleware) {
+	tools := []struct {
+		tool    mcp.Tool
+		handler func(context.Context, mcp.CallToolRequest, *server.ServerContext) (*mcp.CallToolResult, error)
+	}{
+		{
+			tool: mcp.NewTool(toolRegisterTestTarget,
+				mcp.WithDescription("Start a temporary scrape target on a free localhost port exposing the given gauge or counter metrics on /metrics, for testing alerting and recording rules. Returns the target URL to add to the Prometheus scrape configuration. The target runs until deregister_test_target, the end of the session or server shutdown, or until it has not been scraped for an hour; each caller may run 5 targets"),
+				mcp.WithArray("metric_defs", mcp.Required(), mcp.Description("Metrics to expose, e.g. [{\"name\": \"test_queue_depth\", \"type\": \"gauge\", \"value\": 42, \"labels\": {\"queue\": \"orders\"}}]"),
+					mcp.Items(map[string]any{
+						"type": "object",
+						"properties": map[string]any{
+							"name":   map[string]any{"type": "string", "description": "Metric name"},
+							"type":   map[string]any{"type": "string", "enum": []string{server.TestMetricGauge, server.TestMetricCounter}},
+							"help":   map[string]any{"type": "string", "description": "HELP text"},
+							"value":  map[string]any{"type": "number", "description": "Sample value (default: 0); counters must not be negative"},
+							"labels": map[string]any{"type": "object", "additionalProperties": map[string]any{"type": "string"}},
+						},
+						"required": []string{"name", "type"},
+					}),
+				),
+				mcp.WithReadOnlyHintAnnotation(false),
+				mcp.WithDestructiveHintAnnotation(false),
+				mcp.WithOpenWorldHintAnnotation(false),
+				mcp.WithSchemaAdditionalProperties(false),
+			),
+			handler: handleRegisterTestTarget,
+		},
+		{
+			tool: mcp.NewTool(toolDeregisterTestTarget,
+				mcp.WithDescription("Stop a scrape target started with register_test_target"),
+				mcp.WithString("target_id", mcp.Required(), mcp.Description("ID returned by register_test_target")),
+				mcp.WithReadOnlyHintAnnotation(false),
+				mcp.WithDestructiveHintAnnotation(true),
+				mcp.WithOpenWorldHintAnnotation(false),
+				mcp.WithSchemaAdditionalProperties(false),
+			),
+			handler: handleDeregisterTestTarget,
+		},
+	}
+
+	for _, t := range tools {
+		handler := t.handler
+		h := withServerMiddleware(sc, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return handler(ctx, request, sc)
+		})
+		for _, mw := range middleware {
+			h = mw(t.tool.Name, h)
+		}
+		s.AddTool(t.tool, h)
+	}
+
+	onSessionEnd(s, func(scope string) {
+		if err := sc.StopTestTargets(scope); err != nil {
+			sc.Logger().Warn("Test targets of an ended session did not stop cleanly", "scope", scope, "error", err)
+		}
+	})
+}
+
+// handleRegisterTestTarget handles the register_test_target tool
+func handleRegisterTestTarget(ctx context.Context, request mcp.CallToolRequest, sc *server.ServerContext) (*mcp.CallToolResult, error) {
+	defs, err := extractMetricDefs(extractParams(request))
+	if err != nil {
+		return &mcp.CallToolResult{
+			IsError: true,
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: contentTypeText,
+					Text: fmt.Sprintf("Error: %v", err),
+				},
+			},
+		}, nil
+	}
+
+	target, err := sc.StartTestTarget(callerScope(ctx), defs)
+	if err != nil {
+		return &mcp.CallToolResult{
+			IsError: true,
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: contentTypeText,
+					Text: fmt.Sprintf("Error starting test target: %v", err),
+				},
+			},
+		}, nil
+	}
+	sc.Logger().Info("Started test target", "id", target.ID, "url", target.URL, "metrics", target.Metrics)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Started test target %s serving %d metrics (%s).\n\n", target.ID, len(target.Metrics), strings.Join(target.Metrics, ", "))
+	fmt.Fprintf(&b, "Scrape URL: %s\n\n", target.URL)
+	b.WriteString("The target listens on localhost, so only a Prometheus running on this host can scrape it. ")
+	fmt.Fprintf(&b, "Stop it with deregister_test_target and target_id %q; it also stops when this session ends or after an hour without scrapes.\n", target.ID)
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.TextContent{
+				Type: contentTypeText,
+				Text: b.String(),
+			},
+		},
+	}, nil
+}
+
+// handleDeregisterTestTarget handles the deregister_test_target tool
+func handleDeregisterTestTarget(ctx context.Context, request mcp.CallToolRequest, sc *server.ServerContext) (*mcp.CallToolResult, error) {
+	id := getStringParam(extractParams(request), "target_id")
+	stopped, err := sc.StopTestTarget(callerScope(ctx), id)
+	if !stopped {
+		return &mcp.CallToolResult{
+			IsError: true,
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: contentTypeText,
+					Text: fmt.Sprintf("Error: no test target running as %q", id),
+				},
+			},
+		}, nil
+	}
+	if err != nil {
+		sc.Logger().Warn("Test target did not stop cleanly", "id", id, "error", err)
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.TextContent{
+				Type: contentTypeText,
+				Text: fmt.Sprintf("Stopped test target %s", id),
+			},
+		},
+	}, nil
+}
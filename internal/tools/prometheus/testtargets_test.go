@@ -0,0 +1,154 @@
+package prometheus
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	mcpserver "github.com/mark3labs/mcp-go/server"
+
+	"github.com/giantswarm/mcp-prometheus/internal/server"
+)
+
+func TestExtractMetricDefs(t *testing.T) {
+	tests := []struct {
+		name    string
+		params  map[string]any
+		want    server.TestMetricDef
+		wantErr string
+	}{
+		{
+			name: "number value and labels",
+			params: map[string]any{"metric_defs": []any{
+				map[string]any{"name": "queue_depth", "type": "gauge", "value": 42.0, "labels": map[string]any{"queue": "orders"}},
+			}},
+			want: server.TestMetricDef{Name: "queue_depth", Type: "gauge", Value: 42, Labels: map[string]string{"queue": "orders"}},
+		},
+		{
+			name: "string value",
+			params: map[string]any{"metric_defs": []any{
+				map[string]any{"name": "errors_total", "type": "counter", "value": "1.5"},
+			}},
+			want: server.TestMetricDef{Name: "errors_total", Type: "counter", Value: 1.5},
+		},
+		{name: "missing", params: map[string]any{}, wantErr: "metric_defs parameter is required"},
+		{name: "not an object", params: map[string]any{"metric_defs": []any{"up"}}, wantErr: "must be an object"},
+		{name: "missing name", params: map[string]any{"metric_defs": []any{map[string]any{"type": "gauge"}}}, wantErr: "name is required"},
+		{name: "bad value", params: map[string]any{"metric_defs": []any{map[string]any{"name": "m", "type": "gauge", "value": "high"}}}, wantErr: "is not a number"},
+		{name: "bad label", params: map[string]any{"metric_defs": []any{map[string]any{"name": "m", "type": "gauge", "labels": map[string]any{"a": 1.0}}}}, wantErr: "string value"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := extractMetricDefs(tt.params)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("extractMetricDefs() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("extractMetricDefs(): %v", err)
+			}
+			if len(got) != 1 || got[0].Name != tt.want.Name || got[0].Type != tt.want.Type || got[0].Value != tt.want.Value || len(got[0].Labels) != len(tt.want.Labels) {
+				t.Errorf("extractMetricDefs() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTestTargetTools(t *testing.T) {
+	ctx := context.Background()
+	sc, err := server.NewServerContext(ctx, server.WithSlogLogger(discardLogger()))
+	if err != nil {
+		t.Fatalf("Failed to create server context: %v", err)
+	}
+	defer func() { _ = sc.Shutdown() }()
+
+	request := func(args map[string]any) mcp.CallToolRequest {
+		return mcp.CallToolRequest{Params: mcp.CallToolParams{Arguments: args}}
+	}
+	text := func(result *mcp.CallToolResult) string {
+		return result.Content[0].(mcp.TextContent).Text
+	}
+
+	result, _ := handleRegisterTestTarget(ctx, request(map[string]any{"metric_defs": []any{
+		map[string]any{"name": "test_up", "type": "gauge", "value": 1.0, "labels": map[string]any{"job": "rule-test"}},
+	}}), sc)
+	if result.IsError {
+		t.Fatalf("register_test_target failed: %s", text(result))
+	}
+	targets := sc.TestTargets("")
+	if len(targets) != 1 || !strings.Contains(text(result), "Scrape URL: "+targets[0].URL) {
+		t.Fatalf("unexpected register output %q for targets %+v", text(result), targets)
+	}
+
+	resp, err := http.Get(targets[0].URL)
+	if err != nil {
+		t.Fatalf("scrape test target: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if !strings.Contains(string(body), `test_up{job="rule-test"} 1`) {
+		t.Errorf("scrape output missing test_up:\n%s", body)
+	}
+
+	result, _ = handleRegisterTestTarget(ctx, request(map[string]any{"metric_defs": []any{
+		map[string]any{"name": "m", "type": "summary"},
+	}}), sc)
+	if !result.IsError || !strings.Contains(text(result), "type must be") {
+		t.Errorf("expected unsupported type to fail, got: %s", text(result))
+	}
+
+	result, _ = handleDeregisterTestTarget(ctx, request(map[string]any{"target_id": targets[0].ID}), sc)
+	if result.IsError {
+		t.Errorf("deregister_test_target failed: %s", text(result))
+	}
+	result, _ = handleDeregisterTestTarget(ctx, request(map[string]any{"target_id": targets[0].ID}), sc)
+	if !result.IsError {
+		t.Errorf("expected deregistering a stopped target to fail, got: %s", text(result))
+	}
+}
+
+func TestTestTargetsStopWithSession(t *testing.T) {
+	ctx := context.Background()
+	sc, err := server.NewServerContext(ctx, server.WithSlogLogger(discardLogger()))
+	if err != nil {
+		t.Fatalf("Failed to create server context: %v", err)
+	}
+	defer func() { _ = sc.Shutdown() }()
+
+	srv := mcpserver.NewMCPServer("test", "1.0.0", mcpserver.WithToolCapabilities(true))
+	registerTestTargetTools(srv, sc, nil)
+	alice, bob := &idSession{id: "alice"}, &idSession{id: "bob"}
+	for _, session := range []*idSession{alice, bob} {
+		if err := srv.RegisterSession(ctx, session); err != nil {
+			t.Fatalf("RegisterSession: %v", err)
+		}
+	}
+	aliceCtx, bobCtx := srv.WithContext(ctx, alice), srv.WithContext(ctx, bob)
+
+	request := func(args map[string]any) mcp.CallToolRequest {
+		return mcp.CallToolRequest{Params: mcp.CallToolParams{Arguments: args}}
+	}
+	result, _ := handleRegisterTestTarget(aliceCtx, request(map[string]any{"metric_defs": []any{
+		map[string]any{"name": "test_up", "type": "gauge"},
+	}}), sc)
+	if result.IsError {
+		t.Fatalf("register_test_target failed: %v", result.Content)
+	}
+	targets := sc.TestTargets(callerScope(aliceCtx))
+	if len(targets) != 1 {
+		t.Fatalf("got targets %+v, want one for the session", targets)
+	}
+	if result, _ = handleDeregisterTestTarget(bobCtx, request(map[string]any{"target_id": targets[0].ID}), sc); !result.IsError {
+		t.Error("another session stopped the target")
+	}
+
+	srv.UnregisterSession(ctx, alice.id)
+	if got := sc.TestTargets(callerScope(aliceCtx)); len(got) != 0 {
+		t.Errorf("targets kept after their session ended: %+v", got)
+	}
+}
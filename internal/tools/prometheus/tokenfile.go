@@ -0,0 +1,97 @@
+package prometheus
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// tokenFileRefreshInterval is how long a token read from a file is used
+// before the file is read again. Kubernetes rotates projected service account
+// tokens well before they expire, so a minute is ample. It is a variable so
+// tests can shorten it.
+var tokenFileRefreshInterval = time.Minute
+
+// tokenFile reads a bearer token, or a basic auth password, from a file and
+// re-reads it once the cached copy is older than interval.
+type tokenFile struct {
+	path     string
+	interval time.Duration
+	logger   *slog.Logger
+
+	mu      sync.Mutex
+	token   string
+	readAt  time.Time
+	readErr error
+}
+
+// newTokenFile reads the token at path, failing when the file cannot be read
+// or is empty.
+func newTokenFile(path string, logger *slog.Logger) (*tokenFile, error) {
+	f := &tokenFile{path: path, interval: tokenFileRefreshInterval, logger: logger}
+	if _, err := f.Token(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// Token returns the current token. When re-reading the file fails the
+// previous token keeps being used, so a rotation caught mid-write does not
+// fail requests; the failure is logged once.
+func (f *tokenFile) Token() (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.token != "" && time.Since(f.readAt) < f.interval {
+		return f.token, nil
+	}
+
+	token, err := readTokenFile(f.path)
+	if err != nil {
+		if f.token == "" {
+			return "", err
+		}
+		if f.readErr == nil {
+			f.logger.Warn("Failed to re-read token file, using the previous token", "path", f.path, "error", err)
+		}
+		f.readErr = err
+		return f.token, nil
+	}
+	if token != f.token && f.token != "" {
+		f.logger.Debug("Token file changed", "path", f.path)
+	}
+	f.token, f.readAt, f.readErr = token, time.Now(), nil
+	return token, nil
+}
+
+// readTokenFile returns the trimmed contents of the token file at path.
+func readTokenFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("read token file: %w", err)
+	}
+	token := strings.TrimSpace(string(data))
+	if token == "" {
+		return "", fmt.Errorf("read token file %q: file is empty", path)
+	}
+	return token, nil
+}
+
+// tokenFileRoundTripper adds the bearer token from a tokenFile to requests.
+type tokenFileRoundTripper struct {
+	file *tokenFile
+	rt   http.RoundTripper
+}
+
+func (t *tokenFileRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	token, err := t.file.Token()
+	if err != nil {
+		return nil, err
+	}
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "Bearer "+token)
+	return t.rt.RoundTrip(req)
+}
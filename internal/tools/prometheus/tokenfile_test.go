@@ -0,0 +1,156 @@
+package prometheus
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/giantswarm/mcp-prometheus/internal/server"
+)
+
+func TestNewClientTokenFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(path, []byte("token-1\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	var mu sync.Mutex
+	var seen []string
+	prometheus := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		seen = append(seen, r.Header.Get("Authorization"))
+		mu.Unlock()
+		tlsQueryHandler(w, r)
+	}))
+	defer prometheus.Close()
+
+	interval := tokenFileRefreshInterval
+	tokenFileRefreshInterval = 0
+	t.Cleanup(func() { tokenFileRefreshInterval = interval })
+
+	client, err := NewClient(server.PrometheusConfig{URL: prometheus.URL, TokenFile: path}, discardLogger())
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+
+	ctx := context.Background()
+	query := func() {
+		t.Helper()
+		if _, err := client.ExecuteQuery(ctx, "up", ""); err != nil {
+			t.Fatalf("ExecuteQuery: %v", err)
+		}
+	}
+	query()
+	// A rotated token is picked up on the next request.
+	if err := os.WriteFile(path, []byte("token-2"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	query()
+	// A missing file keeps the last token in use.
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	query()
+
+	mu.Lock()
+	defer mu.Unlock()
+	want := []string{"Bearer token-1", "Bearer token-2", "Bearer token-2"}
+	if len(seen) != len(want) {
+		t.Fatalf("saw %q, want %q", seen, want)
+	}
+	for i := range want {
+		if seen[i] != want[i] {
+			t.Errorf("request %d Authorization = %q, want %q", i, seen[i], want[i])
+		}
+	}
+}
+
+func TestNewClientPasswordFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "password")
+	if err := os.WriteFile(path, []byte("glc-1\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	var mu sync.Mutex
+	var seen []string
+	prometheus := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, password, _ := r.BasicAuth()
+		mu.Lock()
+		seen = append(seen, password)
+		mu.Unlock()
+		tlsQueryHandler(w, r)
+	}))
+	defer prometheus.Close()
+
+	interval := tokenFileRefreshInterval
+	tokenFileRefreshInterval = 0
+	t.Cleanup(func() { tokenFileRefreshInterval = interval })
+
+	if _, err := NewClient(server.PrometheusConfig{URL: prometheus.URL, Username: "123456", Password: "p", PasswordFile: path}, discardLogger()); err == nil {
+		t.Error("expected an error for both a password and a password file")
+	}
+	client, err := NewClient(server.PrometheusConfig{URL: prometheus.URL, Username: "123456", PasswordFile: path}, discardLogger())
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+
+	for _, password := range []string{"", "glc-2"} {
+		if password != "" {
+			if err := os.WriteFile(path, []byte(password), 0o600); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if _, err := client.ExecuteQuery(context.Background(), "up", ""); err != nil {
+			t.Fatalf("ExecuteQuery: %v", err)
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(seen) != 2 || seen[0] != "glc-1" || seen[1] != "glc-2" {
+		t.Errorf("saw passwords %q, want the rotated one on the second request", seen)
+	}
+}
+
+func TestTokenFileRefreshInterval(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(path, []byte("token-1"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	file, err := newTokenFile(path, discardLogger())
+	if err != nil {
+		t.Fatalf("newTokenFile: %v", err)
+	}
+	if err := os.WriteFile(path, []byte("token-2"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := file.Token(); got != "token-1" {
+		t.Errorf("Token() within the interval = %q, want the cached token-1", got)
+	}
+	file.readAt = time.Now().Add(-tokenFileRefreshInterval)
+	if got, _ := file.Token(); got != "token-2" {
+		t.Errorf("Token() after the interval = %q, want token-2", got)
+	}
+}
+
+func TestNewClientTokenFileErrors(t *testing.T) {
+	dir := t.TempDir()
+	empty := filepath.Join(dir, "empty")
+	if err := os.WriteFile(empty, []byte("\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	for name, config := range map[string]server.PrometheusConfig{
+		"missing file":     {URL: "http://prometheus:9090", TokenFile: filepath.Join(dir, "missing")},
+		"empty file":       {URL: "http://prometheus:9090", TokenFile: empty},
+		"token and a file": {URL: "http://prometheus:9090", Token: "t", TokenFile: empty},
+	} {
+		if _, err := NewClient(config, discardLogger()); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
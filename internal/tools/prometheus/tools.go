@@ -1,25 +1,45 @@
 package prometheus
 
 import (
+	"cmp"
 	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"maps"
+	"net/http"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 	"unicode/utf8"
 
 	"github.com/giantswarm/mcp-oauth/handler"
 	"github.com/mark3labs/mcp-go/mcp"
 	mcpserver "github.com/mark3labs/mcp-go/server"
+	v1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/prometheus/common/model"
 
+	"github.com/giantswarm/mcp-prometheus/internal/analysis"
+	"github.com/giantswarm/mcp-prometheus/internal/filter"
+	"github.com/giantswarm/mcp-prometheus/internal/format"
+	"github.com/giantswarm/mcp-prometheus/internal/promql"
 	"github.com/giantswarm/mcp-prometheus/internal/server"
 	"github.com/giantswarm/mcp-prometheus/internal/tenancy"
 )
 
 // Constants for result truncation
 const (
+	// MaxResultLength is the default number of characters after which tool
+	// result text is truncated. Operators change it with
+	// server.WithMaxResultLength (--max-result-length).
 	MaxResultLength  = 50000
 	TruncationAdvice = `
 
-⚠️  RESULT TRUNCATED: The query returned a very large result (>50k characters).
+⚠️  RESULT TRUNCATED: The query returned a very large result.
 
 💡 To optimize your query and get less output, consider:
    • Adding more specific label filters: {app="specific-app", namespace="specific-ns"}
@@ -35,7 +55,7 @@ const (
 	// limits, so the advice nudges the caller toward narrower requests.
 	discoveryAdvice = `
 
-⚠️  RESULT TRUNCATED: The response exceeded 50k characters.
+⚠️  RESULT TRUNCATED: The response exceeded the result length limit.
 
 💡 To get a smaller, more focused result, consider:
    • Passing a tighter "matches" selector (e.g. {namespace="my-ns", job="my-job"})
@@ -48,20 +68,31 @@ const (
 	// at execute_query with a narrower selector.
 	alertsAdvice = `
 
-⚠️  RESULT TRUNCATED: The response exceeded 50k characters.
+⚠️  RESULT TRUNCATED: The response exceeded the result length limit.
 
 💡 To narrow the result, query the ALERTS series directly via "execute_query":
    • execute_query with ALERTS{alertname="..."} to inspect a specific alert
    • execute_query with ALERTS{severity="critical"} to filter by label
    • Combine with topk() / count() to summarise rather than enumerate`
 
+	// alertmanagerAlertsAdvice is appended when get_alertmanager_alerts
+	// output is truncated; Alertmanager filters alerts server side.
+	alertmanagerAlertsAdvice = `
+
+⚠️  RESULT TRUNCATED: The response exceeded the result length limit.
+
+💡 To narrow the result, let Alertmanager filter the alerts:
+   • Pass "filter" matchers, e.g. ['alertname="..."'] or ['severity="critical"']
+   • Pass a "receiver" regular expression to see one routing target
+   • Set "silenced" / "inhibited" to 'false' to only list alerts that notify`
+
 	// bulkAdvice is appended when tools that return server-wide state are
 	// truncated and there is no narrower API on the Prometheus/Mimir side
 	// (get_rules, get_targets, get_config, get_tsdb_stats). The honest answer
 	// is "fetch less or filter on the client side."
 	bulkAdvice = `
 
-⚠️  RESULT TRUNCATED: The response exceeded 50k characters.
+⚠️  RESULT TRUNCATED: The response exceeded the result length limit.
 
 💡 This tool returns the full server-side state and has no narrower API. Options:
    • If the tool exposes a "limit" parameter, pass one
@@ -87,17 +118,33 @@ const (
 	// errQueryParameterRequired is returned when a query handler is called without
 	// the required "query" argument.
 	errQueryParameterRequired = "Error: query parameter is required and must be a string"
+
+	// defaultSeriesHistogramTopN is the number of label values find_series
+	// lists with histogram_label when top_n is not given.
+	defaultSeriesHistogramTopN = 20
+
+	// DefaultRangeQueryPoints is the number of samples per series that
+	// execute_range_query sizes its step for when no step is given.
+	// Operators change it with server.WithRangeQueryPoints
+	// (--range-query-points).
+	DefaultRangeQueryPoints = 250
 )
 
 // Common parameter builders to reduce repetition
 func withPrometheusConnectionParams(options ...mcp.ToolOption) []mcp.ToolOption {
 	connectionParams := []mcp.ToolOption{
 		mcp.WithString("prometheus_url",
-			mcp.Description("Prometheus server URL (e.g., 'http://localhost:8080/prometheus')"),
+			mcp.Description("Prometheus server URL (e.g., 'http://localhost:8080/prometheus'). Rejected when the default server or profile has credentials, which are never sent to another URL"),
 		),
 		mcp.WithString("org_id",
 			mcp.Description("Organization ID for multi-tenant Prometheus"),
 		),
+		mcp.WithString("allow_multi_org",
+			mcp.Description("Set to 'true' to allow a pipe-separated org_id that queries several tenants (e.g. 'team-a|team-b')"),
+		),
+		mcp.WithString("profile",
+			mcp.Description("Name of a Prometheus endpoint profile from the server's configuration file; org_id still overrides its org ID, and prometheus_url its URL when the profile has no credentials"),
+		),
 	}
 	return append(connectionParams, options...)
 }
@@ -126,10 +173,10 @@ func withQueryEnhancementParams(options ...mcp.ToolOption) []mcp.ToolOption {
 func withTimeFilteringParams(options ...mcp.ToolOption) []mcp.ToolOption {
 	timeParams := []mcp.ToolOption{
 		mcp.WithString("start_time",
-			mcp.Description("Start time for filtering (RFC3339)"),
+			mcp.Description("Start time for filtering as RFC3339 or Unix timestamp (fractional seconds and ms:<millis> accepted)"),
 		),
 		mcp.WithString("end_time",
-			mcp.Description("End time for filtering (RFC3339)"),
+			mcp.Description("End time for filtering as RFC3339 or Unix timestamp (fractional seconds and ms:<millis> accepted)"),
 		),
 	}
 	return append(timeParams, options...)
@@ -144,6 +191,15 @@ func withLabelMatchingParams(options ...mcp.ToolOption) []mcp.ToolOption {
 	return append(matchParams, options...)
 }
 
+func withAlertmanagerParams(options ...mcp.ToolOption) []mcp.ToolOption {
+	alertmanagerParams := []mcp.ToolOption{
+		mcp.WithString("alertmanager_url",
+			mcp.Description("Alertmanager base URL (e.g., 'http://alertmanager:9093'); overrides ALERTMANAGER_URL and discovery via Prometheus. Rejected when Prometheus or Alertmanager credentials are configured, as they are never sent to another URL"),
+		),
+	}
+	return append(alertmanagerParams, options...)
+}
+
 // ToolMiddleware is an optional hook invoked around each MCP tool call.
 // name is the tool name; next is the underlying handler.
 // Implement this to add metrics, tracing, or other cross-cutting concerns.
@@ -159,23 +215,44 @@ type ToolMiddleware func(
 // no other safety valve.
 func allowsUnlimited(name string) bool {
 	switch name {
-	case toolExecuteQuery, toolExecuteRangeQuery:
+	case toolExecuteQuery, toolExecuteRangeQuery, toolQueryTemplates:
 		return true
 	}
 	return false
 }
 
-// truncationMiddleware caps oversized TextContent in tool results at
-// MaxResultLength and appends the given advice. It is wired by
+// truncationMiddleware caps oversized TextContent in tool results at limit
+// characters and appends the given advice. It is wired by
 // registerPrometheusTools for every tool whose advice argument is non-empty.
 //
+// A "max_result_length" request argument lowers the cap for one call; values
+// above limit are clamped so callers cannot exceed the operator's setting.
 // Honours the "unlimited": "true" request argument only on the tools that
 // allowsUnlimited returns true for; other tools cannot opt out of truncation.
 func truncationMiddleware(
-	name, advice string,
+	name, advice string, limit int,
 	next func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error),
 ) func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		callLimit := limit
+		if args, ok := req.Params.Arguments.(map[string]any); ok {
+			if v := getStringParam(args, "max_result_length"); v != "" {
+				n, err := strconv.Atoi(v)
+				if err != nil || n <= 0 {
+					return &mcp.CallToolResult{
+						IsError: true,
+						Content: []mcp.Content{
+							mcp.TextContent{
+								Type: contentTypeText,
+								Text: fmt.Sprintf("Error: invalid max_result_length %q: must be a positive integer", v),
+							},
+						},
+					}, nil
+				}
+				callLimit = min(n, limit)
+			}
+		}
+
 		res, err := next(ctx, req)
 		if err != nil || res == nil {
 			return res, err
@@ -188,16 +265,49 @@ func truncationMiddleware(
 		// slice, clone here before writing.
 		for i, c := range res.Content {
 			tc, ok := c.(mcp.TextContent)
-			if !ok || len(tc.Text) <= MaxResultLength {
+			if !ok || len(tc.Text) <= callLimit {
 				continue
 			}
-			tc.Text = truncateWithAdvice(tc.Text, advice)
+			tc.Text = truncateWithAdvice(tc.Text, advice, callLimit)
 			res.Content[i] = tc
 		}
 		return res, nil
 	}
 }
 
+// rangeQueryPoints returns the samples per series that range queries without
+// a step are sized for: the value configured on sc, or
+// DefaultRangeQueryPoints when none is set.
+func rangeQueryPoints(sc *server.ServerContext) int {
+	if n := sc.RangeQueryPoints(); n > 0 {
+		return n
+	}
+	return DefaultRangeQueryPoints
+}
+
+// resultLengthLimit returns the truncation cap configured on sc, or
+// MaxResultLength when none is set.
+func resultLengthLimit(sc *server.ServerContext) int {
+	if n := sc.MaxResultLength(); n > 0 {
+		return n
+	}
+	return MaxResultLength
+}
+
+// structuredResultLimit returns the size in bytes the structured content of
+// a query result may reach: the truncation cap configured on sc, lowered by
+// a max_result_length argument, or 0 for an unlimited request.
+func structuredResultLimit(sc *server.ServerContext, request mcp.CallToolRequest) int {
+	if isUnlimitedRequest(request) {
+		return 0
+	}
+	limit := resultLengthLimit(sc)
+	if n, err := strconv.Atoi(getStringParam(extractParams(request), "max_result_length")); err == nil && n > 0 {
+		limit = min(limit, n)
+	}
+	return limit
+}
+
 // isUnlimitedRequest reports whether the caller passed "unlimited": "true"
 // in the tool arguments. Whether the bypass is honoured depends on the tool;
 // see allowsUnlimited.
@@ -217,34 +327,136 @@ type PrometheusHandler func(ctx context.Context, request mcp.CallToolRequest, cl
 func withDynamicPrometheusClient(handler PrometheusHandler, client *Client, sc *server.ServerContext) func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		params := extractParams(request)
-
-		// Create client with dynamic parameters if provided
-		dynamicClient, err := createClientFromParams(ctx, params, client, sc)
-		if err != nil {
-			return &mcp.CallToolResult{
-				IsError: true,
-				Content: []mcp.Content{
-					mcp.TextContent{
-						Type: contentTypeText,
-						Text: fmt.Sprintf("Error creating Prometheus client: %v", err),
+		start := time.Now()
+
+		// Requested target, replaced by the effective one once the client exists.
+		prometheusURL, _ := params["prometheus_url"].(string)
+		orgID, _ := params["org_id"].(string)
+
+		ctx = server.ContextWithToolCall(ctx, &server.ToolCall{
+			Name:      request.Params.Name,
+			Arguments: params,
+			Header:    http.Header{},
+		})
+
+		result, err := sc.RunToolMiddleware(ctx, request.Params.Name, func() (*mcp.CallToolResult, error) {
+			// Create client with dynamic parameters if provided
+			dynamicClient, err := createClientFromParams(ctx, params, client, sc)
+			if err != nil {
+				return &mcp.CallToolResult{
+					IsError: true,
+					Content: []mcp.Content{
+						mcp.TextContent{
+							Type: contentTypeText,
+							Text: fmt.Sprintf("%s: %v", clientErrorPrefix, err),
+						},
 					},
-				},
-			}, nil
+				}, nil
+			}
+			prometheusURL, orgID = dynamicClient.config.URL, dynamicClient.config.OrgID
+
+			// Call the actual handler with the dynamic client
+			return handler(ctx, request, dynamicClient, sc)
+		})
+		// Record the result as the client sees it, so the history holds no
+		// more detail than the error verbosity allows.
+		result, err = redactToolError(sc, request.Params.Name, result, err)
+		recordInvocation(ctx, sc, request, prometheusURL, orgID, start, result, err)
+		return result, err
+	}
+}
+
+// withServerMiddleware wraps the handler of a tool that does not go through
+// withDynamicPrometheusClient, such as get_server_config or the template
+// tools, in the server.ToolMiddleware of sc, so that middleware sees every
+// tool call.
+func withServerMiddleware(sc *server.ServerContext, handler mcpserver.ToolHandlerFunc) mcpserver.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		ctx = server.ContextWithToolCall(ctx, &server.ToolCall{
+			Name:      request.Params.Name,
+			Arguments: extractParams(request),
+			Header:    http.Header{},
+		})
+		return sc.RunToolMiddleware(ctx, request.Params.Name, func() (*mcp.CallToolResult, error) {
+			return handler(ctx, request)
+		})
+	}
+}
+
+// streamNotificationMethod is the JSON-RPC method of the notifications sent
+// by streamingHandler. Params carry an "event" field: progress, data or done.
+const streamNotificationMethod = "notifications/prometheus/stream"
+
+// streamingTransportKey marks request contexts that arrived over a transport
+// able to push notifications while a tool call is in flight.
+type streamingTransportKey struct{}
+
+// WithStreamingTransport marks ctx as coming from the SSE transport so tools
+// honour streaming=true. Its signature matches mcpserver.SSEContextFunc.
+func WithStreamingTransport(ctx context.Context, _ *http.Request) context.Context {
+	return context.WithValue(ctx, streamingTransportKey{}, true)
+}
+
+// streamingHandler wraps handler so that, when the caller passes
+// "streaming": "true" over the SSE transport, progress is pushed to the client
+// as streamNotificationMethod notifications: a "progress" event before the
+// query runs, a "data" event with the result text, and a final "done" event.
+//
+// The Prometheus HTTP API returns range query results in one response, so a
+// single data event is emitted. On other transports, or when the
+// notification cannot be delivered, the parameter is silently ignored and the
+// tool result is returned as usual.
+func streamingHandler(handler PrometheusHandler) PrometheusHandler {
+	return func(ctx context.Context, request mcp.CallToolRequest, client *Client, sc *server.ServerContext) (*mcp.CallToolResult, error) {
+		params := extractParams(request)
+		srv := mcpserver.ServerFromContext(ctx)
+		streaming, _ := ctx.Value(streamingTransportKey{}).(bool)
+		if getStringParam(params, "streaming") != "true" || !streaming || srv == nil {
+			return handler(ctx, request, client, sc)
+		}
+
+		notify := func(event map[string]any) {
+			if err := srv.SendNotificationToClient(ctx, streamNotificationMethod, event); err != nil {
+				sc.Logger().Debug("Failed to send stream notification", "event", event["event"], "error", err)
+			}
 		}
 
-		// Call the actual handler with the dynamic client
-		return handler(ctx, request, dynamicClient, sc)
+		notify(map[string]any{"event": "progress", "status": "executing", "query": getStringParam(params, "query")})
+
+		result, err := handler(ctx, request, client, sc)
+		if err != nil || result == nil {
+			notify(map[string]any{"event": "done", "status": "error"})
+			return result, err
+		}
+
+		var text strings.Builder
+		for _, c := range result.Content {
+			if tc, ok := c.(mcp.TextContent); ok {
+				text.WriteString(tc.Text)
+			}
+		}
+		if result.IsError {
+			notify(map[string]any{"event": "done", "status": "error", "error": text.String()})
+			return result, nil
+		}
+		notify(map[string]any{"event": "data", "result": text.String()})
+		notify(map[string]any{"event": "done", "status": "success"})
+		return result, nil
 	}
 }
 
 // Helper function to create and register a tool with common patterns.
 //
-// All Prometheus tools in this server are read-only (they query Prometheus/Mimir
-// without mutating state) and target a bounded Prometheus/Mimir endpoint rather
-// than the open web, so readOnlyHint is always true and openWorldHint is always
-// false. destructiveHint and idempotentHint are omitted because they are only
-// meaningful when readOnlyHint is false.
+// Prometheus tools target a bounded Prometheus/Mimir endpoint rather than the
+// open web, so openWorldHint is always false. They are read-only unless options
+// set readOnlyHint to false: admin and write tools that do are destructive
+// unless they also set destructiveHint to false, so client approval policies
+// can auto-approve queries but still gate deletions.
 func registerPrometheusTools(s *mcpserver.MCPServer, client *Client, sc *server.ServerContext, middleware []ToolMiddleware, toolName string, description string, advice string, handler PrometheusHandler, options ...mcp.ToolOption) {
+	if advice != noTruncation {
+		options = append(options, mcp.WithString("max_result_length",
+			mcp.Description("Truncate the result text after this many characters for this call (cannot exceed the server limit)")))
+	}
 	allOptions := withPrometheusConnectionParams(options...)
 	baseOptions := []mcp.ToolOption{
 		mcp.WithDescription(description),
@@ -255,10 +467,13 @@ func registerPrometheusTools(s *mcpserver.MCPServer, client *Client, sc *server.
 		mcp.WithSchemaAdditionalProperties(false),
 	}
 	tool := mcp.NewTool(toolName, append(baseOptions, allOptions...)...)
+	if *tool.Annotations.ReadOnlyHint {
+		tool.Annotations.DestructiveHint = mcp.ToBoolPtr(false)
+	}
 
 	h := withDynamicPrometheusClient(handler, client, sc)
 	if advice != noTruncation {
-		h = truncationMiddleware(toolName, advice, h)
+		h = truncationMiddleware(toolName, advice, resultLengthLimit(sc), h)
 	}
 	// User-supplied middlewares wrap the (possibly already truncated) result,
 	// so any telemetry middleware sees post-truncation byte counts.
@@ -280,26 +495,101 @@ func RegisterPrometheusTools(s *mcpserver.MCPServer, sc *server.ServerContext, m
 		if err != nil {
 			return fmt.Errorf("tools: create Prometheus client: %w", err)
 		}
+		if n := sc.ConnectionWarmup(); n > 0 {
+			client.Warmup(sc.Context(), n)
+		}
 	}
 
 	// Query execution tools
 	registerPrometheusTools(s, client, sc, middleware, toolExecuteQuery, "Execute a PromQL instant query against Prometheus",
 		TruncationAdvice, handleExecuteQuery, withQueryEnhancementParams(
+			mcp.WithOutputSchema[QueryOutput](),
 			mcp.WithString("query", mcp.Required(), mcp.Description("PromQL query string")),
-			mcp.WithString("time", mcp.Description("Optional RFC3339 or Unix timestamp (default: current time)")),
+			mcp.WithString("time", mcp.Description("Optional RFC3339 or Unix timestamp; fractional seconds (1704067200.500) and ms:<millis> are accepted (default: current time)")),
+			mcp.WithString("stale_aware_time", mcp.Description("Set to 'true' to step back from the current time until the query returns data (ignored when 'time' is set)")),
+			mcp.WithString("staleness_delta", mcp.Description("Step size used by stale_aware_time (default: '5m')")),
+			mcp.WithString("max_backtrack", mcp.Description("Maximum number of steps taken back by stale_aware_time (default: 3, at most 10)")),
+			mcp.WithString("group_by_namespace", mcp.Description("Set to 'true' to render vector and matrix results in one section per Kubernetes 'namespace' label value")),
+			mcp.WithString("namespace_filter", mcp.Description("Only keep series whose 'namespace' label contains this substring")),
+			mcp.WithString("deduplicate", mcp.Description("Set to 'true' to collapse vector series that only differ in the replica label (PROMETHEUS_REPLICA_LABEL, default 'prometheus_replica'), e.g. from an HA pair behind one endpoint")),
+			mcp.WithString("aggregate", mcp.Description("How deduplicate combines the samples of one series: 'latest' (default), 'first', 'avg', 'max' or 'min'")),
+			mcp.WithString("format", mcp.Description("Output format: 'text' (default) or 'openmetrics' to serialise an instant vector as OpenMetrics text with HELP and TYPE from the metadata API")),
+			mcp.WithString("convert_to", mcp.Description("Convert sample values between units, written '<from>-><to>' or '<from>/<to>': bytes->KiB|MiB|GiB|TiB, seconds->ms|µs|ns, ratio->percent, hertz->MHz|GHz (e.g., 'bytes->GiB')")),
 		)...)
 
 	registerPrometheusTools(s, client, sc, middleware, toolExecuteRangeQuery, "Execute a PromQL range query with start time, end time, and step interval",
-		TruncationAdvice, handleExecuteRangeQuery, withQueryEnhancementParams(
+		TruncationAdvice, streamingHandler(handleExecuteRangeQuery), withQueryEnhancementParams(
+			mcp.WithOutputSchema[QueryOutput](),
 			mcp.WithString("query", mcp.Required(), mcp.Description("PromQL query string")),
-			mcp.WithString("start", mcp.Required(), mcp.Description("Start time as RFC3339 or Unix timestamp")),
-			mcp.WithString("end", mcp.Required(), mcp.Description("End time as RFC3339 or Unix timestamp")),
-			mcp.WithString("step", mcp.Required(), mcp.Description("Query resolution step width (e.g., '15s', '1m', '1h')")),
+			mcp.WithString("start", mcp.Required(), mcp.Description("Start time as RFC3339 or Unix timestamp (fractional seconds and ms:<millis> accepted)")),
+			mcp.WithString("end", mcp.Required(), mcp.Description("End time as RFC3339 or Unix timestamp (fractional seconds and ms:<millis> accepted)")),
+			mcp.WithString("step", mcp.Description("Query resolution step width (e.g., '15s', '1m', '1h'); when omitted, a step giving a few hundred points per series is computed from the range")),
+			mcp.WithString("streaming", mcp.Description("Set to 'true' to receive progress, data and done notifications while the query runs (SSE transport only; ignored otherwise)")),
+			mcp.WithString("include_trend", mcp.Description("Set to 'true' to append a least-squares trend per series: slope per second, R² and the value projected past 'end'")),
+			mcp.WithString("project_steps", mcp.Description("Project the trend this many steps past 'end' (default: 1h past 'end')")),
+			mcp.WithString("convert_to", mcp.Description("Convert sample values between units, written '<from>-><to>' or '<from>/<to>': bytes->KiB|MiB|GiB|TiB, seconds->ms|µs|ns, ratio->percent, hertz->MHz|GHz (e.g., 'bytes->GiB')")),
+			mcp.WithString("max_points_per_series", mcp.Description("Downsample each series of a range result to at most this many samples, keeping peaks, dips and steps (largest-triangle-three-buckets); at least 2")),
+			mcp.WithString("chart", mcp.Description("Set to 'true' to also return a PNG line chart of the result as image content, with a colour legend appended to the text (not with format 'csv')")),
+			mcp.WithString("format", mcp.Description("Output format: 'text' (default), 'csv' for one row per timestamp (Unix seconds and RFC3339 columns) and one column per series, ready for spreadsheet import, or 'sparkline' for one line per series with a unicode sparkline and its min, max and last value")),
+		)...)
+
+	registerPrometheusTools(s, client, sc, middleware, toolExecuteMultiQuery, fmt.Sprintf("Execute up to %d PromQL instant queries in parallel at the same evaluation time and return their results as JSON, one entry per query", maxMultiQueries),
+		TruncationAdvice, handleExecuteMultiQuery,
+		mcp.WithArray("queries", mcp.Required(), mcp.Description(fmt.Sprintf("Queries to run, at most %d, as {\"name\": ..., \"query\": ...} objects or plain PromQL strings (e.g., [{\"name\": \"errors\", \"query\": \"sum(rate(http_requests_total{code=~'5..'}[5m]))\"}])", maxMultiQueries))),
+		mcp.WithString("time", mcp.Description("Evaluation time of every query as RFC3339 or Unix timestamp (default: current time)")),
+		mcp.WithString("partial_success", mcp.Description("Set to 'true' to return the results of the successful queries and an inline error for each failed one, instead of failing the whole call")),
+		mcp.WithOutputSchema[MultiQueryResult](),
+	)
+
+	registerPrometheusTools(s, client, sc, middleware, toolQueryBuilder, "Compose a PromQL query from structured fields (metric, label filters, range function, aggregation, group-by, quantile) and return it, or run it with execute=true: as a range query when start/end are given, otherwise as an instant query",
+		TruncationAdvice, handleQueryBuilder, withQueryEnhancementParams(
+			mcp.WithString("metric", mcp.Required(), mcp.Description("Metric name (e.g., 'http_requests_total', 'http_request_duration_seconds_bucket')")),
+			mcp.WithArray("filters", mcp.Description("Label matchers, one per item (e.g., ['job=\"api\"', 'code=~\"5..\"'])")),
+			mcp.WithString("range_function", mcp.Enum(builderRangeFunctions...), mcp.Description("Function applied to the selector over 'range', e.g. 'rate' for counters")),
+			mcp.WithString("range", mcp.Description("Range window of range_function (default: '5m')")),
+			mcp.WithString("aggregation", mcp.Enum(builderAggregations...), mcp.Description("Aggregation applied last; 'histogram_quantile' sums the buckets by 'le' (plus group_by) first")),
+			mcp.WithArray("group_by", mcp.Description("Labels the aggregation keeps (e.g., ['job', 'instance'])")),
+			mcp.WithString("quantile", mcp.Description("φ between 0 and 1 for quantile_over_time, quantile and histogram_quantile (e.g., '0.99')")),
+			mcp.WithString("k", mcp.Description("Number of series kept by topk and bottomk")),
+			mcp.WithString("execute", mcp.Description("Set to 'true' to run the built query and return its result (default: only return the query)")),
+			mcp.WithString("time", mcp.Description("Evaluation time of an instant query as RFC3339 or Unix timestamp (default: current time)")),
+			mcp.WithString("start", mcp.Description("Range query start time as RFC3339 or Unix timestamp")),
+			mcp.WithString("end", mcp.Description("Range query end time as RFC3339 or Unix timestamp")),
+			mcp.WithString("step", mcp.Description("Range query resolution step width (e.g., '15s', '1m'); computed from the range when omitted")),
+		)...)
+
+	registerPrometheusTools(s, client, sc, middleware, toolGenerateQuery, "Translate a natural-language question into PromQL with the client's own model (MCP sampling), using a catalog of the server's metrics and label names, validate it and run it: as a range query when start/end are given, otherwise as an instant query. Requires a client that supports sampling",
+		TruncationAdvice, generateQueryHandler(s), withQueryEnhancementParams(
+			mcp.WithString("question", mcp.Required(), mcp.Description("Question about the metrics (e.g., 'Which jobs have the highest 5xx error ratio?')")),
+			mcp.WithString("execute", mcp.Description("Set to 'false' to only return the generated query (default: 'true')")),
+			mcp.WithString("time", mcp.Description("Evaluation time of an instant query as RFC3339 or Unix timestamp (default: current time)")),
+			mcp.WithString("start", mcp.Description("Range query start time as RFC3339 or Unix timestamp")),
+			mcp.WithString("end", mcp.Description("Range query end time as RFC3339 or Unix timestamp")),
+			mcp.WithString("step", mcp.Description("Range query resolution step width (e.g., '15s', '1m'); computed from the range when omitted")),
+		)...)
+
+	registerPrometheusTools(s, client, sc, middleware, toolFormatPromQL, "Pretty-print a PromQL expression with the server's format_query endpoint (formatted locally on servers without it), e.g. to normalise long generated expressions before storing them in rules",
+		noTruncation, handleFormatPromQL,
+		mcp.WithString("query", mcp.Required(), mcp.Description("PromQL expression to format")),
+	)
+
+	registerPrometheusTools(s, client, sc, middleware, toolQueryTemplates, "Expand a PromQL template with {{.var}} placeholders and run it: as a range query when start/end are given, otherwise as an instant query",
+		TruncationAdvice, handleQueryTemplates, withQueryEnhancementParams(
+			mcp.WithOutputSchema[QueryOutput](),
+			mcp.WithString("template", mcp.Description("PromQL template using Go text/template syntax, e.g. 'rate({{.metric}}[{{.window}}])'")),
+			mcp.WithString("template_name", mcp.Description("Name of a template stored with register_template (instead of template)")),
+			mcp.WithObject("variables", mcp.Description("Variable names mapped to string values (e.g. {\"metric\": \"http_requests_total\", \"window\": \"5m\"})"), mcp.AdditionalProperties(map[string]any{"type": "string"})),
+			mcp.WithString("time", mcp.Description("Evaluation time of an instant query as RFC3339 or Unix timestamp (default: current time)")),
+			mcp.WithString("start", mcp.Description("Range query start time as RFC3339 or Unix timestamp")),
+			mcp.WithString("end", mcp.Description("Range query end time as RFC3339 or Unix timestamp")),
+			mcp.WithString("step", mcp.Description("Range query resolution step width (e.g., '15s', '1m'); computed from the range when omitted")),
+			mcp.WithString("max_points_per_series", mcp.Description("Downsample each series of a range result to at most this many samples, keeping peaks, dips and steps (largest-triangle-three-buckets); at least 2")),
 		)...)
 
 	// Metrics discovery tools
 	registerPrometheusTools(s, client, sc, middleware, "get_metric_metadata", "Get metadata for a specific metric",
 		discoveryAdvice, handleGetMetricMetadata,
+		mcp.WithOutputSchema[MetricMetadataOutput](),
 		mcp.WithString("metric", mcp.Required(), mcp.Description("The name of the metric to retrieve metadata for")),
 		mcp.WithString("limit", mcp.Description("Maximum number of metadata entries to return")),
 	)
@@ -307,37 +597,119 @@ func RegisterPrometheusTools(s *mcpserver.MCPServer, sc *server.ServerContext, m
 	// Label and series discovery tools
 	registerPrometheusTools(s, client, sc, middleware, "list_label_names", "Get all available label names",
 		discoveryAdvice, handleListLabelNames, withTimeFilteringParams(withLabelMatchingParams(
+			mcp.WithOutputSchema[LabelNamesOutput](),
 			mcp.WithString("limit", mcp.Description("Maximum number of label names to return")),
+			mcp.WithString("with_cardinality", mcp.Description("Set to 'true' to include the number of distinct values of each label")),
+			mcp.WithString("sort_by", mcp.Description("Sort order with with_cardinality: 'name' (default) or 'cardinality' (descending)")),
+			mcp.WithString("min_cardinality", mcp.Description("With with_cardinality, exclude labels with fewer distinct values than this (e.g., '2' hides labels with a single value)")),
 		)...)...)
 
 	registerPrometheusTools(s, client, sc, middleware, "list_label_values", "Get values for a specific label",
 		discoveryAdvice, handleListLabelValues, withTimeFilteringParams(withLabelMatchingParams(
+			mcp.WithOutputSchema[LabelValuesOutput](),
 			mcp.WithString("label", mcp.Required(), mcp.Description("The label name to get values for")),
 			mcp.WithString("limit", mcp.Description("Maximum number of label values to return")),
+			mcp.WithString("group_by_prefix", mcp.Description("Set to 'true' to group values by their first '_'-separated segment, e.g. metric names with label '__name__'")),
+			mcp.WithString("min_group_size", mcp.Description("Minimum number of values sharing a prefix to form a group when group_by_prefix is set (default: 3)")),
+			mcp.WithString("with_types", mcp.Description("With label '__name__', set to 'true' to annotate each metric with its type from the metadata API, or a type inferred from the name suffix when there is no metadata (ignored with group_by_prefix)")),
+			mcp.WithString("page_size", mcp.Description(fmt.Sprintf("Number of values per page (default: %d, at most %d; ignored with group_by_prefix)", defaultLabelValuesPageSize, maxStructuredItems))),
+			mcp.WithString("cursor", mcp.Description("Cursor returned by the previous page to list the values after it; omit for the first page")),
 		)...)...)
 
 	registerPrometheusTools(s, client, sc, middleware, "find_series", "Find series by label matchers",
 		discoveryAdvice, handleFindSeries, withTimeFilteringParams(
+			mcp.WithOutputSchema[SeriesListOutput](),
 			mcp.WithArray("matches", mcp.Required(), mcp.Description("Array of label matchers (e.g., ['{job=\"prometheus\"}', '{__name__=~\"http_.*\"}'])")),
 			mcp.WithString("limit", mcp.Description("Maximum number of series to return")),
+			mcp.WithString("histogram_label", mcp.Description("Instead of listing series, count the matched series per distinct value of this label (e.g., 'job'), sorted by count descending")),
+			mcp.WithString("top_n", mcp.Description(fmt.Sprintf("Number of label values listed with histogram_label (default: %d)", defaultSeriesHistogramTopN))),
+			mcp.WithString("group_by_namespace", mcp.Description("Set to 'true' to list series in one section per Kubernetes 'namespace' label value (ignored with histogram_label)")),
+			mcp.WithString("namespace_filter", mcp.Description("Only keep series whose 'namespace' label contains this substring")),
+			mcp.WithString("show_activity_range", mcp.Description(fmt.Sprintf("Set to 'true' to draw a bar per series showing when it had samples between start_time and end_time (both required). Runs one range query per series and lists at most %d series; ignored with histogram_label", activitySeriesLimit))),
 		)...)
 
+	registerPrometheusTools(s, client, sc, middleware, "suggest_label_filters", "Suggest label filters that reduce the number of series matched by the selectors in a PromQL query",
+		discoveryAdvice, handleSuggestLabelFilters, withTimeFilteringParams(
+			mcp.WithString("query", mcp.Required(), mcp.Description("PromQL query whose vector selectors should be analysed (e.g., 'rate(http_requests_total[5m])')")),
+		)...)
+
+	registerPrometheusTools(s, client, sc, middleware, toolSearchMetrics, "Fuzzy search for metrics by keywords matched against metric names and metadata help strings, tolerating typos and common synonyms (e.g., 'latency ingress' finds nginx_ingress_controller_request_duration_seconds). Search only; no PromQL is executed",
+		discoveryAdvice, handleSearchMetrics,
+		mcp.WithString("query", mcp.Required(), mcp.Description("Space-separated search words (e.g., 'latency ingress', 'disk written bytes')")),
+		mcp.WithString("metric_type", mcp.Description("Only return metrics of this type (counter, gauge, histogram, summary, ...); inferred from the name for metrics without metadata")),
+		mcp.WithString("limit", mcp.Description(fmt.Sprintf("Maximum number of metrics returned, best first (default: %d, at most %d)", defaultSearchLimit, maxSearchLimit))),
+	)
+
+	registerPrometheusTools(s, client, sc, middleware, "find_metrics_by_help_text", "Find metrics by searching their help strings for keywords, ranked by how many keywords match. Search only; no PromQL is executed",
+		discoveryAdvice, handleFindMetricsByHelpText,
+		mcp.WithString("keywords", mcp.Required(), mcp.Description("Space-separated keywords matched case-insensitively against help strings (e.g., 'disk bytes written')")),
+		mcp.WithString("require_all", mcp.Description("Set to 'true' to only return metrics whose help text contains every keyword (default: any keyword)")),
+		mcp.WithString("metric_type", mcp.Description("Only return metrics of this type (counter, gauge, histogram, summary, ...)")),
+	)
+
 	// Target and system information tools
-	registerPrometheusTools(s, client, sc, middleware, "get_targets", "Get information about all scrape targets", bulkAdvice, handleGetTargets)
+	registerPrometheusTools(s, client, sc, middleware, "get_targets", "Get information about all scrape targets", bulkAdvice, handleGetTargets,
+		mcp.WithOutputSchema[TargetsOutput](),
+		mcp.WithString("summary_only", mcp.Description("Set to 'true' to return one row per scrape pool (target counts, health percentage, average and maximum scrape duration) instead of per-target details")),
+	)
 
-	registerPrometheusTools(s, client, sc, middleware, "get_build_info", "Get build information about the Prometheus server", noTruncation, handleGetBuildInfo)
+	registerPrometheusTools(s, client, sc, middleware, "get_build_info", "Get build information about the Prometheus server", noTruncation, handleGetBuildInfo,
+		mcp.WithOutputSchema[BuildInfoOutput](),
+		mcp.WithString("format", mcp.Description("Output format: 'text' (default) or 'json'")),
+	)
 
-	registerPrometheusTools(s, client, sc, middleware, "get_runtime_info", "Get runtime information about the Prometheus server", noTruncation, handleGetRuntimeInfo)
+	registerPrometheusTools(s, client, sc, middleware, "get_runtime_info", "Get runtime information about the Prometheus server", noTruncation, handleGetRuntimeInfo,
+		mcp.WithString("format", mcp.Description("Output format: 'text' (default) or 'json'")),
+	)
 
-	registerPrometheusTools(s, client, sc, middleware, "get_flags", "Get runtime flags that Prometheus was launched with", noTruncation, handleGetFlags)
+	registerPrometheusTools(s, client, sc, middleware, "get_flags", "Get runtime flags that Prometheus was launched with", noTruncation, handleGetFlags,
+		mcp.WithOutputSchema[FlagsOutput](),
+		mcp.WithString("show_changed_only", mcp.Description("Set to 'true' to only list flags that differ from the Prometheus defaults, as a flag_name | default | current table")),
+		mcp.WithString("flag_filter", mcp.Description("Regular expression; only flags whose name matches are returned (e.g., '^storage\\.tsdb\\.')")),
+	)
 
-	registerPrometheusTools(s, client, sc, middleware, "get_config", "Get Prometheus configuration", bulkAdvice, handleGetConfig)
+	// Unredacted output is only offered when the operator allows it.
+	var configOptions []mcp.ToolOption
+	if sc.RawConfigAllowed() {
+		configOptions = append(configOptions, mcp.WithString("raw", mcp.Description("Set to 'true' to return the configuration without redacting credentials")))
+	}
+	registerPrometheusTools(s, client, sc, middleware, "get_config", "Get Prometheus configuration as YAML with credentials redacted",
+		bulkAdvice, handleGetConfig, configOptions...)
 
 	// Alerting tools
-	registerPrometheusTools(s, client, sc, middleware, "get_alerts", "Get active alerts", alertsAdvice, handleGetAlerts)
+	registerPrometheusTools(s, client, sc, middleware, "get_alerts", "Get active alerts", alertsAdvice, handleGetAlerts,
+		mcp.WithOutputSchema[AlertsOutput](),
+	)
+
+	alertSubscriptions := newAlertSubscriptions(s)
+	onSessionEnd(s, alertSubscriptions.stopScope)
+	registerPrometheusTools(s, client, sc, middleware, toolSubscribeAlerts, "Subscribe this session to alert changes: /api/v1/alerts is polled and a notification is pushed whenever an alert starts or stops firing. Returns the alerts firing now",
+		noTruncation, alertSubscriptions.handle,
+		mcp.WithString("interval", mcp.Description("Polling interval as a Prometheus duration, at least 5s (default: 30s)")),
+		mcp.WithString("stop", mcp.Description("Set to 'true' to end this session's subscription")),
+	)
 
 	registerPrometheusTools(s, client, sc, middleware, "get_alertmanagers", "Get AlertManager discovery information", noTruncation, handleGetAlertManagers)
 
+	registerPrometheusTools(s, client, sc, middleware, "list_alertmanager_receivers", "List Alertmanager receivers (GET /api/v2/receivers) with their integration types; credentials are never shown. Optionally simulates routing for a label set against the route tree of the Alertmanager configuration",
+		noTruncation, handleListAlertmanagerReceivers,
+		withAlertmanagerParams(
+			mcp.WithObject("simulate_routing", mcp.Description("Alert labels mapped to string values (e.g. {\"severity\": \"critical\", \"team\": \"db\"}); shows which receivers such an alert is routed to"), mcp.AdditionalProperties(map[string]any{"type": "string"})),
+		)...,
+	)
+
+	registerPrometheusTools(s, client, sc, middleware, "get_alertmanager_alerts", "Get alerts as Alertmanager sees them after routing, silencing and inhibition (GET /api/v2/alerts), with their state, receivers and the silences or alerts suppressing them",
+		alertmanagerAlertsAdvice, handleGetAlertmanagerAlerts,
+		withAlertmanagerParams(
+			mcp.WithString("receiver", mcp.Description("Regular expression; only alerts routed to a matching receiver are returned (e.g., 'pager|slack-.*')")),
+			mcp.WithArray("filter", mcp.Description("Label matchers the alerts must satisfy (e.g., ['alertname=\"HighLatency\"', 'severity=~\"crit.*\"'])")),
+			mcp.WithString("active", mcp.Description("Set to 'false' to leave out active alerts (default: 'true')")),
+			mcp.WithString("silenced", mcp.Description("Set to 'false' to leave out silenced alerts (default: 'true')")),
+			mcp.WithString("inhibited", mcp.Description("Set to 'false' to leave out inhibited alerts (default: 'true')")),
+			mcp.WithString("unprocessed", mcp.Description("Set to 'false' to leave out alerts Alertmanager has not processed yet (default: 'true')")),
+		)...,
+	)
+
 	registerPrometheusTools(s, client, sc, middleware, "get_rules", "Get recording and alerting rules", bulkAdvice, handleGetRules)
 
 	// Advanced tools
@@ -349,8 +721,52 @@ func RegisterPrometheusTools(s *mcpserver.MCPServer, sc *server.ServerContext, m
 	registerPrometheusTools(s, client, sc, middleware, "query_exemplars", "Query exemplars for traces",
 		discoveryAdvice, handleQueryExemplars,
 		mcp.WithString("query", mcp.Required(), mcp.Description("PromQL query string to find exemplars for")),
-		mcp.WithString("start", mcp.Required(), mcp.Description("Start time as RFC3339 or Unix timestamp")),
-		mcp.WithString("end", mcp.Required(), mcp.Description("End time as RFC3339 or Unix timestamp")),
+		mcp.WithString("start", mcp.Required(), mcp.Description("Start time as RFC3339 or Unix timestamp (fractional seconds and ms:<millis> accepted)")),
+		mcp.WithString("end", mcp.Required(), mcp.Description("End time as RFC3339 or Unix timestamp (fractional seconds and ms:<millis> accepted)")),
+		mcp.WithString("trace_backend", mcp.Description("Trace UI to link exemplar trace IDs to: 'jaeger' (default), 'tempo', 'zipkin' or 'generic'. Links are built from PROMETHEUS_TRACE_BASE_URL")),
+		mcp.WithString("trace_url_template", mcp.Description("Link template with {baseURL}, {traceID} and {spanID} placeholders, e.g. 'https://traces.example.com/t/{traceID}'. Required for 'generic', overrides the tempo and zipkin defaults")),
+	)
+
+	registerPrometheusTools(s, client, sc, middleware, "analyze_anomalies", "Detect series whose values in a comparison window deviate from a baseline window by more than a z-score threshold",
+		discoveryAdvice, handleAnalyzeAnomalies,
+		mcp.WithString("query", mcp.Required(), mcp.Description("PromQL query string whose series should be analysed")),
+		mcp.WithString("baseline_start", mcp.Required(), mcp.Description("Start of the baseline window as RFC3339")),
+		mcp.WithString("baseline_end", mcp.Required(), mcp.Description("End of the baseline window as RFC3339")),
+		mcp.WithString("comparison_start", mcp.Required(), mcp.Description("Start of the comparison window as RFC3339")),
+		mcp.WithString("comparison_end", mcp.Required(), mcp.Description("End of the comparison window as RFC3339")),
+		mcp.WithString("step", mcp.Description("Query resolution step width for both windows (default: '1m')")),
+		mcp.WithString("z_score_threshold", mcp.Description("Report series whose peak |z-score| exceeds this value (default: 3.0)")),
+	)
+
+	registerPrometheusTools(s, client, sc, middleware, "validate_histogram", "Check a classic histogram for setup problems that make histogram_quantile return NaN or wrong results: missing +Inf bucket, duplicate or non-numeric le bounds, negative or non-cumulative buckets, missing _count/_sum, _count differing from the +Inf bucket",
+		discoveryAdvice, handleValidateHistogram,
+		mcp.WithString("metric_name", mcp.Required(), mcp.Description("Histogram base name without the _bucket suffix (e.g., 'http_request_duration_seconds')")),
+	)
+
+	registerPrometheusTools(s, client, sc, middleware, "evaluate_rule_timeline", "Replay an alerting rule over a past window and chart each series' inactive/pending/firing state per step (e.g., '....PPPPFFFF')",
+		bulkAdvice, handleEvaluateRuleTimeline,
+		mcp.WithString("rule_expr", mcp.Required(), mcp.Description("Alerting rule expression; every series it returns at an evaluation time counts as active (e.g., 'rate(errors_total[5m]) > 0.1')")),
+		mcp.WithString("for_duration", mcp.Description("The rule's 'for' clause: how long a series must stay active before firing (e.g., '5m'; default: 0s)")),
+		mcp.WithString("start", mcp.Required(), mcp.Description("Start time as RFC3339 or Unix timestamp (fractional seconds and ms:<millis> accepted)")),
+		mcp.WithString("end", mcp.Required(), mcp.Description("End time as RFC3339 or Unix timestamp (fractional seconds and ms:<millis> accepted)")),
+		mcp.WithString("step", mcp.Required(), mcp.Description("Evaluation interval; each chart character covers one step (e.g., '1m')")),
+	)
+
+	registerPrometheusTools(s, client, sc, middleware, toolGetSeriesCountHistory, "Chart how many series existed over a past window (count of the matched series) next to sum(scrape_samples_scraped), with min/max/mean and change, for retention and capacity planning",
+		noTruncation, handleGetSeriesCountHistory,
+		mcp.WithString("start", mcp.Required(), mcp.Description("Start time as RFC3339 or Unix timestamp (fractional seconds and ms:<millis> accepted)")),
+		mcp.WithString("end", mcp.Required(), mcp.Description("End time as RFC3339 or Unix timestamp (fractional seconds and ms:<millis> accepted)")),
+		mcp.WithString("step", mcp.Required(), mcp.Description("Resolution; each chart character covers one step (e.g., '1h')")),
+		mcp.WithArray("matches", mcp.Description("Series selectors to count (e.g., ['{job=\"node\"}', 'up']; default: all series)")),
+	)
+
+	registerPrometheusTools(s, client, sc, middleware, toolSummarizeRangeQuery, "Run a range query and summarize each series as a Markdown table: current value, delta, percentage change, per-second rate for counters, and peak and trough with timestamps. Classic histogram buckets are summarized as estimated p50/p95/p99. Use instead of execute_range_query when the trend matters more than the raw samples",
+		noTruncation, handleSummarizeRangeQuery,
+		mcp.WithString("query", mcp.Required(), mcp.Description("PromQL query to summarize (e.g., 'node_memory_MemAvailable_bytes' or 'http_request_duration_seconds_bucket')")),
+		mcp.WithString("start", mcp.Required(), mcp.Description("Start time as RFC3339 or Unix timestamp (fractional seconds and ms:<millis> accepted)")),
+		mcp.WithString("end", mcp.Required(), mcp.Description("End time as RFC3339 or Unix timestamp (fractional seconds and ms:<millis> accepted)")),
+		mcp.WithString("step", mcp.Required(), mcp.Description("Query resolution step width (e.g., '1m')")),
+		mcp.WithString("top_n", mcp.Description(fmt.Sprintf("Only list the N series with the highest current value, and the N histograms with the most observations (default: %d)", defaultSummaryTopN))),
 	)
 
 	registerPrometheusTools(s, client, sc, middleware, "get_targets_metadata", "Get metadata about metrics from specific targets",
@@ -360,24 +776,112 @@ func RegisterPrometheusTools(s *mcpserver.MCPServer, sc *server.ServerContext, m
 		mcp.WithString("limit", mcp.Description("Maximum number of metadata entries to return")),
 	)
 
+	registerPrometheusTools(s, client, sc, middleware, "query_federated_metrics", "Fetch the latest raw samples of the series matching the given selectors from the /federate endpoint, without PromQL evaluation",
+		discoveryAdvice, handleQueryFederatedMetrics,
+		mcp.WithArray("matches", mcp.Required(), mcp.Description("Array of series selectors passed as match[] (e.g., ['{job=\"prometheus\"}', 'up'])")),
+		mcp.WithString("limit", mcp.Description("Maximum number of series to return")),
+		mcp.WithString("format", mcp.Description("Output format: 'text' (default) or 'json'")),
+	)
+
 	// Status / health tools
 	registerPrometheusTools(s, client, sc, middleware, "check_ready", "Check whether the Prometheus/Mimir server is ready to serve traffic (GET /-/ready)", noTruncation, handleCheckReady)
 
+	registerPrometheusTools(s, client, sc, middleware, toolCheckHealth, "Check whether Prometheus is up (GET /-/healthy) and ready (GET /-/ready) with the response latency of each, to tell a server outage apart from a failing query", noTruncation, handleCheckHealth)
+
+	registerPrometheusTools(s, client, sc, middleware, toolCollectionSummary, "One-page on-call report of a Prometheus instance: scrape target health, active alerts by severity, top 5 metrics by series count, TSDB storage, query engine load and build version",
+		noTruncation, handleCollectionSummary,
+	)
+
+	// Admin tools write samples, destroy data or write full copies of the
+	// TSDB, so operators opt in with --enable-admin-tools.
+	if sc.AdminToolsEnabled() {
+		registerPrometheusTools(s, client, sc, middleware, toolPushMetric, "Write a single synthetic sample to the Prometheus remote write endpoint (PROMETHEUS_REMOTE_WRITE_URL), e.g. to test alert rules. Requires confirm=true", noTruncation, handlePushMetric,
+			mcp.WithReadOnlyHintAnnotation(false),
+			mcp.WithDestructiveHintAnnotation(false),
+			mcp.WithString("metric_name", mcp.Required(), mcp.Description("Metric name of the sample")),
+			mcp.WithObject("labels", mcp.Description("Label names mapped to string values (e.g. {\"job\": \"test\"})"), mcp.AdditionalProperties(map[string]any{"type": "string"})),
+			mcp.WithString("value", mcp.Required(), mcp.Description("Sample value (e.g. '42', '0.5', 'NaN')")),
+			mcp.WithString("timestamp", mcp.Description("Sample time as RFC3339 or Unix timestamp (default: now)")),
+			mcp.WithString("remote_write_url", mcp.Description("Remote write endpoint overriding PROMETHEUS_REMOTE_WRITE_URL (e.g. 'http://prometheus:9090/api/v1/write', 'http://mimir/api/v1/push')")),
+			mcp.WithString("confirm", mcp.Required(), mcp.Description("Must be 'true': this tool writes data into Prometheus")),
+		)
+
+		registerPrometheusTools(s, client, sc, middleware, toolDeleteSeries, "Delete the data of series matching the given selectors via the TSDB admin API (requires Prometheus --web.enable-admin-api). Runs as a dry run that previews the matching series and storage impact and returns a confirm_token; repeat the call with confirm=true and that token to delete", noTruncation, handleDeleteSeries,
+			mcp.WithReadOnlyHintAnnotation(false),
+			mcp.WithDestructiveHintAnnotation(true),
+			mcp.WithArray("matches", mcp.Required(), mcp.Description("Series selectors whose data should be deleted (e.g., ['{job=\"test\"}'])")),
+			mcp.WithString("start", mcp.Description("Start of the time range to delete as RFC3339 or Unix timestamp (default: no lower bound)")),
+			mcp.WithString("end", mcp.Description("End of the time range to delete as RFC3339 or Unix timestamp (default: no upper bound)")),
+			mcp.WithString("dry_run", mcp.Description("Set to 'true' to only preview the deletion (the default unless confirm is 'true')")),
+			mcp.WithString("confirm", mcp.Description("Set to 'true' to delete; requires the confirm_token of a dry run with the same matches, start, end and server")),
+			mcp.WithString("confirm_token", mcp.Description("Token returned by the dry run of this selection; valid for 10 minutes")),
+		)
+
+		registerPrometheusTools(s, client, sc, middleware, toolSnapshotTSDB, "Create a TSDB snapshot via the TSDB admin API (requires Prometheus --web.enable-admin-api) and return its directory name under <data-dir>/snapshots, e.g. to capture state before risky changes", noTruncation, handleSnapshotTSDB,
+			mcp.WithReadOnlyHintAnnotation(false),
+			mcp.WithDestructiveHintAnnotation(false),
+			mcp.WithString("skip_head", mcp.Description("Set to 'true' to leave out data still in the head block (faster, but the last hours are missing)")),
+		)
+	}
+
+	registerPrometheusTools(s, client, sc, middleware, toolReloadConfig, "Reload the Prometheus configuration (POST /-/reload, requires --web.enable-lifecycle) and confirm it by comparing the hash of /api/v1/status/config before and after", noTruncation, handleReloadConfig,
+		mcp.WithReadOnlyHintAnnotation(false),
+		mcp.WithDestructiveHintAnnotation(false),
+	)
+
+	// Server introspection
+	registerServerConfigTool(s, sc, middleware)
+	registerInvocationHistoryTool(s, sc, middleware)
+	registerConnectivityTool(s, client, sc, middleware)
+
+	// Query templates
+	registerTemplateTools(s, sc, middleware)
+
+	// Test scrape targets
+	registerTestTargetTools(s, sc, middleware)
+
+	// Export helpers
+	registerDashboardTool(s, sc, middleware)
+
+	// Local PromQL validation and explanation
+	registerValidatePromQLTool(s, sc, middleware)
+	registerExplainPromQLTool(s, sc, middleware)
+
+	// Read-only resources, investigation prompts and completion of their
+	// arguments
+	registerPrometheusResources(s, client, sc)
+	registerInvestigationPrompts(s)
+	registerCompletions(s, client, sc)
+
+	// Record the calls of tools that do not record their own invocations,
+	// such as local and plugin tools
+	if sc.RecordsInvocations() {
+		mcpserver.WithToolHandlerMiddleware(auditToolCalls(sc))(s)
+	}
+
+	// Custom tools of embedding applications
+	for _, p := range sc.ToolPlugins() {
+		if err := p.Register(s, sc); err != nil {
+			return fmt.Errorf("tools: register plugin %s: %w", p.Name(), err)
+		}
+		sc.Logger().Debug("Registered tool plugin", "plugin", p.Name())
+	}
+
 	return nil
 }
 
-// truncateWithAdvice trims text to MaxResultLength and appends the given
-// advice when truncation occurs. It tries to end at the last newline within
-// the trailing 1000 bytes to avoid cutting mid-line, and backs off any
-// half-rune at the cut so the result remains valid UTF-8 (the advice strings
-// start with multi-byte characters, so a half-rune tail would corrupt the
-// JSON encoding downstream).
-func truncateWithAdvice(text, advice string) string {
-	if len(text) <= MaxResultLength {
+// truncateWithAdvice trims text to limit bytes and appends the given advice
+// when truncation occurs. It tries to end at the last newline within the
+// trailing 1000 bytes (half the limit for small limits) to avoid cutting
+// mid-line, and backs off any half-rune at the cut so the result remains
+// valid UTF-8 (the advice strings start with multi-byte characters, so a
+// half-rune tail would corrupt the JSON encoding downstream).
+func truncateWithAdvice(text, advice string, limit int) string {
+	if len(text) <= limit {
 		return text
 	}
-	truncated := text[:MaxResultLength]
-	if lastNewline := strings.LastIndex(truncated, "\n"); lastNewline > MaxResultLength-1000 {
+	truncated := text[:limit]
+	if lastNewline := strings.LastIndex(truncated, "\n"); lastNewline > limit-min(1000, limit/2) {
 		truncated = truncated[:lastNewline]
 	} else {
 		// No usable newline anchor — the byte cut may sit mid-rune. UTF-8
@@ -389,11 +893,42 @@ func truncateWithAdvice(text, advice string) string {
 	return truncated + advice
 }
 
-// formatQueryResult formats the query result. When unlimited is set, a
+// formatQueryResult formats the query result, labelling values with unit when
+// they were converted. When unlimited is set, a
 // warning prefix is added; otherwise the raw formatted result is returned and
 // truncationMiddleware applies the size cap downstream.
-func formatQueryResult(resultType string, result any, unlimited bool) string {
-	resultStr := fmt.Sprintf("Query executed successfully.\nResult Type: %s\nResult: %+v", resultType, result)
+func formatQueryResult(resultType string, result any, unit string, unlimited bool) string {
+	var body string
+	switch v := result.(type) {
+	case model.Vector:
+		body = formatVector(v, unit)
+	case model.Matrix:
+		body = formatMatrix(v, unit)
+	case *model.Scalar:
+		body = formatScalar(v, unit)
+	case *model.String:
+		body = formatString(v)
+	default:
+		body = fmt.Sprintf("%+v", result)
+	}
+	return queryResultEnvelope(resultType, body, unlimited)
+}
+
+// parseConvertTo parses the convert_to parameter of the query tools. ok is
+// false when the parameter is not set.
+func parseConvertTo(params map[string]any) (c format.UnitConverter, ok bool, err error) {
+	expr := getStringParam(params, "convert_to")
+	if expr == "" {
+		return format.UnitConverter{}, false, nil
+	}
+	c, err = format.ParseUnitConversion(expr)
+	return c, err == nil, err
+}
+
+// queryResultEnvelope wraps a formatted query result body in the common
+// success header, prefixed with a warning when output is unlimited.
+func queryResultEnvelope(resultType, body string, unlimited bool) string {
+	resultStr := fmt.Sprintf("Query executed successfully.\nResult Type: %s\nResult:\n%s", resultType, body)
 	if unlimited {
 		return "⚠️  WARNING: Unlimited output enabled - this response may be very large and could impact performance.\n\n" + resultStr
 	}
@@ -464,36 +999,84 @@ func createClientFromParams(ctx context.Context, params map[string]any, defaultC
 	prometheusURL, hasURL := params["prometheus_url"].(string)
 	explicitOrgID, _ := params["org_id"].(string)
 
+	if err := validateOrgID(explicitOrgID, getStringParam(params, "allow_multi_org") == "true"); err != nil {
+		return nil, err
+	}
+
 	orgID, err := resolveTenantOrgID(ctx, sc, explicitOrgID)
 	if err != nil {
 		return nil, err
 	}
 	hasOrgID := orgID != ""
+	profile := getStringParam(params, "profile")
+	alertmanagerURL := getStringParam(params, "alertmanager_url")
 
-	// If neither parameter is provided and OAuth didn't inject an org ID, use default client
-	if !hasURL && !hasOrgID {
+	// If no parameter is provided and OAuth didn't inject an org ID, use default client
+	if !hasURL && !hasOrgID && profile == "" && alertmanagerURL == "" {
 		if defaultClient != nil && defaultClient.client != nil {
 			return defaultClient, nil
 		}
 		return nil, fmt.Errorf("prometheus_url parameter is required (no default Prometheus configuration available)")
 	}
 
-	// Start with environment config to inherit authentication
+	// Start with the selected profile, or the default config, to inherit
+	// authentication. prometheus_url is refused below when that would send
+	// the inherited credentials to the caller's URL.
 	config := sc.PrometheusConfig()
+	if profile != "" {
+		if config, err = sc.ProfileConfig(profile); err != nil {
+			return nil, err
+		}
+		sc.Logger().Debug("Using Prometheus profile", "profile", profile)
+	}
 
 	// Override URL if provided (validated to prevent SSRF via scheme abuse).
 	if hasURL && prometheusURL != "" {
 		if err := validatePrometheusURL(prometheusURL); err != nil {
 			return nil, err
 		}
+		// Credentials are only sent to the server they were configured
+		// for, never to a URL the caller chose.
+		if auth := config.AuthType(); auth != "none" {
+			source := "the default configuration"
+			if profile != "" {
+				source = fmt.Sprintf("profile %q", profile)
+			}
+			return nil, fmt.Errorf("prometheus_url cannot be combined with %s, which carries %s credentials; add the server as a profile instead", source, auth)
+		}
 		config.URL = prometheusURL
+		// The caller's URL is taken as-is; the configured prefix belongs
+		// to the default server.
+		config.PathPrefix = ""
 		sc.Logger().Debug("Overriding Prometheus URL from parameter", "url", prometheusURL)
 	}
 
+	if alertmanagerURL != "" {
+		if err := validateTargetURL("alertmanager_url", alertmanagerURL); err != nil {
+			return nil, err
+		}
+		// Alertmanager requests carry its own credentials or, failing
+		// those, the Prometheus ones; neither goes to a URL the caller
+		// chose unless it is the configured Alertmanager.
+		if alertmanagerURL != config.Alertmanager.URL && (config.Alertmanager.HasAuth() || config.AuthType() != "none") {
+			return nil, fmt.Errorf("alertmanager_url cannot be combined with configured credentials; set the Alertmanager URL in the server configuration or a profile instead")
+		}
+		config.Alertmanager.URL = alertmanagerURL
+		sc.Logger().Debug("Overriding Alertmanager URL from parameter", "url", alertmanagerURL)
+	}
+
 	// Set the resolved org ID (from tenancy or explicit override)
 	if hasOrgID {
 		config.OrgID = orgID
 		sc.Logger().Debug("Setting Prometheus OrgID", "orgID", orgID)
+
+		// Send tenants living on another shard to their backend unless the
+		// caller chose a URL or profile explicitly.
+		if routeURL, ok := sc.TenantRouter().Route(orgID); ok && !hasURL && profile == "" {
+			config.URL = routeURL
+			config.PathPrefix = ""
+			sc.Logger().Debug("Routing tenant to its backend", "orgID", orgID, "url", redactURL(routeURL))
+		}
 	}
 
 	// Validate that we have a URL
@@ -501,11 +1084,49 @@ func createClientFromParams(ctx context.Context, params map[string]any, defaultC
 		return nil, fmt.Errorf("prometheus_url parameter is required when using dynamic client configuration")
 	}
 
-	sc.Logger().Debug("Creating dynamic client with inherited config",
-		"url", config.URL, "orgID", config.OrgID, "hasAuth", config.Username != "" || config.Token != "")
+	create := func() (server.CachedClient, error) {
+		sc.Logger().Debug("Creating dynamic client with inherited config",
+			"url", config.URL, "orgID", config.OrgID, "hasAuth", config.Username != "" || config.Token != "")
+		return NewClient(config, sc.Logger())
+	}
+
+	// Reuse the client of an earlier call with the same configuration. A
+	// configuration without a key gets a client of its own.
+	key, err := clientCacheKey(config)
+	if err != nil {
+		sc.Logger().Warn("Not caching dynamic client", "error", err)
+		client, err := create()
+		if err != nil {
+			return nil, err
+		}
+		return client.(*Client), nil
+	}
+	client, err := sc.ClientCache().Get(key, create)
+	if err != nil {
+		return nil, err
+	}
+	return client.(*Client), nil
+}
+
+// callerClient returns the client that requests made for the caller outside
+// a tool call, such as resource reads and completions, go through: the
+// default client, or under OAuth tenancy one scoped to the caller's tenants.
+func callerClient(ctx context.Context, defaultClient *Client, sc *server.ServerContext) (*Client, error) {
+	return createClientFromParams(ctx, map[string]any{}, defaultClient, sc)
+}
 
-	// Create and return new client
-	return NewClient(config, sc.Logger())
+// clientCacheKey identifies the clients built from config: its URL, org ID,
+// credentials, TLS and Alertmanager settings. The tracer provider and
+// request limiter are the server's and shared by every client.
+func clientCacheKey(config server.PrometheusConfig) (string, error) {
+	config.TracerProvider = nil
+	config.RequestLimiter = nil
+	data, err := json.Marshal(config)
+	if err != nil {
+		return "", fmt.Errorf("client cache key: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return string(sum[:]), nil
 }
 
 // handleExecuteQuery handles the execute_query tool with enhanced parameters
@@ -528,6 +1149,27 @@ func handleExecuteQuery(ctx context.Context, request mcp.CallToolRequest, client
 	timeParam, _ := params["time"].(string)
 	unlimited := isUnlimitedRequest(request)
 
+	converter, convert, err := parseConvertTo(params)
+	if err == nil && convert && getStringParam(params, "format") == "openmetrics" {
+		err = errors.New("convert_to cannot be combined with format 'openmetrics'")
+	}
+	var aggregate analysis.Aggregate
+	var deduplicate bool
+	if err == nil {
+		aggregate, deduplicate, err = parseDeduplicate(params)
+	}
+	if err != nil {
+		return &mcp.CallToolResult{
+			IsError: true,
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: contentTypeText,
+					Text: fmt.Sprintf("Error: %v", err),
+				},
+			},
+		}, nil
+	}
+
 	// Extract new optional parameters
 	options := QueryOptions{
 		Timeout:       getStringParam(params, "timeout"),
@@ -539,12 +1181,55 @@ func handleExecuteQuery(ctx context.Context, request mcp.CallToolRequest, client
 	sc.Logger().Debug("Executing PromQL query", "query", query, "time", timeParam, "options", options, "unlimited", unlimited)
 
 	// Use enhanced query if any options are provided
+	execute := func(ctx context.Context, timeParam string) (*QueryResult, error) {
+		if options.Timeout != "" || options.Limit != "" || options.Stats != "" || options.LookbackDelta != "" {
+			return client.ExecuteQueryWithOptions(ctx, query, timeParam, options)
+		}
+		return client.ExecuteQuery(ctx, query, timeParam)
+	}
+
+	// The cost guard only needs the evaluation time; an invalid time is
+	// reported by the query itself.
+	if refused := checkQueryPolicy(sc, query, time.Time{}, time.Time{}, 0); refused != nil {
+		return refused, nil
+	}
+	var header string
+	evalTime, timeErr := time.Now(), error(nil)
+	if timeParam != "" {
+		evalTime, timeErr = parseTimeParam(timeParam)
+	}
+	if timeErr == nil {
+		warning, refused := checkQueryCost(ctx, client, sc, query, evalTime, evalTime, 1)
+		if refused != nil {
+			return refused, nil
+		}
+		if warning != "" {
+			header = warning + "\n"
+		}
+	}
+
 	var result *QueryResult
-	var err error
-	if options.Timeout != "" || options.Limit != "" || options.Stats != "" || options.LookbackDelta != "" {
-		result, err = client.ExecuteQueryWithOptions(ctx, query, timeParam, options)
+	if timeParam == "" && getStringParam(params, "stale_aware_time") == "true" {
+		delta, maxBacktrack, parseErr := parseStaleAwareParams(params)
+		if parseErr != nil {
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{
+					mcp.TextContent{
+						Type: contentTypeText,
+						Text: fmt.Sprintf("Error: %v", parseErr),
+					},
+				},
+			}, nil
+		}
+		var stale staleAwareResult
+		stale, err = executeStaleAware(ctx, time.Now(), delta, maxBacktrack, execute)
+		if err == nil {
+			result = stale.Result
+			header += stale.header(maxBacktrack) + "\n\n"
+		}
 	} else {
-		result, err = client.ExecuteQuery(ctx, query, timeParam)
+		result, err = execute(ctx, timeParam)
 	}
 
 	if err != nil {
@@ -554,22 +1239,79 @@ func handleExecuteQuery(ctx context.Context, request mcp.CallToolRequest, client
 			Content: []mcp.Content{
 				mcp.TextContent{
 					Type: contentTypeText,
-					Text: fmt.Sprintf("Error executing query: %v", err),
+					Text: describeQueryError("Error executing query", err),
 				},
 			},
 		}, nil
 	}
 
-	formattedResult := formatQueryResult(result.ResultType, result.Result, unlimited)
+	if filter := getStringParam(params, "namespace_filter"); filter != "" {
+		result.Result = filterQueryResultByNamespace(result.Result, filter)
+	}
+	if deduplicate {
+		result.Result = deduplicateQueryResult(result.Result, aggregate)
+	}
+
+	var unit string
+	if convert {
+		convertQueryResult(result.Result, converter)
+		unit = converter.To
+	}
 
-	return &mcp.CallToolResult{
+	if getStringParam(params, "format") == "openmetrics" {
+		vector, ok := result.Result.(model.Vector)
+		if !ok {
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{
+					mcp.TextContent{
+						Type: contentTypeText,
+						Text: fmt.Sprintf("Error: format 'openmetrics' requires an instant vector result (got %s)", result.ResultType),
+					},
+				},
+			}, nil
+		}
+		text, err := format.OpenMetricsText(vector, fetchFamilyMetadata(ctx, client, vector, sc.Logger()))
+		if err != nil {
+			sc.Logger().Error("Failed to encode OpenMetrics", "error", err)
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{
+					mcp.TextContent{
+						Type: contentTypeText,
+						Text: fmt.Sprintf("Error encoding OpenMetrics: %v", err),
+					},
+				},
+			}, nil
+		}
+		return withStructuredContent(&mcp.CallToolResult{
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: contentTypeText,
+					Text: text,
+				},
+			},
+		}, newQueryOutput(query, result, unit, structuredResultLimit(sc, request))), nil
+	}
+
+	var formattedResult string
+	if getStringParam(params, "group_by_namespace") == "true" {
+		if body, ok := formatQueryResultByNamespace(result.Result, unit); ok {
+			formattedResult = queryResultEnvelope(result.ResultType, body, unlimited)
+		}
+	}
+	if formattedResult == "" {
+		formattedResult = formatQueryResult(result.ResultType, result.Result, unit, unlimited)
+	}
+
+	return withStructuredContent(&mcp.CallToolResult{
 		Content: []mcp.Content{
 			mcp.TextContent{
 				Type: contentTypeText,
-				Text: formattedResult,
+				Text: header + formattedResult,
 			},
 		},
-	}, nil
+	}, newQueryOutput(query, result, unit, structuredResultLimit(sc, request))), nil
 }
 
 // handleExecuteRangeQuery handles the execute_range_query tool with enhanced parameters
@@ -615,59 +1357,416 @@ func handleExecuteRangeQuery(ctx context.Context, request mcp.CallToolRequest, c
 		}, nil
 	}
 
-	step, ok := params["step"].(string)
-	if !ok || step == "" {
+	// Without a step, size it for rangeQueryPoints samples per series so a
+	// long range does not produce more points than fit in a result.
+	step := getStringParam(params, "step")
+	var stepNote string
+	if step == "" {
+		startTime, startErr := parseTimeParam(start)
+		endTime, endErr := parseTimeParam(end)
+		if err := cmp.Or(startErr, endErr); err != nil {
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{
+					mcp.TextContent{
+						Type: contentTypeText,
+						Text: fmt.Sprintf("Error: %v", err),
+					},
+				},
+			}, nil
+		}
+		points := rangeQueryPoints(sc)
+		step = model.Duration(max(autoStep(query, startTime, endTime, points), sc.QueryPolicy().MinStep)).String()
+		stepNote = fmt.Sprintf("Step %s was computed for about %d points per series; pass step to choose another resolution.", step, points)
+	}
+	unlimited := isUnlimitedRequest(request)
+
+	converter, convert, err := parseConvertTo(params)
+	if err != nil {
 		return &mcp.CallToolResult{
 			IsError: true,
 			Content: []mcp.Content{
 				mcp.TextContent{
 					Type: contentTypeText,
-					Text: "Error: step parameter is required and must be a string",
+					Text: fmt.Sprintf("Error: %v", err),
 				},
 			},
 		}, nil
 	}
-	unlimited := isUnlimitedRequest(request)
-
-	// Extract new optional parameters
-	options := QueryOptions{
-		Timeout:       getStringParam(params, "timeout"),
-		Limit:         getStringParam(params, "limit"),
-		Stats:         getStringParam(params, "stats"),
-		LookbackDelta: getStringParam(params, "lookback_delta"),
-	}
-
-	sc.Logger().Debug("Executing PromQL range query", "query", query, "start", start, "end", end, "step", step, "options", options, "unlimited", unlimited)
-
-	// Use enhanced query if any options are provided
-	var result *QueryResult
-	var err error
-	if options.Timeout != "" || options.Limit != "" || options.Stats != "" || options.LookbackDelta != "" {
-		result, err = client.ExecuteRangeQueryWithOptions(ctx, query, start, end, step, options)
-	} else {
-		result, err = client.ExecuteRangeQuery(ctx, query, start, end, step)
-	}
 
+	maxPoints, err := parseMaxPointsPerSeries(params)
 	if err != nil {
-		sc.Logger().Error("Failed to execute range query", "error", err)
 		return &mcp.CallToolResult{
 			IsError: true,
 			Content: []mcp.Content{
 				mcp.TextContent{
 					Type: contentTypeText,
-					Text: fmt.Sprintf("Error executing range query: %v", err),
+					Text: fmt.Sprintf("Error: %v", err),
 				},
 			},
 		}, nil
 	}
 
-	formattedResult := formatQueryResult(result.ResultType, result.Result, unlimited)
-
+	outputFormat := getStringParam(params, "format")
+	withChart := getStringParam(params, "chart") == "true"
+	if withChart && outputFormat == "csv" {
+		return &mcp.CallToolResult{
+			IsError: true,
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: contentTypeText,
+					Text: "Error: chart cannot be combined with format 'csv'",
+				},
+			},
+		}, nil
+	}
+	if outputFormat != "" && outputFormat != "text" && outputFormat != "csv" && outputFormat != "sparkline" {
+		return &mcp.CallToolResult{
+			IsError: true,
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: contentTypeText,
+					Text: fmt.Sprintf("Error: format must be 'text', 'csv' or 'sparkline' (got %q)", outputFormat),
+				},
+			},
+		}, nil
+	}
+
+	// Extract new optional parameters
+	options := QueryOptions{
+		Timeout:       getStringParam(params, "timeout"),
+		Limit:         getStringParam(params, "limit"),
+		Stats:         getStringParam(params, "stats"),
+		LookbackDelta: getStringParam(params, "lookback_delta"),
+	}
+
+	includeTrend := getStringParam(params, "include_trend") == "true"
+	var trendEnd model.Time
+	trendHorizon := defaultTrendHorizon
+	if includeTrend {
+		endTime, err := parseTimeParam(end)
+		if err != nil {
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{
+					mcp.TextContent{
+						Type: contentTypeText,
+						Text: fmt.Sprintf("Error: %v", err),
+					},
+				},
+			}, nil
+		}
+		trendEnd = model.TimeFromUnixNano(endTime.UnixNano())
+
+		if v := getStringParam(params, "project_steps"); v != "" {
+			n, nErr := strconv.Atoi(v)
+			stepDuration, stepErr := model.ParseDuration(step)
+			if nErr != nil || n <= 0 || stepErr != nil {
+				return &mcp.CallToolResult{
+					IsError: true,
+					Content: []mcp.Content{
+						mcp.TextContent{
+							Type: contentTypeText,
+							Text: fmt.Sprintf("Error: project_steps must be a positive integer and step a duration (got project_steps=%q, step=%q)", v, step),
+						},
+					},
+				}, nil
+			}
+			trendHorizon = stepDuration * model.Duration(n)
+		}
+	}
+
+	// Invalid times are reported by the query itself.
+	var costWarning string
+	if startTime, endTime, err := parseFilterTimes(start, end); err == nil {
+		if refused := checkQueryPolicy(sc, query, startTime, endTime, parseStep(step)); refused != nil {
+			return refused, nil
+		}
+		if steps := rangeSteps(startTime, endTime, step); steps > 0 {
+			var refused *mcp.CallToolResult
+			if costWarning, refused = checkQueryCost(ctx, client, sc, query, startTime, endTime, steps); refused != nil {
+				return refused, nil
+			}
+		}
+	}
+
+	sc.Logger().Debug("Executing PromQL range query", "query", query, "start", start, "end", end, "step", step, "options", options, "unlimited", unlimited, "include_trend", includeTrend)
+
+	// Use enhanced query if any options are provided
+	var result *QueryResult
+	if options.Timeout != "" || options.Limit != "" || options.Stats != "" || options.LookbackDelta != "" {
+		result, err = client.ExecuteRangeQueryWithOptions(ctx, query, start, end, step, options)
+	} else {
+		result, err = client.ExecuteRangeQuery(ctx, query, start, end, step)
+	}
+
+	if err != nil {
+		sc.Logger().Error("Failed to execute range query", "error", err)
+		return &mcp.CallToolResult{
+			IsError: true,
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: contentTypeText,
+					Text: describeQueryError("Error executing range query", err),
+				},
+			},
+		}, nil
+	}
+
+	var unit string
+	if convert {
+		convertQueryResult(result.Result, converter)
+		unit = converter.To
+	}
+
+	// Trends are fitted to every sample, before downsampling.
+	var trends string
+	var downsampled int
+	if m, ok := result.Result.(model.Matrix); ok {
+		if includeTrend && len(m) > 0 {
+			trends = "\n" + formatTrends(m, trendEnd, trendHorizon)
+		}
+		if maxPoints > 0 {
+			downsampled = downsampleMatrix(m, maxPoints)
+		}
+	}
+
+	out := newQueryOutput(query, result, unit, structuredResultLimit(sc, request))
+	out.Downsampled = downsampled
+
+	// CSV holds only the samples so it can be imported as is; notes,
+	// warnings and trends are left out.
+	if outputFormat == "csv" {
+		m, _ := result.Result.(model.Matrix)
+		text, err := format.MatrixCSV(m)
+		if err != nil {
+			sc.Logger().Error("Failed to encode CSV", "error", err)
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{
+					mcp.TextContent{
+						Type: contentTypeText,
+						Text: fmt.Sprintf("Error encoding CSV: %v", err),
+					},
+				},
+			}, nil
+		}
+		return withStructuredContent(&mcp.CallToolResult{
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: contentTypeText,
+					Text: text,
+				},
+			},
+		}, out), nil
+	}
+
+	var formattedResult string
+	if m, ok := result.Result.(model.Matrix); ok && outputFormat == "sparkline" {
+		formattedResult = queryResultEnvelope(result.ResultType, formatMatrixSparklines(m, unit), unlimited) + trends
+	} else {
+		formattedResult = formatQueryResult(result.ResultType, result.Result, unit, unlimited) + trends
+	}
+	if downsampled > 0 {
+		formattedResult = fmt.Sprintf("Downsampled %d series to %d points each.\n\n", downsampled, maxPoints) + formattedResult
+	}
+	if stepDuration, err := model.ParseDuration(step); err == nil {
+		if warnings := validateStepSize(query, time.Duration(stepDuration)); len(warnings) > 0 {
+			formattedResult = strings.Join(warnings, "\n") + "\n\n" + formattedResult
+		}
+	}
+	if stepNote != "" {
+		formattedResult = stepNote + "\n\n" + formattedResult
+	}
+	if costWarning != "" {
+		formattedResult = costWarning + "\n" + formattedResult
+	}
+
+	var images []mcp.Content
+	if m, ok := result.Result.(model.Matrix); ok && withChart {
+		if img, legend, err := rangeChart(m); err != nil {
+			formattedResult += "\n\nChart unavailable: " + err.Error()
+		} else {
+			formattedResult += "\n\n" + legend
+			images = append(images, img)
+		}
+	}
+
+	return withStructuredContent(&mcp.CallToolResult{
+		Content: append([]mcp.Content{
+			mcp.TextContent{
+				Type: contentTypeText,
+				Text: formattedResult,
+			},
+		}, images...),
+	}, out), nil
+}
+
+// Characters of an evaluate_rule_timeline chart, one per step.
+const (
+	timelineInactive = '.'
+	timelinePending  = 'P'
+	timelineFiring   = 'F'
+)
+
+// ruleTimeline simulates the alerting rule state machine for one series over
+// steps evaluations spaced step apart from start. The rule is active when the
+// series has a sample at an evaluation time; it is pending until it has been
+// active for forDuration, then firing, and inactive again once the sample
+// disappears.
+func ruleTimeline(samples []model.SamplePair, start model.Time, step time.Duration, steps int, forDuration time.Duration) string {
+	active := make(map[model.Time]bool, len(samples))
+	for _, s := range samples {
+		active[s.Timestamp] = true
+	}
+
+	chart := make([]byte, steps)
+	var activeSince model.Time
+	wasActive := false
+	for i := range steps {
+		t := start.Add(time.Duration(i) * step)
+		if !active[t] {
+			chart[i] = timelineInactive
+			wasActive = false
+			continue
+		}
+		if !wasActive {
+			activeSince, wasActive = t, true
+		}
+		if t.Sub(activeSince) >= forDuration {
+			chart[i] = timelineFiring
+		} else {
+			chart[i] = timelinePending
+		}
+	}
+	return string(chart)
+}
+
+// handleEvaluateRuleTimeline handles the evaluate_rule_timeline tool
+func handleEvaluateRuleTimeline(ctx context.Context, request mcp.CallToolRequest, client *Client, sc *server.ServerContext) (*mcp.CallToolResult, error) {
+	params := extractParams(request)
+
+	for _, key := range []string{"rule_expr", "start", "end", "step"} {
+		if getStringParam(params, key) == "" {
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{
+					mcp.TextContent{
+						Type: contentTypeText,
+						Text: fmt.Sprintf("Error: %s parameter is required and must be a string", key),
+					},
+				},
+			}, nil
+		}
+	}
+
+	ruleExpr := getStringParam(params, "rule_expr")
+	start := getStringParam(params, "start")
+	end := getStringParam(params, "end")
+	step := getStringParam(params, "step")
+
+	startTime, startErr := parseTimeParam(start)
+	endTime, endErr := parseTimeParam(end)
+	stepDuration, stepErr := model.ParseDuration(step)
+	var forDuration model.Duration
+	var forErr error
+	if v := getStringParam(params, "for_duration"); v != "" {
+		forDuration, forErr = model.ParseDuration(v)
+	}
+	if err := errors.Join(startErr, endErr, stepErr, forErr); err != nil {
+		return &mcp.CallToolResult{
+			IsError: true,
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: contentTypeText,
+					Text: fmt.Sprintf("Error: %v", err),
+				},
+			},
+		}, nil
+	}
+	if stepDuration <= 0 || !endTime.After(startTime) {
+		return &mcp.CallToolResult{
+			IsError: true,
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: contentTypeText,
+					Text: "Error: step must be positive and end must be after start",
+				},
+			},
+		}, nil
+	}
+
+	if refused := checkQueryPolicy(sc, ruleExpr, startTime, endTime, time.Duration(stepDuration)); refused != nil {
+		return refused, nil
+	}
+	steps := int(endTime.Sub(startTime)/time.Duration(stepDuration)) + 1
+	costWarning, refused := checkQueryCost(ctx, client, sc, ruleExpr, startTime, endTime, steps)
+	if refused != nil {
+		return refused, nil
+	}
+
+	sc.Logger().Debug("Evaluating rule timeline", "rule_expr", ruleExpr, "for", forDuration, "start", start, "end", end, "step", step)
+
+	result, err := client.ExecuteRangeQuery(ctx, ruleExpr, start, end, step)
+	if err != nil {
+		sc.Logger().Error("Failed to execute rule expression", "error", err)
+		return &mcp.CallToolResult{
+			IsError: true,
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: contentTypeText,
+					Text: describeQueryError("Error executing rule expression", err),
+				},
+			},
+		}, nil
+	}
+	matrix, ok := result.Result.(model.Matrix)
+	if !ok {
+		return &mcp.CallToolResult{
+			IsError: true,
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: contentTypeText,
+					Text: fmt.Sprintf("Error: expected a matrix result, got %s", result.ResultType),
+				},
+			},
+		}, nil
+	}
+
+	evalStart := model.TimeFromUnixNano(startTime.UnixNano())
+
+	var b strings.Builder
+	if costWarning != "" {
+		b.WriteString(costWarning + "\n")
+	}
+	fmt.Fprintf(&b, "Rule timeline for: %s (for: %s)\n", ruleExpr, forDuration)
+	fmt.Fprintf(&b, "%d evaluations every %s from %s to %s\n", steps, stepDuration, formatResultTime(evalStart), formatResultTime(evalStart.Add(time.Duration(steps-1)*time.Duration(stepDuration))))
+	fmt.Fprintf(&b, "Legend: %c inactive, %c pending, %c firing\n\n", timelineInactive, timelinePending, timelineFiring)
+
+	if len(matrix) == 0 {
+		b.WriteString("The expression returned no series: the rule is inactive for the whole window.\n")
+	}
+	width := 0
+	for _, s := range matrix {
+		width = max(width, len(s.Metric.String()))
+	}
+	firing := 0
+	for _, s := range matrix {
+		timeline := ruleTimeline(s.Values, evalStart, time.Duration(stepDuration), steps, time.Duration(forDuration))
+		if strings.IndexByte(timeline, timelineFiring) >= 0 {
+			firing++
+		}
+		fmt.Fprintf(&b, "%-*s: %s\n", width, s.Metric, timeline)
+	}
+	if len(matrix) > 0 {
+		fmt.Fprintf(&b, "\n%d of %d series fired during the window.\n", firing, len(matrix))
+	}
+
 	return &mcp.CallToolResult{
 		Content: []mcp.Content{
 			mcp.TextContent{
 				Type: contentTypeText,
-				Text: formattedResult,
+				Text: b.String(),
 			},
 		},
 	}, nil
@@ -709,19 +1808,20 @@ func handleGetMetricMetadata(ctx context.Context, request mcp.CallToolRequest, c
 		}, nil
 	}
 
-	return &mcp.CallToolResult{
+	return withStructuredContent(&mcp.CallToolResult{
 		Content: []mcp.Content{
 			mcp.TextContent{
 				Type: contentTypeText,
 				Text: fmt.Sprintf("Metadata for metric '%s':\n%+v", metric, metadata),
 			},
 		},
-	}, nil
+	}, newMetricMetadataOutput(metadata)), nil
 }
 
 // handleGetTargets handles the get_targets tool (existing)
 func handleGetTargets(ctx context.Context, request mcp.CallToolRequest, client *Client, sc *server.ServerContext) (*mcp.CallToolResult, error) {
-	sc.Logger().Debug("Getting targets")
+	summaryOnly := getStringParam(extractParams(request), "summary_only") == "true"
+	sc.Logger().Debug("Getting targets", "summary_only", summaryOnly)
 
 	targets, err := client.GetTargets(ctx)
 	if err != nil {
@@ -737,6 +1837,17 @@ func handleGetTargets(ctx context.Context, request mcp.CallToolRequest, client *
 		}, nil
 	}
 
+	if summaryOnly {
+		return withStructuredContent(&mcp.CallToolResult{
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: contentTypeText,
+					Text: formatScrapePoolSummary(targets),
+				},
+			},
+		}, newTargetsOutput(targets, true)), nil
+	}
+
 	result := fmt.Sprintf("Targets information:\nActive targets: %d\nDropped targets: %d\n\nActive Targets: %+v\nDropped Targets: %+v",
 		len(targets.ActiveTargets),
 		len(targets.DroppedTargets),
@@ -744,14 +1855,75 @@ func handleGetTargets(ctx context.Context, request mcp.CallToolRequest, client *
 		targets.DroppedTargets,
 	)
 
-	return &mcp.CallToolResult{
+	return withStructuredContent(&mcp.CallToolResult{
 		Content: []mcp.Content{
 			mcp.TextContent{
 				Type: contentTypeText,
 				Text: result,
 			},
 		},
-	}, nil
+	}, newTargetsOutput(targets, false)), nil
+}
+
+// scrapePoolStats accumulates the active targets of one scrape pool.
+type scrapePoolStats struct {
+	scrapePool        string
+	total             int
+	healthy           int
+	scrapeDurationSum float64 // seconds
+	maxScrapeDuration float64 // seconds
+}
+
+// healthPercentage formats the healthy share as "42/50 (84%)".
+func (s *scrapePoolStats) healthPercentage() string {
+	return fmt.Sprintf("%d/%d (%.0f%%)", s.healthy, s.total, 100*float64(s.healthy)/float64(s.total))
+}
+
+// summarizeScrapePools aggregates active targets by scrape pool, sorted by
+// pool name.
+func summarizeScrapePools(activeTargets []interface{}) []*scrapePoolStats {
+	pools := map[string]*scrapePoolStats{}
+	for _, t := range activeTargets {
+		target, ok := t.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		name, _ := target["scrapePool"].(string)
+		stats, ok := pools[name]
+		if !ok {
+			stats = &scrapePoolStats{scrapePool: name}
+			pools[name] = stats
+		}
+		stats.total++
+		if health, _ := target["health"].(v1.HealthStatus); health == v1.HealthGood {
+			stats.healthy++
+		}
+		duration, _ := target["lastScrapeDuration"].(float64)
+		stats.scrapeDurationSum += duration
+		stats.maxScrapeDuration = max(stats.maxScrapeDuration, duration)
+	}
+
+	result := make([]*scrapePoolStats, 0, len(pools))
+	for _, name := range slices.Sorted(maps.Keys(pools)) {
+		result = append(result, pools[name])
+	}
+	return result
+}
+
+// formatScrapePoolSummary renders one row per scrape pool.
+func formatScrapePoolSummary(targets *TargetsResult) string {
+	pools := summarizeScrapePools(targets.ActiveTargets)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Scrape pool summary: %d pools, %d active targets, %d dropped targets\n\n",
+		len(pools), len(targets.ActiveTargets), len(targets.DroppedTargets))
+	b.WriteString("scrapePool | total_targets | healthy_targets | unhealthy_targets | health_percentage | avg_scrape_duration_ms | max_scrape_duration_ms\n")
+	for _, p := range pools {
+		fmt.Fprintf(&b, "%s | %d | %d | %d | %s | %.1f | %.1f\n",
+			p.scrapePool, p.total, p.healthy, p.total-p.healthy, p.healthPercentage(),
+			1000*p.scrapeDurationSum/float64(p.total), 1000*p.maxScrapeDuration)
+	}
+	return b.String()
 }
 
 // NEW TOOL HANDLERS START HERE
@@ -766,7 +1938,37 @@ func handleListLabelNames(ctx context.Context, request mcp.CallToolRequest, clie
 		Limit:     getStringParam(params, "limit"),
 	}
 
-	sc.Logger().Debug("Listing label names", "options", options)
+	withCardinality := getStringParam(params, "with_cardinality") == "true"
+	sortBy := getStringParam(params, "sort_by")
+	if sortBy != "" && sortBy != "name" && sortBy != "cardinality" {
+		return &mcp.CallToolResult{
+			IsError: true,
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: contentTypeText,
+					Text: fmt.Sprintf("Error: invalid sort_by %q: must be 'name' or 'cardinality'", sortBy),
+				},
+			},
+		}, nil
+	}
+	minCardinality := 0
+	if v := getStringParam(params, "min_cardinality"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{
+					mcp.TextContent{
+						Type: contentTypeText,
+						Text: fmt.Sprintf("Error: invalid min_cardinality %q: must be a non-negative integer", v),
+					},
+				},
+			}, nil
+		}
+		minCardinality = n
+	}
+
+	sc.Logger().Debug("Listing label names", "options", options, "with_cardinality", withCardinality)
 
 	result, err := client.ListLabelNames(ctx, options)
 	if err != nil {
@@ -782,9 +1984,31 @@ func handleListLabelNames(ctx context.Context, request mcp.CallToolRequest, clie
 		}, nil
 	}
 
+	structured := newLabelNamesOutput(result)
 	var responseText string
 	if len(result.LabelNames) == 0 {
 		responseText = "No label names found"
+	} else if withCardinality {
+		cardinalities, err := fetchLabelCardinalities(ctx, client, result.LabelNames, options)
+		if err != nil {
+			sc.Logger().Error("Failed to fetch label cardinalities", "error", err)
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{
+					mcp.TextContent{
+						Type: contentTypeText,
+						Text: fmt.Sprintf("Error fetching label cardinality: %v", err),
+					},
+				},
+			}, nil
+		}
+		filtered := filterLabelCardinalities(cardinalities, minCardinality, sortBy == "cardinality")
+		if len(filtered) > maxStructuredItems {
+			structured.Cardinalities, structured.Truncated = newLabelCardinalityOutputs(filtered[:maxStructuredItems]), true
+		} else {
+			structured.Cardinalities = newLabelCardinalityOutputs(filtered)
+		}
+		responseText = formatLabelCardinalities(filtered, minCardinality)
 	} else {
 		responseText = fmt.Sprintf("Found %d label names:\n", len(result.LabelNames))
 		for i, labelName := range result.LabelNames {
@@ -795,41 +2019,115 @@ func handleListLabelNames(ctx context.Context, request mcp.CallToolRequest, clie
 	if len(result.Warnings) > 0 {
 		responseText += fmt.Sprintf("\nWarnings: %v", result.Warnings)
 	}
-
-	return &mcp.CallToolResult{
-		Content: []mcp.Content{
-			mcp.TextContent{
-				Type: contentTypeText,
-				Text: responseText,
+
+	return withStructuredContent(&mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.TextContent{
+				Type: contentTypeText,
+				Text: responseText,
+			},
+		},
+	}, structured), nil
+}
+
+// metricTypeLabel describes the type of metric: the type reported by the
+// metadata API, or the type promql.InferMetricType guesses from the name.
+// Counter metadata may be keyed without the _total suffix.
+func metricTypeLabel(name string, metadata map[string][]v1.Metadata) string {
+	entries := metadata[name]
+	if base, ok := strings.CutSuffix(name, "_total"); ok && len(entries) == 0 {
+		entries = metadata[base]
+	}
+	if len(entries) > 0 && entries[0].Type != "" {
+		return string(entries[0].Type)
+	}
+	return promql.InferMetricType(name) + ", inferred"
+}
+
+// handleListLabelValues handles the list_label_values tool
+func handleListLabelValues(ctx context.Context, request mcp.CallToolRequest, client *Client, sc *server.ServerContext) (*mcp.CallToolResult, error) {
+	params := extractParams(request)
+
+	label, ok := params["label"].(string)
+	if !ok || label == "" {
+		return &mcp.CallToolResult{
+			IsError: true,
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: contentTypeText,
+					Text: "Error: label parameter is required and must be a string",
+				},
+			},
+		}, nil
+	}
+	options := LabelOptions{
+		StartTime: getStringParam(params, "start_time"),
+		EndTime:   getStringParam(params, "end_time"),
+		Matches:   extractStringArray(params, "matches"),
+		Limit:     getStringParam(params, "limit"),
+	}
+
+	groupByPrefix := getStringParam(params, "group_by_prefix") == "true"
+	minGroupSize := format.DefaultMinGroupSize
+	if v := getStringParam(params, "min_group_size"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{
+					mcp.TextContent{
+						Type: contentTypeText,
+						Text: fmt.Sprintf("Error: invalid min_group_size %q: must be a positive integer", v),
+					},
+				},
+			}, nil
+		}
+		minGroupSize = n
+	}
+
+	pageSize := defaultLabelValuesPageSize
+	if v := getStringParam(params, "page_size"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxStructuredItems {
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{
+					mcp.TextContent{
+						Type: contentTypeText,
+						Text: fmt.Sprintf("Error: invalid page_size %q: must be an integer between 1 and %d", v, maxStructuredItems),
+					},
+				},
+			}, nil
+		}
+		pageSize = n
+	}
+	after, err := decodeCursor(getStringParam(params, "cursor"))
+	if err != nil {
+		return &mcp.CallToolResult{
+			IsError: true,
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: contentTypeText,
+					Text: fmt.Sprintf("Error: invalid cursor: %v", err),
+				},
 			},
-		},
-	}, nil
-}
-
-// handleListLabelValues handles the list_label_values tool
-func handleListLabelValues(ctx context.Context, request mcp.CallToolRequest, client *Client, sc *server.ServerContext) (*mcp.CallToolResult, error) {
-	params := extractParams(request)
+		}, nil
+	}
 
-	label, ok := params["label"].(string)
-	if !ok || label == "" {
+	withTypes := getStringParam(params, "with_types") == "true"
+	if withTypes && label != model.MetricNameLabel {
 		return &mcp.CallToolResult{
 			IsError: true,
 			Content: []mcp.Content{
 				mcp.TextContent{
 					Type: contentTypeText,
-					Text: "Error: label parameter is required and must be a string",
+					Text: fmt.Sprintf("Error: with_types requires label '%s'", model.MetricNameLabel),
 				},
 			},
 		}, nil
 	}
-	options := LabelOptions{
-		StartTime: getStringParam(params, "start_time"),
-		EndTime:   getStringParam(params, "end_time"),
-		Matches:   extractStringArray(params, "matches"),
-		Limit:     getStringParam(params, "limit"),
-	}
 
-	sc.Logger().Debug("Listing label values", "label", label, "options", options)
+	sc.Logger().Debug("Listing label values", "label", label, "options", options, "group_by_prefix", groupByPrefix, "with_types", withTypes)
 
 	result, err := client.ListLabelValues(ctx, label, options)
 	if err != nil {
@@ -845,33 +2143,63 @@ func handleListLabelValues(ctx context.Context, request mcp.CallToolRequest, cli
 		}, nil
 	}
 
+	// Excluded metrics are dropped before formatting, so they are neither
+	// listed nor counted.
+	excluded := 0
+	if label == model.MetricNameLabel {
+		result.LabelValues, excluded = filter.Exclude(result.LabelValues, sc.ExcludedMetrics())
+	}
+	// Prometheus returns sorted values; sorting again keeps pagination
+	// deterministic behind proxies that merge results from several backends.
+	slices.Sort(result.LabelValues)
+
+	structured := newLabelValuesOutput(label, result, excluded)
 	var responseText string
 	if len(result.LabelValues) == 0 {
 		responseText = fmt.Sprintf("No values found for label '%s'", label)
+	} else if groupByPrefix {
+		responseText = fmt.Sprintf("Found %d values for label '%s', grouped by prefix:\n", len(result.LabelValues), label)
+		responseText += format.RenderPrefixGroups(format.GroupByPrefix(result.LabelValues, minGroupSize))
 	} else {
+		var metadata map[string][]v1.Metadata
+		if withTypes {
+			if metadata, err = client.ListMetricMetadata(ctx); err != nil {
+				sc.Logger().Warn("Failed to list metric metadata, inferring all types", "error", err)
+			}
+		}
+		page, start, next := paginate(result.LabelValues, after, pageSize)
+		structured.Values, structured.NextCursor, structured.Truncated = page, next, false
 		responseText = fmt.Sprintf("Found %d values for label '%s':\n", len(result.LabelValues), label)
-		for i, value := range result.LabelValues {
-			responseText += fmt.Sprintf("%d. %s\n", i+1, value)
-			// Limit output for very long lists
-			if i >= 99 {
-				responseText += fmt.Sprintf("... and %d more values\n", len(result.LabelValues)-100)
-				break
+		if len(page) == 0 {
+			responseText += "No more values after the given cursor\n"
+		}
+		for i, value := range page {
+			if withTypes {
+				responseText += fmt.Sprintf("%d. %s (%s)\n", start+i+1, value, metricTypeLabel(value, metadata))
+			} else {
+				responseText += fmt.Sprintf("%d. %s\n", start+i+1, value)
 			}
 		}
+		if next != "" {
+			responseText += fmt.Sprintf("... and %d more values; pass cursor %q for the next page\n", len(result.LabelValues)-start-len(page), next)
+		}
 	}
 
+	if excluded > 0 {
+		responseText += fmt.Sprintf("\n%d metrics hidden by PROMETHEUS_EXCLUDED_METRICS\n", excluded)
+	}
 	if len(result.Warnings) > 0 {
 		responseText += fmt.Sprintf("\nWarnings: %v", result.Warnings)
 	}
 
-	return &mcp.CallToolResult{
+	return withStructuredContent(&mcp.CallToolResult{
 		Content: []mcp.Content{
 			mcp.TextContent{
 				Type: contentTypeText,
 				Text: responseText,
 			},
 		},
-	}, nil
+	}, structured), nil
 }
 
 // handleFindSeries handles the find_series tool
@@ -896,7 +2224,58 @@ func handleFindSeries(ctx context.Context, request mcp.CallToolRequest, client *
 		Limit:     getStringParam(params, "limit"),
 	}
 
-	sc.Logger().Debug("Finding series", "matches", matches, "options", options)
+	histogramLabel := getStringParam(params, "histogram_label")
+	topN := defaultSeriesHistogramTopN
+	if v := getStringParam(params, "top_n"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{
+					mcp.TextContent{
+						Type: contentTypeText,
+						Text: fmt.Sprintf("Error: top_n must be a positive integer (got %q)", v),
+					},
+				},
+			}, nil
+		}
+		topN = n
+	}
+
+	var activityStart, activityEnd time.Time
+	showActivity := getStringParam(params, "show_activity_range") == "true"
+	if showActivity {
+		if options.StartTime == "" || options.EndTime == "" {
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{
+					mcp.TextContent{
+						Type: contentTypeText,
+						Text: "Error: show_activity_range requires start_time and end_time",
+					},
+				},
+			}, nil
+		}
+		var startErr, endErr error
+		activityStart, startErr = parseTimeParam(options.StartTime)
+		activityEnd, endErr = parseTimeParam(options.EndTime)
+		if err := errors.Join(startErr, endErr); err != nil || !activityEnd.After(activityStart) {
+			if err == nil {
+				err = errors.New("end_time must be after start_time")
+			}
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{
+					mcp.TextContent{
+						Type: contentTypeText,
+						Text: fmt.Sprintf("Error: %v", err),
+					},
+				},
+			}, nil
+		}
+	}
+
+	sc.Logger().Debug("Finding series", "matches", matches, "options", options, "histogram_label", histogramLabel)
 
 	result, err := client.FindSeries(ctx, matches, options)
 	if err != nil {
@@ -912,9 +2291,41 @@ func handleFindSeries(ctx context.Context, request mcp.CallToolRequest, client *
 		}, nil
 	}
 
+	if filter := getStringParam(params, "namespace_filter"); filter != "" {
+		result.Series = filterByNamespace(result.Series, seriesNamespace, filter)
+	}
+
 	var responseText string
 	if len(result.Series) == 0 {
 		responseText = "No series found matching the given criteria"
+	} else if histogramLabel != "" {
+		counts := make(map[string]int)
+		for _, series := range result.Series {
+			counts[series[histogramLabel]]++
+		}
+		values := slices.Collect(maps.Keys(counts))
+		sort.Slice(values, func(i, j int) bool {
+			if counts[values[i]] != counts[values[j]] {
+				return counts[values[i]] > counts[values[j]]
+			}
+			return values[i] < values[j]
+		})
+
+		responseText = fmt.Sprintf("Found %d series with %d distinct values of %q:\n", len(result.Series), len(values), histogramLabel)
+		for i, value := range values {
+			if i >= topN {
+				responseText += fmt.Sprintf("... and %d more values\n", len(values)-topN)
+				break
+			}
+			if value == "" {
+				value = fmt.Sprintf("(no %s label)", histogramLabel)
+			}
+			responseText += fmt.Sprintf("%s: %d series\n", value, counts[values[i]])
+		}
+	} else if showActivity {
+		responseText = formatSeriesActivity(ctx, client, sc, result.Series, activityStart, activityEnd)
+	} else if getStringParam(params, "group_by_namespace") == "true" {
+		responseText = formatSeriesByNamespace(result.Series, 50)
 	} else {
 		responseText = fmt.Sprintf("Found %d series:\n", len(result.Series))
 		for i, series := range result.Series {
@@ -931,14 +2342,14 @@ func handleFindSeries(ctx context.Context, request mcp.CallToolRequest, client *
 		responseText += fmt.Sprintf("\nWarnings: %v", result.Warnings)
 	}
 
-	return &mcp.CallToolResult{
+	return withStructuredContent(&mcp.CallToolResult{
 		Content: []mcp.Content{
 			mcp.TextContent{
 				Type: contentTypeText,
 				Text: responseText,
 			},
 		},
-	}, nil
+	}, newSeriesListOutput(result)), nil
 }
 
 // handleGetRules handles the get_rules tool
@@ -987,14 +2398,14 @@ func handleGetAlerts(ctx context.Context, request mcp.CallToolRequest, client *C
 		}, nil
 	}
 
-	return &mcp.CallToolResult{
+	return withStructuredContent(&mcp.CallToolResult{
 		Content: []mcp.Content{
 			mcp.TextContent{
 				Type: contentTypeText,
 				Text: fmt.Sprintf("Active Alerts:\n%+v", alerts),
 			},
 		},
-	}, nil
+	}, newAlertsOutput(alerts)), nil
 }
 
 // handleGetAlertManagers handles the get_alertmanagers tool
@@ -1027,7 +2438,21 @@ func handleGetAlertManagers(ctx context.Context, request mcp.CallToolRequest, cl
 
 // handleGetConfig handles the get_config tool
 func handleGetConfig(ctx context.Context, request mcp.CallToolRequest, client *Client, sc *server.ServerContext) (*mcp.CallToolResult, error) {
-	sc.Logger().Debug("Getting config")
+	params := extractParams(request)
+	raw := getStringParam(params, "raw") == "true"
+	if raw && !sc.RawConfigAllowed() {
+		return &mcp.CallToolResult{
+			IsError: true,
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: contentTypeText,
+					Text: "Error: raw configuration output is disabled; the server must be started with --allow-raw-config",
+				},
+			},
+		}, nil
+	}
+
+	sc.Logger().Debug("Getting config", "raw", raw)
 
 	config, err := client.GetConfig(ctx)
 	if err != nil {
@@ -1043,19 +2468,132 @@ func handleGetConfig(ctx context.Context, request mcp.CallToolRequest, client *C
 		}, nil
 	}
 
+	formatted, err := formatConfigYAML(config.YAML, raw)
+	if err != nil {
+		sc.Logger().Error("Failed to format config", "error", err)
+		return &mcp.CallToolResult{
+			IsError: true,
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: contentTypeText,
+					Text: fmt.Sprintf("Error formatting config: %v", err),
+				},
+			},
+		}, nil
+	}
+
 	return &mcp.CallToolResult{
 		Content: []mcp.Content{
 			mcp.TextContent{
 				Type: contentTypeText,
-				Text: fmt.Sprintf("Prometheus Configuration:\n%+v", config),
+				Text: "Prometheus Configuration:\n" + formatted,
 			},
 		},
 	}, nil
 }
 
+// prometheusDefaultFlags holds the values /api/v1/status/flags reports for
+// an unconfigured Prometheus 3.x server. Flags missing from this map are not
+// compared by show_changed_only.
+var prometheusDefaultFlags = map[string]string{
+	"agent": "false",
+	"alertmanager.drain-notification-queue-on-shutdown": "true",
+	"alertmanager.notification-batch-size":              "256",
+	"alertmanager.notification-queue-capacity":          "10000",
+	"auto-gomaxprocs":                           "true",
+	"auto-gomemlimit":                           "true",
+	"auto-gomemlimit.ratio":                     "0.9",
+	"config.auto-reload-interval":               "30s",
+	"config.file":                               "prometheus.yml",
+	"enable-feature":                            "",
+	"log.format":                                "logfmt",
+	"log.level":                                 "info",
+	"query.lookback-delta":                      "5m",
+	"query.max-concurrency":                     "20",
+	"query.max-samples":                         "50000000",
+	"query.timeout":                             "2m",
+	"rules.alert.for-grace-period":              "10m",
+	"rules.alert.for-outage-tolerance":          "1h",
+	"rules.alert.resend-delay":                  "1m",
+	"rules.max-concurrent-evals":                "4",
+	"scrape.adjust-timestamps":                  "true",
+	"scrape.discovery-reload-interval":          "5s",
+	"scrape.timestamp-tolerance":                "2ms",
+	"storage.agent.no-lockfile":                 "false",
+	"storage.agent.path":                        "data-agent/",
+	"storage.agent.retention.max-time":          "0s",
+	"storage.agent.retention.min-time":          "0s",
+	"storage.agent.wal-compression":             "true",
+	"storage.agent.wal-segment-size":            "0B",
+	"storage.agent.wal-truncate-frequency":      "0s",
+	"storage.remote.flush-deadline":             "1m",
+	"storage.remote.read-concurrent-limit":      "10",
+	"storage.remote.read-max-bytes-in-frame":    "1048576",
+	"storage.remote.read-sample-limit":          "50000000",
+	"storage.tsdb.allow-overlapping-compaction": "true",
+	"storage.tsdb.head-chunks-write-queue-size": "0",
+	"storage.tsdb.no-lockfile":                  "false",
+	"storage.tsdb.path":                         "data/",
+	"storage.tsdb.retention":                    "0s",
+	"storage.tsdb.retention.size":               "0B",
+	"storage.tsdb.retention.time":               "0s",
+	"storage.tsdb.samples-per-chunk":            "120",
+	"storage.tsdb.wal-compression":              "true",
+	"storage.tsdb.wal-compression-type":         "snappy",
+	"storage.tsdb.wal-segment-size":             "0B",
+	"web.config.file":                           "",
+	"web.console.libraries":                     "console_libraries",
+	"web.console.templates":                     "consoles",
+	"web.cors.origin":                           ".*",
+	"web.enable-admin-api":                      "false",
+	"web.enable-lifecycle":                      "false",
+	"web.enable-otlp-receiver":                  "false",
+	"web.enable-remote-write-receiver":          "false",
+	"web.external-url":                          "",
+	"web.listen-address":                        "0.0.0.0:9090",
+	"web.max-connections":                       "512",
+	"web.max-notifications-subscribers":         "16",
+	"web.page-title":                            "Prometheus Time Series Collection and Processing Server",
+	"web.read-timeout":                          "5m",
+	"web.route-prefix":                          "",
+	"web.user-assets":                           "",
+}
+
+// filterChangedFlags returns the flags whose value differs from the one in
+// defaults. Flags without a known default are left out.
+func filterChangedFlags(flags map[string]string, defaults map[string]string) map[string]string {
+	changed := make(map[string]string)
+	for name, value := range flags {
+		if def, ok := defaults[name]; ok && def != value {
+			changed[name] = value
+		}
+	}
+	return changed
+}
+
 // handleGetFlags handles the get_flags tool
 func handleGetFlags(ctx context.Context, request mcp.CallToolRequest, client *Client, sc *server.ServerContext) (*mcp.CallToolResult, error) {
-	sc.Logger().Debug("Getting flags")
+	params := extractParams(request)
+	showChangedOnly := getStringParam(params, "show_changed_only") == "true"
+
+	var flagFilter *regexp.Regexp
+	if pattern := getStringParam(params, "flag_filter"); pattern != "" {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{
+					mcp.TextContent{
+						Type: contentTypeText,
+						Text: fmt.Sprintf("Error: invalid flag_filter regex: %v", err),
+					},
+				},
+			}, nil
+		}
+		flagFilter = re
+	}
+
+	sc.Logger().Debug("Getting flags", "show_changed_only", showChangedOnly, "flag_filter", flagFilter)
 
 	flags, err := client.GetFlags(ctx)
 	if err != nil {
@@ -1071,19 +2609,64 @@ func handleGetFlags(ctx context.Context, request mcp.CallToolRequest, client *Cl
 		}, nil
 	}
 
-	return &mcp.CallToolResult{
+	if flagFilter != nil {
+		maps.DeleteFunc(flags, func(name, _ string) bool { return !flagFilter.MatchString(name) })
+	}
+
+	if !showChangedOnly {
+		return withStructuredContent(&mcp.CallToolResult{
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: contentTypeText,
+					Text: fmt.Sprintf("Prometheus Runtime Flags:\n%+v", flags),
+				},
+			},
+		}, newFlagsOutput(flags, nil)), nil
+	}
+
+	changed := filterChangedFlags(flags, prometheusDefaultFlags)
+	return withStructuredContent(&mcp.CallToolResult{
 		Content: []mcp.Content{
 			mcp.TextContent{
 				Type: contentTypeText,
-				Text: fmt.Sprintf("Prometheus Runtime Flags:\n%+v", flags),
+				Text: formatChangedFlags(flags, changed),
 			},
 		},
-	}, nil
+	}, newFlagsOutput(flags, changed)), nil
+}
+
+// formatChangedFlags renders changed as a "flag_name | default | current"
+// table, noting how many of flags had no known default to compare against.
+func formatChangedFlags(flags, changed map[string]string) string {
+	var b strings.Builder
+	if len(changed) == 0 {
+		b.WriteString("All Prometheus runtime flags with a known default are set to their default value.\n")
+	} else {
+		fmt.Fprintf(&b, "Prometheus runtime flags changed from their defaults (%d):\n\n", len(changed))
+		b.WriteString("flag_name | default | current\n")
+		for _, name := range slices.Sorted(maps.Keys(changed)) {
+			fmt.Fprintf(&b, "%s | %s | %s\n", name, valueOrNone(prometheusDefaultFlags[name]), valueOrNone(changed[name]))
+		}
+	}
+
+	unknown := 0
+	for name := range flags {
+		if _, ok := prometheusDefaultFlags[name]; !ok {
+			unknown++
+		}
+	}
+	if unknown > 0 {
+		fmt.Fprintf(&b, "\n%d flags have no known default and were not compared.\n", unknown)
+	}
+	return b.String()
 }
 
 // handleGetBuildInfo handles the get_build_info tool
 func handleGetBuildInfo(ctx context.Context, request mcp.CallToolRequest, client *Client, sc *server.ServerContext) (*mcp.CallToolResult, error) {
-	sc.Logger().Debug("Getting build info")
+	params := extractParams(request)
+	outputFormat := getStringParam(params, "format")
+
+	sc.Logger().Debug("Getting build info", "format", outputFormat)
 
 	buildInfo, err := client.GetBuildInfo(ctx)
 	if err != nil {
@@ -1099,19 +2682,46 @@ func handleGetBuildInfo(ctx context.Context, request mcp.CallToolRequest, client
 		}, nil
 	}
 
-	return &mcp.CallToolResult{
+	// Uptime is best-effort: Mimir and some proxies do not serve runtimeinfo.
+	var startTime time.Time
+	if runtimeInfo, err := client.GetRuntimeInfo(ctx); err == nil {
+		startTime = runtimeInfo.StartTime
+	} else {
+		sc.Logger().Debug("Runtime info unavailable, omitting uptime", "error", err)
+	}
+
+	text := formatBuildInfo(buildInfo, startTime, time.Now())
+	if outputFormat == "json" {
+		text, err = formatInfoJSON(buildInfo, startTime, time.Now())
+		if err != nil {
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{
+					mcp.TextContent{
+						Type: contentTypeText,
+						Text: fmt.Sprintf("Error formatting build info: %v", err),
+					},
+				},
+			}, nil
+		}
+	}
+
+	return withStructuredContent(&mcp.CallToolResult{
 		Content: []mcp.Content{
 			mcp.TextContent{
 				Type: contentTypeText,
-				Text: fmt.Sprintf("Prometheus Build Information:\n%+v", buildInfo),
+				Text: text,
 			},
 		},
-	}, nil
+	}, newBuildInfoOutput(buildInfo, startTime)), nil
 }
 
 // handleGetRuntimeInfo handles the get_runtime_info tool
 func handleGetRuntimeInfo(ctx context.Context, request mcp.CallToolRequest, client *Client, sc *server.ServerContext) (*mcp.CallToolResult, error) {
-	sc.Logger().Debug("Getting runtime info")
+	params := extractParams(request)
+	outputFormat := getStringParam(params, "format")
+
+	sc.Logger().Debug("Getting runtime info", "format", outputFormat)
 
 	runtimeInfo, err := client.GetRuntimeInfo(ctx)
 	if err != nil {
@@ -1127,102 +2737,67 @@ func handleGetRuntimeInfo(ctx context.Context, request mcp.CallToolRequest, clie
 		}, nil
 	}
 
-	return &mcp.CallToolResult{
-		Content: []mcp.Content{
-			mcp.TextContent{
-				Type: contentTypeText,
-				Text: fmt.Sprintf("Prometheus Runtime Information:\n%+v", runtimeInfo),
-			},
-		},
-	}, nil
-}
-
-// handleGetTSDBStats handles the get_tsdb_stats tool
-func handleGetTSDBStats(ctx context.Context, request mcp.CallToolRequest, client *Client, sc *server.ServerContext) (*mcp.CallToolResult, error) {
-	params := extractParams(request)
-
-	options := TSDBOptions{
-		Limit: getStringParam(params, "limit"),
-	}
-	sc.Logger().Debug("Getting TSDB stats", "options", options)
-
-	tsdbStats, err := client.GetTSDBStats(ctx, options)
-	if err != nil {
-		sc.Logger().Error("Failed to get TSDB stats", "error", err)
+	if outputFormat == "json" {
+		text, err := formatInfoJSON(runtimeInfo, runtimeInfo.StartTime, time.Now())
+		if err != nil {
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{
+					mcp.TextContent{
+						Type: contentTypeText,
+						Text: fmt.Sprintf("Error formatting runtime info: %v", err),
+					},
+				},
+			}, nil
+		}
 		return &mcp.CallToolResult{
-			IsError: true,
 			Content: []mcp.Content{
 				mcp.TextContent{
 					Type: contentTypeText,
-					Text: fmt.Sprintf("Error getting TSDB stats: %v", err),
+					Text: text,
 				},
 			},
 		}, nil
 	}
 
+	// The head chunk count lives in the TSDB status; it is best-effort.
+	chunkCount := -1
+	if stats, err := client.GetTSDBStats(ctx, TSDBOptions{}); err == nil {
+		if tsdb, ok := stats.(v1.TSDBResult); ok {
+			chunkCount = tsdb.HeadStats.ChunkCount
+		}
+	} else {
+		sc.Logger().Debug("TSDB stats unavailable, omitting chunk count", "error", err)
+	}
+
 	return &mcp.CallToolResult{
 		Content: []mcp.Content{
 			mcp.TextContent{
 				Type: contentTypeText,
-				Text: fmt.Sprintf("TSDB Statistics:\n%+v", tsdbStats),
+				Text: formatRuntimeInfo(runtimeInfo, chunkCount, time.Now()),
 			},
 		},
 	}, nil
 }
 
-// handleQueryExemplars handles the query_exemplars tool
-func handleQueryExemplars(ctx context.Context, request mcp.CallToolRequest, client *Client, sc *server.ServerContext) (*mcp.CallToolResult, error) {
+// handleGetTSDBStats handles the get_tsdb_stats tool
+func handleGetTSDBStats(ctx context.Context, request mcp.CallToolRequest, client *Client, sc *server.ServerContext) (*mcp.CallToolResult, error) {
 	params := extractParams(request)
 
-	query, ok := params["query"].(string)
-	if !ok || query == "" {
-		return &mcp.CallToolResult{
-			IsError: true,
-			Content: []mcp.Content{
-				mcp.TextContent{
-					Type: contentTypeText,
-					Text: errQueryParameterRequired,
-				},
-			},
-		}, nil
-	}
-
-	start, ok := params["start"].(string)
-	if !ok || start == "" {
-		return &mcp.CallToolResult{
-			IsError: true,
-			Content: []mcp.Content{
-				mcp.TextContent{
-					Type: contentTypeText,
-					Text: "Error: start parameter is required and must be a string",
-				},
-			},
-		}, nil
-	}
-
-	end, ok := params["end"].(string)
-	if !ok || end == "" {
-		return &mcp.CallToolResult{
-			IsError: true,
-			Content: []mcp.Content{
-				mcp.TextContent{
-					Type: contentTypeText,
-					Text: "Error: end parameter is required and must be a string",
-				},
-			},
-		}, nil
+	options := TSDBOptions{
+		Limit: getStringParam(params, "limit"),
 	}
-	sc.Logger().Debug("Querying exemplars", "query", query, "start", start, "end", end)
+	sc.Logger().Debug("Getting TSDB stats", "options", options)
 
-	exemplars, err := client.QueryExemplars(ctx, query, start, end)
+	tsdbStats, err := client.GetTSDBStats(ctx, options)
 	if err != nil {
-		sc.Logger().Error("Failed to query exemplars", "error", err)
+		sc.Logger().Error("Failed to get TSDB stats", "error", err)
 		return &mcp.CallToolResult{
 			IsError: true,
 			Content: []mcp.Content{
 				mcp.TextContent{
 					Type: contentTypeText,
-					Text: fmt.Sprintf("Error querying exemplars: %v", err),
+					Text: fmt.Sprintf("Error getting TSDB stats: %v", err),
 				},
 			},
 		}, nil
@@ -1232,7 +2807,7 @@ func handleQueryExemplars(ctx context.Context, request mcp.CallToolRequest, clie
 		Content: []mcp.Content{
 			mcp.TextContent{
 				Type: contentTypeText,
-				Text: fmt.Sprintf("Exemplars for query '%s':\n%+v", query, exemplars),
+				Text: fmt.Sprintf("TSDB Statistics:\n%+v", tsdbStats),
 			},
 		},
 	}, nil
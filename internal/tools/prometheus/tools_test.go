@@ -8,14 +8,20 @@ import (
 	"log/slog"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
 	"strings"
 	"testing"
+	"time"
 	"unicode/utf8"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	mcpserver "github.com/mark3labs/mcp-go/server"
+	"github.com/prometheus/common/model"
 
 	"github.com/giantswarm/mcp-prometheus/internal/server"
+	"github.com/giantswarm/mcp-prometheus/pkg/plugin"
 )
 
 const (
@@ -114,6 +120,65 @@ func TestRegisterPrometheusToolsWithMiddleware(t *testing.T) {
 	}
 }
 
+// TestToolPlugin registers a single test_echo tool.
+type TestToolPlugin struct {
+	err error
+}
+
+func (p TestToolPlugin) Name() string { return "test" }
+
+func (p TestToolPlugin) Register(s *mcpserver.MCPServer, _ plugin.Host) error {
+	if p.err != nil {
+		return p.err
+	}
+	s.AddTool(mcp.NewTool("test_echo", mcp.WithDescription("Echo the message")), func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText(req.GetString("message", "")), nil
+	})
+	return nil
+}
+
+func TestRegisterPrometheusToolsWithPlugin(t *testing.T) {
+	tests := []struct {
+		name    string
+		plugin  TestToolPlugin
+		wantErr bool
+	}{
+		{name: "registers plugin tools", plugin: TestToolPlugin{}},
+		{name: "returns plugin error", plugin: TestToolPlugin{err: fmt.Errorf("boom")}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sc, err := server.NewServerContext(context.Background(),
+				server.WithPrometheusConfig(server.PrometheusConfig{URL: "http://localhost:9090"}),
+				server.WithSlogLogger(discardLogger()),
+				server.WithToolPlugin(tt.plugin),
+			)
+			if err != nil {
+				t.Fatalf("Failed to create server context: %v", err)
+			}
+			defer func() { _ = sc.Shutdown() }()
+
+			s := mcpserver.NewMCPServer("test", "1.0.0", mcpserver.WithToolCapabilities(true))
+			err = RegisterPrometheusTools(s, sc)
+			if tt.wantErr {
+				if err == nil || !strings.Contains(err.Error(), "plugin test") {
+					t.Fatalf("expected plugin registration error, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("RegisterPrometheusTools: %v", err)
+			}
+			if s.GetTool("test_echo") == nil {
+				t.Error("expected test_echo tool to be registered")
+			}
+			if s.GetTool(toolExecuteQuery) == nil {
+				t.Error("expected built-in tools to stay registered")
+			}
+		})
+	}
+}
+
 func TestClient(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -403,6 +468,470 @@ func TestHandleGetMetricMetadata(t *testing.T) {
 	}
 }
 
+func TestFilterChangedFlags(t *testing.T) {
+	defaults := map[string]string{"log.level": "info", "query.timeout": "2m", "web.external-url": ""}
+	flags := map[string]string{
+		"log.level":        "debug",
+		"query.timeout":    "2m",
+		"web.external-url": "",
+		"unknown.flag":     "x",
+	}
+
+	changed := filterChangedFlags(flags, defaults)
+	if len(changed) != 1 || changed["log.level"] != "debug" {
+		t.Errorf("filterChangedFlags() = %v, want only log.level=debug", changed)
+	}
+}
+
+func TestHandleGetFlags(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/status/flags" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			respKeyStatus: respValSuccess,
+			respKeyData: map[string]string{
+				"log.level":                   "debug",
+				"query.timeout":               "2m",
+				"storage.tsdb.retention.time": "30d",
+				"storage.tsdb.path":           "data/",
+				"some.future-flag":            "on",
+			},
+		})
+	}))
+	defer mockServer.Close()
+
+	ctx := context.Background()
+	sc, err := server.NewServerContext(ctx,
+		server.WithPrometheusConfig(server.PrometheusConfig{URL: mockServer.URL}),
+		server.WithSlogLogger(discardLogger()),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create server context: %v", err)
+	}
+	defer func() { _ = sc.Shutdown() }()
+
+	client, err := NewClient(sc.PrometheusConfig(), sc.Logger())
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		args    map[string]any
+		want    []string
+		notWant []string
+		isError bool
+	}{
+		{
+			name: "all flags",
+			args: map[string]any{},
+			want: []string{"log.level:debug", "query.timeout:2m", "some.future-flag:on"},
+		},
+		{
+			name:    "changed only",
+			args:    map[string]any{"show_changed_only": "true"},
+			want:    []string{"flag_name | default | current", "log.level | info | debug", "storage.tsdb.retention.time | 0s | 30d", "1 flags have no known default"},
+			notWant: []string{"query.timeout", "storage.tsdb.path"},
+		},
+		{
+			name:    "changed only with filter",
+			args:    map[string]any{"show_changed_only": "true", "flag_filter": `^storage\.`},
+			want:    []string{"storage.tsdb.retention.time | 0s | 30d"},
+			notWant: []string{"log.level", "no known default"},
+		},
+		{
+			name:    "filter without changed only",
+			args:    map[string]any{"flag_filter": "^log"},
+			want:    []string{"log.level:debug"},
+			notWant: []string{"query.timeout"},
+		},
+		{
+			name:    "invalid filter",
+			args:    map[string]any{"flag_filter": "("},
+			want:    []string{"invalid flag_filter regex"},
+			isError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := mcp.CallToolRequest{Params: mcp.CallToolParams{Name: "get_flags", Arguments: tt.args}}
+			result, err := handleGetFlags(ctx, request, client, sc)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.IsError != tt.isError {
+				t.Fatalf("IsError = %v, want %v: %v", result.IsError, tt.isError, result.Content)
+			}
+			text := result.Content[0].(mcp.TextContent).Text
+			for _, want := range tt.want {
+				if !strings.Contains(text, want) {
+					t.Errorf("expected output to contain %q, got:\n%s", want, text)
+				}
+			}
+			for _, notWant := range tt.notWant {
+				if strings.Contains(text, notWant) {
+					t.Errorf("did not expect %q in output:\n%s", notWant, text)
+				}
+			}
+		})
+	}
+}
+
+func TestHandleFindSeriesHistogramLabel(t *testing.T) {
+	var series []map[string]string
+	for job, n := range map[string]int{"api-server": 5, "batch-processor": 2, "cron": 2} {
+		for range n {
+			series = append(series, map[string]string{"__name__": "http_requests_total", "job": job})
+		}
+	}
+	series = append(series, map[string]string{"__name__": "http_requests_total"})
+
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/series" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{respKeyStatus: respValSuccess, respKeyData: series})
+	}))
+	defer mockServer.Close()
+
+	ctx := context.Background()
+	sc, err := server.NewServerContext(ctx,
+		server.WithPrometheusConfig(server.PrometheusConfig{URL: mockServer.URL}),
+		server.WithSlogLogger(discardLogger()),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create server context: %v", err)
+	}
+	defer func() { _ = sc.Shutdown() }()
+
+	client, err := NewClient(sc.PrometheusConfig(), sc.Logger())
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+
+	run := func(args map[string]any) *mcp.CallToolResult {
+		t.Helper()
+		args["matches"] = []any{`{__name__="http_requests_total"}`}
+		request := mcp.CallToolRequest{Params: mcp.CallToolParams{Name: "find_series", Arguments: args}}
+		result, err := handleFindSeries(ctx, request, client, sc)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return result
+	}
+
+	text := run(map[string]any{"histogram_label": "job"}).Content[0].(mcp.TextContent).Text
+	want := `Found 10 series with 4 distinct values of "job":
+api-server: 5 series
+batch-processor: 2 series
+cron: 2 series
+(no job label): 1 series
+`
+	if text != want {
+		t.Errorf("got:\n%s\nwant:\n%s", text, want)
+	}
+
+	text = run(map[string]any{"histogram_label": "job", "top_n": "1"}).Content[0].(mcp.TextContent).Text
+	if !strings.Contains(text, "api-server: 5 series\n... and 3 more values") {
+		t.Errorf("expected top_n to truncate the histogram, got:\n%s", text)
+	}
+
+	if result := run(map[string]any{"histogram_label": "job", "top_n": "0"}); !result.IsError {
+		t.Error("expected an error for top_n=0")
+	}
+}
+
+func TestRuleTimeline(t *testing.T) {
+	start := model.TimeFromUnix(1704067200)
+	// active at steps 4-11 and 16-21 of 22
+	var samples []model.SamplePair
+	for _, i := range []int{4, 5, 6, 7, 8, 9, 10, 11, 16, 17, 18, 19, 20, 21} {
+		samples = append(samples, model.SamplePair{Timestamp: start.Add(time.Duration(i) * time.Minute), Value: 1})
+	}
+
+	tests := []struct {
+		forDuration time.Duration
+		want        string
+	}{
+		{forDuration: 4 * time.Minute, want: "....PPPPFFFF....PPPPFF"},
+		{forDuration: 0, want: "....FFFFFFFF....FFFFFF"},
+		{forDuration: 10 * time.Minute, want: "....PPPPPPPP....PPPPPP"},
+	}
+	for _, tt := range tests {
+		if got := ruleTimeline(samples, start, time.Minute, 22, tt.forDuration); got != tt.want {
+			t.Errorf("ruleTimeline(for=%s) = %q, want %q", tt.forDuration, got, tt.want)
+		}
+	}
+}
+
+func TestHandleEvaluateRuleTimeline(t *testing.T) {
+	const start = 1704067200
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/query_range" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		values := []any{}
+		for i := 2; i < 8; i++ {
+			values = append(values, []any{start + i*60, "1"})
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			respKeyStatus: respValSuccess,
+			respKeyData: map[string]any{
+				"resultType": "matrix",
+				"result": []any{
+					map[string]any{"metric": map[string]string{"job": "api-server"}, "values": values},
+					map[string]any{"metric": map[string]string{"job": "batch"}, "values": []any{[]any{start + 9*60, "1"}}},
+				},
+			},
+		})
+	}))
+	defer mockServer.Close()
+
+	ctx := context.Background()
+	sc, err := server.NewServerContext(ctx,
+		server.WithPrometheusConfig(server.PrometheusConfig{URL: mockServer.URL}),
+		server.WithSlogLogger(discardLogger()),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create server context: %v", err)
+	}
+	defer func() { _ = sc.Shutdown() }()
+
+	client, err := NewClient(sc.PrometheusConfig(), sc.Logger())
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+
+	request := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Name: "evaluate_rule_timeline",
+			Arguments: map[string]any{
+				"rule_expr":    "rate(errors_total[5m]) > 0.1",
+				"for_duration": "3m",
+				"start":        strconv.Itoa(start),
+				"end":          strconv.Itoa(start + 9*60),
+				"step":         "1m",
+			},
+		},
+	}
+	result, err := handleEvaluateRuleTimeline(ctx, request, client, sc)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.IsError {
+		t.Fatalf("expected success, got error: %v", result.Content)
+	}
+	text := result.Content[0].(mcp.TextContent).Text
+
+	for _, want := range []string{
+		"10 evaluations every 1m from 2024-01-01T00:00:00.000Z to 2024-01-01T00:09:00.000Z",
+		`{job="api-server"}: ..PPPFFF..`,
+		`{job="batch"}     : .........P`,
+		"1 of 2 series fired during the window.",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("expected output to contain %q, got:\n%s", want, text)
+		}
+	}
+
+	request.Params.Arguments = map[string]any{"rule_expr": "up == 0", "start": "1704067200", "end": "1704067100", "step": "1m"}
+	if result, _ := handleEvaluateRuleTimeline(ctx, request, client, sc); !result.IsError {
+		t.Error("expected an error when end is before start")
+	}
+}
+
+func TestHandleListLabelValuesGroupByPrefix(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v1/label/__name__/values" {
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				respKeyStatus: respValSuccess,
+				respKeyData: []string{
+					"http_request_duration_seconds",
+					"http_requests_total",
+					"http_response_size_bytes",
+					"up",
+				},
+			})
+		} else {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer mockServer.Close()
+
+	ctx := context.Background()
+	sc, err := server.NewServerContext(ctx,
+		server.WithPrometheusConfig(server.PrometheusConfig{URL: mockServer.URL}),
+		server.WithSlogLogger(discardLogger()),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create server context: %v", err)
+	}
+	defer func() { _ = sc.Shutdown() }()
+
+	client, err := NewClient(sc.PrometheusConfig(), sc.Logger())
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+
+	request := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Name:      "list_label_values",
+			Arguments: map[string]any{"label": "__name__", "group_by_prefix": "true"},
+		},
+	}
+	result, err := handleListLabelValues(ctx, request, client, sc)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if result.IsError {
+		t.Fatalf("Expected success, got error: %v", result.Content)
+	}
+	text := result.Content[0].(mcp.TextContent).Text
+	if !strings.Contains(text, "http_ (3 metrics):\n  http_request_duration_seconds\n") || !strings.HasSuffix(text, "\nup\n") {
+		t.Errorf("unexpected grouped output:\n%s", text)
+	}
+
+	request.Params.Arguments = map[string]any{"label": "__name__", "group_by_prefix": "true", "min_group_size": "0"}
+	result, err = handleListLabelValues(ctx, request, client, sc)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if !result.IsError {
+		t.Error("Expected error for min_group_size=0")
+	}
+}
+
+func TestHandleListLabelValuesWithTypes(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/label/__name__/values":
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				respKeyStatus: respValSuccess,
+				respKeyData:   []string{"http_requests_total", "node_load1", "process_resident_memory_bytes", "up"},
+			})
+		case "/api/v1/metadata":
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				respKeyStatus: respValSuccess,
+				respKeyData: map[string]any{
+					"http_requests": []any{map[string]string{"type": "counter", "help": "Requests.", "unit": ""}},
+					"node_load1":    []any{map[string]string{"type": "gauge", "help": "1m load average.", "unit": ""}},
+				},
+			})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer mockServer.Close()
+
+	ctx := context.Background()
+	sc, err := server.NewServerContext(ctx,
+		server.WithPrometheusConfig(server.PrometheusConfig{URL: mockServer.URL}),
+		server.WithSlogLogger(discardLogger()),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create server context: %v", err)
+	}
+	defer func() { _ = sc.Shutdown() }()
+
+	client, err := NewClient(sc.PrometheusConfig(), sc.Logger())
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+
+	request := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Name:      "list_label_values",
+			Arguments: map[string]any{"label": "__name__", "with_types": "true"},
+		},
+	}
+	result, err := handleListLabelValues(ctx, request, client, sc)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if result.IsError {
+		t.Fatalf("Expected success, got error: %v", result.Content)
+	}
+	text := result.Content[0].(mcp.TextContent).Text
+	for _, want := range []string{
+		"1. http_requests_total (counter)\n",
+		"2. node_load1 (gauge)\n",
+		"3. process_resident_memory_bytes (gauge, inferred)\n",
+		"4. up (unknown, inferred)\n",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("output missing %q:\n%s", want, text)
+		}
+	}
+
+	request.Params.Arguments = map[string]any{"label": "job", "with_types": "true"}
+	result, err = handleListLabelValues(ctx, request, client, sc)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if !result.IsError {
+		t.Error("Expected error for with_types on a label other than __name__")
+	}
+}
+
+func TestHandleListLabelValuesExcludedMetrics(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			respKeyStatus: respValSuccess,
+			respKeyData:   []string{"go_goroutines", "http_requests_total", "scrape_duration_seconds", "up"},
+		})
+	}))
+	defer mockServer.Close()
+
+	ctx := context.Background()
+	sc, err := server.NewServerContext(ctx,
+		server.WithPrometheusConfig(server.PrometheusConfig{URL: mockServer.URL}),
+		server.WithSlogLogger(discardLogger()),
+		server.WithExcludedMetrics([]string{"go_*", "*_seconds", "up"}),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create server context: %v", err)
+	}
+	defer func() { _ = sc.Shutdown() }()
+
+	client, err := NewClient(sc.PrometheusConfig(), sc.Logger())
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+
+	call := func(label string) string {
+		t.Helper()
+		request := mcp.CallToolRequest{
+			Params: mcp.CallToolParams{
+				Name:      "list_label_values",
+				Arguments: map[string]any{"label": label},
+			},
+		}
+		result, err := handleListLabelValues(ctx, request, client, sc)
+		if err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+		if result.IsError {
+			t.Fatalf("Expected success, got error: %v", result.Content)
+		}
+		return result.Content[0].(mcp.TextContent).Text
+	}
+
+	want := "Found 1 values for label '__name__':\n1. http_requests_total\n\n3 metrics hidden by PROMETHEUS_EXCLUDED_METRICS\n"
+	if text := call("__name__"); text != want {
+		t.Errorf("unexpected output:\n%s\nwant:\n%s", text, want)
+	}
+
+	// Only metric names are filtered.
+	if text := call("job"); !strings.Contains(text, "Found 4 values") {
+		t.Errorf("expected values of other labels to be kept:\n%s", text)
+	}
+}
+
 func TestCheckReady(t *testing.T) {
 	tests := []struct {
 		name       string
@@ -575,14 +1104,14 @@ func TestTruncateWithAdvice(t *testing.T) {
 
 	t.Run("under cap returns input verbatim", func(t *testing.T) {
 		in := "small payload"
-		if got := truncateWithAdvice(in, advice); got != in {
+		if got := truncateWithAdvice(in, advice, MaxResultLength); got != in {
 			t.Errorf("expected passthrough, got %q", got)
 		}
 	})
 
 	t.Run("over cap with no nearby newline cuts at MaxResultLength", func(t *testing.T) {
 		in := strings.Repeat("a", MaxResultLength+50)
-		got := truncateWithAdvice(in, advice)
+		got := truncateWithAdvice(in, advice, MaxResultLength)
 		if want := MaxResultLength + len(advice); len(got) != want {
 			t.Errorf("expected total length %d, got %d", want, len(got))
 		}
@@ -595,7 +1124,7 @@ func TestTruncateWithAdvice(t *testing.T) {
 		// Place a newline 50 bytes before MaxResultLength, then more content.
 		head := strings.Repeat("a", MaxResultLength-50)
 		in := head + "\n" + strings.Repeat("b", 200)
-		got := truncateWithAdvice(in, advice)
+		got := truncateWithAdvice(in, advice, MaxResultLength)
 		if want := (MaxResultLength - 50) + len(advice); len(got) != want {
 			t.Errorf("expected total length %d (cut at newline), got %d", want, len(got))
 		}
@@ -644,7 +1173,7 @@ func TestHandleListLabelNamesTruncation(t *testing.T) {
 	}
 
 	// Exercise the production path: handler wrapped by truncationMiddleware.
-	h := truncationMiddleware("list_label_names", discoveryAdvice, func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	h := truncationMiddleware("list_label_names", discoveryAdvice, MaxResultLength, func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		return handleListLabelNames(ctx, req, client, sc)
 	})
 
@@ -766,7 +1295,7 @@ func TestTruncationMiddleware(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			h := truncationMiddleware(tt.toolName, tt.advice, makeHandler(tt.text))
+			h := truncationMiddleware(tt.toolName, tt.advice, MaxResultLength, makeHandler(tt.text))
 			req := mcp.CallToolRequest{Params: mcp.CallToolParams{Name: tt.toolName}}
 			if tt.unlimited {
 				req.Params.Arguments = map[string]any{"unlimited": "true"}
@@ -793,7 +1322,7 @@ func TestTruncationMiddleware(t *testing.T) {
 	}
 
 	t.Run("truncates every oversized TextContent in a multi-block result", func(t *testing.T) {
-		h := truncationMiddleware("find_series", discoveryAdvice, func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		h := truncationMiddleware("find_series", discoveryAdvice, MaxResultLength, func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 			return &mcp.CallToolResult{
 				Content: []mcp.Content{
 					mcp.TextContent{Type: contentTypeText, Text: bigText},
@@ -822,7 +1351,7 @@ func TestTruncationMiddleware(t *testing.T) {
 
 	t.Run("propagates handler errors without modification", func(t *testing.T) {
 		boom := fmt.Errorf("boom")
-		h := truncationMiddleware(toolExecuteQuery, TruncationAdvice, func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		h := truncationMiddleware(toolExecuteQuery, TruncationAdvice, MaxResultLength, func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 			return nil, boom
 		})
 		_, err := h(context.Background(), mcp.CallToolRequest{})
@@ -832,7 +1361,7 @@ func TestTruncationMiddleware(t *testing.T) {
 	})
 
 	t.Run("preserves IsError flag on tool error results", func(t *testing.T) {
-		h := truncationMiddleware(toolExecuteQuery, TruncationAdvice, func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		h := truncationMiddleware(toolExecuteQuery, TruncationAdvice, MaxResultLength, func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 			return &mcp.CallToolResult{
 				IsError: true,
 				Content: []mcp.Content{mcp.TextContent{Type: contentTypeText, Text: bigText}},
@@ -847,6 +1376,49 @@ func TestTruncationMiddleware(t *testing.T) {
 			t.Error("expected truncation to still apply to error results")
 		}
 	})
+
+	t.Run("applies the configured limit", func(t *testing.T) {
+		h := truncationMiddleware("get_rules", bulkAdvice, 100, makeHandler(strings.Repeat("x", 150)))
+		res, _ := h(context.Background(), mcp.CallToolRequest{})
+		got := res.Content[0].(mcp.TextContent).Text
+		if want := 100 + len(bulkAdvice); len(got) != want {
+			t.Errorf("len = %d, want %d", len(got), want)
+		}
+	})
+
+	t.Run("max_result_length lowers the limit per call", func(t *testing.T) {
+		tests := []struct {
+			value   string
+			wantLen int
+		}{
+			{value: "50", wantLen: 50},
+			{value: "1000", wantLen: 100}, // clamped to the configured limit
+		}
+		for _, tt := range tests {
+			h := truncationMiddleware("get_rules", bulkAdvice, 100, makeHandler(strings.Repeat("x", 150)))
+			req := mcp.CallToolRequest{}
+			req.Params.Arguments = map[string]any{"max_result_length": tt.value}
+			res, _ := h(context.Background(), req)
+			got := strings.TrimSuffix(res.Content[0].(mcp.TextContent).Text, bulkAdvice)
+			if len(got) != tt.wantLen {
+				t.Errorf("max_result_length %s: body length = %d, want %d", tt.value, len(got), tt.wantLen)
+			}
+		}
+	})
+
+	t.Run("rejects an invalid max_result_length", func(t *testing.T) {
+		called := false
+		h := truncationMiddleware("get_rules", bulkAdvice, 100, func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			called = true
+			return nil, nil
+		})
+		req := mcp.CallToolRequest{}
+		req.Params.Arguments = map[string]any{"max_result_length": "0"}
+		res, _ := h(context.Background(), req)
+		if !res.IsError || called {
+			t.Errorf("expected an error result without calling the handler, got IsError=%v called=%v", res.IsError, called)
+		}
+	})
 }
 
 // TestTruncateWithAdviceUTF8 verifies that truncation never lands mid-rune,
@@ -863,7 +1435,7 @@ func TestTruncateWithAdviceUTF8(t *testing.T) {
 		// Pad so a bulb crosses the MaxResultLength boundary.
 		padLen := MaxResultLength - 2
 		in := strings.Repeat("a", padLen) + bulb + strings.Repeat("b", 100)
-		got := truncateWithAdvice(in, advice)
+		got := truncateWithAdvice(in, advice, MaxResultLength)
 		body := strings.TrimSuffix(got, advice)
 		if !utf8.ValidString(body) {
 			t.Errorf("truncated body is not valid UTF-8: %q", body[len(body)-8:])
@@ -873,3 +1445,368 @@ func TestTruncateWithAdviceUTF8(t *testing.T) {
 		}
 	})
 }
+
+// notificationSession is a ClientSession that buffers notifications so tests
+// can inspect what a handler pushed to the client.
+type notificationSession struct {
+	ch chan mcp.JSONRPCNotification
+}
+
+func (s *notificationSession) Initialize()       {}
+func (s *notificationSession) Initialized() bool { return true }
+func (s *notificationSession) SessionID() string { return "test-session" }
+func (s *notificationSession) NotificationChannel() chan<- mcp.JSONRPCNotification {
+	return s.ch
+}
+
+func TestStreamingHandler(t *testing.T) {
+	ctx := context.Background()
+	sc, err := server.NewServerContext(ctx,
+		server.WithPrometheusConfig(server.PrometheusConfig{URL: "http://localhost:9090"}),
+		server.WithSlogLogger(discardLogger()),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create server context: %v", err)
+	}
+	defer func() { _ = sc.Shutdown() }()
+
+	inner := func(context.Context, mcp.CallToolRequest, *Client, *server.ServerContext) (*mcp.CallToolResult, error) {
+		return &mcp.CallToolResult{Content: []mcp.Content{mcp.TextContent{Type: contentTypeText, Text: "matrix result"}}}, nil
+	}
+	msg := []byte(`{
+		"jsonrpc": "2.0",
+		"id": 1,
+		"method": "tools/call",
+		"params": {"name": "stream_test", "arguments": {"query": "up", "streaming": "true"}}
+	}`)
+
+	// events dispatches msg through the server so the handler sees the same
+	// context as in production, and returns the streamed event names.
+	events := func(sse bool) []string {
+		srv := mcpserver.NewMCPServer("test", "1.0.0", mcpserver.WithToolCapabilities(true))
+		srv.AddTool(mcp.NewTool("stream_test"), func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return streamingHandler(inner)(ctx, req, nil, sc)
+		})
+
+		session := &notificationSession{ch: make(chan mcp.JSONRPCNotification, 10)}
+		callCtx := srv.WithContext(ctx, session)
+		if sse {
+			callCtx = WithStreamingTransport(callCtx, nil)
+		}
+		srv.HandleMessage(callCtx, msg)
+		close(session.ch)
+
+		var got []string
+		for n := range session.ch {
+			if n.Method != streamNotificationMethod {
+				t.Errorf("unexpected method %q", n.Method)
+			}
+			got = append(got, fmt.Sprint(n.Params.AdditionalFields["event"]))
+		}
+		return got
+	}
+
+	if got := strings.Join(events(true), ","); got != "progress,data,done" {
+		t.Errorf("SSE transport events = %q, want progress,data,done", got)
+	}
+	if got := events(false); len(got) != 0 {
+		t.Errorf("expected no events on non-SSE transport, got %v", got)
+	}
+}
+
+func TestHandleGetTargetsSummaryOnly(t *testing.T) {
+	target := func(pool, health string, duration float64) map[string]any {
+		return map[string]any{
+			"discoveredLabels":   map[string]string{},
+			"labels":             map[string]string{"job": pool},
+			"scrapePool":         pool,
+			"scrapeUrl":          "http://" + pool + ":8080/metrics",
+			"lastError":          "",
+			"lastScrape":         "2024-01-01T00:00:00Z",
+			"lastScrapeDuration": duration,
+			"health":             health,
+		}
+	}
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/targets" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			respKeyStatus: respValSuccess,
+			respKeyData: map[string]any{
+				"activeTargets": []any{
+					target("node", "up", 0.010),
+					target("node", "up", 0.020),
+					target("node", "up", 0.030),
+					target("node", "down", 0.040),
+					target("api", "up", 0.005),
+					target("api", "unknown", 0),
+				},
+				"droppedTargets": []any{map[string]any{"discoveredLabels": map[string]string{}}},
+			},
+		})
+	}))
+	defer mockServer.Close()
+
+	ctx := context.Background()
+	sc, err := server.NewServerContext(ctx,
+		server.WithPrometheusConfig(server.PrometheusConfig{URL: mockServer.URL}),
+		server.WithSlogLogger(discardLogger()),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create server context: %v", err)
+	}
+	defer func() { _ = sc.Shutdown() }()
+
+	client, err := NewClient(sc.PrometheusConfig(), sc.Logger())
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+
+	request := mcp.CallToolRequest{Params: mcp.CallToolParams{Name: "get_targets", Arguments: map[string]any{"summary_only": "true"}}}
+	result, err := handleGetTargets(ctx, request, client, sc)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.IsError {
+		t.Fatalf("expected success, got error: %v", result.Content)
+	}
+
+	want := "Scrape pool summary: 2 pools, 6 active targets, 1 dropped targets\n\n" +
+		"scrapePool | total_targets | healthy_targets | unhealthy_targets | health_percentage | avg_scrape_duration_ms | max_scrape_duration_ms\n" +
+		"api | 2 | 1 | 1 | 1/2 (50%) | 2.5 | 5.0\n" +
+		"node | 4 | 3 | 1 | 3/4 (75%) | 25.0 | 40.0\n"
+	if got := result.Content[0].(mcp.TextContent).Text; got != want {
+		t.Errorf("summary =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestCreateClientFromParamsTenantRoutes(t *testing.T) {
+	newBackend := func(name string, hits map[string]string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			hits[r.Header.Get("X-Scope-OrgID")] = name
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				respKeyStatus: respValSuccess,
+				respKeyData:   map[string]any{respKeyResultType: respValVector, respKeyResult: []any{}},
+			})
+		}))
+	}
+	hits := map[string]string{}
+	defaultBackend := newBackend("default", hits)
+	defer defaultBackend.Close()
+	shard1 := newBackend("shard1", hits)
+	defer shard1.Close()
+	shard2 := newBackend("shard2", hits)
+	defer shard2.Close()
+
+	ctx := context.Background()
+	sc, err := server.NewServerContext(ctx,
+		server.WithPrometheusConfig(server.PrometheusConfig{URL: defaultBackend.URL, PathPrefix: "/prometheus"}),
+		server.WithTenantRouter(map[string]string{"tenant1": shard1.URL, "tenant2": shard2.URL}),
+		server.WithSlogLogger(discardLogger()),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create server context: %v", err)
+	}
+	defer func() { _ = sc.Shutdown() }()
+
+	defaultClient, err := NewClient(sc.PrometheusConfig(), sc.Logger())
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+
+	tests := []struct {
+		params  map[string]any
+		wantURL string
+	}{
+		{map[string]any{"org_id": "tenant1"}, shard1.URL},
+		{map[string]any{"org_id": "tenant3"}, defaultBackend.URL},
+		{map[string]any{"org_id": "tenant2", "prometheus_url": defaultBackend.URL}, defaultBackend.URL},
+	}
+	for _, tt := range tests {
+		client, err := createClientFromParams(ctx, tt.params, defaultClient, sc)
+		if err != nil {
+			t.Fatalf("createClientFromParams(%v): %v", tt.params, err)
+		}
+		if client.config.URL != tt.wantURL {
+			t.Errorf("createClientFromParams(%v) URL = %q, want %q", tt.params, client.config.URL, tt.wantURL)
+		}
+	}
+
+	handler := withDynamicPrometheusClient(handleExecuteQuery, defaultClient, sc)
+	for _, orgID := range []string{"tenant1", "tenant3"} {
+		request := mcp.CallToolRequest{Params: mcp.CallToolParams{Name: toolExecuteQuery, Arguments: map[string]any{paramKeyQuery: "up", "org_id": orgID}}}
+		result, err := handler(ctx, request)
+		if err != nil || result.IsError {
+			t.Fatalf("org_id=%s: unexpected failure: %v %v", orgID, err, result)
+		}
+	}
+	if hits["tenant1"] != "shard1" || hits["tenant3"] != "default" {
+		t.Errorf("backends hit per tenant = %v, want tenant1 on shard1 and tenant3 on default", hits)
+	}
+}
+
+func TestCreateClientFromParamsProfiles(t *testing.T) {
+	dir := t.TempDir()
+	configFile := filepath.Join(dir, "config.yaml")
+	config := "default: prod\nprofiles:\n" +
+		"  prod:\n    url: http://prod:9090\n" +
+		"  staging:\n    url: http://staging:9090\n    org_id: staging\n    token: s3cret\n"
+	if err := os.WriteFile(configFile, []byte(config), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	t.Setenv("PROMETHEUS_URL", "")
+	sc, err := server.NewServerContext(ctx,
+		server.WithConfigFile(configFile),
+		server.WithSlogLogger(discardLogger()),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create server context: %v", err)
+	}
+	defer func() { _ = sc.Shutdown() }()
+
+	defaultClient, err := NewClient(sc.PrometheusConfig(), sc.Logger())
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+
+	tests := []struct {
+		params    map[string]any
+		wantURL   string
+		wantOrgID string
+		wantToken string
+	}{
+		{map[string]any{}, "http://prod:9090", "", ""},
+		{map[string]any{"profile": "staging"}, "http://staging:9090", "staging", "s3cret"},
+		{map[string]any{"profile": "staging", "org_id": "other"}, "http://staging:9090", "other", "s3cret"},
+		{map[string]any{"profile": "prod", "prometheus_url": "http://override:9090"}, "http://override:9090", "", ""},
+	}
+	for _, tt := range tests {
+		client, err := createClientFromParams(ctx, tt.params, defaultClient, sc)
+		if err != nil {
+			t.Fatalf("createClientFromParams(%v): %v", tt.params, err)
+		}
+		if client.config.URL != tt.wantURL || client.config.OrgID != tt.wantOrgID || client.config.Token != tt.wantToken {
+			t.Errorf("createClientFromParams(%v) = URL %q, org %q, token %q; want %q, %q, %q", tt.params,
+				client.config.URL, client.config.OrgID, client.config.Token, tt.wantURL, tt.wantOrgID, tt.wantToken)
+		}
+	}
+
+	if _, err := createClientFromParams(ctx, map[string]any{"profile": "dev"}, defaultClient, sc); err == nil || !strings.Contains(err.Error(), "unknown profile") {
+		t.Errorf("expected an unknown profile error, got %v", err)
+	}
+	if _, err := createClientFromParams(ctx, map[string]any{"profile": "staging", "prometheus_url": "http://override:9090"}, defaultClient, sc); err == nil || !strings.Contains(err.Error(), "carries bearer credentials") {
+		t.Errorf("expected prometheus_url to be refused with a credentialed profile, got %v", err)
+	}
+}
+
+func TestCreateClientFromParamsCachesClients(t *testing.T) {
+	ctx := context.Background()
+	sc, err := server.NewServerContext(ctx,
+		server.WithPrometheusConfig(server.PrometheusConfig{URL: "http://default:9090"}),
+		server.WithSlogLogger(discardLogger()),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create server context: %v", err)
+	}
+	defer func() { _ = sc.Shutdown() }()
+
+	get := func(params map[string]any) *Client {
+		t.Helper()
+		client, err := createClientFromParams(ctx, params, nil, sc)
+		if err != nil {
+			t.Fatalf("createClientFromParams(%v): %v", params, err)
+		}
+		return client
+	}
+
+	first := get(map[string]any{"prometheus_url": "http://other:9090", "org_id": "a"})
+	if again := get(map[string]any{"prometheus_url": "http://other:9090", "org_id": "a"}); again != first {
+		t.Error("expected the same URL and org ID to reuse the cached client")
+	}
+	if other := get(map[string]any{"prometheus_url": "http://other:9090", "org_id": "b"}); other == first || other.config.OrgID != "b" {
+		t.Error("expected another org ID to get its own client")
+	}
+	if n := sc.ClientCache().Len(); n != 2 {
+		t.Errorf("cached %d clients, want 2", n)
+	}
+
+	// Without a cache every call builds a client.
+	uncached, err := server.NewServerContext(ctx,
+		server.WithPrometheusConfig(server.PrometheusConfig{URL: "http://default:9090"}),
+		server.WithSlogLogger(discardLogger()),
+		server.WithClientCache(nil),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create server context: %v", err)
+	}
+	defer func() { _ = uncached.Shutdown() }()
+	params := map[string]any{"org_id": "a"}
+	a, errA := createClientFromParams(ctx, params, nil, uncached)
+	b, errB := createClientFromParams(ctx, params, nil, uncached)
+	if errA != nil || errB != nil || a == b {
+		t.Errorf("expected two clients without a cache, got %p, %p (%v, %v)", a, b, errA, errB)
+	}
+}
+
+func TestToolsDeclareProfileParam(t *testing.T) {
+	srv := newStructuredServer(t)
+
+	// Tools that only work on local state never contact Prometheus.
+	local := map[string]bool{
+		toolGetServerConfig: true, toolGenerateDashboardJSON: true, toolValidatePromQL: true, toolExplainPromQL: true, toolGetInvocationHistory: true,
+		toolRegisterTemplate: true, toolListTemplates: true, toolDeleteTemplate: true,
+		toolRegisterTestTarget: true, toolDeregisterTestTarget: true,
+	}
+	for name, st := range srv.ListTools() {
+		if local[name] {
+			continue
+		}
+		if _, ok := st.Tool.InputSchema.Properties["profile"]; !ok {
+			t.Errorf("tool %q does not accept a profile parameter", name)
+		}
+	}
+}
+
+func TestToolAnnotations(t *testing.T) {
+	srv := newStructuredServer(t)
+
+	// Tools that change state, mapped to whether they destroy data. Every
+	// other tool must be read-only.
+	writes := map[string]bool{
+		toolPushMetric: false, toolDeleteSeries: true, toolReloadConfig: false, toolSnapshotTSDB: false,
+		toolRegisterTemplate: false, toolDeleteTemplate: true,
+		toolRegisterTestTarget: false, toolDeregisterTestTarget: true,
+	}
+	for name, st := range srv.ListTools() {
+		a := st.Tool.Annotations
+		if a.ReadOnlyHint == nil || a.DestructiveHint == nil {
+			t.Errorf("tool %q annotations = %+v, want readOnlyHint and destructiveHint set", name, a)
+			continue
+		}
+		destructive, write := writes[name]
+		if *a.ReadOnlyHint == write || *a.DestructiveHint != destructive {
+			t.Errorf("tool %q: readOnlyHint = %v, destructiveHint = %v; want %v, %v", name, *a.ReadOnlyHint, *a.DestructiveHint, !write, destructive)
+		}
+	}
+}
+
+func TestRegisterPrometheusToolsDefaultsWriteToolsToDestructive(t *testing.T) {
+	sc, err := server.NewServerContext(context.Background(), server.WithSlogLogger(discardLogger()))
+	if err != nil {
+		t.Fatalf("Failed to create server context: %v", err)
+	}
+	defer func() { _ = sc.Shutdown() }()
+
+	s := mcpserver.NewMCPServer("test", "1.0.0")
+	registerPrometheusTools(s, nil, sc, nil, "admin_tool", "An admin tool", noTruncation, nil, mcp.WithReadOnlyHintAnnotation(false))
+	a := s.GetTool("admin_tool").Tool.Annotations
+	if *a.ReadOnlyHint || !*a.DestructiveHint {
+		t.Errorf("annotations = %+v, want a destructive, non-read-only tool", a)
+	}
+}
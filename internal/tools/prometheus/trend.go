@@ -0,0 +1,49 @@
+package prometheus
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/prometheus/common/model"
+
+	"github.com/giantswarm/mcp-prometheus/internal/analysis"
+)
+
+// defaultTrendHorizon is how far past the range end include_trend projects
+// when project_steps is not set.
+const defaultTrendHorizon = model.Duration(time.Hour)
+
+// trendDirection describes the sign of a slope.
+func trendDirection(slope float64) string {
+	switch {
+	case slope > 0:
+		return "↑ growing"
+	case slope < 0:
+		return "↓ shrinking"
+	default:
+		return "→ flat"
+	}
+}
+
+// formatTrends renders a least-squares trend line per series of m, projected
+// horizon past end.
+func formatTrends(m model.Matrix, end model.Time, horizon model.Duration) string {
+	var b strings.Builder
+	b.WriteString("Trend (least-squares fit per series):\n")
+	for _, s := range m {
+		slope, intercept, r2, ok := analysis.LinearRegression(s.Values)
+		if !ok {
+			fmt.Fprintf(&b, "%s: not enough samples for a trend\n", s.Metric)
+			continue
+		}
+		projected := analysis.ProjectLinear(slope, intercept, end.Add(time.Duration(horizon)))
+		fmt.Fprintf(&b, "%s: slope: %+.4g/sec (%s), R²: %.2f, projected_value_in_%s: %.1f",
+			s.Metric, slope, trendDirection(slope), r2, horizon, projected)
+		if r2 < analysis.PoorFitR2 {
+			fmt.Fprintf(&b, " ⚠️ poor fit (R² < %.1f), trend unreliable", analysis.PoorFitR2)
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
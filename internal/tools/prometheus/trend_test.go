@@ -0,0 +1,98 @@
+package prometheus
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/giantswarm/mcp-prometheus/internal/server"
+)
+
+func TestHandleExecuteRangeQueryIncludeTrend(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/query_range" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		// growing: 0.5/s from 100; noisy: alternating 1 and 9.
+		var growing, noisy []any
+		for i := range 5 {
+			ts := 1704067200 + 60*i
+			growing = append(growing, []any{ts, strconv.Itoa(100 + 30*i)})
+			noisy = append(noisy, []any{ts, []string{"1", "9"}[i%2]})
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			respKeyStatus: respValSuccess,
+			respKeyData: map[string]any{respKeyResultType: "matrix", respKeyResult: []any{
+				map[string]any{"metric": map[string]string{"series": "growing"}, "values": growing},
+				map[string]any{"metric": map[string]string{"series": "noisy"}, "values": noisy},
+				map[string]any{"metric": map[string]string{"series": "single"}, "values": []any{[]any{1704067200, "1"}}},
+			}},
+		})
+	}))
+	defer mockServer.Close()
+
+	ctx := context.Background()
+	sc, err := server.NewServerContext(ctx,
+		server.WithPrometheusConfig(server.PrometheusConfig{URL: mockServer.URL}),
+		server.WithSlogLogger(discardLogger()),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create server context: %v", err)
+	}
+	defer func() { _ = sc.Shutdown() }()
+
+	client, err := NewClient(sc.PrometheusConfig(), sc.Logger())
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+
+	call := func(extra map[string]any) *mcp.CallToolResult {
+		t.Helper()
+		args := map[string]any{paramKeyQuery: "x", "start": "1704067200", "end": "1704067440", "step": "1m", "include_trend": "true"}
+		for k, v := range extra {
+			args[k] = v
+		}
+		request := mcp.CallToolRequest{Params: mcp.CallToolParams{Name: toolExecuteRangeQuery, Arguments: args}}
+		result, err := handleExecuteRangeQuery(ctx, request, client, sc)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return result
+	}
+
+	result := call(nil)
+	text := result.Content[0].(mcp.TextContent).Text
+	if result.IsError {
+		t.Fatalf("expected success, got error: %s", text)
+	}
+	// At end (t+240s) the growing series is 220; 1h later 220 + 0.5*3600.
+	for _, want := range []string{
+		"Trend (least-squares fit per series):\n",
+		`{series="growing"}: slope: +0.5/sec (↑ growing), R²: 1.00, projected_value_in_1h: 2020.0` + "\n",
+		`{series="noisy"}: slope: `,
+		"⚠️ poor fit (R² < 0.5), trend unreliable\n",
+		`{series="single"}: not enough samples for a trend` + "\n",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("output missing %q:\n%s", want, text)
+		}
+	}
+
+	// Ten 1m steps past the end: 220 + 0.5*600.
+	text = call(map[string]any{"project_steps": "10"}).Content[0].(mcp.TextContent).Text
+	if want := "projected_value_in_10m: 520.0"; !strings.Contains(text, want) {
+		t.Errorf("output missing %q:\n%s", want, text)
+	}
+
+	if result := call(map[string]any{"project_steps": "-1"}); !result.IsError {
+		t.Errorf("expected an error for negative project_steps, got %v", result.Content)
+	}
+}
@@ -4,8 +4,13 @@ import (
 	"fmt"
 	"net"
 	"net/url"
+	"path"
+	"strings"
 )
 
+// multiOrgSeparator separates tenant IDs in a Mimir multi-tenant org_id.
+const multiOrgSeparator = "|"
+
 // validatePrometheusURL checks that a caller-supplied prometheus_url is safe
 // to use as an HTTP target.
 //
@@ -21,30 +26,23 @@ import (
 //     (AWS IMDSv1, GCP, Azure) live; there is never a legitimate Prometheus
 //     endpoint there.
 func validatePrometheusURL(raw string) error {
-	u, err := url.Parse(raw)
-	if err != nil {
-		return fmt.Errorf("invalid prometheus_url: %w", err)
-	}
+	return validateTargetURL("prometheus_url", raw)
+}
 
-	switch u.Scheme {
-	case "http", "https":
-		// allowed
-	case "":
-		return fmt.Errorf("invalid prometheus_url %q: scheme is required (use http:// or https://)", raw)
-	default:
-		return fmt.Errorf("invalid prometheus_url %q: scheme %q is not allowed (use http:// or https://)", raw, u.Scheme)
+// validateTargetURL applies the validatePrometheusURL rules to the URL
+// parameter param, naming it in the error.
+func validateTargetURL(param, raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return fmt.Errorf("invalid %s: must be a full URL with http or https scheme (got '%s')", param, raw)
 	}
-
 	host := u.Hostname()
-	if host == "" {
-		return fmt.Errorf("invalid prometheus_url %q: host is required", raw)
-	}
 
 	ip := net.ParseIP(host)
 	if ip != nil {
 		// Block link-local ranges — cloud metadata services (169.254.169.254, fe80::…).
 		if isLinkLocal(ip) {
-			return fmt.Errorf("invalid prometheus_url %q: link-local addresses are not allowed", raw)
+			return fmt.Errorf("invalid %s %q: link-local addresses are not allowed", param, raw)
 		}
 	}
 
@@ -67,3 +65,42 @@ func isLinkLocal(ip net.IP) bool {
 	}
 	return false
 }
+
+// validateOrgID rejects a caller-supplied org_id containing the Mimir
+// multi-tenant separator unless the caller opted in with allow_multi_org,
+// so a stray "|" cannot silently fan a query out across tenants.
+func validateOrgID(orgID string, allowMultiOrg bool) error {
+	if allowMultiOrg || !strings.Contains(orgID, multiOrgSeparator) {
+		return nil
+	}
+	return fmt.Errorf("invalid org_id %q: %q is reserved as the multi-tenant separator (set allow_multi_org to 'true' to query several tenants)", orgID, multiOrgSeparator)
+}
+
+// validatePathPrefix checks that a PROMETHEUS_PATH_PREFIX is an absolute path
+// that stops short of the API itself: the client appends /api/v1/... on its
+// own, so a prefix ending in /api would produce /api/api/v1/... requests.
+func validatePathPrefix(prefix string) error {
+	if prefix == "" {
+		return nil
+	}
+	if !strings.HasPrefix(prefix, "/") {
+		return fmt.Errorf("invalid PROMETHEUS_PATH_PREFIX: must start with '/' (got '%s')", prefix)
+	}
+	if strings.HasSuffix(path.Clean(prefix), "/api") {
+		return fmt.Errorf("invalid PROMETHEUS_PATH_PREFIX: must not end with '/api', the API path is appended automatically (got '%s')", prefix)
+	}
+	return nil
+}
+
+// joinPathPrefix returns baseURL with prefix appended, collapsing duplicate
+// and trailing slashes.
+func joinPathPrefix(baseURL, prefix string) string {
+	baseURL = strings.TrimRight(baseURL, "/")
+	if prefix == "" {
+		return baseURL
+	}
+	if cleaned := path.Clean("/" + prefix); cleaned != "/" {
+		return baseURL + cleaned
+	}
+	return baseURL
+}
@@ -1,7 +1,11 @@
 package prometheus
 
 import (
+	"context"
+	"strings"
 	"testing"
+
+	"github.com/giantswarm/mcp-prometheus/internal/server"
 )
 
 func TestValidatePrometheusURL(t *testing.T) {
@@ -39,3 +43,146 @@ func TestValidatePrometheusURL(t *testing.T) {
 		})
 	}
 }
+
+func TestValidatePrometheusURL_ErrorMessage(t *testing.T) {
+	tests := []struct {
+		name string
+		url  string
+	}{
+		{name: "not a url", url: "not-a-url"},
+		{name: "empty scheme", url: "//prometheus.example.com:9090"},
+		{name: "missing host", url: "http://"},
+		{name: "missing host with path", url: "https:///api/v1"},
+		{name: "file scheme", url: "file:///etc/passwd"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validatePrometheusURL(tt.url)
+			if err == nil {
+				t.Fatalf("validatePrometheusURL(%q) expected error", tt.url)
+			}
+			want := "invalid prometheus_url: must be a full URL with http or https scheme (got '" + tt.url + "')"
+			if err.Error() != want {
+				t.Errorf("error = %q, want %q", err.Error(), want)
+			}
+		})
+	}
+}
+
+func TestValidateOrgID(t *testing.T) {
+	tests := []struct {
+		name          string
+		orgID         string
+		allowMultiOrg bool
+		wantErr       bool
+	}{
+		{name: "empty", orgID: "", wantErr: false},
+		{name: "single tenant", orgID: "team-a", wantErr: false},
+		{name: "pipe without opt-in", orgID: "team-a|team-b", wantErr: true},
+		{name: "trailing pipe without opt-in", orgID: "team-a|", wantErr: true},
+		{name: "pipe with opt-in", orgID: "team-a|team-b", allowMultiOrg: true, wantErr: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateOrgID(tt.orgID, tt.allowMultiOrg)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateOrgID(%q, %v) error = %v, wantErr %v", tt.orgID, tt.allowMultiOrg, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidatePathPrefix(t *testing.T) {
+	tests := []struct {
+		prefix  string
+		wantErr string
+	}{
+		{prefix: ""},
+		{prefix: "/prometheus"},
+		{prefix: "/custom/prometheus/"},
+		{prefix: "prometheus", wantErr: "must start with '/'"},
+		{prefix: "/prometheus/api", wantErr: "must not end with '/api'"},
+		{prefix: "/prometheus/api/", wantErr: "must not end with '/api'"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.prefix, func(t *testing.T) {
+			err := validatePathPrefix(tt.prefix)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestJoinPathPrefix(t *testing.T) {
+	tests := []struct {
+		base, prefix, want string
+	}{
+		{"http://prom:9090", "", "http://prom:9090"},
+		{"http://prom:9090/", "", "http://prom:9090"},
+		{"http://prom:9090", "/custom/prometheus", "http://prom:9090/custom/prometheus"},
+		{"http://prom:9090/", "/custom/prometheus/", "http://prom:9090/custom/prometheus"},
+		{"http://prom:9090", "//custom//prometheus", "http://prom:9090/custom/prometheus"},
+		{"http://prom:9090", "/", "http://prom:9090"},
+	}
+	for _, tt := range tests {
+		if got := joinPathPrefix(tt.base, tt.prefix); got != tt.want {
+			t.Errorf("joinPathPrefix(%q, %q) = %q, want %q", tt.base, tt.prefix, got, tt.want)
+		}
+	}
+}
+
+func TestCreateClientFromParams_Validation(t *testing.T) {
+	sc, err := server.NewServerContext(context.Background(), server.WithSlogLogger(discardLogger()))
+	if err != nil {
+		t.Fatalf("NewServerContext: %v", err)
+	}
+	defer func() { _ = sc.Shutdown() }()
+
+	tests := []struct {
+		name    string
+		params  map[string]any
+		wantErr string
+	}{
+		{
+			name:    "invalid url",
+			params:  map[string]any{"prometheus_url": "not-a-url"},
+			wantErr: "invalid prometheus_url",
+		},
+		{
+			name:    "multi org without opt-in",
+			params:  map[string]any{"prometheus_url": "http://localhost:9090", "org_id": "a|b"},
+			wantErr: "invalid org_id",
+		},
+		{
+			name:   "multi org with opt-in",
+			params: map[string]any{"prometheus_url": "http://localhost:9090", "org_id": "a|b", "allow_multi_org": "true"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := createClientFromParams(context.Background(), tt.params, nil, sc)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if client.config.OrgID != "a|b" {
+					t.Errorf("OrgID = %q, want %q", client.config.OrgID, "a|b")
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
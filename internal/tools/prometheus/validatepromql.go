@@ -0,0 +1,105 @@
+package prometheus
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	mcpserver "github.com/mark3labs/mcp-go/server"
+
+	"github.com/giantswarm/mcp-prometheus/internal/promql"
+	"github.com/giantswarm/mcp-prometheus/internal/server"
+)
+
+// toolValidatePromQL is the registered name of the local PromQL parser tool.
+const toolValidatePromQL = "validate_promql"
+
+// registerValidatePromQLTool registers validate_promql. It parses locally,
+// so like generate_dashboard_json it bypasses the dynamic client wrapper and
+// takes no connection parameters.
+func registerValidatePromQLTool(s *mcpserver.MCPServer, sc *server.ServerContext, middleware []ToolMiddleware) {
+	tool := mcp.NewTool(toolValidatePromQL,
+		mcp.WithDescription("Check a PromQL expression with the Prometheus parser locally, without contacting the server. Returns syntax errors with line and column, or the result type and the series selectors the expression reads"),
+		mcp.WithString("query", mcp.Required(), mcp.Description("PromQL expression to validate")),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithDestructiveHintAnnotation(false),
+		mcp.WithOpenWorldHintAnnotation(false),
+		mcp.WithSchemaAdditionalProperties(false),
+	)
+
+	h := withServerMiddleware(sc, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return handleValidatePromQL(ctx, request, sc)
+	})
+	for _, mw := range middleware {
+		h = mw(toolValidatePromQL, h)
+	}
+	s.AddTool(tool, h)
+}
+
+// handleValidatePromQL handles the validate_promql tool
+func handleValidatePromQL(_ context.Context, request mcp.CallToolRequest, sc *server.ServerContext) (*mcp.CallToolResult, error) {
+	params := extractParams(request)
+
+	query := getStringParam(params, "query")
+	if query == "" {
+		return &mcp.CallToolResult{
+			IsError: true,
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: contentTypeText,
+					Text: errQueryParameterRequired,
+				},
+			},
+		}, nil
+	}
+
+	sc.Logger().Debug("Validating PromQL", "query", query)
+
+	expr, err := promql.Parse(query)
+	if err != nil {
+		return &mcp.CallToolResult{
+			IsError: true,
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: contentTypeText,
+					Text: formatSyntaxErrors(query, promql.SyntaxErrors(query, err)),
+				},
+			},
+		}, nil
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Valid PromQL expression of type %s.\n\nNormalised: %s\n", expr.Type(), expr)
+	selectors := promql.VectorSelectors(expr)
+	fmt.Fprintf(&b, "\nSeries selectors (%d):\n", len(selectors))
+	for _, vs := range selectors {
+		fmt.Fprintf(&b, "  %s\n", vs)
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.TextContent{
+				Type: contentTypeText,
+				Text: b.String(),
+			},
+		},
+	}, nil
+}
+
+// formatSyntaxErrors lists syntax errors by position. For a single-line
+// query the first error is also marked with a caret under the query.
+func formatSyntaxErrors(query string, errs []promql.SyntaxError) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Invalid PromQL: %d syntax error(s):\n", len(errs))
+	for _, e := range errs {
+		if e.Line == 0 {
+			fmt.Fprintf(&b, "  %s\n", e.Message)
+			continue
+		}
+		fmt.Fprintf(&b, "  line %d, column %d: %s\n", e.Line, e.Column, e.Message)
+	}
+	if first := errs[0]; first.Line == 1 && !strings.Contains(query, "\n") {
+		fmt.Fprintf(&b, "\n  %s\n  %s^\n", query, strings.Repeat(" ", first.Column-1))
+	}
+	return b.String()
+}
@@ -0,0 +1,73 @@
+package prometheus
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/giantswarm/mcp-prometheus/internal/server"
+)
+
+func TestHandleValidatePromQL(t *testing.T) {
+	sc, err := server.NewServerContext(context.Background(), server.WithSlogLogger(discardLogger()))
+	if err != nil {
+		t.Fatalf("Failed to create server context: %v", err)
+	}
+	defer func() { _ = sc.Shutdown() }()
+
+	tests := []struct {
+		name        string
+		query       string
+		wantIsError bool
+		want        []string
+	}{
+		{
+			name:  "valid",
+			query: `sum by (job) (rate(http_requests_total{code=~"5.."}[5m])) / sum by (job) (rate(http_requests_total[5m]))`,
+			want: []string{
+				"Valid PromQL expression of type vector.\n",
+				"Series selectors (2):\n" +
+					`  http_requests_total{code=~"5.."}` + "\n" +
+					"  http_requests_total\n",
+			},
+		},
+		{
+			name:        "unclosed parenthesis",
+			query:       "rate(http_requests_total[5m]",
+			wantIsError: true,
+			want: []string{
+				"line 1, column 29: unclosed left parenthesis\n",
+				"\n  rate(http_requests_total[5m]\n  " + strings.Repeat(" ", 28) + "^\n",
+			},
+		},
+		{
+			name:        "multi-line",
+			query:       "sum(up)\n  by (job) +",
+			wantIsError: true,
+			want:        []string{"line 2, column 13: unexpected end of input\n"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := mcp.CallToolRequest{Params: mcp.CallToolParams{Name: toolValidatePromQL, Arguments: map[string]any{paramKeyQuery: tt.query}}}
+			result, err := handleValidatePromQL(context.Background(), request, sc)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.IsError != tt.wantIsError {
+				t.Errorf("IsError = %v, want %v", result.IsError, tt.wantIsError)
+			}
+			text := result.Content[0].(mcp.TextContent).Text
+			for _, want := range tt.want {
+				if !strings.Contains(text, want) {
+					t.Errorf("output missing %q:\n%s", want, text)
+				}
+			}
+			if strings.Contains(tt.query, "\n") && strings.Contains(text, "^") {
+				t.Errorf("multi-line query should not get a caret marker:\n%s", text)
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package prometheus
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// warmupTimeout bounds how long Warmup holds up server startup.
+const warmupTimeout = 5 * time.Second
+
+// Warmup opens count connections to Prometheus in parallel by sending a
+// trivial instant query (`1`) on each, so early tool calls find established
+// connections in the pool. It waits for all requests or warmupTimeout,
+// whichever comes first, logs the outcome at INFO and returns the number of
+// successful requests.
+//
+// How many connections stay open afterwards is bounded by the transport's
+// MaxIdleConnsPerHost (2 for http.DefaultTransport).
+func (c *Client) Warmup(ctx context.Context, count int) int {
+	if c.httpClient == nil || count <= 0 {
+		return 0
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, warmupTimeout)
+	defer cancel()
+
+	warmupURL := fmt.Sprintf("%s/api/v1/query?query=1&time=%d", c.baseURL, time.Now().Unix())
+
+	start := time.Now()
+	var succeeded atomic.Int64
+	var wg sync.WaitGroup
+	for range count {
+		wg.Go(func() {
+			if err := c.warmupRequest(ctx, warmupURL); err != nil {
+				c.logger.Debug("Connection warmup request failed", "error", err)
+				return
+			}
+			succeeded.Add(1)
+		})
+	}
+	wg.Wait()
+
+	ok := int(succeeded.Load())
+	c.logger.Info("Prometheus connection warmup finished",
+		"requested", count, "succeeded", ok, "duration", time.Since(start).Round(time.Millisecond))
+	return ok
+}
+
+// warmupRequest sends one warmup query and drains the response so the
+// connection is returned to the pool.
+func (c *Client) warmupRequest(ctx context.Context, warmupURL string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, warmupURL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create warmup request: %w", err)
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("warmup request failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("warmup request returned HTTP %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package prometheus
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	mcpserver "github.com/mark3labs/mcp-go/server"
+
+	"github.com/giantswarm/mcp-prometheus/internal/server"
+)
+
+func TestRegisterPrometheusToolsConnectionWarmup(t *testing.T) {
+	var warmups atomic.Int64
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v1/query" && r.URL.Query().Get("query") == "1" && r.URL.Query().Get("time") != "" {
+			warmups.Add(1)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"status":"success","data":{"resultType":"scalar","result":[1704067200,"1"]}}`))
+	}))
+	defer mockServer.Close()
+
+	sc, err := server.NewServerContext(context.Background(),
+		server.WithPrometheusConfig(server.PrometheusConfig{URL: mockServer.URL}),
+		server.WithSlogLogger(discardLogger()),
+		server.WithConnectionWarmup(4),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create server context: %v", err)
+	}
+	defer func() { _ = sc.Shutdown() }()
+
+	if err := RegisterPrometheusTools(mcpserver.NewMCPServer("test", "1.0.0"), sc); err != nil {
+		t.Fatalf("RegisterPrometheusTools: %v", err)
+	}
+
+	// Warmup completes before RegisterPrometheusTools returns.
+	if got := warmups.Load(); got != 4 {
+		t.Errorf("expected 4 warmup requests, got %d", got)
+	}
+}
+
+func TestWarmupStopsAtContextDeadline(t *testing.T) {
+	release := make(chan struct{})
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+	}))
+	defer mockServer.Close()
+	defer close(release)
+
+	client, err := NewClient(server.PrometheusConfig{URL: mockServer.URL}, discardLogger())
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	if got := client.Warmup(ctx, 2); got != 0 {
+		t.Errorf("expected no successful warmups, got %d", got)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Warmup did not stop at the context deadline (took %v)", elapsed)
+	}
+}
@@ -1,15 +1,26 @@
 package main
 
 import (
+	"runtime"
+
 	"github.com/giantswarm/mcp-prometheus/cmd"
 )
 
-// version will be set by goreleaser during build
-var version = "dev"
+// version and commit will be set by goreleaser during build
+var (
+	version = "dev"
+	commit  = ""
+)
 
 func main() {
-	// Set the version from build-time variable
-	cmd.SetVersion(version)
+	// Set the version from build-time variables and the Go runtime
+	cmd.SetVersion(cmd.BuildInfo{
+		Version:   version,
+		Commit:    commit,
+		GoVersion: runtime.Version(),
+		GOOS:      runtime.GOOS,
+		GOARCH:    runtime.GOARCH,
+	})
 
 	// Execute the root command
 	cmd.Execute()
@@ -0,0 +1,53 @@
+// Package plugin lets applications embedding mcp-prometheus add their own MCP
+// tools. They implement ToolPlugin and register it with
+// cmd.RegisterToolPlugin before calling cmd.Execute; the serve command then
+// registers the plugin's tools after the built-in ones:
+//
+//	type echoPlugin struct{}
+//
+//	func (echoPlugin) Name() string { return "echo" }
+//
+//	func (echoPlugin) Register(s *mcpserver.MCPServer, host plugin.Host) error {
+//	    s.AddTool(mcp.NewTool("echo"), func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+//	        return host.RunToolMiddleware(ctx, "echo", func() (*mcp.CallToolResult, error) {
+//	            host.Logger().Debug("echo called")
+//	            return mcp.NewToolResultText("echo"), nil
+//	        })
+//	    })
+//	    return nil
+//	}
+//
+//	func main() {
+//	    cmd.RegisterToolPlugin(echoPlugin{})
+//	    cmd.Execute()
+//	}
+package plugin
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	mcpserver "github.com/mark3labs/mcp-go/server"
+)
+
+// ToolPlugin adds custom MCP tools to the server without forking the
+// built-in tool registration.
+type ToolPlugin interface {
+	// Name identifies the plugin in logs and registration errors.
+	Name() string
+
+	// Register adds the plugin's tools to s. It runs after the built-in
+	// tools are registered.
+	Register(s *mcpserver.MCPServer, host Host) error
+}
+
+// Host is the server a ToolPlugin registers its tools with.
+type Host interface {
+	// Logger returns the server's logger.
+	Logger() *slog.Logger
+
+	// RunToolMiddleware runs final wrapped in the server's tool middleware,
+	// such as rate limits and audit logging, as the built-in tools do.
+	RunToolMiddleware(ctx context.Context, toolName string, final func() (*mcp.CallToolResult, error)) (*mcp.CallToolResult, error)
+}